@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"bcrdf/internal/index"
+	"bcrdf/internal/state"
+	"bcrdf/internal/validator"
+	"bcrdf/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// completeBackupIDs complète les IDs de sauvegarde (arguments et --backup-id)
+func completeBackupIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	refs := completionBackupRefs()
+	var ids []string
+	for _, ref := range refs {
+		if strings.HasPrefix(ref.ID, toComplete) {
+			ids = append(ids, ref.ID)
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeBackupIDArg complète l'ID de sauvegarde passé en unique argument
+func completeBackupIDArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeBackupIDs(cmd, args, toComplete)
+}
+
+// completeBackupNames complète les noms de jobs de sauvegarde (--name)
+func completeBackupNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ref := range completionBackupRefs() {
+		if !seen[ref.Name] && strings.HasPrefix(ref.Name, toComplete) {
+			seen[ref.Name] = true
+			names = append(names, ref.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionBackupRefs retourne les sauvegardes connues, plus récentes en premier
+// La base d'état locale (backup.state_db) est utilisée si elle existe: la complétion reste
+// instantanée et hors ligne. Sinon, seuls les noms des index distants sont listés.
+// Toute erreur donne simplement une complétion vide.
+func completionBackupRefs() []index.BackupRef {
+	// Ne pas laisser LoadConfig créer un fichier de configuration par défaut
+	if _, err := os.Stat(configFile); err != nil {
+		return nil
+	}
+	config, err := utils.LoadConfig(configFile)
+	if err != nil {
+		return nil
+	}
+
+	if config.Backup.StateDB != "" {
+		if _, err := os.Stat(config.Backup.StateDB); err == nil {
+			return stateBackupRefs(config.Backup.StateDB)
+		}
+	}
+
+	refs, err := index.NewManager(configFile).ListBackupRefs()
+	if err != nil {
+		return nil
+	}
+	return refs
+}
+
+// stateBackupRefs lit les sauvegardes réussies enregistrées dans la base d'état locale
+func stateBackupRefs(path string) []index.BackupRef {
+	store, err := state.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer store.Close()
+
+	runs, err := store.SuccessfulRuns()
+	if err != nil {
+		return nil
+	}
+	refs := make([]index.BackupRef, 0, len(runs))
+	for _, run := range runs {
+		refs = append(refs, index.BackupRef{ID: run.BackupID, Name: run.BackupName, CreatedAt: run.StartedAt})
+	}
+	return refs
+}
+
+// completePresets complète les presets de fournisseurs de bcrdf init --preset
+func completePresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return validator.PresetNames(), cobra.ShellCompDirectiveNoFileComp
+}
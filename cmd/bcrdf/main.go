@@ -19,12 +19,26 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"bcrdf/internal/backup"
+	"bcrdf/internal/bench"
+	"bcrdf/internal/cost"
+	"bcrdf/internal/docs"
+	"bcrdf/internal/estimate"
+	"bcrdf/internal/gc"
 	"bcrdf/internal/health"
 	"bcrdf/internal/index"
+	"bcrdf/internal/manifest"
+	"bcrdf/internal/migration"
+	"bcrdf/internal/repair"
 	"bcrdf/internal/restore"
 	"bcrdf/internal/retention"
+	"bcrdf/internal/selftest"
+	"bcrdf/internal/share"
+	"bcrdf/internal/state"
+	"bcrdf/internal/stats"
+	"bcrdf/internal/tempdir"
 	"bcrdf/internal/validator"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
@@ -43,6 +57,12 @@ func (e *DeferredUpdateError) Error() string {
 var (
 	configFile string
 	verbose    bool
+	verbosity  int
+	quiet      bool
+	noProgress bool
+	lang       string
+	noEmoji    bool
+	asciiOnly  bool
 	// Version information
 	Version   = "2.7.4"
 	BuildTime = time.Now().Format("2006-01-02")
@@ -50,16 +70,20 @@ var (
 )
 
 func main() {
+	// Version enregistrée dans l'origine des index
+	index.ToolVersion = Version
+
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		<-sigChan
-		fmt.Println("\n⚠️  Interruption detected. Finishing current operation...")
-		fmt.Println("   Press Ctrl+C again to force exit.")
+		fmt.Println(utils.Msg("interrupt.detected"))
+		fmt.Println(utils.Msg("interrupt.force_hint"))
 		<-sigChan
-		fmt.Println("\n🛑 Force exit.")
+		fmt.Println(utils.Msg("interrupt.forced"))
+		utils.CloseOutput()
 		os.Exit(1)
 	}()
 
@@ -76,17 +100,54 @@ Key features:
 - S3 and WebDAV compatible storage
 - S3 Glacier storage class support (Scaleway, AWS)
 - Precise point-in-time restoration
-- Automatic retention policies`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			if verbose {
-				utils.SetLogLevel("debug")
+- Automatic retention policies
+
+Reference documentation (configuration, retention, storage tuning, exit codes)
+is embedded in the binary: run 'bcrdf docs' to list the topics.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if lang != "" {
+				if err := utils.SetLocale(lang); err != nil {
+					return err
+				}
+			}
+			if noEmoji || asciiOnly {
+				utils.EnableOutputFilter(asciiOnly)
+			}
+			if quiet && verbosity > 0 {
+				return fmt.Errorf("%w: --quiet and --verbose are mutually exclusive", utils.ErrConfig)
 			}
+			// -v affiche les étapes détaillées au lieu des barres; -vv ajoute les messages de debug
+			verbose = verbosity > 0
+			utils.SetVerbosity(verbosity, quiet)
+			if noProgress {
+				utils.DisableProgress()
+			}
+			if err := fetchRemoteConfig(cmd); err != nil {
+				return err
+			}
+			if err := checkConfigSchema(cmd); err != nil {
+				return err
+			}
+			applyFaultFlags(cmd)
+			startOperation(cmd)
+			return nil
 		},
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "config.yaml", "Configuration file")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose mode")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "config.yaml", "Configuration file or https:// URL (cached locally)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose mode: -v shows detailed steps and info logs, -vv adds debug logs")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print errors (no progress, no step messages), e.g. for cron")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Language of messages: en or fr (default: BCRDF_LANG, then LC_ALL/LC_MESSAGES/LANG)")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Replace emoji with text tags ([OK], [WARN], [ERROR]) or drop them")
+	rootCmd.PersistentFlags().BoolVar(&asciiOnly, "ascii", false, "Print ASCII only: no emoji, accents transliterated, plain progress bar characters")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Disable progress bars (plain summaries are used automatically when stderr is not a terminal)")
+
+	// Chaos testing flags (hidden), equivalent to the BCRDF_FAULT_* environment variables
+	rootCmd.PersistentFlags().String("fault-upload-error-rate", "", "Probability (0-1) that an upload fails")
+	rootCmd.PersistentFlags().String("fault-slow-ms", "", "Latency added to each storage request, in milliseconds")
+	_ = rootCmd.PersistentFlags().MarkHidden("fault-upload-error-rate")
+	_ = rootCmd.PersistentFlags().MarkHidden("fault-slow-ms")
 
 	// Backup command
 	var backupCmd = &cobra.Command{
@@ -94,40 +155,70 @@ Key features:
 		Short: "Perform a backup",
 		Long:  "Performs an incremental backup based on indexes",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			source, _ := cmd.Flags().GetString("source")
+			sources, _ := cmd.Flags().GetStringArray("source")
 			name, _ := cmd.Flags().GetString("name")
+			errorPolicy, _ := cmd.Flags().GetString("error-policy")
+			confirmAnomaly, _ := cmd.Flags().GetBool("confirm-anomaly")
+			retrainDictionary, _ := cmd.Flags().GetBool("retrain-dictionary")
+			oneFileSystem, _ := cmd.Flags().GetBool("one-file-system")
+			symlinks, _ := cmd.Flags().GetString("symlinks")
 
-			if source == "" {
-				return fmt.Errorf("source path is required")
-			}
 			if name == "" {
 				return fmt.Errorf("backup name is required")
 			}
+			switch symlinks {
+			case "", index.SymlinksStore, index.SymlinksFollow, index.SymlinksSkip:
+			default:
+				return fmt.Errorf("%w: invalid --symlinks %q (expected store, follow or skip)", utils.ErrConfig, symlinks)
+			}
 
 			// Afficher le démarrage de la sauvegarde
-			if !verbose {
-				fmt.Printf("🚀 Starting backup: %s -> %s\n", source, name)
+			// Without --source, the sources come from backup.sources
+			if !verbose && !quiet {
+				source := strings.Join(sources, ", ")
+				if source == "" {
+					source = "backup.sources"
+				}
+				fmt.Println(utils.Msg("backup.starting", source, name))
 			}
 
 			backupManager := backup.NewManager(configFile)
-			err := backupManager.CreateBackup(source, name, verbose)
+			backupManager.SetErrorPolicy(errorPolicy)
+			backupManager.SetConfirmAnomaly(confirmAnomaly)
+			backupManager.SetRetrainDictionary(retrainDictionary)
+			backupManager.SetOneFileSystem(oneFileSystem)
+			backupManager.SetSymlinkPolicy(symlinks)
+			if cmd.Flags().Changed("priority") {
+				priority, _ := cmd.Flags().GetInt("priority")
+				backupManager.SetJobPriority(priority)
+			}
+			wait, _ := cmd.Flags().GetBool("wait")
+			backupManager.SetWaitForLock(wait)
+			err := backupManager.CreateBackupSources(sources, name, verbose)
 
 			// Afficher le résultat final
-			if !verbose {
+			if !verbose && !quiet {
 				if err != nil {
-					fmt.Printf("\n❌ Backup failed: %v\n", err)
+					fmt.Printf("\n%s\n", utils.Msg("backup.failed", err))
 				} else {
-					fmt.Printf("\n✅ Backup completed successfully!\n")
+					fmt.Printf("\n%s\n", utils.Msg("backup.succeeded"))
 				}
 			}
 
 			return err
 		},
 	}
-	backupCmd.Flags().StringP("source", "s", "", "Source path to backup")
+	backupCmd.Flags().StringArrayP("source", "s", nil, "Source path to backup, repeatable for several sources in one index (default: backup.sources)")
 	backupCmd.Flags().StringP("name", "n", "", "Backup name")
-	_ = backupCmd.MarkFlagRequired("source")
+	backupCmd.Flags().String("error-policy", "", "On file errors: fail, continue (record failures in index) or threshold=N% (default from config, else continue)")
+	backupCmd.Flags().Bool("confirm-anomaly", false, "Proceed even if an abnormal change rate is detected (anomaly_guard: block)")
+	backupCmd.Flags().Bool("wait", false, "If a backup of the same name is already running on this host, wait for it instead of failing (exit code 6)")
+	backupCmd.Flags().Int("priority", 0, "Job priority when waiting for a slot (max_parallel_jobs); higher runs first (default: job_priority)")
+	backupCmd.Flags().Bool("retrain-dictionary", false, "Train a new compression dictionary for this job (compression_dictionary: true)")
+	backupCmd.Flags().BoolP("one-file-system", "x", false, "Do not descend into other mounted filesystems (default: one_file_system)")
+	backupCmd.Flags().String("symlinks", "", "Symlinks: store (as links), follow (back up targets, loops detected) or skip (default: symlinks, else store)")
 	_ = backupCmd.MarkFlagRequired("name")
+	_ = backupCmd.RegisterFlagCompletionFunc("name", completeBackupNames)
 
 	// Restore command
 	var restoreCmd = &cobra.Command{
@@ -137,6 +228,46 @@ Key features:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			backupID, _ := cmd.Flags().GetString("backup-id")
 			destination, _ := cmd.Flags().GetString("destination")
+			unicodeForm, _ := cmd.Flags().GetString("unicode-form")
+			includes, _ := cmd.Flags().GetStringSlice("include")
+			conflict, _ := cmd.Flags().GetString("conflict")
+			reportOnly, _ := cmd.Flags().GetBool("report-only")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			nice, _ := cmd.Flags().GetInt("nice")
+			ioPriority, _ := cmd.Flags().GetString("io-priority")
+			rateLimit, _ := cmd.Flags().GetString("rate-limit")
+			fromShare, _ := cmd.Flags().GetString("from-share")
+			keyFile, _ := cmd.Flags().GetString("key-file")
+			identityFile, _ := cmd.Flags().GetString("identity")
+
+			restoreManager := restore.NewManager(configFile)
+			if fromShare != "" {
+				bundle, err := share.Load(fromShare)
+				if err != nil {
+					return err
+				}
+				if err := applyShare(restoreManager, bundle, keyFile, identityFile); err != nil {
+					return err
+				}
+				if backupID == "" {
+					backupID = bundle.BackupID
+				}
+			}
+			if err := restoreManager.SetUnicodeForm(unicodeForm); err != nil {
+				return err
+			}
+			if err := restoreManager.SetThrottle(nice, ioPriority, rateLimit); err != nil {
+				return err
+			}
+			if interactive {
+				return restoreManager.RunInteractive(verbose)
+			}
+			if err := restoreManager.SetPathFilters(includes); err != nil {
+				return err
+			}
+			if err := restoreManager.SetConflictPolicy(conflict); err != nil {
+				return err
+			}
 
 			if backupID == "" {
 				return fmt.Errorf("backup ID is required")
@@ -145,20 +276,29 @@ Key features:
 				return fmt.Errorf("destination path is required")
 			}
 
+			// Conflict report only: nothing is downloaded or written
+			if reportOnly {
+				report, err := restoreManager.ReportConflicts(backupID, destination)
+				if err != nil {
+					return err
+				}
+				restore.PrintConflictReport(report)
+				return nil
+			}
+
 			// Afficher le démarrage de la restauration
-			if !verbose {
-				fmt.Printf("🔄 Starting restore: %s -> %s\n", backupID, destination)
+			if !verbose && !quiet {
+				fmt.Println(utils.Msg("restore.starting", backupID, destination))
 			}
 
-			restoreManager := restore.NewManager(configFile)
 			err := restoreManager.RestoreBackup(backupID, destination, verbose)
 
 			// Afficher le résultat final
-			if !verbose {
+			if !verbose && !quiet {
 				if err != nil {
-					fmt.Printf("\n❌ Restore failed: %v\n", err)
+					fmt.Printf("\n%s\n", utils.Msg("restore.failed", err))
 				} else {
-					fmt.Printf("\n✅ Restore completed successfully!\n")
+					fmt.Printf("\n%s\n", utils.Msg("restore.succeeded"))
 				}
 			}
 
@@ -167,14 +307,25 @@ Key features:
 	}
 	restoreCmd.Flags().StringP("backup-id", "b", "", "Backup ID to restore")
 	restoreCmd.Flags().StringP("destination", "d", "", "Destination path")
-	_ = restoreCmd.MarkFlagRequired("backup-id")
-	_ = restoreCmd.MarkFlagRequired("destination")
+	restoreCmd.Flags().Int("nice", -1, "CPU nice 0-19 for this restore (default: restore_nice)")
+	restoreCmd.Flags().String("io-priority", "", "I/O priority: normal, low or idle (default: restore_io_priority)")
+	restoreCmd.Flags().String("rate-limit", "", "Download rate limit per second, e.g. 20MB (default: restore_rate_limit)")
+	restoreCmd.Flags().String("unicode-form", "original", "Unicode normalization of restored paths: original, nfc (Linux/Windows) or nfd")
+	restoreCmd.Flags().String("from-share", "", "Restore from a share file created by 'bcrdf share' (no configuration or storage credentials needed)")
+	restoreCmd.Flags().String("key-file", "", "File containing the encryption key, with --from-share (default: BCRDF_ENCRYPTION_KEY)")
+	restoreCmd.Flags().String("identity", "", "age identity file for backups encrypted to recipients, with --from-share")
+	restoreCmd.Flags().StringSlice("include", nil, "Restore only these paths, relative to the backup source (file, directory or glob; repeatable)")
+	restoreCmd.Flags().String("conflict", "overwrite", "When a file already exists at the destination: overwrite, skip or newer")
+	restoreCmd.Flags().Bool("report-only", false, "Only report conflicts with the destination (files that would be overwritten, local-newer files) without writing anything")
+	_ = restoreCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
+	restoreCmd.Flags().BoolP("interactive", "i", false, "Guided restore: choose the backup, paths, destination and conflict policy step by step")
 
 	// List command
 	var listCmd = &cobra.Command{
-		Use:   "list [backup-id]",
-		Short: "List backups",
-		Long:  "Shows the list of available backups or details of a specific backup",
+		Use:               "list [backup-id]",
+		Short:             "List backups",
+		Long:              "Shows the list of available backups or details of a specific backup",
+		ValidArgsFunction: completeBackupIDArg,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			indexManager := index.NewManager(configFile)
 
@@ -201,20 +352,20 @@ Key features:
 			}
 
 			// Afficher le démarrage de la suppression
-			if !verbose {
-				fmt.Printf("🗑️  Starting deletion of backup: %s\n", backupID)
-				fmt.Printf("📊 Progress will be displayed below:\n\n")
+			if !verbose && !quiet {
+				fmt.Println(utils.Msg("delete.starting", backupID))
+				fmt.Printf("%s\n\n", utils.Msg("progress.below"))
 			}
 
 			backupManager := backup.NewManager(configFile)
 			err := backupManager.DeleteBackup(backupID)
 
 			// Afficher le résultat final
-			if !verbose {
+			if !verbose && !quiet {
 				if err != nil {
-					fmt.Printf("\n❌ Deletion failed: %v\n", err)
+					fmt.Printf("\n%s\n", utils.Msg("delete.failed", err))
 				} else {
-					fmt.Printf("\n✅ Backup deleted successfully!\n")
+					fmt.Printf("\n%s\n", utils.Msg("delete.succeeded"))
 				}
 			}
 
@@ -223,66 +374,43 @@ Key features:
 	}
 	deleteCmd.Flags().StringP("backup-id", "b", "", "Backup ID to delete")
 	_ = deleteCmd.MarkFlagRequired("backup-id")
+	_ = deleteCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
 
 	// Info command
 	var infoCmd = &cobra.Command{
-		Use:   "info",
-		Short: "Show information about algorithms",
-		Long:  "Shows information about available encryption algorithms",
+		Use:   "info <backup-id>",
+		Short: "Show the metadata of a backup",
+		Long: `Shows the metadata of a backup: source and host, bcrdf and index versions, file statistics,
+storage requests and its position in its series (previous and next backups, legal hold).
+backup-id can be latest (with --name, the latest backup of that name).
+
+Encryption algorithms and checksum modes are described in bcrdf docs crypto.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("\n🔐 Supported encryption algorithms:\n")
-			fmt.Printf("%s\n", strings.Repeat("-", 60))
-
-			fmt.Printf("\n📋 AES-256-GCM:\n")
-			fmt.Printf("  • Algorithm: AES-256 in GCM mode\n")
-			fmt.Printf("  • Security: Very high (NIST standard)\n")
-			fmt.Printf("  • Performance: Excellent (hardware acceleration)\n")
-			fmt.Printf("  • Key size: 32 bytes\n")
-			fmt.Printf("  • Nonce: 12 bytes\n")
-			fmt.Printf("  • Authentication tag: 16 bytes\n")
-
-			fmt.Printf("\n📋 XChaCha20-Poly1305:\n")
-			fmt.Printf("  • Algorithm: ChaCha20 with Poly1305\n")
-			fmt.Printf("  • Security: Very high (RFC 8439)\n")
-			fmt.Printf("  • Performance: Excellent (software optimized)\n")
-			fmt.Printf("  • Key size: 32 bytes\n")
-			fmt.Printf("  • Nonce: 24 bytes\n")
-			fmt.Printf("  • Authentication tag: 16 bytes\n")
-
-			fmt.Printf("\n💡 Recommendations:\n")
-			fmt.Printf("  • AES-256-GCM: Ideal for systems with hardware acceleration\n")
-			fmt.Printf("  • XChaCha20-Poly1305: Ideal for systems without AES acceleration\n")
-			fmt.Printf("  • Both algorithms provide equivalent security\n\n")
-
-			fmt.Printf("🔍 Checksum modes for index creation:\n")
-			fmt.Printf("%s\n", strings.Repeat("-", 60))
-
-			fmt.Printf("\n📋 full:\n")
-			fmt.Printf("  • Method: SHA256 of entire file content\n")
-			fmt.Printf("  • Security: Maximum (detects any change)\n")
-			fmt.Printf("  • Speed: Slow (reads all files completely)\n")
-			fmt.Printf("  • Use case: Critical data, small datasets\n")
-
-			fmt.Printf("\n📋 fast (recommended):\n")
-			fmt.Printf("  • Method: SHA256 of metadata + first/last 8KB\n")
-			fmt.Printf("  • Security: Very high (detects 99.9%% of changes)\n")
-			fmt.Printf("  • Speed: Fast (reads only file samples)\n")
-			fmt.Printf("  • Use case: Most backup scenarios\n")
-
-			fmt.Printf("\n📋 metadata:\n")
-			fmt.Printf("  • Method: SHA256 of path + size + date + permissions\n")
-			fmt.Printf("  • Security: Good (detects file replacement/modification)\n")
-			fmt.Printf("  • Speed: Very fast (no file content read)\n")
-			fmt.Printf("  • Use case: Large datasets, quick incremental backups\n")
-
-			fmt.Printf("\n💡 Performance comparison:\n")
-			fmt.Printf("  • metadata: ~10x faster than full\n")
-			fmt.Printf("  • fast: ~5x faster than full, same reliability\n")
-			fmt.Printf("  • full: Slowest but most thorough\n\n")
+			if len(args) == 0 {
+				return fmt.Errorf("%w: backup ID required (algorithm information moved to bcrdf docs crypto)", utils.ErrConfig)
+			}
+			name, _ := cmd.Flags().GetString("name")
 
+			indexMgr := index.NewManager(configFile)
+			backupID := args[0]
+			if backupID == index.LatestBackup {
+				latest, err := indexMgr.LatestBackupID(name)
+				if err != nil {
+					return err
+				}
+				backupID = latest
+			}
+			info, err := indexMgr.LoadBackupInfo(backupID)
+			if err != nil {
+				return err
+			}
+			index.PrintBackupInfo(info)
 			return nil
 		},
 	}
+	infoCmd.Flags().StringP("name", "n", "", "With latest, only consider backups of this name")
 
 	// Init command
 	var initCmd = &cobra.Command{
@@ -299,11 +427,16 @@ Key features:
 			force, _ := cmd.Flags().GetBool("force")
 			test, _ := cmd.Flags().GetBool("test")
 			storageType, _ := cmd.Flags().GetString("storage")
+			preset, _ := cmd.Flags().GetString("preset")
 
 			if test {
 				return runTestConfig(configPath, verbose)
 			}
 
+			if preset != "" {
+				return runPresetInit(configPath, preset, force, verbose)
+			}
+
 			return runInit(configPath, interactive, force, storageType, verbose)
 		},
 	}
@@ -311,6 +444,8 @@ Key features:
 	initCmd.Flags().BoolP("force", "f", false, "Force overwrite of existing configuration file")
 	initCmd.Flags().BoolP("test", "t", false, "Test an existing configuration")
 	initCmd.Flags().StringP("storage", "s", "s3", "Storage type (s3, webdav)")
+	initCmd.Flags().String("preset", "", "Provider preset ("+strings.Join(validator.PresetNames(), ", ")+"): prefills endpoint, region and addressing, then tests the connection")
+	_ = initCmd.RegisterFlagCompletionFunc("preset", completePresets)
 
 	// Version command
 	versionCmd := &cobra.Command{
@@ -326,22 +461,29 @@ Key features:
 	var updateCmd = &cobra.Command{
 		Use:   "update",
 		Short: "Check for and install updates",
-		Long:  "Checks for newer versions on GitHub and installs them automatically",
+		Long:  "Checks for newer versions on GitHub and installs them automatically.\nOn machines without GitHub access, install a release archive copied locally with --from-file.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			checkOnly, _ := cmd.Flags().GetBool("check")
 			force, _ := cmd.Flags().GetBool("force")
 			autoRestart, _ := cmd.Flags().GetBool("auto-restart")
 
+			fromFile, _ := cmd.Flags().GetString("from-file")
+
 			if checkOnly {
 				return checkForUpdates(verbose)
 			}
 
+			if fromFile != "" {
+				return performUpdateFromFile(fromFile, verbose, force, autoRestart)
+			}
+
 			return performUpdate(verbose, force, autoRestart)
 		},
 	}
 	updateCmd.Flags().BoolP("check", "k", false, "Only check for updates without installing")
 	updateCmd.Flags().BoolP("force", "f", false, "Force update even if current version is latest")
 	updateCmd.Flags().BoolP("auto-restart", "r", false, "Automatically restart BCRDF after update")
+	updateCmd.Flags().String("from-file", "", "Install from a local release archive (e.g. bcrdf-linux-x64.tar.gz) instead of downloading")
 
 	// Retention command
 	var retentionCmd = &cobra.Command{
@@ -353,19 +495,19 @@ Key features:
 			apply, _ := cmd.Flags().GetBool("apply")
 
 			// Afficher le démarrage de la gestion de rétention
-			if !verbose && apply {
-				fmt.Printf("🧹 Starting retention policy management\n")
-				fmt.Printf("📊 Progress will be displayed below:\n\n")
+			if !verbose && !quiet && apply {
+				fmt.Println(utils.Msg("retention.starting"))
+				fmt.Printf("%s\n\n", utils.Msg("progress.below"))
 			}
 
 			err := runRetention(configFile, info, apply, verbose)
 
 			// Afficher le résultat final
-			if !verbose && apply {
+			if !verbose && !quiet && apply {
 				if err != nil {
-					fmt.Printf("\n❌ Retention policy failed: %v\n", err)
+					fmt.Printf("\n%s\n", utils.Msg("retention.failed", err))
 				} else {
-					fmt.Printf("\n✅ Retention policy applied successfully!\n")
+					fmt.Printf("\n%s\n", utils.Msg("retention.succeeded"))
 				}
 			}
 
@@ -383,11 +525,121 @@ Key features:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			testRestore, _ := cmd.Flags().GetBool("test-restore")
 			fastMode, _ := cmd.Flags().GetBool("fast")
-			return runHealth(configFile, testRestore, verbose, fastMode)
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			return runHealth(configFile, testRestore, verbose, fastMode, concurrency)
 		},
 	}
-	healthCmd.Flags().BoolP("test-restore", "t", false, "Test restore functionality on sample files")
+	healthCmd.Flags().BoolP("test-restore", "t", false, "Restore sample files into a temporary directory and verify their checksums")
 	healthCmd.Flags().BoolP("fast", "f", false, "Fast mode: check only a random sample of files")
+	healthCmd.Flags().Int("concurrency", 0, "Number of files checked in parallel (default: max_workers)")
+
+	// Status command
+	var statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the last runs and pending operations",
+		Long:  "Shows the last run of each backup, repository reachability and interrupted backups, from the local state database (state_db)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(configFile, verbose)
+		},
+	}
+
+	// Catalog and find commands
+	var catalogCmd = &cobra.Command{
+		Use:   "catalog",
+		Short: "Manage the file catalog of all backups",
+	}
+	var catalogSyncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Add new backups to the file catalog and remove deleted ones",
+		Long: `Loads the index of every backup missing from the catalog of the local state database
+(state_db) and removes the backups deleted from the repository. Run it from cron on a support
+host, or set backup.catalog: true to update the catalog after each backup.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCatalogSync(configFile, verbose)
+		},
+	}
+	catalogCmd.AddCommand(catalogSyncCmd)
+
+	var findCmd = &cobra.Command{
+		Use:   "find <pattern>",
+		Short: "Find which backups hold a file, from the file catalog",
+		Long: `Searches the file catalog (bcrdf catalog sync) without loading any index. The pattern matches
+any part of the path, case-insensitively; a pattern with *, ? or [ is a glob on the whole path
+(case-sensitive), e.g. "*/invoices/*.pdf". Results go from the newest backup to the oldest.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return runFind(configFile, args[0], name, limit, asJSON)
+		},
+	}
+	findCmd.Flags().StringP("name", "n", "", "Only search backups of this name")
+	findCmd.Flags().Int("limit", 100, "Maximum number of results, 0 = all")
+	findCmd.Flags().Bool("json", false, "Print the results as JSON")
+
+	// Legal hold commands
+	var holdCmd = &cobra.Command{
+		Use:   "hold",
+		Short: "Manage legal holds on path patterns",
+		Long: `A legal hold blocks delete and retention (including max_total_size) for every backup holding
+a file matching its pattern, until the hold is removed. Patterns are relative to the backup root,
+e.g. "finance/**"; a pattern without '/' matches the file name at any depth. Holds are stored in
+the repository, so they apply to every host using it.`,
+	}
+	var holdAddCmd = &cobra.Command{
+		Use:   "add <pattern>",
+		Short: "Place a legal hold on a path pattern",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reason, _ := cmd.Flags().GetString("reason")
+			return runHoldAdd(configFile, args[0], reason)
+		},
+	}
+	holdAddCmd.Flags().String("reason", "", "Reason of the hold (case, ticket...)")
+	var holdListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the legal holds of the repository",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHoldList(configFile)
+		},
+	}
+	var holdRemoveCmd = &cobra.Command{
+		Use:   "remove <pattern>",
+		Short: "Lift the legal hold on a path pattern",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHoldRemove(configFile, args[0])
+		},
+	}
+	holdCmd.AddCommand(holdAddCmd, holdListCmd, holdRemoveCmd)
+
+	// Restore audit commands
+	var auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Read and verify the restore audit log",
+		Long: `With backup.restore_audit: true, every restore and sync appends a record to the audit log of
+the repository: user, host, backup, selected paths, destination, files restored and checksum
+results. Records are encrypted and chained by hash, so an altered, removed or inserted record
+breaks the chain reported by 'bcrdf audit verify'.`,
+	}
+	var auditListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the recorded restores, oldest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return runAuditList(configFile, asJSON)
+		},
+	}
+	auditListCmd.Flags().Bool("json", false, "Print the records as JSON")
+	var auditVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Check every audit record and the hash chain (exit code 5 if broken)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuditVerify(configFile)
+		},
+	}
+	auditCmd.AddCommand(auditListCmd, auditVerifyCmd)
 
 	// Clean command
 	var cleanCmd = &cobra.Command{
@@ -401,16 +653,16 @@ Key features:
 			removeOrphaned, _ := cmd.Flags().GetBool("remove-orphaned")
 
 			// Afficher le démarrage du nettoyage
-			if !verbose {
+			if !verbose && !quiet {
 				if allBackups {
-					fmt.Printf("🧹 Starting cleanup of all backups\n")
+					fmt.Println(utils.Msg("clean.starting_all"))
 				} else {
-					fmt.Printf("🧹 Starting cleanup of backup: %s\n", backupID)
+					fmt.Println(utils.Msg("clean.starting", backupID))
 				}
 				if dryRun {
-					fmt.Printf("🔍 Dry run mode - no files will be deleted\n")
+					fmt.Println(utils.Msg("clean.dry_run"))
 				}
-				fmt.Printf("📊 Progress will be displayed below:\n\n")
+				fmt.Printf("%s\n\n", utils.Msg("progress.below"))
 			}
 
 			indexManager := index.NewManager(configFile)
@@ -428,11 +680,11 @@ Key features:
 			}
 
 			// Afficher le résultat final
-			if !verbose {
+			if !verbose && !quiet {
 				if err != nil {
-					fmt.Printf("\n❌ Cleanup failed: %v\n", err)
+					fmt.Printf("\n%s\n", utils.Msg("clean.failed", err))
 				} else {
-					fmt.Printf("\n✅ Cleanup completed successfully!\n")
+					fmt.Printf("\n%s\n", utils.Msg("clean.succeeded"))
 				}
 			}
 
@@ -440,6 +692,7 @@ Key features:
 		},
 	}
 	cleanCmd.Flags().StringP("backup-id", "b", "", "Backup ID to clean (required when not using --all)")
+	_ = cleanCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
 	cleanCmd.Flags().BoolP("dry-run", "d", false, "Dry run mode (show what would be deleted without actually deleting)")
 	cleanCmd.Flags().BoolP("all", "a", false, "Clean all backups and remove orphaned ones without index")
 	cleanCmd.Flags().BoolP("remove-orphaned", "r", false, "Remove orphaned backups that have no index (use with --all)")
@@ -455,6 +708,516 @@ Key features:
 		},
 	}
 
+	// Migrate command
+	var migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade repository to the latest format",
+		Long:  "Upgrades older repository layouts (plain JSON indexes, old chunk naming) to the current format in place",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			rollbackID, _ := cmd.Flags().GetString("rollback")
+			return runMigrate(configFile, dryRun, rollbackID, verbose)
+		},
+	}
+	migrateCmd.Flags().BoolP("dry-run", "d", false, "Show the migration plan without modifying the repository")
+	migrateCmd.Flags().StringP("rollback", "r", "", "Roll back a previous migration by its ID")
+
+	// GC command
+	var gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Remove data objects no longer referenced by any backup",
+		Long:  "Mark-and-sweep garbage collection: loads every index, then deletes only the data objects that no backup references",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			grace, _ := cmd.Flags().GetDuration("grace")
+			return runGC(configFile, dryRun, grace, verbose)
+		},
+	}
+	gcCmd.Flags().BoolP("dry-run", "d", false, "List unreferenced objects without deleting them")
+	gcCmd.Flags().Duration("grace", gc.DefaultGracePeriod, "Keep unreferenced objects younger than this (protects running backups)")
+
+	// Bench command
+	var benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark storage throughput and latency",
+		Long: `Uploads and downloads synthetic objects of several sizes under bench/ with varying
+concurrency, reports throughput and latency per configuration, then recommends
+backup.max_workers and backup.chunk_size for this link and provider. The test objects
+are deleted at the end.
+
+With --compression, samples files from --source instead, measures ratio and speed of
+every compression level and suggests compression_level and compression_adaptive for
+this job (--apply writes them to the configuration file).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if compressionMode, _ := cmd.Flags().GetBool("compression"); compressionMode {
+				source, _ := cmd.Flags().GetString("source")
+				sample, _ := cmd.Flags().GetString("sample")
+				uplink, _ := cmd.Flags().GetString("uplink")
+				apply, _ := cmd.Flags().GetBool("apply")
+				return runCompressionBench(configFile, source, sample, uplink, apply, verbose)
+			}
+			sizes, _ := cmd.Flags().GetStringSlice("sizes")
+			concurrency, _ := cmd.Flags().GetIntSlice("concurrency")
+			budget, _ := cmd.Flags().GetString("budget")
+			return runBench(configFile, sizes, concurrency, budget, verbose)
+		},
+	}
+	benchCmd.Flags().StringSlice("sizes", bench.DefaultSizes, "Object sizes to test")
+	benchCmd.Flags().IntSlice("concurrency", bench.DefaultConcurrency, "Worker counts to test")
+	benchCmd.Flags().String("budget", "64MB", "Data uploaded per configuration")
+	benchCmd.Flags().Bool("compression", false, "Benchmark compression levels on sample files instead of storage")
+	benchCmd.Flags().StringP("source", "s", "", "Source path to sample (with --compression)")
+	benchCmd.Flags().String("sample", "64MB", "Data sampled from the source (with --compression)")
+	benchCmd.Flags().String("uplink", "12MB", "Upload bandwidth per second used to weigh ratio against speed (with --compression)")
+	benchCmd.Flags().Bool("apply", false, "Write the recommended compression settings to the configuration file (with --compression)")
+
+	// Selftest command
+	var selftestCmd = &cobra.Command{
+		Use:   "selftest",
+		Short: "Check crypto, compression, storage, clock and disk space",
+		Long: `Runs a series of environment checks and prints a pass/fail matrix to attach to
+support tickets: encryption round-trips for both algorithms, the configured key,
+compression round-trips, a probe object written, read back and deleted under selftest/,
+the local clock against the storage clock, and free space in the temp and cache
+directories. Every check runs even when an earlier one fails; exits with code 5 when
+any check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return runSelftest(configFile, asJSON, verbose)
+		},
+	}
+	selftestCmd.Flags().Bool("json", false, "Output the check matrix as JSON")
+
+	// Estimate command
+	var estimateCmd = &cobra.Command{
+		Use:   "estimate",
+		Short: "Estimate the size and duration of a first backup",
+		Long: `Walks --source with the backup's skip patterns and max_file_size, compresses a sample
+of files at the configured compression_level and predicts the index size, the volume
+uploaded and the duration of a first full backup. Nothing is uploaded to the backup.
+
+The upload bandwidth comes from --uplink, or is measured with a short storage benchmark
+(a few 8MB objects under bench/, deleted at the end).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, _ := cmd.Flags().GetString("source")
+			sample, _ := cmd.Flags().GetString("sample")
+			uplink, _ := cmd.Flags().GetString("uplink")
+			return runEstimate(configFile, source, sample, uplink, verbose)
+		},
+	}
+	estimateCmd.Flags().StringP("source", "s", "", "Source path to estimate")
+	estimateCmd.Flags().String("sample", "64MB", "Data sampled from the source to measure compression")
+	estimateCmd.Flags().String("uplink", "", "Upload bandwidth per second (e.g. 12MB), measured against the storage when empty")
+
+	// Cost command
+	var costCmd = &cobra.Command{
+		Use:   "cost [backup-id]",
+		Short: "Report storage requests and projected request charges",
+		Long: `Reports PUT/GET/LIST/HEAD/DELETE requests per backup and their projected charges for
+per-request billed providers, with the GET cost of a full restore. Requests are counted
+during each backup and stored in its index; older backups are estimated from their stored
+objects. Suggests chunk size and layout changes that reduce the number of requests.
+Prices are per 1000 requests (default: S3 Standard).`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupID := ""
+			if len(args) == 1 {
+				backupID = args[0]
+			}
+			var pricing cost.Pricing
+			pricing.Put, _ = cmd.Flags().GetFloat64("put-price")
+			pricing.Get, _ = cmd.Flags().GetFloat64("get-price")
+			pricing.List, _ = cmd.Flags().GetFloat64("list-price")
+			pricing.Delete, _ = cmd.Flags().GetFloat64("delete-price")
+			return runCost(configFile, backupID, pricing, verbose)
+		},
+	}
+	costCmd.Flags().Float64("put-price", cost.DefaultPricing.Put, "Price per 1000 PUT requests")
+	costCmd.Flags().Float64("get-price", cost.DefaultPricing.Get, "Price per 1000 GET/HEAD requests")
+	costCmd.Flags().Float64("list-price", cost.DefaultPricing.List, "Price per 1000 LIST requests")
+	costCmd.Flags().Float64("delete-price", cost.DefaultPricing.Delete, "Price per 1000 DELETE requests")
+
+	// Stats command
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Show the data stored by each backup and forecast repository growth",
+		Long: `Lists the data stored by each backup (objects under data/<backup-id>/) and the repository size.
+With --forecast, fits a linear trend on the cumulated stored size over the backup history,
+projects the repository size at 30, 90 and 365 days and estimates when --limit (default:
+retention.max_total_size) will be reached. Retention deletions are not modeled.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			forecast, _ := cmd.Flags().GetBool("forecast")
+			limit, _ := cmd.Flags().GetString("limit")
+			return runStats(configFile, forecast, limit)
+		},
+	}
+	statsCmd.Flags().Bool("forecast", false, "Project repository growth and estimate when the limit is reached")
+	statsCmd.Flags().String("limit", "", "Quota or bucket limit for the forecast (e.g. 2TB), default: retention.max_total_size")
+
+	// Share command
+	var shareCmd = &cobra.Command{
+		Use:   "share <backup-id>",
+		Short: "Create a time-limited share file to restore a backup without storage credentials",
+		Long: `Pre-signs download URLs for the index and data of one backup and writes them to a
+share file (S3 only). A teammate restores it with 'bcrdf restore --from-share <file>'
+without the storage credentials; the encryption key must be sent separately.
+URLs expire after --expires (at most 168h) and give read access to this backup only.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expires, _ := cmd.Flags().GetDuration("expires")
+			output, _ := cmd.Flags().GetString("output")
+			return runShare(configFile, args[0], expires, output, verbose)
+		},
+	}
+	shareCmd.Flags().Duration("expires", 24*time.Hour, "Validity of the share, e.g. 24h (max 168h)")
+	shareCmd.Flags().StringP("output", "o", "", "Share file to write (default: <backup-id>.share.json)")
+
+	// Sync command
+	var syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Keep a directory in line with a backup (warm standby)",
+		Long: `Brings the destination directory in line with a backup: only files missing or
+different from the backup (size and checksum) are downloaded, and files of the destination
+that are not in the backup are removed. Run it after each backup to keep a warm standby.
+Nothing is removed if a file could not be synced.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupID, _ := cmd.Flags().GetString("backup-id")
+			destination, _ := cmd.Flags().GetString("destination")
+			name, _ := cmd.Flags().GetString("name")
+			nice, _ := cmd.Flags().GetInt("nice")
+			ioPriority, _ := cmd.Flags().GetString("io-priority")
+			rateLimit, _ := cmd.Flags().GetString("rate-limit")
+
+			if destination == "" {
+				return fmt.Errorf("destination path is required")
+			}
+			restoreManager := restore.NewManager(configFile)
+			if err := restoreManager.SetThrottle(nice, ioPriority, rateLimit); err != nil {
+				return err
+			}
+			return restoreManager.SyncBackup(backupID, name, destination, verbose)
+		},
+	}
+	syncCmd.Flags().StringP("backup-id", "b", restore.LatestBackup, "Backup ID to sync with, or latest")
+	syncCmd.Flags().StringP("destination", "d", "", "Standby directory to keep in sync")
+	syncCmd.Flags().StringP("name", "n", "", "With latest, only consider backups of this name")
+	syncCmd.Flags().Int("nice", -1, "CPU nice 0-19 (default: restore_nice)")
+	syncCmd.Flags().String("io-priority", "", "I/O priority: normal, low or idle (default: restore_io_priority)")
+	syncCmd.Flags().String("rate-limit", "", "Download rate limit per second, e.g. 20MB (default: restore_rate_limit)")
+	_ = syncCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
+
+	// Export command
+	var exportCmd = &cobra.Command{
+		Use:   "export [directory]",
+		Short: "Export a backup as a plain directory tree or a tar.gz archive",
+		Long: `Exports a backup in a form usable without bcrdf.
+
+With --format hardlink-tree, the backup is materialized as a plain directory tree under
+<directory>/<backup-id>, for users migrating off rsnapshot or feeding tools that expect plain
+files. Files unchanged since the most recent previous export of the same backup name in
+<directory> are hard links to it (same checksum, exported copy left intact): each export is a
+full tree, but only changed files take space and are downloaded. The export is written to
+<backup-id>.partial and renamed once complete. Do not edit exported files in place: hard links
+share their content with the other exports.
+
+With --format tar.gz, the backup (or the paths selected with --include) is streamed into a
+standard tar.gz archive written to --output, or to stdout with --output -. Files are downloaded
+one at a time, so only the largest file needs local space. No directory argument is used.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backupID, _ := cmd.Flags().GetString("backup-id")
+			name, _ := cmd.Flags().GetString("name")
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			includes, _ := cmd.Flags().GetStringSlice("include")
+
+			restoreManager := restore.NewManager(configFile)
+			switch format {
+			case restore.ExportHardlinkTree:
+				if len(args) != 1 {
+					return fmt.Errorf("%w: --format hardlink-tree requires an export directory", utils.ErrConfig)
+				}
+				if output != "" || len(includes) > 0 {
+					return fmt.Errorf("%w: --output and --include only apply to --format tar.gz", utils.ErrConfig)
+				}
+				result, err := restoreManager.ExportHardlinkTree(backupID, name, args[0], verbose)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("✅ Exported to %s: %d files downloaded, %d hard linked", result.Path, result.Restored, result.Linked)
+				if result.Previous != "" {
+					fmt.Printf(" to %s", result.Previous)
+				}
+				fmt.Println()
+				return nil
+			case restore.ExportTarGz:
+				if len(args) != 0 {
+					return fmt.Errorf("%w: --format tar.gz writes to --output, not to a directory", utils.ErrConfig)
+				}
+				if output == "" {
+					return fmt.Errorf("%w: --format tar.gz requires --output (a file, or - for stdout)", utils.ErrConfig)
+				}
+				if output == restore.ExportStdout {
+					// stdout carries the archive: logs and the summary go to stderr
+					utils.LogToStderr()
+				}
+				if err := restoreManager.SetPathFilters(includes); err != nil {
+					return err
+				}
+				result, err := restoreManager.ExportArchive(backupID, name, output, verbose)
+				if err != nil {
+					return err
+				}
+				if result.Path == restore.ExportStdout {
+					fmt.Fprintf(os.Stderr, "✅ Exported %d files to stdout (%.2f MB)\n", result.Restored, float64(result.Bytes)/1024/1024)
+					return nil
+				}
+				fmt.Printf("✅ Exported %d files to %s (%.2f MB)\n", result.Restored, result.Path, float64(result.Bytes)/1024/1024)
+				return nil
+			default:
+				return fmt.Errorf("%w: unsupported export format %q (expected hardlink-tree or tar.gz)", utils.ErrConfig, format)
+			}
+		},
+	}
+	exportCmd.Flags().StringP("backup-id", "b", restore.LatestBackup, "Backup ID to export, or latest")
+	exportCmd.Flags().StringP("name", "n", "", "With latest, only consider backups of this name")
+	exportCmd.Flags().String("format", restore.ExportHardlinkTree, "Export format: hardlink-tree or tar.gz")
+	exportCmd.Flags().StringP("output", "o", "", "With tar.gz, archive file to write, or - for stdout")
+	exportCmd.Flags().StringSlice("include", nil, "With tar.gz, export only these paths, relative to the backup source (file, directory or glob; repeatable)")
+	_ = exportCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
+
+	// Import command
+	var importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import an archive or a directory as a backup",
+		Long: `Ingests an external archive (.tar, .tar.gz, .tgz or .zip) or directory into the repository
+as a full backup with its own index, to consolidate historical backups made with other tools.
+
+An archive is extracted to a temporary directory (backup.temp_dir) with the dates and permissions
+of its entries, then backed up like a directory. --date sets the backup timestamp (default: now),
+so that an old dump sorts with the other backups of its name. The import never depends on a
+previous backup and does not apply the retention policy.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, _ := cmd.Flags().GetString("source")
+			name, _ := cmd.Flags().GetString("name")
+			date, _ := cmd.Flags().GetString("date")
+
+			createdAt, err := parseImportDate(date)
+			if err != nil {
+				return err
+			}
+			backupManager := backup.NewManager(configFile)
+			if err := backupManager.ImportBackup(source, name, createdAt, verbose); err != nil {
+				return err
+			}
+			if !quiet {
+				fmt.Printf("✅ Imported %s as backup %s\n", source, name)
+			}
+			return nil
+		},
+	}
+	importCmd.Flags().StringP("source", "s", "", "Archive (.tar, .tar.gz, .tgz, .zip) or directory to import")
+	importCmd.Flags().StringP("name", "n", "", "Backup name")
+	importCmd.Flags().String("date", "", "Backup date, YYYY-MM-DD or RFC 3339 (default: now)")
+	_ = importCmd.MarkFlagRequired("source")
+	_ = importCmd.MarkFlagRequired("name")
+	_ = importCmd.RegisterFlagCompletionFunc("name", completeBackupNames)
+
+	// Verify command
+	var verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Compare a backup with the live source",
+		Long: `Indexes the source directory (same exclusions and checksum_mode as a backup, nothing
+uploaded) and compares it with a backup: files changed since the backup, files not in the
+backup and backed up files deleted from the source. Run it right before risky maintenance
+to know what a restore would miss. Exits with code 5 when the source has drifted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, _ := cmd.Flags().GetString("against-source")
+			backupID, _ := cmd.Flags().GetString("backup-id")
+			name, _ := cmd.Flags().GetString("name")
+			if source == "" {
+				return fmt.Errorf("%w: --against-source is required", utils.ErrConfig)
+			}
+			return runVerifySource(configFile, backupID, name, source, verbose)
+		},
+	}
+	verifyCmd.Flags().String("against-source", "", "Live source directory to compare with the backup")
+	verifyCmd.Flags().StringP("backup-id", "b", index.LatestBackup, "Backup ID to compare, or latest")
+	verifyCmd.Flags().StringP("name", "n", "", "With latest, only consider backups of this name")
+	_ = verifyCmd.RegisterFlagCompletionFunc("backup-id", completeBackupIDs)
+
+	// Repair command
+	var repairCmd = &cobra.Command{
+		Use:   "repair <backup-id>",
+		Short: "Repair damaged objects of a backup from its parity objects or a replica repository",
+		Long: `Without --from, checks the objects protected by the backup's parity (backup.parity) against
+their checksums and reconstructs missing or corrupt ones from the other objects of their
+stripe, up to the number of parity objects per stripe.
+
+With --from, checks every data object of the backup (present, decrypts, chunk checksums) and
+replaces missing or corrupt objects with their copy from a replica repository: an identical
+copy kept by bucket replication or a sync tool, encrypted with the same key, described by
+its own configuration file. Replica copies are verified before being written. An unreadable
+index is also copied from the replica.
+
+Exits with code 4 when some objects cannot be repaired, and with code 5 when --dry-run
+finds damaged objects.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			return runRepair(configFile, from, args[0], dryRun, verbose)
+		},
+	}
+	repairCmd.Flags().String("from", "", "Configuration file of a replica repository (default: reconstruct from parity objects)")
+	repairCmd.Flags().BoolP("dry-run", "d", false, "List damaged objects without writing to the repository")
+
+	// Uninstall command
+	var uninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the binary and local files",
+		Long: `Removes the bcrdf binary, the local state database, the metadata cache and the scripts
+generated by update. With --remove-config the configuration file is removed too, after a
+separate confirmation. Remote repository data is never touched.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removeConfig, _ := cmd.Flags().GetBool("remove-config")
+			yes, _ := cmd.Flags().GetBool("yes")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			return runUninstall(configFile, removeConfig, yes, dryRun, verbose)
+		},
+	}
+	uninstallCmd.Flags().Bool("remove-config", false, "Also remove the configuration file (holds the encryption key)")
+	uninstallCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	uninstallCmd.Flags().BoolP("dry-run", "d", false, "List what would be removed")
+
+	// Completion command
+	var completionCmd = &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate shell completion script",
+		Long: `Generates a completion script for the given shell. Backup IDs and job names are
+completed from the local state database (backup.state_db) when configured, otherwise
+from the index names in the repository.
+
+  bash:       source <(bcrdf completion bash)
+  zsh:        bcrdf completion zsh > "${fpath[1]}/_bcrdf"
+  fish:       bcrdf completion fish > ~/.config/fish/completions/bcrdf.fish
+  powershell: bcrdf completion powershell | Out-String | Invoke-Expression`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return rootCmd.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return rootCmd.GenZshCompletion(os.Stdout)
+			case "fish":
+				return rootCmd.GenFishCompletion(os.Stdout, true)
+			default:
+				return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+		},
+	}
+
+	// Config command
+	var configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+	var configShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration",
+		Long: `Prints the fully resolved configuration bcrdf runs with: included files merged, defaults
+applied and environment overrides (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, BCRDF_ENCRYPTION_KEY,
+BCRDF_ENCRYPTION_ALGO, BCRDF_DELETE_*) taken into account. A --config URL shows its cached copy.
+
+--redact masks the credentials and the encryption key, and the passwords of URLs, so the output
+can be shared when asking why bcrdf uses a given bucket or setting.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			redact, _ := cmd.Flags().GetBool("redact")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return runConfigShow(configFile, redact, asJSON)
+		},
+	}
+	configShowCmd.Flags().Bool("redact", false, "Mask credentials, the encryption key and URL passwords")
+	configShowCmd.Flags().Bool("json", false, "Print the configuration as JSON instead of YAML")
+	configCmd.AddCommand(configShowCmd)
+
+	// Docs command
+	var docsCmd = &cobra.Command{
+		Use:   "docs [topic]",
+		Short: "Show reference documentation",
+		Long: `Shows reference documentation embedded in the binary (configuration schema,
+retention semantics, storage tuning, exit codes). Without a topic, lists the available topics.
+With --man, generates man pages for every command and topic instead.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: docs.Names(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manDir, _ := cmd.Flags().GetString("man")
+			if manDir != "" {
+				if err := docs.GenerateManPages(rootCmd, manDir, Version); err != nil {
+					return err
+				}
+				fmt.Printf("✅ Man pages written to %s (try: man -l %s/bcrdf.1)\n", manDir, manDir)
+				return nil
+			}
+			return runDocs(args)
+		},
+	}
+	docsCmd.Flags().String("man", "", "Generate man pages into this directory")
+
+	// Export manifest command
+	var exportManifestCmd = &cobra.Command{
+		Use:               "export-manifest <backup-id>",
+		Short:             "Export a signed backup manifest",
+		Long:              "Produces a signed, portable manifest (file list, checksums, sizes) for audits and air-gapped verification",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			return runExportManifest(configFile, args[0], output, verbose)
+		},
+	}
+	exportManifestCmd.Flags().StringP("output", "o", "", "Output file (default: <backup-id>.manifest.json)")
+
+	// Import manifest command
+	var importManifestCmd = &cobra.Command{
+		Use:   "import-manifest <manifest-file>",
+		Short: "Verify a backup manifest and an external copy",
+		Long:  "Checks the manifest signature and optionally verifies an external copy of the backup against it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			copyPath, _ := cmd.Flags().GetString("path")
+			return runImportManifest(configFile, args[0], copyPath, verbose)
+		},
+	}
+	importManifestCmd.Flags().StringP("path", "p", "", "Path of the external copy to verify")
+
+	// Report command
+	var reportCmd = &cobra.Command{
+		Use:               "report <backup-id>",
+		Short:             "Show the report uploaded with a backup",
+		Long:              "Downloads the run report stored next to the index (backup.report_upload) and prints it, or writes it with --output",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBackupIDArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			content, err := backup.NewManager(configFile).FetchReport(args[0])
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(content)
+				return err
+			}
+			return os.WriteFile(output, content, 0600)
+		},
+	}
+	reportCmd.Flags().StringP("output", "o", "", "Write the report to this file instead of stdout")
+
 	// Add commands to root
 	rootCmd.AddCommand(backupCmd)
 	rootCmd.AddCommand(restoreCmd)
@@ -465,14 +1228,45 @@ Key features:
 	rootCmd.AddCommand(updateCmd)
 	rootCmd.AddCommand(retentionCmd)
 	rootCmd.AddCommand(healthCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(catalogCmd)
+	rootCmd.AddCommand(findCmd)
+	rootCmd.AddCommand(holdCmd)
+	rootCmd.AddCommand(auditCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(scanCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(selftestCmd)
+	rootCmd.AddCommand(estimateCmd)
+	rootCmd.AddCommand(costCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(uninstallCmd)
+	rootCmd.AddCommand(exportManifestCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(importManifestCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(configCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		if id := utils.OperationID(); id != "" {
+			fmt.Fprintf(os.Stderr, "Error [op %s]: %v\n", id, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		utils.CloseOutput()
+		os.Exit(utils.ExitCode(err))
 	}
+	utils.CloseOutput()
 }
 
 // runInit executes the init command
@@ -495,6 +1289,19 @@ func runInit(configPath string, interactive, force bool, storageType string, ver
 	}
 }
 
+// runPresetInit generates a configuration from a provider preset and tests it right away
+func runPresetInit(configPath, preset string, force, verbose bool) error {
+	if utils.FileExists(configPath) && !force {
+		return fmt.Errorf("file %s already exists. Use --force to overwrite", configPath)
+	}
+
+	if err := validator.GenerateConfigWithPreset(configPath, preset); err != nil {
+		return fmt.Errorf("error generating %s configuration: %w", preset, err)
+	}
+
+	return runTestConfig(configPath, verbose)
+}
+
 // runQuickInit generates a default configuration
 func runQuickInit(configPath, storageType string, verbose bool) error {
 	if !verbose {
@@ -521,85 +1328,626 @@ func runQuickInit(configPath, storageType string, verbose bool) error {
 	return nil
 }
 
-// runInteractiveInit guides the user through configuration
-func runInteractiveInit(configPath, storageType string, verbose bool) error {
-	// Use the new interactive configuration generator
-	if err := validator.GenerateInteractiveConfig(configPath); err != nil {
-		return fmt.Errorf("error in interactive configuration: %w", err)
+// runInteractiveInit guides the user through configuration
+func runInteractiveInit(configPath, storageType string, verbose bool) error {
+	// Use the new interactive configuration generator
+	if err := validator.GenerateInteractiveConfig(configPath); err != nil {
+		return fmt.Errorf("error in interactive configuration: %w", err)
+	}
+
+	return nil
+}
+
+// runTestConfig tests an existing configuration
+func runTestConfig(configPath string, verbose bool) error {
+	if verbose {
+		utils.Info("🧪 Testing configuration: %s", configPath)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("🧪 Testing configuration: %s", configPath))
+	}
+
+	// Check the file against the configuration schema (all problems, with line numbers)
+	if err := validator.ValidateSchemaFile(configPath); err != nil {
+		return err
+	}
+
+	// Load configuration
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	// Create validator
+	configValidator := validator.NewConfigValidator(config)
+
+	// Validate all parameters
+	if err := configValidator.ValidateAll(verbose); err != nil {
+		return err
+	}
+
+	if verbose {
+		utils.Info("🎉 Configuration is valid and functional!")
+		utils.Info("You can now use BCRDF for your backups.")
+	} else {
+		utils.ProgressSuccess("🎉 Configuration is valid and functional!")
+		utils.ProgressInfo("Ready for BCRDF backups")
+	}
+
+	return nil
+}
+
+// showVersion displays version information
+func showVersion() {
+	fmt.Printf("🚀 BCRDF %s\n", Version)
+	fmt.Printf("📦 Build: %s\n", BuildTime)
+	fmt.Printf("🔧 Go: %s\n", GoVersion)
+}
+
+// runRetention executes retention management commands
+func runRetention(configPath string, info, apply, verbose bool) error {
+	// Load configuration
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	// Initialize storage client
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	// Initialize index manager
+	indexMgr := index.NewManager(configPath)
+
+	// Create retention manager
+	retentionMgr := retention.NewManager(config, indexMgr, storageClient)
+
+	if info {
+		return retentionMgr.GetRetentionInfo(verbose)
+	}
+
+	if apply {
+		return retentionMgr.ApplyRetentionPolicy(verbose)
+	}
+
+	return nil
+}
+
+// runMigrate executes the repository migration or its rollback
+func runMigrate(configPath string, dryRun bool, rollbackID string, verbose bool) error {
+	// Load configuration
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	// Initialize storage client
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	migrationMgr := migration.NewManager(config, storageClient)
+
+	if rollbackID != "" {
+		return migrationMgr.Rollback(rollbackID, verbose)
+	}
+
+	return migrationMgr.Migrate(dryRun, verbose)
+}
+
+// runGC removes data objects that are not referenced by any index
+func runGC(configPath string, dryRun bool, grace time.Duration, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	gcMgr := gc.NewManager(config, index.NewManager(configPath), storageClient)
+	result, err := gcMgr.Collect("data/", grace, dryRun, verbose)
+	if result != nil {
+		gc.PrintResult(result, dryRun)
+	}
+	return err
+}
+
+// runBench measures storage throughput and recommends transfer settings
+func runBench(configPath string, sizeValues []string, concurrency []int, budgetValue string, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	sizes, err := bench.ParseSizes(sizeValues)
+	if err != nil {
+		return err
+	}
+	budget, err := utils.ParseSize(budgetValue)
+	if err != nil {
+		return fmt.Errorf("%w: invalid --budget: %w", utils.ErrConfig, err)
+	}
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	benchMgr := bench.NewManager(config, storageClient)
+	report, err := benchMgr.Run(bench.Options{Sizes: sizes, Concurrency: concurrency, Budget: budget}, verbose)
+	if report != nil {
+		bench.PrintReport(report, config)
+	}
+	return err
+}
+
+// runSelftest runs the environment checks; configuration and storage errors are reported as failed checks
+func runSelftest(configPath string, asJSON, verbose bool) error {
+	var storageClient storage.Client
+	config, configErr := utils.LoadConfig(configPath)
+	if configErr == nil {
+		storageClient, configErr = storage.NewStorageClient(config)
+		if configErr != nil {
+			configErr = fmt.Errorf("error initializing storage: %w", configErr)
+		}
+	} else {
+		config = nil
+	}
+
+	report := selftest.NewManager(config, configErr, storageClient).Run(Version, verbose)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		selftest.PrintReport(report)
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%w: %d of %d selftest checks failed", utils.ErrVerificationFailed, failed, len(report.Checks))
+	}
+	return nil
+}
+
+// runCompressionBench measures compression levels on sample files and optionally applies the best one
+func runCompressionBench(configPath, source, sampleValue, uplinkValue string, apply, verbose bool) error {
+	if source == "" {
+		return fmt.Errorf("%w: --source is required with --compression", utils.ErrConfig)
+	}
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	sample, err := utils.ParseSize(sampleValue)
+	if err != nil {
+		return fmt.Errorf("%w: invalid --sample: %w", utils.ErrConfig, err)
+	}
+	uplink, err := utils.ParseSize(uplinkValue)
+	if err != nil {
+		return fmt.Errorf("%w: invalid --uplink: %w", utils.ErrConfig, err)
+	}
+
+	// Pas d'accès au stockage: seule la source est lue
+	benchMgr := bench.NewManager(config, nil)
+	report, err := benchMgr.RunCompression(source, bench.CompressionOptions{SampleBytes: sample, Uplink: float64(uplink)}, verbose)
+	if err != nil {
+		return err
+	}
+	bench.PrintCompressionReport(report, config)
+
+	if apply {
+		if err := utils.UpdateConfigValues(configPath, bench.CompressionSettings(report)); err != nil {
+			return fmt.Errorf("error applying compression settings: %w", err)
+		}
+		utils.ProgressSuccess(fmt.Sprintf("Compression settings written to %s", configPath))
+	}
+	return nil
+}
+
+// runEstimate predicts the index size, upload volume and duration of a first backup of source
+func runEstimate(configPath, source, sampleValue, uplinkValue string, verbose bool) error {
+	if source == "" {
+		return fmt.Errorf("%w: --source is required", utils.ErrConfig)
+	}
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	sample, err := utils.ParseSize(sampleValue)
+	if err != nil {
+		return fmt.Errorf("%w: invalid --sample: %w", utils.ErrConfig, err)
+	}
+	options := estimate.Options{SampleBytes: sample}
+	measured := false
+	if uplinkValue != "" {
+		uplink, err := utils.ParseSize(uplinkValue)
+		if err != nil {
+			return fmt.Errorf("%w: invalid --uplink: %w", utils.ErrConfig, err)
+		}
+		options.Uplink = float64(uplink)
+	} else {
+		utils.ProgressStep("📡 Measuring upload bandwidth")
+		storageClient, err := storage.NewStorageClient(config)
+		if err == nil {
+			options.Uplink, err = estimate.MeasureUplink(config, storageClient)
+		}
+		if err != nil {
+			utils.Warn("Cannot measure upload bandwidth, assuming %s/s (use --uplink): %v", utils.FormatBytes(bench.DefaultUplink), err)
+		} else {
+			measured = true
+		}
+	}
+
+	report, err := estimate.NewManager(config).Run(source, options, verbose)
+	if err != nil {
+		return err
+	}
+	report.UplinkMeasured = measured
+	estimate.PrintReport(report)
+	return nil
+}
+
+// openCatalog opens the local state database holding the file catalog
+func openCatalog(config *utils.Config) (*state.Store, error) {
+	if config.Backup.StateDB == "" {
+		return nil, fmt.Errorf("%w: the file catalog requires backup.state_db to be configured", utils.ErrConfig)
+	}
+	return state.Open(config.Backup.StateDB)
+}
+
+func runCatalogSync(configPath string, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	store, err := openCatalog(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := state.SyncCatalog(store, index.NewManager(configPath), verbose)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📇 Catalog updated: %d backups added, %d removed, %d cataloged\n", len(result.Added), len(result.Removed), result.Total)
+	return nil
+}
+
+func runFind(configPath, pattern, name string, limit int, asJSON bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	store, err := openCatalog(config)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	matches, err := store.SearchCatalog(pattern, name, limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if matches == nil {
+			matches = []state.CatalogMatch{}
+		}
+		return encoder.Encode(matches)
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No file matching %q in the catalog (run 'bcrdf catalog sync' to index new backups)\n", pattern)
+		return nil
+	}
+	fmt.Printf("%-40s %-17s %10s  %s\n", "Backup", "Date", "Size", "Path")
+	for _, match := range matches {
+		size := utils.FormatBytes(match.Size)
+		if match.IsDir {
+			size = "<dir>"
+		}
+		fmt.Printf("%-40s %-17s %10s  %s\n", match.BackupID, match.CreatedAt.Local().Format("2006-01-02 15:04"), size, match.Path)
+	}
+	if limit > 0 && len(matches) == limit {
+		fmt.Printf("(first %d results, raise --limit for more)\n", limit)
+	}
+	return nil
+}
+
+func runHoldAdd(configPath, pattern, reason string) error {
+	hold, err := index.NewManager(configPath).AddHold(pattern, reason)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔒 Legal hold placed on %q\n", hold.Pattern)
+	return nil
+}
+
+func runHoldList(configPath string) error {
+	holds, err := index.NewManager(configPath).ListHolds()
+	if err != nil {
+		return err
+	}
+	if len(holds) == 0 {
+		fmt.Println("No legal hold")
+		return nil
+	}
+	fmt.Printf("%-30s %-17s %-20s %s\n", "Pattern", "Since", "Host", "Reason")
+	for _, hold := range holds {
+		fmt.Printf("%-30s %-17s %-20s %s\n", hold.Pattern, hold.CreatedAt.Local().Format("2006-01-02 15:04"), hold.CreatedBy, hold.Reason)
+	}
+	return nil
+}
+
+func runHoldRemove(configPath, pattern string) error {
+	if err := index.NewManager(configPath).RemoveHold(pattern); err != nil {
+		return err
+	}
+	fmt.Printf("🔓 Legal hold on %q lifted\n", pattern)
+	return nil
+}
+
+func runAuditList(configPath string, asJSON bool) error {
+	records, err := index.NewManager(configPath).ListAudit()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	if len(records) == 0 {
+		fmt.Println("No restore recorded")
+		return nil
+	}
+	for _, record := range records {
+		paths := "all files"
+		if len(record.Paths) > 0 {
+			paths = strings.Join(record.Paths, ", ")
+		}
+		fmt.Printf("#%d %s %s@%s %s %s -> %s (%s)\n", record.Seq, record.Time.Local().Format("2006-01-02 15:04:05"),
+			record.User, record.Hostname, record.Operation, record.BackupID, record.Destination, record.Status)
+		fmt.Printf("   paths: %s; %d restored, %d kept, %d failed, %d checksums verified, %d mismatches\n",
+			paths, record.FilesRestored, record.FilesKept, record.FilesFailed, record.ChecksumsVerified, len(record.ChecksumMismatches))
+		if record.Error != "" {
+			fmt.Printf("   error: %s\n", record.Error)
+		}
+	}
+	return nil
+}
+
+func runAuditVerify(configPath string) error {
+	result, err := index.NewManager(configPath).VerifyAudit()
+	if err != nil {
+		return err
+	}
+	for _, problem := range result.Problems {
+		fmt.Printf("❌ %s\n", problem)
+	}
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%w: %d problems in the restore audit log", utils.ErrVerificationFailed, len(result.Problems))
+	}
+	fmt.Printf("✅ Restore audit log intact: %d records\n", result.Records)
+	if result.HeadHash != "" {
+		fmt.Printf("   Head: %s (keep it outside the repository to detect removal of the latest records)\n", result.HeadHash)
+	}
+	return nil
+}
+
+// runStatus shows the backup status recorded in the local state database
+func runStatus(configPath string, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	if config.Backup.StateDB == "" {
+		return fmt.Errorf("%w: status requires backup.state_db to be configured", utils.ErrConfig)
+	}
+
+	store, err := state.Open(config.Backup.StateDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err == nil {
+		err = storageClient.TestConnectivity()
+	}
+	if verbose && err != nil {
+		utils.Warn("Storage connectivity test failed: %v", err)
+	}
+
+	return store.PrintStatus(err)
+}
+
+// readsConfig reports whether a command loads the configuration file
+func readsConfig(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "init", "version", "update", "uninstall", "completion", "docs", "help",
+		cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return false
+	}
+	// Share restore: the configuration comes from the share file
+	return !(cmd.Name() == "restore" && cmd.Flags().Changed("from-share"))
+}
+
+// fetchRemoteConfig replaces a --config URL by its local cached copy (downloaded, or the last
+// cached copy when the server is unreachable), so centrally managed hosts pick up changes
+func fetchRemoteConfig(cmd *cobra.Command) error {
+	if !utils.IsRemoteConfig(configFile) || !readsConfig(cmd) {
+		return nil
+	}
+	path, err := utils.FetchRemoteConfig(configFile)
+	if err != nil {
+		return err
+	}
+	configFile = path
+	return nil
+}
+
+// checkConfigSchema validates the configuration file before commands that load it,
+// so mistakes are reported with their line instead of failing deep inside an operation
+func checkConfigSchema(cmd *cobra.Command) error {
+	if !readsConfig(cmd) {
+		return nil
+	}
+	if _, err := os.Stat(configFile); err != nil {
+		// Missing file: LoadConfig reports it (or creates the default configuration)
+		return nil
+	}
+	return validator.ValidateSchemaFile(configFile)
+}
+
+// applyFaultFlags exports the hidden fault injection flags to the environment read by the storage layer
+func applyFaultFlags(cmd *cobra.Command) {
+	flags := map[string]string{
+		"fault-upload-error-rate": storage.FaultUploadErrorRateEnv,
+		"fault-slow-ms":           storage.FaultSlowEnv,
+	}
+	for flag, env := range flags {
+		if cmd.Flags().Changed(flag) {
+			value, _ := cmd.Flags().GetString(flag)
+			os.Setenv(env, value)
+		}
+	}
+}
+
+// startOperation assigns the operation ID of runs that read or change the repository
+func startOperation(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "backup", "restore", "sync", "retention", "delete", "clean", "gc", "migrate", "health":
+		id := utils.StartOperation()
+		if verbose {
+			utils.Info("Operation ID: %s", id)
+		}
+	}
+}
+
+// runDocs prints a documentation topic, or the list of topics
+func runDocs(args []string) error {
+	if len(args) == 0 {
+		fmt.Printf("\n📖 Documentation topics (bcrdf docs <topic>):\n\n")
+		for _, topic := range docs.Topics() {
+			fmt.Printf("  %-12s %s\n", topic.Name, topic.Title)
+		}
+		fmt.Printf("\n")
+		return nil
 	}
 
+	topic, err := docs.Lookup(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(topic.Content)
 	return nil
 }
 
-// runTestConfig tests an existing configuration
-func runTestConfig(configPath string, verbose bool) error {
-	if verbose {
-		utils.Info("🧪 Testing configuration: %s", configPath)
-	} else {
-		utils.ProgressStep(fmt.Sprintf("🧪 Testing configuration: %s", configPath))
+// parseImportDate parses the --date of an imported backup (empty: now)
+func parseImportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("%w: invalid --date %q (expected YYYY-MM-DD or RFC 3339)", utils.ErrConfig, value)
+}
 
-	// Load configuration
+// runConfigShow prints the effective configuration as YAML or JSON
+func runConfigShow(configPath string, redact, asJSON bool) error {
 	config, err := utils.LoadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("error loading configuration: %w", err)
+		return err
 	}
-
-	// Create validator
-	configValidator := validator.NewConfigValidator(config)
-
-	// Validate all parameters
-	if err := configValidator.ValidateAll(verbose); err != nil {
+	effective := utils.EffectiveConfig(config, redact)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(effective)
+	}
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(effective); err != nil {
 		return err
 	}
+	return encoder.Close()
+}
 
-	if verbose {
-		utils.Info("🎉 Configuration is valid and functional!")
-		utils.Info("You can now use BCRDF for your backups.")
-	} else {
-		utils.ProgressSuccess("🎉 Configuration is valid and functional!")
-		utils.ProgressInfo("Ready for BCRDF backups")
+// runExportManifest exports the signed manifest of a backup
+func runExportManifest(configPath, backupID, outputPath string, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
 	}
 
-	return nil
-}
-
-// showVersion displays version information
-func showVersion() {
-	fmt.Printf("🚀 BCRDF %s\n", Version)
-	fmt.Printf("📦 Build: %s\n", BuildTime)
-	fmt.Printf("🔧 Go: %s\n", GoVersion)
+	manifestMgr := manifest.NewManager(config, index.NewManager(configPath))
+	return manifestMgr.Export(backupID, outputPath, verbose)
 }
 
-// runRetention executes retention management commands
-func runRetention(configPath string, info, apply, verbose bool) error {
-	// Load configuration
+// runImportManifest verifies a manifest and optionally an external copy
+func runImportManifest(configPath, manifestPath, copyPath string, verbose bool) error {
 	config, err := utils.LoadConfig(configPath)
 	if err != nil {
 		return fmt.Errorf("error loading configuration: %w", err)
 	}
 
-	// Initialize storage client
-	storageClient, err := storage.NewStorageClient(config)
-	if err != nil {
-		return fmt.Errorf("error initializing storage: %w", err)
-	}
+	manifestMgr := manifest.NewManager(config, index.NewManager(configPath))
+	return manifestMgr.Import(manifestPath, copyPath, verbose)
+}
 
-	// Initialize index manager
+// runVerifySource reports the drift between a backup and its live source
+func runVerifySource(configPath, backupID, name, source string, verbose bool) error {
 	indexMgr := index.NewManager(configPath)
+	if backupID == index.LatestBackup {
+		latest, err := indexMgr.LatestBackupID(name)
+		if err != nil {
+			return err
+		}
+		backupID = latest
+	}
 
-	// Create retention manager
-	retentionMgr := retention.NewManager(config, indexMgr, storageClient)
-
-	if info {
-		return retentionMgr.GetRetentionInfo(verbose)
+	report, err := indexMgr.CompareWithSource(backupID, source, verbose)
+	if err != nil {
+		return err
 	}
 
-	if apply {
-		return retentionMgr.ApplyRetentionPolicy(verbose)
+	fmt.Printf("🔍 %s compared with backup %s (%s)\n", source, report.BackupID, report.BackupTime.Format("2006-01-02 15:04:05"))
+	printDrift := func(title string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		fmt.Printf("\n%s (%d):\n", title, len(paths))
+		for i, path := range paths {
+			if i == 20 && !verbose {
+				fmt.Printf("   ... %d more (use -v to list all)\n", len(paths)-i)
+				break
+			}
+			fmt.Printf("   %s\n", path)
+		}
 	}
+	printDrift("✏️  Changed since backup", report.Changed)
+	printDrift("➕ Not in backup", report.NotBackedUp)
+	printDrift("➖ Deleted from source", report.Deleted)
 
-	return nil
+	if !report.HasDrift() {
+		fmt.Println("\n✅ Source matches the backup")
+		return nil
+	}
+	return fmt.Errorf("%w: source has drifted from %s (%d changed, %d not backed up, %d deleted)",
+		utils.ErrVerificationFailed, report.BackupID, len(report.Changed), len(report.NotBackedUp), len(report.Deleted))
 }
 
 // checkForUpdates checks for newer versions on GitHub
@@ -615,7 +1963,7 @@ func checkForUpdates(verbose bool) error {
 	if strings.HasPrefix(currentVersion, "v") {
 		currentVersion = strings.TrimPrefix(currentVersion, "v")
 	}
-	
+
 	// Remove platform suffix (e.g., -linux-x64, -darwin-arm64)
 	if idx := strings.Index(currentVersion, "-"); idx != -1 {
 		currentVersion = currentVersion[:idx]
@@ -663,7 +2011,7 @@ func performUpdate(verbose, force, autoRestart bool) error {
 	if strings.HasPrefix(currentVersion, "v") {
 		currentVersion = strings.TrimPrefix(currentVersion, "v")
 	}
-	
+
 	// Remove platform suffix (e.g., -linux-x64, -darwin-arm64)
 	if idx := strings.Index(currentVersion, "-"); idx != -1 {
 		currentVersion = currentVersion[:idx]
@@ -785,22 +2133,106 @@ func isNewerVersion(latest, current []string) bool {
 	return false
 }
 
-// downloadAndInstallUpdate downloads and installs the update
-func downloadAndInstallUpdate(version string, verbose, autoRestart bool) error {
-	// Determine platform and architecture
-	platform := runtime.GOOS
-	arch := runtime.GOARCH
+// performUpdateFromFile installs a release archive provided locally (air-gapped machines)
+func performUpdateFromFile(archivePath string, verbose, force, autoRestart bool) error {
+	if !utils.FileExists(archivePath) {
+		return fmt.Errorf("archive not found: %s", archivePath)
+	}
 
-	// Map Go arch to GitHub release arch
-	archMap := map[string]string{
-		"amd64": "x64",
-		"arm64": "arm64",
-		"386":   "x86",
+	platform, releaseArch, err := releasePlatform()
+	if err != nil {
+		return err
 	}
 
-	releaseArch, ok := archMap[arch]
-	if !ok {
-		return fmt.Errorf("unsupported architecture: %s", arch)
+	// Release archives are named bcrdf-<platform>-<arch>: refuse another platform unless forced
+	expected := fmt.Sprintf("bcrdf-%s-%s", platform, releaseArch)
+	name := filepath.Base(archivePath)
+	if strings.HasPrefix(name, "bcrdf-") && !strings.HasPrefix(name, expected) && !force {
+		return fmt.Errorf("archive %s does not match this machine (%s), use --force to install anyway", name, expected)
+	}
+
+	fmt.Printf("📦 Installing from local archive: %s\n", archivePath)
+
+	// The extraction directory is removed with the temp area (a deferred update keeps its own copy)
+	temp, err := updateTempArea()
+	if err != nil {
+		return err
+	}
+	defer temp.Close()
+
+	binaryPath, err := extractBinary(temp, archivePath, platform, releaseArch)
+	if err != nil {
+		return fmt.Errorf("error extracting binary: %w", err)
+	}
+
+	// Run the new binary before installing it: catches a wrong architecture or a corrupted archive
+	if err := os.Chmod(binaryPath, 0755); err != nil {
+		return fmt.Errorf("error setting permissions: %w", err)
+	}
+	output, err := exec.Command(binaryPath, "version").Output()
+	if err != nil {
+		return fmt.Errorf("binary from %s does not run on this machine: %w", name, err)
+	}
+	version := archiveVersion(string(output))
+	if verbose {
+		utils.Info("📦 Archive version: %s", version)
+	}
+
+	// Same version check as the online update
+	if !force && version != "" {
+		currentParts := strings.Split(normalizeVersion(Version), ".")
+		if !isNewerVersion(strings.Split(version, "."), currentParts) {
+			fmt.Printf("✅ Archive version %s is not newer than the running version %s\n", version, normalizeVersion(Version))
+			fmt.Printf("💡 Use --force to install it anyway\n")
+			return nil
+		}
+	}
+
+	if err := installBinary(binaryPath, version, verbose); err != nil {
+		if deferredErr, ok := err.(*DeferredUpdateError); ok {
+			fmt.Printf("\n🎯 Update process completed successfully!\n")
+			fmt.Printf("📝 Deferred update script ready: %s\n", deferredErr.ScriptPath)
+			fmt.Printf("🔄 To complete the update, please follow the instructions above.\n")
+			return nil
+		}
+		return fmt.Errorf("error updating: %w", err)
+	}
+
+	fmt.Printf("🎉 Successfully installed version %s from %s!\n", version, name)
+
+	if autoRestart {
+		fmt.Printf("🚀 Auto-restarting BCRDF with new version...\n")
+		return performAutoRestart()
+	}
+	fmt.Printf("🔄 Please restart BCRDF to use the new version\n")
+	return nil
+}
+
+// archiveVersion extracts the version from the output of "bcrdf version"
+func archiveVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if _, after, found := strings.Cut(line, "BCRDF "); found {
+			return normalizeVersion(strings.TrimSpace(after))
+		}
+	}
+	return ""
+}
+
+// normalizeVersion removes the "v" prefix and the platform suffix (e.g., -linux-x64)
+func normalizeVersion(version string) string {
+	version = strings.TrimPrefix(version, "v")
+	if idx := strings.Index(version, "-"); idx != -1 {
+		version = version[:idx]
+	}
+	return version
+}
+
+// downloadAndInstallUpdate downloads and installs the update
+func downloadAndInstallUpdate(version string, verbose, autoRestart bool) error {
+	// Determine platform and architecture
+	platform, releaseArch, err := releasePlatform()
+	if err != nil {
+		return err
 	}
 
 	// Construct download URL with correct extensions
@@ -834,12 +2266,16 @@ func downloadAndInstallUpdate(version string, verbose, autoRestart bool) error {
 		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
 	}
 
-	// Create temporary file
-	tempFile, err := os.CreateTemp("", "bcrdf-update-*")
+	// Create temporary file, removed with the temp area
+	temp, err := updateTempArea()
+	if err != nil {
+		return err
+	}
+	defer temp.Close()
+	tempFile, err := temp.CreateTemp("update-*")
 	if err != nil {
 		return fmt.Errorf("error creating temp file: %w", err)
 	}
-	defer os.Remove(tempFile.Name())
 
 	// Download with progress
 	fileSize := resp.ContentLength
@@ -859,11 +2295,34 @@ func downloadAndInstallUpdate(version string, verbose, autoRestart bool) error {
 	tempFile.Close()
 
 	// Extract the archive to get the binary
-	binaryPath, err := extractBinary(tempFile.Name(), platform, releaseArch)
+	binaryPath, err := extractBinary(temp, tempFile.Name(), platform, releaseArch)
 	if err != nil {
 		return fmt.Errorf("error extracting binary: %w", err)
 	}
 
+	return installBinary(binaryPath, version, verbose)
+}
+
+// releasePlatform returns the platform and architecture names used by release archives
+func releasePlatform() (string, string, error) {
+	// Map Go arch to GitHub release arch
+	archMap := map[string]string{
+		"amd64": "x64",
+		"arm64": "arm64",
+		"386":   "x86",
+	}
+
+	releaseArch, ok := archMap[runtime.GOARCH]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	}
+	return runtime.GOOS, releaseArch, nil
+}
+
+// installBinary replaces the running executable with binaryPath, keeping a backup for rollback
+// and falling back to a deferred update when the executable is busy
+func installBinary(binaryPath, version string, verbose bool) error {
+
 	// Get current executable path
 	execPath, err := os.Executable()
 	if err != nil {
@@ -916,14 +2375,25 @@ func (pw *progressWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-// extractBinary extracts the binary from the downloaded archive
-func extractBinary(archivePath, platform, releaseArch string) (string, error) {
+// updateTempArea opens the managed temp area of an update, under backup.temp_dir when the
+// configuration file is readable (the update does not require one)
+func updateTempArea() (*tempdir.Area, error) {
+	var config *utils.Config
+	if utils.FileExists(configFile) {
+		if loaded, err := utils.LoadConfig(configFile); err == nil {
+			config = loaded
+		}
+	}
+	return tempdir.Open(config)
+}
+
+// extractBinary extracts the binary from the downloaded archive into the temp area
+func extractBinary(temp *tempdir.Area, archivePath, platform, releaseArch string) (string, error) {
 	// Create temporary directory for extraction
-	tempDir, err := os.MkdirTemp("", "bcrdf-extract-*")
+	tempDir, err := temp.MkdirTemp("extract-*")
 	if err != nil {
 		return "", fmt.Errorf("error creating temp directory: %w", err)
 	}
-	// Note: tempDir will be cleaned up by the caller after copying the binary
 
 	var binaryPath string
 	switch platform {
@@ -1156,7 +2626,7 @@ func copyFile(src, dst string) error {
 	return err
 }
 
-func runHealth(configPath string, testRestore, verbose, fastMode bool) error {
+func runHealth(configPath string, testRestore, verbose, fastMode bool, concurrency int) error {
 	// Load configuration
 	config, err := utils.LoadConfig(configPath)
 	if err != nil {
@@ -1172,8 +2642,14 @@ func runHealth(configPath string, testRestore, verbose, fastMode bool) error {
 	// Initialize index manager
 	indexMgr := index.NewManager(configPath)
 
+	// Les vérifications tournent souvent sur des hôtes de production: même priorité que les restaurations
+	if err := utils.LowerPriority(config.Backup.RestoreNice, config.Backup.RestoreIOPriority); err != nil {
+		utils.Warn("Health check priority not applied: %v", err)
+	}
+
 	// Create health manager
 	healthMgr := health.NewManager(config, indexMgr, storageClient)
+	healthMgr.SetConcurrency(concurrency)
 
 	report, err := healthMgr.CheckHealth(verbose, testRestore, fastMode)
 	if err != nil {
@@ -1181,6 +2657,10 @@ func runHealth(configPath string, testRestore, verbose, fastMode bool) error {
 	}
 
 	healthMgr.PrintReport(report, verbose)
+
+	if report.UnhealthyBackups > 0 {
+		return fmt.Errorf("%w: %d of %d backups are unhealthy", utils.ErrVerificationFailed, report.UnhealthyBackups, report.TotalBackups)
+	}
 	return nil
 }
 
@@ -1188,6 +2668,12 @@ func runHealth(configPath string, testRestore, verbose, fastMode bool) error {
 func handleDeferredUpdate(binaryPath, backupPath, execPath, version string, verbose bool) error {
 	fmt.Printf("\n🔄 Binary is currently in use, implementing deferred update strategy...\n")
 
+	// The temp area is removed when this run exits: the script needs its own copy of the binary
+	binaryPath, err := keepForDeferredUpdate(binaryPath)
+	if err != nil {
+		return err
+	}
+
 	// Create a deferred update script
 	updateScript := createDeferredUpdateScript(binaryPath, backupPath, execPath, version)
 
@@ -1210,6 +2696,25 @@ func handleDeferredUpdate(binaryPath, backupPath, execPath, version string, verb
 	}
 }
 
+// keepForDeferredUpdate copies the new binary out of the temp area, into a directory removed by
+// the deferred update script
+func keepForDeferredUpdate(binaryPath string) (string, error) {
+	dir, err := os.MkdirTemp("", "bcrdf-update-")
+	if err != nil {
+		return "", fmt.Errorf("error creating deferred update directory: %w", err)
+	}
+	kept := filepath.Join(dir, filepath.Base(binaryPath))
+	if err := copyFile(binaryPath, kept); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error keeping the new binary for the deferred update: %w", err)
+	}
+	if err := os.Chmod(kept, 0755); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error setting permissions: %w", err)
+	}
+	return kept, nil
+}
+
 // createDeferredUpdateScript creates a script to complete the update later
 func createDeferredUpdateScript(binaryPath, backupPath, execPath, version string) string {
 	// Create script content
@@ -1318,6 +2823,7 @@ func performAutoRestart() error {
 
 	// Exit current process (restart script will take over)
 	fmt.Printf("🔄 Restarting BCRDF with new version...\n")
+	utils.CloseOutput()
 	os.Exit(0)
 
 	return nil
@@ -1354,3 +2860,122 @@ echo "✅ BCRDF restarted successfully!"
 
 	return scriptPath
 }
+
+// runCost reports storage requests and projected request charges per backup
+func runRepair(configPath, replicaConfigPath, backupID string, dryRun, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	var replicaClient storage.Client
+	if replicaConfigPath != "" {
+		replicaConfig, err := utils.LoadConfig(replicaConfigPath)
+		if err != nil {
+			return fmt.Errorf("error loading replica configuration: %w", err)
+		}
+		replicaClient, err = storage.NewStorageClient(replicaConfig)
+		if err != nil {
+			return fmt.Errorf("error initializing replica storage: %w", err)
+		}
+	}
+
+	repairMgr, err := repair.NewManager(config, storageClient, replicaClient)
+	if err != nil {
+		return err
+	}
+	var result *repair.Result
+	if replicaClient == nil {
+		result, err = repairMgr.RepairFromParity(backupID, dryRun, verbose)
+	} else {
+		result, err = repairMgr.Repair(backupID, dryRun, verbose)
+	}
+	if result != nil {
+		repair.PrintResult(result)
+	}
+	return err
+}
+
+func runCost(configPath, backupID string, pricing cost.Pricing, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	costMgr := cost.NewManager(config, index.NewManager(configPath), storageClient)
+	report, err := costMgr.Analyze(backupID, pricing, verbose)
+	if err != nil {
+		return err
+	}
+	cost.PrintReport(report)
+	return nil
+}
+
+func runStats(configPath string, forecast bool, limit string) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	report, err := stats.NewManager(config, index.NewManager(configPath), storageClient).Analyze(forecast, limit)
+	if err != nil {
+		return err
+	}
+	stats.PrintReport(report)
+	return nil
+}
+
+func runShare(configPath, backupID string, expires time.Duration, output string, verbose bool) error {
+	config, err := utils.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	storageClient, err := storage.NewStorageClient(config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage: %w", err)
+	}
+
+	bundle, err := share.Create(config, index.NewManager(configPath), storageClient, backupID, expires, verbose)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = backupID + ".share.json"
+	}
+	if err := bundle.Save(output); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔗 Share written to %s (%d objects, expires %s)\n", output, len(bundle.Objects), bundle.ExpiresAt.Format(time.RFC3339))
+	fmt.Printf("   Restore with: bcrdf restore --from-share %s -d <destination> --key-file <key>\n", output)
+	fmt.Printf("   ⚠️  Anyone holding this file can download the encrypted data until it expires; send the encryption key separately\n")
+	return nil
+}
+
+// applyShare configures a restore from a share file, the encryption key coming from --key-file or the environment
+func applyShare(restoreManager *restore.Manager, bundle *share.Bundle, keyFile, identityFile string) error {
+	key := os.Getenv("BCRDF_ENCRYPTION_KEY")
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("error reading key file: %w", err)
+		}
+		key = string(data)
+	}
+	return restoreManager.SetShare(bundle, key, identityFile)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"bcrdf/pkg/utils"
+)
+
+// uninstallTarget est un fichier ou répertoire local supprimé par bcrdf uninstall
+type uninstallTarget struct {
+	Kind string
+	Path string
+}
+
+// runUninstall supprime le binaire et les fichiers locaux de bcrdf; les données distantes ne sont jamais touchées
+func runUninstall(configPath string, removeConfig, yes, dryRun, verbose bool) error {
+	var config *utils.Config
+	if _, err := os.Stat(configPath); err == nil {
+		// Configuration invalide: on désinstalle quand même ce qui ne dépend pas d'elle
+		if config, err = utils.LoadConfig(configPath); err != nil {
+			utils.Warn("Could not load %s (%v): state database and caches are not removed", configPath, err)
+			config = nil
+		}
+	}
+
+	targets := uninstallTargets(config, configPath, removeConfig)
+	if len(targets) == 0 {
+		fmt.Println("Nothing to remove")
+		printRemoteLeftUntouched(config)
+		return nil
+	}
+
+	utils.PrintHeader("BCRDF Uninstall")
+	for _, target := range targets {
+		fmt.Printf("  🗑️  %-20s %s\n", target.Kind, target.Path)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Println("Dry run: nothing removed")
+		printRemoteLeftUntouched(config)
+		return nil
+	}
+
+	if !yes && !utils.PromptYesNo("Remove these files?", false) {
+		return fmt.Errorf("uninstall cancelled")
+	}
+
+	// La configuration contient la clé de chiffrement: confirmation séparée
+	if removeConfig && !yes {
+		utils.PrintWarning("The configuration holds the encryption key: without a copy of it, remote backups cannot be restored")
+		if !utils.PromptYesNo(fmt.Sprintf("Really remove %s?", configPath), false) {
+			targets = withoutKind(targets, "configuration")
+		}
+	}
+
+	removed, failed := 0, 0
+	for _, target := range targets {
+		if err := os.RemoveAll(target.Path); err != nil {
+			utils.PrintWarning(fmt.Sprintf("Could not remove %s: %v", target.Path, err))
+			failed++
+			continue
+		}
+		if verbose {
+			utils.Info("Removed %s: %s", target.Kind, target.Path)
+		}
+		removed++
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("%d item(s) removed", removed))
+	if runtime.GOOS == "windows" {
+		utils.PrintInfo("On Windows the running executable cannot remove itself: delete it once this command has exited")
+	}
+	printRemoteLeftUntouched(config)
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d item(s) could not be removed", utils.ErrPartialFailure, failed)
+	}
+	return nil
+}
+
+// uninstallTargets liste les fichiers locaux existants, le binaire en dernier
+func uninstallTargets(config *utils.Config, configPath string, removeConfig bool) []uninstallTarget {
+	var targets []uninstallTarget
+	add := func(kind, path string) {
+		if path == "" {
+			return
+		}
+		if _, err := os.Stat(path); err == nil {
+			targets = append(targets, uninstallTarget{Kind: kind, Path: path})
+		}
+	}
+
+	if config != nil {
+		if config.Backup.StateDB != "" {
+			add("state database", config.Backup.StateDB)
+			for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+				add("state database", config.Backup.StateDB+suffix)
+			}
+		}
+		add("metadata cache", config.Backup.MetadataCacheDir)
+		add("restore cache", config.Backup.RestoreCacheDir)
+	}
+
+	// Scripts générés par update (mise à jour différée, redémarrage automatique)
+	for _, pattern := range []string{"/tmp/bcrdf-update-*.sh", "./bcrdf-update-*.sh", "/tmp/bcrdf-restart.sh", "./bcrdf-restart.sh"} {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			add("generated script", match)
+		}
+	}
+
+	if removeConfig {
+		add("configuration", configPath)
+	}
+
+	if execPath, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+			execPath = resolved
+		}
+		add("update backup", execPath+".backup")
+		add("binary", execPath)
+	}
+	return targets
+}
+
+// withoutKind retire les cibles d'un type
+func withoutKind(targets []uninstallTarget, kind string) []uninstallTarget {
+	kept := targets[:0]
+	for _, target := range targets {
+		if target.Kind != kind {
+			kept = append(kept, target)
+		}
+	}
+	return kept
+}
+
+// printRemoteLeftUntouched rappelle que le dépôt distant n'est pas modifié
+func printRemoteLeftUntouched(config *utils.Config) {
+	fmt.Println()
+	utils.PrintInfo("Remote repository data is intentionally left untouched:")
+	if config == nil {
+		fmt.Println("   backups, indexes and data objects on the configured storage")
+	} else {
+		switch config.Storage.Type {
+		case "webdav":
+			fmt.Printf("   WebDAV: %s\n", config.Storage.Endpoint)
+		default:
+			location := "s3://" + config.Storage.Bucket
+			if config.Storage.Endpoint != "" {
+				location += " (" + config.Storage.Endpoint + ")"
+			}
+			fmt.Printf("   S3: %s\n", location)
+		}
+		fmt.Println("   indexes/, data/ and every backup stay restorable with the encryption key")
+	}
+	fmt.Println("   To remove them, run bcrdf delete or bcrdf retention --apply before uninstalling")
+}
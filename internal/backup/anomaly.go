@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Garde anti-ransomware (anomaly_guard): un chiffrement massif se traduit par une proportion
+// anormale de fichiers modifiés/supprimés et par des contenus devenus aléatoires (entropie maximale)
+const (
+	AnomalyGuardWarn  = "warn"
+	AnomalyGuardBlock = "block"
+	AnomalyGuardOff   = "off"
+
+	defaultAnomalyThreshold = 50 // % des fichiers précédents modifiés ou supprimés
+	anomalyMinFiles         = 20 // en dessous, les proportions ne sont pas significatives
+	entropySampleFiles      = 32 // fichiers modifiés échantillonnés
+	entropySampleBytes      = 64 * 1024
+	highEntropyBits         = 7.5 // bits par octet, proche de données chiffrées
+	highEntropyRatio        = 0.5 // part des fichiers échantillonnés devenus aléatoires
+	entropyMinSamples       = 5   // en dessous, quelques fichiers chiffrés légitimes suffiraient
+)
+
+// compressedExtensions sont des formats déjà compressés, naturellement à haute entropie
+var compressedExtensions = map[string]bool{
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true, ".7z": true, ".rar": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".heic": true,
+	".mp3": true, ".mp4": true, ".mkv": true, ".mov": true, ".avi": true, ".ogg": true, ".flac": true, ".m4a": true,
+	".pdf": true, ".docx": true, ".xlsx": true, ".pptx": true, ".odt": true, ".ods": true, ".jar": true, ".apk": true,
+	".gpg": true, ".age": true, ".enc": true,
+}
+
+// SetConfirmAnomaly autorise une sauvegarde malgré une anomalie détectée (--confirm-anomaly)
+func (m *Manager) SetConfirmAnomaly(confirm bool) {
+	m.confirmAnomaly = confirm
+}
+
+// checkAnomalies compare le diff à la sauvegarde précédente et signale (ou bloque) un taux de
+// changement anormal, pour ne pas remplacer silencieusement de bonnes sauvegardes
+func (m *Manager) checkAnomalies(diff *index.IndexDiff, previous *index.BackupIndex, verbose bool) error {
+	mode := m.config.Backup.AnomalyGuard
+	if mode == "" {
+		mode = AnomalyGuardWarn
+	}
+	if mode == AnomalyGuardOff || previous == nil {
+		return nil
+	}
+
+	reasons := detectAnomalies(diff, previous, m.anomalyThreshold())
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	message := strings.Join(reasons, "; ")
+	if mode == AnomalyGuardBlock && !m.confirmAnomaly {
+		return fmt.Errorf("%w: %s (possible ransomware; inspect the source, then rerun with --confirm-anomaly)",
+			utils.ErrAnomalyDetected, message)
+	}
+
+	if verbose {
+		utils.Warn("⚠️  Anomaly detected: %s (possible ransomware, previous backups are kept)", message)
+	} else {
+		utils.ProgressWarning(fmt.Sprintf("Anomaly detected: %s", message))
+	}
+	return nil
+}
+
+// anomalyThreshold retourne le pourcentage de changements considéré comme anormal
+func (m *Manager) anomalyThreshold() float64 {
+	if m.config.Backup.AnomalyThreshold > 0 {
+		return float64(m.config.Backup.AnomalyThreshold)
+	}
+	return defaultAnomalyThreshold
+}
+
+// detectAnomalies retourne les raisons pour lesquelles un diff paraît anormal par rapport à la
+// sauvegarde précédente
+func detectAnomalies(diff *index.IndexDiff, previous *index.BackupIndex, threshold float64) []string {
+	if len(previous.Files) < anomalyMinFiles {
+		return nil
+	}
+
+	var reasons []string
+	changed := len(diff.Modified) + len(diff.Deleted)
+	if rate := float64(changed) * 100 / float64(len(previous.Files)); rate >= threshold {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% of files modified or deleted (%d modified, %d deleted)",
+			rate, len(diff.Modified), len(diff.Deleted)))
+	}
+
+	if sampled, random := sampleEntropy(diff.Modified, previous); sampled >= entropyMinSamples && float64(random) >= highEntropyRatio*float64(sampled) {
+		reasons = append(reasons, fmt.Sprintf("%d of %d sampled modified files now look encrypted (entropy jump since the previous backup)", random, sampled))
+	}
+	return reasons
+}
+
+// sampleEntropy mesure l'entropie du début de fichiers modifiés et la compare à celle de leur
+// version précédente, enregistrée dans l'index. Seuls les fichiers dont la version précédente
+// avait une entropie connue et basse sont échantillonnés: des données déjà aléatoires (formats
+// compressés, fichiers chiffrés légitimes) ne sont pas un changement.
+func sampleEntropy(files []index.FileEntry, previous *index.BackupIndex) (sampled, random int) {
+	before := make(map[string]float64, len(previous.Files))
+	for _, file := range previous.Files {
+		if file.Entropy > 0 {
+			before[index.PathKey(file.Path)] = file.Entropy
+		}
+	}
+
+	var candidates []index.FileEntry
+	for _, file := range files {
+		if file.IsDirectory || compressedExtensions[strings.ToLower(filepath.Ext(file.Path))] {
+			continue
+		}
+		if entropy, ok := before[index.PathKey(file.Path)]; ok && entropy < highEntropyBits {
+			candidates = append(candidates, file)
+		}
+	}
+
+	step := 1
+	if len(candidates) > entropySampleFiles {
+		step = len(candidates) / entropySampleFiles
+	}
+	for i := 0; i < len(candidates) && sampled < entropySampleFiles; i += step {
+		entropy, err := fileEntropy(candidates[i].Path)
+		if err != nil {
+			continue
+		}
+		sampled++
+		if entropy >= highEntropyBits {
+			random++
+		}
+	}
+	return sampled, random
+}
+
+// fileEntropy calcule l'entropie de Shannon (bits par octet) du début d'un fichier
+func fileEntropy(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, entropySampleBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	return shannonEntropy(buf[:n]), nil
+}
+
+// entropySample retient le début d'un flux lu (io.TeeReader) pour recordEntropy
+type entropySample struct {
+	data []byte
+}
+
+func (s *entropySample) Write(p []byte) (int, error) {
+	if room := entropySampleBytes - len(s.data); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		s.data = append(s.data, p[:room]...)
+	}
+	return len(p), nil
+}
+
+// recordEntropy retient l'entropie du début du contenu envoyé d'un fichier, enregistrée dans l'index
+// pour comparer la version suivante (data: contenu en clair, ou son premier chunk)
+func (m *Manager) recordEntropy(file index.FileEntry, data []byte) {
+	if len(data) > entropySampleBytes {
+		data = data[:entropySampleBytes]
+	}
+	m.entropies.Store(file.GetStorageKey(), math.Round(shannonEntropy(data)*100)/100)
+}
+
+// markEntropy enregistre dans l'index l'entropie mesurée de chaque fichier envoyé
+func (m *Manager) markEntropy(currentIndex *index.BackupIndex) {
+	for i := range currentIndex.Files {
+		file := &currentIndex.Files[i]
+		if file.StorageKey == "" {
+			continue
+		}
+		if entropy, ok := m.entropies.Load(file.StorageKey); ok {
+			file.Entropy = entropy.(float64)
+		}
+	}
+}
+
+// shannonEntropy calcule l'entropie de Shannon de données (0 à 8 bits par octet)
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var entropy float64
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(len(data))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
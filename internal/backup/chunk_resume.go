@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"bcrdf/internal/index"
+	"bcrdf/internal/state"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// uploadedChunk est un chunk envoyé pendant l'exécution, tel qu'il a été stocké
+type uploadedChunk struct {
+	checksum    index.ChunkChecksum
+	size        int64
+	compression string
+}
+
+// existingChunks liste les chunks déjà présents pour un fichier (clé de l'objet -> taille)
+// Une liste impossible n'empêche pas l'envoi: le fichier est alors envoyé en entier.
+func (m *Manager) existingChunks(storageKey string) map[string]int64 {
+	objects, err := m.storageClient.ListObjects(storageKey + ".chunk.")
+	if err != nil {
+		utils.Debug("Existing chunks of %s not listed: %v", storageKey, err)
+		return nil
+	}
+	existing := make(map[string]int64, len(objects))
+	for _, object := range objects {
+		if index.ObjectFileKey(object.Key) == storageKey {
+			existing[object.Key] = object.Size
+		}
+	}
+	return existing
+}
+
+// resumeChunk indique si un chunk peut être repris plutôt que renvoyé: l'objet existe avec la taille
+// envoyée plus tôt, et les données lues ont la même empreinte et la même compression. Un fichier
+// interrompu au chunk 180/200 reprend ainsi au chunk 180: dans l'exécution (reprise des échecs,
+// transfert figé), et après un crash grâce au journal des chunks de state_db (adoptJournaledChunks).
+func (m *Manager) resumeChunk(chunkKey string, chunk []byte, compression string, existing map[string]int64) (index.ChunkChecksum, bool) {
+	size, ok := existing[chunkKey]
+	if !ok {
+		return index.ChunkChecksum{}, false
+	}
+	value, ok := m.chunks.Load(chunkKey)
+	if !ok {
+		return index.ChunkChecksum{}, false
+	}
+	uploaded := value.(uploadedChunk)
+	sum := sha256.Sum256(chunk)
+	if uploaded.size != size || uploaded.compression != compression || uploaded.checksum.Plain != hex.EncodeToString(sum[:]) {
+		return index.ChunkChecksum{}, false
+	}
+	m.stall.touch(chunkKey)
+	utils.Debug("⏭️ Chunk already uploaded, resuming after it: %s", chunkKey)
+	return uploaded.checksum, true
+}
+
+// recordChunk enregistre un chunk envoyé, pour reprendre le fichier s'il est renvoyé pendant
+// l'exécution, et le journalise dans state_db pour le reprendre après une interruption
+func (m *Manager) recordChunk(path, chunkKey string, checksum index.ChunkChecksum, size int, compression string) {
+	m.chunks.Store(chunkKey, uploadedChunk{checksum: checksum, size: int64(size), compression: compression})
+	chunk := state.JournaledChunk{Path: path, Key: chunkKey, Size: int64(size), Checksum: checksum, Compression: compression}
+	if err := m.state.JournalChunk(m.backupID, chunk); err != nil {
+		utils.Debug("Chunk journal: %v", err)
+	}
+}
+
+// interruptedBackup retourne l'ID de la dernière sauvegarde du job si elle s'est arrêtée pendant
+// l'envoi d'un fichier chunké sans écrire son index (crash, échec): la nouvelle exécution la
+// reprend sous cet ID pour retrouver ses chunks. Nécessite state_db.
+func (m *Manager) interruptedBackup(backupName, sourcePath string) (string, bool) {
+	if m.importedFrom != "" {
+		return "", false
+	}
+	// Avec des destinataires, la clé de données de l'exécution interrompue n'est plus connue de l'agent
+	if len(m.config.Backup.Recipients) > 0 {
+		return "", false
+	}
+	run, ok, err := m.state.InterruptedRun(backupName, sourcePath)
+	if err != nil {
+		utils.Warn("Local state database: %v", err)
+		return "", false
+	}
+	if !ok {
+		return "", false
+	}
+	if written, err := storage.ObjectExists(m.storageClient, fmt.Sprintf("indexes/%s.json", run.BackupID)); err != nil || written {
+		return "", false
+	}
+	// L'exécution reprise n'apparaît plus comme interrompue
+	if err := m.state.FinishRun(run.ID, 0, 0, fmt.Errorf("interrupted, resumed by the next run")); err != nil {
+		utils.Warn("Local state database: %v", err)
+	}
+	utils.Info("🔄 Resuming interrupted backup %s (started %s)", run.BackupID, run.StartedAt.Format("2006-01-02 15:04:05"))
+	return run.BackupID, true
+}
+
+// adoptJournaledChunks redonne aux fichiers à envoyer la clé de stockage sous laquelle
+// l'exécution interrompue avait déjà envoyé des chunks, et rend ces chunks disponibles pour
+// resumeChunk: seuls les chunks existants de même taille et de même empreinte sont repris.
+func (m *Manager) adoptJournaledChunks(currentIndex *index.BackupIndex, diff *index.IndexDiff) {
+	chunks, err := m.state.JournaledChunks(m.backupID)
+	if err != nil {
+		utils.Warn("Local state database: %v", err)
+		return
+	}
+	if len(chunks) == 0 {
+		return
+	}
+
+	prefix := fmt.Sprintf("data/%s/", m.backupID)
+	keys := make(map[string]string)
+	for _, chunk := range chunks {
+		keys[chunk.Path] = strings.TrimPrefix(index.ObjectFileKey(chunk.Key), prefix)
+		m.chunks.Store(chunk.Key, uploadedChunk{checksum: chunk.Checksum, size: chunk.Size, compression: chunk.Compression})
+	}
+	adopt := func(files []index.FileEntry) {
+		for i := range files {
+			if key, ok := keys[files[i].Path]; ok {
+				files[i].StorageKey = key
+			}
+		}
+	}
+	adopt(currentIndex.Files)
+	adopt(diff.Added)
+	adopt(diff.Modified)
+	utils.Debug("Resuming %d files from %d chunks already uploaded", len(keys), len(chunks))
+}
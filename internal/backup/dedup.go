@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"fmt"
+
+	"bcrdf/internal/index"
+)
+
+// splitDuplicates regroupe les fichiers de contenu identique d'une même sauvegarde: seul le premier
+// fichier de chaque groupe est envoyé, les autres pointeront vers son objet (markDuplicates).
+// Le regroupement n'a lieu qu'avec checksum_mode full, seul mode dont le checksum ne dépend que du
+// contenu. Les fichiers traités par un handler sont toujours envoyés: leur copie préparée diffère.
+// Retourne les fichiers à envoyer et, pour chaque doublon, le chemin du fichier réellement envoyé.
+func (m *Manager) splitDuplicates(files []index.FileEntry) ([]index.FileEntry, map[string]string) {
+	duplicates := make(map[string]string)
+	if index.ChecksumModeOf(m.config) != index.ChecksumModeFull {
+		return files, duplicates
+	}
+
+	first := make(map[string]string, len(files)) // Contenu (checksum, taille) -> chemin envoyé
+	uploads := make([]index.FileEntry, 0, len(files))
+	for _, file := range files {
+		if file.Checksum == "" {
+			uploads = append(uploads, file)
+			continue
+		}
+		if _, _, ok := m.handlers.Match(file.Path); ok {
+			uploads = append(uploads, file)
+			continue
+		}
+		content := fmt.Sprintf("%s:%d", file.Checksum, file.Size)
+		if path, ok := first[content]; ok {
+			duplicates[file.Path] = path
+			continue
+		}
+		first[content] = file.Path
+		uploads = append(uploads, file)
+	}
+	return uploads, duplicates
+}
+
+// propagateDuplicateFailures reporte l'échec d'un fichier envoyé sur ses doublons, qui n'ont aucune donnée
+func propagateDuplicateFailures(failed map[string]error, duplicates map[string]string) {
+	for path, uploaded := range duplicates {
+		if err, ok := failed[uploaded]; ok {
+			failed[path] = fmt.Errorf("%s has the same content as %s: %w", path, uploaded, err)
+		}
+	}
+}
+
+// markDuplicates fait pointer les doublons vers l'objet du fichier de même contenu envoyé
+// Appelée après markFileStatuses: les doublons d'un fichier en échec sont déjà marqués en échec.
+func (m *Manager) markDuplicates(currentIndex *index.BackupIndex) {
+	if len(m.duplicates) == 0 {
+		return
+	}
+	keys := make(map[string]string, len(m.duplicates))
+	for _, file := range currentIndex.Files {
+		keys[file.Path] = file.StorageKey
+	}
+	for i := range currentIndex.Files {
+		file := &currentIndex.Files[i]
+		uploaded, ok := m.duplicates[file.Path]
+		if !ok || file.Status == index.FileStatusFailed || file.IsSkipped() {
+			continue
+		}
+		if key := keys[uploaded]; key != "" {
+			file.StorageKey = key
+		}
+	}
+}
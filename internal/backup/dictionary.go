@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"bcrdf/internal/compression"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Dictionnaire de compression par job (compression_dictionary): les petits fichiers similaires
+// (logs, JSON) se compressent mal un par un, un dictionnaire partagé leur donne le contexte manquant
+const (
+	dictionaryMinSamples = 16
+	dictionaryMaxSamples = 2000
+)
+
+// SetRetrainDictionary force l'entraînement d'un nouveau dictionnaire (--retrain-dictionary)
+func (m *Manager) SetRetrainDictionary(retrain bool) {
+	m.retrainDict = retrain
+}
+
+// prepareDictionary charge le dictionnaire du job, ou l'entraîne sur les fichiers à sauvegarder
+// Un échec n'interrompt pas la sauvegarde: les fichiers sont alors compressés en gzip
+func (m *Manager) prepareDictionary(backupName string, files []index.FileEntry, verbose bool) {
+	if !m.config.Backup.CompressionDictionary || m.compressionMode() != index.CompressionGzip {
+		return
+	}
+	// Le dictionnaire, tiré du contenu des fichiers, est relu par l'agent avec encryption_key
+	if len(m.config.Backup.Recipients) > 0 {
+		utils.Debug("Compression dictionary disabled: data is encrypted to recipients")
+		return
+	}
+
+	if !m.retrainDict {
+		dict, ref, err := m.loadLatestDictionary(backupName)
+		if err != nil {
+			utils.Warn("Unable to load compression dictionary, training a new one: %v", err)
+		} else if dict != nil {
+			m.dictionary, m.dictionaryRef = dict, ref
+			utils.Debug("📖 Using compression dictionary %s", ref)
+			return
+		}
+	}
+
+	samples := m.dictionarySamples(files)
+	if len(samples) < dictionaryMinSamples {
+		utils.Debug("Not enough small files to train a compression dictionary (%d)", len(samples))
+		return
+	}
+
+	dict := compression.NewDictionary(compression.TrainDictionary(samples, compression.MaxDictionarySize))
+	if len(dict.Data) == 0 {
+		return
+	}
+	ref := backupName + "/" + dict.ID
+
+	encrypted, err := m.encryptor.Encrypt(dict.Data)
+	if err != nil {
+		utils.Warn("Unable to encrypt compression dictionary: %v", err)
+		return
+	}
+	if err := m.saveToStorageWithRetry(context.Background(), index.DictionaryKey(ref), encrypted); err != nil {
+		utils.Warn("Unable to upload compression dictionary: %v", err)
+		return
+	}
+
+	m.dictionary, m.dictionaryRef = dict, ref
+	if verbose {
+		utils.Info("📖 Trained compression dictionary %s (%d bytes, %d sample files)", ref, len(dict.Data), len(samples))
+	} else {
+		utils.ProgressInfo(fmt.Sprintf("Trained compression dictionary %s", ref))
+	}
+}
+
+// loadLatestDictionary retourne le dictionnaire le plus récent du job, nil s'il n'en existe pas
+func (m *Manager) loadLatestDictionary(backupName string) (*compression.Dictionary, string, error) {
+	objects, err := m.storageClient.ListObjects("dictionaries/" + backupName + "/")
+	if err != nil {
+		return nil, "", err
+	}
+
+	latest := -1
+	for i, object := range objects {
+		if !strings.HasSuffix(object.Key, ".dict") {
+			continue
+		}
+		if latest < 0 || object.LastModified.After(objects[latest].LastModified) {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		return nil, "", nil
+	}
+
+	ref := strings.TrimSuffix(strings.TrimPrefix(objects[latest].Key, "dictionaries/"), ".dict")
+	encrypted, err := m.storageClient.Download(objects[latest].Key)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := m.encryptor.Decrypt(encrypted)
+	if err != nil {
+		return nil, "", err
+	}
+	return &compression.Dictionary{ID: ref[strings.LastIndex(ref, "/")+1:], Data: data}, ref, nil
+}
+
+// dictionarySamples lit les petits fichiers compressibles qui serviront à l'entraînement
+func (m *Manager) dictionarySamples(files []index.FileEntry) [][]byte {
+	var samples [][]byte
+	for _, file := range files {
+		if len(samples) >= dictionaryMaxSamples {
+			break
+		}
+		if !m.useDictionary(file) {
+			continue
+		}
+		data, err := os.ReadFile(utils.LongPath(file.Path))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		samples = append(samples, data)
+	}
+	return samples
+}
+
+// useDictionary indique si un fichier est compressé avec le dictionnaire (petit fichier compressible)
+func (m *Manager) useDictionary(file index.FileEntry) bool {
+	return !file.IsDirectory && file.Size > 0 && file.Size <= compression.DictionaryMaxFileSize &&
+		m.compressor.ShouldCompress(file.Path)
+}
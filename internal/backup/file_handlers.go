@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"bcrdf/internal/handlers"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// preparedFile décrit la copie cohérente réellement envoyée pour un fichier traité par un handler
+type preparedFile struct {
+	handler  string
+	size     int64
+	checksum string
+}
+
+// backupWithHandler sauvegarde la copie cohérente écrite par un handler (backup.file_handlers)
+// à la place du fichier lui-même, sous la clé de stockage de l'entrée d'index
+func (m *Manager) backupWithHandler(ctx context.Context, file index.FileEntry, name string, handler handlers.Handler, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+	// La copie a la taille du fichier source à peu près: espace réservé dans la zone temporaire
+	release, err := m.temp.Reserve(file.Size)
+	if err != nil {
+		return fmt.Errorf("%s handler failed for %s: %w", name, file.Path, err)
+	}
+	defer release()
+
+	dir, err := m.temp.MkdirTemp("handler-")
+	if err != nil {
+		return fmt.Errorf("error creating handler directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, filepath.Base(file.Path))
+	if err := handler.Prepare(file.Path, output); err != nil {
+		return fmt.Errorf("%s handler failed for %s: %w", name, file.Path, err)
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		return fmt.Errorf("%s handler failed for %s: %w", name, file.Path, err)
+	}
+	// Le checksum complet de la copie permet de vérifier le fichier restauré
+	entry, err := index.NewFileEntryWithMode(output, info, index.ChecksumModeFull)
+	if err != nil {
+		return fmt.Errorf("error reading %s handler output: %w", name, err)
+	}
+	utils.Debug("%s handler prepared %s (%d bytes)", name, file.Path, info.Size())
+
+	// La préparation d'une grosse base compte comme une progression du transfert
+	m.stall.touch(fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey()))
+
+	prepared := file
+	prepared.Path = output
+	prepared.Size = info.Size()
+	if err := m.backupSingleFileWithMultiProgress(ctx, prepared, backupID, multiProgressBar, verbose); err != nil {
+		return err
+	}
+	m.prepared.Store(file.GetStorageKey(), preparedFile{handler: name, size: info.Size(), checksum: entry.Checksum})
+	return nil
+}
+
+// markPrepared enregistre dans l'index la taille et le checksum des copies envoyées par les handlers
+func (m *Manager) markPrepared(currentIndex *index.BackupIndex) {
+	for i := range currentIndex.Files {
+		file := &currentIndex.Files[i]
+		if file.StorageKey == "" {
+			continue
+		}
+		if value, ok := m.prepared.Load(file.StorageKey); ok {
+			prepared := value.(preparedFile)
+			currentIndex.TotalSize += prepared.size - file.Size
+			file.Size = prepared.size
+			file.Checksum = prepared.checksum
+			file.Handler = prepared.handler
+		}
+	}
+}
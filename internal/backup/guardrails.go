@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"bcrdf/internal/jobs"
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/utils"
+)
+
+// localPath est un fichier ou répertoire local de bcrdf, qui ne doit pas être sauvegardé
+type localPath struct {
+	name string
+	path string
+}
+
+// localPaths retourne les fichiers locaux de bcrdf: base d'état, caches, file d'attente des jobs
+// et zones temporaires (celles de ce run et des runs en cours)
+func (m *Manager) localPaths() []localPath {
+	var paths []localPath
+	if db := m.config.Backup.StateDB; db != "" {
+		for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+			paths = append(paths, localPath{"state_db", db + suffix})
+		}
+	}
+	if dir := m.config.Backup.MetadataCacheDir; dir != "" {
+		paths = append(paths, localPath{"metadata_cache_dir", dir})
+	}
+	if dir := m.config.Backup.RestoreCacheDir; dir != "" {
+		paths = append(paths, localPath{"restore_cache_dir", dir})
+	}
+	queueDir := m.config.Backup.JobQueueDir
+	if queueDir == "" {
+		queueDir = jobs.DefaultDir()
+	}
+	paths = append(paths, localPath{"job_queue_dir", queueDir})
+	for _, area := range tempdir.Areas(tempdir.Root(m.config)) {
+		paths = append(paths, localPath{"temp directory", area})
+	}
+	return paths
+}
+
+// checkSourceGuardrails empêche une sauvegarde de se sauvegarder elle-même: une source située
+// dans un répertoire de bcrdf est refusée, les fichiers de bcrdf situés sous une source sont exclus
+func (m *Manager) checkSourceGuardrails(sources []string, verbose bool) error {
+	var excluded []string
+	for _, source := range sources {
+		absSource, err := filepath.Abs(source)
+		if err != nil {
+			continue
+		}
+		for _, local := range m.localPaths() {
+			absLocal, err := filepath.Abs(local.path)
+			if err != nil {
+				continue
+			}
+			if isWithin(absSource, absLocal) {
+				return fmt.Errorf("%w: source %s is inside bcrdf's %s (%s): the backup would include its own files",
+					utils.ErrConfig, source, local.name, absLocal)
+			}
+			if !isWithin(absLocal, absSource) {
+				continue
+			}
+			rel, err := filepath.Rel(absSource, absLocal)
+			if err != nil {
+				continue
+			}
+			// Chemin tel que le parcours le verra (la source peut être relative)
+			excluded = append(excluded, filepath.Join(source, rel))
+			if verbose {
+				utils.Info("Excluding bcrdf's %s from the backup: %s", local.name, absLocal)
+			} else {
+				utils.Debug("Excluding bcrdf's %s from the backup: %s", local.name, absLocal)
+			}
+		}
+	}
+	m.indexMgr.SetExcludedPaths(excluded)
+	return nil
+}
+
+// isWithin indique si path est dir ou se trouve sous dir
+func isWithin(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimRight(dir, string(filepath.Separator))+string(filepath.Separator))
+}
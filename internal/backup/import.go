@@ -0,0 +1,294 @@
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/utils"
+)
+
+// importDir est une entrée de répertoire extraite, dont les permissions et la date sont
+// appliquées après l'extraction de son contenu
+type importDir struct {
+	path    string
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// ImportBackup importe une archive (tar, tar.gz, tgz, zip) ou un répertoire existant comme une
+// sauvegarde complète du nom name, avec son index, pour regrouper d'anciennes sauvegardes dans
+// le dépôt. Une archive est extraite dans un répertoire temporaire (backup.temp_dir) avec les
+// dates et permissions de ses entrées. createdAt date la sauvegarde (zéro = maintenant).
+// L'import ne dépend d'aucune sauvegarde précédente et n'applique pas la rétention.
+func (m *Manager) ImportBackup(source, name string, createdAt time.Time, verbose bool) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("%w: import source: %w", utils.ErrConfig, err)
+	}
+	if m.importedFrom, err = filepath.Abs(source); err != nil {
+		m.importedFrom = source
+	}
+	m.createdAt = createdAt
+	if info.IsDir() {
+		return m.CreateBackup(source, name, verbose)
+	}
+
+	config, err := utils.LoadConfig(m.configFile)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	root := tempdir.Root(config)
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return fmt.Errorf("error creating temp directory %s: %w", root, err)
+	}
+	extractDir, err := os.MkdirTemp(root, "bcrdf-import-")
+	if err != nil {
+		return fmt.Errorf("error creating temp directory: %w", err)
+	}
+	defer os.RemoveAll(extractDir)
+
+	if verbose {
+		utils.Info("📦 Extracting %s", source)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("Extracting %s...", filepath.Base(source)))
+	}
+	count, err := extractArchive(source, extractDir)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: archive %s contains no files", utils.ErrConfig, source)
+	}
+	if verbose {
+		utils.Info("   - %d files extracted", count)
+	}
+	return m.CreateBackup(extractDir, name, verbose)
+}
+
+// backupTime retourne la date de la sauvegarde: celle de l'import, sinon maintenant
+func (m *Manager) backupTime() time.Time {
+	if !m.createdAt.IsZero() {
+		return m.createdAt
+	}
+	return time.Now()
+}
+
+// extractArchive extrait une archive sous dest selon son extension et retourne le nombre de
+// fichiers et liens extraits
+func extractArchive(archivePath, dest string) (int, error) {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(archivePath, dest)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("error reading %s: %w", archivePath, err)
+		}
+		defer gz.Close()
+		return extractTar(tar.NewReader(gz), dest)
+	case strings.HasSuffix(lower, ".tar"):
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		return extractTar(tar.NewReader(f), dest)
+	default:
+		return 0, fmt.Errorf("%w: unsupported import source %s (expected a directory, .tar, .tar.gz, .tgz or .zip)", utils.ErrConfig, archivePath)
+	}
+}
+
+// extractTar extrait une archive tar (fichiers, répertoires, liens symboliques et physiques)
+func extractTar(tr *tar.Reader, dest string) (int, error) {
+	count := 0
+	var dirs []importDir
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("error reading archive: %w", err)
+		}
+		target, err := archiveTarget(dest, header.Name)
+		if err != nil {
+			return count, err
+		}
+		if target == dest {
+			continue
+		}
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return count, err
+			}
+			dirs = append(dirs, importDir{target, mode, header.ModTime})
+			continue
+		case tar.TypeReg:
+			if err := writeExtracted(target, tr, mode, header.ModTime); err != nil {
+				return count, err
+			}
+		case tar.TypeSymlink:
+			if err := extractSymlink(target, header.Linkname); err != nil {
+				return count, err
+			}
+		case tar.TypeLink:
+			source, err := archiveTarget(dest, header.Linkname)
+			if err != nil {
+				return count, err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return count, err
+			}
+			if err := os.Link(source, target); err != nil {
+				return count, fmt.Errorf("error extracting hard link %s: %w", header.Name, err)
+			}
+		default:
+			utils.Warn("Skipping unsupported archive entry: %s (type %c)", header.Name, header.Typeflag)
+			continue
+		}
+		count++
+	}
+	return count, finishDirs(dirs)
+}
+
+// extractZip extrait une archive zip (fichiers, répertoires et liens symboliques)
+func extractZip(archivePath, dest string) (int, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", archivePath, err)
+	}
+	defer zr.Close()
+
+	count := 0
+	var dirs []importDir
+	for _, f := range zr.File {
+		target, err := archiveTarget(dest, f.Name)
+		if err != nil {
+			return count, err
+		}
+		if target == dest {
+			continue
+		}
+		info := f.FileInfo()
+		if info.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return count, err
+			}
+			dirs = append(dirs, importDir{target, info.Mode().Perm(), f.Modified})
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return count, fmt.Errorf("error reading %s from archive: %w", f.Name, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			var linkTarget []byte
+			if linkTarget, err = io.ReadAll(rc); err == nil {
+				err = extractSymlink(target, string(linkTarget))
+			}
+		} else {
+			err = writeExtracted(target, rc, info.Mode().Perm(), f.Modified)
+		}
+		rc.Close()
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, finishDirs(dirs)
+}
+
+// archiveTarget retourne le chemin d'extraction d'une entrée sous dest; une entrée sortant de
+// dest (.., chemin absolu après retrait du / initial, répertoire parent remplacé par un lien
+// symbolique extrait plus tôt) est refusée
+func archiveTarget(dest, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(strings.TrimLeft(name, "/")))
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: unsafe path %q in archive", utils.ErrConfig, name)
+	}
+	parent := dest
+	parts := strings.Split(clean, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		if info, err := os.Lstat(parent); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("%w: unsafe path %q in archive (through a symlink)", utils.ErrConfig, name)
+		}
+	}
+	return filepath.Join(dest, clean), nil
+}
+
+// writeExtracted écrit un fichier extrait avec sa date de modification et ses permissions
+// (lisible par le propriétaire, pour être sauvegardé); une entrée du même nom est remplacée
+func writeExtracted(target string, r io.Reader, mode os.FileMode, modTime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+		// Un lien symbolique extrait plus tôt ne doit pas être suivi
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("error extracting %s: %w", target, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(target, mode|0400); err != nil {
+		return err
+	}
+	return os.Chtimes(target, modTime, modTime)
+}
+
+// extractSymlink recrée un lien symbolique tel quel (sa cible n'est pas suivie par l'import)
+func extractSymlink(target, linkTarget string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	os.Remove(target)
+	if err := os.Symlink(linkTarget, target); err != nil {
+		return fmt.Errorf("error extracting symlink %s: %w", target, err)
+	}
+	return nil
+}
+
+// finishDirs applique permissions et dates des répertoires, du plus profond au moins profond
+// (écrire dans un répertoire change sa date); le propriétaire garde l'accès pour la sauvegarde
+// et la suppression du répertoire temporaire
+func finishDirs(dirs []importDir) error {
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i].path) > len(dirs[j].path) })
+	for _, dir := range dirs {
+		if err := os.Chmod(dir.path, dir.mode|0700); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dir.path, dir.modTime, dir.modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package backup
+
+import "bcrdf/internal/jobs"
+
+// SetJobPriority surcharge job_priority pour cette exécution (--priority)
+func (m *Manager) SetJobPriority(priority int) {
+	m.jobPriority = &priority
+}
+
+// acquireJobSlot attend un créneau quand max_parallel_jobs limite les sauvegardes simultanées
+func (m *Manager) acquireJobSlot(backupName string, verbose bool) (func(), error) {
+	if m.config.Backup.MaxParallelJobs <= 0 {
+		return func() {}, nil
+	}
+	priority := m.config.Backup.JobPriority
+	if m.jobPriority != nil {
+		priority = *m.jobPriority
+	}
+	slots := jobs.NewSlots(m.config.Backup.JobQueueDir, m.config.Backup.MaxParallelJobs)
+	return slots.Acquire(backupName, priority, verbose)
+}
+
+// SetWaitForLock attend la fin d'une sauvegarde du même job en cours sur cette machine au lieu d'échouer (--wait)
+func (m *Manager) SetWaitForLock(wait bool) {
+	m.waitForLock = wait
+}
+
+// lockJob empêche deux sauvegardes simultanées du même job sur cette machine
+func (m *Manager) lockJob(backupName string, verbose bool) (func(), error) {
+	return jobs.LockJob(m.config.Backup.JobQueueDir, backupName, m.waitForLock, verbose)
+}
@@ -1,22 +1,29 @@
 package backup
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"bcrdf/internal/compression"
 	"bcrdf/internal/crypto"
+	"bcrdf/internal/gc"
+	"bcrdf/internal/handlers"
 	"bcrdf/internal/index"
+	"bcrdf/internal/parity"
 	"bcrdf/internal/retention"
+	"bcrdf/internal/state"
+	"bcrdf/internal/tempdir"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
 )
@@ -35,9 +42,37 @@ type Manager struct {
 	config           *utils.Config
 	indexMgr         *index.Manager
 	encryptor        *crypto.EncryptorV2
+	dataEncryptor    *crypto.EncryptorV2 // Chiffreur des objets de données de la sauvegarde en cours (recipients)
+	wrappedDataKey   string              // Clé de données enveloppée pour les destinataires, vide sans recipients
 	compressor       *compression.Compressor
 	storageClient    storage.Client
 	multiProgressBar *utils.IntegratedProgressBar // Barre de progression intégrée pour les gros fichiers
+	errorPolicy      string                       // Politique d'erreurs (surcharge la configuration si non vide)
+	stall            *stallDetector               // Suivi de la progression des transferts en cours
+	uploads          *uploadQueue                 // Backoff global et disjoncteur des uploads
+	memory           *memoryBudget                // Plafond de mémoire des buffers (memory_limit)
+	compressions     sync.Map                     // Compression appliquée par clé de stockage (gzip, none)
+	entropies        sync.Map                     // Entropie du début du contenu envoyé par clé de stockage (anomaly_guard)
+	previousIndex    *index.BackupIndex           // Index de la sauvegarde précédente (base des index delta)
+	state            *state.Store                 // Base d'état locale (state_db), nil si désactivée
+	backupID         string                       // Sauvegarde en cours (journal des uploads)
+	confirmAnomaly   bool                         // Sauvegarde confirmée malgré une anomalie (--confirm-anomaly)
+	dictionary       *compression.Dictionary      // Dictionnaire des petits fichiers (compression_dictionary), nil sinon
+	dictionaryRef    string                       // Référence du dictionnaire ({job}/{id})
+	retrainDict      bool                         // Entraîner un nouveau dictionnaire (--retrain-dictionary)
+	requestsStart    storage.RequestCounts        // Relevé des requêtes au début de la sauvegarde (index.Requests)
+	jobPriority      *int                         // Surcharge de job_priority (--priority)
+	waitForLock      bool                         // Attendre la sauvegarde du même job en cours (--wait)
+	oneFileSystem    bool                         // Rester sur le système de fichiers de la source (--one-file-system)
+	symlinks         string                       // Surcharge de backup.symlinks (--symlinks)
+	handlers         *handlers.Set                // Handlers de copie cohérente (file_handlers), nil sans règle
+	prepared         sync.Map                     // Copies préparées par les handlers, par clé de stockage
+	duplicates       map[string]string            // Doublons de contenu non envoyés -> chemin du fichier envoyé
+	chunks           sync.Map                     // Chunks envoyés pendant l'exécution, par clé (reprise des fichiers chunkés)
+	temp             *tempdir.Area                // Zone temporaire de l'exécution (snapshots, copies des handlers)
+	timeoutScale     atomic.Int32                 // Multiplicateur des délais de transfert (reprise des échecs), 0 = aucun
+	importedFrom     string                       // Archive ou répertoire importé (bcrdf import), vide sinon
+	createdAt        time.Time                    // Date de la sauvegarde importée, zéro = maintenant
 }
 
 // NewManager crée un nouveau gestionnaire de sauvegarde
@@ -47,26 +82,135 @@ func NewManager(configFile string) *Manager {
 	}
 }
 
+// SetErrorPolicy surcharge la politique d'erreurs de la configuration (fail, continue, threshold=N%)
+func (m *Manager) SetErrorPolicy(policy string) {
+	m.errorPolicy = policy
+}
+
+// SetOneFileSystem empêche l'indexation de descendre dans d'autres points de montage (--one-file-system)
+func (m *Manager) SetOneFileSystem(enabled bool) {
+	m.oneFileSystem = enabled
+}
+
+// SetSymlinkPolicy surcharge la politique des liens symboliques (--symlinks)
+func (m *Manager) SetSymlinkPolicy(policy string) {
+	m.symlinks = policy
+}
+
+// resolveErrorPolicy retourne la politique d'erreurs effective
+func (m *Manager) resolveErrorPolicy() (utils.ErrorPolicy, error) {
+	if m.errorPolicy != "" {
+		return utils.ParseErrorPolicy(m.errorPolicy)
+	}
+	return utils.ParseErrorPolicy(m.config.Backup.ErrorPolicy)
+}
+
 // CreateBackup effectue une sauvegarde complète
 func (m *Manager) CreateBackup(sourcePath, backupName string, verbose bool) error {
+	return m.CreateBackupSources([]string{sourcePath}, backupName, verbose)
+}
+
+// CreateBackupSources sauvegarde plusieurs sources dans un même index (backup.sources sans
+// sources données)
+func (m *Manager) CreateBackupSources(sources []string, backupName string, verbose bool) (err error) {
 	startTime := time.Now()
 	m.logBackupStart(backupName, verbose)
 
-	if err := m.prepareBackup(sourcePath); err != nil {
+	if err := m.prepareBackup(); err != nil {
+		return err
+	}
+	if sources, err = m.resolveSources(sources); err != nil {
+		return err
+	}
+	sourcePath := strings.Join(sources, ", ")
+	m.warnUnencrypted(verbose)
+	for _, source := range sources {
+		m.warnSourceNearlyFull(source, verbose)
+	}
+
+	// Un seul run par job et par machine, avant d'occuper un créneau (max_parallel_jobs)
+	unlock, err := m.lockJob(backupName, verbose)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	releaseSlot, err := m.acquireJobSlot(backupName, verbose)
+	if err != nil {
+		return err
+	}
+	defer releaseSlot()
+
+	// Zone temporaire propre à l'exécution, supprimée à la fin (ou au prochain run après un crash)
+	if m.temp, err = tempdir.Open(m.config); err != nil {
+		return err
+	}
+	defer m.temp.Close()
+
+	// La source ne doit pas contenir les fichiers de bcrdf lui-même (état, caches, temporaires)
+	if err := m.checkSourceGuardrails(sources, verbose); err != nil {
+		return err
+	}
+
+	policy, err := m.resolveErrorPolicy()
+	if err != nil {
+		return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+
+	// Une horloge décalée fausse l'horodatage de l'ID, donc l'âge vu par la rétention
+	storage.CheckClockSkew(m.config, m.storageClient)
+
+	// Série de la sauvegarde (ID sans l'horodatage): base incrémentale et rétention
+	series := index.BackupSeries(m.config.Backup.IDTemplate, backupName)
+	backupID := index.RenderBackupID(m.config.Backup.IDTemplate, backupName, m.backupTime())
+
+	var currentIndex *index.BackupIndex
+	var runID int64
+	m.openState()
+	defer func() { m.closeState(runID, currentIndex, err) }()
+
+	// Un run interrompu pendant l'envoi d'un fichier chunké est repris sous son ID: ses chunks restent en place
+	if interrupted, ok := m.interruptedBackup(backupName, sourcePath); ok {
+		backupID = interrupted
+	}
+	if err := m.checkBackupIDCollision(backupID); err != nil {
+		return err
+	}
+	m.backupID = backupID
+	if err := m.prepareDataKey(); err != nil {
 		return err
 	}
+	m.requestsStart = storage.Requests()
+	runID = m.startRun(backupID, backupName, sourcePath)
 
-	backupID := fmt.Sprintf("%s-%s", backupName, time.Now().Format("20060102-150405"))
+	// Rapport de l'exécution (report_path, report_upload), écrit aussi en cas d'échec
+	report := &runReport{BackupID: backupID, Job: backupName, Source: sourcePath, Started: startTime}
+	defer func() {
+		report.Index, report.Previous, report.Err = currentIndex, m.previousIndex, err
+		report.Duration = time.Since(startTime)
+		m.writeReport(report, verbose)
+	}()
 
-	currentIndex, err := m.createCurrentIndex(sourcePath, backupID, verbose)
+	scanStart := time.Now()
+	currentIndex, err = m.createCurrentIndex(sources, backupID, verbose)
+	report.ScanDuration = time.Since(scanStart)
 	if err != nil {
 		return err
 	}
 
-	diff, err := m.calculateBackupDiff(currentIndex, backupName, verbose)
+	diff, err := m.calculateBackupDiff(currentIndex, series, verbose)
 	if err != nil {
 		return err
 	}
+	report.Diff = diff
+	m.adoptJournaledChunks(currentIndex, diff)
+
+	if err := m.checkAnomalies(diff, m.previousIndex, verbose); err != nil {
+		return err
+	}
+	if err := m.checkTempSpace(diff); err != nil {
+		return err
+	}
 
 	// Vérifier s'il y a des fichiers à sauvegarder
 	totalFilesToBackup := len(diff.Added) + len(diff.Modified)
@@ -75,33 +219,70 @@ func (m *Manager) CreateBackup(sourcePath, backupName string, verbose bool) erro
 		if verbose {
 			utils.Info("🔄 No files to backup, skipping backup creation")
 		} else {
-			utils.ProgressInfo("No files to backup, skipping backup creation")
+			utils.ProgressInfo(utils.Msg("backup.step.nothing_skip"))
 		}
 		m.logBackupCompletion(diff, time.Since(startTime), verbose)
 		return nil
 	}
 
-	if err := m.executeBackup(currentIndex, diff, backupID, backupName, verbose); err != nil {
+	if err := m.executeBackup(currentIndex, diff, backupID, backupName, policy, verbose); err != nil {
 		return err
 	}
+	m.protectWithParity(backupID, verbose)
 
 	m.logBackupCompletion(diff, time.Since(startTime), verbose)
 
 	// Apply retention policy only if a backup was actually created
-	if totalFilesToBackup > 0 {
-		if err := m.applyRetentionPolicyForBackup(backupName, verbose); err != nil {
+	if m.config.Backup.AppendOnly {
+		utils.Debug("Append-only repository: retention left to the pruning instance")
+	} else if totalFilesToBackup > 0 {
+		// Rétention par série, seul point d'application après une sauvegarde
+		if err := m.applyRetentionPolicyForBackup(series, verbose); err != nil {
 			// Don't fail the backup if retention fails, just warn
 			if verbose {
-				utils.Warn("Retention policy application failed: %v", err)
+				utils.Warn("⚠️  Task 7 completed with warnings: Retention policy failed")
+				utils.Warn("   - Error: %v", err)
+				utils.Warn("   - Backup completed successfully, but retention cleanup failed")
 			} else {
-				utils.ProgressWarning("Retention cleanup failed")
+				utils.ProgressWarning(utils.Msg("backup.step.retention_failed"))
 			}
+		} else if verbose {
+			utils.Info("✅ Task 7 completed: Retention policy applied successfully")
+		} else {
+			utils.ProgressSuccess(utils.Msg("backup.step.retention_applied"))
 		}
 	}
 
 	return nil
 }
 
+// protectWithParity écrit les objets de parité de la sauvegarde (backup.parity); un échec laisse
+// la sauvegarde valide mais non protégée
+func (m *Manager) protectWithParity(backupID string, verbose bool) {
+	if m.config.Backup.Parity == "" {
+		return
+	}
+	if !verbose {
+		utils.ProgressStep("🧩 Computing parity objects...")
+	}
+	if _, err := parity.Protect(m.storageClient, m.config, backupID, verbose); err != nil {
+		utils.Warn("⚠️  Parity of %s not written, the backup is not protected: %v", backupID, err)
+	}
+}
+
+// checkBackupIDCollision refuse un ID déjà utilisé (deux hôtes d'un même nom dans la même seconde,
+// modèle sans {hostname}): l'index existant serait écrasé
+func (m *Manager) checkBackupIDCollision(backupID string) error {
+	exists, err := storage.ObjectExists(m.storageClient, fmt.Sprintf("indexes/%s.json", backupID))
+	if err != nil {
+		return fmt.Errorf("%w: error checking backup ID %s: %w", utils.ErrStorageUnreachable, backupID, err)
+	}
+	if exists {
+		return fmt.Errorf("backup ID %s already exists (add {hostname} to backup.id_template if several hosts share this name)", backupID)
+	}
+	return nil
+}
+
 // applyRetentionPolicy applique la politique de rétention après une sauvegarde
 func (m *Manager) applyRetentionPolicy(verbose bool) error {
 	return m.applyRetentionPolicyForBackup("", verbose)
@@ -109,6 +290,11 @@ func (m *Manager) applyRetentionPolicy(verbose bool) error {
 
 // applyRetentionPolicyForBackup applique la politique de rétention pour un nom de backup spécifique
 func (m *Manager) applyRetentionPolicyForBackup(backupName string, verbose bool) error {
+	// Un import daté serait purgé dès son arrivée, avec les sauvegardes plus récentes de la série
+	if m.importedFrom != "" {
+		utils.Debug("Imported backup: retention not applied")
+		return nil
+	}
 	if verbose {
 		utils.Info("📋 Task 7: Applying retention policy")
 		utils.Info("   - Loading retention configuration")
@@ -135,27 +321,40 @@ func (m *Manager) applyRetentionPolicyForBackup(backupName string, verbose bool)
 
 // DeleteBackup supprime une sauvegarde
 func (m *Manager) DeleteBackup(backupID string) error {
-	utils.Info("🗑️ Suppression de la sauvegarde: %s", backupID)
+	utils.Info("🗑️ Deleting backup: %s", backupID)
 
 	// Initialiser les composants si nécessaire
 	if err := m.initializeComponents(); err != nil {
-		return fmt.Errorf("error during l'initialisation des composants: %w", err)
+		return fmt.Errorf("error initializing components: %w", err)
+	}
+	if err := utils.CheckDeletesAllowed(m.config, "delete"); err != nil {
+		return err
 	}
 
 	// Charger l'index de la sauvegarde
 	backupIndex, err := m.indexMgr.LoadIndex(backupID)
 	if err != nil {
-		return fmt.Errorf("erreur lors du chargement de l'index: %w", err)
+		return fmt.Errorf("error loading index: %w", err)
 	}
-
-	// Supprimer les fichiers de données
-	if err := m.deleteBackupFiles(backupIndex); err != nil {
-		return fmt.Errorf("error during la suppression des fichiers: %w", err)
+	if err := m.indexMgr.CheckLegalHolds(backupIndex); err != nil {
+		return err
 	}
 
-	// Supprimer l'index
+	// Supprimer l'index d'abord: les données ne sont plus référencées par cette sauvegarde
 	if err := m.deleteBackupIndex(backupID); err != nil {
-		return fmt.Errorf("error during la suppression de l'index: %w", err)
+		return fmt.Errorf("error deleting index: %w", err)
+	}
+	if err := index.DeleteReports(m.storageClient, backupID); err != nil {
+		utils.Warn("Report of %s not removed: %v", backupID, err)
+	}
+
+	// Ne supprimer que les objets qui ne sont plus référencés par aucune autre sauvegarde
+	// (avec l'index de base et la parité devenus inutiles)
+	gcMgr := gc.NewManager(m.config, m.indexMgr, m.storageClient)
+	if result, err := gcMgr.CollectBackup(backupIndex, false); err != nil {
+		utils.Warn("Unreferenced data of %s could not be fully removed (run 'bcrdf gc'): %v", backupID, err)
+	} else {
+		utils.Info("Deleted %d unreferenced objects for: %s", result.Deleted, backupID)
 	}
 
 	utils.Info("✅ Backup deleted: %s", backupID)
@@ -168,7 +367,7 @@ func (m *Manager) initializeComponents() error {
 	if m.config == nil {
 		config, err := utils.LoadConfig(m.configFile)
 		if err != nil {
-			return fmt.Errorf("erreur lors du chargement de la configuration: %w", err)
+			return fmt.Errorf("error loading configuration: %w", err)
 		}
 		m.config = config
 	}
@@ -184,21 +383,22 @@ func (m *Manager) initializeComponents() error {
 
 	encryptor, err := crypto.NewEncryptorV2(m.config.Backup.EncryptionKey, algorithm)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du chiffreur: %w", err)
+		return fmt.Errorf("error initializing encryptor: %w", err)
 	}
+	encryptor.SetAllowUnencrypted(m.config.Backup.AllowUnencrypted)
 	m.encryptor = encryptor
 
 	// Initialiser le compresseur
 	compressor, err := compression.NewCompressor(m.config.Backup.CompressionLevel)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du compresseur: %w", err)
+		return fmt.Errorf("error initializing compressor: %w", err)
 	}
 	m.compressor = compressor
 
 	// Initialiser le client de stockage
 	storageClient, err := storage.NewStorageClient(m.config)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du client de stockage: %w", err)
+		return fmt.Errorf("error initializing storage client: %w", err)
 	}
 	m.storageClient = storageClient
 
@@ -234,7 +434,7 @@ func (m *Manager) findPreviousBackup(currentBackupName string) (*index.BackupInd
 		return nil, nil
 	}
 
-	utils.Debug("Found previous backup: %s (created at %s)", latestKey, latestTime.Format("2006-01-02 15:04:05"))
+	utils.Debug("Found previous backup: %s (created at %s)", latestKey, latestTime.Local().Format("2006-01-02 15:04:05"))
 	return m.loadBackupIndex(latestKey, latestTime)
 }
 
@@ -251,7 +451,7 @@ func (m *Manager) ensureInitialized() error {
 	if m.storageClient == nil {
 		storageClient, err := storage.NewStorageClient(m.config)
 		if err != nil {
-			return fmt.Errorf("error during l'initialisation du client de stockage: %w", err)
+			return fmt.Errorf("error initializing storage client: %w", err)
 		}
 		m.storageClient = storageClient
 	}
@@ -263,7 +463,7 @@ func (m *Manager) ensureInitialized() error {
 func (m *Manager) listBackupIndexes() ([]string, error) {
 	objects, err := m.storageClient.ListObjects("indexes/")
 	if err != nil {
-		utils.Warn("Impossible de lister les index: %v", err)
+		utils.Warn("Unable to list indexes: %v", err)
 		return nil, err
 	}
 
@@ -288,13 +488,9 @@ func (m *Manager) filterKeysByName(keys []string, backupName string) []string {
 			continue
 		}
 
-		keyBackupID := strings.TrimSuffix(strings.TrimPrefix(key, "indexes/"), ".json")
-		parts := strings.Split(keyBackupID, "-")
-		if len(parts) >= 3 {
-			extractedBackupName := strings.Join(parts[:len(parts)-2], "-")
-			if extractedBackupName == backupName {
-				filteredKeys = append(filteredKeys, key)
-			}
+		ref, err := index.ParseBackupID(strings.TrimSuffix(strings.TrimPrefix(key, "indexes/"), ".json"))
+		if err == nil && ref.Name == backupName {
+			filteredKeys = append(filteredKeys, key)
 		}
 	}
 
@@ -312,53 +508,30 @@ func (m *Manager) selectKeysToUse(allKeys, filteredKeys []string) []string {
 
 // findLatestBackup finds the most recent backup from the given keys
 func (m *Manager) findLatestBackup(keys []string) (string, time.Time, error) {
-	if len(keys) == 0 {
-		return "", time.Time{}, nil
-	}
-
-	m.sortKeysByTimestamp(keys)
-	latestKey := keys[len(keys)-1]
-
-	// Extract timestamp from key
-	parts := strings.Split(latestKey, "-")
-	if len(parts) < 3 {
-		return "", time.Time{}, fmt.Errorf("invalid key format: %s", latestKey)
+	var latestKey string
+	var latestTime time.Time
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		// Horodatages UTC et anciens horodatages locaux comparés en temps absolu
+		ref, err := index.ParseBackupID(strings.TrimSuffix(strings.TrimPrefix(key, "indexes/"), ".json"))
+		if err != nil {
+			utils.Warn("Cannot parse timestamp from key %s: %v", key, err)
+			continue
+		}
+		if latestKey == "" || ref.CreatedAt.After(latestTime) {
+			latestKey, latestTime = key, ref.CreatedAt
+		}
 	}
-
-	// Parse timestamp from the last parts
-	timestampStr := strings.Join(parts[len(parts)-2:], "-")
-	timestampStr = strings.TrimSuffix(timestampStr, ".json")
-
-	latestTime, err := time.Parse("20060102-150405", timestampStr)
-	if err != nil {
-		utils.Warn("Cannot parse timestamp from key %s: %v", latestKey, err)
-		return "", time.Time{}, err
+	if latestKey == "" {
+		return "", time.Time{}, nil
 	}
 
-	utils.Debug("Latest backup found: %s (timestamp: %s)", latestKey, latestTime.Format("2006-01-02 15:04:05"))
+	utils.Debug("Latest backup found: %s (timestamp: %s)", latestKey, latestTime.Local().Format("2006-01-02 15:04:05"))
 	return latestKey, latestTime, nil
 }
 
-// sortKeysByTimestamp sorts keys by timestamp
-func (m *Manager) sortKeysByTimestamp(keys []string) {
-	sort.Slice(keys, func(i, j int) bool {
-		keyI := keys[i]
-		keyJ := keys[j]
-
-		partsI := strings.Split(strings.TrimSuffix(keyI, ".json"), "-")
-		partsJ := strings.Split(strings.TrimSuffix(keyJ, ".json"), "-")
-
-		if len(partsI) < 2 || len(partsJ) < 2 {
-			return keyI < keyJ
-		}
-
-		timestampI := partsI[len(partsI)-2] + "-" + partsI[len(partsI)-1]
-		timestampJ := partsJ[len(partsJ)-2] + "-" + partsJ[len(partsJ)-1]
-
-		return timestampI < timestampJ
-	})
-}
-
 // loadBackupIndex loads the backup index for the given key
 func (m *Manager) loadBackupIndex(latestKey string, latestTime time.Time) (*index.BackupIndex, error) {
 	backupID := strings.TrimSuffix(strings.TrimPrefix(latestKey, "indexes/"), ".json")
@@ -373,7 +546,7 @@ func (m *Manager) loadBackupIndex(latestKey string, latestTime time.Time) (*inde
 	}
 
 	utils.Info("Previous backup found: %s (created on %s)",
-		backupID, latestTime.Format("2006-01-02 15:04:05"))
+		backupID, latestTime.Local().Format("2006-01-02 15:04:05"))
 
 	return previousIndex, nil
 }
@@ -540,17 +713,34 @@ func (m *Manager) startChunkMonitoring(stats *BackupStats, verbose bool) {
 	}()
 }
 
-// backupFiles sauvegarde les fichiers spécifiés
-func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string, verbose bool) error {
-	allFiles := append(added, modified...)
+// backupFiles sauvegarde les fichiers spécifiés et retourne les échecs par chemin
+func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string, verbose bool) (map[string]error, error) {
+	var allFiles []index.FileEntry
+	for _, file := range append(added, modified...) {
+		// Répertoires et fichiers vides: seules les métadonnées de l'index sont conservées
+		if !file.IsMetadataOnly() {
+			allFiles = append(allFiles, file)
+		}
+	}
+	failed := make(map[string]error)
+
+	// Contenus identiques: un seul envoi, les doublons pointeront vers le même objet
+	allFiles, m.duplicates = m.splitDuplicates(allFiles)
+	if len(m.duplicates) > 0 {
+		if verbose {
+			utils.Info("   - %d duplicate files share the upload of an identical file", len(m.duplicates))
+		} else {
+			utils.ProgressInfo(fmt.Sprintf("%d duplicate files uploaded once", len(m.duplicates)))
+		}
+	}
 
 	if len(allFiles) == 0 {
 		if verbose {
 			utils.Info("No files to backup")
 		} else {
-			utils.ProgressInfo("No files to backup")
+			utils.ProgressInfo(utils.Msg("backup.step.nothing"))
 		}
-		return nil
+		return failed, nil
 	}
 
 	// Initialiser les statistiques de monitoring
@@ -597,10 +787,14 @@ func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string
 
 	stats.UpdateStatus("Processing files in parallel")
 
-	// Créer un pool de workers pour le traitement parallèle
-	semaphore := make(chan struct{}, m.config.Backup.MaxWorkers)
+	// File d'upload centrale: les workers consomment les fichiers dans l'ordre (petits en premier si sort_by_size)
+	workers := m.config.Backup.MaxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan int)
 	var wg sync.WaitGroup
-	errors := make(chan error, len(allFiles))
+	failures := make(chan fileFailure, len(allFiles))
 
 	// Barre de progression intégrée pour le mode non-verbeux
 	var multiProgressBar *utils.IntegratedProgressBar
@@ -616,81 +810,80 @@ func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string
 	completed := int64(0)
 	var completedMutex sync.Mutex
 
-	// Timeout global pour éviter les blocages infinis
-	globalTimeout := time.Duration(m.config.Backup.NetworkTimeout*len(allFiles)) * time.Second
-	if globalTimeout == 0 {
-		globalTimeout = 30 * time.Minute // Default 30 minutes
-	}
+	// Pas de timeout global: chaque upload a son propre délai et seuls les transferts figés sont abandonnés
+	m.stall = newStallDetector()
 
-	ctx, cancel := context.WithTimeout(context.Background(), globalTimeout)
-	defer cancel()
+	m.uploads = newUploadQueue(m.config, workers)
+	m.memory = newMemoryBudget(m.config.Backup.MemoryLimit)
 
-	for i, file := range allFiles {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
-		go func(f index.FileEntry, index int) {
+		go func() {
 			defer wg.Done()
+			for index := range queue {
+				f := allFiles[index]
+				m.uploads.acquire()
 
-			// Vérifier le timeout global
-			select {
-			case <-ctx.Done():
-				errors <- fmt.Errorf("global timeout reached for file %s", f.Path)
-				return
-			case semaphore <- struct{}{}:
-				defer func() { <-semaphore }()
-			}
-
-			// Mettre à jour les statistiques
-			stats.UpdateStats(f.Path, f.Size, index+1, len(allFiles))
+				// Mettre à jour les statistiques
+				stats.UpdateStats(f.Path, f.Size, index+1, len(allFiles))
 
-			// Ne pas afficher de barre pour les petits fichiers; les fonctions dédiées géreront l'affichage si nécessaire
-
-			if verbose {
-				utils.Debug("   - Processing file: %s (%.2f MB)", filepath.Base(f.Path), float64(f.Size)/1024/1024)
-			}
+				if verbose {
+					utils.Debug("   - Processing file: %s (%.2f MB)", filepath.Base(f.Path), float64(f.Size)/1024/1024)
+				}
 
-			// Sauvegarder le fichier avec suivi de progression
-			if err := m.backupSingleFileWithMultiProgress(f, backupID, multiProgressBar, verbose); err != nil {
-				errors <- fmt.Errorf("error saving de %s: %w", f.Path, err)
-			}
+				// Sauvegarder le fichier avec suivi de progression
+				if err := m.backupFileWithTimeout(f, backupID, multiProgressBar, verbose); err != nil {
+					failures <- fileFailure{path: f.Path, err: fmt.Errorf("error saving %s: %w", f.Path, err)}
+				}
 
-			// Mettre à jour la progression globale
-			if !verbose && multiProgressBar != nil {
-				completedMutex.Lock()
-				completed += f.Size
-				multiProgressBar.UpdateGlobal(completed)
-				completedMutex.Unlock()
+				// Mettre à jour la progression globale
+				if !verbose && multiProgressBar != nil {
+					completedMutex.Lock()
+					completed += f.Size
+					multiProgressBar.UpdateGlobal(completed)
+					completedMutex.Unlock()
 
-				// Marquer le fichier comme terminé (utiliser le nom de base pour la cohérence)
-				fileName := filepath.Base(f.Path)
-				multiProgressBar.RemoveFile(fileName)
+					// Marquer le fichier comme terminé (utiliser le nom de base pour la cohérence)
+					fileName := filepath.Base(f.Path)
+					multiProgressBar.RemoveFile(fileName)
+				}
+				m.uploads.release()
 			}
-		}(file, i)
+		}()
 	}
 
-	// Attendre la fin ou le timeout
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		// Tous les fichiers ont été traités
-	case <-ctx.Done():
-		utils.Warn("⚠️  Global timeout reached, some files may not have been processed")
+	for i := range allFiles {
+		queue <- i
 	}
+	close(queue)
 
-	close(errors)
+	// Attendre la fin de tous les transferts
+	wg.Wait()
+	close(failures)
 
 	// Terminer la barre de progression multi-fichiers
 	if !verbose && multiProgressBar != nil {
 		multiProgressBar.Finish()
 	}
 
+	for failure := range failures {
+		failed[failure.path] = failure.err
+	}
+	if m.config.Backup.RetryFailedFiles {
+		m.retryFailedFiles(allFiles, failed, backupID, verbose)
+	}
+
 	// Vérifier s'il y a eu des erreurs
 	errorCount := 0
-	for err := range errors {
+	for _, err := range failed {
+		if errors.Is(err, errFileSkipped) {
+			if verbose {
+				utils.Warn("⚠️  %v", err)
+			} else {
+				utils.ProgressWarning(err.Error())
+			}
+			continue
+		}
 		errorCount++
 		if verbose {
 			utils.Error("%v", err)
@@ -698,8 +891,10 @@ func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string
 			utils.ProgressError(err.Error())
 		}
 	}
+	propagateDuplicateFailures(failed, m.duplicates)
 
 	if verbose {
+		m.uploads.logMetrics()
 		if errorCount > 0 {
 			utils.Warn("   - Completed with %d errors", errorCount)
 		} else {
@@ -708,20 +903,51 @@ func (m *Manager) backupFiles(added, modified []index.FileEntry, backupID string
 	}
 
 	stats.UpdateStatus("File processing completed")
-	return nil
+	return failed, nil
+}
+
+// fileFailure associe un fichier à l'erreur rencontrée lors de sa sauvegarde
+type fileFailure struct {
+	path string
+	err  error
+}
+
+// countFailures compte les échecs réels (hors fichiers volontairement ignorés)
+func countFailures(failed map[string]error) int {
+	count := 0
+	for _, err := range failed {
+		if !errors.Is(err, errFileSkipped) {
+			count++
+		}
+	}
+	return count
+}
+
+// countStoredFiles compte les fichiers dont les données sont dans la sauvegarde
+func countStoredFiles(files []index.FileEntry) int {
+	count := 0
+	for i := range files {
+		if files[i].HasData() || (files[i].IsMetadataOnly() && !files[i].IsDirectory) {
+			count++
+		}
+	}
+	return count
 }
 
 // calculateTotalSize calcule la taille totale des fichiers
 func (m *Manager) calculateTotalSize(files []index.FileEntry) int64 {
 	var total int64
 	for _, file := range files {
+		if file.IsSkipped() || file.Status == index.FileStatusFailed {
+			continue
+		}
 		total += file.Size
 	}
 	return total
 }
 
 // backupSingleFileWithMultiProgress sauvegarde un seul fichier avec la barre de progression intégrée
-func (m *Manager) backupSingleFileWithMultiProgress(file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) backupSingleFileWithMultiProgress(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
 	fileName := filepath.Base(file.Path)
 	utils.Debug("   - Processing file: %s (%.2f MB)", fileName, float64(file.Size)/1024/1024)
 
@@ -754,27 +980,42 @@ func (m *Manager) backupSingleFileWithMultiProgress(file index.FileEntry, backup
 		ultraLargeThreshold = 5 * 1024 * 1024 * 1024 // 5GB default
 	}
 
+	// Un fichier standard dont les buffers dépasseraient memory_limit passe en mode streaming (chunks)
+	if file.Size < largeThreshold && m.memory.exceeds(standardBufferWeight(file.Size)) {
+		utils.Debug("🔄 Streaming %s in chunks to stay within memory_limit", fileName)
+		largeThreshold = file.Size
+	}
+
 	// Choisir la méthode de sauvegarde selon la taille avec suivi de progression
 	if file.Size >= ultraLargeThreshold {
 		if verbose {
 			utils.Debug("🔄 Processing ultra-large file: %s (%.2f MB)", fileName, float64(file.Size)/1024/1024)
 		}
-		return m.backupUltraLargeFileWithMultiProgress(file, backupID, multiProgressBar, verbose)
+		weight := m.chunkBufferWeight("50MB")
+		m.memory.acquire(weight)
+		defer m.memory.release(weight)
+		return m.backupUltraLargeFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose)
 	} else if file.Size >= largeThreshold {
 		if verbose {
 			utils.Debug("🔄 Processing large file: %s (%.2f MB)", fileName, float64(file.Size)/1024/1024)
 		}
-		return m.backupLargeFileWithMultiProgress(file, backupID, multiProgressBar, verbose)
+		weight := m.chunkBufferWeight("10MB")
+		m.memory.acquire(weight)
+		defer m.memory.release(weight)
+		return m.backupLargeFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose)
 	} else {
 		if verbose {
 			utils.Debug("🔄 Processing standard file: %s (%.2f MB)", fileName, float64(file.Size)/1024/1024)
 		}
-		return m.backupStandardFileWithMultiProgress(file, backupID, multiProgressBar, verbose)
+		weight := standardBufferWeight(file.Size)
+		m.memory.acquire(weight)
+		defer m.memory.release(weight)
+		return m.backupStandardFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose)
 	}
 }
 
 // backupStandardFileWithMultiProgress sauvegarde un fichier standard avec la barre de progression intégrée
-func (m *Manager) backupStandardFileWithMultiProgress(file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) backupStandardFileWithMultiProgress(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
 	fileName := filepath.Base(file.Path)
 
 	if verbose {
@@ -787,35 +1028,47 @@ func (m *Manager) backupStandardFileWithMultiProgress(file index.FileEntry, back
 		multiProgressBar.UpdateChunkWithName(fileName, 0, 1) // 1 chunk pour les fichiers standards
 	}
 
-	// Lire le fichier
-	fileData, err := os.ReadFile(file.Path)
+	// Lire le fichier au fil de l'eau: il n'est jamais chargé en entier quand il est compressé
+	f, err := os.Open(utils.LongPath(file.Path))
 	if err != nil {
 		return fmt.Errorf("error reading file: %w", err)
 	}
+	defer f.Close()
+	sample := &entropySample{}
+	reader := io.TeeReader(f, sample)
 
-	// Mettre à jour la progression après lecture
-	if multiProgressBar != nil && !verbose {
-		fileName := filepath.Base(file.Path)
-		multiProgressBar.UpdateChunkWithName(fileName, 1, 1) // Lecture terminée
-	}
-
-	// Compresser les données si configuré
-	if m.config.Backup.CompressionLevel > 0 {
+	var payload bytes.Buffer
+	payload.Grow(int(file.Size) + bytes.MinRead)
+	compression := m.compressionMode()
+	if compression == index.CompressionGzip && m.dictionary != nil && m.useDictionary(file) {
+		if err := m.compressor.CompressStreamWithDictionary(reader, &payload, m.dictionary); err != nil {
+			return fmt.Errorf("error compressing file: %w", err)
+		}
+		compression = index.CompressionDictPrefix + m.dictionaryRef
+	} else if compression == index.CompressionGzip {
 		if verbose {
 			utils.Debug("🗜️  Compressing file...")
 		}
-		compressedData, err := m.compressor.Compress(fileData)
-		if err != nil {
+		if err := m.compressor.CompressStream(reader, &payload); err != nil {
 			return fmt.Errorf("error compressing file: %w", err)
 		}
-		fileData = compressedData
+	} else if _, err := payload.ReadFrom(reader); err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	m.compressions.Store(file.GetStorageKey(), compression)
+	m.recordEntropy(file, sample.data)
+
+	// Mettre à jour la progression après lecture
+	if multiProgressBar != nil && !verbose {
+		fileName := filepath.Base(file.Path)
+		multiProgressBar.UpdateChunkWithName(fileName, 1, 1) // Lecture terminée
 	}
 
 	// Chiffrer les données
 	if verbose {
 		utils.Debug("🔐 Encrypting file...")
 	}
-	encryptedData, err := m.encryptor.Encrypt(fileData)
+	encryptedData, err := m.dataEncryptor.Encrypt(payload.Bytes())
 	if err != nil {
 		return fmt.Errorf("error encrypting file: %w", err)
 	}
@@ -824,7 +1077,7 @@ func (m *Manager) backupStandardFileWithMultiProgress(file index.FileEntry, back
 	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
 
 	// Sauvegarder avec retry
-	if err := m.saveToStorageWithRetry(storageKey, encryptedData); err != nil {
+	if err := m.saveToStorageWithRetry(ctx, storageKey, encryptedData); err != nil {
 		return fmt.Errorf("error saving file to storage: %w", err)
 	}
 
@@ -841,7 +1094,7 @@ func (m *Manager) backupStandardFileWithMultiProgress(file index.FileEntry, back
 }
 
 // backupLargeFileWithMultiProgress sauvegarde un fichier volumineux avec la barre de progression intégrée
-func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) backupLargeFileWithMultiProgress(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
 	fileName := filepath.Base(file.Path)
 
 	if verbose {
@@ -857,7 +1110,7 @@ func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupI
 	defer stats.StopMonitoring()
 
 	// Read file in chunks and process each chunk
-	fileHandle, err := os.Open(file.Path)
+	fileHandle, err := os.Open(utils.LongPath(file.Path))
 	if err != nil {
 		return fmt.Errorf("error opening large file: %w", err)
 	}
@@ -911,19 +1164,29 @@ func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupI
 	// Démarrer le monitoring spécifique pour ce fichier chunké
 	m.startChunkMonitoring(stats, verbose)
 
+	// Décision de compression unique pour tous les chunks, enregistrée dans les métadonnées
+	compression := m.compressionMode()
+	var checksums []index.ChunkChecksum
+	existing := m.existingChunks(storageKey)
+	// Tampons réutilisés d'un chunk à l'autre: la mémoire utilisée est bornée par la taille d'un chunk
+	buffer := make([]byte, chunkSize)
+	var compressed bytes.Buffer
 	for {
 		// Read chunk
-		chunk := make([]byte, chunkSize)
-		n, err := fileHandle.Read(chunk)
+		n, err := io.ReadFull(fileHandle, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
+		}
 		if n == 0 {
 			break // End of file
 		}
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
-		}
 
-		chunk = chunk[:n] // Adjust slice to actual bytes read
+		chunk := buffer[:n] // Adjust slice to actual bytes read
 		totalProcessed += int64(n)
+		m.stall.touch(storageKey)
+		if chunkNumber == 0 {
+			m.recordEntropy(file, chunk)
+		}
 
 		// Mettre à jour les statistiques de chunking
 		stats.UpdateChunkStats(chunkNumber+1, int(totalChunks), int64(n))
@@ -944,46 +1207,57 @@ func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupI
 				chunkNumber, n, float64(n)/1024/1024, float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024)
 		}
 
+		// Chunk déjà envoyé par une tentative précédente du même fichier
+		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
+		if checksum, ok := m.resumeChunk(chunkKey, chunk, compression, existing); ok {
+			checksums = append(checksums, checksum)
+			chunkNumber++
+			continue
+		}
+
 		// Compress then encrypt (dans cet ordre)
 		processedChunk := chunk
-		if m.config.Backup.CompressionLevel > 0 {
+		if compression == index.CompressionGzip {
 			if verbose {
 				utils.Debug("🗜️  Compressing chunk %d...", chunkNumber)
 			}
-			compressedChunk, err := m.compressor.Compress(processedChunk)
-			if err != nil {
+			compressed.Reset()
+			if err := m.compressor.CompressStream(bytes.NewReader(processedChunk), &compressed); err != nil {
 				return fmt.Errorf("error compressing chunk %d: %w", chunkNumber, err)
 			}
-			processedChunk = compressedChunk
+			processedChunk = compressed.Bytes()
 		}
 
 		// Encrypt chunk
 		if verbose {
 			utils.Debug("🔐 Encrypting chunk %d...", chunkNumber)
 		}
-		encryptedChunk, err := m.encryptor.Encrypt(processedChunk)
+		encryptedChunk, err := m.dataEncryptor.Encrypt(processedChunk)
 		if err != nil {
 			return fmt.Errorf("error encrypting chunk %d: %w", chunkNumber, err)
 		}
 
-		// Generate chunk key
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
 		if verbose {
 			utils.Debug("📤 Uploading chunk %d to storage: %s", chunkNumber, chunkKey)
 		}
 
 		// Upload chunk with retry
-		if err := m.saveToStorageWithRetry(chunkKey, encryptedChunk); err != nil {
+		if err := m.saveToStorageWithRetry(ctx, chunkKey, encryptedChunk); err != nil {
 			return fmt.Errorf("error uploading chunk %d: %w", chunkNumber, err)
 		}
+		checksum := index.NewChunkChecksum(chunk, encryptedChunk)
+		m.recordChunk(file.Path, chunkKey, checksum, len(encryptedChunk), compression)
+		checksums = append(checksums, checksum)
 
 		chunkNumber++
 	}
 
-	// Save metadata
-	metadata := map[string]interface{}{
-		"chunks": chunkNumber,
-		"size":   file.Size,
+	// Save metadata (avec les checksums de chaque chunk pour health et restore)
+	metadata := index.ChunkMetadata{
+		Chunks:      chunkNumber,
+		Size:        file.Size,
+		Compression: compression,
+		Checksums:   checksums,
 	}
 
 	metadataBytes, err := json.Marshal(metadata)
@@ -992,9 +1266,10 @@ func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupI
 	}
 
 	metadataKey := fmt.Sprintf("%s.metadata", storageKey)
-	if err := m.saveToStorageWithRetry(metadataKey, metadataBytes); err != nil {
+	if err := m.saveToStorageWithRetry(ctx, metadataKey, metadataBytes); err != nil {
 		return fmt.Errorf("error saving metadata: %w", err)
 	}
+	m.compressions.Store(file.GetStorageKey(), compression)
 
 	// Retirer le fichier de la barre de progression
 	if multiProgressBar != nil && !verbose {
@@ -1008,7 +1283,7 @@ func (m *Manager) backupLargeFileWithMultiProgress(file index.FileEntry, backupI
 }
 
 // backupUltraLargeFileWithMultiProgress sauvegarde un fichier extrêmement volumineux avec la barre de progression intégrée
-func (m *Manager) backupUltraLargeFileWithMultiProgress(file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) backupUltraLargeFileWithMultiProgress(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
 	fileName := filepath.Base(file.Path)
 
 	if verbose {
@@ -1024,7 +1299,7 @@ func (m *Manager) backupUltraLargeFileWithMultiProgress(file index.FileEntry, ba
 	defer stats.StopMonitoring()
 
 	// Read file in chunks and process each chunk
-	fileHandle, err := os.Open(file.Path)
+	fileHandle, err := os.Open(utils.LongPath(file.Path))
 	if err != nil {
 		return fmt.Errorf("error opening ultra-large file: %w", err)
 	}
@@ -1078,19 +1353,29 @@ func (m *Manager) backupUltraLargeFileWithMultiProgress(file index.FileEntry, ba
 	// Démarrer le monitoring spécifique pour ce fichier chunké
 	m.startChunkMonitoring(stats, verbose)
 
+	// Décision de compression unique pour tous les chunks, enregistrée dans les métadonnées
+	compression := m.compressionMode()
+	var checksums []index.ChunkChecksum
+	existing := m.existingChunks(storageKey)
+	// Tampons réutilisés d'un chunk à l'autre: la mémoire utilisée est bornée par la taille d'un chunk
+	buffer := make([]byte, chunkSize)
+	var compressed bytes.Buffer
 	for {
 		// Read chunk
-		chunk := make([]byte, chunkSize)
-		n, err := fileHandle.Read(chunk)
+		n, err := io.ReadFull(fileHandle, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
+		}
 		if n == 0 {
 			break // End of file
 		}
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
-		}
 
-		chunk = chunk[:n] // Adjust slice to actual bytes read
+		chunk := buffer[:n] // Adjust slice to actual bytes read
 		totalProcessed += int64(n)
+		m.stall.touch(storageKey)
+		if chunkNumber == 0 {
+			m.recordEntropy(file, chunk)
+		}
 
 		// Mettre à jour les statistiques de chunking
 		stats.UpdateChunkStats(chunkNumber+1, int(totalChunks), int64(n))
@@ -1111,46 +1396,57 @@ func (m *Manager) backupUltraLargeFileWithMultiProgress(file index.FileEntry, ba
 				chunkNumber, n, float64(n)/1024/1024, float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024)
 		}
 
+		// Chunk déjà envoyé par une tentative précédente du même fichier
+		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
+		if checksum, ok := m.resumeChunk(chunkKey, chunk, compression, existing); ok {
+			checksums = append(checksums, checksum)
+			chunkNumber++
+			continue
+		}
+
 		// Compress then encrypt (dans cet ordre)
 		processedChunk := chunk
-		if m.config.Backup.CompressionLevel > 0 {
+		if compression == index.CompressionGzip {
 			if verbose {
 				utils.Debug("🗜️  Compressing chunk %d...", chunkNumber)
 			}
-			compressedChunk, err := m.compressor.Compress(processedChunk)
-			if err != nil {
+			compressed.Reset()
+			if err := m.compressor.CompressStream(bytes.NewReader(processedChunk), &compressed); err != nil {
 				return fmt.Errorf("error compressing chunk %d: %w", chunkNumber, err)
 			}
-			processedChunk = compressedChunk
+			processedChunk = compressed.Bytes()
 		}
 
 		// Encrypt chunk
 		if verbose {
 			utils.Debug("🔐 Encrypting chunk %d...", chunkNumber)
 		}
-		encryptedChunk, err := m.encryptor.Encrypt(processedChunk)
+		encryptedChunk, err := m.dataEncryptor.Encrypt(processedChunk)
 		if err != nil {
 			return fmt.Errorf("error encrypting chunk %d: %w", chunkNumber, err)
 		}
 
-		// Generate chunk key
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
 		if verbose {
 			utils.Debug("📤 Uploading chunk %d to storage: %s", chunkNumber, chunkKey)
 		}
 
 		// Upload chunk with retry
-		if err := m.saveToStorageWithRetry(chunkKey, encryptedChunk); err != nil {
+		if err := m.saveToStorageWithRetry(ctx, chunkKey, encryptedChunk); err != nil {
 			return fmt.Errorf("error uploading chunk %d: %w", chunkNumber, err)
 		}
+		checksum := index.NewChunkChecksum(chunk, encryptedChunk)
+		m.recordChunk(file.Path, chunkKey, checksum, len(encryptedChunk), compression)
+		checksums = append(checksums, checksum)
 
 		chunkNumber++
 	}
 
-	// Save metadata
-	metadata := map[string]interface{}{
-		"chunks": chunkNumber,
-		"size":   file.Size,
+	// Save metadata (avec les checksums de chaque chunk pour health et restore)
+	metadata := index.ChunkMetadata{
+		Chunks:      chunkNumber,
+		Size:        file.Size,
+		Compression: compression,
+		Checksums:   checksums,
 	}
 
 	metadataBytes, err := json.Marshal(metadata)
@@ -1159,9 +1455,10 @@ func (m *Manager) backupUltraLargeFileWithMultiProgress(file index.FileEntry, ba
 	}
 
 	metadataKey := fmt.Sprintf("%s.metadata", storageKey)
-	if err := m.saveToStorageWithRetry(metadataKey, metadataBytes); err != nil {
+	if err := m.saveToStorageWithRetry(ctx, metadataKey, metadataBytes); err != nil {
 		return fmt.Errorf("error saving metadata: %w", err)
 	}
+	m.compressions.Store(file.GetStorageKey(), compression)
 
 	// Retirer le fichier de la barre de progression
 	if multiProgressBar != nil && !verbose {
@@ -1194,580 +1491,88 @@ func (m *Manager) isFileModifiedSinceLastBackup(file index.FileEntry, fileInfo o
 	return false
 }
 
-// backupLargeFile sauvegarde les fichiers volumineux (100MB - 5GB) avec chunking
-func (m *Manager) backupLargeFile(file index.FileEntry, backupID string) error {
-	fileName := filepath.Base(file.Path)
-	utils.Debug("🔄 Processing large file: %s (%d bytes, %.2f MB)", file.Path, file.Size, float64(file.Size)/1024/1024)
-
-	// Initialiser les statistiques de chunking
-	stats := NewBackupStats()
-	stats.TotalSize = file.Size
-	stats.UpdateStatus(fmt.Sprintf("Processing large file: %s", fileName))
-
-	// Arrêter le monitoring à la fin de la fonction
-	defer stats.StopMonitoring()
-
-	// Read file in chunks and process each chunk
-	fileHandle, err := os.Open(file.Path)
-	if err != nil {
-		return fmt.Errorf("error opening large file: %w", err)
-	}
-	defer fileHandle.Close()
-
-	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
-	utils.Debug("📋 Starting chunked upload for large file: %s", file.Path)
+// saveToStorageWithRetry sauvegarde avec retry et timeout
+// L'annulation de ctx (fichier abandonné) interrompt le transfert en cours et les tentatives suivantes.
+func (m *Manager) saveToStorageWithRetry(ctx context.Context, key string, data []byte) error {
+	// Délai propre à ce transfert, proportionnel à la taille des données
+	timeout := m.transferTimeout(len(data))
 
-	// Get chunk size from config or use default
-	chunkSizeStr := m.config.Backup.ChunkSize
-	if chunkSizeStr == "" {
-		chunkSizeStr = "10MB" // Default
+	// Configuration du retry
+	maxRetries := m.config.Backup.RetryAttempts
+	if maxRetries <= 0 {
+		maxRetries = 1 // Au moins 1 tentative
 	}
 
-	chunkSize, err := parseSizeString(chunkSizeStr)
-	if err != nil {
-		utils.Warn("Invalid chunk_size config, using default 10MB: %v", err)
-		chunkSize = 10 * 1024 * 1024 // 10MB default
+	baseDelay := time.Duration(m.config.Backup.RetryDelay) * time.Second
+	if baseDelay <= 0 {
+		baseDelay = 2 * time.Second // Default 2 seconds
 	}
 
-	utils.Debug("🔧 Using chunk size: %s (%d bytes) for large file", chunkSizeStr, chunkSize)
+	var lastError error
 
-	chunkNumber := 0
-	totalProcessed := int64(0)
+	// Boucle de retry avec backoff exponentiel
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			// Calculer le délai avec backoff exponentiel
+			delay := baseDelay * time.Duration(1<<(attempt-1))
+			if delay > 60*time.Second { // Cap à 60 secondes
+				delay = 60 * time.Second
+			}
 
-	// Calculate total chunks for progress bar
-	totalChunks := (file.Size + chunkSize - 1) / chunkSize // Ceiling division
-	stats.TotalChunks = int(totalChunks)
+			utils.Debug("🔄 Retry attempt %d/%d for %s after %v delay",
+				attempt+1, maxRetries, key, delay)
 
-	// Afficher la progression avec la barre multi-fichiers si disponible
-	if m.multiProgressBar != nil {
-		m.multiProgressBar.SetCurrentFile(fileName, file.Size)
-		m.multiProgressBar.UpdateChunk(fileName, 0, int64(totalChunks))
-	} else {
-		utils.ProgressStep(fmt.Sprintf("Processing large file: %s (%.2f MB)",
-			fileName, float64(file.Size)/1024/1024))
-	}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+		}
 
-	utils.Debug("📊 File processing plan:")
-	utils.Debug("   - Total file size: %.2f MB", float64(file.Size)/1024/1024)
-	utils.Debug("   - Chunk size: %.2f MB", float64(chunkSize)/1024/1024)
-	utils.Debug("   - Total chunks: %d", totalChunks)
-	utils.Debug("   - Storage key: %s", storageKey)
+		// Attendre la fin d'une pause globale (throttling ou disjoncteur ouvert)
+		m.uploads.wait()
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("upload of %s cancelled: %w", key, err)
+		}
+		// Le backoff et la pause globale ne comptent pas comme un transfert figé
+		m.stall.touch(key)
+		requestStart := time.Now()
 
-	// Démarrer le monitoring spécifique pour ce fichier chunké
-	m.startChunkMonitoring(stats, true)
+		// Contexte de cette tentative: son délai propre, et l'abandon du fichier
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := m.storageClient.UploadStream(attemptCtx, key, m.newProgressReader(attemptCtx, key, data), int64(len(data)))
+		timedOut := attemptCtx.Err() == context.DeadlineExceeded
+		cancel()
+		if ctx.Err() != nil {
+			// Abandon du fichier: ni échec du backend, ni nouvelle tentative
+			return fmt.Errorf("upload of %s cancelled: %w", key, ctx.Err())
+		}
+		if err != nil && timedOut {
+			err = fmt.Errorf("upload timeout after %v", timeout)
+		}
 
-	for {
-		// Read chunk
-		chunk := make([]byte, chunkSize)
-		n, err := fileHandle.Read(chunk)
-		if n == 0 {
-			break // End of file
+		m.uploads.record(err, time.Since(requestStart))
+		if err == nil && m.config.Backup.VerifyUploads {
+			err = m.verifyUpload(key, data)
 		}
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
+		if err == nil {
+			// Succès !
+			m.stall.touch(key)
+			if err := m.state.JournalUpload(m.backupID, key, int64(len(data))); err != nil {
+				utils.Debug("Upload journal: %v", err)
+			}
+			if attempt > 0 {
+				utils.Info("✅ Upload succeeded on retry attempt %d for %s", attempt+1, key)
+			}
+			return nil
 		}
 
-		chunk = chunk[:n] // Adjust slice to actual bytes read
-		totalProcessed += int64(n)
+		// Erreur, la stocker pour le log final
+		lastError = err
 
-		// Mettre à jour les statistiques de chunking
-		stats.UpdateChunkStats(chunkNumber+1, int(totalChunks), int64(n))
-
-		// Mettre à jour la progression des chunks avec la barre multi-fichiers si disponible
-		if m.multiProgressBar != nil {
-			m.multiProgressBar.UpdateChunk(fileName, int64(chunkNumber+1), int64(totalChunks))
-		} else {
-			// Show progress for each chunk with file name for clarity
-			progress := float64(chunkNumber+1) / float64(totalChunks) * 100
-			utils.ProgressStep(fmt.Sprintf("[%s] Chunk %d/%d (%.1f%%) - %.2f MB / %.2f MB",
-				fileName, chunkNumber+1, totalChunks, progress,
-				float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024))
-		}
-
-		utils.Debug("🔄 Processing chunk %d: %d bytes (%.2f MB), total: %.2f MB / %.2f MB",
-			chunkNumber, n, float64(n)/1024/1024, float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024)
-
-		// Encrypt chunk
-		utils.Debug("🔐 Encrypting chunk %d...", chunkNumber)
-		encryptedChunk, err := m.encryptor.Encrypt(chunk)
-		if err != nil {
-			return fmt.Errorf("error encrypting chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d encrypted successfully", chunkNumber)
-
-		// Upload chunk
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
-		utils.Debug("📤 Uploading chunk %d to storage: %s", chunkNumber, chunkKey)
-		if err := m.saveToStorageWithRetry(chunkKey, encryptedChunk); err != nil {
-			return fmt.Errorf("error uploading chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d uploaded successfully", chunkNumber)
-
-		chunkNumber++
-	}
-
-	// Show completion
-	utils.ProgressSuccess(fmt.Sprintf("Large file completed: %s (%.2f MB in %d chunks)",
-		filepath.Base(file.Path), float64(file.Size)/1024/1024, chunkNumber))
-
-	// Create metadata file
-	utils.Debug("📝 Creating metadata file for chunked file...")
-	metadata := map[string]interface{}{
-		"original_file": file.Path,
-		"file_size":     file.Size,
-		"chunks":        chunkNumber,
-		"storage_key":   storageKey,
-		"chunked":       true,
-	}
-
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("error creating metadata: %w", err)
-	}
-
-	metadataKey := fmt.Sprintf("%s.metadata", storageKey)
-	utils.Debug("📤 Uploading metadata file: %s", metadataKey)
-	if err := m.saveToStorageWithRetry(metadataKey, metadataJSON); err != nil {
-		return fmt.Errorf("error uploading metadata: %w", err)
-	}
-	utils.Debug("✅ Metadata file uploaded successfully")
-
-	// Stocker seulement la clé relative, pas la clé complète
-	file.StorageKey = file.GetStorageKey()
-	utils.Debug("🎯 Large file backed up in %d chunks: %s -> %s", chunkNumber, file.Path, storageKey)
-	return nil
-}
-
-// backupStandardFile sauvegarde un fichier standard (< 100MB)
-func (m *Manager) backupStandardFile(file index.FileEntry, backupID string) error {
-
-	utils.Debug("🔄 Processing standard file: %s (%.2f MB)", file.Path, float64(file.Size)/1024/1024)
-
-	// Lire le fichier
-	fileData, err := os.ReadFile(file.Path)
-	if err != nil {
-		return fmt.Errorf("error reading file: %w", err)
-	}
-
-	// Compresser les données si configuré
-	if m.config.Backup.CompressionLevel > 0 {
-		utils.Debug("🗜️  Compressing file...")
-		compressedData, err := m.compressor.Compress(fileData)
-		if err != nil {
-			return fmt.Errorf("error compressing file: %w", err)
-		}
-		fileData = compressedData
-		utils.Debug("✅ File compressed successfully")
-	}
-
-	// Chiffrer les données
-	utils.Debug("🔐 Encrypting file...")
-	encryptedData, err := m.encryptor.Encrypt(fileData)
-	if err != nil {
-		return fmt.Errorf("error encrypting file: %w", err)
-	}
-	utils.Debug("✅ File encrypted successfully")
-
-	// Sauvegarder vers le stockage
-	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
-	utils.Debug("📤 Uploading file to storage: %s", storageKey)
-	if err := m.saveToStorageWithRetry(storageKey, encryptedData); err != nil {
-		return fmt.Errorf("error uploading file: %w", err)
-	}
-	utils.Debug("✅ File uploaded successfully")
-
-	// Stocker seulement la clé relative, pas la clé complète
-	file.StorageKey = file.GetStorageKey()
-	utils.Debug("🎯 Standard file backed up: %s -> %s", file.Path, storageKey)
-	return nil
-}
-
-// backupUltraLargeFile sauvegarde les fichiers extrêmement volumineux (> 5GB) avec monitoring
-func (m *Manager) backupUltraLargeFile(file index.FileEntry, backupID string) error {
-	fileName := filepath.Base(file.Path)
-	utils.Debug("🔄 Processing ultra-large file: %s (%d bytes, %.2f MB)", file.Path, file.Size, float64(file.Size)/1024/1024)
-
-	// Initialiser les statistiques de chunking
-	stats := NewBackupStats()
-	stats.TotalSize = file.Size
-	stats.UpdateStatus(fmt.Sprintf("Processing ultra-large file: %s", fileName))
-
-	// Arrêter le monitoring à la fin de la fonction
-	defer stats.StopMonitoring()
-
-	// Read file in chunks and process each chunk
-	fileHandle, err := os.Open(file.Path)
-	if err != nil {
-		return fmt.Errorf("error opening ultra-large file: %w", err)
-	}
-	defer fileHandle.Close()
-
-	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
-	utils.Debug("📋 Starting chunked upload for ultra-large file: %s", file.Path)
-
-	// Get chunk size from config or use default
-	chunkSizeStr := m.config.Backup.ChunkSizeLarge
-	if chunkSizeStr == "" {
-		chunkSizeStr = "50MB" // Default
-	}
-
-	chunkSize, err := parseSizeString(chunkSizeStr)
-	if err != nil {
-		utils.Warn("Invalid chunk_size_large config, using default 50MB: %v", err)
-		chunkSize = 50 * 1024 * 1024 // 50MB default
-	}
-
-	utils.Debug("🔧 Using chunk size: %s (%d bytes) for ultra-large file", chunkSizeStr, chunkSize)
-
-	chunkNumber := 0
-	totalProcessed := int64(0)
-
-	// Calculate total chunks for progress bar
-	totalChunks := (file.Size + chunkSize - 1) / chunkSize // Ceiling division
-	stats.TotalChunks = int(totalChunks)
-
-	// Afficher la progression avec la barre multi-fichiers si disponible
-	if m.multiProgressBar != nil {
-		m.multiProgressBar.SetCurrentFile(fileName, file.Size)
-		m.multiProgressBar.UpdateChunk(fileName, 0, int64(totalChunks))
-	} else {
-		utils.ProgressStep(fmt.Sprintf("Processing large file: %s (%.2f MB)",
-			fileName, float64(file.Size)/1024/1024))
-	}
-
-	utils.Debug("📊 File processing plan:")
-	utils.Debug("   - Total file size: %.2f MB", float64(file.Size)/1024/1024)
-	utils.Debug("   - Chunk size: %.2f MB", float64(chunkSize)/1024/1024)
-	utils.Debug("   - Total chunks: %d", totalChunks)
-	utils.Debug("   - Storage key: %s", storageKey)
-
-	// Démarrer le monitoring spécifique pour ce fichier chunké
-	m.startChunkMonitoring(stats, true)
-
-	for {
-		// Read chunk
-		chunk := make([]byte, chunkSize)
-		n, err := fileHandle.Read(chunk)
-		if n == 0 {
-			break // End of file
-		}
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
-		}
-
-		chunk = chunk[:n] // Adjust slice to actual bytes read
-		totalProcessed += int64(n)
-
-		// Mettre à jour les statistiques de chunking
-		stats.UpdateChunkStats(chunkNumber+1, int(totalChunks), int64(n))
-
-		// Mettre à jour la progression des chunks avec la barre multi-fichiers si disponible
-		if m.multiProgressBar != nil {
-			m.multiProgressBar.UpdateChunk(fileName, int64(chunkNumber+1), int64(totalChunks))
-		} else {
-			// Show progress for each chunk with file name for clarity
-			progress := float64(chunkNumber+1) / float64(totalChunks) * 100
-			utils.ProgressStep(fmt.Sprintf("[%s] Chunk %d/%d (%.1f%%) - %.2f MB / %.2f MB",
-				fileName, chunkNumber+1, totalChunks, progress,
-				float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024))
-		}
-
-		utils.Debug("🔄 Processing chunk %d: %d bytes (%.2f MB), total: %.2f MB / %.2f MB",
-			chunkNumber, n, float64(n)/1024/1024, float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024)
-
-		// Encrypt chunk (no compression for ultra-large files)
-		utils.Debug("🔐 Encrypting chunk %d...", chunkNumber)
-		encryptedChunk, err := m.encryptor.Encrypt(chunk)
-		if err != nil {
-			return fmt.Errorf("error encrypting chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d encrypted successfully", chunkNumber)
-
-		// Upload chunk
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
-		utils.Debug("📤 Uploading chunk %d to storage: %s", chunkNumber, chunkKey)
-		if err := m.saveToStorageWithRetry(chunkKey, encryptedChunk); err != nil {
-			return fmt.Errorf("error uploading chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d uploaded successfully", chunkNumber)
-
-		chunkNumber++
-	}
-
-	// Show completion
-	utils.ProgressSuccess(fmt.Sprintf("Large file completed: %s (%.2f MB in %d chunks)",
-		filepath.Base(file.Path), float64(file.Size)/1024/1024, chunkNumber))
-
-	// Create metadata file
-	utils.Debug("📝 Creating metadata file for chunked file...")
-	metadata := map[string]interface{}{
-		"original_file": file.Path,
-		"file_size":     file.Size,
-		"chunks":        chunkNumber,
-		"storage_key":   storageKey,
-		"chunked":       true,
-	}
-
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("error creating metadata: %w", err)
-	}
-
-	metadataKey := fmt.Sprintf("%s.metadata", storageKey)
-	utils.Debug("📤 Uploading metadata file: %s", metadataKey)
-	if err := m.saveToStorageWithRetry(metadataKey, metadataJSON); err != nil {
-		return fmt.Errorf("error uploading metadata: %w", err)
-	}
-	utils.Debug("✅ Metadata file uploaded successfully")
-
-	// Stocker seulement la clé relative, pas la clé complète
-	file.StorageKey = file.GetStorageKey()
-	utils.Debug("🎯 Ultra-large file backed up in %d chunks: %s -> %s", chunkNumber, file.Path, storageKey)
-	return nil
-}
-
-// backupVeryLargeFile sauvegarde les fichiers très volumineux (100MB - 5GB) avec chunking
-func (m *Manager) backupVeryLargeFile(file index.FileEntry, backupID string) error {
-	fileName := filepath.Base(file.Path)
-	utils.Debug("🔄 Processing very large file: %s (%d bytes, %.2f MB)", file.Path, file.Size, float64(file.Size)/1024/1024)
-
-	// Show progress for very large file
-	utils.ProgressStep(fmt.Sprintf("Processing very large file: %s (%.2f MB)",
-		fileName, float64(file.Size)/1024/1024))
-
-	// Read file in chunks and process each chunk
-	fileHandle, err := os.Open(file.Path)
-	if err != nil {
-		return fmt.Errorf("error opening very large file: %w", err)
-	}
-	defer fileHandle.Close()
-
-	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
-	utils.Debug("📋 Starting chunked upload for very large file: %s", file.Path)
-
-	// Get chunk size from config or use default
-	chunkSizeStr := m.config.Backup.ChunkSizeLarge
-	if chunkSizeStr == "" {
-		chunkSizeStr = "25MB" // Default for very large files
-	}
-
-	chunkSize, err := parseSizeString(chunkSizeStr)
-	if err != nil {
-		utils.Warn("Invalid chunk_size_large config, using default 25MB: %v", err)
-		chunkSize = 25 * 1024 * 1024 // 25MB default
-	}
-
-	utils.Debug("🔧 Using chunk size: %s (%d bytes) for very large file", chunkSizeStr, chunkSize)
-
-	chunkNumber := 0
-	totalProcessed := int64(0)
-
-	// Calculate total chunks for progress bar
-	totalChunks := (file.Size + chunkSize - 1) / chunkSize // Ceiling division
-
-	utils.Debug("📊 File processing plan:")
-	utils.Debug("   - Total file size: %.2f MB", float64(file.Size)/1024/1024)
-	utils.Debug("   - Chunk size: %.2f MB", float64(chunkSize)/1024/1024)
-	utils.Debug("   - Total chunks: %d", totalChunks)
-	utils.Debug("   - Storage key: %s", storageKey)
-
-	for {
-		// Read chunk
-		chunk := make([]byte, chunkSize)
-		n, err := fileHandle.Read(chunk)
-		if n == 0 {
-			break // End of file
-		}
-		if err != nil && err != io.EOF {
-			return fmt.Errorf("error reading chunk %d: %w", chunkNumber, err)
-		}
-
-		chunk = chunk[:n] // Adjust slice to actual bytes read
-		totalProcessed += int64(n)
-
-		// Show progress for each chunk
-		progress := float64(chunkNumber+1) / float64(totalChunks) * 100
-		utils.ProgressStep(fmt.Sprintf("[%s] Chunk %d/%d (%.1f%%) - %.2f MB / %.2f MB",
-			fileName, chunkNumber+1, totalChunks, progress,
-			float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024))
-
-		utils.Debug("🔄 Processing chunk %d: %d bytes (%.2f MB), total: %.2f MB / %.2f MB",
-			chunkNumber, n, float64(n)/1024/1024, float64(totalProcessed)/1024/1024, float64(file.Size)/1024/1024)
-
-		// Skip compression for very large files to save memory
-		utils.Debug("⏭️ Skipping compression for very large file chunk: %s", file.Path)
-
-		// Encrypt chunk (no compression for very large files)
-		utils.Debug("🔐 Encrypting chunk %d...", chunkNumber)
-		encryptedChunk, err := m.encryptor.Encrypt(chunk)
-		if err != nil {
-			return fmt.Errorf("error encrypting chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d encrypted successfully", chunkNumber)
-
-		// Upload chunk
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", storageKey, chunkNumber)
-		utils.Debug("📤 Uploading chunk %d to storage: %s", chunkNumber, chunkKey)
-		if err := m.saveToStorageWithRetry(chunkKey, encryptedChunk); err != nil {
-			return fmt.Errorf("error uploading chunk %d: %w", chunkNumber, err)
-		}
-		utils.Debug("✅ Chunk %d uploaded successfully", chunkNumber)
-
-		chunkNumber++
-	}
-
-	// Show completion
-	utils.ProgressSuccess(fmt.Sprintf("Very large file completed: %s (%.2f MB in %d chunks)",
-		fileName, float64(file.Size)/1024/1024, chunkNumber))
-
-	// Create metadata file
-	utils.Debug("📝 Creating metadata file for chunked file...")
-	metadata := map[string]interface{}{
-		"original_file": file.Path,
-		"file_size":     file.Size,
-		"chunks":        chunkNumber,
-		"storage_key":   storageKey,
-		"chunked":       true,
-	}
-
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
-		return fmt.Errorf("error creating metadata: %w", err)
-	}
-
-	metadataKey := fmt.Sprintf("%s.metadata", storageKey)
-	utils.Debug("📤 Uploading metadata file: %s", metadataKey)
-	if err := m.saveToStorageWithRetry(metadataKey, metadataJSON); err != nil {
-		return fmt.Errorf("error uploading metadata: %w", err)
-	}
-	utils.Debug("✅ Metadata file uploaded successfully")
-
-	// Stocker seulement la clé relative, pas la clé complète
-	file.StorageKey = file.GetStorageKey()
-	utils.Debug("🎯 Very large file backed up in %d chunks: %s -> %s", chunkNumber, file.Path, storageKey)
-	return nil
-}
-
-// backupStandardLargeFile sauvegarde les fichiers volumineux (100MB - 1GB)
-func (m *Manager) backupStandardLargeFile(file index.FileEntry, backupID string) error {
-	fileName := filepath.Base(file.Path)
-	utils.Debug("Processing standard large file: %s (%d bytes, %.2f MB)", file.Path, file.Size, float64(file.Size)/1024/1024)
-
-	// Show progress for standard large file
-	utils.ProgressStep(fmt.Sprintf("Processing large file: %s (%.2f MB)",
-		fileName, float64(file.Size)/1024/1024))
-
-	// Use standard buffer for large files
-	bufferSize := 2 * 1024 * 1024 // 2MB buffer
-
-	data, err := utils.ReadFileWithBuffer(file.Path, bufferSize)
-	if err != nil {
-		return fmt.Errorf("error reading large file: %w", err)
-	}
-
-	// Try compression for standard large files
-	compressedData, err := m.compressor.CompressAdaptive(data, file.Path)
-	if err != nil {
-		utils.Debug("Compression failed for large file, using uncompressed: %s", file.Path)
-		compressedData = data
-	}
-
-	// Encrypt
-	encryptedData, err := m.encryptor.Encrypt(compressedData)
-	if err != nil {
-		return fmt.Errorf("error encrypting large file: %w", err)
-	}
-
-	// Save to storage
-	storageKey := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
-	if err := m.saveToStorageWithRetry(storageKey, encryptedData); err != nil {
-		return fmt.Errorf("error saving large file: %w", err)
-	}
-
-	// Stocker seulement la clé relative, pas la clé complète
-	file.StorageKey = file.GetStorageKey()
-	utils.ProgressSuccess(fmt.Sprintf("Large file completed: %s (%.2f MB)",
-		fileName, float64(file.Size)/1024/1024))
-	utils.Debug("Standard large file backed up: %s -> %s", file.Path, storageKey)
-	return nil
-}
-
-// saveToStorageWithRetry sauvegarde avec retry et timeout
-func (m *Manager) saveToStorageWithRetry(key string, data []byte) error {
-	// Timeout pour éviter les blocages infinis
-	timeout := time.Duration(m.config.Backup.NetworkTimeout) * time.Second
-	if timeout == 0 {
-		timeout = 30 * time.Second // Default 30 seconds
-	}
-
-	// Configuration du retry
-	maxRetries := m.config.Backup.RetryAttempts
-	if maxRetries <= 0 {
-		maxRetries = 1 // Au moins 1 tentative
-	}
-
-	baseDelay := time.Duration(m.config.Backup.RetryDelay) * time.Second
-	if baseDelay <= 0 {
-		baseDelay = 2 * time.Second // Default 2 seconds
-	}
-
-	var lastError error
-
-	// Boucle de retry avec backoff exponentiel
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			// Calculer le délai avec backoff exponentiel
-			delay := baseDelay * time.Duration(1<<(attempt-1))
-			if delay > 60*time.Second { // Cap à 60 secondes
-				delay = 60 * time.Second
-			}
-
-			utils.Debug("🔄 Retry attempt %d/%d for %s after %v delay",
-				attempt+1, maxRetries, key, delay)
-
-			time.Sleep(delay)
-		}
-
-		// Créer un contexte avec timeout pour cette tentative
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-
-		// Canal pour le résultat
-		resultChan := make(chan error, 1)
-
-		// Exécuter l'upload en arrière-plan
-		go func() {
-			resultChan <- m.storageClient.Upload(key, data)
-		}()
-
-		// Attendre avec timeout
-		select {
-		case err := <-resultChan:
-			cancel()
-			if err == nil {
-				// Succès !
-				if attempt > 0 {
-					utils.Info("✅ Upload succeeded on retry attempt %d for %s", attempt+1, key)
-				}
-				return nil
-			}
-
-			// Erreur, la stocker pour le log final
-			lastError = err
-
-			// Log de l'erreur
-			if attempt < maxRetries-1 {
-				utils.Warn("⚠️  Upload failed for %s (attempt %d/%d): %v",
-					key, attempt+1, maxRetries, err)
-			}
-
-		case <-ctx.Done():
-			cancel()
-			lastError = fmt.Errorf("upload timeout after %v", timeout)
-
-			if attempt < maxRetries-1 {
-				utils.Warn("⚠️  Upload timeout for %s (attempt %d/%d) after %v",
-					key, attempt+1, maxRetries, timeout)
-			}
+		// Log de l'erreur
+		if attempt < maxRetries-1 {
+			utils.Warn("⚠️  Upload failed for %s (attempt %d/%d): %v",
+				key, attempt+1, maxRetries, err)
 		}
 	}
 
@@ -1790,30 +1595,13 @@ func (m *Manager) calculateEncryptedSize(added, modified []index.FileEntry) int6
 	return 0
 }
 
-// deleteBackupFiles supprime les fichiers de données d'une sauvegarde
-func (m *Manager) deleteBackupFiles(backupIndex *index.BackupIndex) error {
-	utils.Info("Deleting data files for: %s", backupIndex.BackupID)
-
-	for _, file := range backupIndex.Files {
-		if file.StorageKey != "" {
-			if err := m.storageClient.DeleteObject(file.StorageKey); err != nil {
-				utils.Warn("Impossible de supprimer le fichier %s: %v", file.StorageKey, err)
-			} else {
-				utils.Debug("File deleted: %s", file.StorageKey)
-			}
-		}
-	}
-
-	return nil
-}
-
 // deleteBackupIndex supprime l'index d'une sauvegarde
 func (m *Manager) deleteBackupIndex(backupID string) error {
 	utils.Info("Suppression de l'index pour: %s", backupID)
 
 	indexKey := fmt.Sprintf("indexes/%s.json", backupID)
 	if err := m.storageClient.DeleteObject(indexKey); err != nil {
-		return fmt.Errorf("error during la suppression de l'index: %w", err)
+		return fmt.Errorf("error deleting index: %w", err)
 	}
 
 	utils.Debug("Index deleted: %s", indexKey)
@@ -1822,57 +1610,7 @@ func (m *Manager) deleteBackupIndex(backupID string) error {
 
 // parseSizeString parse une chaîne de taille (e.g., "50MB", "1GB") en bytes
 func parseSizeString(sizeStr string) (int64, error) {
-	sizeStr = strings.TrimSpace(sizeStr)
-
-	// Si c'est déjà un nombre, le traiter comme des bytes
-	if number, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
-		return number, nil
-	}
-
-	// Extraire le nombre et l'unité
-	var number int64
-	var unit string
-
-	// Trouver le premier caractère non-numérique
-	for i := 0; i < len(sizeStr); i++ {
-		if sizeStr[i] < '0' || sizeStr[i] > '9' {
-			numberStr := sizeStr[:i]
-			unit = sizeStr[i:]
-
-			var err error
-			number, err = strconv.ParseInt(numberStr, 10, 64)
-			if err != nil {
-				return 0, fmt.Errorf("invalid number in size string: %s", sizeStr)
-			}
-			break
-		}
-	}
-
-	if number == 0 {
-		return 0, fmt.Errorf("no number found in size string: %s", sizeStr)
-	}
-
-	// Convertir selon l'unité
-	unit = strings.ToUpper(strings.TrimSpace(unit))
-	switch unit {
-	case "B", "":
-		return number, nil
-	case "KB":
-		return number * 1024, nil
-	case "MB":
-		return number * 1024 * 1024, nil
-	case "GB":
-		return number * 1024 * 1024 * 1024, nil
-	case "TB":
-		return number * 1024 * 1024 * 1024 * 1024, nil
-	default:
-		return 0, fmt.Errorf("unknown unit in size string: %s", sizeStr)
-	}
-}
-
-// saveToStorage sauvegarde des données dans le stockage
-func (m *Manager) saveToStorage(key string, data []byte) error {
-	return m.storageClient.Upload(key, data)
+	return utils.ParseSize(sizeStr)
 }
 
 // logBackupStart logs the start of backup operation
@@ -1888,12 +1626,43 @@ func (m *Manager) logBackupStart(backupName string, verbose bool) {
 		utils.Info("   6. Create and save backup index")
 		utils.Info("   7. Apply retention policy")
 	} else {
-		utils.ProgressStep(fmt.Sprintf("🔄 🚀 Starting backup: %s", backupName))
+		utils.ProgressStep(utils.Msg("backup.step.start", backupName))
+	}
+}
+
+// warnUnencrypted signale une sauvegarde sans chiffrement (configuration chargée)
+func (m *Manager) warnUnencrypted(verbose bool) {
+	if m.config.Backup.EncryptionAlgo != string(crypto.None) {
+		return
+	}
+	if verbose {
+		utils.Warn("⚠️  Encryption disabled (encryption_algo: none): data and indexes are stored in clear")
+	} else {
+		utils.ProgressWarning("Encryption disabled (encryption_algo: none): data and indexes are stored in clear")
+	}
+}
+
+// resolveSources retourne les sources de la sauvegarde: celles données, sinon backup.sources.
+// Plusieurs sources sont rendues absolues pour calculer leur parent commun.
+func (m *Manager) resolveSources(sources []string) ([]string, error) {
+	if len(sources) == 0 {
+		sources = m.config.Backup.Sources
 	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: source path is required (--source or backup.sources)", utils.ErrConfig)
+	}
+	resolved, err := utils.ResolveSources(sources)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	if len(sources) == 1 {
+		return sources, nil
+	}
+	return resolved, nil
 }
 
 // prepareBackup initializes the backup manager
-func (m *Manager) prepareBackup(sourcePath string) error {
+func (m *Manager) prepareBackup() error {
 	utils.Debug("🔧 Task: Initializing backup manager")
 
 	// Charger la configuration
@@ -1905,34 +1674,53 @@ func (m *Manager) prepareBackup(sourcePath string) error {
 
 	// Initialiser les composants
 	if err := m.initializeComponents(); err != nil {
-		return fmt.Errorf("error during l'initialisation: %w", err)
+		return fmt.Errorf("initialization error: %w", err)
 	}
 
+	fileHandlers, err := handlers.NewSet(m.config.Backup.FileHandlers)
+	if err != nil {
+		return err
+	}
+	m.handlers = fileHandlers
+
 	utils.Debug("✅ Task completed: Backup manager initialized")
 	return nil
 }
 
 // createCurrentIndex creates the current file index
-func (m *Manager) createCurrentIndex(sourcePath, backupID string, verbose bool) (*index.BackupIndex, error) {
+func (m *Manager) createCurrentIndex(sources []string, backupID string, verbose bool) (*index.BackupIndex, error) {
 	if verbose {
 		utils.Info("📋 Task 2: Creating current file index")
-		utils.Info("   - Scanning directory: %s", sourcePath)
+		for _, source := range sources {
+			utils.Info("   - Scanning directory: %s", source)
+		}
 		utils.Info("   - Calculating checksums")
 		utils.Info("   - Building file index")
 	} else {
-		utils.ProgressStep("Creating index...")
+		utils.ProgressStep(utils.Msg("backup.step.create_index"))
+	}
+
+	if m.oneFileSystem {
+		m.indexMgr.SetOneFileSystem(true)
+	}
+	if m.symlinks != "" {
+		m.indexMgr.SetSymlinkPolicy(m.symlinks)
+	}
+	checksumMode := index.ChecksumModeOf(m.config)
+	if checksumMode == index.ChecksumModeMtimeSize {
+		utils.Warn("⚠️  checksum_mode mtime-size: files are compared by size and modification time only, content changes that keep both are not backed up")
 	}
 
-	index, err := m.indexMgr.CreateIndex(sourcePath, backupID, verbose)
+	currentIndex, err := m.indexMgr.CreateIndexFromSources(sources, backupID, checksumMode, verbose)
 	if err != nil {
 		return nil, fmt.Errorf("error creating index: %w", err)
 	}
 
 	if verbose {
-		utils.Info("✅ Task 2 completed: Index created with %d files", index.TotalFiles)
+		utils.Info("✅ Task 2 completed: Index created with %d files", currentIndex.TotalFiles)
 	}
 
-	return index, nil
+	return currentIndex, nil
 }
 
 // calculateBackupDiff calculates differences between current and previous backup
@@ -1942,22 +1730,26 @@ func (m *Manager) calculateBackupDiff(currentIndex *index.BackupIndex, backupNam
 		utils.Info("   - Searching for existing backups")
 		utils.Info("   - Loading previous index (if exists)")
 	} else {
-		utils.ProgressStep("Searching for previous backup...")
+		utils.ProgressStep(utils.Msg("backup.step.find_previous"))
 	}
 
-	// Chercher la sauvegarde précédente pour comparaison
-	previousIndex, err := m.findPreviousBackup(backupName)
-	if err != nil {
-		utils.Debug("Error finding previous backup: %v", err)
-		// Si on ne peut pas charger l'index précédent, traiter comme un premier backup
-		previousIndex = nil
+	// Chercher la sauvegarde précédente pour comparaison (un import est toujours complet)
+	var previousIndex *index.BackupIndex
+	var err error
+	if m.importedFrom == "" {
+		previousIndex, err = m.findPreviousBackup(backupName)
+		if err != nil {
+			utils.Debug("Error finding previous backup: %v", err)
+			// Si on ne peut pas charger l'index précédent, traiter comme un premier backup
+			previousIndex = nil
+		}
 	}
 
 	if previousIndex == nil {
 		if verbose {
 			utils.Info("No previous backup found or unable to load, performing full backup")
 		} else {
-			utils.ProgressInfo("First backup - performing full backup")
+			utils.ProgressInfo(utils.Msg("backup.step.first_backup"))
 		}
 	} else {
 		if verbose {
@@ -1965,6 +1757,17 @@ func (m *Manager) calculateBackupDiff(currentIndex *index.BackupIndex, backupNam
 				previousIndex.BackupID,
 				previousIndex.CreatedAt.Format("2006-01-02 15:04:05"))
 		}
+
+		// Avertir si le même nom de sauvegarde est utilisé depuis une autre machine
+		if !currentIndex.Origin.SameMachine(previousIndex.Origin) {
+			message := fmt.Sprintf("Backup name '%s' was previously used from host %s (current host: %s)",
+				backupName, previousIndex.Origin.Hostname, currentIndex.Origin.Hostname)
+			if verbose {
+				utils.Warn("%s", message)
+			} else {
+				utils.ProgressWarning(message)
+			}
+		}
 	}
 
 	if verbose {
@@ -1974,15 +1777,17 @@ func (m *Manager) calculateBackupDiff(currentIndex *index.BackupIndex, backupNam
 		utils.Info("   - Identifying modified files")
 		utils.Info("   - Identifying deleted files")
 	} else {
-		utils.ProgressStep("Comparing indexes...")
+		utils.ProgressStep(utils.Msg("backup.step.compare"))
 	}
 
+	m.previousIndex = previousIndex
+
 	// Comparer les index pour déterminer les changements
 	var diff *index.IndexDiff
 	if previousIndex != nil {
 		diff, err = m.indexMgr.CompareIndexes(currentIndex, previousIndex)
 		if err != nil {
-			return nil, fmt.Errorf("error during la comparaison des index: %w", err)
+			return nil, fmt.Errorf("error comparing indexes: %w", err)
 		}
 
 		if verbose {
@@ -2013,7 +1818,7 @@ func (m *Manager) calculateBackupDiff(currentIndex *index.BackupIndex, backupNam
 }
 
 // executeBackup executes the actual backup process
-func (m *Manager) executeBackup(currentIndex *index.BackupIndex, diff *index.IndexDiff, backupID string, backupName string, verbose bool) error {
+func (m *Manager) executeBackup(currentIndex *index.BackupIndex, diff *index.IndexDiff, backupID string, backupName string, policy utils.ErrorPolicy, verbose bool) error {
 	totalFilesToBackup := len(diff.Added) + len(diff.Modified)
 
 	if verbose {
@@ -2026,13 +1831,40 @@ func (m *Manager) executeBackup(currentIndex *index.BackupIndex, diff *index.Ind
 		utils.Info("   - Uploading to storage")
 	}
 
+	toBackup := make([]index.FileEntry, 0, totalFilesToBackup)
+	m.prepareDictionary(backupName, append(append(toBackup, diff.Added...), diff.Modified...), verbose)
+
 	// Sauvegarder les fichiers modifiés/ajoutés
-	if err := m.backupFiles(diff.Added, diff.Modified, backupID, verbose); err != nil {
-		return fmt.Errorf("error saving des fichiers: %w", err)
+	failed, err := m.backupFiles(diff.Added, diff.Modified, backupID, verbose)
+	if err != nil {
+		return fmt.Errorf("error saving files: %w", err)
+	}
+
+	// Appliquer la politique d'erreurs avant d'écrire l'index (les fichiers ignorés ne sont pas des échecs)
+	failedCount := countFailures(failed)
+	if policy.Exceeded(failedCount, totalFilesToBackup) {
+		return fmt.Errorf("%w: %d of %d files failed (error policy: %s), backup index not written",
+			utils.ErrPartialFailure, failedCount, totalFilesToBackup, policy)
+	}
+	markFileStatuses(currentIndex, failed)
+	currentIndex.Status = index.BackupStatusOf(failedCount, totalFilesToBackup)
+	m.markDuplicates(currentIndex)
+	m.markCompression(currentIndex)
+	m.markEntropy(currentIndex)
+	m.markPrepared(currentIndex)
+	m.markDataKeys(currentIndex)
+	if failedCount > 0 {
+		if verbose {
+			utils.Warn("⚠️  %d files failed and are recorded as failed in the index (error policy: %s)", failedCount, policy)
+		} else {
+			utils.ProgressWarning(utils.Msg("backup.step.failed_files", failedCount))
+		}
 	}
 
 	// Nettoyer les anciens objets S3 non référencés dans cette sauvegarde
-	if err := m.cleanupUnreferencedObjects(backupID, currentIndex, verbose); err != nil {
+	if m.config.Backup.AppendOnly {
+		utils.Debug("Append-only repository: unreferenced objects cleanup skipped")
+	} else if err := m.cleanupUnreferencedObjects(backupID, currentIndex, verbose); err != nil {
 		if verbose {
 			utils.Warn("⚠️  Warning: Failed to cleanup unreferenced objects: %v", err)
 		}
@@ -2048,20 +1880,24 @@ func (m *Manager) executeBackup(currentIndex *index.BackupIndex, diff *index.Ind
 		utils.Info("   - Creating backup index")
 		utils.Info("   - Saving index to storage")
 	} else {
-		utils.ProgressStep("Finalizing backup...")
+		utils.ProgressStep(utils.Msg("backup.step.finalize"))
 	}
 
 	// Mettre à jour l'index avec les informations de sauvegarde
 	currentIndex.BackupID = backupID
-	currentIndex.CreatedAt = time.Now()
+	currentIndex.CreatedAt = m.backupTime()
+	currentIndex.ImportedFrom = m.importedFrom
 	// Calculer les tailles totales
-	currentIndex.TotalFiles = int64(len(currentIndex.Files))
+	currentIndex.TotalFiles = int64(countStoredFiles(currentIndex.Files))
 	currentIndex.TotalSize = m.calculateTotalSize(currentIndex.Files)
 
 	// Vérifier que tous les fichiers ont des StorageKey valides
 	validFiles := 0
 	emptyKeys := 0
 	for _, file := range currentIndex.Files {
+		if file.Status == index.FileStatusFailed || file.IsSkipped() || file.IsMetadataOnly() {
+			continue
+		}
 		if file.StorageKey == "" {
 			emptyKeys++
 		} else {
@@ -2081,42 +1917,60 @@ func (m *Manager) executeBackup(currentIndex *index.BackupIndex, diff *index.Ind
 		utils.Warn("   This indicates a backup processing issue.")
 	}
 
+	requests := storage.Requests().Sub(m.requestsStart)
+	currentIndex.Requests = &requests
+
 	// Sauvegarder l'index
-	if err := m.indexMgr.SaveIndex(currentIndex); err != nil {
-		return fmt.Errorf("error saving de l'index: %w", err)
+	if err := m.indexMgr.SaveIndexDelta(currentIndex, m.previousIndex); err != nil {
+		return fmt.Errorf("error saving index: %w", err)
 	}
+	m.recordState(backupName, currentIndex)
 
 	if verbose {
 		utils.Info("✅ Task 6 completed: Backup index saved")
 	}
 
-	// Appliquer la politique de rétention automatiquement
-	if verbose {
-		utils.Info("📋 Task 7: Applying retention policy")
-		utils.Info("   - Loading retention configuration")
-		utils.Info("   - Finding old backups")
-		utils.Info("   - Deleting expired backups")
+	return nil
+}
+
+// markFileStatuses enregistre le statut de sauvegarde de chaque fichier dans l'index
+func markFileStatuses(currentIndex *index.BackupIndex, failed map[string]error) {
+	for i := range currentIndex.Files {
+		file := &currentIndex.Files[i]
+		if err, ok := failed[file.Path]; ok {
+			file.Status = index.FileStatusFailed
+			if errors.Is(err, errFileSkipped) {
+				file.Status = index.FileStatusSkippedUnreadable
+			}
+			file.Error = err.Error()
+			// Aucune donnée fiable n'a été envoyée pour ce fichier
+			file.StorageKey = ""
+		} else if file.Status == "" {
+			file.Status = index.FileStatusUploaded
+		}
 	}
+}
 
-	err := m.applyRetentionPolicyForBackup(backupName, verbose)
-	if err != nil {
-		if verbose {
-			utils.Warn("⚠️  Task 7 completed with warnings: Retention policy failed")
-			utils.Warn("   - Error: %v", err)
-			utils.Warn("   - Backup completed successfully, but retention cleanup failed")
-		} else {
-			utils.ProgressWarning("Retention policy failed, but backup completed")
+// compressionMode retourne la compression appliquée aux nouveaux objets
+func (m *Manager) compressionMode() string {
+	if m.config.Backup.CompressionLevel > 0 {
+		return index.CompressionGzip
+	}
+	return index.CompressionNone
+}
+
+// markCompression enregistre dans l'index la compression réellement appliquée à chaque fichier envoyé,
+// pour que la restauration ne dépende jamais de la configuration courante
+func (m *Manager) markCompression(currentIndex *index.BackupIndex) {
+	for i := range currentIndex.Files {
+		file := &currentIndex.Files[i]
+		if file.StorageKey == "" {
+			continue
 		}
-		// Ne pas faire échouer le backup complet à cause de la rétention
-	} else {
-		if verbose {
-			utils.Info("✅ Task 7 completed: Retention policy applied successfully")
-		} else {
-			utils.ProgressSuccess("Retention policy applied")
+		if compression, ok := m.compressions.Load(file.StorageKey); ok {
+			file.Compression = compression.(string)
 		}
 	}
-
-	return nil
 }
 
 // logBackupCompletion logs the completion of backup operation
@@ -2131,8 +1985,8 @@ func (m *Manager) logBackupCompletion(diff *index.IndexDiff, duration time.Durat
 		utils.Info("   ✅ Unreferenced objects cleaned up")
 		utils.Info("   ✅ Retention policy applied")
 	} else {
-		utils.ProgressSuccess(fmt.Sprintf("✅ Backup completed in %v", duration))
-		utils.ProgressInfo(fmt.Sprintf("📊 %d added, %d modified, %d deleted",
+		utils.ProgressSuccess(utils.Msg("backup.step.done", duration))
+		utils.ProgressInfo(utils.Msg("backup.step.summary",
 			len(diff.Added), len(diff.Modified), len(diff.Deleted)))
 	}
 }
@@ -2144,9 +1998,10 @@ func (m *Manager) cleanupUnreferencedObjects(backupID string, currentIndex *inde
 	}
 
 	// Créer un ensemble des clés de stockage référencées dans l'index actuel
+	// (les fichiers inchangés pointent vers les objets d'une sauvegarde précédente)
 	referencedKeys := make(map[string]bool)
 	for _, file := range currentIndex.Files {
-		if file.StorageKey != "" {
+		if file.StorageKey != "" && file.DataOwner(backupID) == backupID {
 			referencedKeys[file.StorageKey] = true
 		}
 	}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"sync"
+
+	"bcrdf/pkg/utils"
+)
+
+// memoryBudget est un sémaphore pondéré qui plafonne la mémoire des buffers de lecture
+// (données lues, compressées puis chiffrées) de tous les workers (memory_limit)
+type memoryBudget struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+// newMemoryBudget crée le budget mémoire, nil si memory_limit n'est pas configuré
+func newMemoryBudget(memoryLimit string) *memoryBudget {
+	if memoryLimit == "" {
+		return nil
+	}
+	limit, err := utils.ParseSize(memoryLimit)
+	if err != nil || limit <= 0 {
+		utils.Warn("Invalid memory_limit config, memory usage is not capped: %v", err)
+		return nil
+	}
+	b := &memoryBudget{limit: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// exceeds indique si un buffer ne peut pas tenir dans le budget
+func (b *memoryBudget) exceeds(weight int64) bool {
+	return b != nil && weight > b.limit
+}
+
+// acquire réserve weight octets, en attendant que d'autres workers libèrent leurs buffers
+func (b *memoryBudget) acquire(weight int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.used+b.clamp(weight) > b.limit {
+		b.cond.Wait()
+	}
+	b.used += b.clamp(weight)
+}
+
+// release libère weight octets
+func (b *memoryBudget) release(weight int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.used -= b.clamp(weight)
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// clamp limite un poids au budget total pour qu'un buffer trop gros passe seul au lieu de bloquer
+func (b *memoryBudget) clamp(weight int64) int64 {
+	if weight > b.limit {
+		return b.limit
+	}
+	return weight
+}
+
+// standardBufferWeight estime la mémoire d'un fichier standard: copie lue ou compressée au fil de
+// l'eau, puis copie chiffrée
+func standardBufferWeight(size int64) int64 {
+	return 2 * size
+}
+
+// chunkBufferWeight estime la mémoire d'un fichier traité par chunks (un chunk à la fois)
+func (m *Manager) chunkBufferWeight(defaultChunkSize string) int64 {
+	chunkSize := m.config.Backup.ChunkSize
+	if chunkSize == "" {
+		chunkSize = defaultChunkSize
+	}
+	size, err := parseSizeString(chunkSize)
+	if err != nil {
+		size, _ = parseSizeString(defaultChunkSize)
+	}
+	return 3 * size
+}
@@ -0,0 +1,275 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/utils"
+)
+
+// Politiques de gestion des fichiers modifiés pendant leur lecture (changed_file_policy)
+const (
+	ChangedFileIgnore   = "ignore"   // comportement historique: aucune vérification
+	ChangedFileRetry    = "retry"    // relire le fichier tant qu'il change (retry_attempts)
+	ChangedFileSnapshot = "snapshot" // copier le fichier dans un répertoire temporaire puis sauvegarder la copie
+	ChangedFileSkip     = "skip"     // ignorer le fichier avec un avertissement
+	ChangedFileVerify   = "verify"   // recalculer le checksum après lecture et échouer s'il diffère
+)
+
+// errFileSkipped signale un fichier volontairement ignoré (statut skipped-unreadable dans l'index)
+var errFileSkipped = errors.New("file skipped")
+
+// backupFileWithChangePolicy sauvegarde un fichier en appliquant son handler ou la politique des fichiers modifiés
+func (m *Manager) backupFileWithChangePolicy(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+	// Les fichiers applicatifs (file_handlers) sont sauvegardés depuis leur copie cohérente
+	if name, handler, ok := m.handlers.Match(file.Path); ok {
+		return m.backupWithHandler(ctx, file, name, handler, backupID, multiProgressBar, verbose)
+	}
+
+	policy := m.config.Backup.ChangedFilePolicy
+	switch policy {
+	case "", ChangedFileIgnore:
+		return m.backupSingleFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose)
+	case ChangedFileSnapshot:
+		return m.backupFromSnapshot(ctx, file, backupID, multiProgressBar, verbose)
+	case ChangedFileVerify:
+		if err := m.backupSingleFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose); err != nil {
+			return err
+		}
+		if err := m.verifyChecksumAfterRead(file); err != nil {
+			m.discardUpload(file, backupID)
+			return err
+		}
+		return nil
+	}
+
+	attempts := 1
+	if policy == ChangedFileRetry {
+		attempts = m.config.Backup.RetryAttempts + 1
+	}
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		before, err := os.Stat(utils.LongPath(file.Path))
+		if err != nil {
+			// Laisser la sauvegarde standard traiter les fichiers disparus
+			return m.backupSingleFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose)
+		}
+
+		if err := m.backupSingleFileWithMultiProgress(ctx, file, backupID, multiProgressBar, verbose); err != nil {
+			return err
+		}
+
+		after, err := os.Stat(utils.LongPath(file.Path))
+		if err == nil && !fileChanged(before, after) {
+			return nil
+		}
+
+		if attempt < attempts {
+			utils.Debug("🔄 File changed while being read, retrying (%d/%d): %s", attempt, attempts-1, file.Path)
+			select {
+			case <-time.After(time.Duration(m.config.Backup.RetryDelay) * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	// Les données envoyées ne correspondent à aucun état stable du fichier: aucune entrée ne les référencera
+	m.discardUpload(file, backupID)
+	if policy == ChangedFileSkip {
+		return fmt.Errorf("%w: %s changed while being read", errFileSkipped, file.Path)
+	}
+	return fmt.Errorf("file %s kept changing while being read after %d attempts", file.Path, attempts)
+}
+
+// discardUpload supprime les objets envoyés pour un fichier finalement ignoré ou en échec (objet,
+// chunks et métadonnées), qui resteraient facturés jusqu'au prochain gc. Un dépôt en ajout seul
+// les laisse au gc d'une instance de confiance.
+func (m *Manager) discardUpload(file index.FileEntry, backupID string) {
+	if m.config.Backup.AppendOnly {
+		return
+	}
+	key := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
+	objects, err := m.storageClient.ListObjects(key)
+	if err != nil {
+		utils.Debug("Objects of %s not removed: %v", file.Path, err)
+		return
+	}
+	for _, object := range objects {
+		if index.ObjectFileKey(object.Key) != key {
+			continue
+		}
+		if err := m.storageClient.DeleteObject(object.Key); err != nil {
+			utils.Debug("Object %s not removed: %v", object.Key, err)
+		}
+	}
+}
+
+// backupFileWithTimeout applique per_file_timeout et stall_timeout: un fichier trop long est ignoré,
+// un transfert sans progression est abandonné. L'abandon annule le contexte du fichier, ce qui
+// interrompt l'upload en cours, et attend la fin de la sauvegarde du fichier avant de rendre la
+// main: le worker ne reprend un fichier qu'une fois le précédent arrêté, et aucun objet n'est
+// envoyé après l'abandon. Une lecture figée dans le noyau (montage NFS hard) n'est pas
+// interruptible: le worker reste occupé jusqu'à son retour.
+func (m *Manager) backupFileWithTimeout(file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+	key := fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey())
+	m.stall.start(key)
+	defer m.stall.finish(key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- m.backupFileWithChangePolicy(ctx, file, backupID, multiProgressBar, verbose)
+	}()
+
+	var deadline <-chan time.Time
+	if m.config.Backup.PerFileTimeout > 0 {
+		timeout := time.Duration(m.config.Backup.PerFileTimeout) * time.Second
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	stallTimeout := m.stallTimeout()
+	ticker := time.NewTicker(stallCheckInterval(stallTimeout))
+	defer ticker.Stop()
+
+	var abort error
+	for abort == nil {
+		select {
+		case err := <-done:
+			return err
+		case <-deadline:
+			abort = fmt.Errorf("%w: %s exceeded per_file_timeout (%ds)", errFileSkipped, file.Path, m.config.Backup.PerFileTimeout)
+		case <-ticker.C:
+			if idle := m.stall.idle(key); idle > stallTimeout {
+				abort = fmt.Errorf("transfer stalled: no progress for %v", idle.Round(time.Second))
+			}
+		}
+	}
+
+	cancel()
+	<-done
+	if errors.Is(abort, errFileSkipped) {
+		// Un fichier ignoré n'est pas repris: ses objets déjà envoyés ne seraient référencés par aucune entrée.
+		// Ceux d'un transfert figé restent pour la reprise de ses chunks.
+		m.discardUpload(file, backupID)
+	}
+	return abort
+}
+
+// stallCheckInterval retourne la fréquence de vérification des transferts bloqués
+func stallCheckInterval(stallTimeout time.Duration) time.Duration {
+	interval := stallTimeout / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// backupFromSnapshot copie le fichier dans la zone temporaire puis sauvegarde cette copie stable
+func (m *Manager) backupFromSnapshot(ctx context.Context, file index.FileEntry, backupID string, multiProgressBar *utils.IntegratedProgressBar, verbose bool) error {
+	attempts := m.config.Backup.RetryAttempts + 1
+
+	// Espace de la copie réservé dans la zone temporaire (temp_max_size)
+	release, err := m.temp.Reserve(file.Size)
+	if err != nil {
+		return fmt.Errorf("error creating snapshot of %s: %w", file.Path, err)
+	}
+	defer release()
+
+	var snapshotPath string
+	for attempt := 1; attempt <= attempts; attempt++ {
+		path, stable, err := snapshotFile(m.temp, file.Path)
+		if err != nil {
+			return fmt.Errorf("error creating snapshot of %s: %w", file.Path, err)
+		}
+		if stable {
+			snapshotPath = path
+			break
+		}
+		os.Remove(path)
+		utils.Debug("🔄 File changed during snapshot, retrying (%d/%d): %s", attempt, attempts, file.Path)
+	}
+
+	if snapshotPath == "" {
+		return fmt.Errorf("file %s kept changing during snapshot after %d attempts", file.Path, attempts)
+	}
+	defer os.Remove(snapshotPath)
+
+	// La copie d'un gros fichier compte comme une progression du transfert
+	m.stall.touch(fmt.Sprintf("data/%s/%s", backupID, file.GetStorageKey()))
+
+	// La clé de stockage est déjà fixée par l'index: seule la source de lecture change
+	snapshot := file
+	snapshot.Path = snapshotPath
+	return m.backupSingleFileWithMultiProgress(ctx, snapshot, backupID, multiProgressBar, verbose)
+}
+
+// verifyChecksumAfterRead vérifie que le fichier correspond toujours au checksum de l'index
+func (m *Manager) verifyChecksumAfterRead(file index.FileEntry) error {
+	info, err := os.Stat(utils.LongPath(file.Path))
+	if err != nil {
+		return fmt.Errorf("error verifying %s after read: %w", file.Path, err)
+	}
+
+	current, err := index.NewFileEntryWithMode(file.Path, info, m.config.Backup.ChecksumMode)
+	if err != nil {
+		return fmt.Errorf("error verifying %s after read: %w", file.Path, err)
+	}
+
+	if current.Checksum != file.Checksum {
+		return fmt.Errorf("file %s changed while being read (checksum mismatch)", file.Path)
+	}
+	return nil
+}
+
+// snapshotFile copie un fichier dans la zone temporaire et indique si la source est restée stable
+func snapshotFile(temp *tempdir.Area, path string) (string, bool, error) {
+	before, err := os.Stat(utils.LongPath(path))
+	if err != nil {
+		return "", false, err
+	}
+
+	source, err := os.Open(utils.LongPath(path))
+	if err != nil {
+		return "", false, err
+	}
+	defer source.Close()
+
+	target, err := temp.CreateTemp("snapshot-*-" + filepath.Base(path))
+	if err != nil {
+		return "", false, err
+	}
+
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		os.Remove(target.Name())
+		return "", false, err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(target.Name())
+		return "", false, err
+	}
+
+	after, err := os.Stat(utils.LongPath(path))
+	if err != nil {
+		os.Remove(target.Name())
+		return "", false, err
+	}
+
+	return target.Name(), !fileChanged(before, after), nil
+}
+
+// fileChanged compare deux états d'un fichier (taille et date de modification)
+func fileChanged(before, after os.FileInfo) bool {
+	return before.Size() != after.Size() || !before.ModTime().Equal(after.ModTime())
+}
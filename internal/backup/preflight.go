@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"fmt"
+	"sort"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// sourceFullPercent est le taux d'occupation de la source au-delà duquel la sauvegarde avertit
+const sourceFullPercent = 95
+
+// warnSourceNearlyFull avertit quand le système de fichiers source est presque plein
+// (espace ou inodes): les applications risquent d'y écrire des fichiers tronqués
+func (m *Manager) warnSourceNearlyFull(sourcePath string, verbose bool) {
+	usage, err := utils.DiskUsageOf(sourcePath)
+	if err != nil {
+		utils.Debug("Source filesystem check skipped: %v", err)
+		return
+	}
+	for _, check := range []struct {
+		what string
+		used float64
+	}{{"space", usage.UsedPercent()}, {"inodes", usage.UsedFilesPercent()}} {
+		if check.used < sourceFullPercent {
+			continue
+		}
+		if verbose {
+			utils.Warn("⚠️  Source filesystem of %s is %.0f%% full (%s): files written during the backup may be incomplete", sourcePath, check.used, check.what)
+		} else {
+			utils.ProgressWarning(fmt.Sprintf("Source filesystem %.0f%% full (%s)", check.used, check.what))
+		}
+	}
+}
+
+// checkTempSpace échoue avant tout envoi si le répertoire temporaire ne peut pas contenir
+// les copies de changed_file_policy: snapshot (un fichier par worker, les plus gros en pire cas)
+func (m *Manager) checkTempSpace(diff *index.IndexDiff) error {
+	if m.config.Backup.ChangedFilePolicy != ChangedFileSnapshot {
+		return nil
+	}
+	required := snapshotTempSpace(append(append([]index.FileEntry{}, diff.Added...), diff.Modified...), m.config.Backup.MaxWorkers)
+	if required == 0 {
+		return nil
+	}
+
+	if limit := m.temp.Limit(); limit > 0 && required > limit {
+		return fmt.Errorf("%w: changed_file_policy snapshot needs up to %s of temp space but temp_max_size is %s",
+			utils.ErrInsufficientSpace, utils.FormatBytes(required), utils.FormatBytes(limit))
+	}
+
+	tempDir := m.temp.Dir()
+	usage, err := utils.DiskUsageOf(tempDir)
+	if err != nil {
+		utils.Debug("Temp space check skipped: %v", err)
+		return nil
+	}
+	if uint64(required) > usage.Free {
+		return fmt.Errorf("%w: changed_file_policy snapshot needs up to %s in %s but only %s is available (set backup.temp_dir to a larger filesystem)",
+			utils.ErrInsufficientSpace, utils.FormatBytes(required), tempDir, utils.FormatBytes(int64(usage.Free)))
+	}
+	return nil
+}
+
+// snapshotTempSpace retourne la taille cumulée des workers plus gros fichiers à sauvegarder
+func snapshotTempSpace(files []index.FileEntry, workers int) int64 {
+	if workers < 1 {
+		workers = 1
+	}
+	sizes := make([]int64, 0, len(files))
+	for _, file := range files {
+		if !file.IsDirectory && !file.IsSkipped() {
+			sizes = append(sizes, file.Size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	if len(sizes) > workers {
+		sizes = sizes[:workers]
+	}
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	return total
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"fmt"
+
+	"bcrdf/internal/crypto"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Chiffrement vers des destinataires (backup.recipients): les objets de données de chaque
+// sauvegarde sont chiffrés avec une clé aléatoire qui lui est propre, enveloppée pour les clés
+// publiques age et enregistrée dans l'index. L'agent oublie la clé à la fin de l'exécution: seul
+// le poste de restauration (identity_file) peut relire les données. Les index restent chiffrés
+// avec encryption_key pour que l'agent les relise (incrémental, rétention, gc).
+
+// prepareDataKey choisit le chiffreur des objets de données de la sauvegarde en cours
+func (m *Manager) prepareDataKey() error {
+	m.dataEncryptor, m.wrappedDataKey = m.encryptor, ""
+	if len(m.config.Backup.Recipients) == 0 {
+		return nil
+	}
+
+	recipients, err := crypto.NewRecipientEncryptor(m.config.Backup.Recipients, "")
+	if err != nil {
+		return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	key, err := crypto.NewDataKey()
+	if err != nil {
+		return err
+	}
+	wrapped, err := recipients.WrapKey(key)
+	if err != nil {
+		return fmt.Errorf("error wrapping data key: %w", err)
+	}
+	encryptor, err := crypto.NewEncryptorV2(key, m.encryptor.GetAlgorithm())
+	if err != nil {
+		return fmt.Errorf("error initializing data encryptor: %w", err)
+	}
+	m.dataEncryptor, m.wrappedDataKey = encryptor, wrapped
+	utils.Debug("🔑 Data encrypted to %d recipients", len(m.config.Backup.Recipients))
+	return nil
+}
+
+// markDataKeys enregistre dans l'index les clés enveloppées des sauvegardes détentrices: celle de
+// la sauvegarde en cours et, pour les fichiers inchangés, celles recopiées de l'index précédent
+func (m *Manager) markDataKeys(currentIndex *index.BackupIndex) {
+	keys := make(map[string]string)
+	if m.wrappedDataKey != "" {
+		keys[m.backupID] = m.wrappedDataKey
+	}
+	if m.previousIndex != nil {
+		for _, file := range currentIndex.Files {
+			if file.DataBackupID == "" || !file.HasData() {
+				continue
+			}
+			if wrapped := m.previousIndex.DataKeys[file.DataBackupID]; wrapped != "" {
+				keys[file.DataBackupID] = wrapped
+			}
+		}
+	}
+	if len(keys) > 0 {
+		currentIndex.DataKeys = keys
+	}
+}
@@ -0,0 +1,368 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Limites du rapport: il doit rester lisible même pour une sauvegarde de millions de fichiers
+const (
+	reportLargestFiles = 10
+	reportMaxErrors    = 100
+)
+
+// runReport rassemble les données d'une exécution pour le rapport (report_path, report_upload)
+type runReport struct {
+	BackupID     string
+	Job          string
+	Source       string
+	Started      time.Time
+	Duration     time.Duration
+	ScanDuration time.Duration
+	Index        *index.BackupIndex // nil si l'exécution a échoué avant l'indexation
+	Previous     *index.BackupIndex // nil pour une première sauvegarde
+	Diff         *index.IndexDiff   // nil si l'exécution a échoué avant la comparaison
+	Err          error
+}
+
+// reportSection est une section du rapport, rendue en Markdown ou en HTML
+type reportSection struct {
+	Title  string
+	Text   string
+	Header []string
+	Rows   [][]string
+}
+
+// reportEnabled indique si un rapport est demandé par la configuration
+func (m *Manager) reportEnabled() bool {
+	return m.config != nil && (m.config.Backup.ReportPath != "" || m.config.Backup.ReportUpload)
+}
+
+// writeReport écrit le rapport de l'exécution (fichier local et/ou stockage).
+// Le rapport est facultatif: un échec est signalé mais ne change jamais le résultat de la sauvegarde.
+func (m *Manager) writeReport(report *runReport, verbose bool) {
+	if !m.reportEnabled() {
+		return
+	}
+	format := m.config.Backup.ReportFormat
+	if format == "" {
+		format = "markdown"
+		if ext := strings.ToLower(filepath.Ext(m.config.Backup.ReportPath)); ext == ".html" || ext == ".htm" {
+			format = "html"
+		}
+	}
+	sections := report.sections()
+	title := fmt.Sprintf("BCRDF backup report: %s", report.BackupID)
+
+	var content []byte
+	ext := "md"
+	if format == "html" {
+		rendered, err := renderReportHTML(title, sections)
+		if err != nil {
+			utils.Warn("Unable to render backup report: %v", err)
+			return
+		}
+		content, ext = rendered, "html"
+	} else {
+		content = renderReportMarkdown(title, sections)
+	}
+
+	if path := m.config.Backup.ReportPath; path != "" {
+		path = reportFilePath(path, report.BackupID, ext)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			utils.Warn("Unable to write backup report: %v", err)
+		} else if err := os.WriteFile(path, content, 0600); err != nil {
+			utils.Warn("Unable to write backup report: %v", err)
+		} else if verbose {
+			utils.Info("📝 Backup report written: %s", path)
+		} else {
+			utils.ProgressInfo(fmt.Sprintf("Backup report written: %s", path))
+		}
+	}
+
+	// Sans index, rien à relire depuis le stockage: le rapport local suffit
+	if m.config.Backup.ReportUpload && report.Index != nil && m.encryptor != nil {
+		encrypted, err := m.encryptor.Encrypt(content)
+		if err == nil {
+			err = m.saveToStorageWithRetry(context.Background(), index.ReportKey(report.BackupID, ext), encrypted)
+		}
+		if err != nil {
+			utils.Warn("Unable to upload backup report: %v", err)
+		} else {
+			utils.Debug("Backup report uploaded: %s", index.ReportKey(report.BackupID, ext))
+		}
+	}
+}
+
+// FetchReport télécharge et déchiffre le rapport envoyé d'une sauvegarde (report_upload)
+func (m *Manager) FetchReport(backupID string) ([]byte, error) {
+	if err := m.initializeComponents(); err != nil {
+		return nil, fmt.Errorf("error initializing components: %w", err)
+	}
+	keys, err := index.ReportKeys(m.storageClient, backupID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no report stored for backup %s (enable backup.report_upload)", backupID)
+	}
+	encrypted, err := m.storageClient.Download(keys[0])
+	if err != nil {
+		return nil, fmt.Errorf("error downloading report %s: %w", keys[0], err)
+	}
+	content, err := m.encryptor.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting report %s: %w", keys[0], err)
+	}
+	return content, nil
+}
+
+// reportFilePath résout report_path: {backup_id} est remplacé, un répertoire reçoit <backup_id>.<ext>
+func reportFilePath(path, backupID, ext string) string {
+	path = strings.ReplaceAll(path, "{backup_id}", backupID)
+	if info, err := os.Stat(path); (err == nil && info.IsDir()) || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return filepath.Join(path, backupID+"."+ext)
+	}
+	return path
+}
+
+// sections construit le contenu du rapport: résumé, tendance, plus gros fichiers, erreurs
+func (r *runReport) sections() []reportSection {
+	status := "no changes"
+	failed, skipped := r.problemFiles()
+	switch {
+	case r.Err != nil:
+		status = "error: " + r.Err.Error()
+	case r.Index != nil && r.Diff != nil && len(r.Diff.Added)+len(r.Diff.Modified) > 0:
+		status = r.Index.EffectiveStatus()
+	}
+
+	summary := reportSection{Title: "Summary", Header: []string{"Item", "Value"}}
+	add := func(key, value string) { summary.Rows = append(summary.Rows, []string{key, value}) }
+	add("Backup", r.BackupID)
+	add("Job", r.Job)
+	add("Source", r.Source)
+	add("Host", hostnameOrUnknown())
+	add("Started", r.Started.Format("2006-01-02 15:04:05"))
+	add("Status", status)
+	add("Duration", reportDuration(r.Duration))
+	add("Scan", reportDuration(r.ScanDuration))
+	if r.Duration > r.ScanDuration {
+		add("Transfer and finalization", reportDuration(r.Duration-r.ScanDuration))
+	}
+	if r.Index != nil {
+		add("Files", fmt.Sprintf("%d", r.Index.TotalFiles))
+		add("Size", utils.FormatBytes(r.Index.TotalSize))
+	}
+	if r.Diff != nil {
+		add("Added", fmt.Sprintf("%d", len(r.Diff.Added)))
+		add("Modified", fmt.Sprintf("%d", len(r.Diff.Modified)))
+		add("Deleted", fmt.Sprintf("%d", len(r.Diff.Deleted)))
+		add("Changed data", utils.FormatBytes(changedBytes(r.Diff)))
+	}
+	add("Failed files", fmt.Sprintf("%d", len(failed)))
+	add("Skipped files", fmt.Sprintf("%d", len(skipped)))
+	sections := []reportSection{summary}
+
+	if trend := r.trend(); trend != nil {
+		sections = append(sections, *trend)
+	}
+	if largest := r.largestFiles(); largest != nil {
+		sections = append(sections, *largest)
+	}
+	sections = append(sections, problemSection("Errors", failed, "No file failed.", r.BackupID))
+	if len(skipped) > 0 {
+		sections = append(sections, problemSection("Skipped files", skipped, "", r.BackupID))
+	}
+	return sections
+}
+
+// problemFiles retourne les fichiers en échec et les fichiers ignorés car illisibles de l'index
+func (r *runReport) problemFiles() (failed, skipped []index.FileEntry) {
+	if r.Index == nil {
+		return nil, nil
+	}
+	for _, file := range r.Index.Files {
+		switch {
+		case file.Status == index.FileStatusFailed:
+			failed = append(failed, file)
+		case file.Status == index.FileStatusSkippedUnreadable:
+			skipped = append(skipped, file)
+		}
+	}
+	return failed, skipped
+}
+
+// trend compare la sauvegarde à la précédente du même job
+func (r *runReport) trend() *reportSection {
+	if r.Previous == nil || r.Index == nil {
+		return nil
+	}
+	section := &reportSection{
+		Title:  "Trend vs. previous run",
+		Text:   fmt.Sprintf("Previous backup: %s (%s, %s earlier).", r.Previous.BackupID, r.Previous.CreatedAt.Format("2006-01-02 15:04:05"), reportDuration(r.Index.CreatedAt.Sub(r.Previous.CreatedAt))),
+		Header: []string{"Metric", "Previous", "Current", "Change"},
+	}
+	section.Rows = append(section.Rows,
+		[]string{"Files", fmt.Sprintf("%d", r.Previous.TotalFiles), fmt.Sprintf("%d", r.Index.TotalFiles), signedCount(r.Index.TotalFiles - r.Previous.TotalFiles)},
+		[]string{"Size", utils.FormatBytes(r.Previous.TotalSize), utils.FormatBytes(r.Index.TotalSize), signedBytes(r.Index.TotalSize - r.Previous.TotalSize)},
+	)
+	if r.Previous.Requests != nil && r.Index.Requests != nil {
+		previous, current := r.Previous.Requests.Total(), r.Index.Requests.Total()
+		section.Rows = append(section.Rows, []string{"Storage requests", fmt.Sprintf("%d", previous), fmt.Sprintf("%d", current), signedCount(current - previous)})
+	}
+	return section
+}
+
+// largestFiles liste les plus gros fichiers envoyés par cette exécution
+func (r *runReport) largestFiles() *reportSection {
+	if r.Diff == nil {
+		return nil
+	}
+	changed := append(append([]index.FileEntry{}, r.Diff.Added...), r.Diff.Modified...)
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Size > changed[j].Size })
+	if len(changed) > reportLargestFiles {
+		changed = changed[:reportLargestFiles]
+	}
+	section := &reportSection{Title: "Largest files", Header: []string{"File", "Size"}}
+	for _, file := range changed {
+		section.Rows = append(section.Rows, []string{file.Path, utils.FormatBytes(file.Size)})
+	}
+	return section
+}
+
+// problemSection liste des fichiers en échec ou ignorés avec leur erreur
+func problemSection(title string, files []index.FileEntry, empty, backupID string) reportSection {
+	section := reportSection{Title: title}
+	if len(files) == 0 {
+		section.Text = empty
+		return section
+	}
+	section.Header = []string{"File", "Status", "Error"}
+	for i, file := range files {
+		if i == reportMaxErrors {
+			section.Text = fmt.Sprintf("%d more not listed (see 'bcrdf list %s').", len(files)-reportMaxErrors, backupID)
+			break
+		}
+		section.Rows = append(section.Rows, []string{file.Path, file.Status, file.Error})
+	}
+	return section
+}
+
+// changedBytes retourne le volume des fichiers ajoutés et modifiés
+func changedBytes(diff *index.IndexDiff) int64 {
+	var total int64
+	for _, file := range diff.Added {
+		total += file.Size
+	}
+	for _, file := range diff.Modified {
+		total += file.Size
+	}
+	return total
+}
+
+func signedCount(n int64) string {
+	return fmt.Sprintf("%+d", n)
+}
+
+func signedBytes(n int64) string {
+	if n < 0 {
+		return "-" + utils.FormatBytes(-n)
+	}
+	return "+" + utils.FormatBytes(n)
+}
+
+// reportDuration arrondit une durée à la seconde (à la milliseconde sous la seconde)
+func reportDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+func hostnameOrUnknown() string {
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "unknown"
+}
+
+// renderReportMarkdown rend le rapport en Markdown
+func renderReportMarkdown(title string, sections []reportSection) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Title)
+		if len(section.Rows) > 0 {
+			b.WriteString("| " + strings.Join(escapeMarkdownRow(section.Header), " | ") + " |\n")
+			b.WriteString("|" + strings.Repeat(" --- |", len(section.Header)) + "\n")
+			for _, row := range section.Rows {
+				b.WriteString("| " + strings.Join(escapeMarkdownRow(row), " | ") + " |\n")
+			}
+			if section.Text != "" {
+				b.WriteString("\n")
+			}
+		}
+		if section.Text != "" {
+			b.WriteString(section.Text + "\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// escapeMarkdownRow protège les cellules d'un tableau Markdown (barres verticales, retours à la ligne)
+func escapeMarkdownRow(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		escaped[i] = strings.ReplaceAll(cell, "\n", " ")
+	}
+	return escaped
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<h2>{{.Title}}</h2>
+{{if .Rows}}<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}{{if .Text}}<p>{{.Text}}</p>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// renderReportHTML rend le rapport en HTML autonome (valeurs échappées)
+func renderReportHTML(title string, sections []reportSection) ([]byte, error) {
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, struct {
+		Title    string
+		Sections []reportSection
+	}{title, sections})
+	return buf.Bytes(), err
+}
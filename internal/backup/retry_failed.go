@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// retryTimeoutScale multiplie les délais de transfert pendant la reprise des fichiers en échec
+const retryTimeoutScale = 3
+
+// retryFailedFiles reprend un par un, avec des lecteurs neufs et des délais allongés, les fichiers
+// en échec pendant la phase parallèle (retry_failed_files): une panne passagère du stockage ou
+// un réseau saturé par les workers ne rend pas la sauvegarde partielle. Les fichiers ignorés
+// volontairement (per_file_timeout, max_file_size...) ne sont pas repris. failed est mis à jour.
+func (m *Manager) retryFailedFiles(files []index.FileEntry, failed map[string]error, backupID string, verbose bool) {
+	var retry []index.FileEntry
+	for _, f := range files {
+		if err, ok := failed[f.Path]; ok && !errors.Is(err, errFileSkipped) {
+			retry = append(retry, f)
+		}
+	}
+	if len(retry) == 0 {
+		return
+	}
+
+	if verbose {
+		utils.Info("   - Retrying %d failed files one at a time", len(retry))
+	} else {
+		utils.ProgressStep(fmt.Sprintf("Retrying %d failed files one at a time", len(retry)))
+	}
+	m.timeoutScale.Store(retryTimeoutScale)
+	defer m.timeoutScale.Store(0)
+
+	recovered := 0
+	for _, f := range retry {
+		m.uploads.acquire()
+		err := m.backupFileWithTimeout(f, backupID, nil, verbose)
+		m.uploads.release()
+		if err != nil {
+			failed[f.Path] = fmt.Errorf("error saving %s (retried): %w", f.Path, err)
+			continue
+		}
+		delete(failed, f.Path)
+		recovered++
+		if verbose {
+			utils.Debug("   - Recovered %s", f.Path)
+		}
+	}
+
+	if verbose {
+		utils.Info("   - Recovered %d of %d failed files", recovered, len(retry))
+	} else {
+		utils.ProgressInfo(fmt.Sprintf("Recovered %d of %d failed files", recovered, len(retry)))
+	}
+}
@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"bcrdf/internal/index"
+)
+
+const (
+	// DefaultStallTimeout est la durée sans progression au-delà de laquelle un transfert est abandonné
+	DefaultStallTimeout = 5 * time.Minute
+
+	// minTransferRate est le débit minimal supposé pour calculer le délai d'un upload (octets/s)
+	minTransferRate = 256 * 1024
+)
+
+// stallDetector suit la dernière progression de chaque transfert en cours
+// Un fichier volumineux qui progresse n'est jamais interrompu, seul un transfert figé l'est
+type stallDetector struct {
+	mu       sync.Mutex
+	progress map[string]time.Time
+}
+
+// newStallDetector crée un détecteur de transferts bloqués
+func newStallDetector() *stallDetector {
+	return &stallDetector{progress: make(map[string]time.Time)}
+}
+
+// start enregistre le début d'un transfert
+func (d *stallDetector) start(key string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.progress[key] = time.Now()
+	d.mu.Unlock()
+}
+
+// touch signale une progression du fichier d'un objet (octets envoyés, chunk lu, copie préparée)
+func (d *stallDetector) touch(objectKey string) {
+	if d == nil {
+		return
+	}
+	key := index.ObjectFileKey(objectKey)
+
+	d.mu.Lock()
+	if _, ok := d.progress[key]; ok {
+		d.progress[key] = time.Now()
+	}
+	d.mu.Unlock()
+}
+
+// finish retire un transfert terminé
+func (d *stallDetector) finish(key string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	delete(d.progress, key)
+	d.mu.Unlock()
+}
+
+// idle retourne le temps écoulé depuis la dernière progression d'un transfert
+func (d *stallDetector) idle(key string) time.Duration {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.progress[key]
+	if !ok {
+		return 0
+	}
+	return time.Since(last)
+}
+
+// progressReader est le corps d'un upload: chaque lecture par le client de stockage compte comme
+// une progression du transfert, un envoi lent qui avance n'est donc jamais pris pour un transfert
+// figé. Les lectures échouent dès l'annulation du contexte. ReadAt et Seek permettent au SDK S3 de
+// lire les parties sans les copier.
+type progressReader struct {
+	ctx    context.Context
+	reader *bytes.Reader
+	stall  *stallDetector
+	key    string
+}
+
+// newProgressReader crée le corps de l'upload de data sous key
+func (m *Manager) newProgressReader(ctx context.Context, key string, data []byte) *progressReader {
+	return &progressReader{ctx: ctx, reader: bytes.NewReader(data), stall: m.stall, key: key}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.stall.touch(r.key)
+	}
+	return n, err
+}
+
+func (r *progressReader) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.ReadAt(p, off)
+	if n > 0 {
+		r.stall.touch(r.key)
+	}
+	return n, err
+}
+
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	return r.reader.Seek(offset, whence)
+}
+
+// stallTimeout retourne le délai sans progression configuré (stall_timeout)
+func (m *Manager) stallTimeout() time.Duration {
+	timeout := DefaultStallTimeout
+	if m.config.Backup.StallTimeout > 0 {
+		timeout = time.Duration(m.config.Backup.StallTimeout) * time.Second
+	}
+	return m.scaleTimeout(timeout)
+}
+
+// transferTimeout retourne le délai d'un upload, proportionnel à sa taille
+func (m *Manager) transferTimeout(size int) time.Duration {
+	timeout := time.Duration(m.config.Backup.NetworkTimeout) * time.Second
+	if timeout == 0 {
+		timeout = 30 * time.Second // Default 30 seconds
+	}
+	return m.scaleTimeout(timeout + time.Duration(size/minTransferRate)*time.Second)
+}
+
+// scaleTimeout allonge un délai pendant la reprise des fichiers en échec
+func (m *Manager) scaleTimeout(timeout time.Duration) time.Duration {
+	if scale := m.timeoutScale.Load(); scale > 1 {
+		return timeout * time.Duration(scale)
+	}
+	return timeout
+}
@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"bcrdf/internal/index"
+	"bcrdf/internal/state"
+	"bcrdf/pkg/utils"
+)
+
+// openState ouvre la base d'état locale (state_db)
+// La base est facultative: une erreur est signalée mais n'interrompt jamais la sauvegarde.
+func (m *Manager) openState() {
+	if m.config.Backup.StateDB == "" {
+		return
+	}
+
+	store, err := state.Open(m.config.Backup.StateDB)
+	if err != nil {
+		utils.Warn("Local state database disabled: %v", err)
+		return
+	}
+	m.state = store
+	m.indexMgr.SetChecksumStore(store)
+}
+
+// startRun enregistre le début de la sauvegarde dans la base d'état
+func (m *Manager) startRun(backupID, backupName, sourcePath string) int64 {
+	runID, err := m.state.StartRun(backupID, backupName, sourcePath)
+	if err != nil {
+		utils.Warn("Local state database: %v", err)
+	}
+	return runID
+}
+
+// recordState enregistre l'état des fichiers de l'index écrit et vide le journal des uploads
+func (m *Manager) recordState(backupName string, currentIndex *index.BackupIndex) {
+	if err := m.state.RecordFiles(backupName, currentIndex.BackupID, currentIndex.Files); err != nil {
+		utils.Warn("Local state database: %v", err)
+	}
+	if err := m.state.ClearJournal(currentIndex.BackupID); err != nil {
+		utils.Warn("Local state database: %v", err)
+	}
+}
+
+// closeState enregistre le résultat de la sauvegarde et ferme la base d'état
+func (m *Manager) closeState(runID int64, currentIndex *index.BackupIndex, runErr error) {
+	if m.state == nil {
+		return
+	}
+	var files, bytes int64
+	if currentIndex != nil {
+		files, bytes = currentIndex.TotalFiles, currentIndex.TotalSize
+	}
+	if err := m.state.FinishRun(runID, files, bytes, runErr); err != nil {
+		utils.Warn("Local state database: %v", err)
+	}
+	if m.config.Backup.Catalog && runErr == nil {
+		// Après la rétention: la nouvelle sauvegarde est ajoutée, les sauvegardes supprimées retirées
+		if _, err := state.SyncCatalog(m.state, m.indexMgr, false); err != nil {
+			utils.Warn("File catalog not updated: %v", err)
+		}
+	}
+	m.state.Close()
+	m.state = nil
+}
@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+const (
+	// DefaultCircuitBreakerThreshold est le nombre d'échecs consécutifs qui ouvre le disjoncteur
+	DefaultCircuitBreakerThreshold = 5
+
+	// DefaultCircuitBreakerCooldown est la durée de pause des uploads lorsque le disjoncteur est ouvert
+	DefaultCircuitBreakerCooldown = time.Minute
+
+	// maxThrottleBackoff plafonne la pause globale en cas de throttling
+	maxThrottleBackoff = 60 * time.Second
+)
+
+// throttlingMarkers identifie les réponses de limitation de débit des backends (S3, WebDAV)
+var throttlingMarkers = []string{"503", "429", "slowdown", "slow down", "throttl", "toomanyrequests", "requestlimitexceeded", "service unavailable"}
+
+// uploadQueue coordonne tous les workers d'upload: un throttling prolongé met toute la file en pause
+// (backoff exponentiel) et une série d'échecs ouvre un disjoncteur qui suspend les uploads.
+// Elle mesure aussi le débit de requêtes et la latence du backend pour adapter la concurrence.
+type uploadQueue struct {
+	mu                  sync.Mutex
+	resumeAt            time.Time
+	throttled           int
+	consecutiveFailures int
+	threshold           int
+	cooldown            time.Duration
+	baseDelay           time.Duration
+
+	// Métriques des requêtes de stockage
+	started      time.Time
+	requests     int
+	failures     int
+	totalLatency time.Duration
+
+	// Concurrence adaptative (AIMD): limit varie entre 1 et maxLimit
+	adaptive   bool
+	limit      int
+	maxLimit   int
+	active     int
+	successes  int
+	lastShrink time.Time
+	slot       *sync.Cond
+}
+
+// newUploadQueue crée l'état partagé de la file d'upload pour workers workers
+func newUploadQueue(config *utils.Config, workers int) *uploadQueue {
+	q := &uploadQueue{
+		threshold: config.Backup.CircuitBreakerThreshold,
+		cooldown:  time.Duration(config.Backup.CircuitBreakerCooldown) * time.Second,
+		baseDelay: time.Duration(config.Backup.RetryDelay) * time.Second,
+		started:   time.Now(),
+		adaptive:  config.Backup.AdaptiveConcurrency,
+		limit:     workers,
+		maxLimit:  workers,
+	}
+	q.slot = sync.NewCond(&q.mu)
+	if q.adaptive {
+		// Démarrage prudent, la limite monte tant que le backend suit
+		q.limit = max(1, workers/2)
+	}
+	if q.threshold <= 0 {
+		q.threshold = DefaultCircuitBreakerThreshold
+	}
+	if q.cooldown <= 0 {
+		q.cooldown = DefaultCircuitBreakerCooldown
+	}
+	if q.baseDelay <= 0 {
+		q.baseDelay = 2 * time.Second
+	}
+	return q
+}
+
+// wait bloque tant que la file est en pause
+func (q *uploadQueue) wait() {
+	if q == nil {
+		return
+	}
+	for {
+		q.mu.Lock()
+		delay := time.Until(q.resumeAt)
+		q.mu.Unlock()
+		if delay <= 0 {
+			return
+		}
+		time.Sleep(delay)
+	}
+}
+
+// acquire réserve un slot de traitement (bloque si la limite de concurrence est atteinte)
+func (q *uploadQueue) acquire() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	for q.active >= q.limit {
+		q.slot.Wait()
+	}
+	q.active++
+	q.mu.Unlock()
+}
+
+// release libère un slot de traitement
+func (q *uploadQueue) release() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.active--
+	q.mu.Unlock()
+	q.slot.Signal()
+}
+
+// record met à jour l'état de la file après une tentative d'upload
+func (q *uploadQueue) record(err error, latency time.Duration) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.requests++
+	q.totalLatency += latency
+	q.adapt(err)
+
+	if err == nil {
+		if q.consecutiveFailures >= q.threshold {
+			utils.Info("✅ Storage backend recovered, resuming uploads")
+		}
+		q.throttled = 0
+		q.consecutiveFailures = 0
+		return
+	}
+
+	q.failures++
+	q.consecutiveFailures++
+
+	if isThrottlingError(err) {
+		q.throttled++
+		delay := q.baseDelay * time.Duration(1<<min(q.throttled-1, 10))
+		if delay > maxThrottleBackoff {
+			delay = maxThrottleBackoff
+		}
+		q.pauseUntil(time.Now().Add(delay))
+		utils.Warn("⚠️  Storage backend is throttling requests, pausing all uploads for %v", delay)
+	}
+
+	if q.consecutiveFailures == q.threshold {
+		q.pauseUntil(time.Now().Add(q.cooldown))
+		utils.Warn("⚠️  %d consecutive upload failures, circuit breaker open: pausing uploads for %v", q.consecutiveFailures, q.cooldown)
+	} else if q.consecutiveFailures > q.threshold {
+		// Demi-ouvert: la tentative de test a échoué, nouvelle pause
+		q.pauseUntil(time.Now().Add(q.cooldown))
+	}
+}
+
+// adapt ajuste la limite de concurrence: +1 après une série de requêtes réussies,
+// divisée par deux en cas de throttling ou de timeout (au plus une fois par seconde).
+// La latence brute n'est pas utilisée car elle dépend surtout de la taille des objets.
+func (q *uploadQueue) adapt(err error) {
+	if !q.adaptive {
+		return
+	}
+
+	if err != nil && (isThrottlingError(err) || strings.Contains(err.Error(), "timeout")) {
+		q.successes = 0
+		if q.limit > 1 && time.Since(q.lastShrink) > time.Second {
+			q.limit = max(1, q.limit/2)
+			q.lastShrink = time.Now()
+			utils.Debug("Adaptive concurrency: reducing to %d workers", q.limit)
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	q.successes++
+	if q.successes >= q.limit && q.limit < q.maxLimit {
+		q.successes = 0
+		q.limit++
+		q.slot.Signal()
+		utils.Debug("Adaptive concurrency: increasing to %d workers", q.limit)
+	}
+}
+
+// logMetrics affiche le débit de requêtes et la latence observés sur le backend
+func (q *uploadQueue) logMetrics() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.requests == 0 {
+		return
+	}
+	elapsed := time.Since(q.started).Seconds()
+	utils.Info("   - Storage requests: %d (%.1f req/s, avg latency %v, %d failed)",
+		q.requests, float64(q.requests)/elapsed, (q.totalLatency / time.Duration(q.requests)).Round(time.Millisecond), q.failures)
+	if q.adaptive {
+		utils.Info("   - Adaptive concurrency: %d/%d workers", q.limit, q.maxLimit)
+	}
+}
+
+// pauseUntil prolonge la pause de la file (jamais raccourcie)
+func (q *uploadQueue) pauseUntil(t time.Time) {
+	if t.After(q.resumeAt) {
+		q.resumeAt = t
+	}
+}
+
+// isThrottlingError détecte une réponse de limitation de débit (503 SlowDown, 429...)
+func isThrottlingError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, marker := range throttlingMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// md5ETagPattern reconnaît un ETag égal au MD5 de l'objet (envoi S3 en une partie)
+// Les ETags multipart ("...-N") ne sont pas des MD5 et imposent de relire l'objet
+var md5ETagPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// verifyUpload relit un objet juste après son envoi (verify_uploads) et le compare aux données
+// envoyées: un HEAD suffit si l'ETag est le MD5 des données, sinon l'objet est téléchargé. Une
+// différence est traitée comme un échec d'envoi (l'objet est renvoyé selon retry_attempts).
+func (m *Manager) verifyUpload(key string, data []byte) error {
+	info, err := m.storageClient.Stat(key)
+	if err != nil {
+		return fmt.Errorf("verification of %s: %w", key, err)
+	}
+	if info.Size != int64(len(data)) {
+		return fmt.Errorf("%w: %s stored with %d bytes, %d sent", utils.ErrVerificationFailed, key, info.Size, len(data))
+	}
+
+	// Un ETag qui n'est pas le MD5 (chiffrement KMS côté serveur) ne prouve rien: relecture complète
+	if etag := strings.Trim(info.ETag, `"`); md5ETagPattern.MatchString(etag) {
+		sum := md5.Sum(data)
+		if etag == hex.EncodeToString(sum[:]) {
+			return nil
+		}
+	}
+
+	stored, err := m.storageClient.Download(key)
+	if err != nil {
+		return fmt.Errorf("verification of %s: %w", key, err)
+	}
+	if !bytes.Equal(stored, data) {
+		return fmt.Errorf("%w: %s read back differs from the data sent", utils.ErrVerificationFailed, key)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package bench
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"bcrdf/internal/compression"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Valeurs par défaut de bcrdf bench --compression
+const (
+	DefaultSampleBytes = 64 * 1024 * 1024
+	DefaultUplink      = 12 * 1024 * 1024 // ~100 Mbit/s
+	maxBytesPerFile    = 4 * 1024 * 1024
+)
+
+// CompressionOptions décrit l'échantillonnage et le lien utilisés pour la recommandation
+type CompressionOptions struct {
+	SampleBytes int64   // Volume lu dans la source
+	Uplink      float64 // Débit d'envoi en octets par seconde
+}
+
+// LevelResult contient les mesures d'un niveau de compression sur l'échantillon
+type LevelResult struct {
+	Level       int
+	InputBytes  int64
+	OutputBytes int64
+	Duration    time.Duration
+}
+
+// Ratio retourne la taille compressée rapportée à la taille d'origine
+func (r LevelResult) Ratio() float64 {
+	if r.InputBytes == 0 {
+		return 1
+	}
+	return float64(r.OutputBytes) / float64(r.InputBytes)
+}
+
+// Speed retourne le débit de compression en octets par seconde
+func (r LevelResult) Speed() float64 {
+	return rate(r.InputBytes, r.Duration)
+}
+
+// CompressionReport contient les mesures de compression et les réglages suggérés
+type CompressionReport struct {
+	Source              string
+	Files               int   // Fichiers échantillonnés
+	SampledBytes        int64 // Octets compressibles mesurés
+	IncompressibleFiles int   // Formats déjà compressés, jamais compressés par la sauvegarde
+	IncompressibleBytes int64
+	Results             []LevelResult
+	Uplink              float64
+	Workers             int
+	Level               int  // Niveau recommandé
+	Adaptive            bool // compression_adaptive recommandé
+}
+
+// RunCompression échantillonne des fichiers de sourcePath, mesure ratio et vitesse de chaque
+// niveau gzip et recommande le niveau qui minimise le temps compression + envoi sur le lien donné
+func (m *Manager) RunCompression(sourcePath string, options CompressionOptions, verbose bool) (*CompressionReport, error) {
+	if options.SampleBytes <= 0 {
+		options.SampleBytes = DefaultSampleBytes
+	}
+	if options.Uplink <= 0 {
+		options.Uplink = DefaultUplink
+	}
+
+	if verbose {
+		utils.Info("🔎 Sampling files from %s", sourcePath)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("🔎 Sampling files from %s", sourcePath))
+	}
+
+	compressor, err := compression.NewCompressor(1)
+	if err != nil {
+		return nil, err
+	}
+	samples, report, err := m.sampleFiles(sourcePath, compressor, options.SampleBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no compressible file found in %s", sourcePath)
+	}
+	report.Uplink = options.Uplink
+	report.Workers = max(1, min(m.config.Backup.MaxWorkers, runtime.NumCPU()))
+
+	for level := 1; level <= 9; level++ {
+		if verbose {
+			utils.Info("🗜️  Measuring gzip level %d", level)
+		} else {
+			utils.ProgressStep(fmt.Sprintf("🗜️  Measuring gzip level %d", level))
+		}
+
+		result := LevelResult{Level: level}
+		start := time.Now()
+		for _, data := range samples {
+			compressed, err := compressor.CompressWithLevel(data, level)
+			if err != nil {
+				return nil, err
+			}
+			result.InputBytes += int64(len(data))
+			result.OutputBytes += int64(len(compressed))
+		}
+		result.Duration = time.Since(start)
+		report.Results = append(report.Results, result)
+	}
+
+	report.Level = RecommendLevel(report.Results, report.Uplink, report.Workers)
+	total := report.SampledBytes + report.IncompressibleBytes
+	report.Adaptive = total > 0 && float64(report.IncompressibleBytes)/float64(total) >= 0.1
+	return report, nil
+}
+
+// sampleFiles lit le début de fichiers tirés au hasard jusqu'à sampleBytes, en appliquant
+// les règles d'exclusion de la sauvegarde
+func (m *Manager) sampleFiles(sourcePath string, compressor *compression.Compressor, sampleBytes int64) ([][]byte, *CompressionReport, error) {
+	var paths []string
+	ignores := index.NewIgnoreRules(sourcePath, m.config)
+	err := filepath.WalkDir(sourcePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Fichier illisible: ignoré comme pendant la sauvegarde
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if index.IsExcluded(m.config, path, info) || ignores.Ignored(path, entry.IsDir()) {
+			if entry.IsDir() && path != sourcePath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Mode().IsRegular() && info.Size() > 0 {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error scanning %s: %w", sourcePath, err)
+	}
+
+	// Ordre aléatoire mais reproductible: l'échantillon couvre toute l'arborescence
+	random := rand.New(rand.NewSource(1))
+	random.Shuffle(len(paths), func(i, j int) { paths[i], paths[j] = paths[j], paths[i] })
+
+	report := &CompressionReport{Source: sourcePath}
+	var samples [][]byte
+	for _, path := range paths {
+		if report.SampledBytes+report.IncompressibleBytes >= sampleBytes {
+			break
+		}
+		data, err := utils.ReadFileHead(path, maxBytesPerFile)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		if !compressor.ShouldCompress(path) {
+			report.IncompressibleFiles++
+			report.IncompressibleBytes += int64(len(data))
+			continue
+		}
+		samples = append(samples, data)
+		report.Files++
+		report.SampledBytes += int64(len(data))
+	}
+	return samples, report, nil
+}
+
+// RecommendLevel retourne le niveau qui minimise le temps par octet source: compression répartie
+// sur workers cœurs, puis envoi des données compressées sur le lien
+func RecommendLevel(results []LevelResult, uplink float64, workers int) int {
+	best, bestCost := 1, 0.0
+	for _, r := range results {
+		speed := r.Speed()
+		if speed <= 0 {
+			continue
+		}
+		cost := 1/(speed*float64(workers)) + r.Ratio()/uplink
+		if bestCost == 0 || cost < bestCost {
+			best, bestCost = r.Level, cost
+		}
+	}
+	return best
+}
+
+// CompressionSettings retourne les valeurs de configuration recommandées (bench --compression --apply)
+func CompressionSettings(report *CompressionReport) map[string]string {
+	return map[string]string{
+		"backup.compression_level":    fmt.Sprintf("%d", report.Level),
+		"backup.compression_adaptive": fmt.Sprintf("%t", report.Adaptive),
+	}
+}
+
+// PrintCompressionReport affiche les mesures et la recommandation
+func PrintCompressionReport(report *CompressionReport, config *utils.Config) {
+	fmt.Printf("\n🗜️  Compression Benchmark Report\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Source: %s\n", report.Source)
+	fmt.Printf("Sampled: %d files (%s)\n", report.Files, formatBytes(report.SampledBytes))
+	if report.IncompressibleFiles > 0 {
+		fmt.Printf("Already compressed formats: %d files (%s), stored as-is\n",
+			report.IncompressibleFiles, formatBytes(report.IncompressibleBytes))
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("%-6s %8s %12s %14s\n", "Level", "Ratio", "MB/s", "Est. MB/s*")
+	for _, r := range report.Results {
+		marker := ""
+		if r.Level == report.Level {
+			marker = " ⭐"
+		}
+		fmt.Printf("gzip-%-1d %7.1f%% %12.2f %14.2f%s\n",
+			r.Level, r.Ratio()*100, r.Speed()/1024/1024, effectiveRate(r, report.Uplink, report.Workers)/1024/1024, marker)
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("* source data backed up per second with %d worker(s) and a %.1f MB/s uplink\n",
+		report.Workers, report.Uplink/1024/1024)
+
+	fmt.Printf("\n💡 Recommended settings:\n")
+	fmt.Printf("  backup.compression_level: %d (current: %d)\n", report.Level, config.Backup.CompressionLevel)
+	fmt.Printf("  backup.compression_adaptive: %t (current: %t)\n", report.Adaptive, config.Backup.CompressionAdaptive)
+	if len(report.Results) > 0 && report.Results[0].Ratio() > 0.95 {
+		fmt.Printf("  This data barely compresses: level 1 keeps the CPU cost minimal\n")
+	}
+	fmt.Printf("\n")
+}
+
+// effectiveRate estime le volume source sauvegardé par seconde pour un niveau
+func effectiveRate(r LevelResult, uplink float64, workers int) float64 {
+	speed := r.Speed()
+	if speed <= 0 {
+		return 0
+	}
+	return 1 / (1/(speed*float64(workers)) + r.Ratio()/uplink)
+}
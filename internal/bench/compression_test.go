@@ -0,0 +1,23 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecommendLevelDependsOnUplink(t *testing.T) {
+	const mb = 1024 * 1024
+	results := []LevelResult{
+		{Level: 1, InputBytes: 100 * mb, OutputBytes: 50 * mb, Duration: time.Second},      // 100 MB/s
+		{Level: 9, InputBytes: 100 * mb, OutputBytes: 30 * mb, Duration: 10 * time.Second}, // 10 MB/s
+	}
+
+	// Lien rapide: la vitesse de compression domine
+	if level := RecommendLevel(results, 1000*mb, 1); level != 1 {
+		t.Errorf("Niveau 1 attendu sur un lien rapide, obtenu %d", level)
+	}
+	// Lien lent: le meilleur ratio l'emporte
+	if level := RecommendLevel(results, 1*mb, 1); level != 9 {
+		t.Errorf("Niveau 9 attendu sur un lien lent, obtenu %d", level)
+	}
+}
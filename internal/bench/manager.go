@@ -0,0 +1,359 @@
+package bench
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Valeurs par défaut de bcrdf bench
+var (
+	DefaultSizes       = []string{"256KB", "1MB", "8MB", "32MB"}
+	DefaultConcurrency = []int{1, 4, 8, 16}
+)
+
+// DefaultBudget est le volume envoyé par configuration (taille x workers)
+const DefaultBudget = 64 * 1024 * 1024
+
+// minChunkSize est la plus petite taille de chunk recommandée (en dessous, le coût par requête domine)
+const minChunkSize = 5 * 1024 * 1024
+
+// Options décrit les configurations à mesurer
+type Options struct {
+	Sizes       []int64
+	Concurrency []int
+	Budget      int64 // Octets envoyés par configuration
+}
+
+// Result contient les mesures d'une configuration (taille d'objet x workers)
+type Result struct {
+	Size           int64
+	Workers        int
+	Objects        int
+	UploadRate     float64 // Octets par seconde
+	DownloadRate   float64
+	UploadP50      time.Duration
+	UploadP95      time.Duration
+	DownloadP50    time.Duration
+	DownloadP95    time.Duration
+	Errors         int
+	FirstErrorText string
+}
+
+// Recommendation contient les réglages suggérés à partir des mesures
+type Recommendation struct {
+	MaxWorkers int
+	ChunkSize  int64
+	UploadRate float64
+}
+
+// Report contient toutes les mesures d'un benchmark
+type Report struct {
+	Prefix         string
+	Results        []Result
+	Recommendation *Recommendation
+	Duration       time.Duration
+}
+
+// Manager mesure le débit et la latence du stockage configuré avec des objets synthétiques
+type Manager struct {
+	config        *utils.Config
+	storageClient storage.Client
+}
+
+// NewManager crée un nouveau gestionnaire de benchmark
+func NewManager(config *utils.Config, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		storageClient: storageClient,
+	}
+}
+
+// ParseSizes convertit une liste de tailles ("256KB", "8MB")
+func ParseSizes(values []string) ([]int64, error) {
+	sizes := make([]int64, 0, len(values))
+	for _, value := range values {
+		size, err := utils.ParseSize(value)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("%w: invalid benchmark size %q", utils.ErrConfig, value)
+		}
+		sizes = append(sizes, size)
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	return sizes, nil
+}
+
+// Run envoie, relit puis supprime des objets synthétiques sous bench/ pour chaque configuration
+func (m *Manager) Run(options Options, verbose bool) (*Report, error) {
+	// Les objets de test sont supprimés à la fin: refusé en mode append-only
+	if err := utils.CheckDeletesAllowed(m.config, "bench"); err != nil {
+		return nil, err
+	}
+	if options.Budget <= 0 {
+		options.Budget = DefaultBudget
+	}
+
+	start := time.Now()
+	report := &Report{Prefix: fmt.Sprintf("bench/%s/", start.Format("20060102-150405"))}
+	defer m.cleanup(report.Prefix, verbose)
+
+	for _, size := range options.Sizes {
+		payload := make([]byte, size)
+		if _, err := rand.Read(payload); err != nil {
+			return nil, fmt.Errorf("error generating benchmark data: %w", err)
+		}
+
+		count := int(max(options.Budget/size, 2))
+		measured := make(map[int]bool)
+		for _, workers := range options.Concurrency {
+			// Plus de workers que d'objets ne mesure rien de nouveau
+			workers = min(workers, count)
+			if workers < 1 || measured[workers] {
+				continue
+			}
+			measured[workers] = true
+
+			if verbose {
+				utils.Info("📶 %s objects x %d, %d workers", formatBytes(size), count, workers)
+			} else {
+				utils.ProgressStep(fmt.Sprintf("📶 Benchmarking %s objects with %d workers", formatBytes(size), workers))
+			}
+
+			result := m.measure(report.Prefix, payload, count, workers)
+			report.Results = append(report.Results, result)
+
+			if result.Errors > 0 && result.Errors == result.Objects*2 {
+				return report, fmt.Errorf("%w: every benchmark request failed: %s", utils.ErrStorageUnreachable, result.FirstErrorText)
+			}
+		}
+	}
+
+	report.Recommendation = Recommend(report.Results)
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// measure envoie puis relit count objets de la même taille avec workers requêtes parallèles
+func (m *Manager) measure(prefix string, payload []byte, count, workers int) Result {
+	size := int64(len(payload))
+	result := Result{Size: size, Workers: workers, Objects: count}
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%d-%d/%04d", prefix, size, workers, i)
+	}
+
+	var mu sync.Mutex
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Errors++
+		if result.FirstErrorText == "" {
+			result.FirstErrorText = err.Error()
+		}
+	}
+
+	uploaded, elapsed, latencies := runParallel(keys, workers, func(key string) (int64, error) {
+		if err := m.storageClient.Upload(key, payload); err != nil {
+			record(err)
+			return 0, err
+		}
+		return size, nil
+	})
+	result.UploadRate = rate(uploaded, elapsed)
+	result.UploadP50, result.UploadP95 = percentile(latencies, 50), percentile(latencies, 95)
+
+	downloaded, elapsed, latencies := runParallel(keys, workers, func(key string) (int64, error) {
+		data, err := m.storageClient.Download(key)
+		if err != nil {
+			record(err)
+			return 0, err
+		}
+		return int64(len(data)), nil
+	})
+	result.DownloadRate = rate(downloaded, elapsed)
+	result.DownloadP50, result.DownloadP95 = percentile(latencies, 50), percentile(latencies, 95)
+
+	return result
+}
+
+// runParallel exécute op sur chaque clé avec workers goroutines et retourne les octets transférés,
+// la durée totale et la latence des requêtes réussies
+func runParallel(keys []string, workers int, op func(key string) (int64, error)) (int64, time.Duration, []time.Duration) {
+	jobs := make(chan string)
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		total     int64
+		latencies []time.Duration
+	)
+
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				opStart := time.Now()
+				n, err := op(key)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				total += n
+				latencies = append(latencies, time.Since(opStart))
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	return total, time.Since(start), latencies
+}
+
+// cleanup supprime les objets de test
+func (m *Manager) cleanup(prefix string, verbose bool) {
+	objects, err := m.storageClient.ListObjects(prefix)
+	if err != nil {
+		utils.Warn("Could not list benchmark objects under %s: %v", prefix, err)
+		return
+	}
+	for _, obj := range objects {
+		if err := m.storageClient.DeleteObject(obj.Key); err != nil {
+			utils.Warn("Could not delete benchmark object %s: %v", obj.Key, err)
+		}
+	}
+	if verbose {
+		utils.Info("🧹 %d benchmark objects deleted", len(objects))
+	}
+}
+
+// Recommend choisit le nombre de workers et la taille de chunk à partir des débits d'envoi mesurés.
+// À débit comparable (90% du meilleur), la configuration la moins gourmande est préférée.
+func Recommend(results []Result) *Recommendation {
+	best := 0.0
+	for _, r := range results {
+		if r.Errors == 0 {
+			best = max(best, r.UploadRate)
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var chosen *Result
+	for i := range results {
+		r := &results[i]
+		if r.Errors > 0 || r.UploadRate < best*0.9 {
+			continue
+		}
+		// Moins de workers d'abord, puis les plus petits objets (mémoire = chunk x workers)
+		if chosen == nil || r.Workers < chosen.Workers || (r.Workers == chosen.Workers && r.Size < chosen.Size) {
+			chosen = r
+		}
+	}
+	if chosen == nil {
+		return nil
+	}
+
+	return &Recommendation{
+		MaxWorkers: chosen.Workers,
+		ChunkSize:  max(chosen.Size, minChunkSize),
+		UploadRate: chosen.UploadRate,
+	}
+}
+
+// PrintReport affiche les mesures et la recommandation
+func PrintReport(report *Report, config *utils.Config) {
+	fmt.Printf("\n📶 Storage Benchmark Report\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 78))
+	fmt.Printf("%-9s %7s %11s %11s %10s %10s %10s %6s\n",
+		"Size", "Workers", "Up MB/s", "Down MB/s", "Up p50", "Up p95", "Down p50", "Errors")
+	for _, r := range report.Results {
+		fmt.Printf("%-9s %7d %11.2f %11.2f %10s %10s %10s %6d\n",
+			formatBytes(r.Size), r.Workers, r.UploadRate/1024/1024, r.DownloadRate/1024/1024,
+			formatLatency(r.UploadP50), formatLatency(r.UploadP95), formatLatency(r.DownloadP50), r.Errors)
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 78))
+	fmt.Printf("Duration: %v\n", report.Duration.Round(time.Second))
+
+	for _, r := range report.Results {
+		if r.FirstErrorText != "" {
+			fmt.Printf("❌ %s x %d workers: %s\n", formatBytes(r.Size), r.Workers, r.FirstErrorText)
+		}
+	}
+
+	rec := report.Recommendation
+	if rec == nil {
+		fmt.Printf("\nNo recommendation: no configuration completed without errors\n\n")
+		return
+	}
+	fmt.Printf("\n💡 Recommended settings (%.2f MB/s upload):\n", rec.UploadRate/1024/1024)
+	fmt.Printf("  backup.max_workers: %d (current: %d)\n", rec.MaxWorkers, config.Backup.MaxWorkers)
+	fmt.Printf("  backup.chunk_size: %s (current: %s)\n", FormatConfigSize(rec.ChunkSize), config.Backup.ChunkSize)
+	fmt.Printf("  Peak chunk memory: ~%s\n\n", formatBytes(rec.ChunkSize*int64(rec.MaxWorkers)))
+}
+
+// FormatConfigSize formate une taille dans la syntaxe de la configuration ("8MB", "256KB")
+func FormatConfigSize(size int64) string {
+	switch {
+	case size%(1024*1024) == 0:
+		return fmt.Sprintf("%dMB", size/1024/1024)
+	case size%1024 == 0:
+		return fmt.Sprintf("%dKB", size/1024)
+	default:
+		return fmt.Sprintf("%d", size)
+	}
+}
+
+// rate calcule un débit en octets par seconde
+func rate(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) / elapsed.Seconds()
+}
+
+// percentile retourne le p-ième centile des latences
+func percentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// formatLatency formate une latence pour le tableau
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// formatBytes formate les bytes en unités lisibles
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
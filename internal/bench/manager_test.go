@@ -0,0 +1,33 @@
+package bench
+
+import "testing"
+
+func TestRecommendPrefersFewerWorkers(t *testing.T) {
+	const mb = 1024 * 1024
+	results := []Result{
+		{Size: 1 * mb, Workers: 1, UploadRate: 10 * mb},
+		{Size: 8 * mb, Workers: 4, UploadRate: 95 * mb},
+		{Size: 8 * mb, Workers: 16, UploadRate: 100 * mb},
+		{Size: 32 * mb, Workers: 4, UploadRate: 98 * mb},
+		{Size: 32 * mb, Workers: 8, UploadRate: 120 * mb, Errors: 1},
+	}
+
+	rec := Recommend(results)
+	if rec == nil {
+		t.Fatal("Une recommandation est attendue")
+	}
+	// Le meilleur débit sans erreur est 100 MB/s: 4 workers suffisent, avec les plus petits objets
+	if rec.MaxWorkers != 4 || rec.ChunkSize != 8*mb {
+		t.Errorf("Recommandation inattendue: %d workers, chunk %d", rec.MaxWorkers, rec.ChunkSize)
+	}
+
+	if FormatConfigSize(rec.ChunkSize) != "8MB" {
+		t.Errorf("Format inattendu: %s", FormatConfigSize(rec.ChunkSize))
+	}
+
+	// Les petits objets sont relevés au minimum de chunk
+	rec = Recommend([]Result{{Size: 256 * 1024, Workers: 2, UploadRate: mb}})
+	if rec.ChunkSize != minChunkSize {
+		t.Errorf("Chunk minimum attendu, obtenu %d", rec.ChunkSize)
+	}
+}
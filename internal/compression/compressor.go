@@ -1,6 +1,7 @@
 package compression
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"fmt"
@@ -30,7 +31,7 @@ func NewCompressor(level int) (*Compressor, error) {
 // CompressFile compresses data with adaptive compression based on file type and size
 func (c *Compressor) CompressFile(data []byte, filePath string) ([]byte, error) {
 	// Check if file should be compressed based on extension
-	if !c.shouldCompress(filePath) {
+	if !c.ShouldCompress(filePath) {
 		utils.Debug("Skipping compression for: %s (already compressed format)", filePath)
 		return data, nil
 	}
@@ -171,7 +172,7 @@ func (c *Compressor) Decompress(data []byte) ([]byte, error) {
 
 // CompressFileToFile compresse un fichier complet vers un autre fichier
 func (c *Compressor) CompressFileToFile(inputPath, outputPath string) error {
-	utils.Info("Compression du fichier: %s", inputPath)
+	utils.Info("Compressing file: %s", inputPath)
 
 	// Lire le fichier source
 	data, err := utils.ReadFile(inputPath)
@@ -219,48 +220,44 @@ func (c *Compressor) DecompressFile(inputPath, outputPath string) error {
 	return nil
 }
 
-// CompressStream compresse un flux de données
+// CompressStream compresse un flux de données au fil de l'eau (sans le charger en mémoire)
 func (c *Compressor) CompressStream(input io.Reader, output io.Writer) error {
-	// Lire toutes les données
-	data, err := io.ReadAll(input)
+	writer, err := gzip.NewWriterLevel(output, c.level)
 	if err != nil {
-		return fmt.Errorf("error reading data: %w", err)
+		return fmt.Errorf("error creating GZIP writer: %w", err)
 	}
-
-	// Compress les données
-	compressedData, err := c.Compress(data)
-	if err != nil {
+	if _, err := io.Copy(writer, input); err != nil {
 		return fmt.Errorf("error compressing: %w", err)
 	}
-
-	// Écrire les données compressedes
-	if _, err := output.Write(compressedData); err != nil {
-		return fmt.Errorf("error writing data compressedes: %w", err)
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing writer: %w", err)
 	}
-
 	return nil
 }
 
-// DecompressStream décompresse un flux de données
-func (c *Compressor) DecompressStream(input io.Reader, output io.Writer) error {
-	// Lire toutes les données compressedes
-	compressedData, err := io.ReadAll(input)
-	if err != nil {
-		return fmt.Errorf("error reading data compressedes: %w", err)
+// DecompressStream décompresse un flux de données au fil de l'eau et retourne le nombre
+// d'octets écrits. Comme Decompress, un flux non compressé (sans l'en-tête GZIP) est recopié tel quel.
+func (c *Compressor) DecompressStream(input io.Reader, output io.Writer) (int64, error) {
+	buffered := bufio.NewReader(input)
+	if magic, err := buffered.Peek(2); err != nil || !c.IsCompressed(magic) {
+		n, err := io.Copy(output, buffered)
+		if err != nil {
+			return n, fmt.Errorf("error copying data: %w", err)
+		}
+		return n, nil
 	}
 
-	// Decompress les données
-	decompressedData, err := c.Decompress(compressedData)
+	reader, err := gzip.NewReader(buffered)
 	if err != nil {
-		return fmt.Errorf("error decompressing: %w", err)
+		return 0, fmt.Errorf("error creating GZIP reader: %w", err)
 	}
+	defer reader.Close()
 
-	// Écrire les données decompressed
-	if _, err := output.Write(decompressedData); err != nil {
-		return fmt.Errorf("error writing data decompressed: %w", err)
+	n, err := io.Copy(output, reader)
+	if err != nil {
+		return n, fmt.Errorf("error decompressing: %w", err)
 	}
-
-	return nil
+	return n, nil
 }
 
 // CompressStreamOptimized compresses data in chunks for better memory efficiency
@@ -351,8 +348,8 @@ func (c *Compressor) SetCompressionLevel(level int) error {
 	return nil
 }
 
-// shouldCompress determines if a file should be compressed based on its extension
-func (c *Compressor) shouldCompress(filePath string) bool {
+// ShouldCompress determines if a file should be compressed based on its extension
+func (c *Compressor) ShouldCompress(filePath string) bool {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	// Already compressed formats - skip compression
@@ -0,0 +1,54 @@
+package compression
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	compressor, err := NewCompressor(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("bcrdf streaming round trip\n"), 4096)
+
+	var compressed bytes.Buffer
+	if err := compressor.CompressStream(bytes.NewReader(data), &compressed); err != nil {
+		t.Fatal(err)
+	}
+	if !compressor.IsCompressed(compressed.Bytes()) || compressed.Len() >= len(data) {
+		t.Fatalf("flux compressé inattendu: %d octets pour %d", compressed.Len(), len(data))
+	}
+
+	// Le flux se décompresse aussi avec Decompress (même format que Compress)
+	whole, err := compressor.Decompress(compressed.Bytes())
+	if err != nil || !bytes.Equal(whole, data) {
+		t.Fatalf("Decompress du flux: %v", err)
+	}
+
+	var restored bytes.Buffer
+	n, err := compressor.DecompressStream(bytes.NewReader(compressed.Bytes()), &restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(restored.Bytes(), data) {
+		t.Fatalf("décompression en flux incorrecte: %d octets", n)
+	}
+}
+
+func TestDecompressStreamPassesThroughUncompressedData(t *testing.T) {
+	compressor, err := NewCompressor(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{[]byte("données en clair"), {0x1f}, {}} {
+		var out bytes.Buffer
+		n, err := compressor.DecompressStream(bytes.NewReader(data), &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(data)) || !bytes.Equal(out.Bytes(), data) {
+			t.Errorf("%q devrait être recopié tel quel, obtenu %q", data, out.Bytes())
+		}
+	}
+}
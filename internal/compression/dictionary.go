@@ -0,0 +1,125 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Dictionnaires de compression: DEFLATE avec dictionnaire prédéfini (la fenêtre DEFLATE est de 32KB)
+const (
+	MaxDictionarySize = 32 * 1024
+	// DictionaryMaxFileSize borne les fichiers compressés avec le dictionnaire: au-delà, le gain est négligeable
+	DictionaryMaxFileSize = 128 * 1024
+	dictionarySegment     = 16
+	dictionarySampleBytes = 8 * 1024
+)
+
+// Dictionary est un dictionnaire entraîné sur les petits fichiers d'un job
+type Dictionary struct {
+	ID   string
+	Data []byte
+}
+
+// NewDictionary identifie un dictionnaire par l'empreinte de son contenu
+func NewDictionary(data []byte) *Dictionary {
+	sum := sha256.Sum256(data)
+	return &Dictionary{ID: hex.EncodeToString(sum[:8]), Data: data}
+}
+
+// TrainDictionary construit un dictionnaire à partir d'échantillons: les segments présents dans
+// le plus grand nombre de fichiers sont retenus, les plus fréquents en fin (distances DEFLATE courtes)
+func TrainDictionary(samples [][]byte, size int) []byte {
+	if size <= 0 || size > MaxDictionarySize {
+		size = MaxDictionarySize
+	}
+
+	frequency := make(map[string]int)
+	for _, sample := range samples {
+		if len(sample) > dictionarySampleBytes {
+			sample = sample[:dictionarySampleBytes]
+		}
+		seen := make(map[string]struct{})
+		for i := 0; i+dictionarySegment <= len(sample); i += 4 {
+			segment := string(sample[i : i+dictionarySegment])
+			if _, ok := seen[segment]; ok {
+				continue
+			}
+			seen[segment] = struct{}{}
+			frequency[segment]++
+		}
+	}
+
+	segments := make([]string, 0, len(frequency))
+	for segment, count := range frequency {
+		if count >= 2 {
+			segments = append(segments, segment)
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		if frequency[segments[i]] != frequency[segments[j]] {
+			return frequency[segments[i]] > frequency[segments[j]]
+		}
+		return segments[i] < segments[j]
+	})
+
+	var selected [][]byte
+	var dict []byte
+	for _, segment := range segments {
+		if len(dict)+len(segment) > size {
+			break
+		}
+		if bytes.Contains(dict, []byte(segment)) {
+			continue
+		}
+		selected = append(selected, []byte(segment))
+		dict = append(dict, segment...)
+	}
+
+	// Les segments les plus fréquents en dernier
+	result := make([]byte, 0, len(dict))
+	for i := len(selected) - 1; i >= 0; i-- {
+		result = append(result, selected[i]...)
+	}
+	return result
+}
+
+// CompressWithDictionary compresse en DEFLATE avec le dictionnaire au niveau configuré
+func (c *Compressor) CompressWithDictionary(data []byte, dict *Dictionary) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.CompressStreamWithDictionary(bytes.NewReader(data), &buf, dict); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CompressStreamWithDictionary compresse un flux avec le dictionnaire, au fil de l'eau (même format que CompressWithDictionary)
+func (c *Compressor) CompressStreamWithDictionary(input io.Reader, output io.Writer, dict *Dictionary) error {
+	writer, err := flate.NewWriterDict(output, c.level, dict.Data)
+	if err != nil {
+		return fmt.Errorf("error creating dictionary writer: %w", err)
+	}
+	if _, err := io.Copy(writer, input); err != nil {
+		return fmt.Errorf("error compressing with dictionary: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing dictionary writer: %w", err)
+	}
+	return nil
+}
+
+// DecompressWithDictionary décompresse des données compressées avec CompressWithDictionary
+func (c *Compressor) DecompressWithDictionary(data []byte, dict *Dictionary) ([]byte, error) {
+	reader := flate.NewReaderDict(bytes.NewReader(data), dict.Data)
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing with dictionary %s: %w", dict.ID, err)
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,45 @@
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestDictionaryRoundTripAndRatio(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 50; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`{"timestamp":"2024-01-01T00:00:%02dZ","level":"info","service":"api-gateway","message":"request completed","status":200,"duration_ms":%d}`, i, i*7)))
+	}
+
+	dict := NewDictionary(TrainDictionary(samples, 0))
+	if len(dict.Data) == 0 || len(dict.Data) > MaxDictionarySize {
+		t.Fatalf("taille de dictionnaire inattendue: %d", len(dict.Data))
+	}
+
+	compressor, err := NewCompressor(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"timestamp":"2024-01-02T10:11:12Z","level":"info","service":"api-gateway","message":"request completed","status":200,"duration_ms":42}`)
+
+	withDict, err := compressor.CompressWithDictionary(data, dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := compressor.Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withDict) >= len(plain) {
+		t.Errorf("le dictionnaire devrait améliorer le ratio: %d >= %d", len(withDict), len(plain))
+	}
+
+	restored, err := compressor.DecompressWithDictionary(withDict, dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, data) {
+		t.Error("données restaurées différentes")
+	}
+}
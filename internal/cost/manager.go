@@ -0,0 +1,241 @@
+package cost
+
+import (
+	"fmt"
+	"strings"
+
+	"bcrdf/internal/bench"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Seuils des suggestions
+const (
+	smallObjectSize   = 256 * 1024
+	maxChunkSizeHint  = 64 * 1024 * 1024
+	suggestChunkShare = 0.25 // part des PUT dus aux chunks
+	suggestSmallShare = 0.5  // part des PUT dus aux petits fichiers
+)
+
+// Pricing contient les tarifs par 1000 requêtes (HEAD est facturé comme GET)
+type Pricing struct {
+	Put    float64
+	Get    float64
+	List   float64
+	Delete float64
+}
+
+// DefaultPricing correspond à S3 Standard (us-east-1)
+var DefaultPricing = Pricing{Put: 0.005, Get: 0.0004, List: 0.005, Delete: 0}
+
+// Charge retourne le coût des requêtes
+func (p Pricing) Charge(r storage.RequestCounts) float64 {
+	return (float64(r.Put)*p.Put + float64(r.Get+r.Head)*p.Get + float64(r.List)*p.List + float64(r.Delete)*p.Delete) / 1000
+}
+
+// BackupCost contient les requêtes d'une sauvegarde et leur coût
+type BackupCost struct {
+	BackupID     string
+	Recorded     bool // Requêtes comptées pendant la sauvegarde (sinon estimées depuis les objets stockés)
+	Requests     storage.RequestCounts
+	Charge       float64
+	DataObjects  int
+	ChunkObjects int
+	SmallFiles   int   // Fichiers < 256KB envoyés par cette sauvegarde
+	RestoreGets  int64 // GET nécessaires à une restauration complète
+	RestoreCost  float64
+}
+
+// Report contient l'analyse des sauvegardes et les suggestions
+type Report struct {
+	Pricing     Pricing
+	Backups     []BackupCost
+	Total       storage.RequestCounts
+	TotalCharge float64
+	Suggestions []string
+}
+
+// Manager analyse les requêtes envoyées par les sauvegardes
+type Manager struct {
+	config        *utils.Config
+	indexMgr      *index.Manager
+	storageClient storage.Client
+}
+
+// NewManager crée un gestionnaire d'analyse des coûts
+func NewManager(config *utils.Config, indexMgr *index.Manager, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		indexMgr:      indexMgr,
+		storageClient: storageClient,
+	}
+}
+
+// Analyze calcule les requêtes et leur coût pour une sauvegarde (ou toutes si backupID est vide)
+func (m *Manager) Analyze(backupID string, pricing Pricing, verbose bool) (*Report, error) {
+	refs, err := m.indexMgr.ListBackupRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Pricing: pricing}
+	for i := len(refs) - 1; i >= 0; i-- {
+		ref := refs[i]
+		if backupID != "" && ref.ID != backupID {
+			continue
+		}
+		if verbose {
+			utils.Info("📊 Analyzing %s", ref.ID)
+		}
+
+		backupIndex, err := m.indexMgr.LoadIndex(ref.ID)
+		if err != nil {
+			utils.Warn("Unable to load index %s: %v", ref.ID, err)
+			continue
+		}
+		backup, err := m.analyzeBackup(backupIndex, pricing)
+		if err != nil {
+			return nil, err
+		}
+		report.Backups = append(report.Backups, *backup)
+		report.Total = addCounts(report.Total, backup.Requests)
+		report.TotalCharge += backup.Charge
+	}
+
+	if backupID != "" && len(report.Backups) == 0 {
+		return nil, fmt.Errorf("backup not found: %s", backupID)
+	}
+
+	report.Suggestions = m.suggest(report)
+	return report, nil
+}
+
+// analyzeBackup compte les objets de la sauvegarde et reprend les requêtes enregistrées dans l'index
+func (m *Manager) analyzeBackup(backupIndex *index.BackupIndex, pricing Pricing) (*BackupCost, error) {
+	objects, err := m.storageClient.ListObjects(fmt.Sprintf("data/%s/", backupIndex.BackupID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing data for %s: %w", utils.ErrStorageUnreachable, backupIndex.BackupID, err)
+	}
+
+	backup := &BackupCost{BackupID: backupIndex.BackupID, DataObjects: len(objects)}
+	stored := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		stored[object.Key] = true
+		if strings.Contains(object.Key, ".chunk.") {
+			backup.ChunkObjects++
+		}
+	}
+	for _, file := range backupIndex.Files {
+		if file.HasData() && file.Size < smallObjectSize && file.DataOwner(backupIndex.BackupID) == backupIndex.BackupID && stored[file.DataKey(backupIndex.BackupID)] {
+			backup.SmallFiles++
+		}
+	}
+
+	if backupIndex.Requests != nil {
+		backup.Recorded = true
+		backup.Requests = *backupIndex.Requests
+	} else {
+		// Sauvegarde antérieure au comptage: un PUT par objet stocké et un pour l'index
+		backup.Requests = storage.RequestCounts{Put: int64(len(objects)) + 1}
+	}
+	backup.Charge = pricing.Charge(backup.Requests)
+
+	// La restauration lit aussi les objets des fichiers inchangés, détenus par les sauvegardes précédentes
+	restored, err := index.ListDataObjects(m.storageClient, backupIndex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing data for %s: %w", utils.ErrStorageUnreachable, backupIndex.BackupID, err)
+	}
+	backup.RestoreGets = int64(len(restored)) + 1
+	backup.RestoreCost = pricing.Charge(storage.RequestCounts{Get: backup.RestoreGets})
+	return backup, nil
+}
+
+// suggest propose des réglages réduisant le nombre de requêtes
+func (m *Manager) suggest(report *Report) []string {
+	var suggestions []string
+	if report.Total.Put == 0 {
+		return suggestions
+	}
+
+	chunks, small := 0, 0
+	for _, backup := range report.Backups {
+		chunks += backup.ChunkObjects
+		small += backup.SmallFiles
+	}
+
+	chunkSize, err := utils.ParseSize(m.config.Backup.ChunkSize)
+	if err == nil && chunkSize > 0 && chunkSize < maxChunkSizeHint &&
+		float64(chunks)/float64(report.Total.Put) >= suggestChunkShare {
+		saved := int64(chunks / 2)
+		suggestions = append(suggestions, fmt.Sprintf(
+			"%d%% of PUTs are chunks: raising backup.chunk_size from %s to %s would save about %d requests (%s)",
+			chunks*100/int(report.Total.Put), m.config.Backup.ChunkSize, bench.FormatConfigSize(chunkSize*2), saved,
+			formatCharge(report.Pricing.Charge(storage.RequestCounts{Put: saved}))))
+	}
+
+	if float64(small)/float64(report.Total.Put) >= suggestSmallShare {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"%d%% of PUTs are files under 256KB, each stored as its own object (batch_size is not applied by the uploader): "+
+				"archive directories of many small files (tar) before the backup, or exclude caches with skip patterns",
+			small*100/int(report.Total.Put)))
+	}
+
+	if report.Total.Get > report.Total.Put && !m.config.Backup.MetadataCache {
+		suggestions = append(suggestions, "GET requests outnumber uploads (previous indexes are downloaded on every run): "+
+			"backup.metadata_cache: true serves unchanged indexes from a local cache")
+	}
+	return suggestions
+}
+
+// PrintReport affiche les requêtes et les coûts par sauvegarde
+func PrintReport(report *Report) {
+	fmt.Printf("\n💰 Request Cost Report\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 100))
+	fmt.Printf("Pricing per 1000 requests: PUT %.4f, GET/HEAD %.4f, LIST %.4f, DELETE %.4f\n",
+		report.Pricing.Put, report.Pricing.Get, report.Pricing.List, report.Pricing.Delete)
+	fmt.Printf("%s\n", strings.Repeat("-", 100))
+	fmt.Printf("%-36s %8s %8s %6s %6s %7s %10s %12s\n", "Backup", "PUT", "GET", "LIST", "HEAD", "DELETE", "Cost", "Restore cost")
+	for _, backup := range report.Backups {
+		marker := ""
+		if !backup.Recorded {
+			marker = " *"
+		}
+		fmt.Printf("%-36s %8d %8d %6d %6d %7d %10s %12s%s\n", backup.BackupID,
+			backup.Requests.Put, backup.Requests.Get, backup.Requests.List, backup.Requests.Head, backup.Requests.Delete,
+			formatCharge(backup.Charge), formatCharge(backup.RestoreCost), marker)
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 100))
+	fmt.Printf("%-36s %8d %8d %6d %6d %7d %10s\n", "Total",
+		report.Total.Put, report.Total.Get, report.Total.List, report.Total.Head, report.Total.Delete, formatCharge(report.TotalCharge))
+	fmt.Printf("* estimated from stored objects (backup made before request counting)\n")
+
+	if len(report.Suggestions) > 0 {
+		fmt.Printf("\n💡 Suggestions:\n")
+		for _, suggestion := range report.Suggestions {
+			fmt.Printf("  - %s\n", suggestion)
+		}
+	} else {
+		fmt.Printf("\n✅ No request-heavy pattern detected\n")
+	}
+	fmt.Printf("\n")
+}
+
+// addCounts additionne deux relevés de requêtes
+func addCounts(a, b storage.RequestCounts) storage.RequestCounts {
+	return storage.RequestCounts{
+		Put:    a.Put + b.Put,
+		Get:    a.Get + b.Get,
+		List:   a.List + b.List,
+		Head:   a.Head + b.Head,
+		Delete: a.Delete + b.Delete,
+	}
+}
+
+// formatCharge affiche un montant en conservant les petites valeurs
+func formatCharge(charge float64) string {
+	if charge > 0 && charge < 0.01 {
+		return fmt.Sprintf("$%.4f", charge)
+	}
+	return fmt.Sprintf("$%.2f", charge)
+}
@@ -0,0 +1,39 @@
+package cost
+
+import (
+	"strings"
+	"testing"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+func TestPricingCharge(t *testing.T) {
+	pricing := Pricing{Put: 5, Get: 0.4, List: 5, Delete: 0}
+	charge := pricing.Charge(storage.RequestCounts{Put: 1000, Get: 500, Head: 500, List: 200, Delete: 100})
+	if charge != 5+0.4+1 {
+		t.Errorf("coût inattendu: %f", charge)
+	}
+}
+
+func TestSuggestChunkSizeAndSmallFiles(t *testing.T) {
+	config := &utils.Config{}
+	config.Backup.ChunkSize = "8MB"
+	m := NewManager(config, nil, nil)
+
+	report := &Report{
+		Pricing: DefaultPricing,
+		Total:   storage.RequestCounts{Put: 100},
+		Backups: []BackupCost{{ChunkObjects: 40}},
+	}
+	suggestions := m.suggest(report)
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0], "16MB") {
+		t.Fatalf("suggestion chunk_size attendue: %v", suggestions)
+	}
+
+	report.Backups = []BackupCost{{SmallFiles: 80}}
+	suggestions = m.suggest(report)
+	if len(suggestions) != 1 || !strings.Contains(suggestions[0], "256KB") {
+		t.Fatalf("suggestion petits fichiers attendue: %v", suggestions)
+	}
+}
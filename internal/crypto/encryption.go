@@ -88,7 +88,7 @@ func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
 
 // EncryptFile chiffre un fichier complet
 func (e *Encryptor) EncryptFile(inputPath, outputPath string) error {
-	utils.Info("Chiffrement du fichier: %s", inputPath)
+	utils.Info("Encrypting file: %s", inputPath)
 
 	// Lire le fichier source
 	data, err := utils.ReadFile(inputPath)
@@ -99,7 +99,7 @@ func (e *Encryptor) EncryptFile(inputPath, outputPath string) error {
 	// Chiffrer les données
 	encryptedData, err := e.Encrypt(data)
 	if err != nil {
-		return fmt.Errorf("erreur lors du chiffrement: %w", err)
+		return fmt.Errorf("error during encryption: %w", err)
 	}
 
 	// Écrire le fichier encrypted
@@ -147,7 +147,7 @@ func (e *Encryptor) EncryptStream(input io.Reader, output io.Writer) error {
 	// Chiffrer les données
 	encryptedData, err := e.Encrypt(data)
 	if err != nil {
-		return fmt.Errorf("erreur lors du chiffrement: %w", err)
+		return fmt.Errorf("error during encryption: %w", err)
 	}
 
 	// Écrire les données encryptedes
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -17,8 +18,18 @@ type EncryptionAlgorithm string
 const (
 	AES256GCM         EncryptionAlgorithm = "aes-256-gcm"
 	XChaCha20Poly1305 EncryptionAlgorithm = "xchacha20-poly1305"
+	// None stocke les données en clair (stockage déjà chiffré), uniquement avec backup.allow_unencrypted
+	None EncryptionAlgorithm = "none"
 )
 
+// unencryptedHeader marque les objets stockés sans chiffrement
+var unencryptedHeader = []byte("BCRDF-UNENCRYPTED-v1\n")
+
+// IsUnencrypted indique si un objet a été stocké en clair (encryption_algo: none)
+func IsUnencrypted(data []byte) bool {
+	return bytes.HasPrefix(data, unencryptedHeader)
+}
+
 // decodeKey décode une clé hexadécimale ou retourne les bytes bruts
 func decodeKey(key string) ([]byte, error) {
 	// Si la clé fait 64 caractères et ne contient que des caractères hex, c'est probablement une clé hex
@@ -38,6 +49,8 @@ type EncryptorV2 struct {
 	algorithm EncryptionAlgorithm
 	aesGCM    cipher.AEAD
 	xchacha   cipher.AEAD
+	// allowUnencrypted accepte de lire des objets stockés en clair
+	allowUnencrypted bool
 }
 
 // NewEncryptorV2 crée un nouveau chiffreur avec l'algorithme spécifié
@@ -58,13 +71,16 @@ func NewEncryptorV2(key string, algorithm EncryptionAlgorithm) (*EncryptorV2, er
 		if len(keyBytes) != 32 {
 			return nil, fmt.Errorf("XChaCha20-Poly1305 requires a 32-byte key, got %d", len(keyBytes))
 		}
+	case None:
+		// Pas de clé nécessaire
 	default:
 		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
 
 	encryptor := &EncryptorV2{
-		key:       keyBytes,
-		algorithm: algorithm,
+		key:              keyBytes,
+		algorithm:        algorithm,
+		allowUnencrypted: algorithm == None,
 	}
 
 	// Initialiser l'algorithme spécifique
@@ -97,6 +113,12 @@ func (e *EncryptorV2) initialize() error {
 	return nil
 }
 
+// SetAllowUnencrypted accepte (ou refuse) de lire des objets stockés en clair: un dépôt chiffré
+// ne doit pas accepter un objet en clair substitué par le stockage
+func (e *EncryptorV2) SetAllowUnencrypted(allow bool) {
+	e.allowUnencrypted = allow || e.algorithm == None
+}
+
 // Encrypt chiffre des données avec l'algorithme configuré
 func (e *EncryptorV2) Encrypt(plaintext []byte) ([]byte, error) {
 	switch e.algorithm {
@@ -104,6 +126,10 @@ func (e *EncryptorV2) Encrypt(plaintext []byte) ([]byte, error) {
 		return e.encryptAES(plaintext)
 	case XChaCha20Poly1305:
 		return e.encryptXChaCha(plaintext)
+	case None:
+		data := make([]byte, 0, len(unencryptedHeader)+len(plaintext))
+		data = append(data, unencryptedHeader...)
+		return append(data, plaintext...), nil
 	default:
 		return nil, fmt.Errorf("unsupported algorithm: %s", e.algorithm)
 	}
@@ -111,11 +137,20 @@ func (e *EncryptorV2) Encrypt(plaintext []byte) ([]byte, error) {
 
 // Decrypt déchiffre des données avec l'algorithme configuré
 func (e *EncryptorV2) Decrypt(ciphertext []byte) ([]byte, error) {
+	if IsUnencrypted(ciphertext) {
+		if !e.allowUnencrypted {
+			return nil, fmt.Errorf("object is stored unencrypted: set backup.allow_unencrypted to read it")
+		}
+		return ciphertext[len(unencryptedHeader):], nil
+	}
+
 	switch e.algorithm {
 	case AES256GCM:
 		return e.decryptAES(ciphertext)
 	case XChaCha20Poly1305:
 		return e.decryptXChaCha(ciphertext)
+	case None:
+		return nil, fmt.Errorf("object is encrypted but encryption_algo is none: set the encryption key and algorithm used for this backup")
 	default:
 		return nil, fmt.Errorf("unsupported algorithm: %s", e.algorithm)
 	}
@@ -195,6 +230,8 @@ func ValidateKeyV2(key string, algorithm EncryptionAlgorithm) error {
 			return fmt.Errorf("key must be 32 bytes for %s, got %d", algorithm, len(keyBytes))
 		}
 		return nil
+	case None:
+		return nil
 	default:
 		return fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnencryptedMode(t *testing.T) {
+	plain, err := NewEncryptorV2("", None)
+	if err != nil {
+		t.Fatalf("Erreur lors de la création du mode sans chiffrement: %v", err)
+	}
+
+	data := []byte("contenu du fichier")
+	stored, err := plain.Encrypt(data)
+	if err != nil {
+		t.Fatalf("Erreur lors de l'écriture: %v", err)
+	}
+	if !IsUnencrypted(stored) {
+		t.Fatal("L'en-tête du mode sans chiffrement est attendu")
+	}
+	restored, err := plain.Decrypt(stored)
+	if err != nil || !bytes.Equal(restored, data) {
+		t.Fatalf("Relecture incorrecte: %q, %v", restored, err)
+	}
+
+	// Un dépôt chiffré refuse un objet en clair, sauf avec allow_unencrypted
+	encrypted, err := NewEncryptorV2(strings.Repeat("ab", 32), AES256GCM)
+	if err != nil {
+		t.Fatalf("Erreur lors de la création du chiffreur: %v", err)
+	}
+	if _, err := encrypted.Decrypt(stored); err == nil {
+		t.Error("Un objet en clair doit être refusé par défaut")
+	}
+	encrypted.SetAllowUnencrypted(true)
+	if restored, err := encrypted.Decrypt(stored); err != nil || !bytes.Equal(restored, data) {
+		t.Errorf("Objet en clair refusé malgré allow_unencrypted: %v", err)
+	}
+}
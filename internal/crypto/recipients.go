@@ -0,0 +1,190 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// RecipientEncryptor chiffre vers des clés publiques age (asymétrique)
+// La clé privée (identité) n'est nécessaire que pour déchiffrer. Seules les clés age (X25519)
+// sont prises en charge, pas OpenPGP.
+type RecipientEncryptor struct {
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// NewRecipientEncryptor crée un chiffreur asymétrique à partir de clés publiques age
+// et, optionnellement, d'un fichier d'identités pour le déchiffrement
+func NewRecipientEncryptor(recipients []string, identityFile string) (*RecipientEncryptor, error) {
+	encryptor := &RecipientEncryptor{}
+
+	for _, r := range recipients {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", r, err)
+		}
+		encryptor.recipients = append(encryptor.recipients, recipient)
+	}
+
+	if identityFile != "" {
+		file, err := os.Open(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("error opening identity file: %w", err)
+		}
+		defer file.Close()
+
+		identities, err := age.ParseIdentities(file)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing identity file: %w", err)
+		}
+		encryptor.identities = identities
+	}
+
+	return encryptor, nil
+}
+
+// CanEncrypt indique si au moins un destinataire est configuré
+func (e *RecipientEncryptor) CanEncrypt() bool {
+	return len(e.recipients) > 0
+}
+
+// CanDecrypt indique si une identité est disponible pour déchiffrer
+func (e *RecipientEncryptor) CanDecrypt() bool {
+	return len(e.identities) > 0
+}
+
+// Encrypt chiffre les données pour tous les destinataires
+func (e *RecipientEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	if !e.CanEncrypt() {
+		return nil, fmt.Errorf("no age recipients configured")
+	}
+
+	var buf bytes.Buffer
+	writer, err := age.Encrypt(&buf, e.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing age encryption: %w", err)
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("error encrypting with age: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error finalizing age encryption: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decrypt déchiffre des données avec les identités disponibles
+func (e *RecipientEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if !e.CanDecrypt() {
+		return nil, fmt.Errorf("data is encrypted to age recipients: an identity file is required to decrypt it")
+	}
+
+	reader, err := age.Decrypt(bytes.NewReader(ciphertext), e.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting with age: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading age plaintext: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// NewDataKey génère une clé de données aléatoire (32 octets, hexadécimal) pour EncryptorV2
+func NewDataKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("error generating data key: %w", err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// WrapKey chiffre une clé de données pour les destinataires (base64, stockable dans un index)
+func (e *RecipientEncryptor) WrapKey(key string) (string, error) {
+	wrapped, err := e.Encrypt([]byte(key))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// UnwrapKey déchiffre une clé de données enveloppée par WrapKey
+func (e *RecipientEncryptor) UnwrapKey(wrapped string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return "", fmt.Errorf("invalid wrapped data key: %w", err)
+	}
+	key, err := e.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// Keyring retourne le chiffreur des objets de données: celui de encryption_key, ou pour les
+// sauvegardes chiffrées vers des destinataires celui de leur clé de données, déchiffrée avec
+// l'identité (identity_file). Les clés déchiffrées sont gardées pour les objets suivants.
+type Keyring struct {
+	base       *EncryptorV2
+	algorithm  EncryptionAlgorithm
+	identities *RecipientEncryptor // nil sans fichier d'identité
+
+	mu   sync.Mutex
+	keys map[string]*EncryptorV2
+}
+
+// NewKeyring crée le trousseau d'un lecteur de sauvegardes; identityFile peut être vide
+func NewKeyring(base *EncryptorV2, algorithm EncryptionAlgorithm, identityFile string) (*Keyring, error) {
+	keyring := &Keyring{base: base, algorithm: algorithm, keys: make(map[string]*EncryptorV2)}
+	if identityFile != "" {
+		identities, err := NewRecipientEncryptor(nil, identityFile)
+		if err != nil {
+			return nil, err
+		}
+		keyring.identities = identities
+	}
+	return keyring, nil
+}
+
+// Encryptor retourne le chiffreur des objets protégés par la clé enveloppée wrapped
+// (vide = encryption_key)
+func (k *Keyring) Encryptor(wrapped string) (*EncryptorV2, error) {
+	if wrapped == "" {
+		return k.base, nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if encryptor, ok := k.keys[wrapped]; ok {
+		return encryptor, nil
+	}
+	if k.identities == nil {
+		return nil, fmt.Errorf("data is encrypted to age recipients: set backup.identity_file to decrypt it")
+	}
+	key, err := k.identities.UnwrapKey(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %w", err)
+	}
+	encryptor, err := NewEncryptorV2(key, k.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	encryptor.SetAllowUnencrypted(k.base.allowUnencrypted)
+	k.keys[wrapped] = encryptor
+	return encryptor, nil
+}
@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestRecipientEncryptorRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Erreur lors de la génération de l'identité: %v", err)
+	}
+
+	identityFile := filepath.Join(t.TempDir(), "identity.txt")
+	if err := os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("Erreur lors de l'écriture de l'identité: %v", err)
+	}
+
+	// Côté agent: uniquement la clé publique
+	agent, err := NewRecipientEncryptor([]string{identity.Recipient().String()}, "")
+	if err != nil {
+		t.Fatalf("Erreur lors de la création du chiffreur: %v", err)
+	}
+
+	plaintext := []byte(`{"backup_id":"test-20250101-120000"}`)
+	ciphertext, err := agent.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Erreur lors du chiffrement: %v", err)
+	}
+
+	if _, err := agent.Decrypt(ciphertext); err == nil {
+		t.Error("L'agent ne devrait pas pouvoir déchiffrer sans identité")
+	}
+
+	// Côté poste de restauration: identité privée
+	workstation, err := NewRecipientEncryptor(nil, identityFile)
+	if err != nil {
+		t.Fatalf("Erreur lors du chargement de l'identité: %v", err)
+	}
+
+	decrypted, err := workstation.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Erreur lors du déchiffrement: %v", err)
+	}
+
+	if string(decrypted) != string(plaintext) {
+		t.Errorf("Données incorrectes: attendu %s, obtenu %s", plaintext, decrypted)
+	}
+}
+
+func TestKeyringUnwrapsDataKeys(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityFile := filepath.Join(t.TempDir(), "identity.txt")
+	if err := os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	base, err := NewEncryptorV2("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", AES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Côté agent: clé de données aléatoire, enveloppée pour le destinataire
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent, err := NewRecipientEncryptor([]string{identity.Recipient().String()}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := agent.WrapKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataEncryptor, err := NewEncryptorV2(key, AES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := dataEncryptor.Encrypt([]byte("contenu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := base.Decrypt(ciphertext); err == nil {
+		t.Error("encryption_key ne doit pas déchiffrer les données d'une sauvegarde chiffrée vers des destinataires")
+	}
+
+	// Sans identité, seule encryption_key est disponible
+	keyring, err := NewKeyring(base, AES256GCM, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encryptor, err := keyring.Encryptor(""); err != nil || encryptor != base {
+		t.Errorf("une clé vide doit désigner encryption_key: %v", err)
+	}
+	if _, err := keyring.Encryptor(wrapped); err == nil {
+		t.Error("la clé de données ne doit pas être lisible sans identité")
+	}
+
+	keyring, err = NewKeyring(base, AES256GCM, identityFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := keyring.Encryptor(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := encryptor.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "contenu" {
+		t.Fatalf("déchiffrement avec la clé de données: %v", err)
+	}
+}
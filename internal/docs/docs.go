@@ -0,0 +1,59 @@
+package docs
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// topicFiles contient la documentation de référence embarquée dans le binaire
+//
+//go:embed topics/*.md
+var topicFiles embed.FS
+
+// Topic est une page de documentation (bcrdf docs <name>)
+type Topic struct {
+	Name    string
+	Title   string
+	Content string
+}
+
+// Topics retourne les pages de documentation disponibles, triées par nom
+func Topics() []Topic {
+	entries, _ := topicFiles.ReadDir("topics")
+	topics := make([]Topic, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		topic, err := Lookup(name)
+		if err == nil {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics
+}
+
+// Names retourne les noms des pages (complétion des arguments)
+func Names() []string {
+	var names []string
+	for _, topic := range Topics() {
+		names = append(names, topic.Name)
+	}
+	return names
+}
+
+// Lookup retourne une page de documentation par son nom
+func Lookup(name string) (Topic, error) {
+	data, err := topicFiles.ReadFile("topics/" + name + ".md")
+	if err != nil {
+		return Topic{}, fmt.Errorf("unknown documentation topic %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	content := string(data)
+	title, _, _ := strings.Cut(content, "\n")
+	return Topic{
+		Name:    name,
+		Title:   strings.TrimPrefix(title, "# "),
+		Content: content,
+	}, nil
+}
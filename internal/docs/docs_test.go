@@ -0,0 +1,40 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGenerateManPages(t *testing.T) {
+	root := &cobra.Command{Use: "bcrdf", Short: "Backup tool"}
+	root.PersistentFlags().StringP("config", "c", "config.yaml", "Configuration file")
+	sub := &cobra.Command{Use: "restore", Short: "Restore a backup", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().String("backup-id", "", "Backup ID")
+	root.AddCommand(sub)
+
+	dir := t.TempDir()
+	if err := GenerateManPages(root, dir, "1.0.0"); err != nil {
+		t.Fatalf("Erreur lors de la génération: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "bcrdf-restore.1"))
+	if err != nil {
+		t.Fatalf("Page de la sous-commande absente: %v", err)
+	}
+	if !strings.Contains(string(page), `\-\-backup\-id`) || !strings.Contains(string(page), `\-\-config`) {
+		t.Errorf("Options manquantes dans la page:\n%s", page)
+	}
+
+	for _, topic := range Topics() {
+		if topic.Title == "" {
+			t.Errorf("Page %s sans titre", topic.Name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "bcrdf-"+topic.Name+".7")); err != nil {
+			t.Errorf("Page de manuel absente pour %s: %v", topic.Name, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// GenerateManPages écrit une page de manuel par commande (section 1) et par page de documentation
+// (section 7) dans dir, à partir des métadonnées cobra
+func GenerateManPages(root *cobra.Command, dir, version string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating man directory: %w", err)
+	}
+
+	if err := writeCommandPages(root, dir, version); err != nil {
+		return err
+	}
+
+	for _, topic := range Topics() {
+		var page strings.Builder
+		writeManHeader(&page, root.Name()+"-"+topic.Name, "7", version)
+		page.WriteString(".SH NAME\n")
+		fmt.Fprintf(&page, "%s-%s \\- %s\n", root.Name(), topic.Name, escapeRoff(topic.Title))
+		writeMarkdown(&page, topic.Content)
+		if err := writeManPage(dir, fmt.Sprintf("%s-%s.7", root.Name(), topic.Name), page.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCommandPages écrit la page d'une commande puis celles de ses sous-commandes
+func writeCommandPages(cmd *cobra.Command, dir, version string) error {
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		if err := writeCommandPages(sub, dir, version); err != nil {
+			return err
+		}
+	}
+
+	name := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+	var page strings.Builder
+	writeManHeader(&page, name, "1", version)
+
+	page.WriteString(".SH NAME\n")
+	fmt.Fprintf(&page, "%s \\- %s\n", name, escapeRoff(cmd.Short))
+
+	page.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&page, ".B %s\n", escapeRoff(cmd.UseLine()))
+
+	page.WriteString(".SH DESCRIPTION\n")
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	writeParagraphs(&page, description)
+
+	writeFlags(&page, "OPTIONS", cmd.NonInheritedFlags())
+	writeFlags(&page, "GLOBAL OPTIONS", cmd.InheritedFlags())
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, strings.ReplaceAll(cmd.Parent().CommandPath(), " ", "-")+"(1)")
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && !sub.IsAdditionalHelpTopicCommand() {
+			related = append(related, strings.ReplaceAll(sub.CommandPath(), " ", "-")+"(1)")
+		}
+	}
+	if !cmd.HasParent() {
+		for _, topic := range Topics() {
+			related = append(related, fmt.Sprintf("%s-%s(7)", cmd.Name(), topic.Name))
+		}
+	}
+	if len(related) > 0 {
+		page.WriteString(".SH SEE ALSO\n")
+		page.WriteString(escapeRoff(strings.Join(related, ", ")) + "\n")
+	}
+
+	return writeManPage(dir, name+".1", page.String())
+}
+
+// writeManHeader écrit l'en-tête .TH d'une page
+func writeManHeader(page *strings.Builder, name, section, version string) {
+	fmt.Fprintf(page, ".TH \"%s\" \"%s\" \"%s\" \"bcrdf %s\" \"BCRDF Manual\"\n",
+		strings.ToUpper(name), section, time.Now().Format("January 2006"), version)
+	page.WriteString(".nh\n.ad l\n")
+}
+
+// writeFlags décrit les options d'une commande
+func writeFlags(page *strings.Builder, title string, flags *pflag.FlagSet) {
+	if !flags.HasAvailableFlags() {
+		return
+	}
+	fmt.Fprintf(page, ".SH %s\n", title)
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		page.WriteString(".TP\n")
+		name := "\\fB\\-\\-" + escapeRoff(flag.Name) + "\\fR"
+		if flag.Shorthand != "" {
+			name = "\\fB\\-" + flag.Shorthand + "\\fR, " + name
+		}
+		if flag.Value.Type() != "bool" {
+			name += " \\fI" + flag.Value.Type() + "\\fR"
+		}
+		page.WriteString(name + "\n")
+		usage := flag.Usage
+		if flag.DefValue != "" && flag.DefValue != "false" && flag.DefValue != "[]" {
+			usage += fmt.Sprintf(" (default %s)", flag.DefValue)
+		}
+		page.WriteString(escapeRoff(usage) + "\n")
+	})
+}
+
+// writeParagraphs écrit un texte libre (descriptions cobra) en conservant les blocs indentés
+func writeParagraphs(page *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			page.WriteString(".PP\n")
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "-"):
+			page.WriteString(".br\n" + escapeRoff(line) + "\n")
+		default:
+			page.WriteString(escapeRoff(line) + "\n")
+		}
+	}
+}
+
+// writeMarkdown convertit le sous-ensemble de Markdown des pages embarquées en roff
+func writeMarkdown(page *strings.Builder, content string) {
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "```"):
+			if inCode {
+				page.WriteString(".fi\n.RE\n")
+			} else {
+				page.WriteString(".RS\n.nf\n")
+			}
+			inCode = !inCode
+		case inCode:
+			page.WriteString(escapeRoff(line) + "\n")
+		case strings.HasPrefix(line, "# "):
+			page.WriteString(".SH DESCRIPTION\n")
+		case strings.HasPrefix(line, "## "):
+			page.WriteString(".SH " + escapeRoff(strings.ToUpper(strings.TrimPrefix(line, "## "))) + "\n")
+		case strings.HasPrefix(line, "- "):
+			page.WriteString(".IP \\(bu 2\n" + escapeRoff(stripBackticks(strings.TrimPrefix(line, "- "))) + "\n")
+		case strings.TrimSpace(line) == "":
+			page.WriteString(".PP\n")
+		default:
+			page.WriteString(escapeRoff(stripBackticks(strings.TrimSpace(line))) + "\n")
+		}
+	}
+}
+
+// stripBackticks retire le balisage de code en ligne
+func stripBackticks(text string) string {
+	return strings.ReplaceAll(text, "`", "")
+}
+
+// escapeRoff protège les caractères interprétés par roff
+func escapeRoff(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = "\\&" + text
+	}
+	return text
+}
+
+// writeManPage écrit une page dans le répertoire de sortie
+func writeManPage(dir, name, content string) error {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing man page %s: %w", name, err)
+	}
+	return nil
+}
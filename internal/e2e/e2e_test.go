@@ -0,0 +1,1786 @@
+// Package e2e contient les tests de bout en bout (sauvegarde, restauration, rétention, santé)
+// exécutés contre le stockage en mémoire (storage.type memory), sans bucket réel.
+package e2e
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+
+	"bcrdf/internal/backup"
+	"bcrdf/internal/crypto"
+	"bcrdf/internal/health"
+	"bcrdf/internal/index"
+	"bcrdf/internal/repair"
+	"bcrdf/internal/restore"
+	"bcrdf/internal/retention"
+	"bcrdf/internal/state"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// testKey est une clé AES-256 de test (32 octets en hexadécimal)
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+// sourceFiles est l'arborescence sauvegardée par les tests
+var sourceFiles = map[string]string{
+	"notes.txt":         "première version",
+	"docs/report.odt":   strings.Repeat("rapport annuel ", 2000),
+	"docs/empty.txt":    "",
+	"photos/2024/a.jpg": strings.Repeat("\x00\x01\x02\x03", 4096),
+}
+
+// setup crée une source, un fichier de configuration et un stockage en mémoire dédiés au test
+func setup(t *testing.T) (configFile, sourceDir string, store *storage.MemoryClient) {
+	t.Helper()
+	dir := t.TempDir()
+	sourceDir = filepath.Join(dir, "source")
+	writeTree(t, sourceDir, sourceFiles)
+
+	bucket := strings.ReplaceAll(t.Name(), "/", "-")
+	configFile = filepath.Join(dir, "config.yaml")
+	config := fmt.Sprintf(`storage:
+  type: memory
+  bucket: %s
+backup:
+  encryption_key: %s
+  encryption_algo: aes-256-gcm
+  compression_level: 3
+  max_workers: 2
+  checksum_mode: full
+  network_timeout: 30
+  retry_attempts: 1
+  retry_delay: 1
+  preserve_empty_files: true
+retention:
+  days: 30
+  max_backups: 10
+`, bucket, testKey)
+	if err := os.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store = storage.MemoryStore(bucket)
+	t.Cleanup(func() { store.SetFaults(storage.Faults{}) })
+	return configFile, sourceDir, store
+}
+
+// writeTree écrit les fichiers sous root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// loadConfig charge la configuration du test
+func loadConfig(t *testing.T, configFile string) *utils.Config {
+	t.Helper()
+	config, err := utils.LoadConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+// backupIDs retourne les identifiants des sauvegardes présentes dans le stockage
+func backupIDs(t *testing.T, store *storage.MemoryClient) []string {
+	t.Helper()
+	objects, err := store.ListObjects("indexes/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, object := range objects {
+		if strings.HasSuffix(object.Key, ".json") {
+			ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(object.Key, "indexes/"), ".json"))
+		}
+	}
+	return ids
+}
+
+// dataKeys retourne les clés des objets de données (hors index)
+func dataKeys(t *testing.T, store *storage.MemoryClient) []string {
+	t.Helper()
+	objects, err := store.ListObjects("data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	return keys
+}
+
+// createBackup sauvegarde sourceDir et retourne l'identifiant de la nouvelle sauvegarde
+func createBackup(t *testing.T, configFile, sourceDir string, store *storage.MemoryClient) string {
+	t.Helper()
+	before := backupIDs(t, store)
+	if err := backup.NewManager(configFile).CreateBackup(sourceDir, "e2e", false); err != nil {
+		t.Fatalf("sauvegarde: %v", err)
+	}
+	for _, id := range backupIDs(t, store) {
+		if !contains(before, id) {
+			return id
+		}
+	}
+	t.Fatal("aucun nouvel index après la sauvegarde")
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// assertRestored vérifie que destDir contient exactement les fichiers attendus
+func assertRestored(t *testing.T, destDir string, files map[string]string) {
+	t.Helper()
+	restored := make(map[string]string)
+	err := filepath.Walk(destDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(destDir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		restored[filepath.ToSlash(relPath)] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for relPath, content := range files {
+		got, ok := restored[relPath]
+		if !ok {
+			t.Errorf("fichier non restauré: %s", relPath)
+		} else if !bytes.Equal([]byte(got), []byte(content)) {
+			t.Errorf("contenu restauré différent pour %s (%d octets, attendu %d)", relPath, len(got), len(content))
+		}
+	}
+	for relPath := range restored {
+		if _, ok := files[relPath]; !ok {
+			t.Errorf("fichier inattendu restauré: %s", relPath)
+		}
+	}
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+
+	backupID := createBackup(t, configFile, sourceDir, store)
+	if len(dataKeys(t, store)) == 0 {
+		t.Fatal("aucun objet de données envoyé")
+	}
+
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+}
+
+func TestSymlinksRestoredAsLinks(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	if err := os.Symlink("notes.txt", filepath.Join(sourceDir, "notes-link")); err != nil {
+		t.Skipf("liens symboliques indisponibles: %v", err)
+	}
+
+	backupID := createBackup(t, configFile, sourceDir, store)
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(destDir, "notes-link")); err != nil || target != "notes.txt" {
+		t.Errorf("lien non restauré: %q, %v", target, err)
+	}
+}
+
+func TestIncrementalBackupUploadsOnlyChanges(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	createBackup(t, configFile, sourceDir, store)
+
+	// Les identifiants sont à la seconde près
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version, plus longue"})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	objects, err := store.ListObjects("data/" + second + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("seul le fichier modifié doit être envoyé, obtenu %d objets", len(objects))
+	}
+
+	// Les fichiers inchangés sont restaurés depuis les objets de la première sauvegarde
+	expected := make(map[string]string)
+	for relPath, content := range sourceFiles {
+		expected[relPath] = content
+	}
+	expected["notes.txt"] = "seconde version, plus longue"
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(second, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, expected)
+}
+
+func TestIncrementalBackupRestoresUnchangedFiles(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	first := createBackup(t, configFile, sourceDir, store)
+
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version"})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	// Troisième sauvegarde: les fichiers inchangés lisent les objets des deux précédentes
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"docs/todo.txt": "nouveau fichier"})
+	third := createBackup(t, configFile, sourceDir, store)
+
+	config := loadConfig(t, configFile)
+	thirdIndex, err := index.NewManagerWithClient(config, store).LoadIndex(third)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range thirdIndex.Files {
+		if !file.HasData() {
+			continue
+		}
+		owner := first
+		relPath, _ := filepath.Rel(sourceDir, file.Path)
+		switch filepath.ToSlash(relPath) {
+		case "notes.txt":
+			owner = second
+		case "docs/todo.txt":
+			owner = third
+		}
+		if got := file.DataOwner(third); got != owner {
+			t.Errorf("%s: objets attendus dans %s, index pointant vers %s", file.Path, owner, got)
+		}
+	}
+
+	expected := make(map[string]string)
+	for relPath, content := range sourceFiles {
+		expected[relPath] = content
+	}
+	expected["notes.txt"] = "seconde version"
+	expected["docs/todo.txt"] = "nouveau fichier"
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(third, destDir, false); err != nil {
+		t.Fatalf("restauration de la sauvegarde incrémentale: %v", err)
+	}
+	assertRestored(t, destDir, expected)
+
+	report, err := health.NewManager(config, index.NewManagerWithClient(config, store), store).CheckHealth(false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HealthyBackups != 3 {
+		t.Fatalf("les fichiers inchangés doivent être lus dans la sauvegarde qui les détient: %s", report.Summary)
+	}
+}
+
+func TestIdenticalFilesUploadedOnce(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	copies := map[string]string{
+		"docs/copie.odt":      sourceFiles["docs/report.odt"],
+		"archive/rapport.odt": sourceFiles["docs/report.odt"],
+	}
+	writeTree(t, sourceDir, copies)
+
+	backupID := createBackup(t, configFile, sourceDir, store)
+	objects, err := store.ListObjects("data/" + backupID + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// notes.txt, report.odt (et ses deux copies) et a.jpg; empty.txt sans objet
+	if len(objects) != 3 {
+		t.Fatalf("les copies doivent partager un objet, obtenu %d objets", len(objects))
+	}
+
+	config := loadConfig(t, configFile)
+	report, err := health.NewManager(config, index.NewManagerWithClient(config, store), store).CheckHealth(false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HealthyBackups != 1 {
+		t.Fatalf("le partage d'objet entre copies n'est pas une corruption: %s", report.Summary)
+	}
+
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	expected := make(map[string]string)
+	for relPath, content := range sourceFiles {
+		expected[relPath] = content
+	}
+	for relPath, content := range copies {
+		expected[relPath] = content
+	}
+	assertRestored(t, destDir, expected)
+}
+
+func TestBackupReportWrittenAndUploaded(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	reportDir := filepath.Join(t.TempDir(), "reports") + string(os.PathSeparator)
+	config := loadConfig(t, configFile)
+	config.Backup.ReportPath = reportDir
+	config.Backup.ReportUpload = true
+	if err := utils.WriteConfig(config, configFile); err != nil {
+		t.Fatal(err)
+	}
+	createBackup(t, configFile, sourceDir, store)
+
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"video.bin": strings.Repeat("v", 50000)})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	// Rapport local: résumé, tendance par rapport à la première sauvegarde, plus gros fichiers
+	local, err := os.ReadFile(filepath.Join(reportDir, second+".md"))
+	if err != nil {
+		t.Fatalf("rapport local absent: %v", err)
+	}
+	for _, expected := range []string{"# BCRDF backup report: " + second, "| Status | complete |", "## Trend vs. previous run", "| Files | 4 | 5 | +1 |", "## Largest files", "video.bin", "No file failed."} {
+		if !strings.Contains(string(local), expected) {
+			t.Errorf("rapport sans %q:\n%s", expected, local)
+		}
+	}
+
+	// Rapport envoyé (chiffré) à côté de l'index, relu par 'bcrdf report'
+	uploaded, err := backup.NewManager(configFile).FetchReport(second)
+	if err != nil {
+		t.Fatalf("rapport envoyé: %v", err)
+	}
+	if !bytes.Equal(uploaded, local) {
+		t.Error("le rapport envoyé diffère du rapport local")
+	}
+	if raw, _ := store.Download(index.ReportKey(second, "md")); bytes.Contains(raw, []byte("video.bin")) {
+		t.Error("le rapport envoyé doit être chiffré")
+	}
+
+	// Supprimé avec sauvegarde
+	if err := backup.NewManager(configFile).DeleteBackup(second); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := index.ReportKeys(store, second); len(keys) != 0 {
+		t.Errorf("rapport conservé après la suppression: %v", keys)
+	}
+}
+
+func TestCatalogUpdatedAfterBackup(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config := loadConfig(t, configFile)
+	config.Backup.StateDB = filepath.Join(t.TempDir(), "state.db")
+	config.Backup.Catalog = true
+	if err := utils.WriteConfig(config, configFile); err != nil {
+		t.Fatal(err)
+	}
+	first := createBackup(t, configFile, sourceDir, store)
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"docs/report.odt": "nouvelle version"})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	catalog, err := state.Open(config.Backup.StateDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer catalog.Close()
+	matches, err := catalog.SearchCatalog("REPORT.odt", "", 0)
+	if err != nil || len(matches) != 2 || matches[0].BackupID != second || matches[1].BackupID != first {
+		t.Fatalf("report.odt attendu dans les deux sauvegardes: %+v (%v)", matches, err)
+	}
+
+	// Une sauvegarde supprimée est retirée à la synchronisation suivante
+	if err := backup.NewManager(configFile).DeleteBackup(first); err != nil {
+		t.Fatal(err)
+	}
+	result, err := state.SyncCatalog(catalog, index.NewManagerWithClient(config, store), false)
+	if err != nil || len(result.Removed) != 1 || result.Total != 1 {
+		t.Fatalf("synchronisation du catalogue: %+v (%v)", result, err)
+	}
+}
+
+func TestRetentionDeletesExpiredBackups(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	current := createBackup(t, configFile, sourceDir, store)
+
+	// Sauvegarde expirée: copie de l'index sous une date hors rétention
+	config := loadConfig(t, configFile)
+	indexMgr := index.NewManagerWithClient(config, store)
+	expired, err := indexMgr.LoadIndex(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.BackupID = "e2e-20200101-000000"
+	expired.Files = nil
+	if err := indexMgr.SaveIndex(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := retention.NewManager(config, indexMgr, store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+
+	ids := backupIDs(t, store)
+	if contains(ids, expired.BackupID) {
+		t.Errorf("sauvegarde expirée conservée: %v", ids)
+	}
+	if !contains(ids, current) {
+		t.Errorf("sauvegarde récente supprimée: %v", ids)
+	}
+}
+
+func TestRetentionKeepsObjectsOfUnchangedFiles(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	first := createBackup(t, configFile, sourceDir, store)
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version"})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	config := loadConfig(t, configFile)
+	config.Retention.MaxBackups = 1
+	if err := retention.NewManager(config, index.NewManagerWithClient(config, store), store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+	if ids := backupIDs(t, store); contains(ids, first) || !contains(ids, second) {
+		t.Fatalf("seule la seconde sauvegarde doit rester: %v", ids)
+	}
+
+	// Seul l'ancien notes.txt est supprimé: report.odt et a.jpg restent lus par la seconde sauvegarde
+	objects, err := store.ListObjects("data/" + first + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("2 objets encore référencés attendus sous data/%s/, obtenu %d", first, len(objects))
+	}
+
+	expected := make(map[string]string)
+	for relPath, content := range sourceFiles {
+		expected[relPath] = content
+	}
+	expected["notes.txt"] = "seconde version"
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(second, destDir, false); err != nil {
+		t.Fatalf("restauration après rétention: %v", err)
+	}
+	assertRestored(t, destDir, expected)
+}
+
+// withBackupSettings ajoute des réglages à la section backup de la configuration du test
+func withBackupSettings(t *testing.T, configFile, settings string) {
+	t.Helper()
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := strings.Replace(string(content), "backup:\n", "backup:\n"+settings, 1)
+	if err := os.WriteFile(configFile, []byte(updated), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecipientsKeepDataUnreadableOnTheAgent(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	withBackupSettings(t, configFile, fmt.Sprintf("  recipients:\n    - %s\n", identity.Recipient()))
+
+	// L'agent n'a que la clé publique: sauvegarde incrémentale et rétention relisent les index
+	first := createBackup(t, configFile, sourceDir, store)
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version"})
+	second := createBackup(t, configFile, sourceDir, store)
+	if objects, _ := store.ListObjects("data/" + second + "/"); len(objects) != 1 {
+		t.Fatalf("seul notes.txt doit être renvoyé, %d objets", len(objects))
+	}
+
+	config := loadConfig(t, configFile)
+	secondIndex, err := index.NewManagerWithClient(config, store).LoadIndex(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secondIndex.DataKeys) != 2 || secondIndex.DataKeys[first] == secondIndex.DataKeys[second] {
+		t.Fatalf("une clé de données par sauvegarde détentrice attendue: %v", secondIndex.DataKeys)
+	}
+
+	// encryption_key ne déchiffre pas les données
+	objects, err := store.ListObjects("data/" + first + "/")
+	if err != nil || len(objects) == 0 {
+		t.Fatalf("objets de %s: %v", first, err)
+	}
+	encrypted, err := store.Download(objects[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := crypto.NewEncryptorV2(testKey, crypto.AES256GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryptor.Decrypt(encrypted); err == nil {
+		t.Fatal("les données ne doivent pas se déchiffrer avec encryption_key")
+	}
+
+	config.Retention.MaxBackups = 1
+	if err := retention.NewManager(config, index.NewManagerWithClient(config, store), store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+	if ids := backupIDs(t, store); contains(ids, first) || !contains(ids, second) {
+		t.Fatalf("seule la seconde sauvegarde doit rester: %v", ids)
+	}
+
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(second, destDir, false); err == nil {
+		t.Fatal("la restauration doit exiger l'identité")
+	}
+
+	// Poste de restauration: identity_file
+	identityFile := filepath.Join(t.TempDir(), "identity.txt")
+	if err := os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withBackupSettings(t, configFile, fmt.Sprintf("  identity_file: %s\n", identityFile))
+	expected := make(map[string]string)
+	for relPath, content := range sourceFiles {
+		expected[relPath] = content
+	}
+	expected["notes.txt"] = "seconde version"
+	destDir = filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(second, destDir, false); err != nil {
+		t.Fatalf("restauration avec l'identité: %v", err)
+	}
+	assertRestored(t, destDir, expected)
+}
+
+func TestRetentionKeepsLastCompleteBackup(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	current := createBackup(t, configFile, sourceDir, store)
+
+	// La sauvegarde récente est partielle, la seule sauvegarde complète est expirée
+	config := loadConfig(t, configFile)
+	indexMgr := index.NewManagerWithClient(config, store)
+	partial, err := indexMgr.LoadIndex(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := *partial
+	expired.BackupID = "e2e-20200101-000000"
+	expired.Files = nil
+	if err := indexMgr.SaveIndex(&expired); err != nil {
+		t.Fatal(err)
+	}
+	partial.Status = index.BackupStatusPartial
+	if err := indexMgr.SaveIndex(partial); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := retention.NewManager(config, indexMgr, store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+
+	ids := backupIDs(t, store)
+	if !contains(ids, expired.BackupID) || !contains(ids, current) {
+		t.Errorf("la dernière sauvegarde complète doit être conservée: %v", ids)
+	}
+}
+
+func TestRetentionMinBackupsFloor(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	current := createBackup(t, configFile, sourceDir, store)
+
+	// Trois sauvegardes expirées: le plancher de 3 en conserve les deux plus récentes
+	config := loadConfig(t, configFile)
+	config.Retention.MinBackups = 3
+	indexMgr := index.NewManagerWithClient(config, store)
+	for _, id := range []string{"e2e-20200101-000000", "e2e-20200102-000000", "e2e-20200103-000000"} {
+		expired, err := indexMgr.LoadIndex(current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expired.BackupID = id
+		expired.Files = nil
+		if err := indexMgr.SaveIndex(expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := retention.NewManager(config, indexMgr, store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+
+	ids := backupIDs(t, store)
+	sort.Strings(ids)
+	want := []string{"e2e-20200102-000000", "e2e-20200103-000000", current}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("sauvegardes conservées: %v, attendu %v", ids, want)
+	}
+}
+
+func TestRetentionQuotaPrunesOldestBackups(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	current := createBackup(t, configFile, sourceDir, store)
+
+	// Anciennes sauvegardes conservées par days et max_backups, mais au-delà du quota
+	config := loadConfig(t, configFile)
+	config.Retention.Days = 36500
+	config.Retention.MaxTotalSize = "1KB"
+	indexMgr := index.NewManagerWithClient(config, store)
+	for _, id := range []string{"e2e-20200101-000000", "e2e-20200102-000000"} {
+		old, err := indexMgr.LoadIndex(current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		old.BackupID = id
+		old.Files = nil
+		if err := indexMgr.SaveIndex(old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retentionMgr := retention.NewManager(config, indexMgr, store)
+	result, err := retentionMgr.EnforceQuota(false)
+	if err != nil {
+		t.Fatalf("quota: %v", err)
+	}
+
+	// La dernière sauvegarde complète reste, même si le quota n'est pas atteint
+	ids := backupIDs(t, store)
+	if len(ids) != 1 || ids[0] != current {
+		t.Errorf("sauvegardes restantes: %v, attendu [%s]", ids, current)
+	}
+	if len(result.DeletedBackups) != 2 || result.FreedBytes <= 0 || result.SizeAfter >= result.SizeBefore {
+		t.Errorf("résultat du quota incorrect: %+v", result)
+	}
+}
+
+func TestLegalHoldBlocksDeletion(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	current := createBackup(t, configFile, sourceDir, store)
+
+	// Sauvegarde expirée contenant docs/report.odt
+	config := loadConfig(t, configFile)
+	indexMgr := index.NewManagerWithClient(config, store)
+	expired, err := indexMgr.LoadIndex(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.BackupID = "e2e-20200101-000000"
+	if err := indexMgr.SaveIndex(expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := indexMgr.AddHold("docs/**", "dossier 42"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le gel, stocké dans le dépôt, bloque la suppression et la rétention
+	err = backup.NewManager(configFile).DeleteBackup(expired.BackupID)
+	if !errors.Is(err, utils.ErrLegalHold) || utils.ExitCode(err) != utils.ExitConfigError {
+		t.Fatalf("suppression d'une sauvegarde sous gel: %v", err)
+	}
+	if err := retention.NewManager(config, indexMgr, store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+	if ids := backupIDs(t, store); !contains(ids, expired.BackupID) {
+		t.Fatalf("sauvegarde sous gel supprimée par la rétention: %v", ids)
+	}
+
+	// Gel levé: la rétention reprend
+	if err := indexMgr.RemoveHold("docs/**"); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexMgr.RemoveHold("docs/**"); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("levée d'un gel inexistant: %v", err)
+	}
+	if err := retention.NewManager(config, indexMgr, store).ApplyRetentionPolicy(false); err != nil {
+		t.Fatalf("rétention: %v", err)
+	}
+	if ids := backupIDs(t, store); contains(ids, expired.BackupID) || !contains(ids, current) {
+		t.Errorf("sauvegardes après levée du gel: %v", ids)
+	}
+}
+
+func TestRestoreAuditChain(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config := loadConfig(t, configFile)
+	config.Backup.RestoreAudit = true
+	if err := utils.WriteConfig(config, configFile); err != nil {
+		t.Fatal(err)
+	}
+	backupID := createBackup(t, configFile, sourceDir, store)
+
+	for i := 0; i < 2; i++ {
+		destDir := filepath.Join(t.TempDir(), "restore")
+		if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+			t.Fatalf("restauration %d: %v", i+1, err)
+		}
+	}
+
+	indexMgr := index.NewManagerWithClient(loadConfig(t, configFile), store)
+	records, err := indexMgr.ListAudit()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("enregistrements d'audit: %v %d", err, len(records))
+	}
+	record := records[1]
+	if record.Seq != 2 || record.BackupID != backupID || record.Status != index.AuditStatusSuccess ||
+		record.ChecksumsVerified == 0 || record.ChecksumsVerified != record.FilesRestored || record.User == "" {
+		t.Errorf("enregistrement incorrect: %+v", record)
+	}
+	if result, err := indexMgr.VerifyAudit(); err != nil || len(result.Problems) != 0 || result.Records != 2 {
+		t.Fatalf("chaîne d'audit intacte attendue: %v %+v", err, result)
+	}
+
+	// Supprimer le premier enregistrement rompt la chaîne
+	objects, _ := store.ListObjects(index.AuditPrefix)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	if err := store.DeleteObject(objects[0].Key); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := indexMgr.VerifyAudit(); err != nil || len(result.Problems) == 0 {
+		t.Errorf("chaîne rompue non détectée: %v %+v", err, result)
+	}
+}
+
+func TestHealthDetectsMissingObjects(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	createBackup(t, configFile, sourceDir, store)
+
+	config := loadConfig(t, configFile)
+	healthMgr := health.NewManager(config, index.NewManagerWithClient(config, store), store)
+
+	report, err := healthMgr.CheckHealth(false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.TotalBackups != 1 || report.HealthyBackups != 1 {
+		t.Fatalf("sauvegarde saine attendue: %s", report.Summary)
+	}
+
+	for _, key := range dataKeys(t, store) {
+		if err := store.DeleteObject(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err = healthMgr.CheckHealth(false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.UnhealthyBackups != 1 {
+		t.Fatalf("objets manquants non détectés: %s", report.Summary)
+	}
+}
+
+func TestRepairFromReplica(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	backupID := createBackup(t, configFile, sourceDir, store)
+
+	// Réplique à l'identique dans un autre bucket
+	replica := storage.MemoryStore(strings.ReplaceAll(t.Name(), "/", "-") + "-replica")
+	objects, err := store.ListObjects("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range objects {
+		data, err := store.Download(object.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := replica.Upload(object.Key, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Un objet supprimé, un objet altéré dans le dépôt principal
+	keys := dataKeys(t, store)
+	if len(keys) < 2 {
+		t.Fatalf("au moins 2 objets de données attendus: %v", keys)
+	}
+	if err := store.DeleteObject(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	corrupted, err := store.Download(keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted[len(corrupted)-1] ^= 0xff
+	if err := store.Upload(keys[1], corrupted); err != nil {
+		t.Fatal(err)
+	}
+
+	config := loadConfig(t, configFile)
+	repairMgr, err := repair.NewManager(config, store, replica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := repairMgr.Repair(backupID, true, false)
+	if !errors.Is(err, utils.ErrVerificationFailed) || len(result.Damaged) != 2 {
+		t.Fatalf("2 objets endommagés attendus en simulation: %v %+v", err, result)
+	}
+
+	result, err = repairMgr.Repair(backupID, false, false)
+	if err != nil || len(result.Damaged) != 2 || result.Unrepaired() != 0 {
+		t.Fatalf("réparation: %v %+v", err, result)
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration après réparation: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+
+	// Réplique endommagée elle aussi: échec partiel
+	if err := store.DeleteObject(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := replica.DeleteObject(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repairMgr.Repair(backupID, false, false); !errors.Is(err, utils.ErrPartialFailure) {
+		t.Fatalf("échec partiel attendu, obtenu %v", err)
+	}
+}
+
+func TestRepairFromParity(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config := loadConfig(t, configFile)
+	config.Backup.Parity = "2+1"
+	if err := utils.WriteConfig(config, configFile); err != nil {
+		t.Fatal(err)
+	}
+	backupID := createBackup(t, configFile, sourceDir, store)
+
+	var keys []string
+	for _, key := range dataKeys(t, store) {
+		if strings.HasPrefix(key, "data/") {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) < 2 {
+		t.Fatalf("au moins 2 objets de données attendus: %v", keys)
+	}
+	if err := store.DeleteObject(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	repairMgr, err := repair.NewManager(loadConfig(t, configFile), store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := repairMgr.RepairFromParity(backupID, false, false)
+	if err != nil || len(result.Damaged) != 1 || result.Unrepaired() != 0 {
+		t.Fatalf("reconstruction depuis la parité: %v %+v", err, result)
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration après reconstruction: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+
+	// La parité est supprimée avec la sauvegarde
+	if err := backup.NewManager(configFile).DeleteBackup(backupID); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := store.ListObjects("parity/"); len(objects) != 0 {
+		t.Errorf("objets de parité restants après suppression: %d", len(objects))
+	}
+}
+
+func TestBackupFailsOnInjectedFaults(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+
+	// Écritures partielles: la sauvegarde échoue et l'index tronqué est signalé par la vérification de santé
+	store.SetFaults(storage.Faults{Latency: time.Millisecond, PartialWriteRate: 1})
+	if err := backup.NewManager(configFile).CreateBackup(sourceDir, "e2e", false); err == nil {
+		t.Fatal("erreur attendue avec des écritures partielles")
+	}
+	store.SetFaults(storage.Faults{})
+	config := loadConfig(t, configFile)
+	report, err := health.NewManager(config, index.NewManagerWithClient(config, store), store).CheckHealth(false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HealthyBackups != 0 {
+		t.Fatalf("sauvegarde partielle considérée saine: %s", report.Summary)
+	}
+	for _, id := range backupIDs(t, store) {
+		if err := store.DeleteObject("indexes/" + id + ".json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Index impossible à écrire
+	store.SetFaults(storage.Faults{FailPrefix: "indexes/"})
+	err = backup.NewManager(configFile).CreateBackup(sourceDir, "e2e", false)
+	if !errors.Is(err, storage.ErrInjectedFault) {
+		t.Fatalf("panne d'index attendue, obtenu: %v", err)
+	}
+
+	// Une fois le stockage rétabli, la sauvegarde complète est restaurable
+	store.SetFaults(storage.Faults{})
+	time.Sleep(1100 * time.Millisecond)
+	backupID := createBackup(t, configFile, sourceDir, store)
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+}
+
+func TestSyncKeepsStandbyInLine(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	createBackup(t, configFile, sourceDir, store)
+
+	standby := filepath.Join(t.TempDir(), "standby")
+	if err := restore.NewManager(configFile).SyncBackup(restore.LatestBackup, "e2e", standby, false); err != nil {
+		t.Fatalf("première synchronisation: %v", err)
+	}
+	assertRestored(t, standby, sourceFiles)
+
+	// Dérive de la copie de secours: fichier modifié, fichier et répertoire en trop
+	writeTree(t, standby, map[string]string{"notes.txt": "modifié localement", "extra/old.log": "obsolète"})
+
+	before := storage.Requests()
+	if err := restore.NewManager(configFile).SyncBackup(restore.LatestBackup, "", standby, false); err != nil {
+		t.Fatalf("seconde synchronisation: %v", err)
+	}
+	assertRestored(t, standby, sourceFiles)
+	if gets := storage.Requests().Sub(before).Get; gets > 2 {
+		// Index et fichier modifié uniquement
+		t.Errorf("seuls l'index et le fichier modifié doivent être téléchargés, %d GET", gets)
+	}
+	if _, err := os.Stat(filepath.Join(standby, "extra")); !os.IsNotExist(err) {
+		t.Errorf("répertoire en trop conservé: %v", err)
+	}
+}
+
+func TestVerifyAgainstSourceReportsDrift(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	backupID := createBackup(t, configFile, sourceDir, store)
+
+	indexMgr := index.NewManager(configFile)
+	report, err := indexMgr.CompareWithSource(backupID, sourceDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasDrift() {
+		t.Fatalf("aucune dérive attendue juste après la sauvegarde: %+v", report)
+	}
+
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "modifié après la sauvegarde", "new.txt": "nouveau"})
+	if err := os.Remove(filepath.Join(sourceDir, "photos", "2024", "a.jpg")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = indexMgr.CompareWithSource(backupID, sourceDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(report.Changed) != "[notes.txt]" || fmt.Sprint(report.NotBackedUp) != "[new.txt]" ||
+		fmt.Sprint(report.Deleted) != "[photos/2024/a.jpg]" {
+		t.Fatalf("dérive inattendue: changed=%v not-backed-up=%v deleted=%v", report.Changed, report.NotBackedUp, report.Deleted)
+	}
+}
+
+func TestOpaqueKeysRoundTrip(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("storage:\n"), []byte("storage:\n  opaque_keys: true\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backup.NewManager(configFile).CreateBackup(sourceDir, "e2e", false); err != nil {
+		t.Fatalf("sauvegarde: %v", err)
+	}
+	objects, err := store.ListObjects("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range objects {
+		if strings.Contains(object.Key, "e2e") {
+			t.Errorf("nom de sauvegarde visible dans le bucket: %s", object.Key)
+		}
+	}
+
+	refs, err := index.NewManager(configFile).ListBackupRefs()
+	if err != nil || len(refs) != 1 {
+		t.Fatalf("1 sauvegarde attendue, obtenu %v %v", refs, err)
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(refs[0].ID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+}
+
+func TestMultipleSourcesInOneIndex(t *testing.T) {
+	configFile, sourceDir, _ := setup(t)
+	otherDir := filepath.Join(filepath.Dir(sourceDir), "other")
+	writeTree(t, otherDir, map[string]string{"www/index.html": "<html></html>"})
+
+	if err := backup.NewManager(configFile).CreateBackupSources([]string{sourceDir, otherDir}, "e2e", false); err != nil {
+		t.Fatalf("sauvegarde: %v", err)
+	}
+	refs, err := index.NewManager(configFile).ListBackupRefs()
+	if err != nil || len(refs) != 1 {
+		t.Fatalf("1 sauvegarde attendue, obtenu %v %v", refs, err)
+	}
+	backupIndex, err := index.NewManager(configFile).LoadIndex(refs[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backupIndex.SourceRoots) != 2 || backupIndex.SourcePath != filepath.Dir(sourceDir) {
+		t.Errorf("sources inattendues: %s %v", backupIndex.SourcePath, backupIndex.SourceRoots)
+	}
+
+	// Chaque source est restaurée sous son nom
+	expected := map[string]string{"other/www/index.html": "<html></html>"}
+	for relPath, content := range sourceFiles {
+		expected["source/"+relPath] = content
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(refs[0].ID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, expected)
+
+	// Des sources imbriquées sont refusées
+	err = backup.NewManager(configFile).CreateBackupSources([]string{sourceDir, filepath.Join(sourceDir, "photos")}, "e2e", false)
+	if !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("sources imbriquées acceptées: %v", err)
+	}
+}
+
+func TestSourceGuardrails(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	cacheDir := filepath.Join(sourceDir, ".bcrdf-cache")
+	writeTree(t, cacheDir, map[string]string{"index.bin": "cache"})
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte(fmt.Sprintf("backup:\n  metadata_cache: true\n  metadata_cache_dir: %s\n  temp_dir: %s\n", cacheDir, sourceDir)), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Le cache et la zone temporaire du run, sous la source, ne sont pas sauvegardés
+	backupID := createBackup(t, configFile, sourceDir, store)
+	backupIndex, err := index.NewManager(configFile).LoadIndex(backupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range backupIndex.Files {
+		rel := index.RelativeToSource(file.Path, backupIndex.SourcePath)
+		if strings.HasPrefix(rel, ".bcrdf-cache") || strings.HasPrefix(rel, "bcrdf-tmp-") {
+			t.Errorf("fichier local de bcrdf sauvegardé: %s", rel)
+		}
+	}
+
+	// Une source située dans un répertoire de bcrdf est refusée
+	err = backup.NewManager(configFile).CreateBackup(cacheDir, "e2e", false)
+	if !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("source dans metadata_cache_dir acceptée: %v", err)
+	}
+}
+
+func TestRetryFailedFilesAfterTransientFault(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+
+	// Les premiers envois de données échouent (retry_attempts: 1): la reprise en fin de run les rattrape
+	store.SetFaults(storage.Faults{FailPrefix: "data/", FailCount: 2})
+	backupID := createBackup(t, configFile, sourceDir, store)
+	store.SetFaults(storage.Faults{})
+	backupIndex, err := index.NewManager(configFile).LoadIndex(backupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backupIndex.Status != index.BackupStatusComplete {
+		t.Errorf("sauvegarde complète attendue, statut %q", backupIndex.Status)
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+
+	// Sans reprise, la même panne laisse le fichier modifié en échec
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  retry_failed_files: false\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "deuxième version"})
+	time.Sleep(1100 * time.Millisecond)
+	store.SetFaults(storage.Faults{FailPrefix: "data/", FailCount: 1})
+	failedID := createBackup(t, configFile, sourceDir, store)
+	failed, err := index.NewManager(configFile).LoadIndex(failedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.Status == index.BackupStatusComplete {
+		t.Error("échec d'envoi non signalé sans retry_failed_files")
+	}
+}
+
+func TestPerFileTimeoutCancelsUpload(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  per_file_timeout: 1\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chaque envoi de données dure 3s: les fichiers dépassent per_file_timeout et leur envoi est annulé
+	store.SetFaults(storage.Faults{SlowPrefix: "data/", SlowUpload: 3 * time.Second})
+	backupID := createBackup(t, configFile, sourceDir, store)
+	store.SetFaults(storage.Faults{})
+	backupIndex, err := index.NewManager(configFile).LoadIndex(backupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range backupIndex.Files {
+		if !file.IsDirectory && file.Size > 0 && !file.IsSkipped() {
+			t.Errorf("fichier non ignoré après per_file_timeout: %s (statut %q)", file.Path, file.Status)
+		}
+	}
+
+	// Un envoi abandonné ne se termine pas en arrière-plan après la sauvegarde
+	time.Sleep(3500 * time.Millisecond)
+	if keys := dataKeys(t, store); len(keys) > 0 {
+		t.Errorf("objets envoyés après l'abandon: %v", keys)
+	}
+}
+
+func TestStallTimeoutFollowsUploadProgress(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  stall_timeout: 1\n  retry_failed_files: false\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Contenu incompressible: l'envoi dure environ 3s au débit simulé
+	video := make([]byte, 24*1024)
+	rand.New(rand.NewSource(1)).Read(video)
+	files := map[string]string{"video.bin": string(video)}
+	writeTree(t, sourceDir, files)
+
+	// Un envoi lent qui avance n'est pas pris pour un transfert figé
+	store.SetFaults(storage.Faults{UploadRate: 8 * 1024})
+	backupID := createBackup(t, configFile, sourceDir, store)
+	store.SetFaults(storage.Faults{})
+	backupIndex, err := index.NewManager(configFile).LoadIndex(backupID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backupIndex.Status != index.BackupStatusComplete {
+		t.Errorf("sauvegarde complète attendue malgré l'envoi lent, statut %q", backupIndex.Status)
+	}
+	destDir := filepath.Join(t.TempDir(), "restore")
+	restoreMgr := restore.NewManager(configFile)
+	if err := restoreMgr.SetPathFilters([]string{"video.bin"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreMgr.RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, files)
+
+	// Un envoi figé est abandonné
+	time.Sleep(1100 * time.Millisecond)
+	video[0] ^= 0xff
+	writeTree(t, sourceDir, map[string]string{"video.bin": string(video)})
+	store.SetFaults(storage.Faults{SlowPrefix: "data/", SlowUpload: 5 * time.Second})
+	stalledID := createBackup(t, configFile, sourceDir, store)
+	store.SetFaults(storage.Faults{})
+	stalled, err := index.NewManager(configFile).LoadIndex(stalledID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stalled.Status == index.BackupStatusComplete {
+		t.Error("transfert figé non abandonné")
+	}
+}
+
+func TestChunkedFileResumesAfterFailedChunk(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  large_file_threshold: 32KB\n  chunk_size: 16KB\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// 7 chunks, contenu différent à chaque version pour que le fichier soit renvoyé
+	bigFile := func(version int) map[string]string {
+		var content strings.Builder
+		for i := 0; content.Len() < 100*1024; i++ {
+			fmt.Fprintf(&content, "v%d bloc %d %x\n", version, i, i*7919)
+		}
+		return map[string]string{"big.bin": content.String()}
+	}
+	writeTree(t, sourceDir, bigFile(1))
+	createBackup(t, configFile, sourceDir, store)
+
+	// Envoi sans panne: requêtes PUT de référence
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, bigFile(2))
+	before := storage.Requests()
+	createBackup(t, configFile, sourceDir, store)
+	reference := storage.Requests().Sub(before).Put
+
+	// Le 4e chunk échoue: la reprise en fin de run repart de ce chunk, sans renvoyer les trois premiers
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, bigFile(3))
+	store.SetFaults(storage.Faults{FailPrefix: "data/", FailAfter: 4, FailCount: 1})
+	before = storage.Requests()
+	backupID := createBackup(t, configFile, sourceDir, store)
+	store.SetFaults(storage.Faults{})
+	if puts := storage.Requests().Sub(before).Put; puts != reference+1 {
+		t.Errorf("%d PUT attendus (référence + le chunk en échec), obtenu %d", reference+1, puts)
+	}
+
+	// Les chunks repris et les chunks renvoyés forment le fichier de la dernière version
+	destDir := filepath.Join(t.TempDir(), "restore")
+	restoreMgr := restore.NewManager(configFile)
+	if err := restoreMgr.SetPathFilters([]string{"big.bin"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreMgr.RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, bigFile(3))
+}
+
+func TestChunkedFileResumesAfterInterruptedRun(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	config := loadConfig(t, configFile)
+	config.Backup.StateDB = filepath.Join(t.TempDir(), "state.db")
+	config.Backup.LargeFileThreshold = "32KB"
+	config.Backup.ChunkSize = "16KB"
+	config.Backup.ErrorPolicy = utils.ErrorPolicyFail
+	config.Backup.RetryFailedFiles = false
+	if err := utils.WriteConfig(config, configFile); err != nil {
+		t.Fatal(err)
+	}
+	bigFile := func(version int) map[string]string {
+		var content strings.Builder
+		for i := 0; content.Len() < 100*1024; i++ {
+			fmt.Fprintf(&content, "v%d bloc %d %x\n", version, i, i*7919)
+		}
+		return map[string]string{"big.bin": content.String()}
+	}
+	writeTree(t, sourceDir, bigFile(1))
+	createBackup(t, configFile, sourceDir, store)
+
+	// Envoi sans panne: requêtes PUT de référence
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, bigFile(2))
+	before := storage.Requests()
+	createBackup(t, configFile, sourceDir, store)
+	reference := storage.Requests().Sub(before).Put
+
+	// Le stockage tombe après quelques chunks: l'exécution s'arrête sans écrire d'index
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, bigFile(3))
+	written := backupIDs(t, store)
+	store.SetFaults(storage.Faults{FailPrefix: "data/", FailAfter: 4})
+	if err := backup.NewManager(configFile).CreateBackup(sourceDir, "e2e", false); err == nil {
+		t.Fatal("échec attendu pendant la panne du stockage")
+	}
+	store.SetFaults(storage.Faults{})
+	interrupted, uploaded := "", 0
+	for _, key := range dataKeys(t, store) {
+		id := strings.SplitN(strings.TrimPrefix(key, "data/"), "/", 2)[0]
+		if !contains(written, id) && strings.Contains(key, ".chunk.") {
+			interrupted = id
+			uploaded++
+		}
+	}
+	if uploaded == 0 {
+		t.Fatal("aucun chunk envoyé avant la panne")
+	}
+
+	// L'exécution suivante reprend la sauvegarde interrompue sous son ID, après ses chunks
+	time.Sleep(1100 * time.Millisecond)
+	before = storage.Requests()
+	backupID := createBackup(t, configFile, sourceDir, store)
+	if backupID != interrupted {
+		t.Errorf("reprise de %s attendue, nouvelle sauvegarde %s", interrupted, backupID)
+	}
+	if puts := storage.Requests().Sub(before).Put; puts != reference-int64(uploaded) {
+		t.Errorf("%d PUT attendus (référence moins %d chunks repris), obtenu %d", reference-int64(uploaded), uploaded, puts)
+	}
+
+	destDir := filepath.Join(t.TempDir(), "restore")
+	restoreMgr := restore.NewManager(configFile)
+	if err := restoreMgr.SetPathFilters([]string{"big.bin"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := restoreMgr.RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	assertRestored(t, destDir, bigFile(3))
+}
+
+func TestVerifyUploadsDetectsCorruptedObjects(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+
+	// Sans vérification, un stockage qui altère les objets passe inaperçu
+	store.SetFaults(storage.Faults{CorruptPrefix: "data/"})
+	unverifiedID := createBackup(t, configFile, sourceDir, store)
+	unverified, err := index.NewManager(configFile).LoadIndex(unverifiedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unverified.Status != index.BackupStatusComplete {
+		t.Fatalf("statut %q sans verify_uploads", unverified.Status)
+	}
+
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  verify_uploads: true\n"), 1)
+	if err := os.WriteFile(configFile, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Avec verify_uploads, chaque objet altéré fait échouer son fichier
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "deuxième version"})
+	verifiedID := createBackup(t, configFile, sourceDir, store)
+	verified, err := index.NewManager(configFile).LoadIndex(verifiedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.Status == index.BackupStatusComplete {
+		t.Fatal("objet altéré accepté avec verify_uploads")
+	}
+	for _, file := range verified.Files {
+		if strings.HasSuffix(file.Path, "notes.txt") && !strings.Contains(file.Error, "verification failed") {
+			t.Errorf("échec de vérification attendu pour notes.txt, obtenu %q (%s)", file.Error, file.Status)
+		}
+	}
+
+	// Stockage sain: la vérification passe
+	store.SetFaults(storage.Faults{})
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "troisième version"})
+	healthyID := createBackup(t, configFile, sourceDir, store)
+	healthy, err := index.NewManager(configFile).LoadIndex(healthyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if healthy.Status != index.BackupStatusComplete {
+		t.Errorf("statut %q sur un stockage sain", healthy.Status)
+	}
+}
+
+func TestRestoreConflictReportOnly(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	backupID := createBackup(t, configFile, sourceDir, store)
+
+	// Destination absente: aucun conflit et rien n'est créé
+	missing := filepath.Join(t.TempDir(), "absent")
+	report, err := restore.NewManager(configFile).ReportConflicts(backupID, missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 0 || report.Existing != 0 {
+		t.Errorf("conflits sur une destination absente: %+v", report)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("destination créée par --report-only")
+	}
+
+	// Restauration puis modification locale: un seul conflit, plus récent localement
+	destDir := filepath.Join(t.TempDir(), "restore")
+	if err := restore.NewManager(configFile).RestoreBackup(backupID, destDir, false); err != nil {
+		t.Fatalf("restauration: %v", err)
+	}
+	local := filepath.Join(destDir, "notes.txt")
+	if err := os.WriteFile(local, []byte("modifié après la restauration"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(local, later, later); err != nil {
+		t.Fatal(err)
+	}
+	report, err = restore.NewManager(configFile).ReportConflicts(backupID, destDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Path != "notes.txt" || report.Conflicts[0].Kind != restore.ConflictKindLocalNewer {
+		t.Fatalf("conflit attendu sur notes.txt, obtenu %+v", report.Conflicts)
+	}
+	if data, _ := os.ReadFile(local); string(data) != "modifié après la restauration" {
+		t.Error("fichier local modifié par --report-only")
+	}
+}
+
+func TestExportHardlinkTree(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	exportRoot := t.TempDir()
+
+	first := createBackup(t, configFile, sourceDir, store)
+	result, err := restore.NewManager(configFile).ExportHardlinkTree(first, "", exportRoot, false)
+	if err != nil {
+		t.Fatalf("premier export: %v", err)
+	}
+	if result.Linked != 0 || result.Previous != "" {
+		t.Errorf("premier export lié à un export précédent: %+v", result)
+	}
+	assertRestored(t, filepath.Join(exportRoot, first), sourceFiles)
+
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version"})
+	second := createBackup(t, configFile, sourceDir, store)
+	result, err = restore.NewManager(configFile).ExportHardlinkTree(restore.LatestBackup, "e2e", exportRoot, false)
+	if err != nil {
+		t.Fatalf("second export: %v", err)
+	}
+	expected := map[string]string{"notes.txt": "seconde version"}
+	for path, content := range sourceFiles {
+		if path != "notes.txt" {
+			expected[path] = content
+		}
+	}
+	assertRestored(t, filepath.Join(exportRoot, second), expected)
+
+	// Fichiers inchangés: liens durs vers le premier export; fichier modifié: téléchargé
+	if result.Previous != filepath.Join(exportRoot, first) || result.Linked != 2 || result.Restored != 2 {
+		t.Errorf("export inattendu: %+v", result)
+	}
+	same := func(relPath string) bool {
+		a, errA := os.Stat(filepath.Join(exportRoot, first, relPath))
+		b, errB := os.Stat(filepath.Join(exportRoot, second, relPath))
+		return errA == nil && errB == nil && os.SameFile(a, b)
+	}
+	if !same("docs/report.odt") || !same("photos/2024/a.jpg") {
+		t.Error("fichiers inchangés non liés au premier export")
+	}
+	if same("notes.txt") {
+		t.Error("fichier modifié lié au premier export")
+	}
+
+	// Un export existant n'est jamais écrasé
+	if _, err := restore.NewManager(configFile).ExportHardlinkTree(second, "", exportRoot, false); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("export existant écrasé: %v", err)
+	}
+}
+
+func TestExportTarGz(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	backupID := createBackup(t, configFile, sourceDir, store)
+	archive := filepath.Join(t.TempDir(), "export.tar.gz")
+
+	result, err := restore.NewManager(configFile).ExportArchive(restore.LatestBackup, "e2e", archive, false)
+	if err != nil {
+		t.Fatalf("export tar.gz: %v", err)
+	}
+	if result.Restored != 3 || result.Bytes == 0 {
+		t.Errorf("export inattendu: %+v", result)
+	}
+	assertArchive(t, archive, sourceFiles)
+	if _, err := os.Stat(archive + ".partial"); !os.IsNotExist(err) {
+		t.Errorf("archive partielle laissée: %v", err)
+	}
+
+	// Sous-ensemble filtré
+	filtered := filepath.Join(t.TempDir(), "docs.tar.gz")
+	manager := restore.NewManager(configFile)
+	if err := manager.SetPathFilters([]string{"docs"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ExportArchive(backupID, "", filtered, false); err != nil {
+		t.Fatalf("export filtré: %v", err)
+	}
+	expected := map[string]string{"docs/report.odt": sourceFiles["docs/report.odt"], "docs/empty.txt": ""}
+	assertArchive(t, filtered, expected)
+
+	// Une archive existante n'est jamais écrasée
+	if _, err := restore.NewManager(configFile).ExportArchive(backupID, "", archive, false); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("archive existante écrasée: %v", err)
+	}
+}
+
+func TestBackupInfo(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	first := createBackup(t, configFile, sourceDir, store)
+	time.Sleep(1100 * time.Millisecond)
+	writeTree(t, sourceDir, map[string]string{"notes.txt": "seconde version"})
+	second := createBackup(t, configFile, sourceDir, store)
+
+	info, err := index.NewManager(configFile).LoadBackupInfo(first)
+	if err != nil {
+		t.Fatalf("info: %v", err)
+	}
+	if info.Name != "e2e" || info.Position != 1 || info.Chain != 2 || info.Previous != "" || info.Next != second {
+		t.Errorf("position inattendue: %s %d/%d, précédente %q, suivante %q", info.Name, info.Position, info.Chain, info.Previous, info.Next)
+	}
+	if info.Files.Files != 3 || info.Files.Empty != 1 || info.Index.Origin == nil {
+		t.Errorf("statistiques inattendues: %+v", info.Files)
+	}
+}
+
+func TestImportArchiveAsBackup(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	createBackup(t, configFile, sourceDir, store)
+
+	archive := filepath.Join(t.TempDir(), "legacy-dump.tar.gz")
+	writeArchive(t, archive, sourceFiles)
+
+	// Import daté dans une série existante: sauvegarde complète, restaurable seule
+	createdAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := backup.NewManager(configFile).ImportBackup(archive, "e2e", createdAt, false); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	importedID := "e2e-20200102T000000Z"
+	imported, err := index.NewManager(configFile).LoadIndex(importedID)
+	if err != nil {
+		t.Fatalf("index de l'import %s: %v", importedID, err)
+	}
+	if imported.ImportedFrom != archive || !imported.CreatedAt.Equal(createdAt) {
+		t.Errorf("index importé inattendu: imported_from=%q created_at=%s", imported.ImportedFrom, imported.CreatedAt)
+	}
+	destDir := t.TempDir()
+	if err := restore.NewManager(configFile).RestoreBackup(importedID, destDir, false); err != nil {
+		t.Fatalf("restauration de l'import: %v", err)
+	}
+	assertRestored(t, destDir, sourceFiles)
+	for _, file := range imported.Files {
+		if strings.HasSuffix(file.Path, "notes.txt") && (file.Permissions != "-rw-r-----" || !file.ModifiedTime.Equal(time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC))) {
+			t.Errorf("permissions ou date de l'archive non conservées: %s %s", file.Permissions, file.ModifiedTime)
+		}
+	}
+
+	// Répertoire importé tel quel
+	if err := backup.NewManager(configFile).ImportBackup(sourceDir, "legacy-dir", time.Time{}, false); err != nil {
+		t.Fatalf("import d'un répertoire: %v", err)
+	}
+
+	// Une entrée sortant du répertoire d'extraction est refusée
+	before := backupIDs(t, store)
+	unsafe := filepath.Join(t.TempDir(), "unsafe.tar.gz")
+	writeArchive(t, unsafe, map[string]string{"../evil.txt": "x"})
+	if err := backup.NewManager(configFile).ImportBackup(unsafe, "unsafe", time.Time{}, false); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("archive dangereuse importée: %v", err)
+	}
+	if after := backupIDs(t, store); len(after) != len(before) {
+		t.Errorf("sauvegarde créée par une archive refusée: %v", after)
+	}
+}
+
+func TestAnomalyGuard(t *testing.T) {
+	configFile, sourceDir, store := setup(t)
+	random := rand.New(rand.NewSource(1))
+	randomContent := func() string {
+		data := make([]byte, 8192)
+		random.Read(data)
+		return string(data)
+	}
+	text := func(version int, paths ...string) map[string]string {
+		files := make(map[string]string, len(paths))
+		for _, path := range paths {
+			files[path] = strings.Repeat(fmt.Sprintf("v%d %s: compte rendu de réunion\n", version, path), 200)
+		}
+		return files
+	}
+	var docs, vault []string
+	for i := 0; i < 30; i++ {
+		docs = append(docs, fmt.Sprintf("docs/f%02d.txt", i))
+	}
+	for i := 0; i < 6; i++ {
+		vault = append(vault, fmt.Sprintf("vault/k%d.bin", i))
+	}
+	writeTree(t, sourceDir, text(1, docs...))
+	for _, path := range vault {
+		writeTree(t, sourceDir, map[string]string{path: randomContent()})
+	}
+	setGuard := func(mode string) {
+		t.Helper()
+		config, err := os.ReadFile(configFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config = regexp.MustCompile(`(?m)^  anomaly_guard: .*\n`).ReplaceAll(config, nil)
+		config = bytes.Replace(config, []byte("backup:\n"), []byte("backup:\n  anomaly_guard: "+mode+"\n"), 1)
+		if err := os.WriteFile(configFile, config, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocked := func(confirm bool) error {
+		t.Helper()
+		time.Sleep(1100 * time.Millisecond)
+		manager := backup.NewManager(configFile)
+		manager.SetConfirmAnomaly(confirm)
+		return manager.CreateBackup(sourceDir, "e2e", false)
+	}
+
+	// L'entropie de chaque fichier envoyé est enregistrée dans l'index
+	first := createBackup(t, configFile, sourceDir, store)
+	firstIndex, err := index.NewManager(configFile).LoadIndex(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range firstIndex.Files {
+		switch {
+		case strings.Contains(file.Path, "docs/f") && (file.Entropy <= 0 || file.Entropy >= 7.5):
+			t.Errorf("entropie d'un texte inattendue: %s %.2f", file.Path, file.Entropy)
+		case strings.Contains(file.Path, "vault/") && file.Entropy < 7.5:
+			t.Errorf("entropie de données aléatoires inattendue: %s %.2f", file.Path, file.Entropy)
+		}
+	}
+
+	// Des données déjà aléatoires qui changent ne sont pas une anomalie
+	setGuard(backup.AnomalyGuardBlock)
+	for _, path := range vault {
+		writeTree(t, sourceDir, map[string]string{path: randomContent()})
+	}
+	if err := blocked(false); err != nil {
+		t.Fatalf("données aléatoires légitimes bloquées: %v", err)
+	}
+
+	// Des textes devenus aléatoires (8 fichiers sur 40, sous le seuil de changement) bloquent la sauvegarde
+	for _, path := range docs[:8] {
+		writeTree(t, sourceDir, map[string]string{path: randomContent()})
+	}
+	before := backupIDs(t, store)
+	if err := blocked(false); !errors.Is(err, utils.ErrAnomalyDetected) {
+		t.Fatalf("saut d'entropie non bloqué: %v", err)
+	}
+	if after := backupIDs(t, store); len(after) != len(before) {
+		t.Errorf("index écrit malgré l'anomalie: %v", after)
+	}
+	if err := blocked(true); err != nil {
+		t.Fatalf("sauvegarde confirmée refusée: %v", err)
+	}
+
+	// Plus de anomaly_threshold % des fichiers modifiés: bloqué, simple avertissement en mode warn
+	writeTree(t, sourceDir, text(2, docs[8:]...))
+	if err := blocked(false); !errors.Is(err, utils.ErrAnomalyDetected) {
+		t.Fatalf("taux de changement anormal non bloqué: %v", err)
+	}
+	setGuard(backup.AnomalyGuardWarn)
+	if err := blocked(false); err != nil {
+		t.Fatalf("anomalie bloquante en mode warn: %v", err)
+	}
+}
+
+// writeArchive écrit une archive tar.gz des fichiers (mode 0640, datés de 2019)
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	modTime := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0640, Size: int64(len(content)), ModTime: modTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertArchive vérifie qu'une archive tar.gz contient exactement les fichiers attendus
+func assertArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("archive gzip illisible: %v", err)
+	}
+	archived := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("archive tar illisible: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archived[header.Name] = string(data)
+	}
+
+	for relPath, content := range files {
+		if got, ok := archived[relPath]; !ok {
+			t.Errorf("fichier absent de l'archive: %s", relPath)
+		} else if got != content {
+			t.Errorf("contenu archivé différent pour %s (%d octets, attendu %d)", relPath, len(got), len(content))
+		}
+	}
+	for relPath := range archived {
+		if _, ok := files[relPath]; !ok {
+			t.Errorf("fichier inattendu dans l'archive: %s", relPath)
+		}
+	}
+}
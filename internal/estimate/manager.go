@@ -0,0 +1,336 @@
+package estimate
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"bcrdf/internal/bench"
+	"bcrdf/internal/compression"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Valeurs par défaut de bcrdf estimate
+const (
+	DefaultSampleBytes = 64 * 1024 * 1024
+	maxBytesPerFile    = 4 * 1024 * 1024
+	maxSampledFiles    = 4096
+	encryptionOverhead = 28 // Nonce et tag AEAD ajoutés à chaque objet
+)
+
+// Options décrit l'échantillonnage et le débit utilisés pour l'estimation
+type Options struct {
+	SampleBytes int64   // Volume lu dans la source pour mesurer la compression
+	Uplink      float64 // Débit d'envoi en octets par seconde
+}
+
+// Report contient l'estimation d'une première sauvegarde de la source
+type Report struct {
+	Source              string
+	Files               int // Fichiers sauvegardés
+	Directories         int
+	SourceBytes         int64
+	Excluded            int // Entrées exclues par skip_patterns et les règles de base
+	TooLarge            int // Fichiers au-delà de max_file_size
+	TooLargeBytes       int64
+	IncompressibleBytes int64 // Formats déjà compressés, envoyés tels quels
+	SampledFiles        int
+	SampledBytes        int64
+	Ratio               float64 // Taille compressée rapportée à la taille d'origine (échantillon)
+	CompressSpeed       float64 // Octets compressés par seconde et par cœur
+	Workers             int
+	IndexBytes          int64
+	UploadBytes         int64 // Données et index envoyés
+	Uplink              float64
+	UplinkMeasured      bool
+	Duration            time.Duration
+}
+
+// Manager estime la taille et la durée d'une première sauvegarde sans rien envoyer
+type Manager struct {
+	config *utils.Config
+}
+
+// NewManager crée un nouveau gestionnaire d'estimation
+func NewManager(config *utils.Config) *Manager {
+	return &Manager{config: config}
+}
+
+// Run parcourt sourcePath avec les règles d'exclusion de la sauvegarde, mesure la compression
+// sur un échantillon de fichiers et en déduit la taille de l'index, le volume envoyé et la durée
+func (m *Manager) Run(sourcePath string, options Options, verbose bool) (*Report, error) {
+	if options.SampleBytes <= 0 {
+		options.SampleBytes = DefaultSampleBytes
+	}
+	if options.Uplink <= 0 {
+		options.Uplink = bench.DefaultUplink
+	}
+	compressor, err := compression.NewCompressor(m.config.Backup.CompressionLevel)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	var maxFileSize int64
+	if m.config.Backup.MaxFileSize != "" {
+		if maxFileSize, err = utils.ParseSize(m.config.Backup.MaxFileSize); err != nil {
+			return nil, fmt.Errorf("%w: invalid max_file_size: %w", utils.ErrConfig, err)
+		}
+	}
+
+	if verbose {
+		utils.Info("🔎 Scanning %s", sourcePath)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("🔎 Scanning %s", sourcePath))
+	}
+
+	report := &Report{Source: sourcePath, Uplink: options.Uplink}
+	indexSize := newIndexCounter(m.config.Backup.IndexCompression)
+	random := rand.New(rand.NewSource(1))
+	var compressible []string
+	var compressibleBytes int64
+	seen := 0
+
+	ignores := index.NewIgnoreRules(sourcePath, m.config)
+	err = filepath.WalkDir(sourcePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Illisible: ignoré comme pendant la sauvegarde
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if index.IsExcluded(m.config, path, info) || ignores.Ignored(path, entry.IsDir()) {
+			report.Excluded++
+			if entry.IsDir() && path != sourcePath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if m.config.Backup.PreserveDirectories && path != sourcePath {
+				report.Directories++
+				indexSize.add(path, info)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if maxFileSize > 0 && info.Size() > maxFileSize {
+			report.TooLarge++
+			report.TooLargeBytes += info.Size()
+			return nil
+		}
+		if info.Size() == 0 && !m.config.Backup.PreserveEmptyFiles {
+			return nil
+		}
+
+		report.Files++
+		report.SourceBytes += info.Size()
+		indexSize.add(path, info)
+		if !compressor.ShouldCompress(path) {
+			report.IncompressibleBytes += info.Size()
+			return nil
+		}
+		compressibleBytes += info.Size()
+
+		// Échantillon uniforme de taille bornée (réservoir), reproductible
+		seen++
+		if len(compressible) < maxSampledFiles {
+			compressible = append(compressible, path)
+		} else if i := random.Intn(seen); i < maxSampledFiles {
+			compressible[i] = path
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", sourcePath, err)
+	}
+	if report.IndexBytes, err = indexSize.close(); err != nil {
+		return nil, err
+	}
+
+	if verbose {
+		utils.Info("🗜️  Sampling compression at level %d", m.config.Backup.CompressionLevel)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("🗜️  Sampling compression at level %d", m.config.Backup.CompressionLevel))
+	}
+	if err := m.sampleCompression(report, compressor, compressible, options.SampleBytes); err != nil {
+		return nil, err
+	}
+
+	report.Workers = max(1, min(m.config.Backup.MaxWorkers, runtime.NumCPU()))
+	compressedBytes := int64(float64(compressibleBytes) * report.Ratio)
+	report.UploadBytes = compressedBytes + report.IncompressibleBytes + report.IndexBytes +
+		int64(report.Files)*encryptionOverhead
+
+	seconds := float64(report.UploadBytes) / report.Uplink
+	if report.CompressSpeed > 0 {
+		seconds += float64(compressibleBytes) / (report.CompressSpeed * float64(report.Workers))
+	}
+	report.Duration = time.Duration(seconds * float64(time.Second))
+	return report, nil
+}
+
+// sampleCompression compresse le début des fichiers échantillonnés au niveau configuré
+func (m *Manager) sampleCompression(report *Report, compressor *compression.Compressor, paths []string, sampleBytes int64) error {
+	report.Ratio = 1
+	random := rand.New(rand.NewSource(1))
+	random.Shuffle(len(paths), func(i, j int) { paths[i], paths[j] = paths[j], paths[i] })
+
+	var compressedBytes int64
+	var elapsed time.Duration
+	for _, path := range paths {
+		if report.SampledBytes >= sampleBytes {
+			break
+		}
+		data, err := utils.ReadFileHead(path, maxBytesPerFile)
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		start := time.Now()
+		compressed, err := compressor.CompressWithLevel(data, m.config.Backup.CompressionLevel)
+		if err != nil {
+			return err
+		}
+		elapsed += time.Since(start)
+		report.SampledFiles++
+		report.SampledBytes += int64(len(data))
+		compressedBytes += int64(len(compressed))
+	}
+
+	if report.SampledBytes > 0 {
+		report.Ratio = float64(compressedBytes) / float64(report.SampledBytes)
+	}
+	if elapsed > 0 {
+		report.CompressSpeed = float64(report.SampledBytes) / elapsed.Seconds()
+	}
+	return nil
+}
+
+// indexCounter mesure la taille de l'index sérialisé (et compressé) sans le garder en mémoire
+type indexCounter struct {
+	counter countingWriter
+	gzip    *gzip.Writer
+	writer  io.Writer
+}
+
+// newIndexCounter prépare le comptage selon index_compression
+func newIndexCounter(indexCompression string) *indexCounter {
+	c := &indexCounter{}
+	c.writer = &c.counter
+	if indexCompression != index.IndexCompressionNone {
+		c.gzip = gzip.NewWriter(&c.counter)
+		c.writer = c.gzip
+	}
+	return c
+}
+
+// add sérialise une entrée comme dans l'index: checksum et clé de stockage sont des empreintes
+// (incompressibles), les autres champs ceux d'un fichier envoyé
+func (c *indexCounter) add(path string, info os.FileInfo) {
+	sum := sha256.Sum256([]byte(path))
+	entry := index.FileEntry{
+		Path:         path,
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+		Checksum:     hex.EncodeToString(sum[:]),
+		IsDirectory:  info.IsDir(),
+		Permissions:  info.Mode().String(),
+	}
+	if !info.IsDir() {
+		key := sha256.Sum256(sum[:])
+		entry.StorageKey = hex.EncodeToString(key[:])
+		entry.CompressedSize = info.Size()
+		entry.EncryptedSize = info.Size()
+		entry.Status = index.FileStatusUploaded
+		entry.Compression = index.CompressionGzip
+	}
+	data, _ := json.MarshalIndent(entry, "    ", "  ")
+	c.writer.Write(data)
+	c.writer.Write([]byte(",\n    "))
+}
+
+// close termine la compression et retourne la taille de l'index
+func (c *indexCounter) close() (int64, error) {
+	if c.gzip != nil {
+		if err := c.gzip.Close(); err != nil {
+			return 0, fmt.Errorf("error compressing index estimate: %w", err)
+		}
+	}
+	return c.counter.n, nil
+}
+
+// countingWriter compte les octets écrits
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// MeasureUplink mesure le débit d'envoi vers le stockage avec un court benchmark (objets de 8MB,
+// max_workers envois en parallèle), supprimés à la fin
+func MeasureUplink(config *utils.Config, storageClient storage.Client) (float64, error) {
+	workers := max(1, config.Backup.MaxWorkers)
+	options := bench.Options{
+		Sizes:       []int64{8 * 1024 * 1024},
+		Concurrency: []int{workers},
+		Budget:      int64(max(workers, 4)) * 8 * 1024 * 1024,
+	}
+	report, err := bench.NewManager(config, storageClient).Run(options, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(report.Results) == 0 || report.Results[0].UploadRate <= 0 {
+		return 0, fmt.Errorf("no upload measured")
+	}
+	return report.Results[0].UploadRate, nil
+}
+
+// PrintReport affiche l'estimation
+func PrintReport(report *Report) {
+	fmt.Printf("\n📐 Backup Estimate\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Source: %s\n", report.Source)
+	fmt.Printf("Files: %d (%s)", report.Files, utils.FormatBytes(report.SourceBytes))
+	if report.Directories > 0 {
+		fmt.Printf(", %d directories", report.Directories)
+	}
+	fmt.Printf("\n")
+	if report.Excluded > 0 {
+		fmt.Printf("Excluded: %d entries (skip patterns and built-in rules)\n", report.Excluded)
+	}
+	if report.TooLarge > 0 {
+		fmt.Printf("Over max_file_size: %d files (%s), not backed up\n", report.TooLarge, utils.FormatBytes(report.TooLargeBytes))
+	}
+	if report.IncompressibleBytes > 0 {
+		fmt.Printf("Already compressed formats: %s, stored as-is\n", utils.FormatBytes(report.IncompressibleBytes))
+	}
+	fmt.Printf("Compression sample: %d files (%s), ratio %.1f%%\n",
+		report.SampledFiles, utils.FormatBytes(report.SampledBytes), report.Ratio*100)
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Index size:  %s\n", utils.FormatBytes(report.IndexBytes))
+	fmt.Printf("Upload size: %s\n", utils.FormatBytes(report.UploadBytes))
+	source := "given"
+	if report.UplinkMeasured {
+		source = "measured"
+	}
+	fmt.Printf("Uplink:      %s/s (%s)\n", utils.FormatBytes(int64(report.Uplink)), source)
+	fmt.Printf("Duration:    ~%s with %d worker(s)\n", report.Duration.Round(time.Second), report.Workers)
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("First full backup; later runs only upload changed files.\n\n")
+}
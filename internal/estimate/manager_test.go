@@ -0,0 +1,57 @@
+package estimate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestRunEstimatesUploadAndIndex(t *testing.T) {
+	source := t.TempDir()
+	files := map[string]string{
+		"notes.txt":       strings.Repeat("ligne de texte compressible\n", 4000),
+		"docs/report.csv": strings.Repeat("a;b;c;d\n", 8000),
+		"photos/a.jpg":    strings.Repeat("\xff\xd8\x00\x01", 2048),
+		"cache/tmp.log":   "ignoré",
+		"big/archive.bin": strings.Repeat("x", 256*1024),
+		"docs/empty.txt":  "",
+	}
+	for relPath, content := range files {
+		path := filepath.Join(source, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &utils.Config{}
+	config.Backup.CompressionLevel = 6
+	config.Backup.MaxWorkers = 2
+	config.Backup.SkipPatterns = []string{"*.log"}
+	config.Backup.MaxFileSize = "128KB"
+
+	report, err := NewManager(config).Run(source, Options{Uplink: 1024 * 1024}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// notes.txt, report.csv et a.jpg; tmp.log exclu, archive.bin trop gros, empty.txt non conservé
+	if report.Files != 3 || report.TooLarge != 1 || report.Excluded == 0 {
+		t.Errorf("fichiers comptés incorrects: %+v", report)
+	}
+	if report.IncompressibleBytes != int64(len(files["photos/a.jpg"])) {
+		t.Errorf("le JPEG doit être compté tel quel: %d", report.IncompressibleBytes)
+	}
+	if report.Ratio <= 0 || report.Ratio >= 0.5 {
+		t.Errorf("ratio de compression inattendu pour du texte répétitif: %.2f", report.Ratio)
+	}
+	if report.IndexBytes <= 0 || report.UploadBytes >= report.SourceBytes || report.Duration <= 0 {
+		t.Errorf("estimation incohérente: index %d, envoi %d / source %d, durée %v",
+			report.IndexBytes, report.UploadBytes, report.SourceBytes, report.Duration)
+	}
+}
@@ -0,0 +1,248 @@
+package gc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/internal/parity"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// DefaultGracePeriod protège les objets récents d'une sauvegarde en cours dont l'index n'est pas encore écrit
+const DefaultGracePeriod = time.Hour
+
+// Result contient le résultat d'un passage de garbage collection
+type Result struct {
+	Indexes      int
+	Referenced   int
+	Scanned      int
+	Protected    int
+	Unreferenced []storage.ObjectInfo
+	Deleted      int
+	FreedBytes   int64
+	Errors       []string
+}
+
+// Manager gère la suppression des objets qui ne sont plus référencés par aucun index (mark-and-sweep)
+type Manager struct {
+	config        *utils.Config
+	indexMgr      *index.Manager
+	storageClient storage.Client
+}
+
+// NewManager crée un nouveau gestionnaire de garbage collection
+func NewManager(config *utils.Config, indexMgr *index.Manager, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		indexMgr:      indexMgr,
+		storageClient: storageClient,
+	}
+}
+
+// Collect supprime les objets de données sous prefix qui ne sont référencés par aucun index. Un
+// passage complet (data/) retire aussi les index de base et la parité qui ne servent plus.
+func (m *Manager) Collect(prefix string, gracePeriod time.Duration, dryRun, verbose bool) (*Result, error) {
+	if prefix == "" {
+		prefix = "data/"
+	}
+	prefixes := []string{prefix}
+	if prefix == "data/" {
+		prefixes = append(prefixes, index.IndexBasePrefix, parity.Prefix)
+	}
+	return m.collect(prefixes, gracePeriod, dryRun, verbose)
+}
+
+// CollectBackup retire ce qui appartenait à une sauvegarde dont l'index vient d'être supprimé: ses
+// objets de données qui ne sont plus référencés par aucun index (les fichiers inchangés des
+// sauvegardes suivantes lisent encore les leurs), son index de base s'il ne sert plus, et sa parité
+// une fois qu'aucun de ses objets n'est référencé.
+func (m *Manager) CollectBackup(backupIndex *index.BackupIndex, verbose bool) (*Result, error) {
+	prefixes := []string{fmt.Sprintf("data/%s/", backupIndex.BackupID), parity.Prefix + backupIndex.BackupID + "/"}
+	if backupIndex.BaseID != "" {
+		prefixes = append(prefixes, index.IndexBaseKey(backupIndex.BaseID))
+	}
+	return m.collect(prefixes, 0, false, verbose)
+}
+
+// collect marque les objets référencés puis supprime les objets non référencés sous prefixes
+func (m *Manager) collect(prefixes []string, gracePeriod time.Duration, dryRun, verbose bool) (*Result, error) {
+	if !dryRun {
+		if err := utils.CheckDeletesAllowed(m.config, "gc"); err != nil {
+			return nil, err
+		}
+	}
+
+	if verbose {
+		utils.Info("🧹 Garbage collection: marking referenced objects")
+	} else {
+		utils.ProgressStep("🧹 Marking referenced objects")
+	}
+
+	// Phase mark: toutes les clés référencées par les index existants
+	marked, err := m.mark(verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Indexes:    marked.indexes,
+		Referenced: len(marked.keys),
+	}
+
+	// Phase sweep: lister les objets et retenir ceux qui ne sont pas référencés
+	var objects []storage.ObjectInfo
+	for _, prefix := range prefixes {
+		listed, err := m.storageClient.ListObjects(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects under %s: %w", prefix, err)
+		}
+		objects = append(objects, listed...)
+	}
+	result.Scanned = len(objects)
+
+	// Les dates des objets sont celles du stockage: une horloge locale en avance raccourcirait la grâce
+	cutoff := time.Now().Add(-gracePeriod)
+	if gracePeriod > 0 {
+		cutoff = cutoff.Add(storage.CheckClockSkew(m.config, m.storageClient))
+	}
+	for _, obj := range objects {
+		if marked.referenced(obj.Key) {
+			continue
+		}
+		if gracePeriod > 0 && obj.LastModified.After(cutoff) {
+			result.Protected++
+			continue
+		}
+		result.Unreferenced = append(result.Unreferenced, obj)
+	}
+
+	sort.Slice(result.Unreferenced, func(i, j int) bool {
+		return result.Unreferenced[i].Key < result.Unreferenced[j].Key
+	})
+
+	if dryRun {
+		return result, nil
+	}
+
+	if verbose {
+		utils.Info("🗑️  Sweeping %d unreferenced objects", len(result.Unreferenced))
+	} else if len(result.Unreferenced) > 0 {
+		utils.ProgressStep(fmt.Sprintf("🗑️  Sweeping %d unreferenced objects", len(result.Unreferenced)))
+	}
+
+	for _, obj := range result.Unreferenced {
+		if err := m.storageClient.DeleteObject(obj.Key); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", obj.Key, err))
+			continue
+		}
+		utils.Debug("Deleted unreferenced object: %s", obj.Key)
+		result.Deleted++
+		result.FreedBytes += obj.Size
+	}
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("%w: garbage collection completed with %d errors", utils.ErrPartialFailure, len(result.Errors))
+	}
+
+	return result, nil
+}
+
+// marks contient ce que les index existants référencent
+type marks struct {
+	keys    map[string]bool // Clés de données des fichiers (data/{sauvegarde détentrice}/{storageKey})
+	bases   map[string]bool // Index de base des index delta
+	owners  map[string]bool // Sauvegardes dont des objets de données sont référencés (leur parité est conservée)
+	indexes int
+}
+
+// referenced indique si un objet (données, index de base ou parité) est encore utilisé
+func (k *marks) referenced(key string) bool {
+	switch {
+	case strings.HasPrefix(key, index.IndexBasePrefix):
+		return k.bases[index.BaseIDFromKey(key)]
+	case parity.IsParityKey(key):
+		return k.owners[parity.BackupIDFromKey(key)]
+	default:
+		return k.keys[index.ObjectFileKey(key)]
+	}
+}
+
+// mark charge tous les index et retourne les clés de données, les index de base et les sauvegardes
+// détentrices référencés
+func (m *Manager) mark(verbose bool) (*marks, error) {
+	objects, err := m.storageClient.ListObjects("indexes/")
+	if err != nil {
+		return nil, fmt.Errorf("error listing indexes: %w", err)
+	}
+
+	marked := &marks{
+		keys:   make(map[string]bool),
+		bases:  make(map[string]bool),
+		owners: make(map[string]bool),
+	}
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+		backupID := strings.TrimSuffix(strings.TrimPrefix(obj.Key, "indexes/"), ".json")
+
+		// Un index illisible empêche de prouver qu'un objet n'est plus utilisé: on s'arrête
+		backupIndex, err := m.indexMgr.LoadIndex(backupID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load index %s, refusing to sweep: %w", backupID, err)
+		}
+		if backupIndex.BaseID != "" {
+			marked.bases[backupIndex.BaseID] = true
+		}
+
+		marked.owners[backupIndex.BackupID] = true
+		for _, file := range backupIndex.Files {
+			if file.StorageKey != "" {
+				marked.keys[file.DataKey(backupIndex.BackupID)] = true
+				marked.owners[file.DataOwner(backupIndex.BackupID)] = true
+			}
+		}
+		marked.indexes++
+
+		if verbose {
+			utils.Debug("Marked %d files from %s", len(backupIndex.Files), backupID)
+		}
+	}
+
+	return marked, nil
+}
+
+// PrintResult affiche le résultat d'un passage de garbage collection
+func PrintResult(result *Result, dryRun bool) {
+	fmt.Printf("\n🧹 Garbage Collection Report\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Indexes scanned: %d\n", result.Indexes)
+	fmt.Printf("Referenced files: %d\n", result.Referenced)
+	fmt.Printf("Objects scanned: %d\n", result.Scanned)
+	fmt.Printf("Protected (grace period): %d\n", result.Protected)
+	fmt.Printf("Unreferenced objects: %d\n", len(result.Unreferenced))
+
+	if dryRun {
+		var total int64
+		for _, obj := range result.Unreferenced {
+			fmt.Printf("  🗑️  %s (%s)\n", obj.Key, formatMB(obj.Size))
+			total += obj.Size
+		}
+		fmt.Printf("Would free: %s\n", formatMB(total))
+	} else {
+		fmt.Printf("Deleted: %d (%s freed)\n", result.Deleted, formatMB(result.FreedBytes))
+		for _, e := range result.Errors {
+			fmt.Printf("  ❌ %s\n", e)
+		}
+	}
+	fmt.Printf("\n")
+}
+
+// formatMB formate une taille en mégaoctets
+func formatMB(size int64) string {
+	return fmt.Sprintf("%.2f MB", float64(size)/1024/1024)
+}
@@ -0,0 +1,200 @@
+package gc
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// testKey est une clé AES-256 de test (32 octets en hexadécimal)
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func newTestManager(t *testing.T, deltas bool) (*Manager, *index.Manager, *storage.MemoryClient) {
+	t.Helper()
+	config := &utils.Config{}
+	config.Backup.EncryptionKey = testKey
+	config.Backup.IndexDeltas = deltas
+	store := storage.NewMemoryClient()
+	indexMgr := index.NewManagerWithClient(config, store)
+	return NewManager(config, indexMgr, store), indexMgr, store
+}
+
+func upload(t *testing.T, store *storage.MemoryClient, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		if err := store.Upload(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func keys(t *testing.T, store *storage.MemoryClient, prefix string) []string {
+	t.Helper()
+	objects, err := store.ListObjects(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, object := range objects {
+		listed = append(listed, object.Key)
+	}
+	sort.Strings(listed)
+	return listed
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCollectBackupKeepsObjectsOfUnchangedFiles(t *testing.T) {
+	gcMgr, indexMgr, store := newTestManager(t, false)
+	first := &index.BackupIndex{BackupID: "b1", Files: []index.FileEntry{
+		{Path: "/src/a.txt", StorageKey: "ka"},
+		{Path: "/src/b.txt", StorageKey: "kb"},
+		{Path: "/src/big.iso", StorageKey: "kd"},
+	}}
+	// b.txt et big.iso ont changé: seul a.txt lit encore les objets de b1
+	second := &index.BackupIndex{BackupID: "b2", Files: []index.FileEntry{
+		{Path: "/src/a.txt", StorageKey: "ka", DataBackupID: "b1"},
+		{Path: "/src/b.txt", StorageKey: "kc"},
+	}}
+	if err := indexMgr.SaveIndex(second); err != nil {
+		t.Fatal(err)
+	}
+	upload(t, store, "data/b1/ka", "data/b1/kb", "data/b1/kd.metadata", "data/b1/kd.chunk.000",
+		"data/b2/kc", "parity/b1/manifest.json", "parity/b1/00000.000")
+
+	result, err := gcMgr.CollectBackup(first, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 3 {
+		t.Errorf("3 objets non référencés attendus, %d supprimés", result.Deleted)
+	}
+	if got := keys(t, store, "data/"); !equal(got, []string{"data/b1/ka", "data/b2/kc"}) {
+		t.Errorf("objets restants inattendus: %v", got)
+	}
+	// La parité de b1 protège encore ka
+	if got := keys(t, store, "parity/"); len(got) != 2 {
+		t.Errorf("la parité d'une sauvegarde encore référencée doit être conservée: %v", got)
+	}
+
+	if err := store.DeleteObject("indexes/b2.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gcMgr.CollectBackup(first, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, store, "data/b1/"); len(got) != 0 {
+		t.Errorf("les objets de b1 ne sont plus référencés: %v", got)
+	}
+	if got := keys(t, store, "parity/"); len(got) != 0 {
+		t.Errorf("la parité de b1 ne protège plus rien: %v", got)
+	}
+}
+
+func TestCollectBackupRemovesUnusedIndexBase(t *testing.T) {
+	gcMgr, indexMgr, store := newTestManager(t, true)
+	files := []index.FileEntry{
+		{Path: "/src/a.txt", StorageKey: "ka"},
+		{Path: "/src/b.txt", StorageKey: "kb"},
+		{Path: "/src/c.txt", StorageKey: "kc"},
+	}
+	first := &index.BackupIndex{BackupID: "b1", SourcePath: "/src", Files: files}
+	if err := indexMgr.SaveIndexDelta(first, nil); err != nil {
+		t.Fatal(err)
+	}
+	second := &index.BackupIndex{BackupID: "b2", SourcePath: "/src", Files: append([]index.FileEntry{}, files...)}
+	if err := indexMgr.SaveIndexDelta(second, first); err != nil {
+		t.Fatal(err)
+	}
+	if second.BaseID != "b1" {
+		t.Fatalf("b2 doit être un delta de la base b1, base %q", second.BaseID)
+	}
+
+	// b2 lit encore la base de b1
+	if err := store.DeleteObject("indexes/b1.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gcMgr.CollectBackup(first, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, store, index.IndexBasePrefix); len(got) != 1 {
+		t.Fatalf("base encore utilisée supprimée: %v", got)
+	}
+
+	if err := store.DeleteObject("indexes/b2.json"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gcMgr.CollectBackup(second, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys(t, store, index.IndexBasePrefix); len(got) != 0 {
+		t.Errorf("base inutilisée conservée: %v", got)
+	}
+}
+
+func TestCollectSweepsOrphanParityAndHonorsGracePeriod(t *testing.T) {
+	gcMgr, indexMgr, store := newTestManager(t, false)
+	current := &index.BackupIndex{BackupID: "b2", Files: []index.FileEntry{{Path: "/src/a.txt", StorageKey: "ka"}}}
+	if err := indexMgr.SaveIndex(current); err != nil {
+		t.Fatal(err)
+	}
+	upload(t, store, "data/b2/ka", "data/b1/old", "parity/b1/manifest.json", "parity/b2/manifest.json")
+
+	// Objets récents: protégés par le délai de grâce
+	result, err := gcMgr.Collect("", time.Hour, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 0 || result.Protected != 2 {
+		t.Fatalf("objets récents supprimés: %d supprimés, %d protégés", result.Deleted, result.Protected)
+	}
+
+	result, err = gcMgr.Collect("", 0, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("data/b1/old et la parité de b1 attendus, %d supprimés", result.Deleted)
+	}
+	if got := keys(t, store, ""); !equal(got, []string{"data/b2/ka", "indexes/b2.json", "parity/b2/manifest.json"}) {
+		t.Errorf("objets restants inattendus: %v", got)
+	}
+}
+
+func TestCollectRefusesToSweepWithUnreadableIndex(t *testing.T) {
+	gcMgr, _, store := newTestManager(t, false)
+	upload(t, store, "indexes/b1.json", "data/b1/ka")
+
+	if _, err := gcMgr.Collect("", 0, false, false); err == nil {
+		t.Fatal("un index illisible doit interrompre la collecte")
+	}
+	if got := keys(t, store, "data/"); len(got) != 1 {
+		t.Errorf("aucun objet ne doit être supprimé: %v", got)
+	}
+}
+
+func TestCollectRefusedOnAppendOnlyRepository(t *testing.T) {
+	gcMgr, _, _ := newTestManager(t, false)
+	gcMgr.config.Backup.AppendOnly = true
+
+	if _, err := gcMgr.Collect("", 0, false, false); err == nil {
+		t.Fatal("la collecte doit être refusée sur un dépôt append-only")
+	}
+	if _, err := gcMgr.Collect("", 0, true, false); err != nil {
+		t.Errorf("le dry-run reste permis: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+func init() {
+	Register("command", func(rule utils.FileHandlerRule) (Handler, error) {
+		if strings.TrimSpace(rule.Command) == "" {
+			return nil, fmt.Errorf("command is required")
+		}
+		return commandHandler{command: rule.Command}, nil
+	})
+}
+
+// commandHandler délègue la copie à une commande externe (ex: redis-cli --rdb {output}).
+// {path} et {output} sont remplacés par les chemins (échappés pour le shell), aussi exposés
+// dans BCRDF_HANDLER_PATH et BCRDF_HANDLER_OUTPUT.
+type commandHandler struct {
+	command string
+}
+
+func (h commandHandler) Prepare(path, output string) error {
+	command := strings.NewReplacer("{path}", shellQuote(path), "{output}", shellQuote(output)).Replace(h.command)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "BCRDF_HANDLER_PATH="+path, "BCRDF_HANDLER_OUTPUT="+output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("handler command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if _, err := os.Stat(output); err != nil {
+		return fmt.Errorf("handler command did not write %s", output)
+	}
+	return nil
+}
+
+// shellQuote protège un chemin pour le shell de la plateforme
+func shellQuote(value string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + value + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
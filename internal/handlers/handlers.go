@@ -0,0 +1,103 @@
+// Package handlers prépare des copies cohérentes des fichiers applicatifs (bases SQLite,
+// dumps Redis...) avant leur sauvegarde, à la place d'une lecture directe du fichier.
+package handlers
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"bcrdf/pkg/utils"
+)
+
+// Handler écrit dans output une copie cohérente du fichier path (output n'existe pas encore)
+type Handler interface {
+	Prepare(path, output string) error
+}
+
+// Factory crée un handler à partir de sa règle de configuration (backup.file_handlers)
+type Factory func(rule utils.FileHandlerRule) (Handler, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register déclare un type de handler, référencé par son nom dans backup.file_handlers
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Names retourne les types de handlers déclarés, triés
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rule associe un motif de chemin à un handler instancié
+type rule struct {
+	pattern string
+	name    string
+	handler Handler
+}
+
+// Set est l'ensemble ordonné des handlers d'une sauvegarde: la première règle qui correspond s'applique
+type Set struct {
+	rules []rule
+}
+
+// NewSet instancie les handlers configurés (nil si aucun)
+func NewSet(rules []utils.FileHandlerRule) (*Set, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	set := &Set{}
+	for _, r := range rules {
+		factory, ok := registry[r.Handler]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown file handler %q for %s", utils.ErrConfig, r.Handler, r.Pattern)
+		}
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid file handler pattern %q: %w", utils.ErrConfig, r.Pattern, err)
+		}
+		handler, err := factory(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: file handler %s for %s: %w", utils.ErrConfig, r.Handler, r.Pattern, err)
+		}
+		set.rules = append(set.rules, rule{pattern: r.Pattern, name: r.Handler, handler: handler})
+	}
+	return set, nil
+}
+
+// Match retourne le handler du premier motif correspondant à filePath: un motif sans "/"
+// s'applique au nom du fichier, un motif avec "/" au chemin complet
+func (s *Set) Match(filePath string) (string, Handler, bool) {
+	if s == nil {
+		return "", nil, false
+	}
+	slashPath := filepath.ToSlash(filePath)
+	for _, r := range s.rules {
+		target := path.Base(slashPath)
+		if strings.Contains(r.pattern, "/") {
+			target = slashPath
+		}
+		if matched, _ := path.Match(r.pattern, target); matched {
+			return r.name, r.handler, true
+		}
+	}
+	return "", nil, false
+}
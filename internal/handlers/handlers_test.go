@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestSetMatch(t *testing.T) {
+	set, err := NewSet([]utils.FileHandlerRule{
+		{Pattern: "/srv/redis/*.rdb", Handler: "command", Command: "redis-cli --rdb {output}"},
+		{Pattern: "*.db", Handler: "sqlite"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"/var/lib/app/data.db":   "sqlite",
+		"/srv/redis/dump.rdb":    "command",
+		"/var/lib/app/dump.rdb":  "",
+		"/var/lib/app/data.db-x": "",
+	}
+	for path, want := range tests {
+		name, _, ok := set.Match(path)
+		if name != want || ok != (want != "") {
+			t.Errorf("%s: handler %q, attendu %q", path, name, want)
+		}
+	}
+
+	var none *Set
+	if _, _, ok := none.Match("/a.db"); ok {
+		t.Error("un ensemble vide ne doit rien traiter")
+	}
+}
+
+func TestNewSetRejectsUnknownHandler(t *testing.T) {
+	_, err := NewSet([]utils.FileHandlerRule{{Pattern: "*.db", Handler: "oracle"}})
+	if !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("erreur de configuration attendue, obtenu %v", err)
+	}
+	_, err = NewSet([]utils.FileHandlerRule{{Pattern: "*.rdb", Handler: "command"}})
+	if !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("commande manquante non détectée: %v", err)
+	}
+}
+
+func TestSQLiteHandlerCopiesDatabase(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "app.db")
+	db, err := sql.Open("sqlite", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("PRAGMA journal_mode=WAL; CREATE TABLE items (name TEXT); INSERT INTO items VALUES ('a'), ('b');"); err != nil {
+		t.Fatal(err)
+	}
+
+	// La base reste ouverte (WAL non fusionné): la copie doit tout de même contenir les lignes
+	output := filepath.Join(dir, "copy.db")
+	if err := (sqliteHandler{}).Prepare(source, output); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := sql.Open("sqlite", output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copied.Close()
+	var count int
+	if err := copied.QueryRow("SELECT COUNT(*) FROM items").Scan(&count); err != nil || count != 2 {
+		t.Errorf("copie incomplète: %d lignes, %v", count, err)
+	}
+}
+
+func TestCommandHandler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commande shell POSIX")
+	}
+	dir := t.TempDir()
+	source := filepath.Join(dir, "dump's.rdb")
+	if err := os.WriteFile(source, []byte("REDIS"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "out.rdb")
+	if err := (commandHandler{command: "cp {path} {output}"}).Prepare(source, output); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "REDIS" {
+		t.Errorf("copie inattendue: %q, %v", data, err)
+	}
+
+	if err := (commandHandler{command: "true"}).Prepare(source, filepath.Join(dir, "missing")); err == nil {
+		t.Error("une commande sans sortie doit échouer")
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"bcrdf/pkg/utils"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register("sqlite", func(rule utils.FileHandlerRule) (Handler, error) {
+		return sqliteHandler{}, nil
+	})
+}
+
+// sqliteHandler copie une base SQLite en cours d'utilisation avec VACUUM INTO: la copie est une
+// image transactionnelle cohérente, journal WAL inclus, sans bloquer les écrivains
+type sqliteHandler struct{}
+
+func (sqliteHandler) Prepare(path, output string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Path:     filepath.ToSlash(abs),
+		RawQuery: "mode=ro&_pragma=busy_timeout(10000)",
+	}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("error opening SQLite database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("VACUUM INTO ?", output); err != nil {
+		return fmt.Errorf("error copying SQLite database: %w", err)
+	}
+	return nil
+}
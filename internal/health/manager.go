@@ -2,23 +2,31 @@ package health
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"bcrdf/internal/index"
+	"bcrdf/internal/restore"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
 )
 
+// testRestoreSampleSize est le nombre de fichiers restaurés par sauvegarde avec --test-restore
+const testRestoreSampleSize = 3
+
 // Manager gère la vérification de santé des sauvegardes
 type Manager struct {
 	config        *utils.Config
 	indexMgr      *index.Manager
 	storageClient storage.Client
+	concurrency   int                // Vérifications en parallèle (0 = max_workers)
+	limiter       *utils.RateLimiter // Débit des téléchargements (restore_rate_limit)
 }
 
 // BackupHealth contient les informations de santé d'une sauvegarde
@@ -35,6 +43,7 @@ type BackupHealth struct {
 	TotalSize    int64
 	MissingFiles []string
 	CorruptFiles []string
+	Origin       *index.BackupOrigin
 }
 
 // HealthReport contient le rapport de santé global
@@ -53,15 +62,21 @@ func NewManager(config *utils.Config, indexMgr *index.Manager, storageClient sto
 		config:        config,
 		indexMgr:      indexMgr,
 		storageClient: storageClient,
+		limiter:       utils.RestoreRateLimiter(config),
 	}
 }
 
+// SetConcurrency définit le nombre de fichiers vérifiés en parallèle (0 = max_workers)
+func (m *Manager) SetConcurrency(concurrency int) {
+	m.concurrency = concurrency
+}
+
 // CheckHealth vérifie la santé de toutes les sauvegardes
 func (m *Manager) CheckHealth(verbose bool, testRestore bool, fastMode bool) (*HealthReport, error) {
 	if verbose {
 		utils.Info("🏥 Starting backup health check...")
 	} else {
-		utils.ProgressStep("🏥 Checking backup health")
+		utils.ProgressStep(utils.Msg("health.step.start"))
 	}
 
 	// Récupérer toutes les sauvegardes
@@ -98,6 +113,9 @@ func (m *Manager) CheckHealth(verbose bool, testRestore bool, fastMode bool) (*H
 		}
 	}
 
+	// Détecter les noms de sauvegarde partagés entre plusieurs machines
+	recommendations = append(recommendations, detectOriginCollisions(healthChecks)...)
+
 	// Calculer les statistiques
 	healthy := 0
 	unhealthy := 0
@@ -171,34 +189,11 @@ type BackupInfo struct {
 
 // parseBackupTimestamp parse la date à partir de l'ID de sauvegarde
 func (m *Manager) parseBackupTimestamp(backupID string) (time.Time, error) {
-	// Format attendu: name-YYYYMMDD-HHMMSS
-	parts := strings.Split(backupID, "-")
-	if len(parts) < 3 {
-		return time.Time{}, fmt.Errorf("invalid backup ID format")
-	}
-
-	// Prendre les 2 dernières parties pour la date
-	datePart := parts[len(parts)-2]
-	timePart := parts[len(parts)-1]
-
-	if len(datePart) != 8 || len(timePart) != 6 {
-		return time.Time{}, fmt.Errorf("invalid date/time format in backup ID")
+	ref, err := index.ParseBackupID(backupID)
+	if err != nil {
+		return time.Time{}, err
 	}
-
-	// Parser la date (YYYYMMDD)
-	year := datePart[:4]
-	month := datePart[4:6]
-	day := datePart[6:8]
-
-	// Parser l'heure (HHMMSS)
-	hour := timePart[:2]
-	minute := timePart[2:4]
-	second := timePart[4:6]
-
-	// Construire la chaîne de date
-	dateStr := fmt.Sprintf("%s-%s-%s %s:%s:%s", year, month, day, hour, minute, second)
-
-	return time.Parse("2006-01-02 15:04:05", dateStr)
+	return ref.CreatedAt, nil
 }
 
 // checkSingleBackup vérifie la santé d'une sauvegarde individuelle
@@ -235,6 +230,7 @@ func (m *Manager) checkSingleBackup(backup BackupInfo, verbose bool, testRestore
 
 	health.FileCount = len(backupIndex.Files)
 	health.TotalSize = backupIndex.TotalSize
+	health.Origin = backupIndex.Origin
 
 	// 3. Vérifier que les fichiers existent dans le stockage
 	filesValid, missingFiles, corruptFiles := m.checkFilesHealth(backupIndex, verbose, fastMode)
@@ -360,6 +356,7 @@ func (m *Manager) downloadWithRetry(key string) ([]byte, error) {
 				if attempt > 0 {
 					utils.Debug("✅ Health check download succeeded on retry attempt %d for %s", attempt+1, key)
 				}
+				m.limiter.Wait(int64(len(result.data)))
 				return result.data, nil
 			}
 
@@ -396,7 +393,17 @@ type downloadResult struct {
 	err  error
 }
 
-// checkFilesHealth vérifie que les fichiers existent dans le stockage
+// fileCheck est le résultat de la vérification d'un fichier
+type fileCheck int
+
+const (
+	fileValid fileCheck = iota
+	fileMissing
+	fileCorrupt
+	fileShared // clé partagée, déjà comptée comme corrompue
+)
+
+// checkFilesHealth vérifie que les fichiers existent dans le stockage (en parallèle)
 func (m *Manager) checkFilesHealth(backupIndex *index.BackupIndex, verbose bool, fastMode bool) (bool, []string, []string) {
 	var missingFiles []string
 	var corruptFiles []string
@@ -414,41 +421,61 @@ func (m *Manager) checkFilesHealth(backupIndex *index.BackupIndex, verbose bool,
 		}
 	}
 
-	for _, file := range filesToCheck {
-		if file.StorageKey == "" {
-			missingFiles = append(missingFiles, file.Path)
-			continue
-		}
+	// Fichiers partageant une clé de stockage (anciens index): un seul contenu a survécu à la sauvegarde
+	sharedKeys := make(map[string]bool)
+	for _, path := range index.DuplicateStorageKeys(backupIndex.Files) {
+		sharedKeys[path] = true
+		corruptFiles = append(corruptFiles, path)
+	}
+	if len(sharedKeys) > 0 && verbose {
+		utils.Warn("%d files share a storage key with another file and may have been overwritten", len(sharedKeys))
+	}
 
-		// Reconstruire la clé complète avec le préfixe data/{backupID}/
-		fullStorageKey := fmt.Sprintf("data/%s/%s", backupIndex.BackupID, file.StorageKey)
+	var progressBar *utils.ProgressBar
+	if !verbose && len(filesToCheck) > 0 {
+		progressBar = utils.NewProgressBar(int64(len(filesToCheck)))
+	}
 
-		// Vérifier d'abord si le fichier principal existe
-		_, err := m.downloadWithRetry(fullStorageKey)
-		if err != nil {
-			missingFiles = append(missingFiles, file.Path)
+	// Les résultats sont indexés par position pour garder l'ordre de l'index dans le rapport
+	results := make([]fileCheck, len(filesToCheck))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for w := 0; w < m.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = m.checkFileHealth(backupIndex.BackupID, filesToCheck[i], verbose)
+				if progressBar != nil {
+					mu.Lock()
+					progressBar.Add(1)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i, file := range filesToCheck {
+		if sharedKeys[file.Path] {
+			results[i] = fileShared
 			continue
 		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	if progressBar != nil {
+		progressBar.Finish()
+	}
 
-		// Vérifier si c'est un fichier chunké en essayant de télécharger les métadonnées
-		// Seulement si le fichier principal existe et si c'est un gros fichier
-		if file.Size > 100*1024*1024 { // 100MB - seuil pour les fichiers chunkés
-			metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
-			_, metadataErr := m.downloadWithRetry(metadataKey)
-			if metadataErr == nil {
-				// Fichier chunké, vérifier les chunks
-				if m.checkChunkedFileHealth(fullStorageKey, verbose) {
-					validFiles++
-				} else {
-					corruptFiles = append(corruptFiles, file.Path)
-				}
-			} else {
-				// Fichier standard, déjà vérifié au-dessus
-				validFiles++
-			}
-		} else {
-			// Fichier standard, déjà vérifié au-dessus
+	for i, result := range results {
+		switch result {
+		case fileValid:
 			validFiles++
+		case fileMissing:
+			missingFiles = append(missingFiles, filesToCheck[i].Path)
+		case fileCorrupt:
+			corruptFiles = append(corruptFiles, filesToCheck[i].Path)
 		}
 	}
 
@@ -466,6 +493,64 @@ func (m *Manager) checkFilesHealth(backupIndex *index.BackupIndex, verbose bool,
 	return isValid, missingFiles, corruptFiles
 }
 
+// checkFileHealth vérifie la présence des données d'un fichier (et l'intégrité des chunks)
+func (m *Manager) checkFileHealth(backupID string, file index.FileEntry, verbose bool) fileCheck {
+	// Les fichiers exclus ou illisibles lors de la sauvegarde ne sont pas manquants
+	if file.IsSkipped() || file.IsMetadataOnly() {
+		return fileValid
+	}
+	if file.StorageKey == "" {
+		return fileMissing
+	}
+
+	// Reconstruire la clé complète avec le préfixe data/{backupID}/
+	fullStorageKey := file.DataKey(backupID)
+	metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
+
+	// Vérifier d'abord si le fichier principal existe (un fichier chunké n'a que ses chunks et ses métadonnées)
+	if !m.objectExists(fullStorageKey) {
+		if m.objectExists(metadataKey) {
+			return m.chunkedFileCheck(fullStorageKey, verbose)
+		}
+		return fileMissing
+	}
+
+	// Vérifier si c'est un fichier chunké, seulement pour les gros fichiers
+	if file.Size > 100*1024*1024 && m.objectExists(metadataKey) { // 100MB - seuil pour les fichiers chunkés
+		return m.chunkedFileCheck(fullStorageKey, verbose)
+	}
+	return fileValid
+}
+
+// chunkedFileCheck convertit la vérification d'un fichier chunké en résultat
+func (m *Manager) chunkedFileCheck(fullStorageKey string, verbose bool) fileCheck {
+	if m.checkChunkedFileHealth(fullStorageKey, verbose) {
+		return fileValid
+	}
+	return fileCorrupt
+}
+
+// objectExists vérifie l'existence d'un objet sans le télécharger (HEAD, PROPFIND)
+func (m *Manager) objectExists(key string) bool {
+	exists, err := storage.ObjectExists(m.storageClient, key)
+	if err != nil {
+		utils.Debug("Cannot check %s: %v", key, err)
+	}
+	return exists
+}
+
+// workers retourne le nombre de vérifications en parallèle (--concurrency, sinon max_workers)
+func (m *Manager) workers() int {
+	workers := m.concurrency
+	if workers <= 0 {
+		workers = m.config.Backup.MaxWorkers
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	return workers
+}
+
 // checkChunkedFileHealth vérifie la santé d'un fichier chunké
 func (m *Manager) checkChunkedFileHealth(fullStorageKey string, verbose bool) bool {
 	// Télécharger les métadonnées
@@ -475,23 +560,30 @@ func (m *Manager) checkChunkedFileHealth(fullStorageKey string, verbose bool) bo
 		return false
 	}
 
-	var metadata map[string]interface{}
-	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
-		return false
-	}
-
-	chunks, ok := metadata["chunks"].(float64)
-	if !ok {
+	metadata, err := index.ParseChunkMetadata(metadataBytes)
+	if err != nil {
+		if verbose {
+			utils.Warn("%s: %v", fullStorageKey, err)
+		}
 		return false
 	}
 
-	totalChunks := int(chunks)
-
-	// Vérifier que tous les chunks existent
-	for chunkNum := 0; chunkNum < totalChunks; chunkNum++ {
+	// Vérifier que tous les chunks existent et correspondent à leur checksum (si enregistré)
+	for chunkNum := 0; chunkNum < metadata.Chunks; chunkNum++ {
 		chunkKey := fmt.Sprintf("%s.chunk.%03d", fullStorageKey, chunkNum)
-		_, err := m.storageClient.Download(chunkKey)
+		hasher := sha256.New()
+		n, err := m.storageClient.DownloadStream(context.Background(), chunkKey, hasher)
 		if err != nil {
+			if verbose {
+				utils.Warn("%s: chunk %d missing: %v", fullStorageKey, chunkNum, err)
+			}
+			return false
+		}
+		m.limiter.Wait(n)
+		if err := metadata.VerifyStoredDigest(chunkNum, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+			if verbose {
+				utils.Warn("%s: %v", fullStorageKey, err)
+			}
 			return false
 		}
 	}
@@ -499,61 +591,39 @@ func (m *Manager) checkChunkedFileHealth(fullStorageKey string, verbose bool) bo
 	return true
 }
 
-// testRestoreSample teste la restauration d'un échantillon de fichiers
+// testRestoreSample restaure un échantillon aléatoire de fichiers dans un répertoire temporaire
+// (téléchargement, déchiffrement, décompression) et vérifie leur taille et leur checksum
 func (m *Manager) testRestoreSample(backupIndex *index.BackupIndex, verbose bool) (bool, []string) {
-	var errors []string
-
-	// Prendre un échantillon de 3 fichiers maximum pour le test
-	sampleSize := 3
-	if len(backupIndex.Files) < sampleSize {
-		sampleSize = len(backupIndex.Files)
+	// Seuls les fichiers dont les données ont été sauvegardées sont testés; les données chiffrées
+	// vers des destinataires ne sont lisibles qu'avec l'identité (identity_file)
+	var candidates []index.FileEntry
+	sealed := 0
+	for _, file := range backupIndex.Files {
+		if !file.HasData() {
+			continue
+		}
+		if backupIndex.WrappedDataKey(file) != "" && m.config.Backup.IdentityFile == "" {
+			sealed++
+			continue
+		}
+		candidates = append(candidates, file)
+	}
+	if sealed > 0 {
+		utils.Debug("Test restore skips %d files encrypted to recipients (no identity_file)", sealed)
 	}
 
+	// Prendre un échantillon de 3 fichiers maximum pour le test
+	sample := m.getRandomSample(candidates, testRestoreSampleSize)
 	if verbose {
-		utils.Info("Testing restore of %d sample files", sampleSize)
+		utils.Info("Testing restore of %d sample files", len(sample))
 	}
 
-	for i := 0; i < sampleSize; i++ {
-		file := backupIndex.Files[i]
-
-		// Reconstruire la clé complète avec le préfixe data/{backupID}/
-		fullStorageKey := fmt.Sprintf("data/%s/%s", backupIndex.BackupID, file.StorageKey)
-
-		// Vérifier si c'est un fichier chunké
-		metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
-		_, err := m.storageClient.Download(metadataKey)
-		if err == nil {
-			// Fichier chunké, tester le premier chunk
-			chunkKey := fmt.Sprintf("%s.chunk.000", fullStorageKey)
-			encryptedData, err := m.storageClient.Download(chunkKey)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("Failed to download test chunk for %s: %v", file.Path, err))
-				continue
-			}
-
-			if len(encryptedData) < 32 {
-				errors = append(errors, fmt.Sprintf("Test chunk for %s appears to be too small", file.Path))
-				continue
-			}
-		} else {
-			// Fichier standard, tester directement
-			encryptedData, err := m.storageClient.Download(fullStorageKey)
-			if err != nil {
-				errors = append(errors, fmt.Sprintf("Failed to download test file %s: %v", file.Path, err))
-				continue
-			}
-
-			if len(encryptedData) < 32 {
-				errors = append(errors, fmt.Sprintf("Test file %s appears to be too small", file.Path))
-				continue
-			}
-		}
-
-		if verbose {
-			utils.Info("✅ Test file %s restored successfully", file.Path)
-		}
+	verifier, err := restore.NewVerifier(m.config, m.indexMgr, m.storageClient)
+	if err != nil {
+		return false, []string{fmt.Sprintf("Cannot initialize test restore: %v", err)}
 	}
 
+	errors := verifier.VerifyFiles(backupIndex, sample, verbose)
 	return len(errors) == 0, errors
 }
 
@@ -578,9 +648,12 @@ func (m *Manager) PrintReport(report *HealthReport, verbose bool) {
 			statusIcon = "⚠️"
 		}
 
-		fmt.Printf("%d. %s %s (%s)\n", i+1, statusIcon, backup.ID, backup.Timestamp.Format("2006-01-02 15:04:05"))
+		fmt.Printf("%d. %s %s (%s)\n", i+1, statusIcon, backup.ID, backup.Timestamp.Local().Format("2006-01-02 15:04:05"))
 		fmt.Printf("   Status: %s\n", backup.Status)
 		fmt.Printf("   Files: %d (%.2f MB)\n", backup.FileCount, float64(backup.TotalSize)/1024/1024)
+		if backup.Origin != nil {
+			fmt.Printf("   Host: %s (%s/%s, bcrdf %s)\n", backup.Origin.Hostname, backup.Origin.OS, backup.Origin.Arch, backup.Origin.BCRDFVersion)
+		}
 
 		if len(backup.Errors) > 0 {
 			fmt.Printf("   Errors:\n")
@@ -663,3 +736,38 @@ func (m *Manager) getRandomSample(files []index.FileEntry, sampleSize int) []ind
 
 	return sample
 }
+
+// detectOriginCollisions signale les noms de sauvegarde utilisés depuis plusieurs machines
+func detectOriginCollisions(checks []BackupHealth) []string {
+	hostsByName := make(map[string]map[string]bool)
+	for _, check := range checks {
+		if check.Origin == nil {
+			continue
+		}
+		parts := strings.Split(check.ID, "-")
+		if len(parts) < 3 {
+			continue
+		}
+		name := strings.Join(parts[:len(parts)-2], "-")
+		if hostsByName[name] == nil {
+			hostsByName[name] = make(map[string]bool)
+		}
+		hostsByName[name][check.Origin.Hostname] = true
+	}
+
+	var warnings []string
+	for name, hosts := range hostsByName {
+		if len(hosts) < 2 {
+			continue
+		}
+		var hostList []string
+		for host := range hosts {
+			hostList = append(hostList, host)
+		}
+		sort.Strings(hostList)
+		warnings = append(warnings, fmt.Sprintf("Backup name '%s' is used from several hosts (%s): incremental backups may collide",
+			name, strings.Join(hostList, ", ")))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
@@ -0,0 +1,152 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// AuditPrefix regroupe le journal d'audit des restaurations (backup.restore_audit)
+const AuditPrefix = "audit/restores/"
+
+// Statuts d'une restauration auditée
+const (
+	AuditStatusSuccess = "success"
+	AuditStatusPartial = "partial"
+	AuditStatusFailed  = "failed"
+)
+
+// AuditRecord trace une restauration. Les enregistrements sont chiffrés comme les index et chaînés:
+// PrevHash est l'empreinte SHA256 de l'objet précédent tel que stocké, si bien qu'un enregistrement
+// modifié, supprimé ou inséré après coup rompt la chaîne (bcrdf audit verify).
+type AuditRecord struct {
+	Seq                int       `json:"seq"`
+	Time               time.Time `json:"time"`
+	Operation          string    `json:"operation"` // restore, sync ou export
+	User               string    `json:"user"`
+	Hostname           string    `json:"hostname"`
+	OperationID        string    `json:"operation_id,omitempty"`
+	BackupID           string    `json:"backup_id"`
+	Paths              []string  `json:"paths,omitempty"` // Chemins sélectionnés, vide = toute la sauvegarde
+	Destination        string    `json:"destination"`
+	FilesRestored      int       `json:"files_restored"`
+	FilesKept          int       `json:"files_kept"` // Fichiers déjà présents conservés (politique de conflit)
+	FilesFailed        int       `json:"files_failed"`
+	ChecksumsVerified  int       `json:"checksums_verified"`
+	ChecksumMismatches []string  `json:"checksum_mismatches,omitempty"`
+	Status             string    `json:"status"`
+	Error              string    `json:"error,omitempty"`
+	PrevHash           string    `json:"prev_hash,omitempty"`
+}
+
+// AuditVerification est le résultat de la vérification de la chaîne d'audit
+type AuditVerification struct {
+	Records  int
+	HeadHash string   // Empreinte du dernier enregistrement, à consigner hors du dépôt
+	Problems []string // Enregistrements illisibles ou chaîne rompue
+}
+
+// auditObject est un enregistrement tel que stocké
+type auditObject struct {
+	key  string
+	data []byte
+}
+
+// AppendAudit ajoute un enregistrement au journal d'audit, chaîné au dernier enregistrement
+func (m *Manager) AppendAudit(record *AuditRecord) error {
+	objects, err := m.auditObjects(false)
+	if err != nil {
+		return err
+	}
+	record.Seq = 1
+	record.PrevHash = ""
+	if len(objects) > 0 {
+		last := objects[len(objects)-1].key
+		data, err := m.storageClient.Download(last)
+		if err != nil {
+			return fmt.Errorf("error reading audit record %s: %w", last, err)
+		}
+		var previous AuditRecord
+		if err := m.decodeIndexObject(data, &previous); err != nil {
+			return fmt.Errorf("error reading audit record %s: %w", last, err)
+		}
+		record.Seq = previous.Seq + 1
+		record.PrevHash = sha256Hex(data)
+	}
+
+	key := fmt.Sprintf("%s%08d-%s.json", AuditPrefix, record.Seq, record.Time.UTC().Format("20060102T150405Z"))
+	if err := m.uploadIndexObject(key, record); err != nil {
+		return fmt.Errorf("error saving audit record: %w", err)
+	}
+	return nil
+}
+
+// ListAudit retourne les enregistrements d'audit, du plus ancien au plus récent
+func (m *Manager) ListAudit() ([]AuditRecord, error) {
+	objects, err := m.auditObjects(false)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]AuditRecord, 0, len(objects))
+	for _, object := range objects {
+		var record AuditRecord
+		if err := m.downloadIndexObject(object.key, &record); err != nil {
+			return nil, fmt.Errorf("error reading audit record %s: %w", object.key, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// VerifyAudit relit tout le journal d'audit et vérifie chaque enregistrement et son chaînage
+func (m *Manager) VerifyAudit() (*AuditVerification, error) {
+	objects, err := m.auditObjects(true)
+	if err != nil {
+		return nil, err
+	}
+	result := &AuditVerification{Records: len(objects)}
+	prevHash := ""
+	for i, object := range objects {
+		var record AuditRecord
+		if err := m.decodeIndexObject(object.data, &record); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: unreadable or altered (%v)", object.key, err))
+		} else {
+			if record.PrevHash != prevHash {
+				result.Problems = append(result.Problems, fmt.Sprintf("%s: chain broken (previous record missing or altered)", object.key))
+			}
+			if record.Seq != i+1 {
+				result.Problems = append(result.Problems, fmt.Sprintf("%s: sequence %d, expected %d", object.key, record.Seq, i+1))
+			}
+		}
+		prevHash = sha256Hex(object.data)
+	}
+	result.HeadHash = prevHash
+	return result, nil
+}
+
+// auditObjects liste les objets du journal triés par clé (numéro de séquence), avec leur contenu si withData
+func (m *Manager) auditObjects(withData bool) ([]auditObject, error) {
+	if err := m.ensureStorage(); err != nil {
+		return nil, err
+	}
+	listed, err := m.storageClient.ListObjects(AuditPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing audit log: %w", utils.ErrStorageUnreachable, err)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Key < listed[j].Key })
+
+	objects := make([]auditObject, 0, len(listed))
+	for _, info := range listed {
+		object := auditObject{key: info.Key}
+		if withData {
+			object.data, err = m.storageClient.Download(info.Key)
+			if err != nil {
+				return nil, fmt.Errorf("error reading audit record %s: %w", info.Key, err)
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
@@ -0,0 +1,140 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// LatestBackup désigne la sauvegarde la plus récente à la place d'un ID
+const LatestBackup = "latest"
+
+// BackupIDTimeLayout est le format de l'horodatage ajouté au nom dans les IDs de sauvegarde:
+// ISO 8601 basique en UTC (sans ':', invalide dans les noms de fichiers Windows)
+const BackupIDTimeLayout = "20060102T150405Z"
+
+// legacyBackupIDTimeLayout est l'horodatage en heure locale des IDs créés avant le passage à l'UTC
+const legacyBackupIDTimeLayout = "20060102-150405"
+
+// DefaultBackupIDTemplate est le modèle d'ID de sauvegarde sans backup.id_template
+const DefaultBackupIDTemplate = "{name}-{date}"
+
+// unsafeIDChars remplace dans les valeurs d'un modèle les caractères invalides dans une clé de stockage
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// BackupSeries retourne la partie d'un ID de sauvegarde précédant l'horodatage (modèle déjà validé,
+// terminé par -{date}). Les sauvegardes d'une même série sont comparées et purgées ensemble.
+func BackupSeries(template, name string) string {
+	if template == "" {
+		template = DefaultBackupIDTemplate
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	hostname = strings.Trim(unsafeIDChars.ReplaceAllString(hostname, "-"), "-")
+	return strings.NewReplacer(
+		"{name}", name,
+		"{hostname}", hostname,
+	).Replace(strings.TrimSuffix(template, "-{date}"))
+}
+
+// RenderBackupID construit l'ID d'une sauvegarde créée à createdAt selon le modèle
+func RenderBackupID(template, name string, createdAt time.Time) string {
+	return BackupSeries(template, name) + "-" + createdAt.UTC().Format(BackupIDTimeLayout)
+}
+
+// BackupRef identifie une sauvegarde à partir de son seul ID (sans télécharger l'index)
+type BackupRef struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// ParseBackupID découpe un ID de sauvegarde (format: backup-name-20060102T150405Z, ou
+// backup-name-20060102-150405 en heure locale de la machine pour les sauvegardes antérieures)
+func ParseBackupID(backupID string) (BackupRef, error) {
+	if i := strings.LastIndex(backupID, "-"); i > 0 {
+		if createdAt, err := time.Parse(BackupIDTimeLayout, backupID[i+1:]); err == nil {
+			return BackupRef{ID: backupID, Name: backupID[:i], CreatedAt: createdAt}, nil
+		}
+	}
+
+	parts := strings.Split(backupID, "-")
+	if len(parts) < 3 {
+		return BackupRef{}, fmt.Errorf("invalid backup ID format: %s", backupID)
+	}
+	createdAt, err := time.ParseInLocation(legacyBackupIDTimeLayout, strings.Join(parts[len(parts)-2:], "-"), time.Local)
+	if err != nil {
+		return BackupRef{}, fmt.Errorf("invalid backup ID format: %s", backupID)
+	}
+	return BackupRef{
+		ID:        backupID,
+		Name:      strings.Join(parts[:len(parts)-2], "-"),
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// ListBackupRefs liste les sauvegardes (plus récentes en premier) à partir des clés d'index uniquement
+func (m *Manager) ListBackupRefs() ([]BackupRef, error) {
+	if m.config == nil {
+		config, err := utils.LoadConfig(m.configFile)
+		if err != nil {
+			return nil, err
+		}
+		m.config = config
+	}
+	if m.storageClient == nil {
+		storageClient, err := storage.NewStorageClient(m.config)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing storage client: %w", err)
+		}
+		m.storageClient = storageClient
+	}
+
+	objects, err := m.storageClient.ListObjects("indexes/")
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing indexes: %w", utils.ErrStorageUnreachable, err)
+	}
+
+	var refs []BackupRef
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+		ref, err := ParseBackupID(strings.TrimSuffix(strings.TrimPrefix(obj.Key, "indexes/"), ".json"))
+		if err != nil {
+			utils.Debug("Skipping index with unexpected name: %s", obj.Key)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].CreatedAt.After(refs[j].CreatedAt)
+	})
+	return refs, nil
+}
+
+// LatestBackupID retourne l'ID de la sauvegarde la plus récente (du nom name s'il est donné)
+func (m *Manager) LatestBackupID(name string) (string, error) {
+	refs, err := m.ListBackupRefs()
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if name == "" || ref.Name == name {
+			return ref.ID, nil
+		}
+	}
+	if name != "" {
+		return "", fmt.Errorf("no backup named %s", name)
+	}
+	return "", fmt.Errorf("no backup found")
+}
@@ -0,0 +1,67 @@
+package index
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderBackupIDTemplates(t *testing.T) {
+	createdAt := time.Date(2024, 3, 12, 14, 30, 0, 0, time.FixedZone("CET", 3600))
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("nom d'hôte indisponible: %v", err)
+	}
+	hostname = strings.Trim(unsafeIDChars.ReplaceAllString(hostname, "-"), "-")
+
+	cases := map[string]string{
+		"":                         "web-20240312T133000Z",
+		"{name}-{date}":            "web-20240312T133000Z",
+		"{name}-{hostname}-{date}": "web-" + hostname + "-20240312T133000Z",
+		"prod-{name}-{date}":       "prod-web-20240312T133000Z",
+	}
+	for template, want := range cases {
+		id := RenderBackupID(template, "web", createdAt)
+		if id != want {
+			t.Errorf("modèle %q: ID %s, attendu %s", template, id, want)
+			continue
+		}
+
+		// L'ID se relit: la série est le nom, l'horodatage la date de création
+		ref, err := ParseBackupID(id)
+		if err != nil {
+			t.Fatalf("ID %s illisible: %v", id, err)
+		}
+		if ref.Name != BackupSeries(template, "web") || !ref.CreatedAt.Equal(createdAt) {
+			t.Errorf("ID %s relu comme %+v", id, ref)
+		}
+	}
+}
+
+func TestParseLegacyBackupID(t *testing.T) {
+	// Anciens IDs: heure locale de la machine, sans fuseau
+	ref, err := ParseBackupID("my-web-20240312-143000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 3, 12, 14, 30, 0, 0, time.Local)
+	if ref.Name != "my-web" || !ref.CreatedAt.Equal(want) {
+		t.Errorf("ID relu comme %+v", ref)
+	}
+
+	// Même instant en UTC: les deux formats se comparent en temps absolu
+	utc, err := ParseBackupID("my-web-" + want.UTC().Format(BackupIDTimeLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utc.Name != "my-web" || !utc.CreatedAt.Equal(ref.CreatedAt) {
+		t.Errorf("ID UTC relu comme %+v, attendu %v", utc, ref.CreatedAt)
+	}
+
+	for _, invalid := range []string{"web", "web-2024", "web-20240312T1430Z"} {
+		if _, err := ParseBackupID(invalid); err == nil {
+			t.Errorf("ID invalide accepté: %s", invalid)
+		}
+	}
+}
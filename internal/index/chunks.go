@@ -0,0 +1,91 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ChunkChecksum contient les empreintes SHA256 d'un chunk: données en clair et objet stocké (chiffré)
+type ChunkChecksum struct {
+	Plain  string `json:"plain"`
+	Stored string `json:"stored"`
+}
+
+// ChunkMetadata décrit un fichier sauvegardé en chunks (objet {storageKey}.metadata)
+// Les anciennes sauvegardes n'ont pas de checksums: seules la présence et le nombre de chunks sont vérifiables
+type ChunkMetadata struct {
+	Chunks      int             `json:"chunks"`
+	Size        int64           `json:"size,omitempty"`
+	Compression string          `json:"compression,omitempty"` // gzip ou none, vide = ancienne sauvegarde
+	Checksums   []ChunkChecksum `json:"chunk_checksums,omitempty"`
+}
+
+// NewChunkChecksum calcule les empreintes d'un chunk
+func NewChunkChecksum(plain, stored []byte) ChunkChecksum {
+	return ChunkChecksum{Plain: sha256Hex(plain), Stored: sha256Hex(stored)}
+}
+
+// ParseChunkMetadata décode les métadonnées d'un fichier chunké
+func ParseChunkMetadata(data []byte) (*ChunkMetadata, error) {
+	var metadata ChunkMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("error parsing chunk metadata: %w", err)
+	}
+	if metadata.Chunks <= 0 {
+		return nil, fmt.Errorf("invalid chunk metadata: chunks field not found")
+	}
+	if len(metadata.Checksums) > 0 && len(metadata.Checksums) != metadata.Chunks {
+		return nil, fmt.Errorf("invalid chunk metadata: %d checksums for %d chunks", len(metadata.Checksums), metadata.Chunks)
+	}
+	return &metadata, nil
+}
+
+// IsCompressed indique si les chunks sont compressés (décision enregistrée, sinon celle de l'index)
+func (c *ChunkMetadata) IsCompressed(file FileEntry, legacyCompressed bool) bool {
+	if c.Compression != "" {
+		return c.Compression == CompressionGzip
+	}
+	return file.IsCompressed(legacyCompressed)
+}
+
+// VerifyStored vérifie l'objet stocké d'un chunk (détecte corruption, chunk manquant ou inversé)
+func (c *ChunkMetadata) VerifyStored(chunk int, data []byte) error {
+	return c.VerifyStoredDigest(chunk, sha256Hex(data))
+}
+
+// VerifyStoredDigest vérifie l'empreinte SHA256 (hexadécimale) de l'objet stocké d'un chunk
+// (calculée au fil du téléchargement, sans charger le chunk en mémoire)
+func (c *ChunkMetadata) VerifyStoredDigest(chunk int, digest string) error {
+	if chunk >= len(c.Checksums) {
+		return nil
+	}
+	if digest != c.Checksums[chunk].Stored {
+		return fmt.Errorf("chunk %d checksum mismatch (stored object)", chunk)
+	}
+	return nil
+}
+
+// VerifyPlain vérifie les données en clair d'un chunk après déchiffrement et décompression
+func (c *ChunkMetadata) VerifyPlain(chunk int, data []byte) error {
+	return c.VerifyPlainDigest(chunk, sha256Hex(data))
+}
+
+// VerifyPlainDigest vérifie l'empreinte SHA256 (hexadécimale) des données en clair d'un chunk
+// (calculée pendant la décompression vers le fichier restauré)
+func (c *ChunkMetadata) VerifyPlainDigest(chunk int, digest string) error {
+	if chunk >= len(c.Checksums) {
+		return nil
+	}
+	if digest != c.Checksums[chunk].Plain {
+		return fmt.Errorf("chunk %d checksum mismatch (plaintext)", chunk)
+	}
+	return nil
+}
+
+// sha256Hex retourne l'empreinte SHA256 hexadécimale de données
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compression des index (index_compression), appliquée avant le chiffrement
+const (
+	IndexCompressionGzip = "gzip"
+	IndexCompressionNone = "none"
+)
+
+// indexHeader préfixe les index encodés (en clair, avant chiffrement): magic, version, compression
+// Les anciens index sont du JSON brut, sans en-tête
+var indexHeader = []byte("BCRDFIDX")
+
+const (
+	indexFormatVersion   byte = 1
+	indexCodecNone       byte = 0
+	indexCodecGzip       byte = 1
+	indexHeaderLength         = 10 // magic + version + compression
+	defaultIndexEncoding      = IndexCompressionGzip
+)
+
+// encodeIndexPayload ajoute l'en-tête et compresse le JSON d'un index
+func encodeIndexPayload(data []byte, compression string) ([]byte, error) {
+	if compression == "" {
+		compression = defaultIndexEncoding
+	}
+
+	var buf bytes.Buffer
+	buf.Write(indexHeader)
+	buf.WriteByte(indexFormatVersion)
+
+	switch compression {
+	case IndexCompressionNone:
+		buf.WriteByte(indexCodecNone)
+		buf.Write(data)
+	case IndexCompressionGzip:
+		buf.WriteByte(indexCodecGzip)
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, fmt.Errorf("error compressing index: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("error compressing index: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported index compression: %s", compression)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeIndexPayload retourne le JSON d'un index (avec en-tête ou ancien format brut)
+func decodeIndexPayload(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, indexHeader) {
+		return data, nil
+	}
+	if len(data) < indexHeaderLength {
+		return nil, fmt.Errorf("truncated index header")
+	}
+	if version := data[len(indexHeader)]; version != indexFormatVersion {
+		return nil, fmt.Errorf("unsupported index format version %d", version)
+	}
+
+	payload := data[indexHeaderLength:]
+	switch data[len(indexHeader)+1] {
+	case indexCodecNone:
+		return payload, nil
+	case indexCodecGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing index: %w", err)
+		}
+		defer reader.Close()
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing index: %w", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported index compression codec %d", data[len(indexHeader)+1])
+	}
+}
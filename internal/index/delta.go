@@ -0,0 +1,186 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// IndexBasePrefix contient les index de base des index delta (hors de indexes/, ce ne sont pas des sauvegardes)
+const IndexBasePrefix = "index-bases/"
+
+// maxDeltaRatio déclenche une nouvelle base lorsque le delta dépasse cette fraction des fichiers
+const maxDeltaRatio = 0.5
+
+// indexBase est la liste complète des fichiers à laquelle les index delta se réfèrent
+type indexBase struct {
+	BaseID        string      `json:"base_id"`
+	SourcePath    string      `json:"source_path,omitempty"`    // Racine des chemins relatifs
+	PathsRelative bool        `json:"paths_relative,omitempty"` // Chemins relatifs à SourcePath
+	Files         []FileEntry `json:"files"`
+}
+
+// IndexBaseKey retourne la clé de stockage d'un index de base
+func IndexBaseKey(baseID string) string {
+	return fmt.Sprintf("%s%s.json", IndexBasePrefix, baseID)
+}
+
+// BaseIDFromKey retourne l'identifiant d'un index de base à partir de sa clé
+func BaseIDFromKey(key string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(key, IndexBasePrefix), ".json")
+}
+
+// SaveIndexDelta sauvegarde un index sous forme de delta par rapport à la base de l'index précédent
+// Chaque delta est cumulatif depuis la base: supprimer une sauvegarde ne casse jamais les autres.
+// Sans base exploitable, ou si le delta devient trop gros, une nouvelle base est écrite.
+func (m *Manager) SaveIndexDelta(current, previous *BackupIndex) error {
+	if err := m.ensureStorage(); err != nil {
+		return err
+	}
+	if !m.config.Backup.IndexDeltas {
+		return m.SaveIndex(current)
+	}
+
+	var upserts []FileEntry
+	var removed []string
+	rebase := true
+
+	if previous != nil && previous.BaseID != "" {
+		base, err := m.loadIndexBase(previous.BaseID)
+		if err != nil {
+			utils.Warn("Cannot load base index %s, writing a new base: %v", previous.BaseID, err)
+		} else {
+			upserts, removed = diffIndexBase(base, current.Files)
+			rebase = float64(len(upserts)+len(removed)) > maxDeltaRatio*float64(len(current.Files))
+			if !rebase {
+				current.BaseID = previous.BaseID
+			}
+		}
+	}
+
+	if rebase {
+		base := &indexBase{BaseID: current.BackupID, SourcePath: current.SourcePath, Files: current.Files}
+		stored := *base
+		if files, _, ok := relativePaths(base.Files, nil, base.SourcePath); ok {
+			stored.Files, stored.PathsRelative = files, true
+		}
+		if err := m.uploadIndexObject(IndexBaseKey(base.BaseID), &stored); err != nil {
+			return fmt.Errorf("error saving base index: %w", err)
+		}
+		m.cacheIndexBase(base.BaseID, base.Files)
+		current.BaseID = base.BaseID
+		upserts, removed = nil, nil
+	}
+
+	delta := *current
+	delta.Files = upserts
+	delta.RemovedPaths = removed
+	if delta.Files == nil {
+		delta.Files = []FileEntry{}
+	}
+
+	indexKey := fmt.Sprintf("indexes/%s.json", current.BackupID)
+	if err := m.uploadIndexObject(indexKey, storedIndex(&delta)); err != nil {
+		return err
+	}
+
+	utils.Info("Index saved: %s (delta: %d changed, %d removed, base %s)", indexKey, len(upserts), len(removed), current.BaseID)
+	return nil
+}
+
+// loadIndexBase charge la liste des fichiers d'un index de base (avec cache)
+func (m *Manager) loadIndexBase(baseID string) ([]FileEntry, error) {
+	m.basesMu.Lock()
+	files, ok := m.bases[baseID]
+	m.basesMu.Unlock()
+	if ok {
+		return files, nil
+	}
+
+	var base indexBase
+	if err := m.downloadIndexObject(IndexBaseKey(baseID), &base); err != nil {
+		return nil, err
+	}
+	if base.PathsRelative {
+		absolutePaths(base.Files, nil, base.SourcePath)
+	}
+	m.cacheIndexBase(baseID, base.Files)
+	return base.Files, nil
+}
+
+// cacheIndexBase mémorise les fichiers d'un index de base
+func (m *Manager) cacheIndexBase(baseID string, files []FileEntry) {
+	m.basesMu.Lock()
+	defer m.basesMu.Unlock()
+	if m.bases == nil {
+		m.bases = make(map[string][]FileEntry)
+	}
+	m.bases[baseID] = files
+}
+
+// diffIndexBase calcule les entrées ajoutées/modifiées et les chemins supprimés par rapport à une base
+func diffIndexBase(base, files []FileEntry) ([]FileEntry, []string) {
+	baseMap := make(map[string]FileEntry, len(base))
+	for _, file := range base {
+		baseMap[file.Path] = file
+	}
+
+	var upserts []FileEntry
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file.Path] = true
+		if previous, ok := baseMap[file.Path]; !ok || !sameEntry(previous, file) {
+			upserts = append(upserts, file)
+		}
+	}
+
+	var removed []string
+	for _, file := range base {
+		if !present[file.Path] {
+			removed = append(removed, file.Path)
+		}
+	}
+	return upserts, removed
+}
+
+// mergeIndexDelta reconstruit la liste complète des fichiers à partir d'une base et d'un delta
+func mergeIndexDelta(base, upserts []FileEntry, removed []string) []FileEntry {
+	removedSet := make(map[string]bool, len(removed))
+	for _, path := range removed {
+		removedSet[path] = true
+	}
+	upsertMap := make(map[string]FileEntry, len(upserts))
+	for _, file := range upserts {
+		upsertMap[file.Path] = file
+	}
+
+	files := make([]FileEntry, 0, len(base)+len(upserts))
+	for _, file := range base {
+		if removedSet[file.Path] {
+			continue
+		}
+		if updated, ok := upsertMap[file.Path]; ok {
+			files = append(files, updated)
+			delete(upsertMap, file.Path)
+			continue
+		}
+		files = append(files, file)
+	}
+	for _, file := range upserts {
+		if _, ok := upsertMap[file.Path]; ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// sameEntry compare deux entrées (les dates sont comparées indépendamment du fuseau)
+func sameEntry(a, b FileEntry) bool {
+	if !a.ModifiedTime.Equal(b.ModifiedTime) {
+		return false
+	}
+	a.ModifiedTime, b.ModifiedTime = time.Time{}, time.Time{}
+	return a == b
+}
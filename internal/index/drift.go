@@ -0,0 +1,81 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DriftReport compare une sauvegarde à l'état actuel de sa source (chemins relatifs à la source)
+type DriftReport struct {
+	BackupID    string
+	BackupTime  time.Time
+	SourcePath  string
+	Changed     []string // Modifiés depuis la sauvegarde (taille, date, permissions ou checksum)
+	NotBackedUp []string // Présents dans la source, absents de la sauvegarde (nouveaux ou en échec)
+	Deleted     []string // Sauvegardés, absents de la source
+}
+
+// HasDrift indique si la source a divergé de la sauvegarde
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Changed)+len(r.NotBackedUp)+len(r.Deleted) > 0
+}
+
+// CompareWithSource indexe sourcePath (mêmes exclusions et mode de checksum qu'une sauvegarde)
+// et le compare à la sauvegarde backupID, sans rien envoyer au stockage.
+// La source peut être montée ailleurs qu'au moment de la sauvegarde: les chemins sont comparés
+// relativement à la racine de chaque index.
+func (m *Manager) CompareWithSource(backupID, sourcePath string, verbose bool) (*DriftReport, error) {
+	backupIndex, err := m.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index %s: %w", backupID, err)
+	}
+
+	// Même mode que la sauvegarde pour que les checksums soient comparables
+	current, err := m.CreateIndexWithMode(sourcePath, "verify", backupIndex.EffectiveChecksumMode(), verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error indexing %s: %w", sourcePath, err)
+	}
+
+	report := &DriftReport{BackupID: backupID, BackupTime: backupIndex.CreatedAt, SourcePath: sourcePath}
+
+	backedUp := make(map[string]FileEntry, len(backupIndex.Files))
+	for _, file := range backupIndex.Files {
+		if file.IsSkipped() {
+			continue
+		}
+		backedUp[PathKey(RelativeToSource(file.Path, backupIndex.SourcePath))] = file
+	}
+
+	seen := make(map[string]bool, len(current.Files))
+	for i := range current.Files {
+		file := &current.Files[i]
+		if file.IsSkipped() {
+			continue
+		}
+		relPath := RelativeToSource(file.Path, current.SourcePath)
+		key := PathKey(relPath)
+		seen[key] = true
+
+		previous, ok := backedUp[key]
+		switch {
+		case !ok || previous.Status == FileStatusFailed:
+			report.NotBackedUp = append(report.NotBackedUp, relPath)
+		case file.IsDirectory:
+			// Un répertoire n'a pas de contenu propre: ses fichiers sont comparés individuellement
+		case m.isFileModified(file, &previous, true):
+			report.Changed = append(report.Changed, relPath)
+		}
+	}
+
+	for key, file := range backedUp {
+		if !seen[key] && file.Status != FileStatusFailed {
+			report.Deleted = append(report.Deleted, RelativeToSource(file.Path, backupIndex.SourcePath))
+		}
+	}
+
+	sort.Strings(report.Changed)
+	sort.Strings(report.NotBackedUp)
+	sort.Strings(report.Deleted)
+	return report, nil
+}
@@ -0,0 +1,140 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// HoldPrefix regroupe les gels juridiques (legal hold) enregistrés dans le dépôt
+const HoldPrefix = "holds/"
+
+// LegalHold interdit la suppression des sauvegardes contenant des fichiers correspondant à Pattern.
+// Le motif est relatif à la racine de sauvegarde (syntaxe glob, ** traverse les répertoires);
+// sans '/', il s'applique au nom de fichier à toute profondeur.
+type LegalHold struct {
+	Pattern   string    `json:"pattern"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"` // Machine ayant posé le gel
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HoldKey retourne la clé de stockage du gel d'un motif
+func HoldKey(pattern string) string {
+	sum := sha256.Sum256([]byte(pattern))
+	return HoldPrefix + hex.EncodeToString(sum[:8]) + ".json"
+}
+
+// compileHold traduit le motif d'un gel en expression régulière sur le chemin relatif
+func compileHold(pattern string) (*regexp.Regexp, error) {
+	glob := strings.Trim(pattern, "/")
+	if glob == "" {
+		return nil, fmt.Errorf("empty legal hold pattern")
+	}
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	return regexp.Compile("^" + globToRegexp(glob) + "$")
+}
+
+// AddHold pose un gel sur un motif (un gel existant sur le même motif est remplacé)
+func (m *Manager) AddHold(pattern, reason string) (*LegalHold, error) {
+	if _, err := compileHold(pattern); err != nil {
+		return nil, fmt.Errorf("%w: invalid legal hold pattern %q: %w", utils.ErrConfig, pattern, err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	hold := &LegalHold{Pattern: pattern, Reason: reason, CreatedBy: hostname, CreatedAt: time.Now()}
+	if err := m.uploadIndexObject(HoldKey(pattern), hold); err != nil {
+		return nil, fmt.Errorf("error saving legal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// ListHolds retourne les gels du dépôt, triés par motif
+func (m *Manager) ListHolds() ([]LegalHold, error) {
+	if err := m.ensureStorage(); err != nil {
+		return nil, err
+	}
+	objects, err := m.storageClient.ListObjects(HoldPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing legal holds: %w", utils.ErrStorageUnreachable, err)
+	}
+
+	var holds []LegalHold
+	for _, object := range objects {
+		var hold LegalHold
+		if err := m.downloadIndexObject(object.Key, &hold); err != nil {
+			return nil, fmt.Errorf("error reading legal hold %s: %w", object.Key, err)
+		}
+		holds = append(holds, hold)
+	}
+	sort.Slice(holds, func(i, j int) bool { return holds[i].Pattern < holds[j].Pattern })
+	return holds, nil
+}
+
+// RemoveHold lève le gel d'un motif
+func (m *Manager) RemoveHold(pattern string) error {
+	if err := m.ensureStorage(); err != nil {
+		return err
+	}
+	key := HoldKey(pattern)
+	if _, err := m.storageClient.Stat(key); err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return fmt.Errorf("%w: no legal hold on %q", utils.ErrConfig, pattern)
+		}
+		return fmt.Errorf("error checking legal hold: %w", err)
+	}
+	if err := m.storageClient.DeleteObject(key); err != nil {
+		return fmt.Errorf("error removing legal hold: %w", err)
+	}
+	return nil
+}
+
+// MatchHolds retourne le premier gel couvrant un fichier de la sauvegarde et le chemin concerné
+// (nil si aucun). Les fichiers en échec ou exclus, absents de la sauvegarde, ne comptent pas.
+func MatchHolds(holds []LegalHold, backupIndex *BackupIndex) (*LegalHold, string) {
+	if len(holds) == 0 {
+		return nil, ""
+	}
+	patterns := make([]*regexp.Regexp, len(holds))
+	for i, hold := range holds {
+		patterns[i], _ = compileHold(hold.Pattern) // Motifs validés par AddHold
+	}
+	for _, file := range backupIndex.Files {
+		if file.Status == FileStatusFailed || file.IsSkipped() {
+			continue
+		}
+		relPath := path.Clean(RelativeToSource(file.Path, backupIndex.SourcePath))
+		for i, pattern := range patterns {
+			if pattern != nil && pattern.MatchString(relPath) {
+				return &holds[i], relPath
+			}
+		}
+	}
+	return nil, ""
+}
+
+// CheckLegalHolds refuse la suppression d'une sauvegarde couverte par un gel (utils.ErrLegalHold)
+func (m *Manager) CheckLegalHolds(backupIndex *BackupIndex) error {
+	holds, err := m.ListHolds()
+	if err != nil {
+		return err
+	}
+	if hold, relPath := MatchHolds(holds, backupIndex); hold != nil {
+		return fmt.Errorf("%w: %s contains %s (hold %q)", utils.ErrLegalHold, backupIndex.BackupID, relPath, hold.Pattern)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package index
+
+import "testing"
+
+func TestMatchHolds(t *testing.T) {
+	backupIndex := &BackupIndex{
+		BackupID:   "e2e-20260101-000000",
+		SourcePath: "/srv/data",
+		Files: []FileEntry{
+			{Path: "/srv/data/notes.txt"},
+			{Path: "/srv/data/finance/2025/bilan.xlsx"},
+			{Path: "/srv/data/mail/archive.pst", Status: FileStatusFailed},
+		},
+	}
+
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"finance/**", "finance/2025/bilan.xlsx"},
+		{"/finance/**", "finance/2025/bilan.xlsx"},
+		{"*.xlsx", "finance/2025/bilan.xlsx"}, // Sans '/': nom de fichier à toute profondeur
+		{"finance/*.xlsx", ""},
+		{"*.pst", ""}, // Fichier en échec: absent de la sauvegarde
+		{"notes.txt", "notes.txt"},
+	}
+	for _, c := range cases {
+		hold, relPath := MatchHolds([]LegalHold{{Pattern: c.pattern}}, backupIndex)
+		if relPath != c.want || (hold != nil) != (c.want != "") {
+			t.Errorf("gel %q: obtenu %q, attendu %q", c.pattern, relPath, c.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package index
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// IgnoreFileName est le fichier d'exclusions lu dans chaque répertoire de la source (comme .gitignore)
+const IgnoreFileName = ".bcrdfignore"
+
+// ignorePattern est une ligne d'un fichier .bcrdfignore
+type ignorePattern struct {
+	pattern  *regexp.Regexp
+	negate   bool // !motif: ré-inclut une entrée exclue par un motif précédent
+	dirOnly  bool // motif/: ne s'applique qu'aux répertoires
+	anchored bool // motif contenant un /: relatif au répertoire du fichier, sinon comparé au nom seul
+}
+
+// IgnoreRules applique les fichiers .bcrdfignore rencontrés pendant le parcours d'une source.
+// Les motifs d'un fichier s'appliquent à son répertoire et à tous ses sous-répertoires; le dernier
+// motif qui correspond l'emporte, les fichiers les plus profonds étant lus en dernier.
+// Un répertoire exclu n'est pas parcouru: son contenu ne peut pas être ré-inclus.
+type IgnoreRules struct {
+	root     string
+	patterns map[string][]ignorePattern // Motifs par répertoire, lus à la première consultation
+}
+
+// NewIgnoreRules prépare les exclusions .bcrdfignore de root (nil si backup.honor_ignore_files est désactivé)
+func NewIgnoreRules(root string, config *utils.Config) *IgnoreRules {
+	if config == nil || !config.Backup.HonorIgnoreFiles {
+		return nil
+	}
+	return &IgnoreRules{root: filepath.Clean(root), patterns: make(map[string][]ignorePattern)}
+}
+
+// Ignored indique si path est exclu par un fichier .bcrdfignore de la source
+func (r *IgnoreRules) Ignored(path string, isDir bool) bool {
+	if r == nil {
+		return false
+	}
+	path = filepath.Clean(path)
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	// Répertoires de root au parent de path, dans l'ordre de priorité croissante
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	ignored := false
+	dir := r.root
+	for i := 0; i < len(parts); i++ {
+		relToDir := strings.Join(parts[i:], "/")
+		for _, p := range r.load(dir) {
+			if p.dirOnly && !isDir {
+				continue
+			}
+			target := parts[len(parts)-1]
+			if p.anchored {
+				target = relToDir
+			}
+			if p.pattern.MatchString(target) {
+				ignored = !p.negate
+			}
+		}
+		dir = filepath.Join(dir, parts[i])
+	}
+	return ignored
+}
+
+// load lit (une seule fois) le fichier .bcrdfignore de dir
+func (r *IgnoreRules) load(dir string) []ignorePattern {
+	if patterns, ok := r.patterns[dir]; ok {
+		return patterns
+	}
+	patterns, err := readIgnoreFile(filepath.Join(dir, IgnoreFileName))
+	if err != nil && !os.IsNotExist(err) {
+		utils.Warn("Cannot read %s: %v", filepath.Join(dir, IgnoreFileName), err)
+	}
+	r.patterns[dir] = patterns
+	return patterns
+}
+
+// readIgnoreFile lit un fichier .bcrdfignore (lignes vides et commentaires # ignorés)
+func readIgnoreFile(path string) ([]ignorePattern, error) {
+	file, err := os.Open(utils.LongPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if p, ok := parseIgnoreLine(scanner.Text()); ok {
+			patterns = append(patterns, p)
+		} else if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			utils.Warn("Invalid pattern in %s: %q", path, line)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// parseIgnoreLine convertit une ligne en motif (syntaxe .gitignore: *, ?, [...], **, !, / final ou initial)
+func parseIgnoreLine(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:] // \! et \# désignent les caractères eux-mêmes
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.pattern = pattern
+	return p, true
+}
+
+// globToRegexp traduit un motif glob en expression régulière (** traverse les répertoires)
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestIgnoreRulesPerDirectory(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".bcrdfignore":             "# exclusions globales\n*.tmp\n/build/\ncache/\n",
+		"app/.bcrdfignore":         "*.log\n!keep.log\ndata/**/*.bak\n",
+		"app/run.log":              "x",
+		"app/keep.log":             "x",
+		"app/data/a/old.bak":       "x",
+		"app/data/a/new.db":        "x",
+		"app/cache/blob":           "x",
+		"app/build/out.bin":        "x",
+		"build/out.bin":            "x",
+		"notes.tmp":                "x",
+		"notes.txt":                "x",
+		"other/run.log":            "x",
+		"other/.bcrdfignore":       "!*.tmp\n",
+		"other/draft.tmp":          "x",
+		"other/deep/[literal].txt": "x",
+	}
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &utils.Config{}
+	config.Backup.HonorIgnoreFiles = true
+	rules := NewIgnoreRules(root, config)
+
+	var kept []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if rules.Ignored(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			kept = append(kept, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(kept)
+
+	// /build/ ne vise que la racine, cache/ tous les niveaux; les fichiers .bcrdfignore sont sauvegardés
+	want := []string{
+		".bcrdfignore",
+		"app/.bcrdfignore",
+		"app/build/out.bin",
+		"app/data/a/new.db",
+		"app/keep.log",
+		"notes.txt",
+		"other/.bcrdfignore",
+		"other/deep/[literal].txt",
+		"other/draft.tmp",
+		"other/run.log",
+	}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("fichiers conservés:\n%v\nattendu:\n%v", kept, want)
+	}
+
+	// Désactivé par la configuration: aucune règle
+	config.Backup.HonorIgnoreFiles = false
+	if NewIgnoreRules(root, config).Ignored(filepath.Join(root, "notes.tmp"), false) {
+		t.Error("honor_ignore_files: false doit ignorer les fichiers .bcrdfignore")
+	}
+}
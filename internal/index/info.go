@@ -0,0 +1,193 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BackupInfo décrit une sauvegarde (bcrdf info <backup-id>): son index, sa place dans sa série
+// et le gel qui la protège éventuellement
+type BackupInfo struct {
+	Index    *BackupIndex
+	Name     string      // Série de la sauvegarde (ID sans l'horodatage)
+	Position int         // Rang dans la série, 1 = la plus ancienne
+	Chain    int         // Nombre de sauvegardes de la série
+	Previous string      // Sauvegarde précédente de la série, vide pour la première
+	Next     string      // Sauvegarde suivante de la série, vide pour la dernière
+	Hold     *LegalHold  // Gel couvrant un fichier de la sauvegarde, nil sinon
+	HoldPath string      // Fichier couvert par le gel
+	Files    FileSummary // Répartition des entrées de l'index
+}
+
+// FileSummary compte les entrées d'un index par nature
+type FileSummary struct {
+	Files       int // Fichiers avec données
+	Directories int
+	Symlinks    int
+	Empty       int // Fichiers vides (sans objet stocké)
+	Failed      int // Fichiers en échec lors de la sauvegarde
+	Skipped     int // Fichiers exclus ou illisibles
+	Largest     string
+	LargestSize int64
+}
+
+// LoadBackupInfo charge l'index d'une sauvegarde et la situe dans sa série
+func (m *Manager) LoadBackupInfo(backupID string) (*BackupInfo, error) {
+	backupIndex, err := m.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index %s: %w", backupID, err)
+	}
+	info := &BackupInfo{Index: backupIndex, Files: SummarizeFiles(backupIndex)}
+
+	if ref, err := ParseBackupID(backupID); err == nil {
+		info.Name = ref.Name
+		refs, err := m.ListBackupRefs()
+		if err != nil {
+			return nil, err
+		}
+		info.Position, info.Chain, info.Previous, info.Next = chainPosition(refs, ref)
+	}
+
+	holds, err := m.ListHolds()
+	if err != nil {
+		return nil, err
+	}
+	info.Hold, info.HoldPath = MatchHolds(holds, backupIndex)
+	return info, nil
+}
+
+// SummarizeFiles compte les entrées d'un index par nature et retient le plus gros fichier
+func SummarizeFiles(backupIndex *BackupIndex) FileSummary {
+	var summary FileSummary
+	for _, file := range backupIndex.Files {
+		switch {
+		case file.IsSkipped():
+			summary.Skipped++
+		case file.Status == FileStatusFailed:
+			summary.Failed++
+		case file.IsDirectory:
+			summary.Directories++
+		case file.IsSymlink():
+			summary.Symlinks++
+		case file.IsMetadataOnly():
+			summary.Empty++
+		default:
+			summary.Files++
+			if file.Size > summary.LargestSize {
+				summary.Largest, summary.LargestSize = RelativeToSource(file.Path, backupIndex.SourcePath), file.Size
+			}
+		}
+	}
+	return summary
+}
+
+// chainPosition situe une sauvegarde parmi celles de sa série (refs triées de la plus récente
+// à la plus ancienne): rang depuis la plus ancienne, taille de la série, voisines
+func chainPosition(refs []BackupRef, current BackupRef) (position, chain int, previous, next string) {
+	var series []BackupRef
+	for _, ref := range refs {
+		if ref.Name == current.Name {
+			series = append(series, ref)
+		}
+	}
+	chain = len(series)
+	for i, ref := range series {
+		if ref.ID != current.ID {
+			continue
+		}
+		position = chain - i
+		if i+1 < chain {
+			previous = series[i+1].ID
+		}
+		if i > 0 {
+			next = series[i-1].ID
+		}
+	}
+	return position, chain, previous, next
+}
+
+// PrintBackupInfo affiche les métadonnées d'une sauvegarde
+func PrintBackupInfo(info *BackupInfo) {
+	backupIndex := info.Index
+	fmt.Printf("\n📋 Backup %s\n", backupIndex.BackupID)
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Created: %s (%s ago)\n", backupIndex.CreatedAt.Local().Format("2006-01-02 15:04:05"), time.Since(backupIndex.CreatedAt).Round(time.Minute))
+	fmt.Printf("Status: %s\n", backupIndex.EffectiveStatus())
+
+	fmt.Printf("\n📁 Source\n")
+	fmt.Printf("  Path: %s\n", backupIndex.SourcePath)
+	if len(backupIndex.SourceRoots) > 0 {
+		fmt.Printf("  Sources: %s\n", strings.Join(backupIndex.SourceRoots, ", "))
+	}
+	if backupIndex.ImportedFrom != "" {
+		fmt.Printf("  Imported from: %s\n", backupIndex.ImportedFrom)
+	}
+	if origin := backupIndex.Origin; origin != nil {
+		fmt.Printf("  Host: %s (%s/%s)\n", origin.Hostname, origin.OS, origin.Arch)
+		if origin.SourceDevice != "" {
+			fmt.Printf("  Source device: %s\n", origin.SourceDevice)
+		}
+	}
+
+	fmt.Printf("\n🔖 Versions\n")
+	if origin := backupIndex.Origin; origin != nil {
+		fmt.Printf("  BCRDF version: %s\n", origin.BCRDFVersion)
+		if origin.ConfigHash != "" {
+			fmt.Printf("  Config hash: %s\n", origin.ConfigHash)
+		}
+		if origin.OperationID != "" {
+			fmt.Printf("  Operation ID: %s\n", origin.OperationID)
+		}
+	}
+	keyScheme := backupIndex.KeyScheme
+	if keyScheme == "" {
+		keyScheme = "legacy"
+	}
+	fmt.Printf("  Storage key scheme: %s\n", keyScheme)
+	if mode := backupIndex.EffectiveChecksumMode(); ReducedSafety(mode) {
+		fmt.Printf("  Checksum mode: %s (reduced safety)\n", mode)
+	} else {
+		fmt.Printf("  Checksum mode: %s\n", mode)
+	}
+
+	files := info.Files
+	fmt.Printf("\n📊 Statistics\n")
+	fmt.Printf("  Files: %d (%d with data, %d empty, %d symlinks), %d directories\n",
+		backupIndex.TotalFiles, files.Files, files.Empty, files.Symlinks, files.Directories)
+	if files.Failed > 0 || files.Skipped > 0 {
+		fmt.Printf("  Not backed up: %d failed, %d skipped\n", files.Failed, files.Skipped)
+	}
+	fmt.Printf("  Total size: %.1f MB\n", float64(backupIndex.TotalSize)/(1024*1024))
+	if backupIndex.CompressedSize > 0 {
+		fmt.Printf("  Compressed size: %.1f MB\n", float64(backupIndex.CompressedSize)/(1024*1024))
+	}
+	if backupIndex.EncryptedSize > 0 {
+		fmt.Printf("  Encrypted size: %.1f MB\n", float64(backupIndex.EncryptedSize)/(1024*1024))
+	}
+	if files.Largest != "" {
+		fmt.Printf("  Largest file: %s (%.1f MB)\n", files.Largest, float64(files.LargestSize)/(1024*1024))
+	}
+	if requests := backupIndex.Requests; requests != nil {
+		fmt.Printf("  Storage requests: %d (%d PUT, %d GET, %d LIST, %d HEAD, %d DELETE)\n",
+			requests.Total(), requests.Put, requests.Get, requests.List, requests.Head, requests.Delete)
+	}
+
+	fmt.Printf("\n🔗 Chain\n")
+	if info.Name != "" {
+		fmt.Printf("  Series: %s (backup %d of %d)\n", info.Name, info.Position, info.Chain)
+	}
+	if info.Previous != "" {
+		fmt.Printf("  Previous: %s\n", info.Previous)
+	}
+	if info.Next != "" {
+		fmt.Printf("  Next: %s\n", info.Next)
+	}
+	if backupIndex.BaseID != "" {
+		fmt.Printf("  Index base: %s\n", backupIndex.BaseID)
+	}
+	if info.Hold != nil {
+		fmt.Printf("  Legal hold: %s (covers %s), delete and retention blocked\n", info.Hold.Pattern, info.HoldPath)
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,45 @@
+package index
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeFiles(t *testing.T) {
+	backupIndex := &BackupIndex{
+		SourcePath: "/srv/data",
+		Files: []FileEntry{
+			{Path: "/srv/data/docs", IsDirectory: true},
+			{Path: "/srv/data/docs/report.odt", Size: 2048, StorageKey: "a"},
+			{Path: "/srv/data/notes.txt", Size: 10, StorageKey: "b"},
+			{Path: "/srv/data/empty.txt"},
+			{Path: "/srv/data/current", LinkTarget: "docs"},
+			{Path: "/srv/data/mail.pst", Size: 4096, Status: FileStatusFailed},
+			{Path: "/srv/data/cache.tmp", Status: FileStatusSkippedExcluded},
+		},
+	}
+
+	summary := SummarizeFiles(backupIndex)
+	want := FileSummary{Files: 2, Directories: 1, Symlinks: 1, Empty: 1, Failed: 1, Skipped: 1, Largest: "docs/report.odt", LargestSize: 2048}
+	if summary != want {
+		t.Errorf("résumé %+v, attendu %+v", summary, want)
+	}
+}
+
+func TestChainPosition(t *testing.T) {
+	ref := func(name string, day int) BackupRef {
+		createdAt := time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC)
+		return BackupRef{ID: name + "-" + createdAt.Format(BackupIDTimeLayout), Name: name, CreatedAt: createdAt}
+	}
+	// Triées de la plus récente à la plus ancienne, comme ListBackupRefs
+	refs := []BackupRef{ref("web", 4), ref("db", 3), ref("web", 2), ref("web", 1)}
+
+	position, chain, previous, next := chainPosition(refs, refs[2])
+	if position != 2 || chain != 3 || previous != refs[3].ID || next != refs[0].ID {
+		t.Errorf("web du 2: rang %d/%d, précédente %q, suivante %q", position, chain, previous, next)
+	}
+	position, chain, previous, next = chainPosition(refs, refs[1])
+	if position != 1 || chain != 1 || previous != "" || next != "" {
+		t.Errorf("db seule: rang %d/%d, précédente %q, suivante %q", position, chain, previous, next)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"bcrdf/internal/crypto"
+	"bcrdf/internal/parity"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
 )
@@ -21,6 +23,59 @@ type Manager struct {
 	storageClient storage.Client
 	checksumCache *ChecksumCache
 	encryptor     *crypto.EncryptorV2
+	// bases met en cache les index de base déjà chargés (index deltas)
+	bases   map[string][]FileEntry
+	basesMu sync.Mutex
+	// checksumStore est le cache persistant des checksums (base d'état locale, optionnel)
+	checksumStore ChecksumStore
+	// pendingChecksums sont les checksums calculés pendant le parcours, mis en cache à la fin
+	pendingChecksums []CachedChecksum
+	// oneFileSystem force le parcours à rester sur le système de fichiers de la source (--one-file-system)
+	oneFileSystem bool
+	// symlinks surcharge backup.symlinks (--symlinks)
+	symlinks string
+	// excludedPaths sont les fichiers locaux de bcrdf (état, caches, zones temporaires) à ne pas parcourir
+	excludedPaths map[string]bool
+}
+
+// ChecksumStore est un cache persistant des checksums, indexé par chemin, mode, taille et date de modification
+type ChecksumStore interface {
+	LookupChecksum(path, mode string, size int64, modTime time.Time) (string, bool)
+	StoreChecksums(checksums []CachedChecksum) error
+}
+
+// CachedChecksum est un checksum à mettre en cache
+type CachedChecksum struct {
+	Path     string
+	Mode     string
+	Size     int64
+	ModTime  time.Time
+	Checksum string
+}
+
+// SetChecksumStore active le cache persistant des checksums (mode fast uniquement: le mode full
+// relit toujours le contenu, c'est sa garantie)
+func (m *Manager) SetChecksumStore(store ChecksumStore) {
+	m.checksumStore = store
+}
+
+// SetOneFileSystem empêche le parcours de descendre dans d'autres points de montage,
+// en plus de backup.one_file_system
+func (m *Manager) SetOneFileSystem(enabled bool) {
+	m.oneFileSystem = enabled
+}
+
+// SetExcludedPaths exclut du parcours des chemins absolus, et tout ce qu'ils contiennent
+func (m *Manager) SetExcludedPaths(paths []string) {
+	m.excludedPaths = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		m.excludedPaths[filepath.Clean(path)] = true
+	}
+}
+
+// SetSymlinkPolicy surcharge backup.symlinks (store, follow ou skip)
+func (m *Manager) SetSymlinkPolicy(policy string) {
+	m.symlinks = policy
 }
 
 // NewManager crée un nouveau gestionnaire d'index
@@ -31,6 +86,16 @@ func NewManager(configFile string) *Manager {
 	}
 }
 
+// NewManagerWithClient crée un gestionnaire d'index sur une configuration et un client déjà prêts
+// (partage pré-signé: aucun fichier de configuration ni identifiant de stockage)
+func NewManagerWithClient(config *utils.Config, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		storageClient: storageClient,
+		checksumCache: NewChecksumCache(),
+	}
+}
+
 // initializeEncryptor initialise le chiffreur si nécessaire
 func (m *Manager) initializeEncryptor() error {
 	if m.encryptor != nil {
@@ -54,8 +119,9 @@ func (m *Manager) initializeEncryptor() error {
 
 	encryptor, err := crypto.NewEncryptorV2(m.config.Backup.EncryptionKey, algorithm)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du chiffreur pour les index: %w", err)
+		return fmt.Errorf("error initializing index encryptor: %w", err)
 	}
+	encryptor.SetAllowUnencrypted(m.config.Backup.AllowUnencrypted)
 	m.encryptor = encryptor
 
 	return nil
@@ -63,37 +129,67 @@ func (m *Manager) initializeEncryptor() error {
 
 // CreateIndex crée un nouvel index pour un répertoire
 func (m *Manager) CreateIndex(sourcePath, backupID string, verbose bool) (*BackupIndex, error) {
-	return m.CreateIndexWithMode(sourcePath, backupID, "fast", verbose)
+	return m.CreateIndexWithMode(sourcePath, backupID, ChecksumModeFast, verbose)
 }
 
 // CreateIndexWithMode crée un nouvel index avec un mode de checksum spécifique
 func (m *Manager) CreateIndexWithMode(sourcePath, backupID, checksumMode string, verbose bool) (*BackupIndex, error) {
+	return m.CreateIndexFromSources([]string{sourcePath}, backupID, checksumMode, verbose)
+}
+
+// CreateIndexFromSources crée un index fusionnant plusieurs sources. Avec plusieurs sources,
+// SourcePath est leur parent commun (les chemins restaurés gardent le nom de chaque source)
+// et SourceRoots les enregistre.
+func (m *Manager) CreateIndexFromSources(sources []string, backupID, checksumMode string, verbose bool) (*BackupIndex, error) {
+	sourcePath := sources[0]
+	if len(sources) > 1 {
+		sourcePath = CommonRoot(sources)
+	}
 	if verbose {
-		utils.Info("Creating index for: %s (mode: %s)", sourcePath, checksumMode)
+		utils.Info("Creating index for: %s (mode: %s)", strings.Join(sources, ", "), checksumMode)
 	}
 
 	index := m.initializeIndex(backupID, sourcePath)
+	if len(sources) > 1 {
+		index.SourceRoots = sources
+	}
+	index.ChecksumMode = checksumMode
+	startTime := time.Now()
 
-	fileCount, err := m.countFiles(sourcePath, checksumMode, verbose)
-	if err != nil {
-		return nil, err
+	// Un seul parcours: la progression affiche le débit au lieu d'un pourcentage
+	var progress *utils.ScanProgress
+	if !verbose {
+		utils.ProgressStep(scanDescription(checksumMode))
+		progress = utils.NewScanProgress()
 	}
 
-	progressBar := m.setupProgressBar(verbose, fileCount)
+	defer m.flushChecksums()
+	for _, source := range sources {
+		if err := m.processFiles(source, checksumMode, verbose, index, progress); err != nil {
+			return nil, err
+		}
+	}
 
-	err = m.processFiles(sourcePath, checksumMode, verbose, index, progressBar)
-	if err != nil {
-		return nil, err
+	if progress != nil {
+		progress.Finish()
 	}
 
-	// Terminer la barre de progression
-	if !verbose && progressBar != nil {
-		progressBar.Finish()
+	// Enregistrer l'origine de la sauvegarde (machine, version, source)
+	index.Origin = CollectOrigin(sources[0], m.config)
+	index.KeyScheme = KeySchemeUUID
+
+	// Protection contre les collisions: aucun objet ne doit en écraser un autre dans la sauvegarde
+	if rekeyed := EnsureUniqueStorageKeys(index.Files); len(rekeyed) > 0 {
+		utils.Warn("Storage key collision detected, %d files re-keyed", len(rekeyed))
 	}
 
 	if verbose {
 		utils.Info("Index created with %d files, total size: %d bytes",
 			index.TotalFiles, index.TotalSize)
+		if elapsed := time.Since(startTime).Seconds(); elapsed > 0 {
+			utils.Info("Indexing rate: %.0f files/s, %s/s",
+				float64(index.TotalFiles)/elapsed, formatBytes(int64(float64(index.TotalSize)/elapsed)))
+		}
 
 		// Show cache statistics
 		stats := m.checksumCache.GetStats()
@@ -103,89 +199,123 @@ func (m *Manager) CreateIndexWithMode(sourcePath, backupID, checksumMode string,
 				stats.Hits, stats.Misses, hitRate)
 		}
 	} else {
-		utils.ProgressDone(fmt.Sprintf("Index created with %d files", index.TotalFiles))
+		utils.ProgressDone(utils.Msg("scan.done", index.TotalFiles))
 	}
 
 	return index, nil
 }
 
 // LoadIndex charge un index depuis le stockage
+// Un index delta (base_id) est fusionné avec sa base: l'appelant reçoit toujours la liste complète des fichiers
 func (m *Manager) LoadIndex(backupID string) (*BackupIndex, error) {
+	var index BackupIndex
+	if err := m.downloadIndexObject(fmt.Sprintf("indexes/%s.json", backupID), &index); err != nil {
+		return nil, err
+	}
+	if index.PathsRelative {
+		absolutePaths(index.Files, index.RemovedPaths, index.SourcePath)
+		index.PathsRelative = false
+	}
+
+	if index.BaseID != "" {
+		base, err := m.loadIndexBase(index.BaseID)
+		if err != nil {
+			return nil, fmt.Errorf("error loading base index %s for %s: %w", index.BaseID, backupID, err)
+		}
+		index.Files = mergeIndexDelta(base, index.Files, index.RemovedPaths)
+		index.RemovedPaths = nil
+	}
+
+	return &index, nil
+}
+
+// SaveIndex sauvegarde un index complet
+func (m *Manager) SaveIndex(index *BackupIndex) error {
+	indexKey := fmt.Sprintf("indexes/%s.json", index.BackupID)
+	if err := m.uploadIndexObject(indexKey, storedIndex(index)); err != nil {
+		return err
+	}
+
+	utils.Info("Index saved: %s", indexKey)
+	return nil
+}
+
+// ensureStorage charge la configuration, le client de stockage et le chiffreur si nécessaire
+func (m *Manager) ensureStorage() error {
 	// Charger la configuration si nécessaire
 	if m.config == nil {
 		config, err := utils.LoadConfig(m.configFile)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		m.config = config
 	}
 
-	// Initialiser le client S3 si nécessaire
+	// Initialiser le client de stockage si nécessaire
 	if m.storageClient == nil {
 		storageClient, err := storage.NewStorageClient(m.config)
 		if err != nil {
-			return nil, fmt.Errorf("error initializing storage client: %w", err)
+			return fmt.Errorf("error initializing storage client: %w", err)
 		}
 		m.storageClient = storageClient
 	}
 
-	// Construire le chemin de l'index
-	indexKey := fmt.Sprintf("indexes/%s.json", backupID)
+	// Initialiser le chiffreur si nécessaire
+	if err := m.initializeEncryptor(); err != nil {
+		return fmt.Errorf("error initializing index encryptor: %w", err)
+	}
+	return nil
+}
 
-	// Charger depuis S3
-	data, err := m.storageClient.Download(indexKey)
-	if err != nil {
-		return nil, fmt.Errorf("error loading index: %w", err)
+// downloadIndexObject télécharge, déchiffre, décompresse et décode un objet d'index
+func (m *Manager) downloadIndexObject(key string, v interface{}) error {
+	if err := m.ensureStorage(); err != nil {
+		return err
 	}
 
-	// Initialiser le chiffreur si nécessaire
-	if err := m.initializeEncryptor(); err != nil {
-		return nil, fmt.Errorf("error initializing encryptor for index loading: %w", err)
+	data, err := m.storageClient.Download(key)
+	if err != nil {
+		return fmt.Errorf("error loading index: %w", err)
 	}
+	return m.decodeIndexObject(data, v)
+}
 
+// decodeIndexObject déchiffre, décompresse et décode un objet d'index déjà téléchargé
+func (m *Manager) decodeIndexObject(data []byte, v interface{}) error {
 	// Déchiffrer les données
 	decryptedData, err := m.encryptor.Decrypt(data)
 	if err != nil {
-		return nil, fmt.Errorf("error decrypting index: %w", err)
+		return fmt.Errorf("error decrypting index: %w", err)
 	}
 
-	var index BackupIndex
-	if err := json.Unmarshal(decryptedData, &index); err != nil {
-		return nil, fmt.Errorf("error decoding index: %w", err)
+	// Retirer l'en-tête et décompresser (les anciens index sont du JSON brut)
+	decodedData, err := decodeIndexPayload(decryptedData)
+	if err != nil {
+		return fmt.Errorf("error decoding index: %w", err)
 	}
 
-	return &index, nil
+	if err := json.Unmarshal(decodedData, v); err != nil {
+		return fmt.Errorf("error decoding index: %w", err)
+	}
+	return nil
 }
 
-// SaveIndex sauvegarde un index
-func (m *Manager) SaveIndex(index *BackupIndex) error {
-	// Charger la configuration si nécessaire
-	if m.config == nil {
-		config, err := utils.LoadConfig(m.configFile)
-		if err != nil {
-			return err
-		}
-		m.config = config
-	}
-
-	// Initialiser le client de stockage si nécessaire
-	if m.storageClient == nil {
-		storageClient, err := storage.NewStorageClient(m.config)
-		if err != nil {
-			return fmt.Errorf("error initializing storage client: %w", err)
-		}
-		m.storageClient = storageClient
+// uploadIndexObject sérialise, compresse, chiffre et envoie un objet d'index
+func (m *Manager) uploadIndexObject(key string, v interface{}) error {
+	if err := m.ensureStorage(); err != nil {
+		return err
 	}
 
 	// Sérialiser l'index
-	data, err := json.MarshalIndent(index, "", "  ")
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error serializing index: %w", err)
 	}
 
-	// Initialiser le chiffreur si nécessaire
-	if err := m.initializeEncryptor(); err != nil {
-		return fmt.Errorf("error initializing encryptor for index saving: %w", err)
+	// Compresser avant chiffrement (en-tête indiquant la compression)
+	data, err = encodeIndexPayload(data, m.config.Backup.IndexCompression)
+	if err != nil {
+		return err
 	}
 
 	// Chiffrer les données
@@ -194,19 +324,15 @@ func (m *Manager) SaveIndex(index *BackupIndex) error {
 		return fmt.Errorf("error encrypting index: %w", err)
 	}
 
-	// Sauvegarder dans S3
-	indexKey := fmt.Sprintf("indexes/%s.json", index.BackupID)
-	if err := m.storageClient.Upload(indexKey, encryptedData); err != nil {
+	if err := m.storageClient.Upload(key, encryptedData); err != nil {
 		return fmt.Errorf("error saving index: %w", err)
 	}
-
-	utils.Info("Index saved: %s", indexKey)
 	return nil
 }
 
 // CompareIndexes compare deux index et retourne les différences
 func (m *Manager) CompareIndexes(current, previous *BackupIndex) (*IndexDiff, error) {
-	utils.Info("Comparaison des index: %s vs %s", current.BackupID, previous.BackupID)
+	utils.Info("Comparing indexes: %s vs %s", current.BackupID, previous.BackupID)
 
 	diff := &IndexDiff{
 		Added:    []FileEntry{},
@@ -218,26 +344,49 @@ func (m *Manager) CompareIndexes(current, previous *BackupIndex) (*IndexDiff, er
 	currentMap := make(map[string]FileEntry)
 	previousMap := make(map[string]FileEntry)
 
-	for _, file := range current.Files {
-		currentMap[file.Path] = file
+	currentPos := make(map[string]int)
+
+	// Clés normalisées (NFC): un changement de normalisation n'est pas une modification
+	for i, file := range current.Files {
+		currentMap[PathKey(file.Path)] = file
+		currentPos[PathKey(file.Path)] = i
 	}
 
 	for _, file := range previous.Files {
-		previousMap[file.Path] = file
+		previousMap[PathKey(file.Path)] = file
+	}
+
+	// Checksums de modes différents (changement de checksum_mode): taille, date et permissions seules
+	sameMode := current.EffectiveChecksumMode() == previous.EffectiveChecksumMode()
+	if !sameMode {
+		utils.Info("Checksum mode changed (%s -> %s): comparing size, modification time and permissions only",
+			previous.EffectiveChecksumMode(), current.EffectiveChecksumMode())
 	}
 
 	// Trouver les fichiers ajoutés et modifiés
 	for path, currentFile := range currentMap {
+		// Les fichiers ignorés ne sont jamais envoyés
+		if currentFile.IsSkipped() {
+			continue
+		}
+
 		if previousFile, exists := previousMap[path]; !exists {
 			// Nouveau fichier
 			diff.Added = append(diff.Added, currentFile)
 			utils.Debug("📁 Added: %s", path)
 		} else {
 			// Vérifier si le fichier a été modifié
-			if m.isFileModified(&currentFile, &previousFile) {
+			if m.isFileModified(&currentFile, &previousFile, sameMode) {
 				diff.Modified = append(diff.Modified, currentFile)
 				utils.Debug("🔄 Modified: %s", path)
 			} else {
+				// Un fichier inchangé n'est pas renvoyé: l'entrée pointe vers les objets de la sauvegarde
+				// qui les détient (data/{DataBackupID}/{storageKey})
+				entry := &current.Files[currentPos[path]]
+				entry.StorageKey = previousFile.StorageKey
+				entry.DataBackupID = previousFile.DataOwner(previous.BackupID)
+				entry.Compression = previousFile.Compression
+				entry.Entropy = previousFile.Entropy
 				utils.Debug("✅ Unchanged: %s", path)
 			}
 		}
@@ -259,7 +408,14 @@ func (m *Manager) CompareIndexes(current, previous *BackupIndex) (*IndexDiff, er
 }
 
 // isFileModified détermine si un fichier a été modifié avec une logique améliorée
-func (m *Manager) isFileModified(current, previous *FileEntry) bool {
+// (compareChecksums = false quand les deux entrées n'ont pas le même mode de checksum)
+func (m *Manager) isFileModified(current, previous *FileEntry, compareChecksums bool) bool {
+	// Un fichier en échec ou ignoré lors de la sauvegarde précédente doit être renvoyé
+	if previous.Status == FileStatusFailed || previous.IsSkipped() {
+		utils.Debug("   Previous backup did not store this file (%s)", previous.Status)
+		return true
+	}
+
 	// Vérifier d'abord la taille (le plus rapide)
 	if current.Size != previous.Size {
 		utils.Debug("   Size changed: %d -> %d", previous.Size, current.Size)
@@ -281,7 +437,7 @@ func (m *Manager) isFileModified(current, previous *FileEntry) bool {
 	}
 
 	// Vérifier le checksum seulement si nécessaire
-	if current.Checksum != previous.Checksum {
+	if compareChecksums && current.Checksum != previous.Checksum {
 		utils.Debug("   Checksum changed: %s -> %s", previous.Checksum[:8], current.Checksum[:8])
 		return true
 	}
@@ -313,20 +469,26 @@ func (m *Manager) ListBackups(backupID string) error {
 	})
 
 	fmt.Printf("\n📋 Available backups:\n")
-	fmt.Printf("%-20s %-25s %-15s %-12s %-12s\n",
-		"ID", "Date", "Files", "Size", "Compressed")
-	fmt.Printf("%s\n", strings.Repeat("-", 90))
+	fmt.Printf("%-20s %-25s %-15s %-12s %-12s %-20s\n",
+		"ID", "Date", "Files", "Size", "Compressed", "Host")
+	fmt.Printf("%s\n", strings.Repeat("-", 110))
 
 	for _, backup := range indexes {
 		sizeMB := float64(backup.TotalSize) / 1024 / 1024
 		compressedMB := float64(backup.CompressedSize) / 1024 / 1024
 
-		fmt.Printf("%-20s %-20s %-15d %-12.1f MB %-12.1f MB\n",
+		host := backup.Hostname
+		if host == "" {
+			host = "-"
+		}
+
+		fmt.Printf("%-20s %-20s %-15d %-12.1f MB %-12.1f MB %-20s\n",
 			backup.BackupID,
 			backup.CreatedAt.Format("2006-01-02 15:04:05"),
 			backup.TotalFiles,
 			sizeMB,
-			compressedMB)
+			compressedMB,
+			host)
 	}
 
 	fmt.Printf("\nTotal: %d backups\n", len(indexes))
@@ -345,10 +507,37 @@ func (m *Manager) showBackupDetails(backupID string) error {
 	fmt.Printf("%s\n", strings.Repeat("-", 60))
 	fmt.Printf("Created: %s\n", index.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Source path: %s\n", index.SourcePath)
+	if index.ImportedFrom != "" {
+		fmt.Printf("Imported from: %s\n", index.ImportedFrom)
+	}
+	if len(index.SourceRoots) > 0 {
+		fmt.Printf("Sources: %s\n", strings.Join(index.SourceRoots, ", "))
+	}
 	fmt.Printf("Files: %d\n", index.TotalFiles)
 	fmt.Printf("Total size: %.1f MB\n", float64(index.TotalSize)/(1024*1024))
 	fmt.Printf("Compressed size: %.1f MB\n", float64(index.CompressedSize)/(1024*1024))
 	fmt.Printf("Encrypted size: %.1f MB\n", float64(index.EncryptedSize)/(1024*1024))
+	if status := index.EffectiveStatus(); status != BackupStatusComplete {
+		fmt.Printf("Status: %s (some files failed, see the file list)\n", status)
+	}
+	if mode := index.EffectiveChecksumMode(); ReducedSafety(mode) {
+		fmt.Printf("Checksum mode: %s (reduced safety: content changes keeping size and mtime are not detected)\n", mode)
+	} else {
+		fmt.Printf("Checksum mode: %s\n", mode)
+	}
+	if index.Origin != nil {
+		fmt.Printf("Host: %s (%s/%s)\n", index.Origin.Hostname, index.Origin.OS, index.Origin.Arch)
+		fmt.Printf("BCRDF version: %s\n", index.Origin.BCRDFVersion)
+		if index.Origin.SourceDevice != "" {
+			fmt.Printf("Source device: %s\n", index.Origin.SourceDevice)
+		}
+		if index.Origin.ConfigHash != "" {
+			fmt.Printf("Config hash: %s\n", index.Origin.ConfigHash)
+		}
+		if index.Origin.OperationID != "" {
+			fmt.Printf("Operation ID: %s\n", index.Origin.OperationID)
+		}
+	}
 
 	fmt.Printf("\n📁 Files:\n")
 	for i, file := range index.Files {
@@ -402,30 +591,40 @@ func shouldSkipFile(path string, info os.FileInfo) bool {
 
 // shouldSkipFileWithConfig determines if a file should be skipped based on config patterns
 func (m *Manager) shouldSkipFileWithConfig(path string, info os.FileInfo) bool {
-	// First check basic skip rules
-	if shouldSkipFile(path, info) {
+	// Skip directories by default (only backup files, not directories)
+	if info.IsDir() {
 		return true
 	}
 
-	// Skip directories by default (only backup files, not directories)
-	if info.IsDir() {
+	return m.isExcluded(path, info)
+}
+
+// isExcluded applies the basic skip rules and the configured skip patterns
+func (m *Manager) isExcluded(path string, info os.FileInfo) bool {
+	return IsExcluded(m.config, path, info)
+}
+
+// IsExcluded applies the basic skip rules and the skip patterns of config (nil = basic rules only)
+func IsExcluded(config *utils.Config, path string, info os.FileInfo) bool {
+	// First check basic skip rules
+	if shouldSkipFile(path, info) {
 		return true
 	}
 
 	// Check configured skip patterns
-	if m.config != nil && len(m.config.Backup.SkipPatterns) > 0 {
+	if config != nil && len(config.Backup.SkipPatterns) > 0 {
 		relativePath := filepath.Base(path)
 		fullPath := path
 
-		for _, pattern := range m.config.Backup.SkipPatterns {
+		for _, pattern := range config.Backup.SkipPatterns {
 			// Handle directory patterns (ending with /)
 			if strings.HasSuffix(pattern, "/") {
 				dirPattern := strings.TrimSuffix(pattern, "/")
 				if info.IsDir() && (strings.Contains(fullPath, dirPattern) || relativePath == dirPattern) {
 					return true
 				}
-				// Skip files inside the directory
-				if strings.Contains(fullPath, "/"+dirPattern+"/") {
+				// Skip files inside the directory (separator-independent)
+				if strings.Contains(filepath.ToSlash(fullPath), "/"+dirPattern+"/") {
 					return true
 				}
 			} else {
@@ -474,7 +673,7 @@ func (m *Manager) listIndexes() ([]BackupMetadata, error) {
 	// Lister les objets dans le préfixe indexes/
 	objects, err := m.storageClient.ListObjects("indexes/")
 	if err != nil {
-		return nil, fmt.Errorf("error listing indexes: %w", err)
+		return nil, fmt.Errorf("%w: error listing indexes: %w", utils.ErrStorageUnreachable, err)
 	}
 
 	// Extraire les clés des objets
@@ -492,7 +691,7 @@ func (m *Manager) listIndexes() ([]BackupMetadata, error) {
 			// Charger l'index pour obtenir les métadonnées
 			index, err := m.LoadIndex(backupID)
 			if err != nil {
-				utils.Warn("Impossible de charger l'index %s: %v", backupID, err)
+				utils.Warn("Unable to load index %s: %v", backupID, err)
 				continue
 			}
 
@@ -504,7 +703,10 @@ func (m *Manager) listIndexes() ([]BackupMetadata, error) {
 				TotalSize:      index.TotalSize,
 				CompressedSize: index.CompressedSize,
 				EncryptedSize:  index.EncryptedSize,
-				Status:         "completed",
+				Status:         index.EffectiveStatus(),
+			}
+			if index.Origin != nil {
+				backup.Hostname = index.Origin.Hostname
 			}
 			backups = append(backups, backup)
 		}
@@ -523,61 +725,35 @@ func (m *Manager) initializeIndex(backupID, sourcePath string) *BackupIndex {
 	}
 }
 
-// countFiles counts the total number of files to process
-func (m *Manager) countFiles(sourcePath, checksumMode string, verbose bool) (int64, error) {
-	var fileCount int64
-
-	// Load configuration for skip patterns
-	if m.config == nil {
-		config, err := utils.LoadConfig(m.configFile)
-		if err != nil {
-			return 0, fmt.Errorf("error loading config: %w", err)
-		}
-		m.config = config
+// symlinkPolicy retourne la politique des liens symboliques (backup.symlinks, store par défaut)
+func (m *Manager) symlinkPolicy() string {
+	if m.symlinks != "" {
+		return m.symlinks
 	}
-
-	modeDesc := map[string]string{
-		"full":     "🔄 Analyzing directory (full integrity)...",
-		"fast":     "🔄 Analyzing directory (fast mode)...",
-		"metadata": "🔄 Analyzing directory (metadata only)...",
-	}
-	desc := modeDesc[checksumMode]
-	if desc == "" {
-		desc = "🔄 Analyzing directory..."
-	}
-	utils.ProgressStep(desc)
-
-	err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || m.shouldSkipFileWithConfig(path, info) {
-			return nil
-		}
-
-		// Only count files, not directories
-		if !info.IsDir() {
-			fileCount++
-		}
-
-		return nil
-	})
-	if err != nil {
-		return 0, fmt.Errorf("error counting files: %w", err)
+	if m.config.Backup.Symlinks != "" {
+		return m.config.Backup.Symlinks
 	}
-
-	return fileCount, nil
+	return SymlinksStore
 }
 
-// setupProgressBar creates and initializes a progress bar if needed
-func (m *Manager) setupProgressBar(verbose bool, fileCount int64) *utils.ProgressBar {
-	if verbose || fileCount <= 0 {
-		return nil
+// scanDescription retourne le message de début d'indexation selon le mode de checksum
+func scanDescription(checksumMode string) string {
+	switch checksumMode {
+	case ChecksumModeFull:
+		return utils.Msg("scan.full")
+	case ChecksumModeFast:
+		return utils.Msg("scan.fast")
+	case ChecksumModeMetadata:
+		return utils.Msg("scan.metadata")
+	case ChecksumModeMtimeSize:
+		return utils.Msg("scan.mtime_size")
+	default:
+		return utils.Msg("scan.default")
 	}
-	return utils.NewProgressBar(fileCount)
 }
 
 // processFiles walks through the source directory and processes each file
-func (m *Manager) processFiles(sourcePath, checksumMode string, verbose bool, index *BackupIndex, progressBar *utils.ProgressBar) error {
-	processed := int64(0)
-
+func (m *Manager) processFiles(sourcePath, checksumMode string, verbose bool, index *BackupIndex, progress *utils.ScanProgress) error {
 	// Load configuration for skip patterns
 	if m.config == nil {
 		config, err := utils.LoadConfig(m.configFile)
@@ -587,18 +763,120 @@ func (m *Manager) processFiles(sourcePath, checksumMode string, verbose bool, in
 		m.config = config
 	}
 
-	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+	// Périphérique de la source: les entrées d'un autre système de fichiers ne sont pas parcourues
+	var sourceDevice uint64
+	oneFileSystem := false
+	if m.oneFileSystem || m.config.Backup.OneFileSystem {
+		if info, err := os.Stat(sourcePath); err == nil {
+			sourceDevice, oneFileSystem = fileDevice(info)
+		}
+		if !oneFileSystem {
+			utils.Warn("one_file_system is not supported on this platform, mount points will be crossed")
+		}
+	}
+
+	// Exclusions gérées par les équipes à côté de leurs données (.bcrdfignore)
+	ignores := NewIgnoreRules(sourcePath, m.config)
+
+	return walkSource(sourcePath, m.symlinkPolicy(), func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			if verbose {
 				utils.Warn("Error accessing %s: %v", path, err)
 			}
+			index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedUnreadable, err))
 			return nil // Continue despite error
 		}
 
+		if m.excludedPaths[path] {
+			utils.Debug("Excluded bcrdf local file: %s", path)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if ignores.Ignored(path, info.IsDir()) {
+			utils.Debug("Ignored by %s: %s", IgnoreFileName, path)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedExcluded,
+				fmt.Errorf("excluded by %s", IgnoreFileName)))
+			return nil
+		}
+
+		if oneFileSystem {
+			if device, ok := fileDevice(info); ok && device != sourceDevice {
+				if !info.IsDir() {
+					return nil
+				}
+				// Le point de montage lui-même est conservé (vide) pour que la restauration recrée l'arborescence
+				if m.config.Backup.PreserveDirectories && !m.isExcluded(path, info) {
+					if entry, err := NewFileEntryWithModeAndCache(path, info, checksumMode, nil); err == nil {
+						index.Files = append(index.Files, *entry)
+					}
+				}
+				if verbose {
+					utils.Info("Not crossing filesystem boundary: %s", path)
+				} else {
+					utils.Debug("Not crossing filesystem boundary: %s", path)
+				}
+				return filepath.SkipDir
+			}
+		}
+
+		// Répertoires: enregistrés sans données si demandé (permissions restaurées)
+		if info.IsDir() && m.config.Backup.PreserveDirectories &&
+			filepath.Clean(path) != filepath.Clean(sourcePath) && !m.isExcluded(path, info) {
+			if entry, err := NewFileEntryWithModeAndCache(path, info, checksumMode, nil); err == nil {
+				index.Files = append(index.Files, *entry)
+			}
+			return nil
+		}
+
 		if m.shouldSkipFileWithConfig(path, info) {
 			if verbose {
 				utils.Debug("Skipping file: %s", path)
 			}
+			// Les répertoires ne sont pas sauvegardés: seuls les fichiers exclus sont tracés
+			if !info.IsDir() {
+				index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedExcluded, nil))
+			}
+			return nil
+		}
+
+		// Liens symboliques enregistrés tels quels (symlinks: store), sans données
+		if isSymlink(info) {
+			entry, err := NewSymlinkEntry(path, info)
+			if err != nil {
+				index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedUnreadable, err))
+				return nil
+			}
+			index.Files = append(index.Files, *entry)
+			index.TotalFiles++
+			if progress != nil {
+				progress.Add(0)
+			}
+			return nil
+		}
+
+		// Fichiers trop volumineux: ignorés avec un avertissement
+		if maxSize := m.maxFileSize(); maxSize > 0 && info.Size() > maxSize {
+			utils.Warn("Skipping %s: %.2f MB exceeds max_file_size (%s)", path, float64(info.Size())/1024/1024, m.config.Backup.MaxFileSize)
+			index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedExcluded,
+				fmt.Errorf("exceeds max_file_size %s", m.config.Backup.MaxFileSize)))
+			return nil
+		}
+
+		// Fichiers vides: enregistrés sans données si demandé
+		if info.Size() == 0 && m.config.Backup.PreserveEmptyFiles {
+			if entry, err := NewFileEntryWithModeAndCache(path, info, checksumMode, nil); err == nil {
+				index.Files = append(index.Files, *entry)
+				index.TotalFiles++
+				if progress != nil {
+					progress.Add(0)
+				}
+			}
 			return nil
 		}
 
@@ -614,30 +892,97 @@ func (m *Manager) processFiles(sourcePath, checksumMode string, verbose bool, in
 			utils.Debug("Processing file: %s", path)
 		}
 
-		entry, err := NewFileEntryWithModeAndCache(path, info, checksumMode, m.checksumCache)
+		entry, err := m.newFileEntry(path, info, checksumMode)
 		if err != nil {
 			if verbose {
 				utils.Warn("Error creating entry for %s: %v", path, err)
 			}
+			index.Files = append(index.Files, skippedEntry(path, info, FileStatusSkippedUnreadable, err))
 			return nil
 		}
 
-		// Générer la StorageKey immédiatement
-		entry.StorageKey = entry.GetStorageKey()
+		// Générer la StorageKey immédiatement (aléatoire: deux fichiers ne peuvent pas partager un objet)
+		assignStorageKey(entry)
 
 		index.Files = append(index.Files, *entry)
 		index.TotalFiles++
 		index.TotalSize += entry.Size
 
-		if !verbose && progressBar != nil {
-			processed++
-			progressBar.Update(processed)
+		if progress != nil {
+			progress.Add(entry.Size)
 		}
 
 		return nil
 	})
 }
 
+// newFileEntry crée l'entrée d'un fichier en réutilisant le checksum persistant d'un fichier inchangé.
+// Seul le mode fast utilise le cache: en mode full, le contenu est toujours relu.
+func (m *Manager) newFileEntry(path string, info os.FileInfo, checksumMode string) (*FileEntry, error) {
+	if m.checksumStore == nil || checksumMode != ChecksumModeFast {
+		return NewFileEntryWithModeAndCache(path, info, checksumMode, m.checksumCache)
+	}
+
+	if checksum, ok := m.checksumStore.LookupChecksum(path, checksumMode, info.Size(), info.ModTime()); ok {
+		entry, err := NewFileEntryWithModeAndCache(path, info, ChecksumModeMetadata, nil)
+		if err != nil {
+			return nil, err
+		}
+		entry.Checksum = checksum
+		return entry, nil
+	}
+
+	entry, err := NewFileEntryWithModeAndCache(path, info, checksumMode, m.checksumCache)
+	if err != nil {
+		return nil, err
+	}
+	m.pendingChecksums = append(m.pendingChecksums, CachedChecksum{
+		Path: path, Mode: checksumMode, Size: info.Size(), ModTime: info.ModTime(), Checksum: entry.Checksum,
+	})
+	return entry, nil
+}
+
+// flushChecksums met en cache les checksums calculés pendant le parcours
+func (m *Manager) flushChecksums() {
+	if m.checksumStore == nil || len(m.pendingChecksums) == 0 {
+		return
+	}
+	if err := m.checksumStore.StoreChecksums(m.pendingChecksums); err != nil {
+		utils.Warn("Checksum cache not updated: %v", err)
+	}
+	m.pendingChecksums = nil
+}
+
+// maxFileSize retourne la taille maximale d'un fichier sauvegardé (0 = illimitée)
+func (m *Manager) maxFileSize() int64 {
+	if m.config.Backup.MaxFileSize == "" {
+		return 0
+	}
+	size, err := utils.ParseSize(m.config.Backup.MaxFileSize)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// skippedEntry crée une entrée d'index pour un fichier non sauvegardé
+func skippedEntry(path string, info os.FileInfo, status string, reason error) FileEntry {
+	entry := FileEntry{
+		Path:   path,
+		Status: status,
+	}
+	if info != nil {
+		entry.Size = info.Size()
+		entry.ModifiedTime = info.ModTime()
+		entry.IsDirectory = info.IsDir()
+		entry.Permissions = info.Mode().String()
+	}
+	if reason != nil {
+		entry.Error = reason.Error()
+	}
+	return entry
+}
+
 // CleanOrphanedFiles nettoie les fichiers orphelins sur le stockage
 // qui ne correspondent pas à l'index de sauvegarde
 // Si backupID est vide, nettoie toutes les sauvegardes
@@ -673,6 +1018,11 @@ func (m *Manager) CleanOrphanedFiles(backupID string, dryRun, verbose bool) erro
 		}
 		m.storageClient = storageClient
 	}
+	if !dryRun {
+		if err := utils.CheckDeletesAllowed(m.config, "clean"); err != nil {
+			return err
+		}
+	}
 
 	if verbose {
 		utils.ProgressDone("Storage client initialized")
@@ -683,8 +1033,8 @@ func (m *Manager) CleanOrphanedFiles(backupID string, dryRun, verbose bool) erro
 	validKeys := make(map[string]bool)
 	for _, file := range index.Files {
 		if !file.IsDirectory {
-			// Construire la clé de stockage complète avec le préfixe data/backupID/
-			fullStorageKey := fmt.Sprintf("data/%s/%s", backupID, file.StorageKey)
+			// Construire la clé de stockage complète avec le préfixe data/{sauvegarde détentrice}/
+			fullStorageKey := file.DataKey(backupID)
 			validKeys[fullStorageKey] = true
 
 			// Si c'est un gros fichier, ajouter aussi tous ses chunks comme valides
@@ -877,6 +1227,11 @@ func (m *Manager) CleanAllBackups(dryRun, verbose, removeOrphaned bool) error {
 		}
 		m.storageClient = storageClient
 	}
+	if !dryRun {
+		if err := utils.CheckDeletesAllowed(m.config, "clean"); err != nil {
+			return err
+		}
+	}
 
 	// Lister tous les objets sur le stockage
 	if verbose {
@@ -1011,8 +1366,8 @@ func (m *Manager) CleanAllBackups(dryRun, verbose, removeOrphaned bool) error {
 
 		for _, file := range index.Files {
 			if !file.IsDirectory {
-				// Construire la clé de stockage complète avec le préfixe data/backupID/
-				fullStorageKey := fmt.Sprintf("data/%s/%s", backup.BackupID, file.StorageKey)
+				// Construire la clé de stockage complète avec le préfixe data/{sauvegarde détentrice}/
+				fullStorageKey := file.DataKey(backup.BackupID)
 				validKeys[fullStorageKey] = true
 
 				// Si c'est un gros fichier, ajouter aussi tous ses chunks comme valides
@@ -1055,9 +1410,10 @@ func (m *Manager) CleanAllBackups(dryRun, verbose, removeOrphaned bool) error {
 	orphanedBackupObjects := make(map[string][]storage.ObjectInfo)
 
 	for _, obj := range allObjects {
-		// Ignorer les fichiers d'index et de métadonnées
+		// Ignorer les fichiers d'index, de métadonnées, les rapports et la parité
 		if strings.HasSuffix(obj.Key, ".index") || strings.HasSuffix(obj.Key, ".metadata") ||
-			strings.HasSuffix(obj.Key, ".json") || strings.Contains(obj.Key, "indexes/") {
+			strings.HasSuffix(obj.Key, ".json") || strings.Contains(obj.Key, "indexes/") ||
+			strings.HasPrefix(obj.Key, ReportPrefix) || parity.IsParityKey(obj.Key) {
 			continue
 		}
 
@@ -1090,12 +1446,14 @@ func (m *Manager) CleanAllBackups(dryRun, verbose, removeOrphaned bool) error {
 		}
 
 		// Vérifier si la clé existe dans un index valide
-		if !validKeys[obj.Key] {
+		referenced := validKeys[ObjectFileKey(obj.Key)]
+		if !referenced {
 			orphanedFiles = append(orphanedFiles, obj)
 		}
 
-		// Vérifier si la sauvegarde a un index
-		if !validBackupIDs[backupID] {
+		// Vérifier si la sauvegarde a un index (les objets d'une sauvegarde supprimée restent
+		// utilisés tant que les fichiers inchangés des sauvegardes suivantes les référencent)
+		if !validBackupIDs[backupID] && !referenced {
 			if orphanedBackupObjects[backupID] == nil {
 				orphanedBackupObjects[backupID] = []storage.ObjectInfo{}
 				orphanedBackups = append(orphanedBackups, backupID)
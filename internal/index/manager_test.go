@@ -1,6 +1,7 @@
 package index
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -131,3 +132,339 @@ func TestShouldSkipFile(t *testing.T) {
 		})
 	}
 }
+
+func TestFileEntryFileMode(t *testing.T) {
+	tempDir := t.TempDir()
+	dir := filepath.Join(tempDir, "private")
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatalf("Erreur lors de la création du répertoire: %v", err)
+	}
+	if err := os.Chmod(dir, 0750); err != nil {
+		t.Fatalf("Erreur lors du chmod: %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Erreur lors de la récupération des informations: %v", err)
+	}
+
+	entry, err := NewFileEntry(dir, info)
+	if err != nil {
+		t.Fatalf("Erreur lors de la création de l'entrée: %v", err)
+	}
+
+	if !entry.IsMetadataOnly() {
+		t.Error("Un répertoire devrait être une entrée sans données")
+	}
+
+	mode, ok := entry.FileMode()
+	if !ok || mode != 0750 {
+		t.Errorf("Permissions incorrectes: attendu 0750, obtenu %o (%v)", mode, ok)
+	}
+}
+
+func TestRelativeToSource(t *testing.T) {
+	cases := []struct {
+		path, source, expected string
+	}{
+		{"/home/user/docs/a.txt", "/home/user/docs", "a.txt"},
+		{"/home/user/docs/sub/b.txt", "/home/user/docs/", "sub/b.txt"},
+		{`C:\Users\Alice\Docs\report.pdf`, `C:\Users\Alice\Docs`, "report.pdf"},
+		{`c:\users\alice\docs\sub\x.txt`, `C:\Users\Alice\Docs`, "sub/x.txt"},
+		{`\\server\share\data\file.bin`, `\\server\share\data`, "file.bin"},
+		{`\\?\C:\Very\Long\Path\file.txt`, `C:\Very\Long`, "Path/file.txt"},
+		{`\\?\UNC\server\share\dir\f.txt`, `\\server\share`, "dir/f.txt"},
+		{`D:\Other\f.txt`, `C:\Users`, "Other/f.txt"},
+	}
+
+	for _, c := range cases {
+		if got := RelativeToSource(c.path, c.source); got != c.expected {
+			t.Errorf("RelativeToSource(%q, %q): attendu %q, obtenu %q", c.path, c.source, c.expected, got)
+		}
+	}
+}
+
+func TestCommonRoot(t *testing.T) {
+	cases := []struct {
+		paths    []string
+		expected string
+	}{
+		{[]string{"/etc", "/var/www", "/home"}, "/"},
+		{[]string{"/srv/app/config", "/srv/app/data"}, "/srv/app"},
+		{[]string{"/data", "/data2"}, "/"},
+		{[]string{"/srv/app"}, "/srv/app"},
+	}
+	for _, c := range cases {
+		if got := CommonRoot(c.paths); got != c.expected {
+			t.Errorf("CommonRoot(%v): attendu %q, obtenu %q", c.paths, c.expected, got)
+		}
+	}
+}
+
+func TestChunkMetadataChecksums(t *testing.T) {
+	chunks := [][]byte{[]byte("chunk zero"), []byte("chunk one")}
+	metadata := ChunkMetadata{Chunks: 2, Size: 19}
+	for _, chunk := range chunks {
+		metadata.Checksums = append(metadata.Checksums, NewChunkChecksum(chunk, chunk))
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Erreur lors de la sérialisation: %v", err)
+	}
+	parsed, err := ParseChunkMetadata(data)
+	if err != nil {
+		t.Fatalf("Erreur lors de la lecture des métadonnées: %v", err)
+	}
+
+	if err := parsed.VerifyStored(0, chunks[0]); err != nil {
+		t.Errorf("Le chunk 0 devrait être valide: %v", err)
+	}
+	// Chunks inversés: détectés par position
+	if err := parsed.VerifyStored(1, chunks[0]); err == nil {
+		t.Error("Un chunk inversé devrait être détecté")
+	}
+
+	// Anciennes métadonnées sans checksums: pas de vérification possible
+	legacy, err := ParseChunkMetadata([]byte(`{"chunks": 3, "size": 42}`))
+	if err != nil {
+		t.Fatalf("Erreur lors de la lecture des anciennes métadonnées: %v", err)
+	}
+	if err := legacy.VerifyPlain(2, []byte("anything")); err != nil {
+		t.Errorf("Les anciennes métadonnées ne devraient pas être vérifiées: %v", err)
+	}
+}
+
+func TestEnsureUniqueStorageKeys(t *testing.T) {
+	files := []FileEntry{
+		{Path: "/a", StorageKey: "same"},
+		{Path: "/b", StorageKey: "same"},
+		{Path: "/c", StorageKey: "other"},
+	}
+
+	if dup := DuplicateStorageKeys(files); len(dup) != 2 {
+		t.Errorf("Attendu 2 fichiers en collision, obtenu %v", dup)
+	}
+
+	rekeyed := EnsureUniqueStorageKeys(files)
+	if len(rekeyed) != 1 || rekeyed[0] != "/b" {
+		t.Errorf("Seul /b devrait recevoir une nouvelle clé, obtenu %v", rekeyed)
+	}
+	if files[0].StorageKey != "same" || files[1].StorageKey == "same" {
+		t.Error("La première entrée doit garder sa clé, la seconde doit changer")
+	}
+	if dup := DuplicateStorageKeys(files); len(dup) != 0 {
+		t.Errorf("Aucune collision ne devrait subsister, obtenu %v", dup)
+	}
+
+	// Contenu identique envoyé une seule fois: le partage de clé est voulu
+	shared := []FileEntry{
+		{Path: "/a", StorageKey: "same", Checksum: "abc", Size: 3},
+		{Path: "/b", StorageKey: "same", Checksum: "abc", Size: 3},
+	}
+	if dup := DuplicateStorageKeys(shared); len(dup) != 0 {
+		t.Errorf("Les doublons de contenu ne sont pas des collisions, obtenu %v", dup)
+	}
+}
+
+func TestIndexPayloadEncoding(t *testing.T) {
+	data := []byte(`{"backup_id":"test","files":[]}`)
+
+	for _, compression := range []string{IndexCompressionGzip, IndexCompressionNone} {
+		encoded, err := encodeIndexPayload(data, compression)
+		if err != nil {
+			t.Fatalf("Erreur d'encodage (%s): %v", compression, err)
+		}
+		decoded, err := decodeIndexPayload(encoded)
+		if err != nil {
+			t.Fatalf("Erreur de décodage (%s): %v", compression, err)
+		}
+		if string(decoded) != string(data) {
+			t.Errorf("Index altéré (%s): %s", compression, decoded)
+		}
+	}
+
+	// Ancien format: JSON brut sans en-tête
+	decoded, err := decodeIndexPayload(data)
+	if err != nil || string(decoded) != string(data) {
+		t.Errorf("L'ancien format devrait être lu tel quel: %v", err)
+	}
+}
+
+func TestIndexDeltaMerge(t *testing.T) {
+	now := time.Now()
+	base := []FileEntry{
+		{Path: "/a", Size: 1, ModifiedTime: now, StorageKey: "ka"},
+		{Path: "/b", Size: 2, ModifiedTime: now, StorageKey: "kb"},
+		{Path: "/c", Size: 3, ModifiedTime: now, StorageKey: "kc"},
+	}
+	current := []FileEntry{
+		{Path: "/a", Size: 1, ModifiedTime: now.UTC(), StorageKey: "ka"},
+		{Path: "/b", Size: 20, ModifiedTime: now, StorageKey: "kb2"},
+		{Path: "/d", Size: 4, ModifiedTime: now, StorageKey: "kd"},
+	}
+
+	upserts, removed := diffIndexBase(base, current)
+	if len(upserts) != 2 || len(removed) != 1 || removed[0] != "/c" {
+		t.Fatalf("Delta incorrect: %d modifiés, supprimés %v", len(upserts), removed)
+	}
+
+	merged := mergeIndexDelta(base, upserts, removed)
+	if len(merged) != len(current) {
+		t.Fatalf("Attendu %d fichiers après fusion, obtenu %d", len(current), len(merged))
+	}
+	for i := range current {
+		if !sameEntry(merged[i], current[i]) {
+			t.Errorf("Entrée %d incorrecte après fusion: %+v", i, merged[i])
+		}
+	}
+}
+
+func TestVerifyRestored(t *testing.T) {
+	tempDir := t.TempDir()
+	original := filepath.Join(tempDir, "original.txt")
+	if err := os.WriteFile(original, []byte("contenu de test"), 0600); err != nil {
+		t.Fatalf("Erreur lors de la création du fichier de test: %v", err)
+	}
+	info, err := os.Stat(original)
+	if err != nil {
+		t.Fatalf("Erreur lors de la lecture des informations: %v", err)
+	}
+
+	restored := filepath.Join(tempDir, "restored.txt")
+	for _, mode := range []string{"full", "fast", "metadata"} {
+		entry, err := NewFileEntryWithMode(original, info, mode)
+		if err != nil {
+			t.Fatalf("Erreur lors de la création de l'entrée (%s): %v", mode, err)
+		}
+
+		if err := os.WriteFile(restored, []byte("contenu de test"), 0644); err != nil {
+			t.Fatalf("Erreur lors de l'écriture du fichier restauré: %v", err)
+		}
+		if err := VerifyRestored(restored, *entry, mode); err != nil {
+			t.Errorf("Le fichier restauré devrait être valide (%s): %v", mode, err)
+		}
+
+		if err := os.WriteFile(restored, []byte("contenu modifié"), 0644); err != nil {
+			t.Fatalf("Erreur lors de l'écriture du fichier restauré: %v", err)
+		}
+		if mode != "metadata" && VerifyRestored(restored, *entry, mode) == nil {
+			t.Errorf("Un contenu modifié devrait être détecté (%s)", mode)
+		}
+	}
+}
+
+func TestMatchesEntryReducedSafety(t *testing.T) {
+	tempDir := t.TempDir()
+	original := filepath.Join(tempDir, "original.txt")
+	if err := os.WriteFile(original, []byte("contenu de test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(original, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := filepath.Join(tempDir, "local.txt")
+	for _, mode := range []string{ChecksumModeMetadata, ChecksumModeMtimeSize} {
+		entry, err := NewFileEntryWithMode(original, info, mode)
+		if err != nil {
+			t.Fatalf("Erreur lors de la création de l'entrée (%s): %v", mode, err)
+		}
+		if !MatchesEntry(original, *entry, mode) {
+			t.Errorf("Le fichier inchangé devrait correspondre à son entrée (%s)", mode)
+		}
+
+		// Même taille, contenu et date différents: pas inchangé
+		if err := os.WriteFile(local, []byte("CONTENU DE TEST"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if MatchesEntry(local, *entry, mode) {
+			t.Errorf("Une taille égale ne suffit pas à déclarer un fichier inchangé (%s)", mode)
+		}
+	}
+}
+
+func TestMtimeSizeChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("contenu"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := NewFileEntryWithMode(path, info, ChecksumModeMtimeSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Checksum != mtimeSizeChecksum(info.Size(), info.ModTime()) {
+		t.Errorf("checksum mtime-size inattendu: %s", entry.Checksum)
+	}
+
+	// Même taille et même date: la modification du contenu n'est pas vue (sécurité réduite)
+	if err := os.WriteFile(path, []byte("CONTENU"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	info, _ = os.Stat(path)
+	again, _ := NewFileEntryWithMode(path, info, ChecksumModeMtimeSize)
+	if again.Checksum != entry.Checksum {
+		t.Error("le mode mtime-size ne doit dépendre que de la taille et de la date")
+	}
+}
+
+func TestCompareIndexesIgnoresChecksumsAcrossModes(t *testing.T) {
+	modTime := time.Now()
+	previous := &BackupIndex{BackupID: "old", Files: []FileEntry{
+		{Path: "a.txt", Size: 3, ModifiedTime: modTime, Checksum: "fast-sum", StorageKey: "k1"},
+	}}
+	current := &BackupIndex{BackupID: "new", ChecksumMode: ChecksumModeMtimeSize, Files: []FileEntry{
+		{Path: "a.txt", Size: 3, ModifiedTime: modTime, Checksum: mtimeSizeChecksum(3, modTime)},
+	}}
+
+	diff, err := (&Manager{}).CompareIndexes(current, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Modified) != 0 || current.Files[0].StorageKey != "k1" {
+		t.Errorf("fichier inchangé considéré modifié après changement de mode: %+v", diff.Modified)
+	}
+
+	current.ChecksumMode = ""
+	if diff, _ := (&Manager{}).CompareIndexes(current, previous); len(diff.Modified) != 1 {
+		t.Errorf("checksum différent non détecté dans le même mode: %+v", diff.Modified)
+	}
+}
+
+func TestCompareIndexesPointsUnchangedFilesToTheirOwner(t *testing.T) {
+	modTime := time.Now()
+	previous := &BackupIndex{BackupID: "b2", Files: []FileEntry{
+		{Path: "a.txt", Size: 3, ModifiedTime: modTime, Checksum: "sum-a", StorageKey: "ka", DataBackupID: "b1"},
+		{Path: "b.txt", Size: 4, ModifiedTime: modTime, Checksum: "sum-b", StorageKey: "kb"},
+	}}
+	current := &BackupIndex{BackupID: "b3", Files: []FileEntry{
+		{Path: "a.txt", Size: 3, ModifiedTime: modTime, Checksum: "sum-a"},
+		{Path: "b.txt", Size: 4, ModifiedTime: modTime, Checksum: "sum-b"},
+		{Path: "c.txt", Size: 5, ModifiedTime: modTime, Checksum: "sum-c", StorageKey: "kc"},
+	}}
+
+	if _, err := (&Manager{}).CompareIndexes(current, previous); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"data/b1/ka", "data/b2/kb", "data/b3/kc"} {
+		if got := current.Files[i].DataKey(current.BackupID); got != want {
+			t.Errorf("%s: clé %s, attendu %s", current.Files[i].Path, got, want)
+		}
+	}
+	if owners := current.DataOwners(); len(owners) != 3 || owners[0] != "b3" || owners[1] != "b1" || owners[2] != "b2" {
+		t.Errorf("sauvegardes détentrices inattendues: %v", owners)
+	}
+}
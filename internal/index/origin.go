@@ -0,0 +1,93 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// ToolVersion est la version de BCRDF enregistrée dans les index (définie par la CLI)
+var ToolVersion = "dev"
+
+// BackupOrigin décrit la machine et la source d'où provient une sauvegarde
+type BackupOrigin struct {
+	Hostname     string `json:"hostname"`
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	BCRDFVersion string `json:"bcrdf_version"`
+	ConfigHash   string `json:"config_hash,omitempty"`
+	MachineID    string `json:"machine_id,omitempty"`
+	SourceDevice string `json:"source_device,omitempty"`
+	OperationID  string `json:"operation_id,omitempty"` // Exécution ayant créé la sauvegarde (corrélation des logs)
+}
+
+// CollectOrigin collecte les informations d'origine pour une source donnée
+func CollectOrigin(sourcePath string, config *utils.Config) *BackupOrigin {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	origin := &BackupOrigin{
+		Hostname:     hostname,
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		BCRDFVersion: ToolVersion,
+		ConfigHash:   configHash(config),
+		MachineID:    readMachineID(),
+		OperationID:  utils.OperationID(),
+	}
+
+	if absPath, err := filepath.Abs(sourcePath); err == nil {
+		origin.SourceDevice = sourceDeviceID(absPath)
+	}
+
+	return origin
+}
+
+// SameMachine indique si deux origines proviennent de la même machine
+func (o *BackupOrigin) SameMachine(other *BackupOrigin) bool {
+	if o == nil || other == nil {
+		return true // Anciens index sans origine: impossible de conclure
+	}
+	if o.MachineID != "" && other.MachineID != "" {
+		return o.MachineID == other.MachineID
+	}
+	return o.Hostname == other.Hostname
+}
+
+// configHash calcule une empreinte de la configuration sans les secrets
+func configHash(config *utils.Config) string {
+	if config == nil {
+		return ""
+	}
+
+	redacted := *config
+	redacted.Storage.AccessKey = ""
+	redacted.Storage.SecretKey = ""
+	redacted.Storage.Password = ""
+	redacted.Backup.EncryptionKey = ""
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:8])
+}
+
+// readMachineID lit l'identifiant machine systemd/dbus s'il existe
+func readMachineID() string {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package index
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// sourceDeviceID retourne l'identifiant du périphérique contenant la source
+func sourceDeviceID(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	device, ok := fileDevice(info)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("dev:%d", device)
+}
+
+// fileDevice retourne le périphérique (système de fichiers) d'une entrée
+func fileDevice(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
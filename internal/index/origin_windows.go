@@ -0,0 +1,23 @@
+//go:build windows
+
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sourceDeviceID retourne le volume contenant la source
+func sourceDeviceID(path string) string {
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return ""
+	}
+	return "volume:" + strings.ToUpper(volume)
+}
+
+// fileDevice n'est pas disponible: les volumes montés dans un répertoire ne sont pas détectés
+func fileDevice(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,95 @@
+package index
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// longPathPrefix est le préfixe Windows des chemins étendus (> 260 caractères)
+const longPathPrefix = `\\?\`
+
+// IsWindowsPath détecte un chemin Windows (lettre de lecteur, UNC ou chemin étendu)
+func IsWindowsPath(path string) bool {
+	if strings.HasPrefix(path, `\\`) {
+		return true
+	}
+	return len(path) >= 2 && path[1] == ':' &&
+		((path[0] >= 'a' && path[0] <= 'z') || (path[0] >= 'A' && path[0] <= 'Z'))
+}
+
+// RelativeToSource retourne le chemin d'une entrée relatif à la racine de sauvegarde, avec des séparateurs '/'
+// Les chemins Windows (lettres de lecteur, UNC, \\?\) sont gérés quel que soit l'OS de restauration
+func RelativeToSource(path, sourcePath string) string {
+	windows := IsWindowsPath(path) || IsWindowsPath(sourcePath)
+	if windows {
+		path = windowsToSlash(path)
+		sourcePath = windowsToSlash(sourcePath)
+	} else {
+		path = filepath.ToSlash(path)
+		sourcePath = filepath.ToSlash(sourcePath)
+	}
+
+	if !windows && !strings.HasPrefix(path, "/") {
+		// Chemin relatif: conservé tel quel
+		return path
+	}
+
+	root := strings.TrimRight(sourcePath, "/")
+	if hasPathPrefix(path, root+"/", windows) {
+		return path[len(root)+1:]
+	}
+	if hasPathPrefix(path, root, windows) {
+		return strings.TrimLeft(path[len(root):], "/")
+	}
+
+	// Hors de la racine: retirer le lecteur ou le serveur UNC pour rester sous la destination
+	if windows {
+		if len(path) >= 2 && path[1] == ':' {
+			path = path[2:]
+		} else if strings.HasPrefix(path, "//") {
+			parts := strings.SplitN(strings.TrimPrefix(path, "//"), "/", 3)
+			if len(parts) == 3 {
+				path = parts[2]
+			}
+		}
+	}
+	return strings.TrimLeft(path, "/")
+}
+
+// CommonRoot retourne le plus long répertoire parent commun à des chemins absolus
+// ("/" pour /etc et /var/www, vide pour des lecteurs Windows différents)
+func CommonRoot(paths []string) string {
+	root := filepath.Clean(paths[0])
+	for _, path := range paths[1:] {
+		path = filepath.Clean(path)
+		for root != "" && path != root && !strings.HasPrefix(path, strings.TrimRight(root, string(filepath.Separator))+string(filepath.Separator)) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				return ""
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+// windowsToSlash convertit un chemin Windows en séparateurs '/' et retire le préfixe étendu
+func windowsToSlash(path string) string {
+	if strings.HasPrefix(path, longPathPrefix+`UNC\`) {
+		path = `\\` + path[len(longPathPrefix+`UNC\`):]
+	} else {
+		path = strings.TrimPrefix(path, longPathPrefix)
+	}
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// hasPathPrefix compare un préfixe de chemin (insensible à la casse pour Windows)
+func hasPathPrefix(path, prefix string, caseInsensitive bool) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	if caseInsensitive {
+		return strings.EqualFold(path[:len(prefix)], prefix)
+	}
+	return path[:len(prefix)] == prefix
+}
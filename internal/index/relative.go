@@ -0,0 +1,79 @@
+package index
+
+import "strings"
+
+// Chemins relatifs à la racine: les index enregistrent les chemins relatifs à SourcePath, qui est
+// enregistré une seule fois. En mémoire les chemins restent absolus (lecture des fichiers sources,
+// comparaison avec la sauvegarde précédente): la conversion a lieu à l'écriture et à la lecture.
+// Les index sans paths_relative (anciens ou source relative) gardent leurs chemins tels quels.
+
+// relativePaths retourne les chemins de files relatifs à root, ou false si root n'est pas
+// absolu ou si un chemin est hors de root (l'index est alors enregistré tel quel)
+func relativePaths(files []FileEntry, removed []string, root string) ([]FileEntry, []string, bool) {
+	windows := IsWindowsPath(root)
+	if root == "" || (!windows && !strings.HasPrefix(root, "/")) {
+		return nil, nil, false
+	}
+	prefix := rootPrefix(root)
+
+	relFiles := make([]FileEntry, len(files))
+	for i, file := range files {
+		path := file.Path
+		if windows {
+			path = windowsToSlash(path)
+		}
+		if !hasPathPrefix(path, prefix, windows) {
+			return nil, nil, false
+		}
+		relFiles[i] = file
+		relFiles[i].Path = path[len(prefix):]
+	}
+	var relRemoved []string
+	for _, path := range removed {
+		if windows {
+			path = windowsToSlash(path)
+		}
+		if !hasPathPrefix(path, prefix, windows) {
+			return nil, nil, false
+		}
+		relRemoved = append(relRemoved, path[len(prefix):])
+	}
+	return relFiles, relRemoved, true
+}
+
+// absolutePaths rétablit les chemins absolus de fichiers enregistrés relatifs à root
+func absolutePaths(files []FileEntry, removed []string, root string) {
+	for i := range files {
+		files[i].Path = joinRoot(root, files[i].Path)
+	}
+	for i := range removed {
+		removed[i] = joinRoot(root, removed[i])
+	}
+}
+
+// rootPrefix retourne la racine avec séparateurs '/' et '/' final
+func rootPrefix(root string) string {
+	if IsWindowsPath(root) {
+		root = windowsToSlash(root)
+	}
+	return strings.TrimRight(root, "/") + "/"
+}
+
+// joinRoot place un chemin relatif ('/') sous la racine, avec les séparateurs de la racine
+func joinRoot(root, rel string) string {
+	if IsWindowsPath(root) {
+		return strings.TrimRight(root, `\`) + `\` + strings.ReplaceAll(rel, "/", `\`)
+	}
+	return rootPrefix(root) + rel
+}
+
+// storedIndex retourne l'index tel qu'enregistré: une copie aux chemins relatifs si possible
+func storedIndex(index *BackupIndex) *BackupIndex {
+	files, removed, ok := relativePaths(index.Files, index.RemovedPaths, index.SourcePath)
+	if !ok {
+		return index
+	}
+	stored := *index
+	stored.Files, stored.RemovedPaths, stored.PathsRelative = files, removed, true
+	return &stored
+}
@@ -0,0 +1,52 @@
+package index
+
+import "testing"
+
+func TestStoredIndexPathsRelative(t *testing.T) {
+	cases := []struct {
+		root  string
+		paths []string
+		rel   []string
+	}{
+		{"/srv/app", []string{"/srv/app/config.yml", "/srv/app/data/db.sqlite"}, []string{"config.yml", "data/db.sqlite"}},
+		{"/", []string{"/etc/hosts"}, []string{"etc/hosts"}},
+		{`C:\Users\Alice`, []string{`C:\Users\Alice\Docs\a.txt`}, []string{"Docs/a.txt"}},
+	}
+	for _, c := range cases {
+		index := &BackupIndex{SourcePath: c.root}
+		for _, path := range c.paths {
+			index.Files = append(index.Files, FileEntry{Path: path})
+		}
+		stored := storedIndex(index)
+		if !stored.PathsRelative {
+			t.Fatalf("%s: chemins relatifs attendus", c.root)
+		}
+		for i, file := range stored.Files {
+			if file.Path != c.rel[i] {
+				t.Errorf("%s: attendu %q, obtenu %q", c.root, c.rel[i], file.Path)
+			}
+		}
+		if index.Files[0].Path != c.paths[0] {
+			t.Errorf("l'index en mémoire ne doit pas être modifié: %q", index.Files[0].Path)
+		}
+
+		// Relecture: chemins absolus d'origine
+		absolutePaths(stored.Files, nil, stored.SourcePath)
+		for i, file := range stored.Files {
+			if file.Path != c.paths[i] {
+				t.Errorf("%s: attendu %q après relecture, obtenu %q", c.root, c.paths[i], file.Path)
+			}
+		}
+	}
+}
+
+func TestStoredIndexKeepsPathsOutsideRoot(t *testing.T) {
+	for _, index := range []*BackupIndex{
+		{SourcePath: "/srv/app", Files: []FileEntry{{Path: "/srv/app/a"}, {Path: "/srv/other/b"}}},
+		{SourcePath: "src", Files: []FileEntry{{Path: "src/a"}}},
+	} {
+		if stored := storedIndex(index); stored.PathsRelative || stored != index {
+			t.Errorf("index %s enregistré avec des chemins relatifs", index.SourcePath)
+		}
+	}
+}
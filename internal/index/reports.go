@@ -0,0 +1,42 @@
+package index
+
+import (
+	"fmt"
+
+	"bcrdf/pkg/storage"
+)
+
+// ReportPrefix regroupe les rapports de sauvegarde envoyés au stockage (report_upload)
+const ReportPrefix = "reports/"
+
+// ReportKey retourne la clé de stockage du rapport d'une sauvegarde (ext: md ou html)
+func ReportKey(backupID, ext string) string {
+	return ReportPrefix + backupID + "." + ext
+}
+
+// ReportKeys retourne les rapports envoyés pour une sauvegarde (aucun si report_upload n'était pas actif)
+func ReportKeys(client storage.Client, backupID string) ([]string, error) {
+	objects, err := client.ListObjects(ReportPrefix + backupID + ".")
+	if err != nil {
+		return nil, fmt.Errorf("error listing reports of %s: %w", backupID, err)
+	}
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	return keys, nil
+}
+
+// DeleteReports supprime les rapports d'une sauvegarde supprimée
+func DeleteReports(client storage.Client, backupID string) error {
+	keys, err := ReportKeys(client, backupID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := client.DeleteObject(key); err != nil {
+			return fmt.Errorf("error deleting report %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Schémas de clés de stockage enregistrés dans l'index (key_scheme)
+//   - "" (ancien): sha256(checksum + "_" + chemin), déterministe
+//   - uuid-v1: identifiant aléatoire de 128 bits par fichier, la correspondance chemin -> clé est dans l'index
+//
+// La restauration lit toujours la clé dans l'index: les anciennes sauvegardes restent lisibles sans migration.
+const (
+	KeySchemeLegacy = ""
+	KeySchemeUUID   = "uuid-v1"
+)
+
+// chunkSuffixPattern détecte le suffixe des chunks d'un fichier volumineux (<clé>.chunk.%03d)
+var chunkSuffixPattern = regexp.MustCompile(`\.chunk\.\d+$`)
+
+// ObjectFileKey retourne la clé de fichier à laquelle appartient un objet (chunk ou métadonnées)
+func ObjectFileKey(objectKey string) string {
+	key := strings.TrimSuffix(objectKey, ".metadata")
+	return chunkSuffixPattern.ReplaceAllString(key, "")
+}
+
+// NewStorageKey génère une clé de stockage aléatoire (128 bits, hexadécimal)
+func NewStorageKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("cannot generate storage key: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// assignStorageKey attribue une clé de stockage à une entrée; sans clé, le fichier est marqué en échec
+func assignStorageKey(entry *FileEntry) {
+	key, err := NewStorageKey()
+	if err != nil {
+		utils.Warn("Cannot back up %s: %v", entry.Path, err)
+		entry.StorageKey = ""
+		entry.Status = FileStatusFailed
+		entry.Error = err.Error()
+		return
+	}
+	entry.StorageKey = key
+}
+
+// EnsureUniqueStorageKeys attribue une nouvelle clé aux entrées dont la clé est déjà utilisée
+// dans l'index, pour qu'aucun objet n'en écrase un autre. Retourne les chemins ré-attribués.
+func EnsureUniqueStorageKeys(files []FileEntry) []string {
+	seen := make(map[string]bool, len(files))
+	var rekeyed []string
+	for i := range files {
+		key := files[i].StorageKey
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			for seen[files[i].StorageKey] {
+				assignStorageKey(&files[i])
+			}
+			rekeyed = append(rekeyed, files[i].Path)
+		}
+		seen[files[i].StorageKey] = true
+	}
+	return rekeyed
+}
+
+// DuplicateStorageKeys retourne les chemins qui partagent une clé de stockage avec une autre entrée
+// (données écrasées lors de la sauvegarde, possible avec d'anciens index). Les entrées de même
+// checksum partagent volontairement leur objet (contenu identique envoyé une seule fois) et ne
+// sont pas signalées.
+func DuplicateStorageKeys(files []FileEntry) []string {
+	groups := make(map[string][]FileEntry)
+	for _, file := range files {
+		if file.HasData() {
+			groups[file.StorageKey] = append(groups[file.StorageKey], file)
+		}
+	}
+
+	var duplicates []string
+	for _, group := range groups {
+		if len(group) < 2 || sameContent(group) {
+			continue
+		}
+		for _, file := range group {
+			duplicates = append(duplicates, file.Path)
+		}
+	}
+	return duplicates
+}
+
+// sameContent indique si toutes les entrées ont le même checksum (connu)
+func sameContent(files []FileEntry) bool {
+	for _, file := range files {
+		if file.Checksum == "" || file.Checksum != files[0].Checksum || file.Size != files[0].Size {
+			return false
+		}
+	}
+	return true
+}
+
+// DataOwners retourne les sauvegardes dont l'index lit des objets: la sienne en premier, puis celles
+// qui détiennent les objets de ses fichiers inchangés
+func (b *BackupIndex) DataOwners() []string {
+	owners := []string{b.BackupID}
+	seen := map[string]bool{b.BackupID: true}
+	var previous []string
+	for i := range b.Files {
+		if owner := b.Files[i].DataOwner(b.BackupID); b.Files[i].HasData() && !seen[owner] {
+			seen[owner] = true
+			previous = append(previous, owner)
+		}
+	}
+	sort.Strings(previous)
+	return append(owners, previous...)
+}
+
+// ListDataObjects liste les objets de données lus par l'index: tous ceux de la sauvegarde, et ceux des
+// sauvegardes précédentes référencés par ses fichiers inchangés (chunks et métadonnées compris)
+func ListDataObjects(client storage.Client, b *BackupIndex) ([]storage.ObjectInfo, error) {
+	referenced := make(map[string]bool)
+	for i := range b.Files {
+		if b.Files[i].HasData() {
+			referenced[b.Files[i].DataKey(b.BackupID)] = true
+		}
+	}
+
+	var objects []storage.ObjectInfo
+	for _, owner := range b.DataOwners() {
+		listed, err := client.ListObjects(fmt.Sprintf("data/%s/", owner))
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range listed {
+			if owner == b.BackupID || referenced[ObjectFileKey(object.Key)] {
+				objects = append(objects, object)
+			}
+		}
+	}
+	return objects, nil
+}
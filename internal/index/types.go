@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
 )
 
 // FileEntry représente une entrée dans l'index
@@ -22,18 +26,143 @@ type FileEntry struct {
 	Permissions    string    `csv:"permissions"`
 	Owner          string    `csv:"owner"`
 	Group          string    `csv:"group"`
+	Status         string    `csv:"status" json:",omitempty"`         // Statut de sauvegarde (FileStatus*)
+	Error          string    `csv:"error" json:",omitempty"`          // Raison d'un échec ou d'un fichier ignoré
+	UnicodeForm    string    `csv:"unicode_form" json:",omitempty"`   // Forme d'origine du chemin si non NFC (nfd, mixed)
+	Compression    string    `csv:"compression" json:",omitempty"`    // Compression des objets stockés (gzip, none), vide = ancienne sauvegarde
+	LinkTarget     string    `csv:"link_target" json:",omitempty"`    // Cible d'un lien symbolique enregistré tel quel (symlinks: store)
+	Handler        string    `csv:"handler" json:",omitempty"`        // Handler ayant préparé la copie sauvegardée (file_handlers)
+	Entropy        float64   `csv:"entropy" json:",omitempty"`        // Entropie du début du contenu envoyé (bits par octet, anomaly_guard)
+	DataBackupID   string    `csv:"data_backup_id" json:",omitempty"` // Sauvegarde détenant les objets d'un fichier inchangé, vide = celle de l'index
+}
+
+// Statuts d'une entrée d'index
+const (
+	FileStatusUploaded          = "uploaded"
+	FileStatusSkippedUnreadable = "skipped-unreadable"
+	FileStatusSkippedExcluded   = "skipped-excluded"
+	FileStatusFailed            = "failed"
+)
+
+// Statuts d'une sauvegarde (BackupIndex.Status)
+const (
+	BackupStatusComplete = "complete"
+	BackupStatusPartial  = "partial" // Des fichiers ont échoué (politique d'erreurs continue ou threshold)
+	BackupStatusFailed   = "failed"  // Aucun des fichiers à sauvegarder n'a pu l'être
+)
+
+// Compression appliquée aux objets stockés
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+	// CompressionDictPrefix préfixe les objets compressés avec un dictionnaire: "deflate-dict:{job}/{id}"
+	CompressionDictPrefix = "deflate-dict:"
+)
+
+// DictionaryRef retourne la référence du dictionnaire des objets du fichier, vide sans dictionnaire
+func (f *FileEntry) DictionaryRef() string {
+	if !strings.HasPrefix(f.Compression, CompressionDictPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(f.Compression, CompressionDictPrefix)
+}
+
+// DictionaryKey retourne la clé de stockage d'un dictionnaire à partir de sa référence
+func DictionaryKey(ref string) string {
+	return "dictionaries/" + ref + ".dict"
+}
+
+// IsCompressed indique si les objets du fichier sont compressés
+// Les anciennes sauvegardes n'enregistrent pas la décision: on suppose la configuration (legacyCompressed)
+func (f *FileEntry) IsCompressed(legacyCompressed bool) bool {
+	if f.Compression == "" {
+		return legacyCompressed
+	}
+	return f.Compression == CompressionGzip
+}
+
+// IsSkipped indique si le fichier est volontairement absent de la sauvegarde
+func (f *FileEntry) IsSkipped() bool {
+	return f.Status == FileStatusSkippedUnreadable || f.Status == FileStatusSkippedExcluded
+}
+
+// IsSymlink indique une entrée enregistrée comme lien symbolique (restaurée en lien vers LinkTarget)
+func (f *FileEntry) IsSymlink() bool {
+	return f.LinkTarget != ""
+}
+
+// IsMetadataOnly indique une entrée restaurée sans données (répertoire, fichier vide ou lien)
+func (f *FileEntry) IsMetadataOnly() bool {
+	if f.IsSkipped() || f.Status == FileStatusFailed {
+		return false
+	}
+	return f.IsDirectory || (f.Size == 0 && f.StorageKey == "")
+}
+
+// FileMode retourne les permissions enregistrées (format os.FileMode.String())
+func (f *FileEntry) FileMode() (os.FileMode, bool) {
+	perms := f.Permissions
+	if len(perms) < 9 {
+		return 0, false
+	}
+	perms = perms[len(perms)-9:]
+
+	var mode os.FileMode
+	for i, c := range perms {
+		if c != '-' {
+			mode |= 1 << uint(8-i)
+		}
+	}
+	return mode, true
+}
+
+// HasData indique si des données ont été sauvegardées pour ce fichier
+func (f *FileEntry) HasData() bool {
+	return f.StorageKey != "" && f.Status != FileStatusFailed && !f.IsSkipped()
+}
+
+// DataOwner retourne la sauvegarde qui détient les objets du fichier dans l'index backupID: un
+// fichier inchangé référence les objets envoyés par une sauvegarde précédente
+func (f *FileEntry) DataOwner(backupID string) string {
+	if f.DataBackupID != "" {
+		return f.DataBackupID
+	}
+	return backupID
+}
+
+// DataKey retourne la clé de l'objet de données du fichier dans l'index backupID
+// (data/{sauvegarde détentrice}/{storageKey}; un fichier chunké y ajoute .metadata et .chunk.NNN)
+func (f *FileEntry) DataKey(backupID string) string {
+	return fmt.Sprintf("data/%s/%s", f.DataOwner(backupID), f.StorageKey)
 }
 
 // BackupIndex représente un index de sauvegarde complet
 type BackupIndex struct {
-	BackupID       string      `json:"backup_id"`
-	CreatedAt      time.Time   `json:"created_at"`
-	SourcePath     string      `json:"source_path"`
-	TotalFiles     int64       `json:"total_files"`
-	TotalSize      int64       `json:"total_size"`
-	CompressedSize int64       `json:"compressed_size"`
-	EncryptedSize  int64       `json:"encrypted_size"`
-	Files          []FileEntry `json:"files"`
+	BackupID       string                 `json:"backup_id"`
+	CreatedAt      time.Time              `json:"created_at"`
+	SourcePath     string                 `json:"source_path"`
+	SourceRoots    []string               `json:"source_roots,omitempty"`   // Sources d'une sauvegarde multi-sources, sous SourcePath (leur parent commun)
+	PathsRelative  bool                   `json:"paths_relative,omitempty"` // Chemins des fichiers enregistrés relatifs à SourcePath
+	TotalFiles     int64                  `json:"total_files"`
+	TotalSize      int64                  `json:"total_size"`
+	CompressedSize int64                  `json:"compressed_size"`
+	EncryptedSize  int64                  `json:"encrypted_size"`
+	Origin         *BackupOrigin          `json:"origin,omitempty"`
+	KeyScheme      string                 `json:"key_scheme,omitempty"`    // Schéma des clés de stockage (KeyScheme*)
+	BaseID         string                 `json:"base_id,omitempty"`       // Index de base d'un index delta (index-bases/{id}.json)
+	RemovedPaths   []string               `json:"removed_paths,omitempty"` // Fichiers de la base absents de cette sauvegarde (delta)
+	Requests       *storage.RequestCounts `json:"requests,omitempty"`      // Requêtes envoyées au stockage jusqu'à l'écriture de l'index
+	ChecksumMode   string                 `json:"checksum_mode,omitempty"` // Mode des checksums de l'index, vide = fast
+	Status         string                 `json:"status,omitempty"`        // BackupStatus*, vide = complete (anciens index)
+	ImportedFrom   string                 `json:"imported_from,omitempty"` // Archive ou répertoire importé (bcrdf import), vide sinon
+	DataKeys       map[string]string      `json:"data_keys,omitempty"`     // Clé de données de chaque sauvegarde détentrice, enveloppée pour les destinataires (recipients)
+	Files          []FileEntry            `json:"files"`
+}
+
+// WrappedDataKey retourne la clé de données enveloppée qui chiffre les objets du fichier, vide
+// s'ils sont chiffrés avec encryption_key
+func (b *BackupIndex) WrappedDataKey(file FileEntry) string {
+	return b.DataKeys[file.DataOwner(b.BackupID)]
 }
 
 // BackupMetadata représente les métadonnées d'une sauvegarde
@@ -46,11 +175,32 @@ type BackupMetadata struct {
 	CompressedSize int64     `json:"compressed_size"`
 	EncryptedSize  int64     `json:"encrypted_size"`
 	Status         string    `json:"status"`
+	Hostname       string    `json:"hostname,omitempty"`
+}
+
+// NewSymlinkEntry crée l'entrée d'un lien symbolique enregistré tel quel: aucune donnée,
+// le checksum porte sur la cible pour qu'un changement de cible soit détecté
+func NewSymlinkEntry(path string, info os.FileInfo) (*FileEntry, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256([]byte("symlink:" + target))
+	return &FileEntry{
+		Path:         path,
+		ModifiedTime: info.ModTime(),
+		Checksum:     hex.EncodeToString(hash[:]),
+		Permissions:  info.Mode().String(),
+		Owner:        "unknown",
+		Group:        "unknown",
+		UnicodeForm:  DetectUnicodeForm(path),
+		LinkTarget:   target,
+	}, nil
 }
 
 // NewFileEntry creates a new file entry
 func NewFileEntry(path string, info os.FileInfo) (*FileEntry, error) {
-	return NewFileEntryWithMode(path, info, "fast")
+	return NewFileEntryWithMode(path, info, ChecksumModeFast)
 }
 
 // NewFileEntryWithMode creates a new file entry with specified checksum mode
@@ -91,6 +241,7 @@ func NewFileEntryWithModeAndCache(path string, info os.FileInfo, checksumMode st
 		Checksum:     checksum,
 		IsDirectory:  info.IsDir(),
 		Permissions:  info.Mode().String(),
+		UnicodeForm:  DetectUnicodeForm(path),
 	}
 
 	// Récupérer les informations d'utilisateur et de groupe
@@ -102,15 +253,66 @@ func NewFileEntryWithModeAndCache(path string, info os.FileInfo, checksumMode st
 	return entry, nil
 }
 
+// Modes de checksum (checksum_mode)
+const (
+	ChecksumModeFull      = "full"       // contenu complet (SHA-256)
+	ChecksumModeFast      = "fast"       // métadonnées et échantillons du contenu (défaut)
+	ChecksumModeMetadata  = "metadata"   // chemin, taille, date et permissions, sans ouvrir les fichiers
+	ChecksumModeMtimeSize = "mtime-size" // taille et date seules, sans hachage (sécurité réduite)
+)
+
+// ChecksumModeOf retourne le mode de checksum effectif d'une configuration (fast par défaut)
+func ChecksumModeOf(config *utils.Config) string {
+	if config == nil || config.Backup.ChecksumMode == "" {
+		return ChecksumModeFast
+	}
+	return config.Backup.ChecksumMode
+}
+
+// EffectiveChecksumMode retourne le mode des checksums de l'index (fast pour les anciens index)
+func (b *BackupIndex) EffectiveChecksumMode() string {
+	if b.ChecksumMode == "" {
+		return ChecksumModeFast
+	}
+	return b.ChecksumMode
+}
+
+// EffectiveStatus retourne le statut de la sauvegarde (complete pour les anciens index)
+func (b *BackupIndex) EffectiveStatus() string {
+	if b.Status == "" {
+		return BackupStatusComplete
+	}
+	return b.Status
+}
+
+// BackupStatusOf déduit le statut d'une sauvegarde du nombre de fichiers en échec
+func BackupStatusOf(failed, total int) string {
+	switch {
+	case failed == 0:
+		return BackupStatusComplete
+	case failed >= total:
+		return BackupStatusFailed
+	default:
+		return BackupStatusPartial
+	}
+}
+
+// ReducedSafety indique un mode qui ne voit pas les modifications conservant taille et date
+func ReducedSafety(mode string) bool {
+	return mode == ChecksumModeMetadata || mode == ChecksumModeMtimeSize
+}
+
 // calculateFileChecksumWithMode calculates checksum based on mode
 func calculateFileChecksumWithMode(path string, info os.FileInfo, mode string) (string, error) {
 	switch mode {
-	case "full":
+	case ChecksumModeFull:
 		return calculateFullChecksum(path)
-	case "fast":
+	case ChecksumModeFast:
 		return calculateFastChecksum(path, info)
-	case "metadata":
+	case ChecksumModeMetadata:
 		return calculateMetadataChecksum(path, info)
+	case ChecksumModeMtimeSize:
+		return mtimeSizeChecksum(info.Size(), info.ModTime()), nil
 	default:
 		return calculateFastChecksum(path, info)
 	}
@@ -191,10 +393,20 @@ func calculateMetadataChecksum(path string, info os.FileInfo) (string, error) {
 		}
 	}
 
-	// Hash based on: path + size + modtime + permissions
-	data := fmt.Sprintf("%s-%d-%d-%s", path, info.Size(), info.ModTime().Unix(), info.Mode().String())
+	return metadataChecksum(path, info.Size(), info.ModTime(), info.Mode().String()), nil
+}
+
+// metadataChecksum hashes path + size + modtime + permissions
+func metadataChecksum(path string, size int64, modTime time.Time, permissions string) string {
+	data := fmt.Sprintf("%s-%d-%d-%s", path, size, modTime.Unix(), permissions)
 	hash := sha256.Sum256([]byte(data))
-	return hex.EncodeToString(hash[:]), nil
+	return hex.EncodeToString(hash[:])
+}
+
+// mtimeSizeChecksum est l'empreinte du mode mtime-size: ni lecture ni hachage, seulement
+// la taille et la date de modification à la nanoseconde
+func mtimeSizeChecksum(size int64, modTime time.Time) string {
+	return fmt.Sprintf("mtime-size:%d:%d", size, modTime.UnixNano())
 }
 
 // calculateDirectoryChecksum calculates a checksum for a directory based on its metadata
@@ -205,7 +417,8 @@ func calculateDirectoryChecksum(path string, info os.FileInfo) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// GetStorageKey génère une clé de stockage unique pour un fichier
+// GetStorageKey retourne la clé de stockage du fichier, ou la génère avec l'ancien schéma déterministe
+// Les nouveaux index attribuent des clés aléatoires (NewStorageKey) lors de leur création
 func (f *FileEntry) GetStorageKey() string {
 	if f.StorageKey != "" {
 		return f.StorageKey
@@ -0,0 +1,54 @@
+package index
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Formes de normalisation Unicode des chemins
+const (
+	UnicodeFormOriginal = "original"
+	UnicodeFormNFC      = "nfc"
+	UnicodeFormNFD      = "nfd"
+	UnicodeFormMixed    = "mixed"
+)
+
+// PathKey retourne la clé de comparaison d'un chemin, indépendante de la normalisation (NFC)
+// Un même fichier sauvegardé depuis macOS (NFD) et Linux (NFC) a ainsi la même clé
+func PathKey(path string) string {
+	return norm.NFC.String(path)
+}
+
+// DetectUnicodeForm retourne la forme d'un chemin: "" si NFC (cas courant), sinon nfd ou mixed
+func DetectUnicodeForm(path string) string {
+	if norm.NFC.IsNormalString(path) {
+		return ""
+	}
+	if norm.NFD.IsNormalString(path) {
+		return UnicodeFormNFD
+	}
+	return UnicodeFormMixed
+}
+
+// NormalizePath applique une forme de normalisation à un chemin (original = inchangé)
+func NormalizePath(path, form string) string {
+	switch form {
+	case UnicodeFormNFC:
+		return norm.NFC.String(path)
+	case UnicodeFormNFD:
+		return norm.NFD.String(path)
+	default:
+		return path
+	}
+}
+
+// ValidateUnicodeForm vérifie une forme de normalisation demandée pour la restauration
+func ValidateUnicodeForm(form string) error {
+	switch form {
+	case "", UnicodeFormOriginal, UnicodeFormNFC, UnicodeFormNFD:
+		return nil
+	default:
+		return fmt.Errorf("invalid unicode form %q (expected original, nfc or nfd)", form)
+	}
+}
@@ -0,0 +1,70 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// restoredInfo présente un fichier restauré avec la date de modification de son entrée d'index
+type restoredInfo struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (r restoredInfo) ModTime() time.Time { return r.modTime }
+
+// VerifyRestored vérifie qu'un fichier restauré correspond à son entrée d'index (taille et checksum)
+// selon le mode de checksum de la sauvegarde (BackupIndex.EffectiveChecksumMode). Un checksum
+// complet reste accepté en mode fast, mode supposé des anciens index qui ne l'enregistrent pas.
+// En modes metadata et mtime-size, le checksum ne dépend pas du contenu: seule la taille est
+// vérifiable (la restauration ne rétablit pas les dates).
+func VerifyRestored(restoredPath string, entry FileEntry, mode string) error {
+	info, err := os.Stat(restoredPath)
+	if err != nil {
+		return err
+	}
+	if info.Size() != entry.Size {
+		return fmt.Errorf("size mismatch: %d bytes restored, %d expected", info.Size(), entry.Size)
+	}
+	if ReducedSafety(mode) {
+		return nil
+	}
+
+	if mode != ChecksumModeFull {
+		fast, err := calculateFastChecksum(restoredPath, restoredInfo{FileInfo: info, modTime: entry.ModifiedTime})
+		if err != nil {
+			return err
+		}
+		if fast == entry.Checksum {
+			return nil
+		}
+	}
+
+	full, err := calculateFullChecksum(restoredPath)
+	if err != nil {
+		return err
+	}
+	if full == entry.Checksum {
+		return nil
+	}
+	return fmt.Errorf("checksum mismatch")
+}
+
+// MatchesEntry indique si un fichier local est identique à son entrée d'index (fichier inchangé,
+// à conserver ou à lier plutôt qu'à restaurer). En modes metadata et mtime-size, une taille égale
+// ne prouve rien: la date de modification (et les permissions en mode metadata) doivent être
+// celles de l'entrée, comme lors de la sauvegarde.
+func MatchesEntry(path string, entry FileEntry, mode string) bool {
+	if !ReducedSafety(mode) {
+		return VerifyRestored(path, entry, mode) == nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != entry.Size {
+		return false
+	}
+	if mode == ChecksumModeMetadata {
+		return metadataChecksum(entry.Path, info.Size(), info.ModTime(), info.Mode().String()) == entry.Checksum
+	}
+	return mtimeSizeChecksum(info.Size(), info.ModTime()) == entry.Checksum
+}
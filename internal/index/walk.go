@@ -0,0 +1,97 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// Politiques de parcours des liens symboliques (symlinks)
+const (
+	SymlinksStore  = "store"  // enregistrer le lien lui-même (cible), sans données: restauré en lien
+	SymlinksFollow = "follow" // sauvegarder la cible: contenu des fichiers, parcours des répertoires (boucles détectées)
+	SymlinksSkip   = "skip"   // ignorer les liens
+)
+
+// isSymlink indique si une entrée du parcours est un lien symbolique
+func isSymlink(info os.FileInfo) bool {
+	return info != nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// sourceWalker parcourt la source en appliquant la politique des liens symboliques
+type sourceWalker struct {
+	policy  string
+	fn      filepath.WalkFunc
+	visited map[string]bool // Répertoires réels déjà parcourus (mode follow)
+}
+
+// walkSource parcourt root comme filepath.Walk. Selon policy, les liens sont passés tels quels
+// à fn (store), ignorés (skip) ou remplacés par leur cible (follow): les répertoires liés sont
+// alors parcourus sous le chemin du lien, chaque répertoire réel une seule fois.
+// Une source qui est elle-même un lien est toujours suivie (comme find -H).
+func walkSource(root, policy string, fn filepath.WalkFunc) error {
+	w := &sourceWalker{policy: policy, fn: fn, visited: make(map[string]bool)}
+	if info, err := os.Lstat(root); err == nil && isSymlink(info) {
+		return w.follow(root)
+	}
+	return filepath.Walk(root, w.visit)
+}
+
+// visit traite une entrée du parcours de filepath.Walk
+func (w *sourceWalker) visit(path string, info os.FileInfo, err error) error {
+	if err != nil || !isSymlink(info) {
+		if err == nil && info.IsDir() && w.policy == SymlinksFollow {
+			if real, err := filepath.EvalSymlinks(path); err == nil {
+				w.visited[real] = true
+			}
+		}
+		return w.fn(path, info, err)
+	}
+	switch w.policy {
+	case SymlinksSkip:
+		utils.Debug("Skipping symlink: %s", path)
+		return nil
+	case SymlinksFollow:
+		return w.follow(path)
+	default:
+		return w.fn(path, info, nil)
+	}
+}
+
+// follow remplace un lien par sa cible; un répertoire lié est parcouru sous le chemin du lien
+func (w *sourceWalker) follow(linkPath string) error {
+	target, err := os.Stat(linkPath)
+	if err != nil {
+		return w.fn(linkPath, nil, fmt.Errorf("broken symlink: %w", err))
+	}
+	if !target.IsDir() {
+		return w.fn(linkPath, target, nil)
+	}
+
+	real, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		return w.fn(linkPath, nil, err)
+	}
+	if w.visited[real] {
+		utils.Warn("Symlink loop or directory already backed up, not following: %s -> %s", linkPath, real)
+		return nil
+	}
+	w.visited[real] = true
+
+	// Les entrées de la cible sont présentées sous le chemin du lien
+	err = filepath.Walk(real, func(path string, info os.FileInfo, err error) error {
+		rel := strings.TrimPrefix(path, real)
+		if path == real {
+			// Le répertoire lié lui-même, avec les attributs de sa cible
+			info = target
+		}
+		return w.visit(linkPath+rel, info, err)
+	})
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// symlinkTree crée src/{file.txt, dir/inner.txt, link-file -> file.txt, link-dir -> dir, dir/loop -> ..}
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"file.txt", filepath.Join("dir", "inner.txt")} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{"link-file": "file.txt", "link-dir": "dir", filepath.Join("dir", "loop"): ".."}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Skipf("liens symboliques indisponibles: %v", err)
+		}
+	}
+	return root
+}
+
+// walkedFiles retourne les entrées non répertoires visitées, relatives à root (liens marqués "@")
+func walkedFiles(t *testing.T, root, policy string) []string {
+	t.Helper()
+	var paths []string
+	err := walkSource(root, policy, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			t.Fatalf("erreur de parcours %s: %v", path, err)
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		if isSymlink(info) {
+			rel += "@"
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestWalkSourceSymlinkPolicies(t *testing.T) {
+	root := symlinkTree(t)
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{SymlinksStore, []string{"dir/inner.txt", "dir/loop@", "file.txt", "link-dir@", "link-file@"}},
+		{SymlinksSkip, []string{"dir/inner.txt", "file.txt"}},
+		// link-dir pointe vers un répertoire déjà parcouru et dir/loop vers la racine: non suivis
+		{SymlinksFollow, []string{"dir/inner.txt", "file.txt", "link-file"}},
+	}
+	for _, tt := range tests {
+		if got := walkedFiles(t, root, tt.policy); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: obtenu %v, attendu %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestWalkSourceFollowsLinkedDirectoryOutsideSource(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(base, "outside")
+	root := filepath.Join(base, "src")
+	for _, dir := range []string{outside, root} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "shared")); err != nil {
+		t.Skipf("liens symboliques indisponibles: %v", err)
+	}
+	if err := os.Symlink(root, filepath.Join(outside, "back")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := walkedFiles(t, root, SymlinksFollow)
+	if strings.Join(got, ",") != "shared/a.txt" {
+		t.Errorf("obtenu %v, attendu [shared/a.txt]", got)
+	}
+}
+
+func TestNewSymlinkEntry(t *testing.T) {
+	root := symlinkTree(t)
+	path := filepath.Join(root, "link-file")
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := NewSymlinkEntry(path, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entry.IsSymlink() || entry.LinkTarget != "file.txt" || !entry.IsMetadataOnly() || entry.HasData() {
+		t.Errorf("entrée de lien inattendue: %+v", entry)
+	}
+}
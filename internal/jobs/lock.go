@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// LockJob empêche deux exécutions simultanées du même job sur cette machine (entrées cron qui se
+// chevauchent): le verrou est un fichier locks/<job>.lock de dir créé de façon exclusive et
+// rafraîchi tant que le processus vit. Un verrou non rafraîchi (processus tué) est repris.
+// Si le job tourne déjà, LockJob échoue (ErrLockConflict) ou, avec wait, attend sa fin.
+// Retourne la fonction qui libère le verrou.
+func LockJob(dir, job string, wait, verbose bool) (func(), error) {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	locksDir := filepath.Join(dir, "locks")
+	if err := os.MkdirAll(locksDir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating job lock directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(job))
+	lockPath := filepath.Join(locksDir, hex.EncodeToString(sum[:8])+".lock")
+	owner := Ticket{ID: utils.NewOperationID(), Job: job, QueuedAt: time.Now(), PID: os.Getpid()}
+
+	announced := false
+	for {
+		created, err := createLock(lockPath, owner)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return holdLock(lockPath), nil
+		}
+
+		holder, stale := readLock(lockPath)
+		if stale {
+			utils.Debug("Removing stale job lock %s", lockPath)
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if !wait {
+			return nil, fmt.Errorf("%w: backup %q is already running on this host (pid %d, started %s); use --wait to queue",
+				utils.ErrLockConflict, job, holder.PID, holder.QueuedAt.Format("2006-01-02 15:04:05"))
+		}
+		if !announced {
+			announced = true
+			message := fmt.Sprintf("Waiting for the running backup %q to finish (pid %d)", job, holder.PID)
+			if verbose {
+				utils.Info("⏳ %s", message)
+			} else {
+				utils.ProgressInfo(message)
+			}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// createLock crée le fichier de verrou s'il n'existe pas (false s'il est déjà pris)
+func createLock(path string, owner Ticket) (bool, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error creating job lock: %w", err)
+	}
+	data, err := json.Marshal(owner)
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return false, fmt.Errorf("error writing job lock: %w", err)
+	}
+	return true, nil
+}
+
+// readLock lit le détenteur d'un verrou; stale indique un verrou abandonné (non rafraîchi)
+func readLock(path string) (Ticket, bool) {
+	var holder Ticket
+	info, err := os.Stat(path)
+	if err != nil {
+		return holder, false // Libéré entre-temps: la prochaine tentative le prendra
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &holder)
+	}
+	return holder, time.Since(info.ModTime()) > staleAfter
+}
+
+// holdLock rafraîchit le verrou jusqu'à sa libération
+func holdLock(path string) func() {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				_ = os.Chtimes(path, now, now)
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		_ = os.Remove(path)
+	}
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestLockJobSingleInstance(t *testing.T) {
+	dir := t.TempDir()
+	release, err := LockJob(dir, "web", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Même job: refusé sans --wait, un autre job n'est pas concerné
+	if _, err := LockJob(dir, "web", false, false); !errors.Is(err, utils.ErrLockConflict) {
+		t.Fatalf("second run du même job accepté: %v", err)
+	}
+	other, err := LockJob(dir, "db", false, false)
+	if err != nil {
+		t.Fatalf("un autre job doit pouvoir démarrer: %v", err)
+	}
+	other()
+
+	acquired := make(chan func())
+	go func() {
+		second, err := LockJob(dir, "web", true, false)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- second
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("--wait ne doit pas démarrer tant que le job tourne")
+	case <-time.After(3 * time.Second):
+	}
+
+	release()
+	select {
+	case second := <-acquired:
+		second()
+	case <-time.After(5 * time.Second):
+		t.Fatal("--wait aurait dû obtenir le verrou libéré")
+	}
+}
+
+func TestLockJobTakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	release, err := LockJob(dir, "web", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// Processus tué: le verrou n'est plus rafraîchi
+	locks, _ := filepath.Glob(filepath.Join(dir, "locks", "*.lock"))
+	if len(locks) != 1 {
+		t.Fatalf("verrou attendu, obtenu %v", locks)
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	if err := os.Chtimes(locks[0], old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	takeover, err := LockJob(dir, "web", false, false)
+	if err != nil {
+		t.Fatalf("verrou abandonné non repris: %v", err)
+	}
+	takeover()
+}
@@ -0,0 +1,207 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// Coordination des sauvegardes planifiées (cron, timers systemd) qui se chevauchent: chaque
+// exécution attend un créneau dans un répertoire partagé, les priorités les plus hautes d'abord
+const (
+	heartbeatInterval = 10 * time.Second
+	staleAfter        = 6 * heartbeatInterval // Processus arrêté sans libérer son créneau
+	pollInterval      = 2 * time.Second
+)
+
+// Ticket décrit une exécution en attente ou en cours
+type Ticket struct {
+	ID       string    `json:"id"`
+	Job      string    `json:"job"`
+	Priority int       `json:"priority"`
+	QueuedAt time.Time `json:"queued_at"`
+	PID      int       `json:"pid"`
+}
+
+// Slots coordonne les exécutions via les répertoires queue/ et running/ de dir
+type Slots struct {
+	dir         string
+	maxParallel int
+}
+
+// DefaultDir est le répertoire de coordination par défaut (job_queue_dir)
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "bcrdf-jobs")
+}
+
+// NewSlots crée un coordinateur limitant les exécutions simultanées à maxParallel
+func NewSlots(dir string, maxParallel int) *Slots {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Slots{dir: dir, maxParallel: maxParallel}
+}
+
+// Acquire attend un créneau et retourne la fonction qui le libère. Tant que les créneaux
+// sont pris, les tickets sont servis par priorité décroissante puis par ordre d'arrivée.
+func (s *Slots) Acquire(job string, priority int, verbose bool) (func(), error) {
+	queueDir, runningDir := filepath.Join(s.dir, "queue"), filepath.Join(s.dir, "running")
+	for _, dir := range []string{queueDir, runningDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("error creating job queue directory: %w", err)
+		}
+	}
+
+	ticket := Ticket{ID: utils.NewOperationID(), Job: job, Priority: priority, QueuedAt: time.Now(), PID: os.Getpid()}
+	ticketPath := filepath.Join(queueDir, ticket.ID+".json")
+	if err := writeTicket(ticketPath, ticket); err != nil {
+		return nil, err
+	}
+
+	// Le ticket (puis le créneau) est rafraîchi tant que le processus vit
+	stop := make(chan struct{})
+	heartbeat := make(chan string)
+	go func() {
+		current := ticketPath
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case path := <-heartbeat:
+				current = path
+			case <-ticker.C:
+				now := time.Now()
+				_ = os.Chtimes(current, now, now)
+			}
+		}
+	}()
+	release := func(path string) func() {
+		return func() {
+			close(stop)
+			_ = os.Remove(path)
+		}
+	}
+
+	announced := false
+	for {
+		running := readTickets(runningDir)
+		if len(running) < s.maxParallel {
+			queued := readTickets(queueDir)
+			rank := position(queued, ticket.ID)
+			if rank >= 0 && rank < s.maxParallel-len(running) {
+				runningPath := filepath.Join(runningDir, ticket.ID+".json")
+				if err := writeTicket(runningPath, ticket); err != nil {
+					release(ticketPath)()
+					return nil, err
+				}
+				heartbeat <- runningPath
+				_ = os.Remove(ticketPath)
+
+				// Deux exécutions ont pu prendre le dernier créneau en même temps: elles se retirent
+				// et réessaient après un délai aléatoire
+				if len(readTickets(runningDir)) <= s.maxParallel {
+					return release(runningPath), nil
+				}
+				if err := writeTicket(ticketPath, ticket); err != nil {
+					release(runningPath)()
+					return nil, err
+				}
+				heartbeat <- ticketPath
+				_ = os.Remove(runningPath)
+				time.Sleep(time.Duration(rand.Int63n(int64(pollInterval))))
+			}
+		}
+
+		if !announced {
+			announced = true
+			message := fmt.Sprintf("Waiting for a job slot (%d running, max_parallel_jobs: %d, priority %d)", len(running), s.maxParallel, priority)
+			if verbose {
+				utils.Info("⏳ %s", message)
+			} else {
+				utils.ProgressInfo(message)
+			}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// position retourne le rang d'un ticket (priorité décroissante, puis arrivée), -1 s'il est absent
+func position(tickets []Ticket, id string) int {
+	sort.SliceStable(tickets, func(i, j int) bool {
+		if tickets[i].Priority != tickets[j].Priority {
+			return tickets[i].Priority > tickets[j].Priority
+		}
+		if !tickets[i].QueuedAt.Equal(tickets[j].QueuedAt) {
+			return tickets[i].QueuedAt.Before(tickets[j].QueuedAt)
+		}
+		return tickets[i].ID < tickets[j].ID
+	})
+	for i, ticket := range tickets {
+		if ticket.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// readTickets lit les tickets d'un répertoire et supprime ceux des processus disparus
+func readTickets(dir string) []Ticket {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var tickets []Ticket
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > staleAfter {
+			utils.Debug("Removing stale job ticket %s", path)
+			_ = os.Remove(path)
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var ticket Ticket
+		if err := json.Unmarshal(data, &ticket); err != nil {
+			continue
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets
+}
+
+// writeTicket écrit un ticket de façon atomique (fichier temporaire puis renommage)
+func writeTicket(path string, ticket Ticket) error {
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing job ticket: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Join(fmt.Errorf("error writing job ticket: %w", err), os.Remove(tmp))
+	}
+	return nil
+}
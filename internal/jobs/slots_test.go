@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPositionOrdersByPriorityThenArrival(t *testing.T) {
+	now := time.Now()
+	tickets := []Ticket{
+		{ID: "a", Priority: 0, QueuedAt: now},
+		{ID: "b", Priority: 10, QueuedAt: now.Add(time.Minute)},
+		{ID: "c", Priority: 0, QueuedAt: now.Add(-time.Minute)},
+	}
+	for id, expected := range map[string]int{"b": 0, "c": 1, "a": 2, "x": -1} {
+		if got := position(tickets, id); got != expected {
+			t.Errorf("rang de %s: %d, attendu %d", id, got, expected)
+		}
+	}
+}
+
+func TestAcquireWaitsForFreeSlot(t *testing.T) {
+	slots := NewSlots(t.TempDir(), 1)
+
+	release, err := slots.Acquire("first", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := slots.Acquire("second", 0, false)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("le second job ne doit pas démarrer tant que le créneau est pris")
+	case <-time.After(3 * time.Second):
+	}
+
+	release()
+	select {
+	case second := <-acquired:
+		second()
+	case <-time.After(5 * time.Second):
+		t.Fatal("le second job aurait dû obtenir le créneau libéré")
+	}
+}
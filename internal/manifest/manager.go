@@ -0,0 +1,276 @@
+package manifest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// FormatVersion est la version du format de manifeste
+const FormatVersion = 1
+
+// FileRecord décrit un fichier dans le manifeste
+type FileRecord struct {
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	Checksum     string    `json:"checksum"`
+	ModifiedTime time.Time `json:"modified_time"`
+}
+
+// Manifest est une liste portable et signée du contenu d'une sauvegarde
+type Manifest struct {
+	FormatVersion int          `json:"format_version"`
+	BackupID      string       `json:"backup_id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	ExportedAt    time.Time    `json:"exported_at"`
+	SourcePath    string       `json:"source_path"`
+	ChecksumMode  string       `json:"checksum_mode"`
+	TotalFiles    int64        `json:"total_files"`
+	TotalSize     int64        `json:"total_size"`
+	Files         []FileRecord `json:"files"`
+	Signature     string       `json:"signature"`
+}
+
+// VerifyResult contient le résultat de la vérification d'une copie externe
+type VerifyResult struct {
+	Verified   int
+	SizeOnly   int
+	Missing    []string
+	Mismatched []string
+}
+
+// Manager gère l'export et la vérification des manifestes
+type Manager struct {
+	config   *utils.Config
+	indexMgr *index.Manager
+}
+
+// NewManager crée un nouveau gestionnaire de manifestes
+func NewManager(config *utils.Config, indexMgr *index.Manager) *Manager {
+	return &Manager{
+		config:   config,
+		indexMgr: indexMgr,
+	}
+}
+
+// Export génère le manifeste signé d'une sauvegarde
+func (m *Manager) Export(backupID, outputPath string, verbose bool) error {
+	if verbose {
+		utils.Info("📜 Exporting manifest for backup: %s", backupID)
+	} else {
+		utils.ProgressStep(fmt.Sprintf("📜 Exporting manifest for backup: %s", backupID))
+	}
+
+	backupIndex, err := m.indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return fmt.Errorf("error loading index %s: %w", backupID, err)
+	}
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		BackupID:      backupIndex.BackupID,
+		CreatedAt:     backupIndex.CreatedAt,
+		ExportedAt:    time.Now(),
+		SourcePath:    backupIndex.SourcePath,
+		ChecksumMode:  backupIndex.EffectiveChecksumMode(),
+		TotalFiles:    backupIndex.TotalFiles,
+		TotalSize:     backupIndex.TotalSize,
+	}
+
+	for _, file := range backupIndex.Files {
+		if file.IsDirectory || file.IsSymlink() || (!file.HasData() && !file.IsMetadataOnly()) {
+			continue
+		}
+		manifest.Files = append(manifest.Files, FileRecord{
+			Path:         relativePath(file.Path, backupIndex.SourcePath),
+			Size:         file.Size,
+			Checksum:     file.Checksum,
+			ModifiedTime: file.ModifiedTime,
+		})
+	}
+
+	signature, err := m.sign(manifest)
+	if err != nil {
+		return err
+	}
+	manifest.Signature = signature
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing manifest: %w", err)
+	}
+
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("%s.manifest.json", backupID)
+	}
+	if err := utils.WriteFile(outputPath, data); err != nil {
+		return err
+	}
+
+	if verbose {
+		utils.Info("✅ Manifest written: %s (%d files)", outputPath, len(manifest.Files))
+	} else {
+		utils.ProgressSuccess(fmt.Sprintf("Manifest written: %s (%d files)", outputPath, len(manifest.Files)))
+	}
+
+	return nil
+}
+
+// Import vérifie la signature d'un manifeste et, si un chemin est fourni, la copie externe
+func (m *Manager) Import(manifestPath, copyPath string, verbose bool) error {
+	data, err := utils.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("error decoding manifest: %w", err)
+	}
+
+	if manifest.FormatVersion > FormatVersion {
+		return fmt.Errorf("unsupported manifest format version %d (max %d)", manifest.FormatVersion, FormatVersion)
+	}
+
+	expected, err := m.sign(&manifest)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(manifest.Signature)) {
+		return fmt.Errorf("%w: manifest signature is invalid: file was modified or signed with another key", utils.ErrVerificationFailed)
+	}
+
+	if verbose {
+		utils.Info("✅ Manifest signature valid: %s (%d files)", manifest.BackupID, len(manifest.Files))
+	} else {
+		utils.ProgressSuccess(fmt.Sprintf("Manifest signature valid: %s (%d files)", manifest.BackupID, len(manifest.Files)))
+	}
+
+	if copyPath == "" {
+		return nil
+	}
+
+	result := m.verifyCopy(&manifest, copyPath, verbose)
+	printVerifyResult(&manifest, copyPath, result)
+
+	if len(result.Missing) > 0 || len(result.Mismatched) > 0 {
+		return fmt.Errorf("%w: external copy does not match manifest: %d missing, %d mismatched",
+			utils.ErrVerificationFailed, len(result.Missing), len(result.Mismatched))
+	}
+
+	return nil
+}
+
+// verifyCopy compare une copie externe avec le contenu du manifeste
+func (m *Manager) verifyCopy(manifest *Manifest, copyPath string, verbose bool) *VerifyResult {
+	result := &VerifyResult{}
+
+	var progressBar *utils.ProgressBar
+	if !verbose {
+		utils.ProgressStep(fmt.Sprintf("Verifying external copy: %s", copyPath))
+		progressBar = utils.NewProgressBar(int64(len(manifest.Files)))
+	}
+
+	for i, record := range manifest.Files {
+		target := filepath.Join(copyPath, record.Path)
+
+		info, err := os.Stat(target)
+		if err != nil {
+			result.Missing = append(result.Missing, record.Path)
+		} else if info.Size() != record.Size {
+			result.Mismatched = append(result.Mismatched, record.Path)
+		} else if manifest.ChecksumMode == "full" {
+			// Seul le mode full produit un checksum dépendant uniquement du contenu
+			checksum, err := contentChecksum(target)
+			if err != nil || checksum != record.Checksum {
+				result.Mismatched = append(result.Mismatched, record.Path)
+			} else {
+				result.Verified++
+			}
+		} else {
+			result.SizeOnly++
+		}
+
+		if verbose {
+			utils.Debug("Verified %s", record.Path)
+		} else {
+			progressBar.Update(int64(i + 1))
+		}
+	}
+
+	if progressBar != nil {
+		progressBar.Finish()
+	}
+
+	return result
+}
+
+// sign calcule la signature HMAC-SHA256 du manifeste (hors champ signature)
+func (m *Manager) sign(manifest *Manifest) (string, error) {
+	if m.config.Backup.EncryptionKey == "" {
+		return "", fmt.Errorf("encryption key required to sign manifests")
+	}
+
+	unsigned := *manifest
+	unsigned.Signature = ""
+	payload, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", fmt.Errorf("error serializing manifest: %w", err)
+	}
+
+	// Clé dédiée dérivée de la clé de chiffrement
+	signingKey := sha256.Sum256([]byte("bcrdf-manifest:" + m.config.Backup.EncryptionKey))
+	mac := hmac.New(sha256.New, signingKey[:])
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// printVerifyResult affiche le résultat de la vérification
+func printVerifyResult(manifest *Manifest, copyPath string, result *VerifyResult) {
+	fmt.Printf("\n📜 Manifest verification: %s\n", manifest.BackupID)
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Copy path: %s\n", copyPath)
+	fmt.Printf("Checksum mode: %s\n", manifest.ChecksumMode)
+	fmt.Printf("Verified (content): %d\n", result.Verified)
+	fmt.Printf("Verified (size only): %d\n", result.SizeOnly)
+	fmt.Printf("Missing: %d\n", len(result.Missing))
+	fmt.Printf("Mismatched: %d\n", len(result.Mismatched))
+
+	for _, path := range result.Missing {
+		fmt.Printf("  ❌ missing: %s\n", path)
+	}
+	for _, path := range result.Mismatched {
+		fmt.Printf("  ⚠️  mismatch: %s\n", path)
+	}
+	fmt.Printf("\n")
+}
+
+// relativePath retourne le chemin relatif à la racine de sauvegarde, comme lors d'une restauration
+func relativePath(path, sourcePath string) string {
+	return index.RelativeToSource(path, sourcePath)
+}
+
+// contentChecksum calcule le SHA256 du contenu d'un fichier
+func contentChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
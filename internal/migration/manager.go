@@ -0,0 +1,417 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bcrdf/internal/crypto"
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// CurrentRepositoryVersion est la version du format de dépôt produite par cette version de BCRDF
+const CurrentRepositoryVersion = 2
+
+// Types d'opérations de migration
+const (
+	OpEncryptIndex = "encrypt-index"
+	OpRenameChunk  = "rename-chunk"
+)
+
+// Statuts d'une migration
+const (
+	StatusRunning    = "running"
+	StatusCompleted  = "completed"
+	StatusRolledBack = "rolled-back"
+)
+
+// legacyChunkPattern détecte les chunks nommés sans zéros de remplissage (ex: .chunk.7)
+var legacyChunkPattern = regexp.MustCompile(`^(.+)\.chunk\.(\d{1,2})$`)
+
+// Operation décrit une transformation unitaire appliquée au dépôt
+type Operation struct {
+	Kind      string `json:"kind"`
+	SourceKey string `json:"source_key"`
+	TargetKey string `json:"target_key"`
+	BackupKey string `json:"backup_key,omitempty"`
+	Applied   bool   `json:"applied"`
+}
+
+// Journal enregistre une migration pour permettre son annulation
+type Journal struct {
+	MigrationID   string      `json:"migration_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	TargetVersion int         `json:"target_version"`
+	Status        string      `json:"status"`
+	Operations    []Operation `json:"operations"`
+}
+
+// Manager gère la migration des anciens formats de dépôt
+type Manager struct {
+	config        *utils.Config
+	storageClient storage.Client
+	encryptor     *crypto.EncryptorV2
+}
+
+// NewManager crée un nouveau gestionnaire de migration
+func NewManager(config *utils.Config, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		storageClient: storageClient,
+	}
+}
+
+// initializeEncryptor initialise le chiffreur utilisé pour les index
+func (m *Manager) initializeEncryptor() error {
+	if m.encryptor != nil {
+		return nil
+	}
+
+	algorithm := crypto.EncryptionAlgorithm(m.config.Backup.EncryptionAlgo)
+	if algorithm == "" {
+		algorithm = crypto.AES256GCM
+	}
+
+	encryptor, err := crypto.NewEncryptorV2(m.config.Backup.EncryptionKey, algorithm)
+	if err != nil {
+		return fmt.Errorf("error initializing encryptor for migration: %w", err)
+	}
+	encryptor.SetAllowUnencrypted(m.config.Backup.AllowUnencrypted)
+	m.encryptor = encryptor
+	return nil
+}
+
+// Migrate met à jour le dépôt vers le format courant
+func (m *Manager) Migrate(dryRun, verbose bool) error {
+	if !dryRun {
+		if err := utils.CheckDeletesAllowed(m.config, "migrate"); err != nil {
+			return err
+		}
+	}
+	if verbose {
+		utils.Info("🔧 Analyzing repository layout (target version: %d)", CurrentRepositoryVersion)
+	} else {
+		utils.ProgressStep("🔧 Analyzing repository layout")
+	}
+
+	operations, err := m.planMigration(verbose)
+	if err != nil {
+		return fmt.Errorf("error planning migration: %w", err)
+	}
+
+	if len(operations) == 0 {
+		if verbose {
+			utils.Info("✅ Repository already uses format version %d, nothing to migrate", CurrentRepositoryVersion)
+		} else {
+			utils.ProgressSuccess("Repository is up to date")
+		}
+		return nil
+	}
+
+	if dryRun {
+		m.printPlan(operations)
+		return nil
+	}
+
+	journal := &Journal{
+		MigrationID:   fmt.Sprintf("migration-%s", time.Now().Format("20060102-150405")),
+		CreatedAt:     time.Now(),
+		TargetVersion: CurrentRepositoryVersion,
+		Status:        StatusRunning,
+	}
+
+	if !verbose {
+		utils.ProgressStep(fmt.Sprintf("Applying %d migration operations (%s)", len(operations), journal.MigrationID))
+	}
+
+	progressBar := utils.NewProgressBar(int64(len(operations)))
+	for i, op := range operations {
+		op.BackupKey = fmt.Sprintf("migrations/%s/backup/%s", journal.MigrationID, op.SourceKey)
+
+		if err := m.applyOperation(&op); err != nil {
+			// Sauvegarder le journal partiel pour permettre un rollback
+			journal.Operations = append(journal.Operations, op)
+			if saveErr := m.saveJournal(journal); saveErr != nil {
+				utils.Warn("Unable to save migration journal: %v", saveErr)
+			}
+			return fmt.Errorf("migration %s failed on %s (rollback with --rollback %s): %w",
+				journal.MigrationID, op.SourceKey, journal.MigrationID, err)
+		}
+
+		journal.Operations = append(journal.Operations, op)
+		if err := m.saveJournal(journal); err != nil {
+			return fmt.Errorf("error saving migration journal: %w", err)
+		}
+
+		if verbose {
+			utils.Info("   ✅ %s: %s -> %s", op.Kind, op.SourceKey, op.TargetKey)
+		} else {
+			progressBar.Update(int64(i + 1))
+		}
+	}
+	if !verbose {
+		progressBar.Finish()
+	}
+
+	journal.Status = StatusCompleted
+	if err := m.saveJournal(journal); err != nil {
+		return fmt.Errorf("error saving migration journal: %w", err)
+	}
+
+	if verbose {
+		utils.Info("✅ Migration %s completed: %d operations", journal.MigrationID, len(journal.Operations))
+	} else {
+		utils.ProgressSuccess(fmt.Sprintf("Migration %s completed: %d operations", journal.MigrationID, len(journal.Operations)))
+		utils.ProgressInfo(fmt.Sprintf("Undo with: bcrdf migrate --rollback %s", journal.MigrationID))
+	}
+
+	return nil
+}
+
+// Rollback annule une migration à partir de son journal
+func (m *Manager) Rollback(migrationID string, verbose bool) error {
+	if err := utils.CheckDeletesAllowed(m.config, "migrate --rollback"); err != nil {
+		return err
+	}
+	journal, err := m.loadJournal(migrationID)
+	if err != nil {
+		return err
+	}
+
+	if journal.Status == StatusRolledBack {
+		return fmt.Errorf("migration %s has already been rolled back", migrationID)
+	}
+
+	if verbose {
+		utils.Info("↩️  Rolling back migration %s (%d operations)", migrationID, len(journal.Operations))
+	} else {
+		utils.ProgressStep(fmt.Sprintf("↩️  Rolling back migration %s", migrationID))
+	}
+
+	// Annuler dans l'ordre inverse
+	var errors []string
+	for i := len(journal.Operations) - 1; i >= 0; i-- {
+		op := journal.Operations[i]
+		if op.BackupKey == "" {
+			continue
+		}
+
+		if err := storage.CopyObject(m.storageClient, op.BackupKey, op.SourceKey); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", op.SourceKey, err))
+			continue
+		}
+
+		if op.Applied && op.TargetKey != op.SourceKey {
+			if err := m.storageClient.DeleteObject(op.TargetKey); err != nil {
+				utils.Warn("Unable to delete migrated object %s: %v", op.TargetKey, err)
+			}
+		}
+
+		if verbose {
+			utils.Info("   ↩️  Restored %s", op.SourceKey)
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("rollback completed with %d errors: %s", len(errors), strings.Join(errors, "; "))
+	}
+
+	journal.Status = StatusRolledBack
+	if err := m.saveJournal(journal); err != nil {
+		return fmt.Errorf("error saving migration journal: %w", err)
+	}
+
+	if verbose {
+		utils.Info("✅ Migration %s rolled back", migrationID)
+	} else {
+		utils.ProgressSuccess(fmt.Sprintf("Migration %s rolled back", migrationID))
+	}
+
+	return nil
+}
+
+// planMigration liste les opérations nécessaires pour atteindre le format courant
+func (m *Manager) planMigration(verbose bool) ([]Operation, error) {
+	objects, err := m.storageClient.ListObjects("indexes/")
+	if err != nil {
+		return nil, fmt.Errorf("error listing indexes: %w", err)
+	}
+
+	var operations []Operation
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+
+		data, err := m.storageClient.Download(obj.Key)
+		if err != nil {
+			utils.Warn("Unable to download index %s: %v", obj.Key, err)
+			continue
+		}
+
+		if isPlainIndex(data) {
+			if verbose {
+				utils.Info("   📄 Plain JSON index: %s", obj.Key)
+			}
+			operations = append(operations, Operation{
+				Kind:      OpEncryptIndex,
+				SourceKey: obj.Key,
+				TargetKey: obj.Key,
+			})
+		}
+
+		backupID := strings.TrimSuffix(strings.TrimPrefix(obj.Key, "indexes/"), ".json")
+		chunkOps, err := m.planChunkRenames(backupID)
+		if err != nil {
+			utils.Warn("Unable to list data for %s: %v", backupID, err)
+			continue
+		}
+		if verbose && len(chunkOps) > 0 {
+			utils.Info("   📦 %d legacy chunk names in %s", len(chunkOps), backupID)
+		}
+		operations = append(operations, chunkOps...)
+	}
+
+	return operations, nil
+}
+
+// planChunkRenames détecte les chunks utilisant l'ancien nommage non paddé
+func (m *Manager) planChunkRenames(backupID string) ([]Operation, error) {
+	objects, err := m.storageClient.ListObjects(fmt.Sprintf("data/%s/", backupID))
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []Operation
+	for _, obj := range objects {
+		newKey, ok := legacyChunkKey(obj.Key)
+		if !ok {
+			continue
+		}
+		operations = append(operations, Operation{
+			Kind:      OpRenameChunk,
+			SourceKey: obj.Key,
+			TargetKey: newKey,
+		})
+	}
+
+	return operations, nil
+}
+
+// applyOperation sauvegarde l'objet d'origine puis applique la transformation
+func (m *Manager) applyOperation(op *Operation) error {
+	original, err := m.storageClient.Download(op.SourceKey)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %w", op.SourceKey, err)
+	}
+
+	if err := m.storageClient.Upload(op.BackupKey, original); err != nil {
+		return fmt.Errorf("error saving rollback copy: %w", err)
+	}
+
+	switch op.Kind {
+	case OpEncryptIndex:
+		if err := m.initializeEncryptor(); err != nil {
+			return err
+		}
+		encrypted, err := m.encryptor.Encrypt(original)
+		if err != nil {
+			return fmt.Errorf("error encrypting index: %w", err)
+		}
+		if err := m.storageClient.Upload(op.TargetKey, encrypted); err != nil {
+			return fmt.Errorf("error uploading encrypted index: %w", err)
+		}
+	case OpRenameChunk:
+		if err := m.storageClient.Upload(op.TargetKey, original); err != nil {
+			return fmt.Errorf("error uploading renamed chunk: %w", err)
+		}
+		if err := m.storageClient.DeleteObject(op.SourceKey); err != nil {
+			return fmt.Errorf("error deleting legacy chunk: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown migration operation: %s", op.Kind)
+	}
+
+	op.Applied = true
+	return nil
+}
+
+// printPlan affiche les opérations prévues en mode dry-run
+func (m *Manager) printPlan(operations []Operation) {
+	fmt.Printf("\n🔍 Migration plan (dry run) - target version %d\n", CurrentRepositoryVersion)
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	for i, op := range operations {
+		if op.SourceKey == op.TargetKey {
+			fmt.Printf("  [%d] %s: %s\n", i+1, op.Kind, op.SourceKey)
+		} else {
+			fmt.Printf("  [%d] %s: %s -> %s\n", i+1, op.Kind, op.SourceKey, op.TargetKey)
+		}
+	}
+	fmt.Printf("\nTotal: %d operations (no changes made)\n", len(operations))
+}
+
+// saveJournal enregistre le journal de migration dans le stockage
+func (m *Manager) saveJournal(journal *Journal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing journal: %w", err)
+	}
+	return m.storageClient.Upload(journalKey(journal.MigrationID), data)
+}
+
+// loadJournal charge le journal d'une migration
+func (m *Manager) loadJournal(migrationID string) (*Journal, error) {
+	data, err := m.storageClient.Download(journalKey(migrationID))
+	if err != nil {
+		return nil, fmt.Errorf("migration journal not found for %s: %w", migrationID, err)
+	}
+
+	var journal Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("error decoding migration journal: %w", err)
+	}
+	return &journal, nil
+}
+
+// journalKey retourne la clé de stockage du journal d'une migration
+func journalKey(migrationID string) string {
+	return fmt.Sprintf("migrations/%s/journal.json", migrationID)
+}
+
+// isPlainIndex détecte un index stocké en JSON clair (ancien format)
+func isPlainIndex(data []byte) bool {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "{") {
+		return false
+	}
+
+	var idx index.BackupIndex
+	if err := json.Unmarshal([]byte(trimmed), &idx); err != nil {
+		return false
+	}
+	return idx.BackupID != ""
+}
+
+// legacyChunkKey retourne la clé paddée d'un chunk à l'ancien format
+func legacyChunkKey(key string) (string, bool) {
+	matches := legacyChunkPattern.FindStringSubmatch(key)
+	if matches == nil {
+		return "", false
+	}
+
+	chunkNum, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return "", false
+	}
+
+	newKey := fmt.Sprintf("%s.chunk.%03d", matches[1], chunkNum)
+	if newKey == key {
+		return "", false
+	}
+	return newKey, true
+}
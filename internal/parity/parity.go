@@ -0,0 +1,483 @@
+package parity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Prefix regroupe les objets de parité des sauvegardes (backup.parity)
+const Prefix = "parity/"
+
+// Manifest décrit les bandes de parité d'une sauvegarde (objet parity/{id}/manifest.json).
+// Il n'est pas chiffré: il ne contient que des clés, des tailles et des empreintes d'objets
+// déjà chiffrés.
+type Manifest struct {
+	BackupID string   `json:"backup_id"`
+	Data     int      `json:"data"`   // Shards de données par bande (k)
+	Parity   int      `json:"parity"` // Shards de parité par bande (m)
+	Stripes  []Stripe `json:"stripes"`
+}
+
+// Stripe est une bande: jusqu'à k objets de données complétés par des zéros jusqu'à ShardSize
+type Stripe struct {
+	ShardSize int64       `json:"shard_size"`
+	Objects   []ObjectRef `json:"objects"`
+	Parity    []ObjectRef `json:"parity"`
+}
+
+// ObjectRef identifie un objet protégé et son empreinte SHA256
+type ObjectRef struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Result résume une reconstruction
+type Result struct {
+	Checked       int
+	Damaged       []string // Objets manquants ou altérés (données et parité)
+	Reconstructed []string
+	Unrecoverable []string // Objets de données des bandes ayant perdu plus d'objets que de shards de parité
+}
+
+// ManifestKey retourne la clé du manifeste de parité d'une sauvegarde
+func ManifestKey(backupID string) string {
+	return Prefix + backupID + "/manifest.json"
+}
+
+// parityKey retourne la clé d'un shard de parité
+func parityKey(backupID string, stripe, shard int) string {
+	return fmt.Sprintf("%s%s/%05d.%03d", Prefix, backupID, stripe, shard)
+}
+
+// Blocs de calcul: la parité est calculée position par position, sur des blocs de même
+// décalage dans chaque objet de la bande (lus avec DownloadRange). La mémoire utilisée est de
+// l'ordre de (k+m) blocs, quelle que soit la taille des objets.
+const (
+	defaultBlockSize = 4 * 1024 * 1024
+	minBlockSize     = 64 * 1024
+)
+
+// blockSize retourne la taille des blocs d'une bande de shards shards: memory_limit est réparti
+// entre les k+m blocs en mémoire
+func blockSize(config *utils.Config, shards int) int64 {
+	size := int64(defaultBlockSize)
+	if config == nil || config.Backup.MemoryLimit == "" {
+		return size
+	}
+	limit, err := utils.ParseSize(config.Backup.MemoryLimit)
+	if err != nil || limit <= 0 {
+		return size
+	}
+	if perShard := limit / int64(shards); perShard < size {
+		size = perShard
+	}
+	if size < minBlockSize {
+		size = minBlockSize
+	}
+	return size
+}
+
+// Protect calcule les objets de parité des données d'une sauvegarde (data/{id}/) selon
+// backup.parity: les objets sont regroupés par taille en bandes de k objets, chacune protégée par
+// m shards de parité. Les objets sont relus depuis le stockage par blocs (memory_limit), et les
+// shards de parité sont écrits dans la zone temporaire avant leur envoi.
+func Protect(client storage.Client, config *utils.Config, backupID string, verbose bool) (*Manifest, error) {
+	data, parity, err := utils.ParseParity(config.Backup.Parity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	rs, err := newCodec(data, parity)
+	if err != nil {
+		return nil, err
+	}
+	temp, err := tempdir.Open(config)
+	if err != nil {
+		return nil, err
+	}
+	defer temp.Close()
+
+	objects, err := client.ListObjects(fmt.Sprintf("data/%s/", backupID))
+	if err != nil {
+		return nil, fmt.Errorf("error listing data of %s: %w", backupID, err)
+	}
+	// Bandes d'objets de tailles voisines: moins de remplissage par des zéros
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Size != objects[j].Size {
+			return objects[i].Size < objects[j].Size
+		}
+		return objects[i].Key < objects[j].Key
+	})
+
+	block := blockSize(config, data+parity)
+	manifest := &Manifest{BackupID: backupID, Data: data, Parity: parity}
+	for start := 0; start < len(objects); start += data {
+		end := start + data
+		if end > len(objects) {
+			end = len(objects)
+		}
+		stripe, err := protectStripe(client, rs, temp, block, backupID, len(manifest.Stripes), objects[start:end])
+		if err != nil {
+			return nil, err
+		}
+		manifest.Stripes = append(manifest.Stripes, *stripe)
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding parity manifest: %w", err)
+	}
+	if err := client.Upload(ManifestKey(backupID), encoded); err != nil {
+		return nil, fmt.Errorf("error uploading parity manifest: %w", err)
+	}
+	if verbose {
+		utils.Info("🧩 Parity %d+%d: %d objects protected in %d stripes", data, parity, len(objects), len(manifest.Stripes))
+	}
+	return manifest, nil
+}
+
+// protectStripe calcule et envoie les shards de parité d'une bande, bloc par bloc
+func protectStripe(client storage.Client, rs *codec, temp *tempdir.Area, block int64, backupID string, number int, objects []storage.ObjectInfo) (*Stripe, error) {
+	stripe := &Stripe{}
+	for _, object := range objects {
+		if object.Size > stripe.ShardSize {
+			stripe.ShardSize = object.Size
+		}
+	}
+
+	sums := make([]hash.Hash, len(objects))
+	for i := range sums {
+		sums[i] = sha256.New()
+	}
+	out, err := newShardFiles(temp, rs.parity, stripe.ShardSize)
+	if err != nil {
+		return nil, err
+	}
+	defer out.remove()
+
+	shards := make([][]byte, rs.data)
+	for offset := int64(0); offset < stripe.ShardSize; offset += block {
+		length := min(block, stripe.ShardSize-offset)
+		for i := range shards {
+			shards[i] = nil
+			if i < len(objects) {
+				content, err := readBlock(client, objects[i].Key, objects[i].Size, offset, length)
+				if err != nil {
+					return nil, fmt.Errorf("error reading %s for parity: %w", objects[i].Key, err)
+				}
+				sums[i].Write(content)
+				shards[i] = content
+			}
+		}
+		// Bande incomplète ou objet plus court: les octets absents valent zéro
+		padShards(shards, length)
+		for i := range shards {
+			if shards[i] == nil {
+				shards[i] = make([]byte, length)
+			}
+		}
+		if err := out.write(rs.encode(shards)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, object := range objects {
+		stripe.Objects = append(stripe.Objects, ObjectRef{Key: object.Key, Size: object.Size, SHA256: hex.EncodeToString(sums[i].Sum(nil))})
+	}
+	for i := range out.files {
+		key := parityKey(backupID, number, i)
+		if err := out.upload(client, i, key, stripe.ShardSize); err != nil {
+			return nil, err
+		}
+		stripe.Parity = append(stripe.Parity, ObjectRef{Key: key, Size: stripe.ShardSize, SHA256: out.sum(i)})
+	}
+	return stripe, nil
+}
+
+// readBlock lit le bloc [offset, offset+length) d'un objet de taille size (vide au-delà de sa fin)
+func readBlock(client storage.Client, key string, size, offset, length int64) ([]byte, error) {
+	if offset >= size {
+		return []byte{}, nil
+	}
+	length = min(length, size-offset)
+	content, err := client.DownloadRange(key, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) != length {
+		return nil, fmt.Errorf("short read at offset %d: %d bytes instead of %d", offset, len(content), length)
+	}
+	return content, nil
+}
+
+// LoadManifest charge le manifeste de parité d'une sauvegarde (ErrObjectNotFound sans parité)
+func LoadManifest(client storage.Client, backupID string) (*Manifest, error) {
+	data, err := client.Download(ManifestKey(backupID))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing parity manifest of %s: %w", backupID, err)
+	}
+	return &manifest, nil
+}
+
+// Reconstruct vérifie les objets protégés d'une sauvegarde (présence, empreinte) et reconstruit
+// les objets manquants ou altérés à partir des autres objets de leur bande et de la parité.
+// Avec dryRun, les objets endommagés sont seulement listés.
+func Reconstruct(client storage.Client, config *utils.Config, manifest *Manifest, dryRun, verbose bool) (*Result, error) {
+	return ReconstructObjects(client, config, manifest, nil, dryRun, verbose)
+}
+
+// ReconstructObjects est Reconstruct limité aux objets de données retenus par wanted (nil = tous): les autres
+// objets absents ont été supprimés par le garbage collector (sauvegarde supprimée dont une partie des
+// objets reste référencée). Ils comptent comme perdus dans leur bande mais ne sont ni signalés ni
+// reconstruits.
+// Comme Protect, la reconstruction procède par blocs (memory_limit); les objets reconstruits sont
+// écrits dans la zone temporaire et vérifiés avant d'être renvoyés.
+func ReconstructObjects(client storage.Client, config *utils.Config, manifest *Manifest, wanted func(key string) bool, dryRun, verbose bool) (*Result, error) {
+	rs, err := newCodec(manifest.Data, manifest.Parity)
+	if err != nil {
+		return nil, err
+	}
+	temp, err := tempdir.Open(config)
+	if err != nil {
+		return nil, err
+	}
+	defer temp.Close()
+
+	block := blockSize(config, manifest.Data+manifest.Parity)
+	result := &Result{}
+	for _, stripe := range manifest.Stripes {
+		if err := reconstructStripe(client, rs, temp, block, stripe, wanted, result, dryRun, verbose); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// reconstructStripe répare une bande; les shards parité endommagés sont aussi réécrits
+func reconstructStripe(client storage.Client, rs *codec, temp *tempdir.Area, block int64, stripe Stripe, wanted func(key string) bool, result *Result, dryRun, verbose bool) error {
+	refs := make([]*ObjectRef, rs.data+rs.parity)
+	for i := range stripe.Objects {
+		refs[i] = &stripe.Objects[i]
+	}
+	for i := range stripe.Parity {
+		refs[rs.data+i] = &stripe.Parity[i]
+	}
+
+	// Vérification de chaque objet en flux (empreinte), sans le garder en mémoire
+	healthy := make([]bool, len(refs))
+	var damaged []int
+	lost := 0
+	for i, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		if intact(client, ref) {
+			healthy[i] = true
+			continue
+		}
+		lost++
+		if i < rs.data && wanted != nil && !wanted(ref.Key) {
+			continue
+		}
+		if verbose {
+			utils.Info("❌ %s damaged", ref.Key)
+		}
+		damaged = append(damaged, i)
+		result.Damaged = append(result.Damaged, ref.Key)
+	}
+	for i := range stripe.Objects {
+		if wanted == nil || wanted(stripe.Objects[i].Key) {
+			result.Checked++
+		}
+	}
+	if len(damaged) == 0 || dryRun {
+		return nil
+	}
+	if lost > rs.parity {
+		for _, i := range damaged {
+			if i < rs.data {
+				result.Unrecoverable = append(result.Unrecoverable, refs[i].Key)
+			}
+		}
+		utils.Warn("⚠️  %d objects lost in a stripe protected by %d parity shards: cannot reconstruct", lost, rs.parity)
+		return nil
+	}
+
+	out, err := newShardFiles(temp, len(damaged), stripe.ShardSize)
+	if err != nil {
+		return err
+	}
+	defer out.remove()
+
+	shards := make([][]byte, len(refs))
+	for offset := int64(0); offset < stripe.ShardSize; offset += block {
+		length := min(block, stripe.ShardSize-offset)
+		for i, ref := range refs {
+			shards[i] = nil
+			switch {
+			case ref == nil:
+				shards[i] = make([]byte, length) // Bande incomplète: shard de zéros
+			case healthy[i]:
+				content, err := readBlock(client, ref.Key, ref.Size, offset, length)
+				if err != nil {
+					return fmt.Errorf("error reading %s: %w", ref.Key, err)
+				}
+				shards[i] = content
+			}
+		}
+		padShards(shards, length)
+		if err := rs.reconstruct(shards); err != nil {
+			return err
+		}
+
+		// Seule la partie des blocs dans la taille de l'objet est écrite
+		blocks := make([][]byte, len(damaged))
+		for j, i := range damaged {
+			blocks[j] = shards[i][:max(0, min(length, refs[i].Size-offset))]
+		}
+		if err := out.write(blocks); err != nil {
+			return err
+		}
+	}
+
+	for j, i := range damaged {
+		ref := refs[i]
+		if out.sum(j) != ref.SHA256 {
+			return fmt.Errorf("reconstructed %s does not match its checksum", ref.Key)
+		}
+	}
+	for j, i := range damaged {
+		ref := refs[i]
+		if err := out.upload(client, j, ref.Key, ref.Size); err != nil {
+			return fmt.Errorf("error uploading reconstructed %s: %w", ref.Key, err)
+		}
+		result.Reconstructed = append(result.Reconstructed, ref.Key)
+		if verbose {
+			utils.Info("🧩 %s reconstructed from parity", ref.Key)
+		}
+	}
+	return nil
+}
+
+// intact indique si un objet est présent avec sa taille et son empreinte (lu en flux)
+func intact(client storage.Client, ref *ObjectRef) bool {
+	sum := sha256.New()
+	n, err := client.DownloadStream(context.Background(), ref.Key, sum)
+	return err == nil && n == ref.Size && hex.EncodeToString(sum.Sum(nil)) == ref.SHA256
+}
+
+// shardFiles accumule des shards bloc par bloc dans la zone temporaire, avec leur empreinte
+type shardFiles struct {
+	files   []*os.File
+	sums    []hash.Hash
+	release func()
+}
+
+// newShardFiles crée count fichiers de shards vides, en réservant la place de count shards de size
+// octets dans la zone temporaire (temp_max_size)
+func newShardFiles(temp *tempdir.Area, count int, size int64) (*shardFiles, error) {
+	release, err := temp.Reserve(int64(count) * size)
+	if err != nil {
+		return nil, err
+	}
+	out := &shardFiles{release: release}
+	for i := 0; i < count; i++ {
+		file, err := temp.CreateTemp("parity-*")
+		if err != nil {
+			out.remove()
+			return nil, fmt.Errorf("error creating parity scratch file: %w", err)
+		}
+		out.files = append(out.files, file)
+		out.sums = append(out.sums, sha256.New())
+	}
+	return out, nil
+}
+
+// write ajoute un bloc à chaque shard
+func (s *shardFiles) write(blocks [][]byte) error {
+	for i, block := range blocks {
+		if _, err := s.files[i].Write(block); err != nil {
+			return fmt.Errorf("error writing parity scratch file: %w", err)
+		}
+		s.sums[i].Write(block)
+	}
+	return nil
+}
+
+// sum retourne l'empreinte SHA256 du shard i
+func (s *shardFiles) sum(i int) string {
+	return hex.EncodeToString(s.sums[i].Sum(nil))
+}
+
+// upload envoie le shard i sous key en flux
+func (s *shardFiles) upload(client storage.Client, i int, key string, size int64) error {
+	if _, err := s.files[i].Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := client.UploadStream(context.Background(), key, s.files[i], size); err != nil {
+		return fmt.Errorf("error uploading parity %s: %w", key, err)
+	}
+	return nil
+}
+
+// remove ferme et supprime les fichiers de shards
+func (s *shardFiles) remove() {
+	for _, file := range s.files {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	s.release()
+}
+
+// Delete supprime les objets de parité d'une sauvegarde supprimée
+func Delete(client storage.Client, backupID string) error {
+	objects, err := client.ListObjects(Prefix + backupID + "/")
+	if err != nil {
+		return fmt.Errorf("error listing parity of %s: %w", backupID, err)
+	}
+	for _, object := range objects {
+		if err := client.DeleteObject(object.Key); err != nil {
+			return fmt.Errorf("error deleting parity %s: %w", object.Key, err)
+		}
+	}
+	return nil
+}
+
+// BackupIDFromKey retourne la sauvegarde à laquelle appartient un objet de parité
+func BackupIDFromKey(key string) string {
+	backupID, _, _ := strings.Cut(strings.TrimPrefix(key, Prefix), "/")
+	return backupID
+}
+
+// IsParityKey indique si une clé appartient aux objets de parité
+func IsParityKey(key string) bool {
+	return strings.HasPrefix(key, Prefix)
+}
+
+// padShards complète les shards de données par des zéros jusqu'à size (les shards nil, à
+// reconstruire, sont laissés tels quels)
+func padShards(shards [][]byte, size int64) {
+	for i, shard := range shards {
+		if shard != nil && int64(len(shard)) < size {
+			padded := make([]byte, size)
+			copy(padded, shard)
+			shards[i] = padded
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package parity
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// testConfig retourne une configuration de parité scheme, avec une zone temporaire propre au test
+func testConfig(t *testing.T, scheme string) *utils.Config {
+	config := &utils.Config{}
+	config.Backup.Parity = scheme
+	config.Backup.TempDir = t.TempDir()
+	return config
+}
+
+func TestCodecReconstructsAnyLostShards(t *testing.T) {
+	rs, err := newCodec(4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := rand.New(rand.NewSource(1))
+	data := make([][]byte, 4)
+	for i := range data {
+		data[i] = make([]byte, 64)
+		random.Read(data[i])
+	}
+	shards := append(append([][]byte{}, data...), rs.encode(data)...)
+
+	// Toutes les paires de shards perdus sont reconstructibles
+	for a := 0; a < len(shards); a++ {
+		for b := a + 1; b < len(shards); b++ {
+			damaged := append([][]byte{}, shards...)
+			damaged[a], damaged[b] = nil, nil
+			if err := rs.reconstruct(damaged); err != nil {
+				t.Fatalf("perte des shards %d et %d: %v", a, b, err)
+			}
+			for i := range shards {
+				if !bytes.Equal(damaged[i], shards[i]) {
+					t.Fatalf("shard %d mal reconstruit après la perte de %d et %d", i, a, b)
+				}
+			}
+		}
+	}
+
+	damaged := append([][]byte{}, shards...)
+	damaged[0], damaged[1], damaged[2] = nil, nil, nil
+	if err := rs.reconstruct(damaged); err == nil {
+		t.Error("erreur attendue avec 3 shards perdus pour 2 de parité")
+	}
+}
+
+func TestProtectAndReconstruct(t *testing.T) {
+	client := storage.MemoryStore(t.Name())
+	contents := map[string][]byte{}
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("data/b1/object%d", i)
+		contents[key] = bytes.Repeat([]byte{byte('a' + i)}, 100+i*37)
+		if err := client.Upload(key, contents[key]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := testConfig(t, "3+1")
+	manifest, err := Protect(client, config, "b1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Stripes) != 2 {
+		t.Fatalf("2 bandes attendues pour 5 objets en 3+1, obtenu %d", len(manifest.Stripes))
+	}
+
+	// Un objet perdu et un objet altéré, dans deux bandes différentes
+	lost, altered := manifest.Stripes[0].Objects[1].Key, manifest.Stripes[1].Objects[0].Key
+	if err := client.DeleteObject(lost); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload(altered, []byte("corrompu")); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadManifest(client, "b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Reconstruct(client, config, loaded, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Reconstructed) != 2 || len(result.Unrecoverable) != 0 {
+		t.Fatalf("2 objets reconstruits attendus: %+v", result)
+	}
+	for key, content := range contents {
+		got, err := client.Download(key)
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("%s non restauré à l'identique (%v)", key, err)
+		}
+	}
+
+	if err := Delete(client, "b1"); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := client.ListObjects(Prefix); len(objects) != 0 {
+		t.Errorf("objets de parité restants: %d", len(objects))
+	}
+}
+
+func TestReconstructObjectsIgnoresCollectedObjects(t *testing.T) {
+	client := storage.MemoryStore(t.Name())
+	for i := 0; i < 3; i++ {
+		if err := client.Upload(fmt.Sprintf("data/b1/object%d", i), bytes.Repeat([]byte{byte('a' + i)}, 64)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := testConfig(t, "3+1")
+	manifest, err := Protect(client, config, "b1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// object0 a été retiré par le garbage collector, object1 est perdu: deux pertes pour un shard de parité
+	wanted := func(key string) bool { return key != "data/b1/object0" }
+	for _, key := range []string{"data/b1/object0", "data/b1/object1"} {
+		if err := client.DeleteObject(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := ReconstructObjects(client, config, manifest, wanted, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Damaged) != 1 || result.Damaged[0] != "data/b1/object1" || len(result.Unrecoverable) != 1 {
+		t.Fatalf("seul object1 doit être signalé, et irrécupérable: %+v", result)
+	}
+	if result.Checked != 2 {
+		t.Errorf("2 objets vérifiés attendus, obtenu %d", result.Checked)
+	}
+
+	// Sans autre perte, l'objet collecté n'est ni signalé ni recréé
+	if err := client.Upload("data/b1/object1", bytes.Repeat([]byte{'b'}, 64)); err != nil {
+		t.Fatal(err)
+	}
+	result, err = ReconstructObjects(client, config, manifest, wanted, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Damaged) != 0 || len(result.Reconstructed) != 0 {
+		t.Fatalf("aucun dommage attendu: %+v", result)
+	}
+	if _, err := client.Stat("data/b1/object0"); err == nil {
+		t.Error("l'objet collecté ne doit pas être recréé")
+	}
+}
+
+func TestProtectAndReconstructByBlocks(t *testing.T) {
+	client := storage.MemoryStore(t.Name())
+	random := rand.New(rand.NewSource(2))
+	var contents [][]byte
+	for i := 0; i < 3; i++ {
+		content := make([]byte, 3*minBlockSize+1000*i)
+		random.Read(content)
+		contents = append(contents, content)
+		if err := client.Upload(fmt.Sprintf("data/b1/object%d", i), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// memory_limit réparti sur 4 shards: blocs de 64 Ko, plusieurs par objet
+	config := testConfig(t, "3+1")
+	config.Backup.MemoryLimit = "256KB"
+	if size := blockSize(config, 4); size != minBlockSize {
+		t.Fatalf("blocs de %d octets attendus, obtenu %d", minBlockSize, size)
+	}
+	manifest, err := Protect(client, config, "b1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// La parité par blocs est identique à celle calculée sur les objets entiers
+	rs, err := newCodec(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shards := append([][]byte{}, contents...)
+	padShards(shards, manifest.Stripes[0].ShardSize)
+	parity, err := client.Download(manifest.Stripes[0].Parity[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parity, rs.encode(shards)[0]) {
+		t.Fatal("parité par blocs différente de la parité des objets entiers")
+	}
+
+	lost := manifest.Stripes[0].Objects[2].Key
+	if err := client.DeleteObject(lost); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Reconstruct(client, config, manifest, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Reconstructed) != 1 {
+		t.Fatalf("1 objet reconstruit attendu: %+v", result)
+	}
+	for i, content := range contents {
+		got, err := client.Download(fmt.Sprintf("data/b1/object%d", i))
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("object%d non restauré à l'identique (%v)", i, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package parity
+
+import (
+	"fmt"
+)
+
+// Code de Reed-Solomon systématique sur GF(2^8) (polynôme 0x11d): les k shards de données sont
+// stockés tels quels et m shards de parité sont calculés avec une matrice de Cauchy, dont toute
+// sous-matrice carrée est inversible. N'importe quels k shards parmi les k+m suffisent donc à
+// reconstruire les autres.
+
+// MaxShards est le nombre maximal de shards (données + parité) d'une bande
+const MaxShards = 256
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+// gfMul multiplie deux éléments de GF(2^8)
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfInv retourne l'inverse d'un élément non nul de GF(2^8)
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// codec encode et reconstruit les bandes d'un schéma k+m
+type codec struct {
+	data, parity int
+	matrix       [][]byte // k+m lignes: identité puis matrice de Cauchy
+}
+
+// newCodec crée le codec d'un schéma data+parity
+func newCodec(data, parity int) (*codec, error) {
+	if data < 1 || parity < 1 || data+parity > MaxShards {
+		return nil, fmt.Errorf("invalid parity scheme %d+%d (at most %d shards)", data, parity, MaxShards)
+	}
+	c := &codec{data: data, parity: parity, matrix: make([][]byte, data+parity)}
+	for i := 0; i < data; i++ {
+		c.matrix[i] = make([]byte, data)
+		c.matrix[i][i] = 1
+	}
+	for i := 0; i < parity; i++ {
+		row := make([]byte, data)
+		for j := 0; j < data; j++ {
+			row[j] = gfInv(byte(data+i) ^ byte(j))
+		}
+		c.matrix[data+i] = row
+	}
+	return c, nil
+}
+
+// encode calcule les shards de parité; les shards de données doivent avoir la même taille
+func (c *codec) encode(shards [][]byte) [][]byte {
+	size := len(shards[0])
+	parity := make([][]byte, c.parity)
+	for i := range parity {
+		parity[i] = make([]byte, size)
+		mulAdd(parity[i], c.matrix[c.data+i], shards)
+	}
+	return parity
+}
+
+// reconstruct complète les shards nil (données puis parité) à partir des shards présents
+func (c *codec) reconstruct(shards [][]byte) error {
+	if len(shards) != c.data+c.parity {
+		return fmt.Errorf("expected %d shards, got %d", c.data+c.parity, len(shards))
+	}
+
+	// Les k premiers shards présents et les lignes correspondantes de la matrice
+	var rows [][]byte
+	var inputs [][]byte
+	for i, shard := range shards {
+		if shard != nil && len(rows) < c.data {
+			rows = append(rows, c.matrix[i])
+			inputs = append(inputs, shard)
+		}
+	}
+	if len(rows) < c.data {
+		return fmt.Errorf("too many lost shards: %d available, %d needed", len(rows), c.data)
+	}
+
+	decode, err := invert(rows)
+	if err != nil {
+		return err
+	}
+	size := len(inputs[0])
+	for i := 0; i < c.data; i++ {
+		if shards[i] == nil {
+			shards[i] = make([]byte, size)
+			mulAdd(shards[i], decode[i], inputs)
+		}
+	}
+	for i := 0; i < c.parity; i++ {
+		if shards[c.data+i] == nil {
+			shards[c.data+i] = make([]byte, size)
+			mulAdd(shards[c.data+i], c.matrix[c.data+i], shards[:c.data])
+		}
+	}
+	return nil
+}
+
+// mulAdd ajoute à out la combinaison linéaire des shards par les coefficients de row
+func mulAdd(out []byte, row []byte, shards [][]byte) {
+	for j, coefficient := range row {
+		if coefficient == 0 {
+			continue
+		}
+		logC := int(gfLog[coefficient])
+		for n, b := range shards[j] {
+			if b != 0 {
+				out[n] ^= gfExp[logC+int(gfLog[b])]
+			}
+		}
+	}
+}
+
+// invert inverse une matrice carrée de GF(2^8) (élimination de Gauss-Jordan)
+func invert(matrix [][]byte) ([][]byte, error) {
+	n := len(matrix)
+	work := make([][]byte, n)
+	for i := range work {
+		work[i] = make([]byte, 2*n)
+		copy(work[i], matrix[i])
+		work[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for pivot < n && work[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, fmt.Errorf("singular parity matrix")
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+		scale := gfInv(work[col][col])
+		for j := range work[col] {
+			work[col][j] = gfMul(work[col][j], scale)
+		}
+		for i := 0; i < n; i++ {
+			if i == col || work[i][col] == 0 {
+				continue
+			}
+			factor := work[i][col]
+			for j := range work[i] {
+				work[i][j] ^= gfMul(factor, work[col][j])
+			}
+		}
+	}
+	inverse := make([][]byte, n)
+	for i := range work {
+		inverse[i] = work[i][n:]
+	}
+	return inverse, nil
+}
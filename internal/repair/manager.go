@@ -0,0 +1,422 @@
+package repair
+
+import (
+	"fmt"
+	"strings"
+
+	"bcrdf/internal/crypto"
+	"bcrdf/internal/index"
+	"bcrdf/internal/parity"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// objectState décrit l'état d'un objet de données dans un dépôt
+type objectState int
+
+const (
+	objectValid objectState = iota
+	objectMissing
+	objectCorrupt
+)
+
+// String retourne le libellé de l'état pour le rapport
+func (s objectState) String() string {
+	switch s {
+	case objectMissing:
+		return "missing"
+	case objectCorrupt:
+		return "corrupt"
+	}
+	return "valid"
+}
+
+// Damage décrit un objet endommagé du dépôt principal
+type Damage struct {
+	Path     string // Fichier de la sauvegarde concerné (vide pour la parité)
+	Key      string
+	State    string // missing, corrupt
+	Repaired bool
+	Error    string // Raison de l'échec de la réparation
+}
+
+// Result résume une réparation
+type Result struct {
+	BackupID      string
+	IndexRepaired bool
+	FilesChecked  int
+	Damaged       []Damage
+	DryRun        bool
+}
+
+// Unrepaired retourne le nombre d'objets endommagés qui n'ont pas pu être réparés
+func (r *Result) Unrepaired() int {
+	count := 0
+	for _, damage := range r.Damaged {
+		if !damage.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// Manager répare les objets d'une sauvegarde à partir d'un dépôt réplique: une copie à l'identique
+// du dépôt (réplication du bucket, rclone sync...), chiffrée avec la même clé
+type Manager struct {
+	config  *utils.Config
+	primary storage.Client
+	replica storage.Client
+	keyring *crypto.Keyring
+}
+
+// NewManager crée un gestionnaire de réparation; les objets des deux dépôts sont vérifiés avec la
+// clé de la configuration principale (et identity_file pour les sauvegardes chiffrées vers des
+// destinataires). replica est nil pour une réparation par la parité seule.
+func NewManager(config *utils.Config, primary, replica storage.Client) (*Manager, error) {
+	algorithm := crypto.EncryptionAlgorithm(config.Backup.EncryptionAlgo)
+	if algorithm == "" {
+		algorithm = crypto.AES256GCM
+	}
+	encryptor, err := crypto.NewEncryptorV2(config.Backup.EncryptionKey, algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing encryptor: %w", err)
+	}
+	encryptor.SetAllowUnencrypted(config.Backup.AllowUnencrypted)
+	keyring, err := crypto.NewKeyring(encryptor, algorithm, config.Backup.IdentityFile)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing encryptor: %w", err)
+	}
+	return &Manager{config: config, primary: primary, replica: replica, keyring: keyring}, nil
+}
+
+// Repair vérifie chaque objet de données de la sauvegarde dans le dépôt principal (présence,
+// déchiffrement, empreintes des chunks) et remplace les objets manquants ou corrompus par la
+// copie de la réplique, après l'avoir vérifiée. Un index illisible est aussi recopié.
+// Avec dryRun, les objets endommagés sont seulement listés.
+func (m *Manager) Repair(backupID string, dryRun, verbose bool) (*Result, error) {
+	result := &Result{BackupID: backupID, DryRun: dryRun}
+
+	backupIndex, err := m.loadIndex(backupID, result, verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	var progressBar *utils.ProgressBar
+	if !verbose && len(backupIndex.Files) > 0 {
+		progressBar = utils.NewProgressBar(int64(len(backupIndex.Files)))
+	}
+	for _, file := range backupIndex.Files {
+		if progressBar != nil {
+			progressBar.Add(1)
+		}
+		if !file.HasData() {
+			continue
+		}
+		result.FilesChecked++
+		if err := m.repairFile(backupIndex, file, result, verbose); err != nil {
+			if progressBar != nil {
+				progressBar.Finish()
+			}
+			return result, err
+		}
+	}
+	if progressBar != nil {
+		progressBar.Finish()
+	}
+
+	if dryRun && (len(result.Damaged) > 0 || result.IndexRepaired) {
+		return result, fmt.Errorf("%w: %d damaged objects in %s", utils.ErrVerificationFailed, len(result.Damaged), backupID)
+	}
+	if unrepaired := result.Unrepaired(); unrepaired > 0 {
+		return result, fmt.Errorf("%w: %d damaged objects could not be repaired from the replica", utils.ErrPartialFailure, unrepaired)
+	}
+	return result, nil
+}
+
+// RepairFromParity reconstruit les objets manquants ou altérés de la sauvegarde à partir de ses
+// objets de parité (backup.parity), sans second dépôt. Jusqu'à m objets par bande de k+m sont
+// reconstructibles. Les objets des fichiers inchangés sont reconstruits à partir de la parité des
+// sauvegardes précédentes qui les détiennent.
+func (m *Manager) RepairFromParity(backupID string, dryRun, verbose bool) (*Result, error) {
+	manifest, err := parity.LoadManifest(m.primary, backupID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: backup %s has no parity objects (backup.parity), repair it from a replica with --from: %w",
+			utils.ErrConfig, backupID, err)
+	}
+	reconstructed, err := parity.Reconstruct(m.primary, m.config, manifest, dryRun, verbose)
+	if reconstructed == nil {
+		return nil, err
+	}
+
+	// Objets des fichiers inchangés: parité de la sauvegarde qui les détient, limitée aux objets
+	// référencés (ceux que le garbage collector a retirés d'une sauvegarde supprimée sont ignorés)
+	backupIndex, indexErr := index.NewManagerWithClient(m.config, m.primary).LoadIndex(backupID)
+	if indexErr != nil {
+		utils.Warn("⚠️  Index of %s unreadable, repairing its own objects only: %v", backupID, indexErr)
+	}
+	if err == nil && indexErr == nil {
+		referenced := make(map[string]bool)
+		for _, file := range backupIndex.Files {
+			if file.HasData() {
+				referenced[file.DataKey(backupID)] = true
+			}
+		}
+		wanted := func(key string) bool { return referenced[index.ObjectFileKey(key)] }
+		for _, owner := range backupIndex.DataOwners()[1:] {
+			ownerManifest, loadErr := parity.LoadManifest(m.primary, owner)
+			if loadErr != nil {
+				utils.Warn("⚠️  Backup %s holds unchanged files of %s but has no parity objects: %v", owner, backupID, loadErr)
+				continue
+			}
+			partial, reconstructErr := parity.ReconstructObjects(m.primary, m.config, ownerManifest, wanted, dryRun, verbose)
+			if partial == nil {
+				return nil, reconstructErr
+			}
+			reconstructed.Checked += partial.Checked
+			reconstructed.Damaged = append(reconstructed.Damaged, partial.Damaged...)
+			reconstructed.Reconstructed = append(reconstructed.Reconstructed, partial.Reconstructed...)
+			reconstructed.Unrecoverable = append(reconstructed.Unrecoverable, partial.Unrecoverable...)
+			if err = reconstructErr; err != nil {
+				break
+			}
+		}
+	}
+	result := &Result{BackupID: backupID, DryRun: dryRun, FilesChecked: reconstructed.Checked}
+	repaired := make(map[string]bool, len(reconstructed.Reconstructed))
+	for _, key := range reconstructed.Reconstructed {
+		repaired[key] = true
+	}
+	lost := make(map[string]bool, len(reconstructed.Unrecoverable))
+	for _, key := range reconstructed.Unrecoverable {
+		lost[key] = true
+	}
+	for _, key := range reconstructed.Damaged {
+		damage := Damage{Key: key, State: "damaged", Repaired: repaired[key]}
+		if lost[key] {
+			damage.Error = "too many objects lost in its parity stripe"
+		}
+		result.Damaged = append(result.Damaged, damage)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if dryRun && len(result.Damaged) > 0 {
+		return result, fmt.Errorf("%w: %d damaged objects in %s", utils.ErrVerificationFailed, len(result.Damaged), backupID)
+	}
+	if unrepaired := result.Unrepaired(); unrepaired > 0 && !dryRun {
+		return result, fmt.Errorf("%w: %d damaged objects could not be reconstructed from parity", utils.ErrPartialFailure, unrepaired)
+	}
+	return result, nil
+}
+
+// loadIndex charge l'index depuis le dépôt principal, sinon depuis la réplique (et le recopie)
+func (m *Manager) loadIndex(backupID string, result *Result, verbose bool) (*index.BackupIndex, error) {
+	backupIndex, err := index.NewManagerWithClient(m.config, m.primary).LoadIndex(backupID)
+	if err == nil {
+		return backupIndex, nil
+	}
+	utils.Warn("⚠️  Index of %s unreadable in the primary repository: %v", backupID, err)
+
+	backupIndex, replicaErr := index.NewManagerWithClient(m.config, m.replica).LoadIndex(backupID)
+	if replicaErr != nil {
+		return nil, fmt.Errorf("%w: index of %s unreadable in both repositories: %w", utils.ErrVerificationFailed, backupID, replicaErr)
+	}
+	keys := []string{fmt.Sprintf("indexes/%s.json", backupID)}
+	if backupIndex.BaseID != "" {
+		keys = append(keys, index.IndexBaseKey(backupIndex.BaseID))
+	}
+	if !result.DryRun {
+		for _, key := range keys {
+			if err := m.copyObject(key); err != nil {
+				return nil, fmt.Errorf("error restoring index %s from the replica: %w", key, err)
+			}
+			if verbose {
+				utils.Info("🔧 Index object %s copied from the replica", key)
+			}
+		}
+	}
+	result.IndexRepaired = true
+	return backupIndex, nil
+}
+
+// repairFile vérifie les objets d'un fichier et répare ceux qui sont endommagés
+func (m *Manager) repairFile(backupIndex *index.BackupIndex, file index.FileEntry, result *Result, verbose bool) error {
+	encryptor, err := m.keyring.Encryptor(backupIndex.WrappedDataKey(file))
+	if err != nil {
+		return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	// Le tag d'authentification détecte toute altération d'un objet
+	verifyEncrypted := func(data []byte) error {
+		_, err := encryptor.Decrypt(data)
+		return err
+	}
+
+	key := file.DataKey(backupIndex.BackupID)
+	for _, object := range m.damagedObjects(key, verifyEncrypted) {
+		damage := Damage{Path: file.Path, Key: object.key, State: object.state.String()}
+		if verbose {
+			utils.Info("❌ %s: %s (%s)", file.Path, object.key, damage.State)
+		}
+		if !result.DryRun {
+			if err := m.repairObject(object); err != nil {
+				damage.Error = err.Error()
+				utils.Warn("⚠️  Cannot repair %s: %v", object.key, err)
+			} else {
+				damage.Repaired = true
+				if verbose {
+					utils.Info("🔧 %s copied from the replica", object.key)
+				}
+			}
+		}
+		result.Damaged = append(result.Damaged, damage)
+	}
+	return nil
+}
+
+// damagedObject est un objet endommagé du dépôt principal; verify valide la copie de la réplique
+type damagedObject struct {
+	key    string
+	state  objectState
+	verify func(data []byte) error
+}
+
+// damagedObjects retourne les objets endommagés d'un fichier: l'objet lui-même, ou pour un
+// fichier chunké ses métadonnées et ses chunks. verifyEncrypted vérifie un objet par déchiffrement.
+func (m *Manager) damagedObjects(key string, verifyEncrypted func(data []byte) error) []damagedObject {
+	data, err := m.primary.Download(key)
+	if err == nil {
+		if err := verifyEncrypted(data); err != nil {
+			utils.Debug("%s: %v", key, err)
+			return []damagedObject{{key, objectCorrupt, verifyEncrypted}}
+		}
+		return nil
+	}
+	utils.Debug("%s: %v", key, err)
+
+	// Un fichier chunké n'a que ses métadonnées et ses chunks; si les métadonnées sont
+	// endommagées, celles de la réplique décrivent les chunks
+	metadataKey := key + ".metadata"
+	verifyMetadata := func(data []byte) error {
+		_, err := index.ParseChunkMetadata(data)
+		return err
+	}
+	var damaged []damagedObject
+	metadata, state := m.loadChunkMetadata(m.primary, metadataKey)
+	if state != objectValid {
+		replicaMetadata, replicaState := m.loadChunkMetadata(m.replica, metadataKey)
+		if state == objectMissing && replicaState != objectValid {
+			return []damagedObject{{key, objectMissing, verifyEncrypted}}
+		}
+		damaged = append(damaged, damagedObject{metadataKey, state, verifyMetadata})
+		if replicaState != objectValid {
+			return damaged
+		}
+		metadata = replicaMetadata
+	}
+
+	for chunk := 0; chunk < metadata.Chunks; chunk++ {
+		chunkKey := fmt.Sprintf("%s.chunk.%03d", key, chunk)
+		verifyChunk := chunkVerifier(metadata, chunk, verifyEncrypted)
+		data, err := m.primary.Download(chunkKey)
+		if err != nil {
+			damaged = append(damaged, damagedObject{chunkKey, objectMissing, verifyChunk})
+			continue
+		}
+		if err := verifyChunk(data); err != nil {
+			utils.Debug("%s: %v", chunkKey, err)
+			damaged = append(damaged, damagedObject{chunkKey, objectCorrupt, verifyChunk})
+		}
+	}
+	return damaged
+}
+
+// loadChunkMetadata charge les métadonnées d'un fichier chunké depuis un dépôt
+func (m *Manager) loadChunkMetadata(client storage.Client, key string) (*index.ChunkMetadata, objectState) {
+	data, err := client.Download(key)
+	if err != nil {
+		return nil, objectMissing
+	}
+	metadata, err := index.ParseChunkMetadata(data)
+	if err != nil {
+		utils.Debug("%s: %v", key, err)
+		return nil, objectCorrupt
+	}
+	return metadata, objectValid
+}
+
+// chunkVerifier vérifie un chunk avec son empreinte, ou par déchiffrement pour les anciennes sauvegardes
+func chunkVerifier(metadata *index.ChunkMetadata, chunk int, verifyEncrypted func(data []byte) error) func(data []byte) error {
+	return func(data []byte) error {
+		if len(metadata.Checksums) > 0 {
+			return metadata.VerifyStored(chunk, data)
+		}
+		return verifyEncrypted(data)
+	}
+}
+
+// repairObject remplace un objet du dépôt principal par la copie de la réplique, après l'avoir vérifiée
+func (m *Manager) repairObject(object damagedObject) error {
+	data, err := m.replica.Download(object.key)
+	if err != nil {
+		return fmt.Errorf("replica copy unavailable: %w", err)
+	}
+	if err := object.verify(data); err != nil {
+		return fmt.Errorf("replica copy is corrupt too: %w", err)
+	}
+	if err := m.primary.Upload(object.key, data); err != nil {
+		return fmt.Errorf("error uploading %s: %w", object.key, err)
+	}
+	return nil
+}
+
+// copyObject recopie un objet de la réplique vers le dépôt principal
+func (m *Manager) copyObject(key string) error {
+	data, err := m.replica.Download(key)
+	if err != nil {
+		return fmt.Errorf("error downloading %s from the replica: %w", key, err)
+	}
+	if err := m.primary.Upload(key, data); err != nil {
+		return fmt.Errorf("error uploading %s: %w", key, err)
+	}
+	return nil
+}
+
+// PrintResult affiche les objets endommagés et le résultat de la réparation
+func PrintResult(result *Result) {
+	fmt.Printf("\n🔧 Repair of %s\n", result.BackupID)
+	fmt.Printf("%s\n", strings.Repeat("-", 80))
+	if result.IndexRepaired {
+		if result.DryRun {
+			fmt.Printf("Index: unreadable, would be copied from the replica\n")
+		} else {
+			fmt.Printf("Index: copied from the replica\n")
+		}
+	}
+	fmt.Printf("Checked: %d, damaged objects: %d\n", result.FilesChecked, len(result.Damaged))
+	for _, damage := range result.Damaged {
+		status := "would be repaired"
+		switch {
+		case damage.Repaired:
+			status = "repaired"
+		case damage.Error != "":
+			status = "NOT repaired: " + damage.Error
+		}
+		if damage.Path != "" {
+			fmt.Printf("  - %s [%s] %s: %s\n", damage.Path, damage.State, damage.Key, status)
+		} else {
+			fmt.Printf("  - [%s] %s: %s\n", damage.State, damage.Key, status)
+		}
+	}
+	switch {
+	case len(result.Damaged) == 0 && !result.IndexRepaired:
+		fmt.Printf("✅ No damaged object\n")
+	case result.DryRun:
+		fmt.Printf("🔍 Dry run: nothing was written\n")
+	case result.Unrepaired() == 0:
+		fmt.Printf("✅ All damaged objects repaired from the replica\n")
+	default:
+		fmt.Printf("❌ %d damaged objects could not be repaired\n", result.Unrepaired())
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,225 @@
+package restore
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// ExportStdout désigne la sortie standard comme destination d'une archive
+const ExportStdout = "-"
+
+// ExportArchive écrit une sauvegarde (limitée aux filtres de chemins) dans une archive tar.gz
+// standard, lisible sans bcrdf. output est un fichier, écrit dans <output>.partial puis renommé,
+// ou "-" pour la sortie standard. Les fichiers sont téléchargés un par un dans un répertoire
+// temporaire puis ajoutés à l'archive: l'espace local nécessaire est celui du plus gros fichier.
+// backupID peut valoir "latest" (la plus récente, limitée au nom name s'il est donné).
+func (m *Manager) ExportArchive(backupID, name, output string, verbose bool) (result *ExportResult, err error) {
+	if err := m.loadConfig(backupID); err != nil {
+		return nil, err
+	}
+	if err := m.initializeComponents(); err != nil {
+		return nil, fmt.Errorf("initialization error: %w", err)
+	}
+	if err := m.applyThrottle(verbose); err != nil {
+		return nil, err
+	}
+	if backupID == LatestBackup {
+		if backupID, err = m.indexMgr.LatestBackupID(name); err != nil {
+			return nil, err
+		}
+	}
+	if output != ExportStdout {
+		if _, err := os.Lstat(output); err == nil {
+			return nil, fmt.Errorf("%w: export %s already exists", utils.ErrConfig, output)
+		}
+	}
+	m.startAudit("export", backupID, output)
+	defer func() { err = m.finishAudit(err) }()
+
+	backupIndex, err := m.indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index: %w", err)
+	}
+	if len(m.pathFilters) > 0 {
+		backupIndex = m.selectFiles(backupIndex)
+		if len(backupIndex.Files) == 0 {
+			return nil, fmt.Errorf("no file in %s matches %s", backupID, strings.Join(m.pathFilters, ", "))
+		}
+	}
+
+	scratchDir, err := os.MkdirTemp("", "bcrdf-export-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratchDir)
+
+	var out *os.File
+	partial := output + exportPartialSuffix
+	if output == ExportStdout {
+		out = utils.RawStdout()
+	} else {
+		if out, err = os.Create(partial); err != nil {
+			return nil, fmt.Errorf("error creating archive: %w", err)
+		}
+		defer func() {
+			out.Close()
+			if err != nil {
+				os.Remove(partial)
+			}
+		}()
+	}
+
+	result = &ExportResult{Path: output}
+	counter := &countingWriter{w: out}
+	gz := gzip.NewWriter(counter)
+	tw := tar.NewWriter(gz)
+	if err := m.writeArchive(tw, backupIndex, scratchDir, result, verbose); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("error finalizing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("error finalizing archive: %w", err)
+	}
+	result.Bytes = counter.n
+
+	if output != ExportStdout {
+		if err := out.Sync(); err != nil {
+			return nil, fmt.Errorf("error writing archive: %w", err)
+		}
+		if err := out.Close(); err != nil {
+			return nil, fmt.Errorf("error writing archive: %w", err)
+		}
+		if err := os.Rename(partial, output); err != nil {
+			return nil, fmt.Errorf("error finalizing export: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// writeArchive ajoute à l'archive les entrées restaurables de l'index, triées par chemin
+func (m *Manager) writeArchive(tw *tar.Writer, backupIndex *index.BackupIndex, scratchDir string, result *ExportResult, verbose bool) error {
+	// Archive sensible à la casse: pas de renommage des chemins qui ne diffèrent que par la casse
+	restorePaths := m.restorePathPlan(backupIndex, false, verbose)
+	files := make([]index.FileEntry, len(backupIndex.Files))
+	copy(files, backupIndex.Files)
+	sort.Slice(files, func(i, j int) bool { return restorePaths[files[i].Path] < restorePaths[files[j].Path] })
+
+	for _, file := range files {
+		if file.IsSkipped() {
+			continue
+		}
+		if file.Status == index.FileStatusFailed {
+			if verbose {
+				utils.Warn("File failed during backup, not exported: %s (%s)", file.Path, file.Error)
+			}
+			continue
+		}
+		relPath := filepath.ToSlash(restorePaths[file.Path])
+		if file.Path == "" || relPath == "" || relPath == "." {
+			continue
+		}
+
+		header := archiveHeader(file, relPath)
+		if file.IsMetadataOnly() {
+			if err := tw.WriteHeader(header); err != nil {
+				return fmt.Errorf("error writing %s to archive: %w", relPath, err)
+			}
+			continue
+		}
+		if file.StorageKey == "" {
+			if verbose {
+				utils.Warn("Skipping file with empty storage key: %s", file.Path)
+			}
+			continue
+		}
+		if err := m.archiveFile(tw, file, restorePaths[file.Path], header, backupIndex, scratchDir, verbose); err != nil {
+			return fmt.Errorf("error exporting %s: %w", file.Path, err)
+		}
+		result.Restored++
+	}
+	return nil
+}
+
+// archiveFile télécharge un fichier dans scratchDir, l'ajoute à l'archive puis le supprime
+func (m *Manager) archiveFile(tw *tar.Writer, file index.FileEntry, relPath string, header *tar.Header, backupIndex *index.BackupIndex, scratchDir string, verbose bool) error {
+	staged := file
+	staged.Path = relPath
+	stagedPath := utils.LongPath(filepath.Join(scratchDir, relPath))
+	defer os.Remove(stagedPath)
+
+	if err := m.restoreSingleFile(staged, backupIndex, scratchDir, nil, verbose); err != nil {
+		return err
+	}
+	m.auditRestored(stagedPath, file, backupIndex.EffectiveChecksumMode())
+
+	f, err := os.Open(stagedPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header.Size = info.Size()
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return err
+	}
+	utils.Debug("Archived %s (%d bytes)", header.Name, header.Size)
+	return nil
+}
+
+// archiveHeader construit l'en-tête tar d'une entrée (taille des fichiers fixée à l'écriture)
+func archiveHeader(file index.FileEntry, relPath string) *tar.Header {
+	mode, ok := file.FileMode()
+	header := &tar.Header{
+		Name:    relPath,
+		ModTime: file.ModifiedTime,
+		Format:  tar.FormatPAX,
+	}
+	switch {
+	case file.IsDirectory:
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		if !ok {
+			mode = 0755
+		}
+	case file.IsSymlink():
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = file.LinkTarget
+		mode = 0777
+	default:
+		header.Typeflag = tar.TypeReg
+		if !ok {
+			mode = 0644
+		}
+	}
+	header.Mode = int64(mode.Perm())
+	return header
+}
+
+// countingWriter compte les octets écrits
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
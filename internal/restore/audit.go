@@ -0,0 +1,121 @@
+package restore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// auditTracker collecte le résultat d'une restauration pour le journal d'audit (backup.restore_audit)
+type auditTracker struct {
+	mu     sync.Mutex
+	record index.AuditRecord
+}
+
+// startAudit commence l'enregistrement d'une restauration si backup.restore_audit est activé.
+// Les restaurations depuis un partage, en lecture seule sur le dépôt, ne sont pas auditées.
+func (m *Manager) startAudit(operation, backupID, destinationPath string) {
+	if !m.config.Backup.RestoreAudit || m.share != nil {
+		return
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	if absPath, err := filepath.Abs(destinationPath); err == nil {
+		destinationPath = absPath
+	}
+	m.audit = &auditTracker{record: index.AuditRecord{
+		Time:        time.Now(),
+		Operation:   operation,
+		User:        currentUser(),
+		Hostname:    hostname,
+		OperationID: utils.OperationID(),
+		BackupID:    backupID,
+		Paths:       m.pathFilters,
+		Destination: destinationPath,
+	}}
+}
+
+// auditRestored vérifie le checksum d'un fichier restauré (mode: mode de checksum de la sauvegarde)
+// et le compte dans l'enregistrement
+func (m *Manager) auditRestored(restoredPath string, file index.FileEntry, mode string) {
+	if m.audit == nil {
+		return
+	}
+	err := index.VerifyRestored(utils.LongPath(restoredPath), file, mode)
+
+	m.audit.mu.Lock()
+	defer m.audit.mu.Unlock()
+	m.audit.record.FilesRestored++
+	if err != nil {
+		m.audit.record.ChecksumMismatches = append(m.audit.record.ChecksumMismatches, file.Path)
+		return
+	}
+	m.audit.record.ChecksumsVerified++
+}
+
+// auditCounts enregistre les fichiers conservés et en échec
+func (m *Manager) auditCounts(kept, failed int) {
+	if m.audit == nil {
+		return
+	}
+	m.audit.mu.Lock()
+	defer m.audit.mu.Unlock()
+	m.audit.record.FilesKept += kept
+	m.audit.record.FilesFailed += failed
+}
+
+// finishAudit ajoute l'enregistrement au journal d'audit du dépôt. Une restauration qui ne peut
+// pas être tracée est signalée en échec: l'obligation de traçabilité l'emporte.
+func (m *Manager) finishAudit(restoreErr error) error {
+	if m.audit == nil {
+		return restoreErr
+	}
+	record := &m.audit.record
+	m.audit = nil
+
+	switch {
+	case restoreErr == nil && len(record.ChecksumMismatches) == 0:
+		record.Status = index.AuditStatusSuccess
+	case restoreErr == nil || errors.Is(restoreErr, utils.ErrPartialFailure):
+		record.Status = index.AuditStatusPartial
+	default:
+		record.Status = index.AuditStatusFailed
+	}
+	if restoreErr != nil {
+		record.Error = restoreErr.Error()
+	}
+
+	if err := m.indexMgr.AppendAudit(record); err != nil {
+		if restoreErr != nil {
+			utils.Error("Restore audit record not written: %v", err)
+			return restoreErr
+		}
+		return fmt.Errorf("restore completed but its audit record could not be written: %w", err)
+	}
+	if len(record.ChecksumMismatches) > 0 && restoreErr == nil {
+		return fmt.Errorf("%w: %d restored files do not match their checksum", utils.ErrVerificationFailed, len(record.ChecksumMismatches))
+	}
+	return restoreErr
+}
+
+// currentUser retourne le compte qui exécute la restauration
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, name := range []string{"USER", "USERNAME"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,186 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Types de conflit entre la sauvegarde et un fichier déjà présent à la destination
+const (
+	ConflictKindOverwritten = "overwritten" // Le fichier local diffère de la sauvegarde
+	ConflictKindLocalNewer  = "local-newer" // Le fichier local est plus récent que la version sauvegardée
+)
+
+// Actions de la politique de conflit sur un fichier en conflit
+const (
+	ConflictActionOverwrite = "overwrite"
+	ConflictActionKeep      = "keep"
+)
+
+// maxLoggedConflicts limite les conflits listés avant une restauration (--report-only les liste tous)
+const maxLoggedConflicts = 10
+
+// Conflict est un fichier de la sauvegarde déjà présent, avec un contenu différent, à la destination
+type Conflict struct {
+	Path       string    // Chemin relatif à la destination
+	Kind       string    // ConflictKind*
+	Action     string    // Ce que fera la politique de conflit (ConflictAction*)
+	BackupSize int64     // Taille de la version sauvegardée
+	BackupTime time.Time // Date de modification de la version sauvegardée
+	LocalSize  int64     // Taille du fichier local
+	LocalTime  time.Time // Date de modification du fichier local
+	NotAFile   bool      // Un répertoire ou un lien occupe le chemin du fichier
+}
+
+// ConflictReport liste les conflits d'une restauration sur une arborescence existante
+type ConflictReport struct {
+	BackupID    string
+	Destination string
+	Policy      string // overwrite, skip ou newer
+	Files       int    // Fichiers de la sauvegarde comparés à la destination
+	Existing    int    // Fichiers déjà présents (identiques compris)
+	Conflicts   []Conflict
+}
+
+// Overwritten retourne le nombre de fichiers locaux que la restauration remplacera
+func (r *ConflictReport) Overwritten() int {
+	count := 0
+	for _, conflict := range r.Conflicts {
+		if conflict.Action == ConflictActionOverwrite {
+			count++
+		}
+	}
+	return count
+}
+
+// LocalNewer retourne le nombre de fichiers locaux plus récents que la sauvegarde
+func (r *ConflictReport) LocalNewer() int {
+	count := 0
+	for _, conflict := range r.Conflicts {
+		if conflict.Kind == ConflictKindLocalNewer {
+			count++
+		}
+	}
+	return count
+}
+
+// ReportConflicts compare une sauvegarde à la destination sans rien écrire (restore --report-only):
+// fichiers qui seraient remplacés et fichiers locaux plus récents que la sauvegarde
+func (m *Manager) ReportConflicts(backupID, destinationPath string) (*ConflictReport, error) {
+	if err := m.loadConfig(backupID); err != nil {
+		return nil, err
+	}
+	if err := m.initializeComponents(); err != nil {
+		return nil, fmt.Errorf("initialization error: %w", err)
+	}
+
+	backupIndex, err := m.indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index: %w", err)
+	}
+	if len(m.pathFilters) > 0 {
+		backupIndex = m.selectFiles(backupIndex)
+		if len(backupIndex.Files) == 0 {
+			return nil, fmt.Errorf("no file in %s matches %s", backupID, strings.Join(m.pathFilters, ", "))
+		}
+	}
+	// Pas de sonde de casse, qui écrirait dans la destination: chemins de destination sensibles à la casse
+	return m.findConflicts(backupIndex, destinationPath, m.restorePathPlan(backupIndex, false, false)), nil
+}
+
+// findConflicts compare les fichiers restaurables aux fichiers présents à la destination
+// Un fichier de même taille et de même checksum que la sauvegarde est identique, pas en conflit.
+func (m *Manager) findConflicts(backupIndex *index.BackupIndex, destinationPath string, restorePaths map[string]string) *ConflictReport {
+	policy := m.conflictPolicy
+	if policy == "" {
+		policy = ConflictOverwrite
+	}
+	report := &ConflictReport{BackupID: backupIndex.BackupID, Destination: destinationPath, Policy: policy}
+
+	for _, file := range backupIndex.Files {
+		if file.IsDirectory || file.IsSkipped() || file.Status == index.FileStatusFailed || file.Path == "" {
+			continue
+		}
+		report.Files++
+		relPath := restorePaths[file.Path]
+		info, err := os.Lstat(filepath.Join(destinationPath, relPath))
+		if err != nil {
+			continue
+		}
+		report.Existing++
+		notAFile := !info.Mode().IsRegular()
+		if !notAFile && info.Size() == file.Size && isUnchanged(filepath.Join(destinationPath, relPath), file, backupIndex.EffectiveChecksumMode()) {
+			continue
+		}
+
+		conflict := Conflict{
+			Path:       relPath,
+			Kind:       ConflictKindOverwritten,
+			Action:     ConflictActionOverwrite,
+			BackupSize: file.Size,
+			BackupTime: file.ModifiedTime,
+			LocalSize:  info.Size(),
+			LocalTime:  info.ModTime(),
+			NotAFile:   notAFile,
+		}
+		if info.ModTime().After(file.ModifiedTime) {
+			conflict.Kind = ConflictKindLocalNewer
+		}
+		if m.keepExisting(filepath.Join(destinationPath, relPath), file, backupIndex.EffectiveChecksumMode()) {
+			conflict.Action = ConflictActionKeep
+		}
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+	return report
+}
+
+// logConflicts signale les conflits avant d'écrire dans la destination
+func logConflicts(report *ConflictReport, verbose bool) {
+	summary := fmt.Sprintf("%d existing files differ from the backup (%d newer locally): %d will be overwritten (conflict policy: %s)",
+		len(report.Conflicts), report.LocalNewer(), report.Overwritten(), report.Policy)
+	if !verbose {
+		utils.ProgressWarning(summary)
+		return
+	}
+	utils.Warn("⚠️  %s", summary)
+	for i, conflict := range report.Conflicts {
+		if i == maxLoggedConflicts {
+			utils.Warn("   ... %d more (restore --report-only lists them all)", len(report.Conflicts)-maxLoggedConflicts)
+			break
+		}
+		utils.Warn("   - %s: %s, %s", conflict.Path, conflict.Kind, conflict.Action)
+	}
+}
+
+// PrintConflictReport affiche le rapport de conflits d'une restauration
+func PrintConflictReport(report *ConflictReport) {
+	fmt.Printf("\n📋 Restore Conflict Report\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+	fmt.Printf("Backup: %s\n", report.BackupID)
+	fmt.Printf("Destination: %s\n", report.Destination)
+	fmt.Printf("Conflict policy: %s\n", report.Policy)
+	fmt.Printf("Files in backup: %d\n", report.Files)
+	fmt.Printf("Already at destination: %d (%d identical)\n", report.Existing, report.Existing-len(report.Conflicts))
+	fmt.Printf("Conflicts: %d (%d newer locally)\n", len(report.Conflicts), report.LocalNewer())
+	fmt.Printf("Would overwrite: %d\n", report.Overwritten())
+
+	for _, conflict := range report.Conflicts {
+		icon := "✏️ "
+		if conflict.Kind == ConflictKindLocalNewer {
+			icon = "⚠️ "
+		}
+		local := fmt.Sprintf("%d bytes, %s", conflict.LocalSize, conflict.LocalTime.Format(time.RFC3339))
+		if conflict.NotAFile {
+			local = "not a regular file"
+		}
+		fmt.Printf("  %s %s [%s, %s]\n", icon, conflict.Path, conflict.Kind, conflict.Action)
+		fmt.Printf("       backup: %d bytes, %s; local: %s\n", conflict.BackupSize, conflict.BackupTime.Format(time.RFC3339), local)
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,61 @@
+package restore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bcrdf/internal/index"
+)
+
+func TestFindConflicts(t *testing.T) {
+	dest := t.TempDir()
+	backupTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	write := func(name, content string, modified time.Time) {
+		path := filepath.Join(dest, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("identique.txt", "12345", backupTime)
+	write("ancien.txt", "abc", backupTime.Add(-time.Hour))
+	write("recent.txt", "modifié localement", backupTime.Add(time.Hour))
+
+	// Version sauvegardée: "12345" pour tous les fichiers, seul identique.txt n'a pas changé
+	sum := sha256.Sum256([]byte("12345"))
+	backupIndex := &index.BackupIndex{BackupID: "b1", SourcePath: "/src"}
+	for _, name := range []string{"identique.txt", "ancien.txt", "recent.txt", "absent.txt"} {
+		backupIndex.Files = append(backupIndex.Files, index.FileEntry{Path: "/src/" + name, Size: 5, ModifiedTime: backupTime,
+			Checksum: hex.EncodeToString(sum[:]), StorageKey: "k"})
+	}
+	paths := map[string]string{}
+	for _, file := range backupIndex.Files {
+		paths[file.Path] = filepath.Base(file.Path)
+	}
+
+	m := &Manager{conflictPolicy: ConflictNewer}
+	report := m.findConflicts(backupIndex, dest, paths)
+	if report.Files != 4 || report.Existing != 3 || len(report.Conflicts) != 2 {
+		t.Fatalf("rapport inattendu: %d fichiers, %d présents, %d conflits", report.Files, report.Existing, len(report.Conflicts))
+	}
+	kinds := map[string]Conflict{}
+	for _, conflict := range report.Conflicts {
+		kinds[conflict.Path] = conflict
+	}
+	if c := kinds["ancien.txt"]; c.Kind != ConflictKindOverwritten || c.Action != ConflictActionOverwrite {
+		t.Errorf("ancien.txt: %s, %s", c.Kind, c.Action)
+	}
+	// La politique newer conserve le fichier local plus récent
+	if c := kinds["recent.txt"]; c.Kind != ConflictKindLocalNewer || c.Action != ConflictActionKeep {
+		t.Errorf("recent.txt: %s, %s", c.Kind, c.Action)
+	}
+	if report.Overwritten() != 1 || report.LocalNewer() != 1 {
+		t.Errorf("compteurs: %d remplacés, %d plus récents", report.Overwritten(), report.LocalNewer())
+	}
+}
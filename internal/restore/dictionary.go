@@ -0,0 +1,29 @@
+package restore
+
+import (
+	"fmt"
+	"strings"
+
+	"bcrdf/internal/compression"
+	"bcrdf/internal/index"
+)
+
+// dictionary retourne le dictionnaire de compression référencé par l'index, téléchargé une seule fois
+func (m *Manager) dictionary(ref string) (*compression.Dictionary, error) {
+	if cached, ok := m.dictionaries.Load(ref); ok {
+		return cached.(*compression.Dictionary), nil
+	}
+
+	encrypted, err := m.downloadWithRetry(index.DictionaryKey(ref))
+	if err != nil {
+		return nil, fmt.Errorf("error downloading compression dictionary %s: %w", ref, err)
+	}
+	data, err := m.encryptor.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting compression dictionary %s: %w", ref, err)
+	}
+
+	dict := &compression.Dictionary{ID: ref[strings.LastIndex(ref, "/")+1:], Data: data}
+	m.dictionaries.Store(ref, dict)
+	return dict, nil
+}
@@ -0,0 +1,175 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// Formats d'export d'une sauvegarde (bcrdf export --format)
+const (
+	ExportHardlinkTree = "hardlink-tree" // Arborescence simple, fichiers inchangés liés à l'export précédent
+	ExportTarGz        = "tar.gz"        // Archive tar compressée, lisible sans bcrdf
+)
+
+// exportPartialSuffix marque un export en cours: seul un export terminé porte le nom de sa sauvegarde
+const exportPartialSuffix = ".partial"
+
+// ExportResult résume un export
+type ExportResult struct {
+	Path     string // Répertoire ou archive de l'export ("-" pour la sortie standard)
+	Previous string // Export précédent servant aux liens durs, vide sinon
+	Linked   int    // Fichiers liés à l'export précédent
+	Restored int    // Fichiers téléchargés
+	Bytes    int64  // Taille de l'archive écrite (tar.gz)
+}
+
+// ExportHardlinkTree matérialise une sauvegarde en arborescence simple sous exportRoot/<backup-id>,
+// à la manière de rsnapshot: les fichiers inchangés depuis l'export précédent du même nom
+// (même checksum, fichier exporté intact) sont des liens durs vers celui-ci, les autres sont
+// téléchargés. L'export est écrit dans <backup-id>.partial puis renommé une fois complet.
+// backupID peut valoir "latest" (la plus récente, limitée au nom name s'il est donné).
+func (m *Manager) ExportHardlinkTree(backupID, name, exportRoot string, verbose bool) (result *ExportResult, err error) {
+	if err := m.loadConfig(backupID); err != nil {
+		return nil, err
+	}
+	if err := m.initializeComponents(); err != nil {
+		return nil, fmt.Errorf("initialization error: %w", err)
+	}
+	if err := m.applyThrottle(verbose); err != nil {
+		return nil, err
+	}
+	if backupID == LatestBackup {
+		if backupID, err = m.indexMgr.LatestBackupID(name); err != nil {
+			return nil, err
+		}
+	}
+
+	target := filepath.Join(exportRoot, backupID)
+	if _, err := os.Lstat(target); err == nil {
+		return nil, fmt.Errorf("%w: export %s already exists", utils.ErrConfig, target)
+	}
+	m.startAudit("export", backupID, target)
+	defer func() { err = m.finishAudit(err) }()
+
+	backupIndex, err := m.indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index: %w", err)
+	}
+
+	// Reste d'un export interrompu: recommencé
+	partial := target + exportPartialSuffix
+	if err := os.RemoveAll(partial); err != nil {
+		return nil, fmt.Errorf("error removing interrupted export %s: %w", partial, err)
+	}
+	if err := utils.EnsureDirectory(partial); err != nil {
+		return nil, fmt.Errorf("error creating export directory: %w", err)
+	}
+
+	result = &ExportResult{Path: target}
+	restorePaths := m.planRestorePaths(backupIndex, partial, verbose)
+	remaining := *backupIndex
+	remaining.Files, remaining.TotalFiles, remaining.TotalSize = nil, 0, 0
+
+	previousID, previousFiles, previousMode := m.previousExport(exportRoot, backupID)
+	if previousID != "" {
+		result.Previous = filepath.Join(exportRoot, previousID)
+		if verbose {
+			utils.Info("   - Hard linking unchanged files against %s", result.Previous)
+		}
+	}
+	for _, file := range backupIndex.Files {
+		relPath := restorePaths[file.Path]
+		if previous, ok := previousFiles[relPath]; ok && linkUnchanged(file, previous, previousMode, filepath.Join(result.Previous, relPath), filepath.Join(partial, relPath)) {
+			result.Linked++
+			continue
+		}
+		remaining.Files = append(remaining.Files, file)
+		if !file.IsDirectory {
+			remaining.TotalFiles++
+			remaining.TotalSize += file.Size
+		}
+	}
+
+	if err := m.checkDestinationSpace(&remaining, partial, verbose); err != nil {
+		return nil, err
+	}
+	if err := m.restoreFiles(&remaining, partial, verbose); err != nil {
+		return nil, fmt.Errorf("error restoring files: %w", err)
+	}
+	if err := os.Rename(partial, target); err != nil {
+		return nil, fmt.Errorf("error finalizing export: %w", err)
+	}
+	for _, file := range remaining.Files {
+		if !file.IsDirectory && (file.HasData() || file.IsMetadataOnly()) {
+			result.Restored++
+		}
+	}
+	return result, nil
+}
+
+// previousExport retourne l'export terminé le plus récent de la même sauvegarde (même nom) sous
+// exportRoot, avec les entrées de son index par chemin exporté et son mode de checksum; vide s'il
+// n'y en a pas
+func (m *Manager) previousExport(exportRoot, backupID string) (string, map[string]index.FileEntry, string) {
+	current, err := index.ParseBackupID(backupID)
+	if err != nil {
+		return "", nil, ""
+	}
+	entries, err := os.ReadDir(exportRoot)
+	if err != nil {
+		return "", nil, ""
+	}
+	var candidates []index.BackupRef
+	for _, entry := range entries {
+		ref, err := index.ParseBackupID(entry.Name())
+		if err != nil || !entry.IsDir() || ref.ID == backupID || ref.Name != current.Name {
+			continue
+		}
+		candidates = append(candidates, ref)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].CreatedAt.After(candidates[j].CreatedAt) })
+
+	// Un export dont la sauvegarde a été supprimée ne peut plus être comparé: le suivant est essayé
+	for _, candidate := range candidates {
+		previousIndex, err := m.indexMgr.LoadIndex(candidate.ID)
+		if err != nil {
+			utils.Debug("Export %s not usable for hard links: %v", candidate.ID, err)
+			continue
+		}
+		paths := m.restorePathPlan(previousIndex, false, false)
+		files := make(map[string]index.FileEntry, len(previousIndex.Files))
+		for _, file := range previousIndex.Files {
+			files[paths[file.Path]] = file
+		}
+		return candidate.ID, files, previousIndex.EffectiveChecksumMode()
+	}
+	return "", nil, ""
+}
+
+// linkUnchanged lie un fichier inchangé à sa copie de l'export précédent
+// La copie doit être intacte (taille et checksum de l'index, selon mode, le mode de checksum de
+// la sauvegarde précédente): un fichier modifié depuis l'export précédent, ou un lien impossible
+// (autre système de fichiers), est téléchargé.
+func linkUnchanged(file, previous index.FileEntry, mode, previousPath, targetPath string) bool {
+	if file.IsDirectory || !file.HasData() || file.Checksum == "" ||
+		file.Checksum != previous.Checksum || file.Size != previous.Size {
+		return false
+	}
+	info, err := os.Lstat(previousPath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != previous.Size || !isUnchanged(previousPath, previous, mode) {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return false
+	}
+	if err := os.Link(previousPath, targetPath); err != nil {
+		utils.Debug("Hard link %s -> %s failed: %v", targetPath, previousPath, err)
+		return false
+	}
+	return true
+}
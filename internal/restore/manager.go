@@ -1,9 +1,13 @@
 package restore
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,29 +18,51 @@ import (
 	"bcrdf/internal/compression"
 	"bcrdf/internal/crypto"
 	"bcrdf/internal/index"
+	"bcrdf/internal/share"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
 )
 
 // Manager gère les opérations de restoration
 type Manager struct {
-	configFile    string
-	config        *utils.Config
-	indexMgr      *index.Manager
-	encryptor     *crypto.EncryptorV2
-	compressor    *compression.Compressor
-	storageClient storage.Client
+	configFile     string
+	config         *utils.Config
+	indexMgr       *index.Manager
+	encryptor      *crypto.EncryptorV2
+	keyring        *crypto.Keyring // Clés de données des sauvegardes chiffrées vers des destinataires (identity_file)
+	compressor     *compression.Compressor
+	storageClient  storage.Client
+	unicodeForm    string   // Normalisation Unicode des chemins restaurés (original, nfc, nfd)
+	pathFilters    []string // Chemins à restaurer (vide = tout)
+	conflictPolicy string   // Fichiers déjà présents: overwrite (défaut), skip ou newer
+	dictionaries   sync.Map // Dictionnaires de compression par référence ({job}/{id})
+	nice           int      // Surcharge de restore_nice (< 0 = configuration)
+	ioPriority     string   // Surcharge de restore_io_priority
+	rateLimit      string   // Surcharge de restore_rate_limit
+	limiter        *utils.RateLimiter
+	share          *share.Bundle // Partage pré-signé (remplace configuration et identifiants)
+	audit          *auditTracker // Restauration en cours d'audit (backup.restore_audit)
 }
 
 // NewManager crée un nouveau gestionnaire de restoration
 func NewManager(configFile string) *Manager {
 	return &Manager{
 		configFile: configFile,
+		nice:       -1,
 	}
 }
 
+// SetUnicodeForm définit la normalisation Unicode appliquée aux chemins restaurés
+func (m *Manager) SetUnicodeForm(form string) error {
+	if err := index.ValidateUnicodeForm(form); err != nil {
+		return err
+	}
+	m.unicodeForm = form
+	return nil
+}
+
 // RestoreBackup restaure une sauvegarde complète
-func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool) error {
+func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool) (err error) {
 	if verbose {
 		utils.Info("🔄 🚀 Starting restore: %s", backupID)
 		utils.Info("📋 Tasks to perform:")
@@ -47,7 +73,7 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   5. Verify restored files")
 		utils.Info("   6. Finalize restore operation")
 	} else {
-		utils.ProgressStep(fmt.Sprintf("🔄 🚀 Starting restore: %s", backupID))
+		utils.ProgressStep(utils.Msg("restore.step.start", backupID))
 	}
 
 	// Charger la configuration
@@ -57,15 +83,18 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   - Initializing components")
 	}
 
-	config, err := utils.LoadConfig(m.configFile)
-	if err != nil {
-		return fmt.Errorf("erreur lors du chargement de la configuration: %w", err)
+	if err := m.loadConfig(backupID); err != nil {
+		return err
 	}
-	m.config = config
 
 	// Initialiser les composants
 	if err := m.initializeComponents(); err != nil {
-		return fmt.Errorf("error during l'initialisation: %w", err)
+		return fmt.Errorf("initialization error: %w", err)
+	}
+	m.startAudit("restore", backupID, destinationPath)
+	defer func() { err = m.finishAudit(err) }()
+	if err := m.applyThrottle(verbose); err != nil {
+		return err
 	}
 
 	if verbose {
@@ -79,12 +108,18 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   - Downloading backup index")
 		utils.Info("   - Parsing index data")
 	} else {
-		utils.ProgressStep("Chargement de l'index...")
+		utils.ProgressStep(utils.Msg("restore.step.load_index"))
 	}
 
 	backupIndex, err := m.indexMgr.LoadIndex(backupID)
 	if err != nil {
-		return fmt.Errorf("erreur lors du chargement de l'index: %w", err)
+		return fmt.Errorf("error loading index: %w", err)
+	}
+	if len(m.pathFilters) > 0 {
+		backupIndex = m.selectFiles(backupIndex)
+		if len(backupIndex.Files) == 0 {
+			return fmt.Errorf("no file in %s matches %s", backupID, strings.Join(m.pathFilters, ", "))
+		}
 	}
 
 	if verbose {
@@ -93,16 +128,27 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		// Analyser les clés de stockage
 		validFiles := 0
 		emptyKeys := 0
+		skippedFiles := 0
+		failedFiles := 0
 		for _, file := range backupIndex.Files {
-			if file.StorageKey == "" {
+			switch {
+			case file.IsSkipped():
+				skippedFiles++
+			case file.Status == index.FileStatusFailed:
+				failedFiles++
+			case file.IsMetadataOnly():
+				validFiles++
+			case file.StorageKey == "":
 				emptyKeys++
-			} else {
+			default:
 				validFiles++
 			}
 		}
 		utils.Info("📊 Index analysis:")
 		utils.Info("   - Total files: %d", len(backupIndex.Files))
 		utils.Info("   - Valid storage keys: %d", validFiles)
+		utils.Info("   - Intentionally skipped: %d", skippedFiles)
+		utils.Info("   - Failed during backup: %d", failedFiles)
 		utils.Info("   - Empty storage keys: %d", emptyKeys)
 
 		if emptyKeys > 0 {
@@ -117,12 +163,15 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   - Checking destination: %s", destinationPath)
 		utils.Info("   - Creating directory structure")
 	} else {
-		utils.ProgressStep("Preparing destination directory...")
+		utils.ProgressStep(utils.Msg("restore.step.prepare"))
 	}
 
 	if err := utils.EnsureDirectory(destinationPath); err != nil {
 		return fmt.Errorf("error creating directory de destination: %w", err)
 	}
+	if err := m.checkDestinationSpace(backupIndex, destinationPath, verbose); err != nil {
+		return err
+	}
 
 	if verbose {
 		utils.Info("✅ Task 3 completed: Destination directory ready")
@@ -137,11 +186,11 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   - Decrypting and decompressing")
 		utils.Info("   - Writing to destination")
 	} else {
-		utils.ProgressStep(fmt.Sprintf("Restoring %d files to: %s", backupIndex.TotalFiles, destinationPath))
+		utils.ProgressStep(utils.Msg("restore.step.files", backupIndex.TotalFiles, destinationPath))
 	}
 
 	if err := m.restoreFiles(backupIndex, destinationPath, verbose); err != nil {
-		return fmt.Errorf("error during la restoration des fichiers: %w", err)
+		return fmt.Errorf("error restoring files: %w", err)
 	}
 
 	if verbose {
@@ -166,7 +215,7 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 		utils.Info("   ✅ File integrity verified")
 		utils.Info("   ✅ Restore operation completed")
 	} else {
-		utils.ProgressSuccess(fmt.Sprintf("✅ Restore completed successfully to: %s", destinationPath))
+		utils.ProgressSuccess(utils.Msg("restore.step.done", destinationPath))
 	}
 
 	return nil
@@ -174,24 +223,25 @@ func (m *Manager) RestoreBackup(backupID, destinationPath string, verbose bool)
 
 // RestoreFile restaure un fichier spécifique
 func (m *Manager) RestoreFile(backupID, filePath, destinationPath string) error {
-	utils.Info("🔄 Restoration du fichier: %s", filePath)
+	utils.Info("🔄 Restoring file: %s", filePath)
 
 	// Charger la configuration
-	config, err := utils.LoadConfig(m.configFile)
-	if err != nil {
-		return fmt.Errorf("erreur lors du chargement de la configuration: %w", err)
+	if err := m.loadConfig(backupID); err != nil {
+		return err
 	}
-	m.config = config
 
 	// Initialiser les composants
 	if err := m.initializeComponents(); err != nil {
-		return fmt.Errorf("error during l'initialisation: %w", err)
+		return fmt.Errorf("initialization error: %w", err)
+	}
+	if err := m.applyThrottle(false); err != nil {
+		return err
 	}
 
 	// Charger l'index de la sauvegarde
 	backupIndex, err := m.indexMgr.LoadIndex(backupID)
 	if err != nil {
-		return fmt.Errorf("erreur lors du chargement de l'index: %w", err)
+		return fmt.Errorf("error loading index: %w", err)
 	}
 
 	// Trouver le fichier dans l'index
@@ -204,12 +254,12 @@ func (m *Manager) RestoreFile(backupID, filePath, destinationPath string) error
 	}
 
 	if targetFile == nil {
-		return fmt.Errorf("file not found dans la sauvegarde: %s", filePath)
+		return fmt.Errorf("file not found in backup: %s", filePath)
 	}
 
 	// Restaurer le fichier
-	if err := m.restoreSingleFile(*targetFile, backupID, destinationPath, nil, true); err != nil {
-		return fmt.Errorf("error during la restoration du fichier: %w", err)
+	if err := m.restoreSingleFile(*targetFile, backupIndex, destinationPath, nil, true); err != nil {
+		return fmt.Errorf("error restoring file: %w", err)
 	}
 
 	utils.Info("✅ File restored: %s", filePath)
@@ -218,9 +268,31 @@ func (m *Manager) RestoreFile(backupID, filePath, destinationPath string) error
 
 // initializeComponents initialise tous les composants nécessaires
 func (m *Manager) initializeComponents() error {
+	if err := m.initializeCodecs(); err != nil {
+		return err
+	}
+
+	if m.share != nil {
+		m.storageClient = m.share.Client()
+		m.indexMgr = index.NewManagerWithClient(m.config, m.storageClient)
+		return nil
+	}
+
 	// Initialiser le gestionnaire d'index
 	m.indexMgr = index.NewManager(m.configFile)
 
+	// Initialiser le client de stockage
+	storageClient, err := storage.NewStorageClient(m.config)
+	if err != nil {
+		return fmt.Errorf("error initializing storage client: %w", err)
+	}
+	m.storageClient = storageClient
+
+	return nil
+}
+
+// initializeCodecs initialise le chiffreur et le compresseur
+func (m *Manager) initializeCodecs() error {
 	// Initialiser le chiffreur avec l'algorithme configuré
 	algorithm := crypto.EncryptionAlgorithm(m.config.Backup.EncryptionAlgo)
 	if algorithm == "" {
@@ -229,24 +301,21 @@ func (m *Manager) initializeComponents() error {
 
 	encryptor, err := crypto.NewEncryptorV2(m.config.Backup.EncryptionKey, algorithm)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du chiffreur: %w", err)
+		return fmt.Errorf("error initializing encryptor: %w", err)
 	}
+	encryptor.SetAllowUnencrypted(m.config.Backup.AllowUnencrypted)
 	m.encryptor = encryptor
+	if m.keyring, err = crypto.NewKeyring(encryptor, algorithm, m.config.Backup.IdentityFile); err != nil {
+		return fmt.Errorf("error initializing encryptor: %w", err)
+	}
 
 	// Initialiser le compresseur
 	compressor, err := compression.NewCompressor(m.config.Backup.CompressionLevel)
 	if err != nil {
-		return fmt.Errorf("error during l'initialisation du compresseur: %w", err)
+		return fmt.Errorf("error initializing compressor: %w", err)
 	}
 	m.compressor = compressor
 
-	// Initialiser le client de stockage
-	storageClient, err := storage.NewStorageClient(m.config)
-	if err != nil {
-		return fmt.Errorf("error during l'initialisation du client de stockage: %w", err)
-	}
-	m.storageClient = storageClient
-
 	return nil
 }
 
@@ -417,7 +486,7 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 	if verbose {
 		utils.Info("Restoring %d files to: %s", backupIndex.TotalFiles, destinationPath)
 	} else {
-		utils.ProgressStep(fmt.Sprintf("Restoring %d files to: %s", backupIndex.TotalFiles, destinationPath))
+		utils.ProgressStep(utils.Msg("restore.step.files", backupIndex.TotalFiles, destinationPath))
 	}
 
 	// Initialiser les statistiques de monitoring
@@ -475,7 +544,39 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 	completed := int64(0)
 	var completedMutex sync.Mutex
 
-	for i, file := range backupIndex.Files {
+	// Chemins de destination: normalisation Unicode et collisions de casse
+	restorePaths := m.planRestorePaths(backupIndex, destinationPath, verbose)
+
+	// Rapport de conflits avant toute écriture (la synchronisation remplace les fichiers modifiés par principe)
+	if m.conflictPolicy != conflictUnchanged {
+		if report := m.findConflicts(backupIndex, destinationPath, restorePaths); len(report.Conflicts) > 0 {
+			logConflicts(report, verbose)
+		}
+	}
+
+	// Recréer d'abord les répertoires et fichiers vides enregistrés dans l'index
+	if err := m.restoreMetadataEntries(backupIndex, destinationPath, restorePaths); err != nil {
+		return err
+	}
+
+	keptCount := 0
+	var pending []index.FileEntry
+	for _, file := range backupIndex.Files {
+		if file.IsMetadataOnly() {
+			continue
+		}
+
+		// Fichiers volontairement absents (exclus ou illisibles lors de la sauvegarde)
+		if file.IsSkipped() {
+			continue
+		}
+		if file.Status == index.FileStatusFailed {
+			if verbose {
+				utils.Warn("File failed during backup, not restorable: %s (%s)", file.Path, file.Error)
+			}
+			continue
+		}
+
 		// Ignorer les fichiers avec des chemins vides ou des clés de stockage vides
 		if file.Path == "" || file.StorageKey == "" {
 			if verbose {
@@ -484,6 +585,24 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 			continue
 		}
 
+		if m.keepExisting(filepath.Join(destinationPath, restorePaths[file.Path]), file, backupIndex.EffectiveChecksumMode()) {
+			keptCount++
+			continue
+		}
+		pending = append(pending, file)
+	}
+
+	// Chaque objet n'est téléchargé qu'une fois, puis recopié vers les autres chemins qui le référencent
+	plan := planDownloads(pending)
+	if duplicates := plan.duplicates(); duplicates > 0 {
+		if verbose {
+			utils.Info("   - %d files share an object with another file and are copied locally", duplicates)
+		} else {
+			utils.ProgressInfo(fmt.Sprintf("%d duplicate files copied locally", duplicates))
+		}
+	}
+
+	for i, file := range plan.downloads {
 		wg.Add(1)
 		go func(f index.FileEntry, index int) {
 			defer wg.Done()
@@ -504,30 +623,33 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 			}
 
 			// Construire un chemin relatif par rapport à la racine de sauvegarde pour restaurer sous destinationPath
-			relPath := f.Path
-			sourceRoot := filepath.Clean(backupIndex.SourcePath)
-			// Si le chemin source est absolu et que f.Path commence par sourceRoot, le tronquer
-			if filepath.IsAbs(relPath) {
-				prefix := sourceRoot + string(os.PathSeparator)
-				if strings.HasPrefix(relPath, prefix) {
-					relPath = relPath[len(prefix):]
-				} else if strings.HasPrefix(relPath, sourceRoot) {
-					relPath = relPath[len(sourceRoot):]
-					relPath = strings.TrimLeft(relPath, string(os.PathSeparator))
-				}
-			}
-			// Utiliser une copie avec le chemin relatif
 			f2 := f
-			f2.Path = relPath
+			f2.Path = restorePaths[f.Path]
 
-			if err := m.restoreSingleFile(f2, backupIndex.BackupID, destinationPath, progressBar, verbose); err != nil {
-				errors <- fmt.Errorf("error during la restoration de %s: %w", f.Path, err)
+			restoredSize := f.Size
+			if err := m.restoreSingleFile(f2, backupIndex, destinationPath, progressBar, verbose); err != nil {
+				errors <- fmt.Errorf("error restoring %s: %w", f.Path, err)
+				for _, c := range plan.copies[f.Path] {
+					errors <- fmt.Errorf("error restoring %s: shared object of %s not restored", c.Path, f.Path)
+				}
+			} else {
+				srcPath := filepath.Join(destinationPath, f2.Path)
+				m.auditRestored(srcPath, f, backupIndex.EffectiveChecksumMode())
+				for _, c := range plan.copies[f.Path] {
+					copyPath := filepath.Join(destinationPath, restorePaths[c.Path])
+					if err := copyRestoredFile(srcPath, copyPath); err != nil {
+						errors <- fmt.Errorf("error restoring %s: %w", c.Path, err)
+						continue
+					}
+					m.auditRestored(copyPath, c, backupIndex.EffectiveChecksumMode())
+					restoredSize += c.Size
+				}
 			}
 
 			// Mettre à jour la progression globale
 			if !verbose && progressBar != nil {
 				completedMutex.Lock()
-				completed += f.Size
+				completed += restoredSize
 				progressBar.UpdateGlobal(completed)
 				completedMutex.Unlock()
 
@@ -541,6 +663,9 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 	wg.Wait()
 	close(errors)
 
+	// Appliquer les permissions des répertoires une fois leur contenu écrit
+	m.applyDirectoryPermissions(backupIndex, destinationPath, restorePaths)
+
 	// Terminer la barre de progression
 	if !verbose && progressBar != nil {
 		progressBar.Finish()
@@ -559,8 +684,16 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 	}
 
 	// Compter les fichiers ignorés
+	intentionalCount := 0
+	failedCount := 0
 	for _, file := range backupIndex.Files {
-		if file.Path == "" || file.StorageKey == "" {
+		switch {
+		case file.IsSkipped():
+			intentionalCount++
+		case file.Status == index.FileStatusFailed:
+			failedCount++
+		case file.IsMetadataOnly():
+		case file.Path == "" || file.StorageKey == "":
 			skippedCount++
 		}
 	}
@@ -574,18 +707,38 @@ func (m *Manager) restoreFiles(backupIndex *index.BackupIndex, destinationPath s
 		if skippedCount > 0 {
 			utils.Warn("   - Skipped %d files with empty storage keys", skippedCount)
 		}
+		if failedCount > 0 {
+			utils.Warn("   - %d files were not restorable (failed during backup)", failedCount)
+		}
+		if intentionalCount > 0 {
+			utils.Info("   - %d files were excluded or unreadable at backup time", intentionalCount)
+		}
+		if keptCount > 0 {
+			utils.Info("   - %d existing files kept (conflict policy: %s)", keptCount, m.conflictPolicy)
+		}
 	} else {
 		if skippedCount > 0 {
 			utils.ProgressInfo(fmt.Sprintf("Skipped %d files with empty storage keys", skippedCount))
 		}
+		if failedCount > 0 {
+			utils.ProgressWarning(fmt.Sprintf("%d files failed during backup and were not restored", failedCount))
+		}
+		if keptCount > 0 {
+			utils.ProgressInfo(fmt.Sprintf("%d existing files kept (conflict policy: %s)", keptCount, m.conflictPolicy))
+		}
 	}
 
+	m.auditCounts(keptCount, errorCount)
 	stats.UpdateStatus("File restoration completed")
+
+	if errorCount > 0 {
+		return fmt.Errorf("%w: %d files could not be restored", utils.ErrPartialFailure, errorCount)
+	}
 	return nil
 }
 
 // restoreSingleFile restaure un seul fichier
-func (m *Manager) restoreSingleFile(file index.FileEntry, backupID, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) restoreSingleFile(file index.FileEntry, backupIndex *index.BackupIndex, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
 	// Vérifier que la clé de stockage n'est pas vide
 	if file.StorageKey == "" {
 		utils.Warn("Skipping file with empty storage key: %s", file.Path)
@@ -593,22 +746,28 @@ func (m *Manager) restoreSingleFile(file index.FileEntry, backupID, destinationP
 	}
 
 	// Reconstruct the full storage key with prefix
-	fullStorageKey := fmt.Sprintf("data/%s/%s", backupID, file.StorageKey)
+	backupID := backupIndex.BackupID
+	fullStorageKey := file.DataKey(backupID)
 
-	// Vérifier si c'est un fichier chunké en essayant de télécharger les métadonnées
+	// Chiffreur des objets: encryption_key, ou la clé de données de la sauvegarde détentrice (recipients)
+	encryptor, err := m.keyring.Encryptor(backupIndex.WrappedDataKey(file))
+	if err != nil {
+		return err
+	}
+
+	// Vérifier si c'est un fichier chunké (présence des métadonnées)
 	metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
-	_, err := m.storageClient.Download(metadataKey)
-	if err == nil {
+	if chunked, _ := storage.ObjectExists(m.storageClient, metadataKey); chunked {
 		// C'est un fichier chunké, le restaurer en chunks
-		return m.restoreChunkedFile(file, backupID, destinationPath, progressBar, verbose)
+		return m.restoreChunkedFile(file, backupID, encryptor, destinationPath, progressBar, verbose)
 	}
 
 	// Fichier normal, traitement standard
-	return m.restoreStandardFile(file, backupID, destinationPath, progressBar, verbose)
+	return m.restoreStandardFile(file, backupID, encryptor, destinationPath, progressBar, verbose)
 }
 
 // restoreChunkedFile restaure un fichier qui a été sauvegardé en chunks avec monitoring
-func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID string, encryptor *crypto.EncryptorV2, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
 	fileName := filepath.Base(file.Path)
 	utils.Debug("🔄 Restoring chunked file: %s (%.2f MB)", file.Path, float64(file.Size)/1024/1024)
 
@@ -621,7 +780,7 @@ func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destination
 	defer stats.StopMonitoring()
 
 	// Reconstruct the full storage key with prefix
-	fullStorageKey := fmt.Sprintf("data/%s/%s", backupID, file.StorageKey)
+	fullStorageKey := file.DataKey(backupID)
 
 	// Download metadata first
 	metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
@@ -632,17 +791,13 @@ func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destination
 		return fmt.Errorf("error downloading metadata: %w", err)
 	}
 
-	var metadata map[string]interface{}
-	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
-		return fmt.Errorf("error parsing metadata: %w", err)
-	}
-
-	chunks, ok := metadata["chunks"].(float64)
-	if !ok {
-		return fmt.Errorf("invalid metadata: chunks field not found")
+	metadata, err := index.ParseChunkMetadata(metadataBytes)
+	if err != nil {
+		return err
 	}
 
-	totalChunks := int(chunks)
+	totalChunks := metadata.Chunks
+	compressed := metadata.IsCompressed(file, m.config.Backup.CompressionLevel > 0)
 	stats.TotalChunks = totalChunks
 
 	utils.Debug("📊 Chunked file restoration plan:")
@@ -651,7 +806,7 @@ func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destination
 	utils.Debug("   - Destination: %s", filepath.Join(destinationPath, file.Path))
 
 	// Create destination file
-	destPath := filepath.Join(destinationPath, file.Path)
+	destPath := utils.LongPath(filepath.Join(destinationPath, file.Path))
 	utils.Debug("📝 Creating destination file: %s", destPath)
 
 	if err := utils.EnsureDirectory(filepath.Dir(destPath)); err != nil {
@@ -690,36 +845,35 @@ func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destination
 		}
 		utils.Debug("✅ Chunk %d downloaded successfully (%d bytes)", chunkNum+1, len(chunkData))
 
+		if err := metadata.VerifyStored(chunkNum, chunkData); err != nil {
+			return err
+		}
+
 		// Mettre à jour les statistiques de chunking
 		stats.UpdateChunkStats(chunkNum+1, totalChunks, int64(len(chunkData)))
 
 		// Decrypt chunk
 		utils.Debug("🔓 Decrypting chunk %d...", chunkNum+1)
-		decryptedChunk, err := m.encryptor.Decrypt(chunkData)
+		decryptedChunk, err := encryptor.Decrypt(chunkData)
 		if err != nil {
 			return fmt.Errorf("error decrypting chunk %d: %w", chunkNum, err)
 		}
 		utils.Debug("✅ Chunk %d decrypted successfully", chunkNum+1)
 
-		// Decompress chunk if compression was enabled during backup
-		if m.config.Backup.CompressionLevel > 0 {
-			utils.Debug("🗜️ Decompressing chunk %d...", chunkNum+1)
-			decompressed, err := m.compressor.Decompress(decryptedChunk)
-			if err != nil {
-				return fmt.Errorf("error decompressing chunk %d: %w", chunkNum, err)
-			}
-			decryptedChunk = decompressed
-			utils.Debug("✅ Chunk %d decompressed successfully", chunkNum+1)
-		}
-
-		// Write chunk to file
+		// Décompresser (metadata, sinon configuration) directement dans le fichier, l'empreinte
+		// en clair étant calculée au passage
 		utils.Debug("📝 Writing chunk %d to file...", chunkNum+1)
-		if _, err := destFile.Write(decryptedChunk); err != nil {
+		hasher := sha256.New()
+		written, err := m.writePlain(io.MultiWriter(destFile, hasher), decryptedChunk, compressed)
+		if err != nil {
 			return fmt.Errorf("error writing chunk %d: %w", chunkNum, err)
 		}
+		if err := metadata.VerifyPlainDigest(chunkNum, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+			return err
+		}
 		utils.Debug("✅ Chunk %d written to file successfully", chunkNum+1)
 
-		totalRestored += int64(len(decryptedChunk))
+		totalRestored += written
 
 		// Mettre à jour la barre de progression avec la progression réelle
 		if !verbose && progressBar != nil {
@@ -737,11 +891,11 @@ func (m *Manager) restoreChunkedFile(file index.FileEntry, backupID, destination
 }
 
 // restoreStandardFile restaure un fichier standard (non-chunké)
-func (m *Manager) restoreStandardFile(file index.FileEntry, backupID, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
+func (m *Manager) restoreStandardFile(file index.FileEntry, backupID string, encryptor *crypto.EncryptorV2, destinationPath string, progressBar *utils.IntegratedProgressBar, verbose bool) error {
 	utils.Debug("🔄 Restoring standard file: %s (%.2f MB)", file.Path, float64(file.Size)/1024/1024)
 
 	// Reconstruct the full storage key with prefix
-	fullStorageKey := fmt.Sprintf("data/%s/%s", backupID, file.StorageKey)
+	fullStorageKey := file.DataKey(backupID)
 	utils.Debug("📥 Downloading file from storage: %s", fullStorageKey)
 	encryptedData, err := m.downloadWithRetry(fullStorageKey)
 	if err != nil {
@@ -751,33 +905,45 @@ func (m *Manager) restoreStandardFile(file index.FileEntry, backupID, destinatio
 
 	// Decrypt file
 	utils.Debug("🔓 Decrypting file...")
-	decryptedData, err := m.encryptor.Decrypt(encryptedData)
+	decryptedData, err := encryptor.Decrypt(encryptedData)
 	if err != nil {
 		return fmt.Errorf("error decrypting file: %w", err)
 	}
 	utils.Debug("✅ File decrypted successfully")
 
-	// Decompress if needed
-	if m.config.Backup.CompressionLevel > 0 {
-		utils.Debug("🗜️ Decompressing file...")
-		decompressedData, err := m.compressor.Decompress(decryptedData)
+	// Compression selon l'index, sinon la configuration; un dictionnaire impose de décompresser en mémoire
+	compressed := file.IsCompressed(m.config.Backup.CompressionLevel > 0)
+	if ref := file.DictionaryRef(); ref != "" {
+		dict, err := m.dictionary(ref)
+		if err != nil {
+			return err
+		}
+		decompressedData, err := m.compressor.DecompressWithDictionary(decryptedData, dict)
 		if err != nil {
 			return fmt.Errorf("error decompressing file: %w", err)
 		}
 		decryptedData = decompressedData
-		utils.Debug("✅ File decompressed successfully")
+		compressed = false
 	}
 
 	// Create destination directory
-	destPath := filepath.Join(destinationPath, file.Path)
+	destPath := utils.LongPath(filepath.Join(destinationPath, file.Path))
 	utils.Debug("📝 Creating destination file: %s", destPath)
 
 	if err := utils.EnsureDirectory(filepath.Dir(destPath)); err != nil {
 		return fmt.Errorf("error creating destination directory: %w", err)
 	}
 
-	// Write file
-	if err := os.WriteFile(destPath, decryptedData, 0644); err != nil {
+	// Écrire le fichier, décompressé au fil de l'eau
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating destination file: %w", err)
+	}
+	if _, err := m.writePlain(destFile, decryptedData, compressed); err != nil {
+		destFile.Close()
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if err := destFile.Close(); err != nil {
 		return fmt.Errorf("error writing file: %w", err)
 	}
 	utils.Debug("✅ File written successfully")
@@ -786,19 +952,195 @@ func (m *Manager) restoreStandardFile(file index.FileEntry, backupID, destinatio
 	return nil
 }
 
+// writePlain écrit dans w les données déchiffrées d'un objet, décompressées au fil de l'eau sans
+// charger le contenu en clair en mémoire, et retourne le nombre d'octets écrits
+func (m *Manager) writePlain(w io.Writer, data []byte, compressed bool) (int64, error) {
+	if !compressed {
+		n, err := w.Write(data)
+		return int64(n), err
+	}
+	return m.compressor.DecompressStream(bytes.NewReader(data), w)
+}
+
 // restorePermissions restaure les permissions d'un fichier
 func (m *Manager) restorePermissions(filePath string, file index.FileEntry) error {
-	// TODO: Implémenter la restoration des permissions
-	// Pour l'instant, on utilise les permissions par défaut
+	mode, ok := file.FileMode()
+	if !ok {
+		return nil
+	}
+	return os.Chmod(filePath, mode)
+}
+
+// restoreMetadataEntries recrée les répertoires, fichiers vides et liens enregistrés sans données
+func (m *Manager) restoreMetadataEntries(backupIndex *index.BackupIndex, destinationPath string, restorePaths map[string]string) error {
+	for _, file := range backupIndex.Files {
+		if !file.IsMetadataOnly() {
+			continue
+		}
+
+		destPath := utils.LongPath(filepath.Join(destinationPath, restorePaths[file.Path]))
+		if file.IsDirectory {
+			if err := utils.EnsureDirectory(destPath); err != nil {
+				return fmt.Errorf("error creating directory %s: %w", destPath, err)
+			}
+			continue
+		}
+
+		if err := utils.EnsureDirectory(filepath.Dir(destPath)); err != nil {
+			return fmt.Errorf("error creating destination directory: %w", err)
+		}
+		if file.IsSymlink() {
+			if err := restoreSymlink(destPath, file.LinkTarget); err != nil {
+				return fmt.Errorf("error creating symlink %s: %w", destPath, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(destPath, nil, 0644); err != nil {
+			return fmt.Errorf("error creating empty file %s: %w", destPath, err)
+		}
+		if err := m.restorePermissions(destPath, file); err != nil {
+			utils.Debug("Unable to restore permissions for %s: %v", destPath, err)
+		}
+	}
 	return nil
 }
 
+// restoreSymlink crée un lien symbolique, en remplaçant un fichier ou lien existant
+// (un lien déjà correct est conservé, un répertoire n'est jamais remplacé)
+func restoreSymlink(destPath, target string) error {
+	if existing, err := os.Lstat(destPath); err == nil {
+		if current, err := os.Readlink(destPath); err == nil && current == target {
+			return nil
+		}
+		if existing.IsDir() {
+			return fmt.Errorf("a directory already exists at this path")
+		}
+		if err := os.Remove(destPath); err != nil {
+			return err
+		}
+	}
+	return os.Symlink(target, destPath)
+}
+
+// applyDirectoryPermissions applique les permissions des répertoires, du plus profond au moins profond
+func (m *Manager) applyDirectoryPermissions(backupIndex *index.BackupIndex, destinationPath string, restorePaths map[string]string) {
+	var dirs []index.FileEntry
+	for _, file := range backupIndex.Files {
+		if file.IsDirectory && file.IsMetadataOnly() {
+			dirs = append(dirs, file)
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		return len(dirs[i].Path) > len(dirs[j].Path)
+	})
+
+	for _, dir := range dirs {
+		destPath := utils.LongPath(filepath.Join(destinationPath, restorePaths[dir.Path]))
+		if err := m.restorePermissions(destPath, dir); err != nil {
+			utils.Debug("Unable to restore permissions for %s: %v", destPath, err)
+		}
+	}
+}
+
+// planRestorePaths calcule le chemin relatif de destination de chaque entrée
+// en appliquant la normalisation Unicode demandée et en renommant les collisions
+// (chemins identiques après normalisation, ou ne différant que par la casse sur une destination insensible)
+func (m *Manager) planRestorePaths(backupIndex *index.BackupIndex, destinationPath string, verbose bool) map[string]string {
+	caseInsensitive := isCaseInsensitive(destinationPath)
+	if caseInsensitive && verbose {
+		utils.Info("   - Destination is case-insensitive: colliding paths will be renamed")
+	}
+	return m.restorePathPlan(backupIndex, caseInsensitive, verbose)
+}
+
+// restorePathPlan calcule les chemins de destination, les collisions étant renommées si la
+// destination ne distingue pas la casse
+func (m *Manager) restorePathPlan(backupIndex *index.BackupIndex, caseInsensitive, verbose bool) map[string]string {
+	plan := make(map[string]string, len(backupIndex.Files))
+	taken := make(map[string]bool)
+	collisions := 0
+
+	for _, file := range backupIndex.Files {
+		relPath := index.NormalizePath(relativeRestorePath(file.Path, backupIndex.SourcePath), m.unicodeForm)
+
+		// Les répertoires fusionnent naturellement: seules les collisions de fichiers sont renommées
+		if !file.IsDirectory {
+			key := collisionKey(relPath, caseInsensitive)
+			for n := 1; taken[key]; n++ {
+				relPath = collisionPath(relPath, n)
+				key = collisionKey(relPath, caseInsensitive)
+				if n == 1 {
+					collisions++
+				}
+			}
+			taken[key] = true
+		}
+
+		plan[file.Path] = relPath
+	}
+
+	if collisions > 0 {
+		if verbose {
+			utils.Warn("⚠️  %d path collisions on destination, colliding files renamed with a .bcrdf-collision-N suffix", collisions)
+		} else {
+			utils.ProgressWarning(fmt.Sprintf("%d path collisions renamed (.bcrdf-collision-N)", collisions))
+		}
+	}
+
+	return plan
+}
+
+// collisionKey retourne la clé sous laquelle la destination identifie un chemin
+func collisionKey(path string, caseInsensitive bool) string {
+	key := index.PathKey(path)
+	if caseInsensitive {
+		key = strings.ToLower(key)
+	}
+	return key
+}
+
+// collisionPath insère un suffixe de collision avant l'extension
+func collisionPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if previous := strings.LastIndex(base, ".bcrdf-collision-"); previous >= 0 {
+		base = base[:previous]
+	}
+	return fmt.Sprintf("%s.bcrdf-collision-%d%s", base, n, ext)
+}
+
+// isCaseInsensitive détecte si le système de fichiers de destination ignore la casse
+func isCaseInsensitive(destinationPath string) bool {
+	if err := utils.EnsureDirectory(destinationPath); err != nil {
+		return false
+	}
+
+	probe, err := os.CreateTemp(destinationPath, "bcrdf-case-probe-")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	defer os.Remove(probe.Name())
+
+	upper := filepath.Join(filepath.Dir(probe.Name()), strings.ToUpper(filepath.Base(probe.Name())))
+	_, err = os.Stat(upper)
+	return err == nil
+}
+
+// relativeRestorePath retourne le chemin relatif à la racine de sauvegarde, avec les séparateurs locaux
+func relativeRestorePath(path, sourcePath string) string {
+	return filepath.FromSlash(index.RelativeToSource(path, sourcePath))
+}
+
 // loadFromStorage charge un objet depuis le stockage
 func (m *Manager) loadFromStorage(key string) ([]byte, error) {
 	return m.storageClient.Download(key)
 }
 
-// downloadWithRetry télécharge avec retry et timeout
+// downloadWithRetry télécharge avec retry et timeout. Chaque tentative lit l'objet en flux sous
+// un contexte annulé à l'expiration du timeout: un téléchargement abandonné est interrompu au
+// lieu de continuer en arrière-plan.
 func (m *Manager) downloadWithRetry(key string) ([]byte, error) {
 	// Timeout pour éviter les blocages infinis
 	timeout := time.Duration(m.config.Backup.NetworkTimeout) * time.Second
@@ -818,6 +1160,7 @@ func (m *Manager) downloadWithRetry(key string) ([]byte, error) {
 	}
 
 	var lastError error
+	var buf bytes.Buffer
 
 	// Boucle de retry avec backoff exponentiel
 	for attempt := 0; attempt < maxRetries; attempt++ {
@@ -835,46 +1178,37 @@ func (m *Manager) downloadWithRetry(key string) ([]byte, error) {
 		}
 
 		// Créer un contexte avec timeout pour cette tentative
+		buf.Reset()
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-
-		// Canal pour le résultat
-		resultChan := make(chan downloadResult, 1)
-
-		// Exécuter le téléchargement en arrière-plan
-		go func() {
-			data, err := m.storageClient.Download(key)
-			resultChan <- downloadResult{data: data, err: err}
-		}()
-
-		// Attendre avec timeout
-		select {
-		case result := <-resultChan:
-			cancel()
-			if result.err == nil {
-				// Succès !
-				if attempt > 0 {
-					utils.Info("✅ Download succeeded on retry attempt %d for %s", attempt+1, key)
-				}
-				return result.data, nil
-			}
-
-			// Erreur, la stocker pour le log final
-			lastError = result.err
-
-			// Log de l'erreur
-			if attempt < maxRetries-1 {
-				utils.Warn("⚠️  Download failed for %s (attempt %d/%d): %v",
-					key, attempt+1, maxRetries, result.err)
+		n, err := m.storageClient.DownloadStream(ctx, key, &buf)
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if err == nil {
+			// Succès !
+			if attempt > 0 {
+				utils.Info("✅ Download succeeded on retry attempt %d for %s", attempt+1, key)
 			}
+			m.limiter.Wait(n)
+			return buf.Bytes(), nil
+		}
 
-		case <-ctx.Done():
-			cancel()
+		if timedOut {
 			lastError = fmt.Errorf("download timeout after %v", timeout)
-
 			if attempt < maxRetries-1 {
 				utils.Warn("⚠️  Download timeout for %s (attempt %d/%d) after %v",
 					key, attempt+1, maxRetries, timeout)
 			}
+			continue
+		}
+
+		// Erreur, la stocker pour le log final
+		lastError = err
+
+		// Log de l'erreur
+		if attempt < maxRetries-1 {
+			utils.Warn("⚠️  Download failed for %s (attempt %d/%d): %v",
+				key, attempt+1, maxRetries, err)
 		}
 	}
 
@@ -884,9 +1218,3 @@ func (m *Manager) downloadWithRetry(key string) ([]byte, error) {
 
 	return nil, fmt.Errorf("download failed after %d attempts for %s: %w", maxRetries, key, lastError)
 }
-
-// downloadResult contient le résultat d'un téléchargement
-type downloadResult struct {
-	data []byte
-	err  error
-}
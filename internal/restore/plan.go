@@ -0,0 +1,65 @@
+package restore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// downloadPlan regroupe les entrées qui lisent le même objet, pour ne le télécharger qu'une fois
+type downloadPlan struct {
+	downloads []index.FileEntry            // Une entrée par objet unique, restaurée depuis le stockage
+	copies    map[string][]index.FileEntry // Entrées recopiées depuis le fichier restauré, par chemin de l'entrée téléchargée
+}
+
+// planDownloads construit le plan de téléchargement (ordre des entrées conservé)
+func planDownloads(files []index.FileEntry) downloadPlan {
+	plan := downloadPlan{copies: make(map[string][]index.FileEntry)}
+	first := make(map[string]string, len(files)) // Objet (sauvegarde détentrice, clé) -> chemin de l'entrée téléchargée
+	for _, file := range files {
+		object := file.DataKey("")
+		if path, ok := first[object]; ok {
+			plan.copies[path] = append(plan.copies[path], file)
+			continue
+		}
+		first[object] = file.Path
+		plan.downloads = append(plan.downloads, file)
+	}
+	return plan
+}
+
+// duplicates retourne le nombre d'entrées restaurées par copie locale
+func (p downloadPlan) duplicates() int {
+	count := 0
+	for _, copies := range p.copies {
+		count += len(copies)
+	}
+	return count
+}
+
+// copyRestoredFile recopie un fichier déjà restauré vers une autre destination
+func copyRestoredFile(srcPath, destPath string) error {
+	src, err := os.Open(utils.LongPath(srcPath))
+	if err != nil {
+		return fmt.Errorf("error opening restored copy: %w", err)
+	}
+	defer src.Close()
+
+	destPath = utils.LongPath(destPath)
+	if err := utils.EnsureDirectory(filepath.Dir(destPath)); err != nil {
+		return fmt.Errorf("error creating destination directory: %w", err)
+	}
+	dest, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		dest.Close()
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return dest.Close()
+}
@@ -0,0 +1,45 @@
+package restore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bcrdf/internal/index"
+)
+
+func TestPlanDownloadsFetchesEachObjectOnce(t *testing.T) {
+	files := []index.FileEntry{
+		{Path: "a.txt", StorageKey: "k1", Size: 3},
+		{Path: "b.txt", StorageKey: "k2", Size: 4},
+		{Path: "copy/a.txt", StorageKey: "k1", Size: 3},
+		{Path: "copy2/a.txt", StorageKey: "k1", Size: 3},
+	}
+
+	plan := planDownloads(files)
+	if len(plan.downloads) != 2 || plan.downloads[0].Path != "a.txt" || plan.downloads[1].Path != "b.txt" {
+		t.Fatalf("téléchargements inattendus: %+v", plan.downloads)
+	}
+	if copies := plan.copies["a.txt"]; len(copies) != 2 || copies[0].Path != "copy/a.txt" {
+		t.Fatalf("copies inattendues: %+v", plan.copies)
+	}
+	if plan.duplicates() != 2 {
+		t.Errorf("2 doublons attendus, obtenu %d", plan.duplicates())
+	}
+}
+
+func TestCopyRestoredFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(src, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(dir, "nested", "b.txt")
+	if err := copyRestoredFile(src, dest); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "data" {
+		t.Fatalf("copie incorrecte: %q, %v", data, err)
+	}
+}
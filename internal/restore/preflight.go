@@ -0,0 +1,78 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// spaceMargin est la part d'espace libre gardée en réserve au-delà des données à restaurer
+// (métadonnées du système de fichiers, blocs partiels)
+const spaceMargin = 0.05
+
+// spaceRequirement est l'espace et le nombre d'inodes nécessaires à une restauration
+type spaceRequirement struct {
+	bytes  int64
+	inodes uint64
+}
+
+// requiredSpace calcule ce que la restauration écrira dans destinationPath: les fichiers déjà présents
+// sont déduits (remplacés ou conservés), seules les entrées nouvelles consomment un inode
+func requiredSpace(backupIndex *index.BackupIndex, destinationPath string) spaceRequirement {
+	var required spaceRequirement
+	for _, file := range backupIndex.Files {
+		if file.IsSkipped() || file.Status == index.FileStatusFailed {
+			continue
+		}
+		target := filepath.Join(destinationPath, relativeRestorePath(file.Path, backupIndex.SourcePath))
+		info, err := os.Lstat(utils.LongPath(target))
+		if err != nil {
+			required.inodes++
+		}
+		if !file.HasData() {
+			continue
+		}
+		size := file.Size
+		if err == nil && info.Mode().IsRegular() {
+			size -= info.Size()
+		}
+		if size > 0 {
+			required.bytes += size
+		}
+	}
+	return required
+}
+
+// checkDestinationSpace échoue avant tout téléchargement si la destination n'a pas assez
+// d'espace libre ou d'inodes pour la restauration
+func (m *Manager) checkDestinationSpace(backupIndex *index.BackupIndex, destinationPath string, verbose bool) error {
+	usage, err := utils.DiskUsageOf(destinationPath)
+	if err != nil {
+		utils.Debug("Destination space check skipped: %v", err)
+		return nil
+	}
+	required := requiredSpace(backupIndex, destinationPath)
+	if verbose {
+		utils.Info("   - Space required: %s (%d new entries), available: %s",
+			utils.FormatBytes(required.bytes), required.inodes, utils.FormatBytes(int64(usage.Free)))
+	}
+	return checkSpace(usage, required, destinationPath)
+}
+
+// checkSpace compare l'occupation d'un système de fichiers à un besoin
+func checkSpace(usage utils.DiskUsage, required spaceRequirement, destinationPath string) error {
+	needed := uint64(float64(required.bytes) * (1 + spaceMargin))
+	if needed > usage.Free {
+		return fmt.Errorf("%w: restoring to %s needs %s (including %.0f%% margin) but only %s is available",
+			utils.ErrInsufficientSpace, destinationPath, utils.FormatBytes(int64(needed)), spaceMargin*100, utils.FormatBytes(int64(usage.Free)))
+	}
+	// Files = 0: système de fichiers sans limite d'inodes connue
+	if usage.Files > 0 && required.inodes > usage.FreeFiles {
+		return fmt.Errorf("%w: restoring to %s needs %d inodes but only %d are free",
+			utils.ErrInsufficientSpace, destinationPath, required.inodes, usage.FreeFiles)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package restore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+func TestRequiredSpaceDeductsExistingFiles(t *testing.T) {
+	dest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dest, "a.txt"), make([]byte, 40), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backupIndex := &index.BackupIndex{
+		SourcePath: "/src",
+		Files: []index.FileEntry{
+			{Path: "/src/a.txt", StorageKey: "k1", Size: 100},
+			{Path: "/src/b.txt", StorageKey: "k2", Size: 50},
+			{Path: "/src/dir", IsDirectory: true},
+			{Path: "/src/failed.txt", StorageKey: "k3", Size: 1000, Status: index.FileStatusFailed},
+		},
+	}
+
+	required := requiredSpace(backupIndex, dest)
+	if required.bytes != 110 {
+		t.Errorf("110 octets attendus (60 + 50), obtenu %d", required.bytes)
+	}
+	if required.inodes != 2 {
+		t.Errorf("2 inodes attendus (b.txt, dir), obtenu %d", required.inodes)
+	}
+}
+
+func TestCheckSpace(t *testing.T) {
+	usage := utils.DiskUsage{Total: 1000, Free: 100, Files: 10, FreeFiles: 2}
+
+	if err := checkSpace(usage, spaceRequirement{bytes: 90, inodes: 2}, "/dest"); err != nil {
+		t.Errorf("espace suffisant refusé: %v", err)
+	}
+	if err := checkSpace(usage, spaceRequirement{bytes: 99, inodes: 1}, "/dest"); !errors.Is(err, utils.ErrInsufficientSpace) {
+		t.Errorf("marge non appliquée: %v", err)
+	}
+	if err := checkSpace(usage, spaceRequirement{bytes: 10, inodes: 3}, "/dest"); !errors.Is(err, utils.ErrInsufficientSpace) {
+		t.Errorf("manque d'inodes non détecté: %v", err)
+	}
+	// Sans compteur d'inodes (Windows), seul l'espace est vérifié
+	usage.Files = 0
+	if err := checkSpace(usage, spaceRequirement{bytes: 10, inodes: 3}, "/dest"); err != nil {
+		t.Errorf("inodes vérifiés sans compteur: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"bcrdf/internal/index"
+)
+
+// Politiques appliquées quand un fichier existe déjà à la destination
+const (
+	ConflictOverwrite = "overwrite" // Remplacer le fichier existant (défaut)
+	ConflictSkip      = "skip"      // Conserver le fichier existant
+	ConflictNewer     = "newer"     // Remplacer seulement si la version sauvegardée est plus récente
+)
+
+// SetPathFilters limite la restauration aux chemins (relatifs à la source) correspondant aux motifs
+// Un motif désigne un fichier, un répertoire (avec tout son contenu) ou un glob (*.pdf, docs/*.txt)
+func (m *Manager) SetPathFilters(patterns []string) error {
+	m.pathFilters = nil
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid path filter %q: %w", pattern, err)
+		}
+		m.pathFilters = append(m.pathFilters, pattern)
+	}
+	return nil
+}
+
+// SetConflictPolicy définit le comportement face aux fichiers déjà présents à la destination
+func (m *Manager) SetConflictPolicy(policy string) error {
+	switch policy {
+	case "", ConflictOverwrite, ConflictSkip, ConflictNewer:
+		m.conflictPolicy = policy
+		return nil
+	default:
+		return fmt.Errorf("invalid conflict policy %q (expected overwrite, skip or newer)", policy)
+	}
+}
+
+// CountMatching retourne le nombre d'entrées de l'index sélectionnées par les filtres de chemins
+func (m *Manager) CountMatching(backupIndex *index.BackupIndex) int {
+	return len(m.selectFiles(backupIndex).Files)
+}
+
+// selectFiles retourne une copie de l'index limitée aux entrées correspondant aux filtres
+func (m *Manager) selectFiles(backupIndex *index.BackupIndex) *index.BackupIndex {
+	if len(m.pathFilters) == 0 {
+		return backupIndex
+	}
+
+	selected := *backupIndex
+	selected.Files = nil
+	selected.TotalFiles = 0
+	selected.TotalSize = 0
+	for _, file := range backupIndex.Files {
+		if !matchesPathFilters(index.RelativeToSource(file.Path, backupIndex.SourcePath), m.pathFilters) {
+			continue
+		}
+		selected.Files = append(selected.Files, file)
+		if !file.IsDirectory {
+			selected.TotalFiles++
+			selected.TotalSize += file.Size
+		}
+	}
+	return &selected
+}
+
+// matchesPathFilters indique si un chemin relatif correspond à l'un des motifs
+func matchesPathFilters(relPath string, patterns []string) bool {
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	for _, pattern := range patterns {
+		if relPath == pattern || strings.HasPrefix(relPath, pattern+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return true
+		}
+		// Un motif sans répertoire (*.pdf) s'applique au nom du fichier à toute profondeur
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(relPath)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keepExisting indique si le fichier déjà présent à la destination doit être conservé
+func (m *Manager) keepExisting(destPath string, file index.FileEntry, mode string) bool {
+	if m.conflictPolicy == "" || m.conflictPolicy == ConflictOverwrite {
+		return false
+	}
+	info, err := os.Lstat(destPath)
+	if err != nil {
+		return false
+	}
+	switch m.conflictPolicy {
+	case ConflictNewer:
+		return !file.ModifiedTime.After(info.ModTime())
+	case conflictUnchanged:
+		return info.Mode().IsRegular() && isUnchanged(destPath, file, mode)
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+package restore
+
+import "testing"
+
+func TestMatchesPathFilters(t *testing.T) {
+	patterns := []string{"docs", "photos/2024/*.jpg", "*.pdf"}
+
+	cases := map[string]bool{
+		"docs":                    true,
+		"docs/report.odt":         true,
+		"docs2/report.odt":        false,
+		"photos/2024/beach.jpg":   true,
+		"photos/2023/beach.jpg":   false,
+		"archives/old/bill.pdf":   true,
+		"archives/old/bill.pdf.1": false,
+	}
+	for relPath, expected := range cases {
+		if got := matchesPathFilters(relPath, patterns); got != expected {
+			t.Errorf("Filtre incorrect pour %s: attendu %v, obtenu %v", relPath, expected, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package restore
+
+import (
+	"fmt"
+
+	"bcrdf/internal/share"
+	"bcrdf/pkg/utils"
+)
+
+// SetShare restaure depuis un partage pré-signé (bcrdf share) au lieu du fichier de configuration
+// La clé de chiffrement est transmise à part; identityFile déchiffre les données chiffrées vers des destinataires age
+func (m *Manager) SetShare(bundle *share.Bundle, encryptionKey, identityFile string) error {
+	config, err := bundle.Config(encryptionKey, identityFile)
+	if err != nil {
+		return err
+	}
+	m.share, m.config = bundle, config
+	return nil
+}
+
+// loadConfig charge la configuration, sauf pour un partage qui fournit la sienne
+func (m *Manager) loadConfig(backupID string) error {
+	if m.share != nil {
+		if m.share.BackupID != backupID {
+			return fmt.Errorf("%w: the share only grants access to %s", utils.ErrConfig, m.share.BackupID)
+		}
+		return nil
+	}
+
+	config, err := utils.LoadConfig(m.configFile)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	m.config = config
+	return nil
+}
@@ -0,0 +1,126 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// LatestBackup désigne la sauvegarde la plus récente (sync et verify --backup-id latest)
+const LatestBackup = index.LatestBackup
+
+// conflictUnchanged conserve les fichiers de la destination identiques à la sauvegarde (taille et checksum)
+const conflictUnchanged = "unchanged"
+
+// SyncBackup aligne destinationPath sur une sauvegarde (copie de secours à chaud): seuls les fichiers
+// absents ou modifiés sont téléchargés, et les fichiers de la destination absents de la sauvegarde
+// sont supprimés. backupID peut valoir "latest" (la plus récente, limitée au nom name s'il est donné).
+func (m *Manager) SyncBackup(backupID, name, destinationPath string, verbose bool) (err error) {
+	if err := m.loadConfig(backupID); err != nil {
+		return err
+	}
+	if err := m.initializeComponents(); err != nil {
+		return fmt.Errorf("initialization error: %w", err)
+	}
+	if err := m.applyThrottle(verbose); err != nil {
+		return err
+	}
+
+	if backupID == LatestBackup {
+		latest, err := m.indexMgr.LatestBackupID(name)
+		if err != nil {
+			return err
+		}
+		backupID = latest
+	}
+	m.startAudit("sync", backupID, destinationPath)
+	defer func() { err = m.finishAudit(err) }()
+	if verbose {
+		utils.Info("🔄 Syncing %s with backup %s", destinationPath, backupID)
+	} else {
+		utils.ProgressStep(utils.Msg("sync.step.start", destinationPath, backupID))
+	}
+
+	backupIndex, err := m.indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return fmt.Errorf("error loading index: %w", err)
+	}
+	if err := utils.EnsureDirectory(destinationPath); err != nil {
+		return fmt.Errorf("error creating directory de destination: %w", err)
+	}
+	if err := m.checkDestinationSpace(backupIndex, destinationPath, verbose); err != nil {
+		return err
+	}
+
+	m.conflictPolicy = conflictUnchanged
+	if err := m.restoreFiles(backupIndex, destinationPath, verbose); err != nil {
+		// Destination incomplète: rien n'est supprimé tant que la synchronisation n'a pas abouti
+		return fmt.Errorf("error synchronizing files: %w", err)
+	}
+
+	removed, err := m.removeExtraneous(backupIndex, destinationPath)
+	if err != nil {
+		return fmt.Errorf("error removing extraneous files: %w", err)
+	}
+	if verbose {
+		utils.Info("   - %d extraneous entries removed", removed)
+		utils.Info("🎯 Destination in sync with %s", backupID)
+	} else {
+		utils.ProgressSuccess(utils.Msg("sync.step.done", destinationPath, backupID, removed))
+	}
+	return nil
+}
+
+// removeExtraneous supprime les fichiers et répertoires de la destination absents de la sauvegarde
+// Retourne le nombre d'entrées supprimées.
+func (m *Manager) removeExtraneous(backupIndex *index.BackupIndex, destinationPath string) (int, error) {
+	restorePaths := m.planRestorePaths(backupIndex, destinationPath, false)
+	expected := make(map[string]bool, len(restorePaths))
+	for _, relPath := range restorePaths {
+		// Les répertoires parents des entrées sont conservés
+		for p := filepath.Clean(relPath); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+			expected[p] = true
+		}
+	}
+
+	var extraneous []string
+	err := filepath.Walk(destinationPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(destinationPath, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		if expected[relPath] {
+			return nil
+		}
+		extraneous = append(extraneous, path)
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sort.Strings(extraneous)
+	for _, path := range extraneous {
+		utils.Debug("Removing extraneous entry: %s", path)
+		if err := os.RemoveAll(path); err != nil {
+			return 0, err
+		}
+	}
+	return len(extraneous), nil
+}
+
+// isUnchanged indique si le fichier de la destination est identique à son entrée d'index
+// (mode: mode de checksum de la sauvegarde)
+func isUnchanged(destPath string, file index.FileEntry, mode string) bool {
+	return index.MatchesEntry(destPath, file, mode)
+}
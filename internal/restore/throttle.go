@@ -0,0 +1,67 @@
+package restore
+
+import (
+	"fmt"
+
+	"bcrdf/pkg/utils"
+)
+
+// SetThrottle surcharge restore_nice (nice < 0 = configuration), restore_io_priority et
+// restore_rate_limit (vides = configuration)
+func (m *Manager) SetThrottle(nice int, ioPriority, rateLimit string) error {
+	if nice > 19 {
+		return fmt.Errorf("%w: invalid --nice %d (expected 0-19)", utils.ErrConfig, nice)
+	}
+	if err := utils.ValidateIOPriority(ioPriority); err != nil {
+		return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	if rateLimit != "" {
+		if _, err := utils.ParseSize(rateLimit); err != nil {
+			return fmt.Errorf("%w: invalid --rate-limit: %w", utils.ErrConfig, err)
+		}
+	}
+	m.nice, m.ioPriority, m.rateLimit = nice, ioPriority, rateLimit
+	return nil
+}
+
+// applyThrottle abaisse la priorité du processus et prépare la limite de débit des téléchargements
+func (m *Manager) applyThrottle(verbose bool) error {
+	nice, ioPriority, rateLimit := m.config.Backup.RestoreNice, m.config.Backup.RestoreIOPriority, m.config.Backup.RestoreRateLimit
+	if m.nice >= 0 {
+		nice = m.nice
+	}
+	if m.ioPriority != "" {
+		ioPriority = m.ioPriority
+	}
+	if m.rateLimit != "" {
+		rateLimit = m.rateLimit
+	}
+
+	if err := utils.LowerPriority(nice, ioPriority); err != nil {
+		// Une priorité non appliquée ne doit pas empêcher une restauration
+		utils.Warn("⚠️  Restore priority not applied: %v", err)
+	}
+
+	if m.rateLimit != "" {
+		limit, err := utils.ParseSize(m.rateLimit)
+		if err != nil {
+			return fmt.Errorf("%w: invalid --rate-limit: %w", utils.ErrConfig, err)
+		}
+		m.limiter = utils.NewRateLimiter(limit)
+	} else {
+		m.limiter = utils.RestoreRateLimiter(m.config)
+	}
+
+	if verbose && (nice > 0 || m.limiter != nil || (ioPriority != "" && ioPriority != utils.IOPriorityNormal)) {
+		utils.Info("🐢 Restore throttled: nice %d, io priority %s, rate limit %s", nice, valueOr(ioPriority, utils.IOPriorityNormal), valueOr(rateLimit, "none"))
+	}
+	return nil
+}
+
+// valueOr retourne value, ou fallback si elle est vide
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
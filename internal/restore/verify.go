@@ -0,0 +1,76 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"bcrdf/internal/index"
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// NewVerifier crée un gestionnaire de restauration pour les vérifications (health --test-restore)
+func NewVerifier(config *utils.Config, indexMgr *index.Manager, storageClient storage.Client) (*Manager, error) {
+	m := &Manager{
+		config:        config,
+		indexMgr:      indexMgr,
+		storageClient: storageClient,
+		nice:          -1,
+		limiter:       utils.RestoreRateLimiter(config),
+	}
+	if err := m.initializeCodecs(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VerifyFiles restaure des fichiers dans la zone temporaire (supprimée ensuite), en lecture seule
+// pour le dépôt, et vérifie leur taille et leur checksum. Retourne une erreur par fichier invalide.
+func (m *Manager) VerifyFiles(backupIndex *index.BackupIndex, files []index.FileEntry, verbose bool) []string {
+	temp, err := tempdir.Open(m.config)
+	if err != nil {
+		return []string{fmt.Sprintf("Cannot create scratch directory: %v", err)}
+	}
+	defer temp.Close()
+	scratchDir, err := temp.MkdirTemp("verify-")
+	if err != nil {
+		return []string{fmt.Sprintf("Cannot create scratch directory: %v", err)}
+	}
+
+	var errors []string
+	for _, file := range files {
+		// Un fichier à la fois: la réservation est libérée après sa vérification
+		release, err := temp.Reserve(file.Size)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Test restore of %s skipped: %v", file.Path, err))
+			continue
+		}
+		problem := m.verifyFile(backupIndex, file, scratchDir)
+		release()
+		if problem != "" {
+			errors = append(errors, problem)
+			continue
+		}
+
+		if verbose {
+			utils.Info("✅ Test file %s restored and verified", file.Path)
+		}
+	}
+	return errors
+}
+
+// verifyFile restaure un fichier dans scratchDir, le vérifie puis le supprime (message vide si valide)
+func (m *Manager) verifyFile(backupIndex *index.BackupIndex, file index.FileEntry, scratchDir string) string {
+	restoredPath := utils.LongPath(filepath.Join(scratchDir, file.Path))
+	defer os.Remove(restoredPath)
+
+	if err := m.restoreSingleFile(file, backupIndex, scratchDir, nil, false); err != nil {
+		return fmt.Sprintf("Test restore of %s failed: %v", file.Path, err)
+	}
+	if err := index.VerifyRestored(restoredPath, file, backupIndex.EffectiveChecksumMode()); err != nil {
+		return fmt.Sprintf("Test restore of %s: %v", file.Path, err)
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// RunInteractive guide l'opérateur (sauvegarde, date, chemins, destination, conflits) puis restaure
+func (m *Manager) RunInteractive(verbose bool) error {
+	utils.PrintHeader("BCRDF Interactive Restore")
+
+	fmt.Println("This wizard will help you restore files from a backup.")
+	fmt.Println("Press Enter to use default values shown in brackets.")
+
+	indexMgr := index.NewManager(m.configFile)
+	refs, err := indexMgr.ListBackupRefs()
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("no backup found in the repository")
+	}
+
+	ref := chooseBackup(refs)
+
+	utils.PrintSection("Files to restore")
+	backupIndex, err := indexMgr.LoadIndex(ref.ID)
+	if err != nil {
+		return fmt.Errorf("error loading index: %w", err)
+	}
+	utils.PrintInfo(fmt.Sprintf("%s contains %d files (%.2f MB) from %s",
+		ref.ID, backupIndex.TotalFiles, float64(backupIndex.TotalSize)/1024/1024, backupIndex.SourcePath))
+	filters := m.promptPathFilters(backupIndex)
+
+	utils.PrintSection("Destination")
+	destination := promptDestination(ref.ID)
+	conflict := ConflictOverwrite
+	if !isEmptyDir(destination) {
+		utils.PrintWarning(fmt.Sprintf("%s already contains files", destination))
+		policies := []string{
+			"overwrite: replace existing files",
+			"skip: keep existing files",
+			"newer: replace only files older than the backed up version",
+		}
+		conflict = []string{ConflictOverwrite, ConflictSkip, ConflictNewer}[utils.PromptChoice("When a file already exists:", policies, 1)]
+	}
+	if err := m.SetConflictPolicy(conflict); err != nil {
+		return err
+	}
+
+	utils.PrintSection("Summary")
+	fmt.Printf("  Backup:      %s (%s)\n", ref.ID, ref.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	if len(filters) > 0 {
+		fmt.Printf("  Paths:       %s\n", strings.Join(filters, ", "))
+	} else {
+		fmt.Printf("  Paths:       everything\n")
+	}
+	fmt.Printf("  Destination: %s\n", destination)
+	fmt.Printf("  Conflicts:   %s\n", conflict)
+	fmt.Printf("\n  Equivalent command: bcrdf restore -c %s -b %s -d %s%s --conflict %s\n",
+		m.configFile, ref.ID, destination, includeFlags(filters), conflict)
+
+	if !utils.PromptYesNo("\nStart the restore?", true) {
+		utils.PrintInfo("Restore cancelled")
+		return nil
+	}
+	return m.RestoreBackup(ref.ID, destination, verbose)
+}
+
+// chooseBackup fait choisir une sauvegarde (nom puis date) parmi celles du dépôt
+func chooseBackup(refs []index.BackupRef) index.BackupRef {
+	utils.PrintSection("Backup selection")
+
+	byName := make(map[string][]index.BackupRef)
+	for _, ref := range refs {
+		byName[ref.Name] = append(byName[ref.Name], ref)
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	name := names[0]
+	if len(names) > 1 {
+		labels := make([]string, len(names))
+		for i, n := range names {
+			labels[i] = fmt.Sprintf("%s (%d backups, latest %s)", n, len(byName[n]), byName[n][0].CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		name = names[utils.PromptChoice("Select the backup job:", labels, 0)]
+	} else {
+		utils.PrintInfo(fmt.Sprintf("Backup job: %s", name))
+	}
+
+	candidates := byName[name]
+	labels := make([]string, len(candidates))
+	for i, ref := range candidates {
+		labels[i] = fmt.Sprintf("%s  (%s)", ref.CreatedAt.Local().Format("Mon 2006-01-02 15:04:05"), ref.ID)
+	}
+	return candidates[utils.PromptChoice("Select the backup date (most recent first):", labels, 0)]
+}
+
+// promptPathFilters demande les chemins à restaurer jusqu'à ce qu'ils sélectionnent au moins un fichier
+func (m *Manager) promptPathFilters(backupIndex *index.BackupIndex) []string {
+	for {
+		input := utils.PromptString("Paths to restore, relative to the source (comma-separated, globs allowed, empty = everything)", "")
+		var filters []string
+		for _, part := range strings.Split(input, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				filters = append(filters, part)
+			}
+		}
+
+		if err := m.SetPathFilters(filters); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		if len(filters) == 0 {
+			return nil
+		}
+		count := m.CountMatching(backupIndex)
+		if count == 0 {
+			fmt.Printf("❌ No file matches these paths. Please try again.\n")
+			continue
+		}
+		utils.PrintSuccess(fmt.Sprintf("%d entries selected", count))
+		return filters
+	}
+}
+
+// promptDestination demande un répertoire de destination utilisable
+func promptDestination(backupID string) string {
+	for {
+		destination := utils.PromptString("Destination directory", "./restore-"+backupID)
+		info, err := os.Stat(destination)
+		if err == nil && !info.IsDir() {
+			fmt.Printf("❌ %s exists and is not a directory.\n", destination)
+			continue
+		}
+		return destination
+	}
+}
+
+// isEmptyDir indique si un répertoire est absent ou vide
+func isEmptyDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err != nil || len(entries) == 0
+}
+
+// includeFlags reconstruit les options --include de la ligne de commande équivalente
+func includeFlags(filters []string) string {
+	var flags strings.Builder
+	for _, filter := range filters {
+		fmt.Fprintf(&flags, " --include %q", filter)
+	}
+	return flags.String()
+}
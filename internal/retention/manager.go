@@ -2,12 +2,12 @@ package retention
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"bcrdf/internal/gc"
 	"bcrdf/internal/index"
 	"bcrdf/pkg/storage"
 	"bcrdf/pkg/utils"
@@ -18,6 +18,9 @@ type Manager struct {
 	config        *utils.Config
 	indexMgr      *index.Manager
 	storageClient storage.Client
+
+	clockChecked bool
+	clockOffset  time.Duration // Correction vers l'heure du stockage (backup.max_clock_skew dépassé)
 }
 
 // BackupInfo contient les informations d'une sauvegarde pour la rétention
@@ -41,8 +44,22 @@ func (m *Manager) ApplyRetentionPolicy(verbose bool) error {
 	return m.ApplyRetentionPolicyForBackup("", verbose)
 }
 
-// ApplyRetentionPolicyForBackup applique la politique de rétention pour un nom de backup spécifique
+// ApplyRetentionPolicyForBackup applique la politique de rétention pour un nom de backup spécifique,
+// puis le quota du dépôt (retention.max_total_size) s'il est configuré
 func (m *Manager) ApplyRetentionPolicyForBackup(backupName string, verbose bool) error {
+	if err := m.applyRetentionRules(backupName, verbose); err != nil {
+		return err
+	}
+	result, err := m.EnforceQuota(verbose)
+	reportQuota(result, verbose)
+	return err
+}
+
+// applyRetentionRules supprime les sauvegardes sélectionnées par days et max_backups
+func (m *Manager) applyRetentionRules(backupName string, verbose bool) error {
+	if err := utils.CheckDeletesAllowed(m.config, "retention"); err != nil {
+		return err
+	}
 	if verbose {
 		utils.Info("🧹 Applying retention policy...")
 		if backupName != "" {
@@ -50,8 +67,11 @@ func (m *Manager) ApplyRetentionPolicyForBackup(backupName string, verbose bool)
 		}
 		utils.Info("   - Max age: %d days", m.config.Retention.Days)
 		utils.Info("   - Max backups: %d", m.config.Retention.MaxBackups)
+		if m.config.Retention.MinBackups > 0 {
+			utils.Info("   - Min backups: %d", m.config.Retention.MinBackups)
+		}
 	} else {
-		utils.ProgressStep("🧹 Applying retention policy")
+		utils.ProgressStep(utils.Msg("retention.step.start"))
 	}
 
 	// Récupérer toutes les sauvegardes disponibles
@@ -77,7 +97,7 @@ func (m *Manager) ApplyRetentionPolicyForBackup(backupName string, verbose bool)
 			if verbose {
 				utils.Info("No backups found for name '%s', nothing to clean up", backupName)
 			} else {
-				utils.ProgressSuccess("No backups to clean up")
+				utils.ProgressSuccess(utils.Msg("retention.step.nothing"))
 			}
 			return nil
 		}
@@ -109,12 +129,25 @@ func (m *Manager) ApplyRetentionPolicyForBackup(backupName string, verbose bool)
 
 	// Identifier les sauvegardes à supprimer
 	toDelete := m.identifyBackupsToDelete(backupsToProcess, verbose)
+	toDelete = m.protectLastGoodBackups(backupsToProcess, toDelete, verbose)
+	toDelete, floored := m.applyMinBackups(backupsToProcess, toDelete)
+	for _, backup := range floored {
+		if verbose {
+			utils.Info("Keeping backup %s: retention.min_backups is %d", backup.ID, m.config.Retention.MinBackups)
+		} else {
+			utils.ProgressInfo(fmt.Sprintf("Keeping %s (min_backups)", backup.ID))
+		}
+	}
+	toDelete, err = m.protectHeldBackups(toDelete, verbose)
+	if err != nil {
+		return err
+	}
 
 	if len(toDelete) == 0 {
 		if verbose {
 			utils.Info("✅ No backups need to be deleted")
 		} else {
-			utils.ProgressSuccess("Retention policy satisfied")
+			utils.ProgressSuccess(utils.Msg("retention.step.satisfied"))
 		}
 		return nil
 	}
@@ -175,27 +208,34 @@ func (m *Manager) getAllBackups(verbose bool) ([]BackupInfo, error) {
 	return backups, nil
 }
 
-// parseBackupTimestamp extrait la date d'un ID de sauvegarde
-func (m *Manager) parseBackupTimestamp(backupID string) (time.Time, error) {
-	// Format attendu: backup-name-20060102-150405
-	parts := strings.Split(backupID, "-")
-	if len(parts) < 3 {
-		return time.Time{}, fmt.Errorf("invalid backup ID format: %s", backupID)
+// now retourne l'heure de référence des âges: celle du stockage si l'horloge locale s'en écarte
+// au-delà de backup.max_clock_skew (mesure faite une fois par exécution)
+func (m *Manager) now() time.Time {
+	if !m.clockChecked {
+		m.clockChecked = true
+		if m.storageClient != nil {
+			m.clockOffset = storage.CheckClockSkew(m.config, m.storageClient)
+			if m.clockOffset != 0 {
+				utils.Info("🕒 Using the storage server time to compute backup ages")
+			}
+		}
 	}
+	return time.Now().Add(m.clockOffset)
+}
 
-	// Prendre les deux dernières parties (date et heure)
-	dateStr := parts[len(parts)-2]
-	timeStr := parts[len(parts)-1]
-
-	timestampStr := fmt.Sprintf("%s-%s", dateStr, timeStr)
-
-	return time.Parse("20060102-150405", timestampStr)
+// parseBackupTimestamp extrait la date d'un ID de sauvegarde (IDs UTC et anciens IDs en heure locale)
+func (m *Manager) parseBackupTimestamp(backupID string) (time.Time, error) {
+	ref, err := index.ParseBackupID(backupID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ref.CreatedAt, nil
 }
 
 // identifyBackupsToDelete identifie les sauvegardes à supprimer selon la politique
 func (m *Manager) identifyBackupsToDelete(backups []BackupInfo, verbose bool) []BackupInfo {
 	var toDelete []BackupInfo
-	now := time.Now()
+	now := m.now()
 	maxAge := time.Duration(m.config.Retention.Days) * 24 * time.Hour
 	maxBackups := m.config.Retention.MaxBackups
 
@@ -239,13 +279,146 @@ func (m *Manager) identifyBackupsToDelete(backups []BackupInfo, verbose bool) []
 	return toDelete
 }
 
+// protectLastGoodBackups retire de toDelete la sauvegarde complète la plus récente de chaque série:
+// les sauvegardes partielles ou en échec comptent dans max_backups mais ne remplacent jamais
+// la dernière sauvegarde restaurable en entier
+func (m *Manager) protectLastGoodBackups(backups, toDelete []BackupInfo, verbose bool) []BackupInfo {
+	if len(toDelete) == 0 {
+		return toDelete
+	}
+	protected := m.lastGoodBackups(backups, toDelete)
+
+	kept := toDelete[:0]
+	for _, backup := range toDelete {
+		if protected[backup.ID] {
+			if verbose {
+				utils.Info("Keeping backup %s: last complete backup of its series", backup.ID)
+			} else {
+				utils.ProgressInfo(fmt.Sprintf("Keeping %s (last complete backup)", backup.ID))
+			}
+			continue
+		}
+		kept = append(kept, backup)
+	}
+	return kept
+}
+
+// protectHeldBackups retire de toDelete les sauvegardes contenant des fichiers sous gel juridique
+// (bcrdf hold). Une sauvegarde dont l'index est illisible est conservée: le gel n'a pas pu être vérifié.
+func (m *Manager) protectHeldBackups(toDelete []BackupInfo, verbose bool) ([]BackupInfo, error) {
+	if len(toDelete) == 0 {
+		return toDelete, nil
+	}
+	holds, err := m.indexMgr.ListHolds()
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return toDelete, nil
+	}
+
+	kept := toDelete[:0]
+	for _, backup := range toDelete {
+		backupIndex, err := m.loadBackupIndexIfNeeded(backup)
+		if err != nil {
+			utils.Warn("Keeping backup %s: legal holds cannot be checked: %v", backup.ID, err)
+			continue
+		}
+		if hold, relPath := index.MatchHolds(holds, backupIndex); hold != nil {
+			if verbose {
+				utils.Info("Keeping backup %s: %s is under legal hold %q", backup.ID, relPath, hold.Pattern)
+			} else {
+				utils.ProgressInfo(fmt.Sprintf("Keeping %s (legal hold)", backup.ID))
+			}
+			continue
+		}
+		backup.Index = backupIndex
+		kept = append(kept, backup)
+	}
+	return kept, nil
+}
+
+// lastGoodBackups retourne les IDs des dernières sauvegardes complètes des séries ayant des
+// sauvegardes à supprimer (backups triées, plus récentes en premier). Les index sont chargés
+// du plus récent au plus ancien jusqu'à la première sauvegarde complète de chaque série.
+func (m *Manager) lastGoodBackups(backups, toDelete []BackupInfo) map[string]bool {
+	pending := make(map[string]bool)
+	for _, backup := range toDelete {
+		pending[backupSeries(backup.ID)] = true
+	}
+
+	protected := make(map[string]bool)
+	for _, backup := range backups {
+		series := backupSeries(backup.ID)
+		if !pending[series] {
+			continue
+		}
+		backupIndex, err := m.loadBackupIndexIfNeeded(backup)
+		if err != nil {
+			utils.Debug("Cannot read status of %s: %v", backup.ID, err)
+			continue
+		}
+		if status := backupIndex.EffectiveStatus(); status != index.BackupStatusComplete {
+			utils.Debug("Backup %s is %s, not counted as last complete backup", backup.ID, status)
+			continue
+		}
+		protected[backup.ID] = true
+		delete(pending, series)
+	}
+	return protected
+}
+
+// applyMinBackups retire de toDelete les sauvegardes les plus récentes de chaque série tant que
+// moins de min_backups seraient conservées: un plancher qui l'emporte sur les règles d'âge et de nombre.
+// Retourne les sauvegardes restant à supprimer et celles conservées par le plancher.
+func (m *Manager) applyMinBackups(backups, toDelete []BackupInfo) (remaining, floored []BackupInfo) {
+	minBackups := m.config.Retention.MinBackups
+	if minBackups <= 0 || len(toDelete) == 0 {
+		return toDelete, nil
+	}
+
+	deleting := make(map[string]bool, len(toDelete))
+	for _, backup := range toDelete {
+		deleting[backup.ID] = true
+	}
+	kept := make(map[string]int)
+	for _, backup := range backups {
+		if !deleting[backup.ID] {
+			kept[backupSeries(backup.ID)]++
+		}
+	}
+
+	candidates := append([]BackupInfo(nil), toDelete...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Timestamp.After(candidates[j].Timestamp)
+	})
+	for _, backup := range candidates {
+		series := backupSeries(backup.ID)
+		if kept[series] < minBackups {
+			kept[series]++
+			floored = append(floored, backup)
+			continue
+		}
+		remaining = append(remaining, backup)
+	}
+	return remaining, floored
+}
+
+// backupSeries retourne la série d'un ID de sauvegarde (l'ID lui-même s'il est illisible)
+func backupSeries(backupID string) string {
+	if ref, err := index.ParseBackupID(backupID); err == nil {
+		return ref.Name
+	}
+	return backupID
+}
+
 // deleteBackups supprime une liste de sauvegardes
 func (m *Manager) deleteBackups(backups []BackupInfo, verbose bool) error {
 	deletedCount := 0
 	var errors []string
 
 	for _, backup := range backups {
-		if err := m.deleteSingleBackup(backup, verbose); err != nil {
+		if _, err := m.deleteSingleBackup(backup, verbose); err != nil {
 			errors = append(errors, err.Error())
 			continue
 		}
@@ -263,15 +436,8 @@ func (m *Manager) filterBackupsByName(backups []BackupInfo, backupName string) [
 
 	var filtered []BackupInfo
 	for _, backup := range backups {
-		// Extraire le nom de backup de l'ID (format: backup-name-20060102-150405)
-		parts := strings.Split(backup.ID, "-")
-		if len(parts) < 3 {
-			continue
-		}
-
-		// Reconstruire le nom de backup (tous les éléments sauf les 2 derniers)
-		extractedBackupName := strings.Join(parts[:len(parts)-2], "-")
-		if extractedBackupName == backupName {
+		ref, err := index.ParseBackupID(backup.ID)
+		if err == nil && ref.Name == backupName {
 			filtered = append(filtered, backup)
 		}
 	}
@@ -279,25 +445,31 @@ func (m *Manager) filterBackupsByName(backups []BackupInfo, backupName string) [
 	return filtered
 }
 
-// deleteSingleBackup deletes a single backup
-func (m *Manager) deleteSingleBackup(backup BackupInfo, verbose bool) error {
+// deleteSingleBackup deletes a single backup and returns the bytes freed
+// L'index est supprimé d'abord, puis le garbage collector retire les objets qui ne sont plus
+// référencés: les fichiers inchangés des sauvegardes suivantes lisent encore une partie d'entre eux.
+func (m *Manager) deleteSingleBackup(backup BackupInfo, verbose bool) (int64, error) {
 	m.logDeletionStart(backup, verbose)
 
 	backupIndex, err := m.loadBackupIndexIfNeeded(backup)
 	if err != nil {
-		return fmt.Errorf("error loading index for %s: %v", backup.ID, err)
+		return 0, fmt.Errorf("error loading index for %s: %v", backup.ID, err)
 	}
 
-	if err := m.deleteBackupData(backup, backupIndex, verbose); err != nil {
-		return fmt.Errorf("error deleting files for %s: %v", backup.ID, err)
+	if err := m.deleteBackupIndex(backup.ID); err != nil {
+		return 0, fmt.Errorf("error deleting index for %s: %v", backup.ID, err)
+	}
+	if err := index.DeleteReports(m.storageClient, backup.ID); err != nil {
+		utils.Warn("Report of %s not removed: %v", backup.ID, err)
 	}
 
-	if err := m.deleteBackupIndex(backup.ID); err != nil {
-		return fmt.Errorf("error deleting index for %s: %v", backup.ID, err)
+	freed, err := m.deleteBackupData(backup, backupIndex, verbose)
+	if err != nil {
+		utils.Warn("Unreferenced data of %s could not be fully removed (run 'bcrdf gc'): %v", backup.ID, err)
 	}
 
 	m.logDeletionSuccess(backup, verbose)
-	return nil
+	return freed, nil
 }
 
 // logDeletionStart logs the start of backup deletion
@@ -305,7 +477,7 @@ func (m *Manager) logDeletionStart(backup BackupInfo, verbose bool) {
 	if verbose {
 		utils.Info("Deleting backup: %s (age: %v)", backup.ID, time.Since(backup.Timestamp).Round(time.Hour))
 	} else {
-		utils.ProgressStep(fmt.Sprintf("Deleting backup: %s", backup.ID))
+		utils.ProgressStep(utils.Msg("retention.step.delete", backup.ID))
 	}
 }
 
@@ -318,21 +490,16 @@ func (m *Manager) loadBackupIndexIfNeeded(backup BackupInfo) (*index.BackupIndex
 	return m.indexMgr.LoadIndex(backup.ID)
 }
 
-// deleteBackupData deletes the backup data files
-func (m *Manager) deleteBackupData(backup BackupInfo, backupIndex *index.BackupIndex, verbose bool) error {
-	if verbose {
-		utils.Info("Deleting %d files for backup %s", len(backupIndex.Files), backup.ID)
-	}
-
-	if err := m.deleteBackupFiles(backupIndex); err != nil {
-		return err
+// deleteBackupData deletes the backup data no longer referenced by any index
+func (m *Manager) deleteBackupData(backup BackupInfo, backupIndex *index.BackupIndex, verbose bool) (int64, error) {
+	result, err := gc.NewManager(m.config, m.indexMgr, m.storageClient).CollectBackup(backupIndex, false)
+	if result == nil {
+		return 0, err
 	}
-
 	if verbose {
-		utils.Info("✅ All files deleted for backup %s", backup.ID)
+		utils.Info("✅ %d unreferenced objects deleted for backup %s (%s)", result.Deleted, backup.ID, utils.FormatBytes(result.FreedBytes))
 	}
-
-	return nil
+	return result.FreedBytes, err
 }
 
 // logDeletionSuccess logs successful deletion
@@ -347,7 +514,7 @@ func (m *Manager) logDeletionSuccess(backup BackupInfo, verbose bool) {
 func (m *Manager) reportDeletionResults(deletedCount int, errors []string, verbose bool) error {
 	if len(errors) > 0 {
 		m.reportErrors(deletedCount, errors, verbose)
-		return fmt.Errorf("retention cleanup completed with %d errors", len(errors))
+		return fmt.Errorf("%w: retention cleanup completed with %d errors", utils.ErrPartialFailure, len(errors))
 	}
 
 	m.reportSuccess(deletedCount, verbose)
@@ -362,7 +529,7 @@ func (m *Manager) reportErrors(deletedCount int, errors []string, verbose bool)
 			utils.Warn("  - %s", err)
 		}
 	} else {
-		utils.ProgressWarning(fmt.Sprintf("Deleted %d backups with %d errors", deletedCount, len(errors)))
+		utils.ProgressWarning(utils.Msg("retention.step.partial", deletedCount, len(errors)))
 	}
 }
 
@@ -371,45 +538,8 @@ func (m *Manager) reportSuccess(deletedCount int, verbose bool) {
 	if verbose {
 		utils.Info("✅ Retention cleanup completed: %d backups deleted", deletedCount)
 	} else {
-		utils.ProgressSuccess(fmt.Sprintf("Retention cleanup: %d backups deleted", deletedCount))
-	}
-}
-
-// deleteBackupFiles supprime les fichiers de données d'une sauvegarde
-func (m *Manager) deleteBackupFiles(backupIndex *index.BackupIndex) error {
-	var errors []string
-
-	for _, file := range backupIndex.Files {
-		if file.StorageKey != "" {
-			// Reconstruct the full storage key with prefix
-			fullStorageKey := fmt.Sprintf("data/%s/%s", backupIndex.BackupID, file.StorageKey)
-
-			// Check if this is a chunked file by trying to download metadata
-			metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
-			_, err := m.storageClient.Download(metadataKey)
-			if err == nil {
-				// This is a chunked file, delete chunks and metadata
-				if err := m.deleteChunkedFile(fullStorageKey); err != nil {
-					errors = append(errors, fmt.Sprintf("failed to delete chunked file %s: %v", fullStorageKey, err))
-				} else {
-					utils.Debug("Chunked file deleted: %s (original: %s)", fullStorageKey, file.Path)
-				}
-			} else {
-				// Standard file, delete directly
-				if err := m.deleteWithRetry(fullStorageKey); err != nil {
-					errors = append(errors, fmt.Sprintf("failed to delete %s: %v", fullStorageKey, err))
-				} else {
-					utils.Debug("File deleted: %s (original: %s)", fullStorageKey, file.Path)
-				}
-			}
-		}
+		utils.ProgressSuccess(utils.Msg("retention.step.done", deletedCount))
 	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("errors deleting files: %s", strings.Join(errors, "; "))
-	}
-
-	return nil
 }
 
 // deleteBackupIndex supprime l'index d'une sauvegarde
@@ -435,7 +565,7 @@ func (m *Manager) GetRetentionInfo(verbose bool) error {
 		return backups[i].Timestamp.After(backups[j].Timestamp)
 	})
 
-	now := time.Now()
+	now := m.now()
 	maxAge := time.Duration(m.config.Retention.Days) * 24 * time.Hour
 	cutoffTime := now.Add(-maxAge)
 
@@ -443,7 +573,15 @@ func (m *Manager) GetRetentionInfo(verbose bool) error {
 	fmt.Printf("==========================\n")
 	fmt.Printf("Max backups: %d\n", m.config.Retention.MaxBackups)
 	fmt.Printf("Max age: %d days\n", m.config.Retention.Days)
+	if m.config.Retention.MinBackups > 0 {
+		fmt.Printf("Min backups: %d per series\n", m.config.Retention.MinBackups)
+	}
 	fmt.Printf("Current backups: %d\n", len(backups))
+	if m.config.Retention.MaxTotalSize != "" {
+		if size, err := m.repositorySize(); err == nil {
+			fmt.Printf("Max total size: %s (repository: %s)\n", m.config.Retention.MaxTotalSize, utils.FormatBytes(size))
+		}
+	}
 	fmt.Printf("Cutoff date: %s\n\n", cutoffTime.Format("2006-01-02 15:04:05"))
 
 	if len(backups) == 0 {
@@ -454,6 +592,25 @@ func (m *Manager) GetRetentionInfo(verbose bool) error {
 	fmt.Printf("Backup List:\n")
 	fmt.Printf("------------\n")
 
+	var expiring []BackupInfo
+	for i, backup := range backups {
+		if i >= m.config.Retention.MaxBackups || backup.Timestamp.Before(cutoffTime) {
+			expiring = append(expiring, backup)
+		}
+	}
+	protected := m.lastGoodBackups(backups, expiring)
+	var candidates []BackupInfo
+	for _, backup := range expiring {
+		if !protected[backup.ID] {
+			candidates = append(candidates, backup)
+		}
+	}
+	remaining, _ := m.applyMinBackups(backups, candidates)
+	deleting := make(map[string]bool, len(remaining))
+	for _, backup := range remaining {
+		deleting[backup.ID] = true
+	}
+
 	for i, backup := range backups {
 		age := now.Sub(backup.Timestamp)
 		status := "✅ Keep"
@@ -463,6 +620,11 @@ func (m *Manager) GetRetentionInfo(verbose bool) error {
 		} else if backup.Timestamp.Before(cutoffTime) {
 			status = "🗑️  Delete (too old)"
 		}
+		if protected[backup.ID] && status != "✅ Keep" {
+			status = "🛡️  Keep (last complete backup)"
+		} else if !deleting[backup.ID] && status != "✅ Keep" {
+			status = "🛡️  Keep (min_backups)"
+		}
 
 		fmt.Printf("%d. %s (%s ago) - %s\n",
 			i+1, backup.ID, age.Round(time.Hour), status)
@@ -472,43 +634,6 @@ func (m *Manager) GetRetentionInfo(verbose bool) error {
 	return nil
 }
 
-// deleteChunkedFile supprime un fichier chunké et ses métadonnées
-func (m *Manager) deleteChunkedFile(fullStorageKey string) error {
-	// Download metadata to get chunk count
-	metadataKey := fmt.Sprintf("%s.metadata", fullStorageKey)
-	metadataBytes, err := m.storageClient.Download(metadataKey)
-	if err != nil {
-		return fmt.Errorf("error downloading metadata: %w", err)
-	}
-
-	var metadata map[string]interface{}
-	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
-		return fmt.Errorf("error parsing metadata: %w", err)
-	}
-
-	chunks, ok := metadata["chunks"].(float64)
-	if !ok {
-		return fmt.Errorf("invalid metadata: chunks field not found")
-	}
-
-	totalChunks := int(chunks)
-
-	// Delete all chunks
-	for chunkNum := 0; chunkNum < totalChunks; chunkNum++ {
-		chunkKey := fmt.Sprintf("%s.chunk.%03d", fullStorageKey, chunkNum)
-		if err := m.deleteWithRetry(chunkKey); err != nil {
-			utils.Debug("Warning: failed to delete chunk %s: %v", chunkKey, err)
-		}
-	}
-
-	// Delete metadata file
-	if err := m.deleteWithRetry(metadataKey); err != nil {
-		return fmt.Errorf("error deleting metadata: %w", err)
-	}
-
-	return nil
-}
-
 // deleteWithRetry supprime un objet avec retry et timeout
 func (m *Manager) deleteWithRetry(key string) error {
 	// Timeout pour éviter les blocages infinis
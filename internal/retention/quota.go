@@ -0,0 +1,167 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+
+	"bcrdf/internal/gc"
+	"bcrdf/pkg/utils"
+)
+
+// QuotaResult résume un passage de mise au quota (retention.max_total_size)
+type QuotaResult struct {
+	Quota          int64
+	SizeBefore     int64
+	SizeAfter      int64
+	DeletedBackups []string
+	OrphansDeleted int
+	FreedBytes     int64
+}
+
+// repositorySize retourne la taille totale des objets du dépôt (données, index, index de base)
+func (m *Manager) repositorySize() (int64, error) {
+	objects, err := m.storageClient.ListObjects("")
+	if err != nil {
+		return 0, fmt.Errorf("error listing repository objects: %w", err)
+	}
+	var total int64
+	for _, obj := range objects {
+		total += obj.Size
+	}
+	return total, nil
+}
+
+// EnforceQuota ramène le dépôt sous retention.max_total_size: les objets orphelins sont d'abord
+// collectés, puis les sauvegardes les plus anciennes sont supprimées une à une tant que le quota
+// est dépassé (la taille des objets libérés est déduite à chaque suppression), et une dernière collecte
+// remesure le dépôt. Le quota porte sur tout le dépôt, toutes séries
+// confondues; min_backups, la dernière sauvegarde complète de chaque série et les sauvegardes
+// sous gel juridique restent protégés.
+func (m *Manager) EnforceQuota(verbose bool) (*QuotaResult, error) {
+	if m.config.Retention.MaxTotalSize == "" {
+		return nil, nil
+	}
+	quota, err := utils.ParseSize(m.config.Retention.MaxTotalSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid retention max_total_size: %w", utils.ErrConfig, err)
+	}
+	if err := utils.CheckDeletesAllowed(m.config, "retention"); err != nil {
+		return nil, err
+	}
+
+	size, err := m.repositorySize()
+	if err != nil {
+		return nil, err
+	}
+	result := &QuotaResult{Quota: quota, SizeBefore: size, SizeAfter: size}
+	if size <= quota {
+		utils.Debug("Repository size %s within quota %s", utils.FormatBytes(size), utils.FormatBytes(quota))
+		return result, nil
+	}
+
+	if verbose {
+		utils.Info("📦 Repository size %s exceeds max_total_size %s", utils.FormatBytes(size), utils.FormatBytes(quota))
+	} else {
+		utils.ProgressStep(fmt.Sprintf("📦 Repository over quota (%s > %s)", utils.FormatBytes(size), utils.FormatBytes(quota)))
+	}
+
+	// Orphelins d'abord: ils ne servent à aucune restauration
+	if err := m.collectOrphans(result, verbose); err != nil {
+		return result, err
+	}
+
+	eligible, err := m.quotaCandidates()
+	if err != nil {
+		return result, err
+	}
+	for _, backup := range eligible {
+		if result.SizeAfter <= quota {
+			break
+		}
+		freed, err := m.deleteSingleBackup(backup, verbose)
+		if err != nil {
+			return result, fmt.Errorf("%w: quota cleanup: %w", utils.ErrPartialFailure, err)
+		}
+		result.DeletedBackups = append(result.DeletedBackups, backup.ID)
+		result.SizeAfter -= freed
+		result.FreedBytes += freed
+	}
+	if len(result.DeletedBackups) > 0 {
+		if err := m.collectOrphans(result, verbose); err != nil {
+			return result, err
+		}
+	}
+
+	if result.SizeAfter > quota {
+		utils.Warn("⚠️  Repository still over quota (%s > %s): no other backup can be deleted (min_backups, last complete backups)",
+			utils.FormatBytes(result.SizeAfter), utils.FormatBytes(quota))
+	}
+	return result, nil
+}
+
+// collectOrphans supprime les objets non référencés puis remesure le dépôt
+func (m *Manager) collectOrphans(result *QuotaResult, verbose bool) error {
+	collected, err := gc.NewManager(m.config, m.indexMgr, m.storageClient).Collect("data/", gc.DefaultGracePeriod, false, verbose)
+	if collected != nil {
+		result.OrphansDeleted += collected.Deleted
+	}
+	if err != nil {
+		return err
+	}
+
+	size, err := m.repositorySize()
+	if err != nil {
+		return err
+	}
+	result.FreedBytes += result.SizeAfter - size
+	result.SizeAfter = size
+	return nil
+}
+
+// quotaCandidates retourne les sauvegardes supprimables pour le quota, des plus anciennes aux plus récentes
+func (m *Manager) quotaCandidates() ([]BackupInfo, error) {
+	backups, err := m.getAllBackups(false)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backups: %w", err)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Timestamp.After(backups[j].Timestamp)
+	})
+
+	protected := m.lastGoodBackups(backups, backups)
+	eligible, _ := m.applyMinBackups(backups, backups)
+	var candidates []BackupInfo
+	for _, backup := range eligible {
+		if !protected[backup.ID] {
+			candidates = append(candidates, backup)
+		}
+	}
+
+	candidates, err = m.protectHeldBackups(candidates, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Timestamp.Before(candidates[j].Timestamp)
+	})
+	return candidates, nil
+}
+
+// reportQuota affiche le résultat d'une mise au quota
+func reportQuota(result *QuotaResult, verbose bool) {
+	if result == nil || result.SizeBefore <= result.Quota {
+		return
+	}
+	message := fmt.Sprintf("Quota cleanup: %d backups and %d orphan objects deleted, %s freed (%s / %s)",
+		len(result.DeletedBackups), result.OrphansDeleted, utils.FormatBytes(result.FreedBytes),
+		utils.FormatBytes(result.SizeAfter), utils.FormatBytes(result.Quota))
+	if verbose {
+		utils.Info("✅ %s", message)
+		for _, id := range result.DeletedBackups {
+			utils.Info("   - %s", id)
+		}
+	} else {
+		utils.ProgressSuccess(message)
+	}
+}
@@ -0,0 +1,335 @@
+package selftest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"bcrdf/internal/compression"
+	"bcrdf/internal/crypto"
+	"bcrdf/internal/tempdir"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// Résultats d'une vérification
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Seuils des vérifications d'horloge et d'espace disque
+const (
+	clockFailSkew = 5 * time.Minute // Au-delà, les requêtes signées S3 sont refusées (15 min) ou proches de l'être
+	diskWarnFree  = 1 << 30         // 1GB
+	diskFailFree  = 100 << 20       // 100MB
+)
+
+// ProbePrefix regroupe les objets sondes écrits par bcrdf selftest
+const ProbePrefix = "selftest/"
+
+// Check est le résultat d'une vérification
+type Check struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Report est la matrice des vérifications, à joindre à une demande de support
+type Report struct {
+	Version  string    `json:"version"`
+	OS       string    `json:"os"`
+	Arch     string    `json:"arch"`
+	Hostname string    `json:"hostname"`
+	Time     time.Time `json:"time"`
+	Checks   []Check   `json:"checks"`
+}
+
+// Failed retourne le nombre de vérifications en échec
+func (r *Report) Failed() int {
+	failed := 0
+	for _, check := range r.Checks {
+		if check.Status == StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Manager exécute les vérifications de l'environnement
+type Manager struct {
+	config        *utils.Config // nil si la configuration n'a pas pu être chargée
+	configErr     error
+	storageClient storage.Client
+}
+
+// NewManager crée un gestionnaire d'auto-test; configErr est l'erreur de chargement de la
+// configuration (les vérifications locales sont faites quand même)
+func NewManager(config *utils.Config, configErr error, storageClient storage.Client) *Manager {
+	return &Manager{config: config, configErr: configErr, storageClient: storageClient}
+}
+
+// Run exécute toutes les vérifications, sans s'arrêter au premier échec
+func (m *Manager) Run(version string, verbose bool) *Report {
+	hostname, _ := os.Hostname()
+	report := &Report{Version: version, OS: runtime.GOOS, Arch: runtime.GOARCH, Hostname: hostname, Time: time.Now()}
+
+	run := func(name string, check func() (string, string)) {
+		if verbose {
+			utils.Info("🔎 Checking %s", name)
+		} else {
+			utils.ProgressStep(fmt.Sprintf("Checking %s", name))
+		}
+		start := time.Now()
+		status, detail := check()
+		report.Checks = append(report.Checks, Check{Name: name, Status: status, Detail: detail, Duration: time.Since(start)})
+	}
+
+	run("configuration", m.checkConfig)
+	for _, algorithm := range []crypto.EncryptionAlgorithm{crypto.AES256GCM, crypto.XChaCha20Poly1305} {
+		run("crypto "+string(algorithm), func() (string, string) { return checkCrypto(algorithm) })
+	}
+	run("encryption key", m.checkKey)
+	run("compression", m.checkCompression)
+
+	run("storage write/read/delete", m.checkStorage)
+	run("clock", m.checkClock)
+	for _, dir := range m.localDirs() {
+		run("disk "+dir.name, func() (string, string) { return checkDisk(dir.path) })
+	}
+	return report
+}
+
+// checkConfig signale une configuration illisible ou invalide
+func (m *Manager) checkConfig() (string, string) {
+	if m.configErr != nil {
+		return StatusFail, m.configErr.Error()
+	}
+	return StatusPass, fmt.Sprintf("storage %s, %s", m.config.Storage.Type, valueOr(m.config.Backup.EncryptionAlgo, string(crypto.AES256GCM)))
+}
+
+// checkCrypto chiffre et déchiffre avec une clé aléatoire, puis vérifie qu'un message altéré est refusé
+func checkCrypto(algorithm crypto.EncryptionAlgorithm) (string, string) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return StatusFail, fmt.Sprintf("random generator: %v", err)
+	}
+	encryptor, err := crypto.NewEncryptorV2(hex.EncodeToString(key), algorithm)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	plaintext := []byte(strings.Repeat("bcrdf selftest ", 1000))
+	ciphertext, err := encryptor.Encrypt(plaintext)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("encrypt: %v", err)
+	}
+	decrypted, err := encryptor.Decrypt(ciphertext)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		return StatusFail, fmt.Sprintf("round-trip mismatch: %v", err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	if _, err := encryptor.Decrypt(ciphertext); err == nil {
+		return StatusFail, "altered ciphertext was accepted"
+	}
+	return StatusPass, "round-trip ok, tampering detected"
+}
+
+// checkKey vérifie que la clé configurée est utilisable avec l'algorithme configuré
+func (m *Manager) checkKey() (string, string) {
+	if m.config == nil {
+		return StatusSkip, "no configuration"
+	}
+	algorithm := crypto.EncryptionAlgorithm(valueOr(m.config.Backup.EncryptionAlgo, string(crypto.AES256GCM)))
+	if algorithm == "none" {
+		return StatusWarn, "encryption disabled (encryption_algo none)"
+	}
+	if m.config.Backup.EncryptionKey == "" {
+		if m.config.Backup.AllowUnencrypted {
+			return StatusWarn, "no encryption key (allow_unencrypted)"
+		}
+		return StatusFail, "no encryption key configured"
+	}
+	encryptor, err := crypto.NewEncryptorV2(m.config.Backup.EncryptionKey, algorithm)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	ciphertext, err := encryptor.Encrypt([]byte("probe"))
+	if err == nil {
+		_, err = encryptor.Decrypt(ciphertext)
+	}
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	return StatusPass, string(algorithm)
+}
+
+// checkCompression compresse et décompresse des données compressibles et aléatoires
+func (m *Manager) checkCompression() (string, string) {
+	level := 3
+	if m.config != nil && m.config.Backup.CompressionLevel > 0 {
+		level = m.config.Backup.CompressionLevel
+	}
+	compressor, err := compression.NewCompressor(level)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	random := make([]byte, 64*1024)
+	if _, err := rand.Read(random); err != nil {
+		return StatusFail, fmt.Sprintf("random generator: %v", err)
+	}
+	samples := [][]byte{[]byte(strings.Repeat("compressible line of text\n", 4096)), random}
+	var ratio float64
+	for i, sample := range samples {
+		compressed, err := compressor.Compress(sample)
+		if err != nil {
+			return StatusFail, fmt.Sprintf("compress: %v", err)
+		}
+		decompressed, err := compressor.Decompress(compressed)
+		if err != nil || !bytes.Equal(decompressed, sample) {
+			return StatusFail, fmt.Sprintf("round-trip mismatch: %v", err)
+		}
+		if i == 0 {
+			ratio = float64(len(sample)) / float64(len(compressed))
+		}
+	}
+	return StatusPass, fmt.Sprintf("level %d, text ratio %.0fx", level, ratio)
+}
+
+// checkStorage écrit, relit puis supprime un objet sonde
+func (m *Manager) checkStorage() (string, string) {
+	if m.storageClient == nil {
+		return StatusSkip, "no storage configured"
+	}
+	payload := make([]byte, 4096)
+	if _, err := rand.Read(payload); err != nil {
+		return StatusFail, fmt.Sprintf("random generator: %v", err)
+	}
+	hostname, _ := os.Hostname()
+	key := fmt.Sprintf("%s%s-%s.probe", ProbePrefix, hostname, time.Now().UTC().Format("20060102-150405.000000000"))
+
+	start := time.Now()
+	if err := m.storageClient.Upload(key, payload); err != nil {
+		return StatusFail, fmt.Sprintf("write: %v", err)
+	}
+	if _, err := m.storageClient.Stat(key); err != nil {
+		return StatusFail, fmt.Sprintf("stat: %v (probe %s left)", err, key)
+	}
+	data, err := m.storageClient.Download(key)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("read: %v (probe %s left)", err, key)
+	}
+	if !bytes.Equal(data, payload) {
+		return StatusFail, fmt.Sprintf("read back %d bytes differing from the %d written (probe %s left)", len(data), len(payload), key)
+	}
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err := utils.CheckDeletesAllowed(m.config, "selftest"); err != nil {
+		return StatusWarn, fmt.Sprintf("write/read ok in %v; delete skipped (append_only), probe %s left", latency, key)
+	}
+	if err := m.storageClient.DeleteObject(key); err != nil {
+		return StatusFail, fmt.Sprintf("delete: %v (probe %s left)", err, key)
+	}
+	return StatusPass, fmt.Sprintf("write/read/delete ok in %v", latency)
+}
+
+// checkClock compare l'horloge locale à celle du serveur de stockage (en-tête Date)
+func (m *Manager) checkClock() (string, string) {
+	if m.storageClient == nil {
+		return StatusSkip, "no storage configured"
+	}
+	skew, err := storage.ClockSkew(m.storageClient)
+	if err != nil {
+		return StatusSkip, err.Error()
+	}
+	return clockStatus(skew, storage.MaxClockSkew(m.config))
+}
+
+// clockStatus classe un écart d'horloge: avertissement au-delà de max_clock_skew, échec au-delà
+// de clockFailSkew
+func clockStatus(skew, maxSkew time.Duration) (string, string) {
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	detail := fmt.Sprintf("local clock %s storage", storage.DescribeClockSkew(skew))
+	switch {
+	case abs > clockFailSkew && abs > maxSkew:
+		return StatusFail, detail + " (fix NTP: signed requests and retention ages are affected)"
+	case abs > maxSkew:
+		return StatusWarn, detail + fmt.Sprintf(" (max_clock_skew %v)", maxSkew)
+	default:
+		return StatusPass, detail
+	}
+}
+
+// localDir est un répertoire local utilisé par bcrdf
+type localDir struct {
+	name string
+	path string
+}
+
+// localDirs retourne les répertoires locaux dont l'espace libre est vérifié
+func (m *Manager) localDirs() []localDir {
+	dirs := []localDir{{"temp", tempdir.Root(m.config)}}
+	if m.config == nil {
+		return dirs
+	}
+	if m.config.Backup.StateDB != "" {
+		dirs = append(dirs, localDir{"state_db", filepath.Dir(m.config.Backup.StateDB)})
+	}
+	if m.config.Backup.RestoreCacheDir != "" {
+		dirs = append(dirs, localDir{"restore_cache_dir", m.config.Backup.RestoreCacheDir})
+	}
+	if m.config.Backup.MetadataCacheDir != "" {
+		dirs = append(dirs, localDir{"metadata_cache_dir", m.config.Backup.MetadataCacheDir})
+	}
+	return dirs
+}
+
+// checkDisk vérifie l'espace libre du système de fichiers d'un répertoire
+func checkDisk(path string) (string, string) {
+	usage, err := utils.DiskUsageOf(path)
+	if err != nil {
+		return StatusSkip, err.Error()
+	}
+	detail := fmt.Sprintf("%s free (%.0f%% used) in %s", utils.FormatBytes(int64(usage.Free)), usage.UsedPercent(), path)
+	switch {
+	case usage.Free < diskFailFree:
+		return StatusFail, detail
+	case usage.Free < diskWarnFree:
+		return StatusWarn, detail
+	default:
+		return StatusPass, detail
+	}
+}
+
+// PrintReport affiche la matrice des vérifications
+func PrintReport(report *Report) {
+	fmt.Printf("BCRDF %s selftest on %s (%s/%s), %s\n\n", report.Version, report.Hostname, report.OS, report.Arch,
+		report.Time.Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("%-28s %-6s %s\n", "Check", "Result", "Detail")
+	for _, check := range report.Checks {
+		fmt.Printf("%-28s %-6s %s\n", check.Name, strings.ToUpper(check.Status), check.Detail)
+	}
+	if failed := report.Failed(); failed > 0 {
+		fmt.Printf("\n❌ %d checks failed\n", failed)
+	} else {
+		fmt.Printf("\n✅ All checks passed\n")
+	}
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package selftest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+func TestRunWithMemoryStorage(t *testing.T) {
+	client := storage.MemoryStore(t.Name())
+	config := &utils.Config{}
+	config.Storage.Type = "memory"
+	config.Backup.EncryptionKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	config.Backup.CompressionLevel = 3
+	config.Backup.TempDir = t.TempDir()
+
+	report := NewManager(config, nil, client).Run("test", false)
+	for _, check := range report.Checks {
+		if check.Status == StatusFail {
+			t.Errorf("Vérification %s en échec: %s", check.Name, check.Detail)
+		}
+	}
+	if report.Failed() != 0 {
+		t.Errorf("Aucun échec attendu, obtenu %d", report.Failed())
+	}
+
+	// L'objet sonde est supprimé
+	objects, err := client.ListObjects(ProbePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("Objet sonde non supprimé: %v", objects)
+	}
+}
+
+func TestRunWithoutConfiguration(t *testing.T) {
+	report := NewManager(nil, errors.New("config file not found"), nil).Run("test", false)
+
+	status := make(map[string]string)
+	for _, check := range report.Checks {
+		status[check.Name] = check.Status
+	}
+	// Les vérifications locales sont faites malgré la configuration illisible
+	if status["configuration"] != StatusFail || status["storage write/read/delete"] != StatusSkip {
+		t.Errorf("Statuts inattendus: %v", status)
+	}
+	if status["crypto aes-256-gcm"] != StatusPass || status["compression"] != StatusPass {
+		t.Errorf("Vérifications locales attendues en succès: %v", status)
+	}
+}
+
+func TestClockStatus(t *testing.T) {
+	if status, _ := clockStatus(2*time.Second, time.Minute); status != StatusPass {
+		t.Errorf("Écart de 2s accepté attendu, obtenu %s", status)
+	}
+	if status, _ := clockStatus(-2*time.Minute, time.Minute); status != StatusWarn {
+		t.Errorf("Avertissement attendu pour 2 min, obtenu %s", status)
+	}
+	if status, _ := clockStatus(10*time.Minute, time.Minute); status != StatusFail {
+		t.Errorf("Échec attendu pour 10 min, obtenu %s", status)
+	}
+	// Un seuil configuré plus large que clockFailSkew reste respecté
+	if status, _ := clockStatus(10*time.Minute, time.Hour); status != StatusPass {
+		t.Errorf("Écart toléré par max_clock_skew attendu, obtenu %s", status)
+	}
+}
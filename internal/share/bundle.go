@@ -0,0 +1,168 @@
+package share
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// MaxExpiry est la durée de validité maximale d'une URL pré-signée SigV4
+const MaxExpiry = 7 * 24 * time.Hour
+
+// Bundle contient les URLs pré-signées nécessaires à la restauration d'une sauvegarde.
+// Il ne contient ni identifiants de stockage ni clé de chiffrement: la clé est transmise à part.
+type Bundle struct {
+	BackupID         string                    `json:"backup_id"`
+	CreatedAt        time.Time                 `json:"created_at"`
+	ExpiresAt        time.Time                 `json:"expires_at"`
+	EncryptionAlgo   string                    `json:"encryption_algo,omitempty"`
+	CompressionLevel int                       `json:"compression_level"`
+	AllowUnencrypted bool                      `json:"allow_unencrypted,omitempty"`
+	MaxWorkers       int                       `json:"max_workers,omitempty"`
+	RetryAttempts    int                       `json:"retry_attempts,omitempty"`
+	RetryDelay       int                       `json:"retry_delay,omitempty"`
+	NetworkTimeout   int                       `json:"network_timeout,omitempty"`
+	Objects          []storage.PresignedObject `json:"objects"`
+}
+
+// Create pré-signe l'index, sa base, les dictionnaires et les données d'une sauvegarde
+func Create(config *utils.Config, indexMgr *index.Manager, storageClient storage.Client, backupID string, expires time.Duration, verbose bool) (*Bundle, error) {
+	if expires <= 0 || expires > MaxExpiry {
+		return nil, fmt.Errorf("%w: share expiry must be between 1s and %s", utils.ErrConfig, MaxExpiry)
+	}
+
+	presigner, err := storage.NewPresigner(config)
+	if err != nil {
+		return nil, err
+	}
+
+	backupIndex, err := indexMgr.LoadIndex(backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index %s: %w", backupID, err)
+	}
+
+	keys := []string{fmt.Sprintf("indexes/%s.json", backupID)}
+	if backupIndex.BaseID != "" {
+		keys = append(keys, index.IndexBaseKey(backupIndex.BaseID))
+	}
+	dictionaries := make(map[string]bool)
+	for i := range backupIndex.Files {
+		if ref := backupIndex.Files[i].DictionaryRef(); ref != "" && !dictionaries[ref] {
+			dictionaries[ref] = true
+			keys = append(keys, index.DictionaryKey(ref))
+		}
+	}
+
+	now := time.Now()
+	bundle := &Bundle{
+		BackupID:         backupID,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(expires),
+		EncryptionAlgo:   config.Backup.EncryptionAlgo,
+		CompressionLevel: config.Backup.CompressionLevel,
+		AllowUnencrypted: config.Backup.AllowUnencrypted,
+		MaxWorkers:       config.Backup.MaxWorkers,
+		RetryAttempts:    config.Backup.RetryAttempts,
+		RetryDelay:       config.Backup.RetryDelay,
+		NetworkTimeout:   config.Backup.NetworkTimeout,
+	}
+
+	for _, key := range keys {
+		info, err := storageClient.Stat(key)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", key, err)
+		}
+		if err := bundle.add(presigner, key, info.Size, expires); err != nil {
+			return nil, err
+		}
+	}
+
+	// Données de la sauvegarde et objets des fichiers inchangés détenus par les sauvegardes précédentes
+	objects, err := index.ListDataObjects(storageClient, backupIndex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing data for %s: %w", utils.ErrStorageUnreachable, backupID, err)
+	}
+	for _, object := range objects {
+		if err := bundle.add(presigner, object.Key, object.Size, expires); err != nil {
+			return nil, err
+		}
+	}
+
+	if verbose {
+		utils.Info("🔗 Pre-signed %d objects for %s, valid until %s", len(bundle.Objects), backupID, bundle.ExpiresAt.Format(time.RFC3339))
+	}
+	return bundle, nil
+}
+
+// add pré-signe un objet et l'ajoute au partage
+func (b *Bundle) add(presigner storage.Presigner, key string, size int64, expires time.Duration) error {
+	url, err := presigner.PresignGet(key, expires)
+	if err != nil {
+		return err
+	}
+	b.Objects = append(b.Objects, storage.PresignedObject{Key: key, Size: size, URL: url})
+	return nil
+}
+
+// Save écrit le partage, lisible par son seul propriétaire (les URLs donnent accès aux données)
+func (b *Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing share file: %w", err)
+	}
+	return nil
+}
+
+// Load lit un partage et vérifie qu'il n'a pas expiré
+func Load(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading share file: %w", err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid share file %s: %w", path, err)
+	}
+	if bundle.BackupID == "" || len(bundle.Objects) == 0 {
+		return nil, fmt.Errorf("invalid share file %s: no backup", path)
+	}
+	if time.Now().After(bundle.ExpiresAt) {
+		return nil, fmt.Errorf("share for %s expired at %s", bundle.BackupID, bundle.ExpiresAt.Format(time.RFC3339))
+	}
+	return &bundle, nil
+}
+
+// Config construit la configuration de restauration à partir du partage et de la clé transmise à part
+func (b *Bundle) Config(encryptionKey, identityFile string) (*utils.Config, error) {
+	encryptionKey = strings.TrimSpace(encryptionKey)
+	if encryptionKey == "" && !b.AllowUnencrypted {
+		return nil, fmt.Errorf("%w: encryption key required (--key-file or BCRDF_ENCRYPTION_KEY)", utils.ErrConfig)
+	}
+
+	config := &utils.Config{}
+	config.Storage.Type = "share"
+	config.Backup.EncryptionKey = encryptionKey
+	config.Backup.EncryptionAlgo = b.EncryptionAlgo
+	config.Backup.CompressionLevel = b.CompressionLevel
+	config.Backup.AllowUnencrypted = b.AllowUnencrypted
+	config.Backup.IdentityFile = identityFile
+	config.Backup.MaxWorkers = max(b.MaxWorkers, 1)
+	config.Backup.RetryAttempts = b.RetryAttempts
+	config.Backup.RetryDelay = b.RetryDelay
+	config.Backup.NetworkTimeout = b.NetworkTimeout
+	return config, nil
+}
+
+// Client retourne le client en lecture seule servant les objets du partage
+func (b *Bundle) Client() storage.Client {
+	return storage.NewPresignedClient(b.Objects)
+}
@@ -0,0 +1,215 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/utils"
+)
+
+// catalogSchema crée le catalogue des fichiers de toutes les sauvegardes (bcrdf find).
+// La table plein texte utilise le tokenizer trigram: recherche de sous-chaînes insensible à la
+// casse (MATCH) et motifs GLOB accélérés, sans charger les index.
+const catalogSchema = `
+CREATE TABLE IF NOT EXISTS catalog_backups (
+	backup_id   TEXT PRIMARY KEY,
+	backup_name TEXT NOT NULL,
+	created_at  INTEGER NOT NULL,
+	files       INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS catalog USING fts5(
+	path, backup_id UNINDEXED, size UNINDEXED, mod_time UNINDEXED, is_dir UNINDEXED,
+	tokenize = 'trigram'
+);
+`
+
+// CatalogMatch est un fichier trouvé dans le catalogue
+type CatalogMatch struct {
+	BackupID   string    `json:"backup_id"`
+	BackupName string    `json:"backup_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	IsDir      bool      `json:"is_dir"`
+}
+
+// CatalogSyncResult résume une mise à jour du catalogue
+type CatalogSyncResult struct {
+	Added   []string
+	Removed []string
+	Total   int // Sauvegardes cataloguées après la mise à jour
+}
+
+// CatalogBackup ajoute (ou remplace) les fichiers d'une sauvegarde au catalogue
+func (s *Store) CatalogBackup(backupName string, backupIndex *index.BackupIndex) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM catalog WHERE backup_id = ?`, backupIndex.BackupID); err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO catalog (path, backup_id, size, mod_time, is_dir) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	defer stmt.Close()
+
+	var files int64
+	for _, file := range backupIndex.Files {
+		if file.Status == index.FileStatusFailed || file.IsSkipped() {
+			continue // Absents de la sauvegarde: introuvables à la restauration
+		}
+		if _, err := stmt.Exec(file.Path, backupIndex.BackupID, file.Size, file.ModifiedTime.Unix(), file.IsDirectory); err != nil {
+			return fmt.Errorf("error updating catalog: %w", err)
+		}
+		files++
+	}
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO catalog_backups (backup_id, backup_name, created_at, files) VALUES (?, ?, ?, ?)`,
+		backupIndex.BackupID, backupName, backupIndex.CreatedAt.Unix(), files); err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveFromCatalog retire une sauvegarde supprimée du catalogue
+func (s *Store) RemoveFromCatalog(backupID string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM catalog WHERE backup_id = ?`, backupID); err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM catalog_backups WHERE backup_id = ?`, backupID); err != nil {
+		return fmt.Errorf("error updating catalog: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CatalogedBackups retourne les sauvegardes présentes dans le catalogue
+func (s *Store) CatalogedBackups() (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT backup_id FROM catalog_backups`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying catalog: %w", err)
+	}
+	defer rows.Close()
+
+	backups := make(map[string]bool)
+	for rows.Next() {
+		var backupID string
+		if err := rows.Scan(&backupID); err != nil {
+			return nil, fmt.Errorf("error reading catalog: %w", err)
+		}
+		backups[backupID] = true
+	}
+	return backups, rows.Err()
+}
+
+// SearchCatalog cherche les fichiers dont le chemin contient pattern (insensible à la casse), ou
+// correspond au motif glob s'il contient *, ? ou [ (sensible à la casse, sur tout le chemin).
+// Les résultats vont des sauvegardes les plus récentes aux plus anciennes; backupName filtre une série.
+func (s *Store) SearchCatalog(pattern, backupName string, limit int) ([]CatalogMatch, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty search pattern")
+	}
+	query := `SELECT c.backup_id, b.backup_name, b.created_at, c.path, c.size, c.mod_time, c.is_dir
+		FROM catalog c JOIN catalog_backups b ON b.backup_id = c.backup_id WHERE `
+	var args []interface{}
+	switch {
+	case strings.ContainsAny(pattern, "*?["):
+		query += `c.path GLOB ?`
+		args = append(args, pattern)
+	case len(pattern) >= 3:
+		// Phrase trigram: sous-chaîne exacte, les guillemets sont doublés
+		query += `catalog MATCH ?`
+		args = append(args, `"`+strings.ReplaceAll(pattern, `"`, `""`)+`"`)
+	default:
+		// Moins de 3 caractères: pas de trigramme, parcours complet
+		query += `c.path LIKE ? ESCAPE '\'`
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+		args = append(args, "%"+escaped+"%")
+	}
+	if backupName != "" {
+		query += ` AND b.backup_name = ?`
+		args = append(args, backupName)
+	}
+	query += ` ORDER BY b.created_at DESC, c.path`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching catalog: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []CatalogMatch
+	for rows.Next() {
+		var match CatalogMatch
+		var created, modTime int64
+		if err := rows.Scan(&match.BackupID, &match.BackupName, &created, &match.Path, &match.Size, &modTime, &match.IsDir); err != nil {
+			return nil, fmt.Errorf("error reading catalog: %w", err)
+		}
+		match.CreatedAt = time.Unix(created, 0)
+		match.ModTime = time.Unix(modTime, 0)
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// SyncCatalog aligne le catalogue sur le dépôt: les index des sauvegardes absentes du catalogue
+// sont chargés une fois, les sauvegardes supprimées du dépôt en sont retirées
+func SyncCatalog(s *Store, indexMgr *index.Manager, verbose bool) (*CatalogSyncResult, error) {
+	refs, err := indexMgr.ListBackupRefs()
+	if err != nil {
+		return nil, err
+	}
+	cataloged, err := s.CatalogedBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CatalogSyncResult{}
+	present := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		present[ref.ID] = true
+		if cataloged[ref.ID] {
+			continue
+		}
+		backupIndex, err := indexMgr.LoadIndex(ref.ID)
+		if err != nil {
+			utils.Warn("Backup %s not cataloged: %v", ref.ID, err)
+			continue
+		}
+		if err := s.CatalogBackup(ref.Name, backupIndex); err != nil {
+			return result, err
+		}
+		if verbose {
+			utils.Info("📇 Cataloged %s (%d entries)", ref.ID, len(backupIndex.Files))
+		}
+		result.Added = append(result.Added, ref.ID)
+	}
+	for backupID := range cataloged {
+		if present[backupID] {
+			continue
+		}
+		if err := s.RemoveFromCatalog(backupID); err != nil {
+			return result, err
+		}
+		if verbose {
+			utils.Info("📇 Removed deleted backup %s from the catalog", backupID)
+		}
+		result.Removed = append(result.Removed, backupID)
+	}
+	result.Total = len(cataloged) + len(result.Added) - len(result.Removed)
+	return result, nil
+}
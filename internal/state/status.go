@@ -0,0 +1,71 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PrintStatus affiche l'état des sauvegardes à partir de la base locale
+// storageErr est le résultat du test de connectivité (nil = stockage accessible)
+func (s *Store) PrintStatus(storageErr error) error {
+	latest, err := s.LatestRuns()
+	if err != nil {
+		return err
+	}
+	unfinished, err := s.UnfinishedRuns()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📊 BCRDF Status\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 60))
+
+	if storageErr != nil {
+		fmt.Printf("Repository: ❌ unreachable (%v)\n", storageErr)
+	} else {
+		fmt.Printf("Repository: ✅ reachable\n")
+	}
+
+	fmt.Printf("\nLast runs:\n")
+	if len(latest) == 0 {
+		fmt.Printf("  (no backup recorded yet)\n")
+	}
+	for _, run := range latest {
+		fmt.Printf("  %-20s %s  %s", run.BackupName, run.StartedAt.Format("2006-01-02 15:04:05"), statusLabel(run.Status))
+		if run.Status != RunRunning {
+			fmt.Printf("  %d files, %.2f MB, %s", run.Files, float64(run.Bytes)/1024/1024, run.Duration().Round(time.Second))
+		}
+		fmt.Printf("\n")
+		if run.Error != "" {
+			fmt.Printf("  %-20s ↳ %s\n", "", run.Error)
+		}
+	}
+
+	fmt.Printf("\nPending operations:\n")
+	if len(unfinished) == 0 {
+		fmt.Printf("  (none)\n")
+	}
+	for _, run := range unfinished {
+		uploads, err := s.JournaledUploads(run.BackupID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s: interrupted or in progress since %s (%d objects already uploaded)\n",
+			run.BackupID, run.StartedAt.Format("2006-01-02 15:04:05"), len(uploads))
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
+// statusLabel retourne le libellé affiché d'un statut d'exécution
+func statusLabel(status string) string {
+	switch status {
+	case RunSuccess:
+		return "✅ success"
+	case RunFailed:
+		return "❌ failed"
+	default:
+		return "⏳ running"
+	}
+}
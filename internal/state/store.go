@@ -0,0 +1,490 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bcrdf/internal/index"
+
+	_ "modernc.org/sqlite"
+)
+
+// Statuts d'une exécution de sauvegarde
+const (
+	RunRunning = "running"
+	RunSuccess = "success"
+	RunFailed  = "failed"
+)
+
+// schema crée les tables de l'état local (idempotent)
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	backup_id   TEXT NOT NULL,
+	backup_name TEXT NOT NULL,
+	source_path TEXT NOT NULL,
+	started_at  INTEGER NOT NULL,
+	finished_at INTEGER,
+	status      TEXT NOT NULL,
+	files       INTEGER NOT NULL DEFAULT 0,
+	bytes       INTEGER NOT NULL DEFAULT 0,
+	error       TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS runs_name ON runs (backup_name, started_at);
+CREATE TABLE IF NOT EXISTS files (
+	backup_name TEXT NOT NULL,
+	path        TEXT NOT NULL,
+	size        INTEGER NOT NULL,
+	mod_time    INTEGER NOT NULL,
+	checksum    TEXT NOT NULL,
+	storage_key TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	backup_id   TEXT NOT NULL,
+	PRIMARY KEY (backup_name, path)
+);
+CREATE TABLE IF NOT EXISTS checksum_cache (
+	path     TEXT NOT NULL,
+	mode     TEXT NOT NULL,
+	size     INTEGER NOT NULL,
+	mod_time INTEGER NOT NULL,
+	checksum TEXT NOT NULL,
+	PRIMARY KEY (path, mode)
+);
+CREATE TABLE IF NOT EXISTS upload_journal (
+	backup_id   TEXT NOT NULL,
+	object_key  TEXT NOT NULL,
+	size        INTEGER NOT NULL,
+	uploaded_at INTEGER NOT NULL,
+	PRIMARY KEY (backup_id, object_key)
+);
+CREATE TABLE IF NOT EXISTS chunk_journal (
+	backup_id   TEXT NOT NULL,
+	path        TEXT NOT NULL,
+	chunk_key   TEXT NOT NULL,
+	size        INTEGER NOT NULL,
+	plain       TEXT NOT NULL,
+	stored      TEXT NOT NULL,
+	compression TEXT NOT NULL,
+	PRIMARY KEY (backup_id, chunk_key)
+);
+`
+
+// migrations modifient une base existante une seule fois, dans l'ordre; PRAGMA user_version
+// retient le nombre de migrations appliquées
+var migrations = []string{
+	// 1: la table checksums (ancien cache, sans le mode de checksum) est remplacée par checksum_cache
+	`DROP TABLE IF EXISTS checksums;`,
+}
+
+// migrate applique les migrations que la base n'a pas encore reçues
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(migrations); version++ {
+		if _, err := db.Exec(migrations[version]); err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+// Store est la base d'état locale (SQLite): historique des sauvegardes, état des fichiers,
+// cache de checksums et journal des uploads. Elle ne remplace pas les index distants,
+// elle permet seulement des requêtes rapides sans accéder au stockage.
+type Store struct {
+	db *sql.DB
+}
+
+// Run décrit une exécution de sauvegarde enregistrée
+type Run struct {
+	ID         int64
+	BackupID   string
+	BackupName string
+	SourcePath string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Status     string
+	Files      int64
+	Bytes      int64
+	Error      string
+}
+
+// Open ouvre (ou crée) la base d'état
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("error creating state directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening state database: %w", err)
+	}
+	// SQLite n'accepte qu'un écrivain: une seule connexion évite les erreurs SQLITE_BUSY entre workers
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("PRAGMA journal_mode=WAL; PRAGMA busy_timeout=5000;"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error configuring state database: %w", err)
+	}
+	if _, err := db.Exec(schema + catalogSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating state schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating state database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close ferme la base d'état
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// StartRun enregistre le début d'une sauvegarde et retourne l'identifiant de l'exécution
+func (s *Store) StartRun(backupID, backupName, sourcePath string) (int64, error) {
+	if s == nil {
+		return 0, nil
+	}
+	result, err := s.db.Exec(
+		`INSERT INTO runs (backup_id, backup_name, source_path, started_at, status) VALUES (?, ?, ?, ?, ?)`,
+		backupID, backupName, sourcePath, time.Now().Unix(), RunRunning)
+	if err != nil {
+		return 0, fmt.Errorf("error recording backup run: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// FinishRun enregistre la fin d'une sauvegarde (runErr nil = succès)
+func (s *Store) FinishRun(runID int64, files, bytes int64, runErr error) error {
+	if s == nil || runID == 0 {
+		return nil
+	}
+	status, message := RunSuccess, ""
+	if runErr != nil {
+		status, message = RunFailed, runErr.Error()
+	}
+	_, err := s.db.Exec(
+		`UPDATE runs SET finished_at = ?, status = ?, files = ?, bytes = ?, error = ? WHERE id = ?`,
+		time.Now().Unix(), status, files, bytes, message, runID)
+	if err != nil {
+		return fmt.Errorf("error recording backup run: %w", err)
+	}
+	return nil
+}
+
+// Runs retourne les dernières exécutions (toutes sauvegardes si backupName est vide), plus récentes en premier
+func (s *Store) Runs(backupName string, limit int) ([]Run, error) {
+	query := `SELECT id, backup_id, backup_name, source_path, started_at, COALESCE(finished_at, 0), status, files, bytes, error FROM runs`
+	var args []interface{}
+	if backupName != "" {
+		query += ` WHERE backup_name = ?`
+		args = append(args, backupName)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying backup runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var run Run
+		var started, finished int64
+		if err := rows.Scan(&run.ID, &run.BackupID, &run.BackupName, &run.SourcePath, &started, &finished,
+			&run.Status, &run.Files, &run.Bytes, &run.Error); err != nil {
+			return nil, fmt.Errorf("error reading backup runs: %w", err)
+		}
+		run.StartedAt = time.Unix(started, 0)
+		if finished > 0 {
+			run.FinishedAt = time.Unix(finished, 0)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// LatestRuns retourne la dernière exécution de chaque sauvegarde (par nom)
+func (s *Store) LatestRuns() ([]Run, error) {
+	runs, err := s.Runs("", 0)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var latest []Run
+	for _, run := range runs {
+		if seen[run.BackupName] {
+			continue
+		}
+		seen[run.BackupName] = true
+		latest = append(latest, run)
+	}
+	return latest, nil
+}
+
+// UnfinishedRuns retourne les exécutions jamais terminées (interrompues, ou en cours)
+func (s *Store) UnfinishedRuns() ([]Run, error) {
+	runs, err := s.Runs("", 0)
+	if err != nil {
+		return nil, err
+	}
+	var unfinished []Run
+	for _, run := range runs {
+		if run.Status == RunRunning {
+			unfinished = append(unfinished, run)
+		}
+	}
+	return unfinished, nil
+}
+
+// SuccessfulRuns retourne les exécutions réussies (sauvegardes dont l'index a été écrit), plus récentes en premier
+func (s *Store) SuccessfulRuns() ([]Run, error) {
+	runs, err := s.Runs("", 0)
+	if err != nil {
+		return nil, err
+	}
+	var successful []Run
+	for _, run := range runs {
+		if run.Status == RunSuccess {
+			successful = append(successful, run)
+		}
+	}
+	return successful, nil
+}
+
+// Duration retourne la durée d'une exécution terminée
+func (r Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// RecordFiles remplace l'état des fichiers d'une sauvegarde par celui du dernier index écrit
+func (s *Store) RecordFiles(backupName, backupID string, files []index.FileEntry) error {
+	if s == nil {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error recording file state: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM files WHERE backup_name = ?`, backupName); err != nil {
+		return fmt.Errorf("error recording file state: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO files (backup_name, path, size, mod_time, checksum, storage_key, status, backup_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("error recording file state: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, file := range files {
+		status := file.Status
+		if status == "" {
+			status = "ok"
+		}
+		if _, err := stmt.Exec(backupName, file.Path, file.Size, file.ModifiedTime.UnixNano(), file.Checksum,
+			file.StorageKey, status, backupID); err != nil {
+			return fmt.Errorf("error recording file state: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// FileCounts retourne le nombre de fichiers par statut pour une sauvegarde
+func (s *Store) FileCounts(backupName string) (map[string]int64, error) {
+	rows, err := s.db.Query(`SELECT status, COUNT(*) FROM files WHERE backup_name = ? GROUP BY status`, backupName)
+	if err != nil {
+		return nil, fmt.Errorf("error querying file state: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("error reading file state: %w", err)
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// LookupChecksum retourne le checksum mis en cache d'un fichier inchangé (même mode, même taille et même date)
+func (s *Store) LookupChecksum(path, mode string, size int64, modTime time.Time) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	var checksum string
+	err := s.db.QueryRow(`SELECT checksum FROM checksum_cache WHERE path = ? AND mode = ? AND size = ? AND mod_time = ?`,
+		path, mode, size, modTime.UnixNano()).Scan(&checksum)
+	if err != nil {
+		return "", false
+	}
+	return checksum, true
+}
+
+// StoreChecksums met en cache les checksums calculés pendant un parcours, en une transaction
+func (s *Store) StoreChecksums(checksums []index.CachedChecksum) error {
+	if s == nil || len(checksums) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error caching checksums: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO checksum_cache (path, mode, size, mod_time, checksum) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("error caching checksums: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range checksums {
+		if _, err := stmt.Exec(c.Path, c.Mode, c.Size, c.ModTime.UnixNano(), c.Checksum); err != nil {
+			return fmt.Errorf("error caching checksums: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error caching checksums: %w", err)
+	}
+	return nil
+}
+
+// JournalUpload enregistre un objet envoyé avec succès
+func (s *Store) JournalUpload(backupID, key string, size int64) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO upload_journal (backup_id, object_key, size, uploaded_at) VALUES (?, ?, ?, ?)`,
+		backupID, key, size, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("error journaling upload: %w", err)
+	}
+	return nil
+}
+
+// JournaledUploads retourne les objets envoyés pour une sauvegarde
+func (s *Store) JournaledUploads(backupID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT object_key FROM upload_journal WHERE backup_id = ? ORDER BY object_key`, backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying upload journal: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error reading upload journal: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ClearJournal supprime le journal d'une sauvegarde terminée (son index fait foi)
+func (s *Store) ClearJournal(backupID string) error {
+	if s == nil {
+		return nil
+	}
+	if _, err := s.db.Exec(`DELETE FROM upload_journal WHERE backup_id = ?`, backupID); err != nil {
+		return fmt.Errorf("error clearing upload journal: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM chunk_journal WHERE backup_id = ?`, backupID); err != nil {
+		return fmt.Errorf("error clearing upload journal: %w", err)
+	}
+	return nil
+}
+
+// JournaledChunk est un chunk envoyé par une sauvegarde dont l'index n'est pas encore écrit
+type JournaledChunk struct {
+	Path        string // Fichier source
+	Key         string // Objet du chunk (data/<id>/<clé>.chunk.NNN)
+	Size        int64  // Taille de l'objet stocké
+	Checksum    index.ChunkChecksum
+	Compression string
+}
+
+// JournalChunk enregistre un chunk envoyé, pour reprendre son fichier après une interruption
+func (s *Store) JournalChunk(backupID string, chunk JournaledChunk) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO chunk_journal (backup_id, path, chunk_key, size, plain, stored, compression) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		backupID, chunk.Path, chunk.Key, chunk.Size, chunk.Checksum.Plain, chunk.Checksum.Stored, chunk.Compression)
+	if err != nil {
+		return fmt.Errorf("error journaling chunk: %w", err)
+	}
+	return nil
+}
+
+// JournaledChunks retourne les chunks envoyés pour une sauvegarde, par objet
+func (s *Store) JournaledChunks(backupID string) ([]JournaledChunk, error) {
+	if s == nil {
+		return nil, nil
+	}
+	rows, err := s.db.Query(`SELECT path, chunk_key, size, plain, stored, compression FROM chunk_journal WHERE backup_id = ? ORDER BY chunk_key`, backupID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying chunk journal: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []JournaledChunk
+	for rows.Next() {
+		var chunk JournaledChunk
+		if err := rows.Scan(&chunk.Path, &chunk.Key, &chunk.Size, &chunk.Checksum.Plain, &chunk.Checksum.Stored, &chunk.Compression); err != nil {
+			return nil, fmt.Errorf("error reading chunk journal: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// InterruptedRun retourne la dernière exécution du job si elle s'est arrêtée (crash, ou échec
+// avant l'écriture de son index) après avoir envoyé des chunks depuis la même source: la
+// sauvegarde suivante la reprend sous son identifiant
+func (s *Store) InterruptedRun(backupName, sourcePath string) (Run, bool, error) {
+	if s == nil {
+		return Run{}, false, nil
+	}
+	runs, err := s.Runs(backupName, 1)
+	if err != nil || len(runs) == 0 {
+		return Run{}, false, err
+	}
+	run := runs[0]
+	if run.Status == RunSuccess || run.SourcePath != sourcePath {
+		return Run{}, false, nil
+	}
+	var chunks int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM chunk_journal WHERE backup_id = ?`, run.BackupID).Scan(&chunks); err != nil {
+		return Run{}, false, fmt.Errorf("error querying chunk journal: %w", err)
+	}
+	return run, chunks > 0, nil
+}
@@ -0,0 +1,198 @@
+package state
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bcrdf/internal/index"
+)
+
+func TestStoreRunsAndChecksums(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "state", "bcrdf.db"))
+	if err != nil {
+		t.Fatalf("Ouverture de la base d'état impossible: %v", err)
+	}
+	defer store.Close()
+
+	runID, err := store.StartRun("docs-20250101-120000", "docs", "/data/docs")
+	if err != nil {
+		t.Fatalf("StartRun a échoué: %v", err)
+	}
+	if err := store.FinishRun(runID, 3, 1024, errors.New("boom")); err != nil {
+		t.Fatalf("FinishRun a échoué: %v", err)
+	}
+
+	runs, err := store.Runs("docs", 10)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Une exécution attendue, obtenu %d (%v)", len(runs), err)
+	}
+	if runs[0].Status != RunFailed || runs[0].Error != "boom" || runs[0].Files != 3 {
+		t.Errorf("Exécution enregistrée incorrecte: %+v", runs[0])
+	}
+
+	modTime := time.Unix(1700000000, 123)
+	cached := []index.CachedChecksum{{Path: "/data/docs/a.txt", Mode: index.ChecksumModeFast, Size: 42, ModTime: modTime, Checksum: "abc"}}
+	if err := store.StoreChecksums(cached); err != nil {
+		t.Fatalf("StoreChecksums a échoué: %v", err)
+	}
+	if checksum, ok := store.LookupChecksum("/data/docs/a.txt", index.ChecksumModeFast, 42, modTime); !ok || checksum != "abc" {
+		t.Errorf("Checksum en cache attendu, obtenu %q (%v)", checksum, ok)
+	}
+	if _, ok := store.LookupChecksum("/data/docs/a.txt", index.ChecksumModeFast, 43, modTime); ok {
+		t.Error("Un fichier modifié ne doit pas réutiliser le checksum en cache")
+	}
+	if _, ok := store.LookupChecksum("/data/docs/a.txt", index.ChecksumModeFull, 42, modTime); ok {
+		t.Error("Un checksum d'un autre mode ne doit pas être réutilisé")
+	}
+
+	files := []index.FileEntry{
+		{Path: "/data/docs/a.txt", Size: 42, ModifiedTime: modTime, Checksum: "abc", StorageKey: "k1"},
+		{Path: "/data/docs/b.txt", Status: index.FileStatusFailed},
+	}
+	if err := store.RecordFiles("docs", "docs-20250101-120000", files); err != nil {
+		t.Fatalf("RecordFiles a échoué: %v", err)
+	}
+	counts, err := store.FileCounts("docs")
+	if err != nil || counts["ok"] != 1 || counts[index.FileStatusFailed] != 1 {
+		t.Errorf("Comptes par statut incorrects: %v (%v)", counts, err)
+	}
+}
+
+func TestCatalogSearch(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "bcrdf.db"))
+	if err != nil {
+		t.Fatalf("Ouverture de la base d'état impossible: %v", err)
+	}
+	defer store.Close()
+
+	older := &index.BackupIndex{BackupID: "docs-20250101T120000Z", CreatedAt: time.Unix(1735732800, 0), Files: []index.FileEntry{
+		{Path: "/data/docs/Factures/2024.pdf", Size: 10},
+		{Path: "/data/docs/a.txt", Size: 1},
+		{Path: "/data/docs/locked.db", Status: index.FileStatusSkippedUnreadable},
+	}}
+	newer := &index.BackupIndex{BackupID: "docs-20250102T120000Z", CreatedAt: time.Unix(1735819200, 0), Files: []index.FileEntry{
+		{Path: "/data/docs/Factures/2024.pdf", Size: 12},
+	}}
+	for _, backupIndex := range []*index.BackupIndex{older, newer} {
+		if err := store.CatalogBackup("docs", backupIndex); err != nil {
+			t.Fatalf("CatalogBackup a échoué: %v", err)
+		}
+	}
+
+	matches, err := store.SearchCatalog("factures", "", 0)
+	if err != nil || len(matches) != 2 || matches[0].BackupID != newer.BackupID || matches[0].Size != 12 {
+		t.Fatalf("Recherche insensible à la casse: %+v (%v)", matches, err)
+	}
+	if matches, _ := store.SearchCatalog("*.pdf", "docs", 1); len(matches) != 1 {
+		t.Errorf("Motif glob limité à 1 résultat: %+v", matches)
+	}
+	if matches, _ := store.SearchCatalog("a.", "", 0); len(matches) != 1 || matches[0].Path != "/data/docs/a.txt" {
+		t.Errorf("Motif court (LIKE): %+v", matches)
+	}
+	if matches, _ := store.SearchCatalog("locked", "", 0); len(matches) != 0 {
+		t.Errorf("Un fichier ignoré ne doit pas être catalogué: %+v", matches)
+	}
+
+	if err := store.RemoveFromCatalog(older.BackupID); err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := store.SearchCatalog("2024", "", 0); len(matches) != 1 || matches[0].BackupID != newer.BackupID {
+		t.Errorf("Sauvegarde retirée encore présente: %+v", matches)
+	}
+}
+
+func TestInterruptedRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "bcrdf.db"))
+	if err != nil {
+		t.Fatalf("Ouverture de la base d'état impossible: %v", err)
+	}
+	defer store.Close()
+
+	// Exécution interrompue (jamais terminée) après l'envoi d'un chunk
+	if _, err := store.StartRun("docs-20250101T120000Z", "docs", "/data/docs"); err != nil {
+		t.Fatal(err)
+	}
+	chunk := JournaledChunk{
+		Path:        "/data/docs/video.mkv",
+		Key:         "data/docs-20250101T120000Z/abc.chunk.000",
+		Size:        1024,
+		Checksum:    index.ChunkChecksum{Plain: "p0", Stored: "s0"},
+		Compression: index.CompressionGzip,
+	}
+	if err := store.JournalChunk("docs-20250101T120000Z", chunk); err != nil {
+		t.Fatalf("JournalChunk a échoué: %v", err)
+	}
+
+	run, ok, err := store.InterruptedRun("docs", "/data/docs")
+	if err != nil || !ok || run.BackupID != "docs-20250101T120000Z" {
+		t.Fatalf("Exécution interrompue attendue: %+v, %v (%v)", run, ok, err)
+	}
+	if _, ok, _ := store.InterruptedRun("docs", "/data/autre"); ok {
+		t.Error("Une autre source ne doit pas reprendre l'exécution interrompue")
+	}
+	chunks, err := store.JournaledChunks("docs-20250101T120000Z")
+	if err != nil || len(chunks) != 1 || chunks[0] != chunk {
+		t.Errorf("Chunk journalisé incorrect: %+v (%v)", chunks, err)
+	}
+
+	// Une exécution réussie après elle: plus rien à reprendre
+	runID, err := store.StartRun("docs-20250102T120000Z", "docs", "/data/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.FinishRun(runID, 1, 1024, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.InterruptedRun("docs", "/data/docs"); ok {
+		t.Error("Aucune reprise attendue après une exécution réussie")
+	}
+
+	if err := store.ClearJournal("docs-20250101T120000Z"); err != nil {
+		t.Fatal(err)
+	}
+	if chunks, _ := store.JournaledChunks("docs-20250101T120000Z"); len(chunks) != 0 {
+		t.Errorf("Journal des chunks non vidé: %+v", chunks)
+	}
+}
+
+func TestMigrationsRunOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bcrdf.db")
+
+	// Base d'une ancienne version: table checksums, aucune migration appliquée
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE checksums (path TEXT PRIMARY KEY, checksum TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Ouverture de la base d'état impossible: %v", err)
+	}
+	var version, tables int
+	store.db.QueryRow("PRAGMA user_version").Scan(&version)
+	store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'checksums'`).Scan(&tables)
+	if version != len(migrations) || tables != 0 {
+		t.Fatalf("Migration non appliquée: version %d, table checksums présente: %v", version, tables != 0)
+	}
+
+	// Les ouvertures suivantes ne rejouent pas les migrations
+	if _, err := store.db.Exec(`CREATE TABLE checksums (path TEXT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if store, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'checksums'`).Scan(&tables)
+	if tables != 1 {
+		t.Error("Migration rejouée à la réouverture de la base")
+	}
+}
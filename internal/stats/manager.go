@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/internal/index"
+	"bcrdf/pkg/storage"
+	"bcrdf/pkg/utils"
+)
+
+// forecastHorizons sont les échéances des projections de taille affichées avec --forecast
+var forecastHorizons = []int{30, 90, 365}
+
+// BackupStats contient les données stockées par une sauvegarde
+type BackupStats struct {
+	BackupID    string
+	CreatedAt   time.Time
+	Objects     int
+	StoredBytes int64 // Objets data/{id}/ envoyés par cette sauvegarde (chiffrés, compressés)
+}
+
+// Forecast projette la croissance du dépôt à partir de l'historique des sauvegardes
+type Forecast struct {
+	GrowthPerDay float64 // Octets ajoutés par jour (régression linéaire sur l'historique)
+	HistoryDays  float64 // Période couverte par l'historique
+	Limit        int64   // Quota ou limite du bucket, 0 = aucune
+	LimitSource  string  // Origine de la limite (--limit, retention.max_total_size)
+	FullAt       time.Time
+	Projections  map[int]int64 // Taille projetée à N jours
+}
+
+// Report contient les statistiques du dépôt
+type Report struct {
+	Backups         []BackupStats // Des plus anciennes aux plus récentes
+	RepositorySize  int64         // Tous les objets du dépôt (données, index, dictionnaires...)
+	RepositoryCount int
+	Forecast        *Forecast
+}
+
+// Manager calcule les statistiques de stockage des sauvegardes
+type Manager struct {
+	config        *utils.Config
+	indexMgr      *index.Manager
+	storageClient storage.Client
+}
+
+// NewManager crée un gestionnaire de statistiques
+func NewManager(config *utils.Config, indexMgr *index.Manager, storageClient storage.Client) *Manager {
+	return &Manager{
+		config:        config,
+		indexMgr:      indexMgr,
+		storageClient: storageClient,
+	}
+}
+
+// Analyze mesure les données stockées par sauvegarde; avec forecast, projette la croissance
+// du dépôt jusqu'à limit (retention.max_total_size si limit est vide)
+func (m *Manager) Analyze(forecast bool, limit string) (*Report, error) {
+	refs, err := m.indexMgr.ListBackupRefs()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := m.storageClient.ListObjects("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: error listing repository objects: %w", utils.ErrStorageUnreachable, err)
+	}
+
+	report := &Report{RepositoryCount: len(objects)}
+	stored := make(map[string]*BackupStats, len(refs))
+	for _, ref := range refs {
+		stats := &BackupStats{BackupID: ref.ID, CreatedAt: ref.CreatedAt}
+		stored[ref.ID] = stats
+	}
+	for _, object := range objects {
+		report.RepositorySize += object.Size
+		if !strings.HasPrefix(object.Key, "data/") {
+			continue
+		}
+		backupID, _, ok := strings.Cut(strings.TrimPrefix(object.Key, "data/"), "/")
+		if stats, found := stored[backupID]; ok && found {
+			stats.Objects++
+			stats.StoredBytes += object.Size
+		}
+	}
+	for _, stats := range stored {
+		report.Backups = append(report.Backups, *stats)
+	}
+	sort.Slice(report.Backups, func(i, j int) bool {
+		return report.Backups[i].CreatedAt.Before(report.Backups[j].CreatedAt)
+	})
+
+	if !forecast {
+		return report, nil
+	}
+	limitBytes, source, err := m.forecastLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	report.Forecast, err = ForecastGrowth(report.Backups, report.RepositorySize, limitBytes, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	report.Forecast.LimitSource = source
+	return report, nil
+}
+
+// forecastLimit retourne la limite de la projection: --limit, sinon retention.max_total_size
+func (m *Manager) forecastLimit(limit string) (int64, string, error) {
+	source := "--limit"
+	if limit == "" {
+		limit, source = m.config.Retention.MaxTotalSize, "retention.max_total_size"
+	}
+	if limit == "" {
+		return 0, "", nil
+	}
+	size, err := utils.ParseSize(limit)
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: invalid %s: %w", utils.ErrConfig, source, err)
+	}
+	return size, source, nil
+}
+
+// ForecastGrowth ajuste une droite (moindres carrés) sur la taille cumulée des sauvegardes dans le
+// temps et projette la taille actuelle du dépôt. Les suppressions de la rétention ne sont pas
+// modélisées: la projection suppose que le rythme observé se poursuit.
+func ForecastGrowth(backups []BackupStats, currentSize, limit int64, now time.Time) (*Forecast, error) {
+	if len(backups) < 2 {
+		return nil, fmt.Errorf("not enough history to forecast: at least 2 backups are needed, found %d", len(backups))
+	}
+	first := backups[0].CreatedAt
+	span := backups[len(backups)-1].CreatedAt.Sub(first).Hours() / 24
+	if span <= 0 {
+		return nil, fmt.Errorf("not enough history to forecast: all backups were made at the same time")
+	}
+
+	var sumX, sumY, sumXY, sumXX, cumulative float64
+	n := float64(len(backups))
+	for _, backup := range backups {
+		cumulative += float64(backup.StoredBytes)
+		x := backup.CreatedAt.Sub(first).Hours() / 24
+		sumX += x
+		sumY += cumulative
+		sumXY += x * cumulative
+		sumXX += x * x
+	}
+	growth := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	if math.IsNaN(growth) || growth < 0 {
+		growth = 0
+	}
+
+	forecast := &Forecast{
+		GrowthPerDay: growth,
+		HistoryDays:  span,
+		Limit:        limit,
+		Projections:  make(map[int]int64, len(forecastHorizons)),
+	}
+	for _, days := range forecastHorizons {
+		forecast.Projections[days] = currentSize + int64(growth*float64(days))
+	}
+	switch {
+	case limit <= 0:
+	case currentSize >= limit:
+		forecast.FullAt = now
+	case growth > 0:
+		days := float64(limit-currentSize) / growth
+		forecast.FullAt = now.Add(time.Duration(days * 24 * float64(time.Hour)))
+	}
+	return forecast, nil
+}
+
+// PrintReport affiche les données stockées par sauvegarde et la projection
+func PrintReport(report *Report) {
+	fmt.Printf("\n📊 Repository Statistics\n")
+	fmt.Printf("%s\n", strings.Repeat("-", 90))
+	fmt.Printf("%-44s %-20s %8s %12s %12s\n", "Backup", "Date", "Objects", "Stored", "Cumulative")
+	var cumulative int64
+	for _, backup := range report.Backups {
+		cumulative += backup.StoredBytes
+		fmt.Printf("%-44s %-20s %8d %12s %12s\n", backup.BackupID, backup.CreatedAt.Local().Format("2006-01-02 15:04"),
+			backup.Objects, utils.FormatBytes(backup.StoredBytes), utils.FormatBytes(cumulative))
+	}
+	fmt.Printf("%s\n", strings.Repeat("-", 90))
+	fmt.Printf("Repository size: %s (%d objects, %d backups)\n", utils.FormatBytes(report.RepositorySize),
+		report.RepositoryCount, len(report.Backups))
+
+	if forecast := report.Forecast; forecast != nil {
+		fmt.Printf("\n📈 Forecast (linear trend over %.0f days of history, retention deletions not modeled)\n", forecast.HistoryDays)
+		fmt.Printf("  Growth: %s/day, %s/month\n", utils.FormatBytes(int64(forecast.GrowthPerDay)),
+			utils.FormatBytes(int64(forecast.GrowthPerDay*30)))
+		for _, days := range forecastHorizons {
+			fmt.Printf("  In %3d days: %s\n", days, utils.FormatBytes(forecast.Projections[days]))
+		}
+		switch {
+		case forecast.Limit <= 0:
+			fmt.Printf("  No limit to forecast against: set retention.max_total_size or pass --limit\n")
+		case !forecast.FullAt.IsZero() && !forecast.FullAt.After(time.Now()):
+			fmt.Printf("  ⚠️  Limit %s (%s) already reached\n", utils.FormatBytes(forecast.Limit), forecast.LimitSource)
+		case forecast.FullAt.IsZero():
+			fmt.Printf("  ✅ Limit %s (%s) not reached: the repository is not growing\n", utils.FormatBytes(forecast.Limit), forecast.LimitSource)
+		default:
+			fmt.Printf("  Limit %s (%s) reached around %s (in %.0f days)\n", utils.FormatBytes(forecast.Limit), forecast.LimitSource,
+				forecast.FullAt.Local().Format("2006-01-02"), time.Until(forecast.FullAt).Hours()/24)
+		}
+	}
+	fmt.Printf("\n")
+}
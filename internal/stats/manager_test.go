@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForecastGrowth(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	backups := []BackupStats{
+		{CreatedAt: start, StoredBytes: 1000},
+		{CreatedAt: start.AddDate(0, 0, 1), StoredBytes: 100},
+		{CreatedAt: start.AddDate(0, 0, 2), StoredBytes: 100},
+		{CreatedAt: start.AddDate(0, 0, 3), StoredBytes: 100},
+	}
+	now := start.AddDate(0, 0, 3)
+
+	forecast, err := ForecastGrowth(backups, 1300, 2300, now)
+	if err != nil {
+		t.Fatalf("projection impossible: %v", err)
+	}
+	if forecast.GrowthPerDay != 100 {
+		t.Errorf("croissance attendue 100 octets/jour, obtenu %f", forecast.GrowthPerDay)
+	}
+	if want := now.AddDate(0, 0, 10); !forecast.FullAt.Equal(want) {
+		t.Errorf("limite atteinte le %v, attendu %v", forecast.FullAt, want)
+	}
+	if forecast.Projections[30] != 4300 {
+		t.Errorf("taille projetée à 30 jours: %d", forecast.Projections[30])
+	}
+
+	// Limite déjà dépassée: atteinte maintenant
+	forecast, _ = ForecastGrowth(backups, 3000, 2300, now)
+	if !forecast.FullAt.Equal(now) {
+		t.Errorf("limite dépassée attendue à %v, obtenu %v", now, forecast.FullAt)
+	}
+}
+
+func TestForecastGrowthNeedsHistory(t *testing.T) {
+	now := time.Now()
+	if _, err := ForecastGrowth([]BackupStats{{CreatedAt: now}}, 0, 0, now); err == nil {
+		t.Error("erreur attendue avec une seule sauvegarde")
+	}
+	if _, err := ForecastGrowth([]BackupStats{{CreatedAt: now}, {CreatedAt: now}}, 0, 0, now); err == nil {
+		t.Error("erreur attendue sans écart de temps entre les sauvegardes")
+	}
+}
@@ -0,0 +1,209 @@
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// Répertoires temporaires gérés: chaque processus travaille dans sa propre zone
+// <racine>/bcrdf-tmp-<id>/, marquée vivante par un fichier rafraîchi périodiquement.
+// La zone est supprimée à la fermeture; celle d'un processus tué (crash, kill -9) n'est plus
+// rafraîchie et est supprimée par le balayage de la zone suivante.
+const (
+	areaPrefix        = "bcrdf-tmp-"
+	aliveFile         = ".alive"
+	heartbeatInterval = 10 * time.Second
+	staleAfter        = 6 * heartbeatInterval // Processus arrêté sans supprimer sa zone
+)
+
+// Area est la zone temporaire d'un processus, avec un plafond optionnel de l'espace réservé
+type Area struct {
+	dir   string
+	limit int64 // Octets réservables au total, 0 = pas de plafond
+
+	mu       sync.Mutex
+	reserved int64
+	stop     chan struct{}
+	closed   bool
+}
+
+// Root retourne la racine des zones temporaires: backup.temp_dir, sinon le répertoire
+// temporaire du système (TMPDIR)
+func Root(config *utils.Config) string {
+	if config != nil && config.Backup.TempDir != "" {
+		return config.Backup.TempDir
+	}
+	return os.TempDir()
+}
+
+// Open balaye les zones abandonnées puis crée la zone du processus selon la configuration
+// (backup.temp_dir, backup.temp_max_size); config peut être nil (valeurs par défaut)
+func Open(config *utils.Config) (*Area, error) {
+	var limit int64
+	if config != nil && config.Backup.TempMaxSize != "" {
+		size, err := utils.ParseSize(config.Backup.TempMaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid temp_max_size: %w", utils.ErrConfig, err)
+		}
+		limit = size
+	}
+	return OpenIn(Root(config), limit)
+}
+
+// OpenIn crée une zone temporaire sous root, limitée à limit octets réservés (0 = sans plafond)
+func OpenIn(root string, limit int64) (*Area, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("error creating temp directory %s: %w", root, err)
+	}
+	if removed, err := Sweep(root); err != nil {
+		utils.Debug("Temp directory sweep incomplete: %v", err)
+	} else if removed > 0 {
+		utils.Info("🧹 Removed %d temp directories left by interrupted runs in %s", removed, root)
+	}
+
+	dir, err := os.MkdirTemp(root, areaPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, aliveFile), []byte(fmt.Sprintf("%d\n", os.Getpid())), 0600); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("error creating temp directory: %w", err)
+	}
+
+	area := &Area{dir: dir, limit: limit, stop: make(chan struct{})}
+	go area.heartbeat()
+	return area, nil
+}
+
+// heartbeat rafraîchit le marqueur de vie jusqu'à la fermeture de la zone
+func (a *Area) heartbeat() {
+	alive := filepath.Join(a.dir, aliveFile)
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			_ = os.Chtimes(alive, now, now)
+		}
+	}
+}
+
+// Dir retourne le répertoire de la zone
+func (a *Area) Dir() string {
+	return a.dir
+}
+
+// MkdirTemp crée un sous-répertoire de la zone (un par worker ou par opération)
+func (a *Area) MkdirTemp(pattern string) (string, error) {
+	return os.MkdirTemp(a.dir, pattern)
+}
+
+// CreateTemp crée un fichier temporaire dans la zone
+func (a *Area) CreateTemp(pattern string) (*os.File, error) {
+	return os.CreateTemp(a.dir, pattern)
+}
+
+// Reserve réserve size octets avant d'écrire dans la zone; échoue (utils.ErrInsufficientSpace)
+// au-delà de temp_max_size. Retourne la fonction qui libère la réservation.
+func (a *Area) Reserve(size int64) (func(), error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.limit > 0 && a.reserved+size > a.limit {
+		return nil, fmt.Errorf("%w: temp space cap reached (%s in use, %s requested, temp_max_size %s)",
+			utils.ErrInsufficientSpace, utils.FormatBytes(a.reserved), utils.FormatBytes(size), utils.FormatBytes(a.limit))
+	}
+	a.reserved += size
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			a.reserved -= size
+			a.mu.Unlock()
+		})
+	}, nil
+}
+
+// Limit retourne le plafond de la zone (0 = sans plafond)
+func (a *Area) Limit() int64 {
+	return a.limit
+}
+
+// Close supprime la zone et tout son contenu
+func (a *Area) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	if err := os.RemoveAll(a.dir); err != nil {
+		return fmt.Errorf("error removing temp directory %s: %w", a.dir, err)
+	}
+	return nil
+}
+
+// Areas retourne les zones temporaires présentes sous root (de ce processus ou d'autres)
+func Areas(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var areas []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), areaPrefix) {
+			areas = append(areas, filepath.Join(root, entry.Name()))
+		}
+	}
+	return areas
+}
+
+// Sweep supprime les zones de root dont le marqueur de vie n'est plus rafraîchi (processus
+// arrêté sans nettoyage). Retourne le nombre de zones supprimées.
+func Sweep(root string) (int, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var lastErr error
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), areaPrefix) {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if !stale(dir) {
+			continue
+		}
+		utils.Debug("Removing abandoned temp directory %s", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			lastErr = err
+			continue
+		}
+		removed++
+	}
+	return removed, lastErr
+}
+
+// stale indique une zone abandonnée: marqueur ancien, ou absent dans une zone ancienne
+func stale(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, aliveFile))
+	if err != nil {
+		info, err = os.Stat(dir)
+		if err != nil {
+			return false
+		}
+	}
+	return time.Since(info.ModTime()) > staleAfter
+}
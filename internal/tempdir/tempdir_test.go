@@ -0,0 +1,83 @@
+package tempdir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestAreaCleanupAndSweep(t *testing.T) {
+	root := t.TempDir()
+
+	// Zone d'un processus tué: marqueur de vie non rafraîchi
+	abandoned := filepath.Join(root, areaPrefix+"crashed")
+	if err := os.MkdirAll(filepath.Join(abandoned, "snapshot"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	alive := filepath.Join(abandoned, aliveFile)
+	if err := os.WriteFile(alive, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	if err := os.Chtimes(alive, old, old); err != nil {
+		t.Fatal(err)
+	}
+	// Répertoire étranger: jamais touché
+	other := filepath.Join(root, "other")
+	if err := os.Mkdir(other, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	area, err := OpenIn(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(abandoned); !os.IsNotExist(err) {
+		t.Errorf("zone abandonnée non supprimée: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("répertoire étranger supprimé: %v", err)
+	}
+
+	// Une zone vivante n'est pas balayée par un autre processus
+	if removed, err := Sweep(root); err != nil || removed != 0 {
+		t.Errorf("zone vivante balayée: %d %v", removed, err)
+	}
+
+	file, err := area.CreateTemp("snapshot-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if err := area.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(area.Dir()); !os.IsNotExist(err) {
+		t.Errorf("zone non supprimée à la fermeture: %v", err)
+	}
+}
+
+func TestAreaReserveCap(t *testing.T) {
+	area, err := OpenIn(t.TempDir(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer area.Close()
+
+	release, err := area.Reserve(60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := area.Reserve(50); !errors.Is(err, utils.ErrInsufficientSpace) {
+		t.Errorf("dépassement du plafond accepté: %v", err)
+	}
+	release()
+	release() // Libération idempotente
+	if _, err := area.Reserve(100); err != nil {
+		t.Errorf("réservation refusée après libération: %v", err)
+	}
+}
@@ -26,19 +26,19 @@ func NewConfigValidator(config *utils.Config) *ConfigValidator {
 // ValidateAll valide tous les aspects de la configuration
 func (v *ConfigValidator) ValidateAll(verbose bool) error {
 	if !verbose {
-		utils.ProgressStep("Validating configuration...")
+		utils.ProgressStep(utils.Msg("config.step.validate"))
 	} else {
 		utils.Info("🔍 Starting configuration validation")
 	}
 
 	// Validation du stockage
 	if err := v.validateStorage(verbose); err != nil {
-		return fmt.Errorf("erreur de validation du stockage: %w", err)
+		return fmt.Errorf("storage validation error: %w", err)
 	}
 
 	// Validation de la sauvegarde
 	if err := v.validateBackup(verbose); err != nil {
-		return fmt.Errorf("erreur de validation de la sauvegarde: %w", err)
+		return fmt.Errorf("backup validation error: %w", err)
 	}
 
 	// Testing storage connectivity
@@ -49,7 +49,7 @@ func (v *ConfigValidator) ValidateAll(verbose bool) error {
 	if verbose {
 		utils.Info("✅ Validation completed successfully")
 	} else {
-		utils.ProgressSuccess("Configuration validated successfully")
+		utils.ProgressSuccess(utils.Msg("config.step.valid"))
 	}
 
 	return nil
@@ -63,12 +63,19 @@ func (v *ConfigValidator) validateStorage(verbose bool) error {
 
 	storageConfig := v.config.Storage
 
+	// Disposition du dépôt (prefix, index_prefix, data_prefix)
+	if _, err := storage.LayoutFromConfig(v.config); err != nil {
+		return err
+	}
+
 	// Vérifier le type de stockage
 	switch storageConfig.Type {
 	case "s3":
 		return v.validateS3Storage(storageConfig, verbose)
 	case "webdav":
 		return v.validateWebDAVStorage(storageConfig, verbose)
+	case "memory":
+		return nil
 	default:
 		return fmt.Errorf("unsupported storage type: %s", storageConfig.Type)
 	}
@@ -76,19 +83,25 @@ func (v *ConfigValidator) validateStorage(verbose bool) error {
 
 // validateS3Storage valide les paramètres S3
 func (v *ConfigValidator) validateS3Storage(storageConfig struct {
-	Type         string `mapstructure:"type"`
-	Bucket       string `mapstructure:"bucket"`
-	Region       string `mapstructure:"region"`
-	AccessKey    string `mapstructure:"access_key"`
-	SecretKey    string `mapstructure:"secret_key"`
-	StorageClass string `mapstructure:"storage_class"`
-	Endpoint     string `mapstructure:"endpoint"`
-	Username     string `mapstructure:"username"`
-	Password     string `mapstructure:"password"`
+	Type            string                       `mapstructure:"type"`
+	Bucket          string                       `mapstructure:"bucket"`
+	Region          string                       `mapstructure:"region"`
+	AccessKey       string                       `mapstructure:"access_key"`
+	SecretKey       string                       `mapstructure:"secret_key"`
+	StorageClass    string                       `mapstructure:"storage_class"`
+	AddressingStyle string                       `mapstructure:"addressing_style"`
+	Prefix          string                       `mapstructure:"prefix"`
+	IndexPrefix     string                       `mapstructure:"index_prefix"`
+	DataPrefix      string                       `mapstructure:"data_prefix"`
+	OpaqueKeys      bool                         `mapstructure:"opaque_keys"`
+	Endpoint        string                       `mapstructure:"endpoint"`
+	Username        string                       `mapstructure:"username"`
+	Password        string                       `mapstructure:"password"`
+	Destructive     utils.DestructiveCredentials `mapstructure:"destructive"`
 }, verbose bool) error {
 	// Vérifier le bucket
 	if storageConfig.Bucket == "" {
-		return fmt.Errorf("nom du bucket requis pour S3")
+		return fmt.Errorf("bucket name required for S3")
 	}
 
 	// Vérifier la région
@@ -139,15 +152,21 @@ func (v *ConfigValidator) validateS3Storage(storageConfig struct {
 
 // validateWebDAVStorage valide les paramètres WebDAV
 func (v *ConfigValidator) validateWebDAVStorage(storageConfig struct {
-	Type         string `mapstructure:"type"`
-	Bucket       string `mapstructure:"bucket"`
-	Region       string `mapstructure:"region"`
-	AccessKey    string `mapstructure:"access_key"`
-	SecretKey    string `mapstructure:"secret_key"`
-	StorageClass string `mapstructure:"storage_class"`
-	Endpoint     string `mapstructure:"endpoint"`
-	Username     string `mapstructure:"username"`
-	Password     string `mapstructure:"password"`
+	Type            string                       `mapstructure:"type"`
+	Bucket          string                       `mapstructure:"bucket"`
+	Region          string                       `mapstructure:"region"`
+	AccessKey       string                       `mapstructure:"access_key"`
+	SecretKey       string                       `mapstructure:"secret_key"`
+	StorageClass    string                       `mapstructure:"storage_class"`
+	AddressingStyle string                       `mapstructure:"addressing_style"`
+	Prefix          string                       `mapstructure:"prefix"`
+	IndexPrefix     string                       `mapstructure:"index_prefix"`
+	DataPrefix      string                       `mapstructure:"data_prefix"`
+	OpaqueKeys      bool                         `mapstructure:"opaque_keys"`
+	Endpoint        string                       `mapstructure:"endpoint"`
+	Username        string                       `mapstructure:"username"`
+	Password        string                       `mapstructure:"password"`
+	Destructive     utils.DestructiveCredentials `mapstructure:"destructive"`
 }, verbose bool) error {
 	// Vérifier l'endpoint
 	if storageConfig.Endpoint == "" {
@@ -183,29 +202,45 @@ func (v *ConfigValidator) validateBackup(verbose bool) error {
 
 	backup := v.config.Backup
 
-	// Vérifier la clé de chiffrement
-	if backup.EncryptionKey == "" {
-		return fmt.Errorf("encryption key required")
-	}
-
 	// Valider la clé selon l'algorithme
 	algorithm := crypto.EncryptionAlgorithm(backup.EncryptionAlgo)
 	if algorithm == "" {
 		algorithm = crypto.AES256GCM // Par défaut
 	}
 
-	if err := crypto.ValidateKeyV2(backup.EncryptionKey, algorithm); err != nil {
-		return fmt.Errorf("invalid encryption key: %w", err)
-	}
-
 	// Vérifier l'algorithme de chiffrement
 	switch algorithm {
 	case crypto.AES256GCM, crypto.XChaCha20Poly1305:
-		// OK
+		// Vérifier la clé de chiffrement
+		if backup.EncryptionKey == "" {
+			return fmt.Errorf("encryption key required")
+		}
+		if err := crypto.ValidateKeyV2(backup.EncryptionKey, algorithm); err != nil {
+			return fmt.Errorf("invalid encryption key: %w", err)
+		}
+	case crypto.None:
+		// Mode sans chiffrement: opt-in explicite, pas de clé
+		if !backup.AllowUnencrypted {
+			return fmt.Errorf("encryption_algo none requires allow_unencrypted: true")
+		}
+		utils.Warn("Encryption disabled: data and indexes are stored in clear, rely on the storage encryption")
 	default:
 		return fmt.Errorf("unsupported encryption algorithm: %s", backup.EncryptionAlgo)
 	}
 
+	// Vérifier les destinataires age des données
+	if len(backup.Recipients) > 0 || backup.IdentityFile != "" {
+		if _, err := crypto.NewRecipientEncryptor(backup.Recipients, backup.IdentityFile); err != nil {
+			return fmt.Errorf("invalid encryption recipients: %w", err)
+		}
+		if len(backup.Recipients) > 0 && crypto.EncryptionAlgorithm(backup.EncryptionAlgo) == crypto.None {
+			return fmt.Errorf("recipients require an encryption algorithm (encryption_algo is none)")
+		}
+		if verbose {
+			utils.Info("   Recipients: %d (identity file: %t)", len(backup.Recipients), backup.IdentityFile != "")
+		}
+	}
+
 	// Vérifier le niveau de compression
 	if backup.CompressionLevel < 1 || backup.CompressionLevel > 9 {
 		return fmt.Errorf("invalid compression level (1-9): %d", backup.CompressionLevel)
@@ -237,13 +272,13 @@ func (v *ConfigValidator) testStorageConnectivity(verbose bool) error {
 
 	// Tester la connectivité
 	if err := storageClient.TestConnectivity(); err != nil {
-		return fmt.Errorf("impossible de se connecter au stockage: %w", err)
+		return fmt.Errorf("%w: cannot connect to storage: %w", utils.ErrStorageUnreachable, err)
 	}
 
 	// Tester la liste d'objets
 	objects, err := storageClient.ListObjects("test/")
 	if err != nil {
-		return fmt.Errorf("impossible de lister les objets: %w", err)
+		return fmt.Errorf("%w: cannot list objects: %w", utils.ErrStorageUnreachable, err)
 	}
 
 	if verbose {
@@ -260,13 +295,13 @@ func (v *ConfigValidator) testStorageConnectivity(verbose bool) error {
 
 	// Vérifier qu'on peut le lire
 	if _, err := storageClient.Download(testKey); err != nil {
-		return fmt.Errorf("impossible de lire depuis le stockage: %w", err)
+		return fmt.Errorf("cannot read from storage: %w", err)
 	}
 
 	// Nettoyer le fichier test
 	if err := storageClient.DeleteObject(testKey); err != nil {
 		if verbose {
-			utils.Warn("Impossible de supprimer le fichier test: %v", err)
+			utils.Warn("Unable to delete test file: %v", err)
 		}
 	}
 
@@ -284,10 +319,19 @@ func GenerateConfig(outputPath string) error {
 
 // GenerateConfigWithType génère une configuration pour un type de stockage spécifique
 func GenerateConfigWithType(outputPath, storageType string) error {
+	config, err := defaultConfig(storageType)
+	if err != nil {
+		return err
+	}
+	return writeGeneratedConfig(config, outputPath)
+}
+
+// defaultConfig construit la configuration par défaut d'un type de stockage, avec une clé générée
+func defaultConfig(storageType string) (*utils.Config, error) {
 	// Générer une clé de chiffrement sécurisée
 	encryptionKey, err := crypto.GenerateKeyV2(crypto.AES256GCM)
 	if err != nil {
-		return fmt.Errorf("error generating key: %w", err)
+		return nil, fmt.Errorf("error generating key: %w", err)
 	}
 
 	// Configuration par défaut
@@ -308,7 +352,7 @@ func GenerateConfigWithType(outputPath, storageType string) error {
 		config.Storage.Username = "YOUR_USERNAME"
 		config.Storage.Password = "YOUR_PASSWORD"
 	default:
-		return fmt.Errorf("unsupported storage type: %s", storageType)
+		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
 	}
 
 	config.Backup.EncryptionKey = hex.EncodeToString([]byte(encryptionKey))
@@ -328,6 +372,8 @@ func GenerateConfigWithType(outputPath, storageType string) error {
 		"*.zip", "*.tar.gz", "*.rar", "*.7z", "*.iso",
 		"*.vmdk", "*.vdi", "*.qcow2", "*.raw",
 	}
+	config.Backup.HonorIgnoreFiles = true
+	config.Backup.RetryFailedFiles = true
 	config.Backup.ChunkSize = "32MB"    // Smaller chunks for stability
 	config.Backup.MemoryLimit = "256MB" // Less memory usage
 	config.Backup.NetworkTimeout = 120  // 2 minutes timeout
@@ -344,6 +390,11 @@ func GenerateConfigWithType(outputPath, storageType string) error {
 	config.Retention.Days = 30
 	config.Retention.MaxBackups = 10
 
+	return config, nil
+}
+
+// writeGeneratedConfig écrit une configuration générée (en créant le répertoire parent)
+func writeGeneratedConfig(config *utils.Config, outputPath string) error {
 	// Créer le répertoire parent si nécessaire
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("error creating directory: %w", err)
@@ -554,7 +605,8 @@ func configureBackupInteractive(config *utils.Config) error {
 	checksumModes := []string{
 		"fast (recommended - 5x faster, very secure)",
 		"full (slowest - maximum security)",
-		"metadata (fastest - 10x faster, good security)",
+		"metadata (10x faster, never reads file contents - reduced safety)",
+		"mtime-size (fastest, size and mtime only - reduced safety, for multi-million-file shares)",
 	}
 	checksumChoice := utils.PromptChoice("Choose checksum mode:", checksumModes, 0)
 	switch checksumChoice {
@@ -564,6 +616,8 @@ func configureBackupInteractive(config *utils.Config) error {
 		config.Backup.ChecksumMode = "full"
 	case 2:
 		config.Backup.ChecksumMode = "metadata"
+	case 3:
+		config.Backup.ChecksumMode = "mtime-size"
 	}
 
 	config.Backup.CompressionLevel = utils.PromptInt("Compression level (1=fast, 9=best)", 1, 1, 9)
@@ -611,6 +665,8 @@ func configureBackupInteractive(config *utils.Config) error {
 		utils.PrintSuccess("Extended skip patterns configured (includes archives and disk images)")
 	}
 
+	config.Backup.HonorIgnoreFiles = utils.PromptYesNo("Apply .bcrdfignore files found in the source directories?", true)
+
 	return nil
 }
 
@@ -0,0 +1,83 @@
+package validator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestConfigIncludeMergesBase(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shared/base.yaml": `storage:
+  type: memory
+  bucket: fleet
+backup:
+  encryption_key: 000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+  compression_level: 6
+  network_timeout: 60
+  retry_attempts: 3
+  retry_delay: 2
+  skip_patterns: ["*.tmp"]
+retention:
+  days: 90
+`,
+		"host.yaml": `include: [shared/base.yaml]
+backup:
+  max_workers: 4
+  skip_patterns: ["*.log"]
+retention:
+  days: 14
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hostFile := filepath.Join(dir, "host.yaml")
+	if err := ValidateSchemaFile(hostFile); err != nil {
+		t.Fatalf("configuration avec include refusée: %v", err)
+	}
+	config, err := utils.LoadConfig(hostFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Base partagée: stockage et chiffrement; fichier local: réglages de la machine (listes remplacées)
+	if config.Storage.Bucket != "fleet" || config.Backup.CompressionLevel != 6 {
+		t.Errorf("valeurs de la base non reprises: %+v", config.Storage)
+	}
+	if config.Backup.MaxWorkers != 4 || config.Retention.Days != 14 {
+		t.Errorf("surcharges locales ignorées: workers %d, days %d", config.Backup.MaxWorkers, config.Retention.Days)
+	}
+	if strings.Join(config.Backup.SkipPatterns, ",") != "*.log" {
+		t.Errorf("la liste locale doit remplacer celle de la base: %v", config.Backup.SkipPatterns)
+	}
+
+	// Les fichiers inclus sont vérifiés contre le schéma
+	base := filepath.Join(dir, "shared", "base.yaml")
+	if err := os.WriteFile(base, []byte(files["shared/base.yaml"]+"bakup:\n  x: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateSchemaFile(hostFile); err == nil || !strings.Contains(err.Error(), "base.yaml") {
+		t.Errorf("clé inconnue de la base non signalée: %v", err)
+	}
+
+	// Inclusion circulaire refusée
+	if err := os.WriteFile(base, []byte("include: [../host.yaml]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := utils.LoadConfig(hostFile); !errors.Is(err, utils.ErrConfig) || !strings.Contains(err.Error(), "circular include") {
+		t.Errorf("inclusion circulaire acceptée: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// ProviderPreset préremplit la configuration S3 d'un fournisseur (bcrdf init --preset)
+type ProviderPreset struct {
+	Name            string
+	Description     string
+	EndpointFormat  string // %s est remplacé par la région (vide = demandé à l'utilisateur)
+	DefaultRegion   string
+	Regions         []string
+	AddressingStyle string // path ou virtual
+	StorageClass    string // Vide: le fournisseur ne gère pas les classes de stockage
+	Notes           []string
+}
+
+// providerPresets sont les fournisseurs S3 compatibles connus
+var providerPresets = map[string]ProviderPreset{
+	"scaleway": {
+		Name:            "scaleway",
+		Description:     "Scaleway Object Storage",
+		EndpointFormat:  "https://s3.%s.scw.cloud",
+		DefaultRegion:   "fr-par",
+		Regions:         []string{"fr-par", "nl-ams", "pl-waw"},
+		AddressingStyle: "virtual",
+		StorageClass:    "STANDARD",
+		Notes: []string{
+			"storage_class GLACIER is supported (objects must be restored before a bcrdf restore)",
+		},
+	},
+	"wasabi": {
+		Name:            "wasabi",
+		Description:     "Wasabi Hot Cloud Storage",
+		EndpointFormat:  "https://s3.%s.wasabisys.com",
+		DefaultRegion:   "eu-central-1",
+		Regions:         []string{"us-east-1", "us-east-2", "us-west-1", "eu-central-1", "eu-central-2", "eu-west-1", "ap-northeast-1"},
+		AddressingStyle: "virtual",
+		Notes: []string{
+			"Wasabi has no storage classes: storage_class is left empty",
+			"objects deleted before 90 days are still billed: keep retention.days at 90 or more",
+		},
+	},
+	"backblaze": {
+		Name:            "backblaze",
+		Description:     "Backblaze B2 (S3-compatible API)",
+		EndpointFormat:  "https://s3.%s.backblazeb2.com",
+		DefaultRegion:   "eu-central-003",
+		Regions:         []string{"us-west-001", "us-west-002", "us-west-004", "us-east-005", "eu-central-003"},
+		AddressingStyle: "virtual",
+		Notes: []string{
+			"use an application key: keyID as access key, applicationKey as secret key",
+			"B2 only accepts the default storage class: storage_class is left empty",
+		},
+	},
+	"minio": {
+		Name:            "minio",
+		Description:     "MinIO (self-hosted)",
+		DefaultRegion:   "us-east-1",
+		AddressingStyle: "path",
+		Notes: []string{
+			"MinIO requires path-style addressing unless MINIO_DOMAIN is configured",
+		},
+	},
+	"hetzner": {
+		Name:            "hetzner",
+		Description:     "Hetzner Object Storage",
+		EndpointFormat:  "https://%s.your-objectstorage.com",
+		DefaultRegion:   "fsn1",
+		Regions:         []string{"fsn1", "nbg1", "hel1"},
+		AddressingStyle: "virtual",
+		Notes: []string{
+			"Hetzner Object Storage has no storage classes: storage_class is left empty",
+			"for a Hetzner Storage Box, use WebDAV instead (bcrdf init --interactive)",
+		},
+	},
+}
+
+// PresetNames retourne les noms des presets disponibles
+func PresetNames() []string {
+	names := make([]string, 0, len(providerPresets))
+	for name := range providerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupPreset retourne un preset par son nom
+func LookupPreset(name string) (ProviderPreset, error) {
+	preset, ok := providerPresets[strings.ToLower(name)]
+	if !ok {
+		return ProviderPreset{}, fmt.Errorf("%w: unknown preset %q (available: %s)",
+			utils.ErrConfig, name, strings.Join(PresetNames(), ", "))
+	}
+	return preset, nil
+}
+
+// Apply préremplit la configuration de stockage pour une région donnée
+func (p ProviderPreset) Apply(config *utils.Config, region string) {
+	config.Storage.Type = "s3"
+	config.Storage.Region = region
+	config.Storage.AddressingStyle = p.AddressingStyle
+	config.Storage.StorageClass = p.StorageClass
+	if p.EndpointFormat != "" {
+		config.Storage.Endpoint = fmt.Sprintf(p.EndpointFormat, region)
+	}
+}
+
+// GenerateConfigWithPreset génère une configuration pour un fournisseur: les paramètres connus
+// sont préremplis, seuls la région, le bucket et les identifiants sont demandés
+func GenerateConfigWithPreset(outputPath, presetName string) error {
+	preset, err := LookupPreset(presetName)
+	if err != nil {
+		return err
+	}
+
+	config, err := defaultConfig("s3")
+	if err != nil {
+		return err
+	}
+
+	utils.PrintHeader(fmt.Sprintf("BCRDF Configuration - %s", preset.Description))
+
+	region := preset.DefaultRegion
+	if len(preset.Regions) > 0 {
+		region = promptRegion(preset)
+	}
+	preset.Apply(config, region)
+	if preset.EndpointFormat == "" {
+		config.Storage.Endpoint = utils.PromptString("Server URL", "http://localhost:9000")
+		config.Storage.Region = utils.PromptString("Region", preset.DefaultRegion)
+	}
+	utils.PrintInfo(fmt.Sprintf("Endpoint: %s (%s-style addressing)", config.Storage.Endpoint, preset.AddressingStyle))
+
+	config.Storage.Bucket = promptRequired("Bucket name")
+	config.Storage.AccessKey = promptRequired("Access Key")
+	config.Storage.SecretKey = utils.PromptPassword("Secret Key")
+
+	for _, note := range preset.Notes {
+		utils.PrintInfo(note)
+	}
+
+	if err := writeGeneratedConfig(config, outputPath); err != nil {
+		return err
+	}
+	utils.PrintSuccess(fmt.Sprintf("Configuration saved to: %s", outputPath))
+	return nil
+}
+
+// promptRegion fait choisir une région du fournisseur
+func promptRegion(preset ProviderPreset) string {
+	defaultChoice := 0
+	for i, region := range preset.Regions {
+		if region == preset.DefaultRegion {
+			defaultChoice = i
+		}
+	}
+	return preset.Regions[utils.PromptChoice("Select the region:", preset.Regions, defaultChoice)]
+}
+
+// promptRequired demande une valeur jusqu'à ce qu'elle soit renseignée
+func promptRequired(prompt string) string {
+	for {
+		if value := utils.PromptString(prompt, ""); value != "" {
+			return value
+		}
+		fmt.Printf("❌ %s is required.\n", prompt)
+	}
+}
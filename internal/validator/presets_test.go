@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestPresetApply(t *testing.T) {
+	preset, err := LookupPreset("Scaleway")
+	if err != nil {
+		t.Fatalf("Preset scaleway introuvable: %v", err)
+	}
+
+	config := &utils.Config{}
+	preset.Apply(config, "nl-ams")
+	if config.Storage.Endpoint != "https://s3.nl-ams.scw.cloud" {
+		t.Errorf("Endpoint inattendu: %s", config.Storage.Endpoint)
+	}
+	if config.Storage.Region != "nl-ams" || config.Storage.AddressingStyle != "virtual" {
+		t.Errorf("Région ou adressage inattendus: %s / %s", config.Storage.Region, config.Storage.AddressingStyle)
+	}
+
+	minio, _ := LookupPreset("minio")
+	minio.Apply(config, minio.DefaultRegion)
+	if config.Storage.AddressingStyle != "path" || config.Storage.StorageClass != "" {
+		t.Errorf("MinIO doit utiliser le path-style sans classe de stockage")
+	}
+
+	if _, err := LookupPreset("unknown"); err == nil {
+		t.Error("Un preset inconnu doit être refusé")
+	}
+}
@@ -0,0 +1,393 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bcrdf/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaIssue est un problème du fichier de configuration, localisé dans le YAML
+type SchemaIssue struct {
+	Line    int
+	Column  int
+	Key     string // Chemin de la clé (ex: backup.max_workers)
+	Message string
+}
+
+func (i SchemaIssue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Key, i.Message)
+}
+
+// sizeKeys sont les tailles exprimées avec une unité ("10MB", "5GB")
+var sizeKeys = map[string]bool{
+	"backup.buffer_size":            true,
+	"backup.batch_size_limit":       true,
+	"backup.chunk_size":             true,
+	"backup.chunk_size_large":       true,
+	"backup.memory_limit":           true,
+	"backup.large_file_threshold":   true,
+	"backup.ultra_large_threshold":  true,
+	"backup.max_file_size":          true,
+	"backup.restore_rate_limit":     true,
+	"backup.restore_cache_max_size": true,
+	"retention.max_total_size":      true,
+}
+
+// enumKeys sont les clés à valeurs fermées
+var enumKeys = map[string][]string{
+	"storage.type":                 {"s3", "webdav", "memory"},
+	"storage.storage_class":        {"STANDARD", "GLACIER", "DEEP_ARCHIVE", "INTELLIGENT_TIERING"},
+	"storage.addressing_style":     {"path", "virtual"},
+	"backup.encryption_algo":       {"aes-256-gcm", "xchacha20-poly1305", "none"},
+	"backup.checksum_mode":         {"full", "fast", "metadata", "mtime-size"},
+	"backup.changed_file_policy":   {"ignore", "retry", "snapshot", "skip", "verify"},
+	"backup.symlinks":              {"store", "follow", "skip"},
+	"backup.file_handlers.handler": {"sqlite", "command"},
+	"backup.index_compression":     {"gzip", "none"},
+	"backup.anomaly_guard":         {"warn", "block", "off"},
+	"backup.restore_io_priority":   {"normal", "low", "idle"},
+}
+
+// rangeKeys sont les entiers bornés (mêmes bornes que la validation au chargement)
+var rangeKeys = map[string][2]int{
+	"backup.compression_level": {1, 22},
+	"backup.max_workers":       {1, 1 << 16},
+	"backup.network_timeout":   {30, 1 << 30},
+	"backup.retry_attempts":    {0, 10},
+	"backup.retry_delay":       {1, 60},
+	"backup.anomaly_threshold": {0, 100},
+	"backup.restore_nice":      {0, 19},
+	"backup.max_parallel_jobs": {0, 1 << 16},
+	"retention.min_backups":    {0, 1 << 16},
+}
+
+// ValidateSchemaFile vérifie un fichier de configuration contre le schéma de utils.Config:
+// clés inconnues, types, unités de taille, valeurs énumérées, bornes et options incompatibles.
+// Les problèmes sont tous remontés ensemble, avec leur ligne et colonne. Les fichiers inclus
+// (include:) sont vérifiés à leur tour, chacun avec ses propres numéros de ligne.
+func ValidateSchemaFile(path string) error {
+	return validateSchemaFile(path, make(map[string]bool))
+}
+
+// validateSchemaFile vérifie path puis ses fichiers inclus (visited évite les inclusions circulaires)
+func validateSchemaFile(path string, visited map[string]bool) error {
+	if absPath, err := filepath.Abs(path); err == nil {
+		if visited[absPath] {
+			return nil
+		}
+		visited[absPath] = true
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+	}
+	issues, err := ValidateSchema(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", utils.ErrConfig, path, err)
+	}
+	if len(issues) == 0 {
+		includes, err := utils.ConfigIncludes(path)
+		if err != nil {
+			return fmt.Errorf("%w: %w", utils.ErrConfig, err)
+		}
+		for _, include := range includes {
+			if err := validateSchemaFile(include, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = fmt.Sprintf("  %s:%s", path, issue)
+	}
+	return fmt.Errorf("%w: %d problem(s) in %s:\n%s", utils.ErrConfig, len(issues), path, strings.Join(lines, "\n"))
+}
+
+// ValidateSchema vérifie un document YAML de configuration et retourne ses problèmes
+func ValidateSchema(data []byte) ([]SchemaIssue, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	checker := &schemaChecker{values: make(map[string]*yaml.Node)}
+	checker.checkMapping(document.Content[0], reflect.TypeOf(utils.Config{}), "")
+	checker.checkExclusions()
+
+	sort.SliceStable(checker.issues, func(i, j int) bool {
+		return checker.issues[i].Line < checker.issues[j].Line
+	})
+	return checker.issues, nil
+}
+
+// schemaChecker parcourt le YAML en parallèle du type Go attendu
+type schemaChecker struct {
+	issues []SchemaIssue
+	values map[string]*yaml.Node // Scalaires rencontrés, pour les règles entre clés
+}
+
+func (c *schemaChecker) report(node *yaml.Node, key, format string, args ...interface{}) {
+	c.issues = append(c.issues, SchemaIssue{
+		Line:    node.Line,
+		Column:  node.Column,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkMapping vérifie un bloc YAML correspondant à une structure
+func (c *schemaChecker) checkMapping(node *yaml.Node, structType reflect.Type, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		if !isNull(node) {
+			c.report(node, strings.TrimSuffix(prefix, "."), "expected a mapping, got %s", describe(node))
+		}
+		return
+	}
+
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if tag := field.Tag.Get("mapstructure"); tag != "" && tag != "-" {
+			fields[tag] = field
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		path := prefix + keyNode.Value
+
+		field, ok := fields[keyNode.Value]
+		if !ok {
+			if suggestion := closestKey(keyNode.Value, fields); suggestion != "" {
+				c.report(keyNode, path, "unknown key (did you mean %s?)", suggestion)
+			} else {
+				c.report(keyNode, path, "unknown key")
+			}
+			continue
+		}
+		c.checkValue(valueNode, field.Type, path)
+	}
+}
+
+// checkValue vérifie une valeur selon son type Go puis les règles propres à la clé
+func (c *schemaChecker) checkValue(node *yaml.Node, fieldType reflect.Type, path string) {
+	if fieldType.Kind() == reflect.Struct {
+		c.checkMapping(node, fieldType, path+".")
+		return
+	}
+	if isNull(node) {
+		return
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			c.report(node, path, "expected a list, got %s", describe(node))
+			return
+		}
+		// Listes de blocs (backup.file_handlers): chaque élément est vérifié comme une structure
+		if elem := fieldType.Elem(); elem.Kind() == reflect.Struct {
+			for _, item := range node.Content {
+				c.checkMapping(item, elem, path+".")
+			}
+			return
+		}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				c.report(item, path, "expected a list of strings, got %s", describe(item))
+			}
+		}
+		return
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			c.report(node, path, "expected a string, got %s", describe(node))
+			return
+		}
+	case reflect.Int, reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			hint := ""
+			if sizeLike(node.Value) {
+				hint = " (this key takes a plain number, without unit)"
+			}
+			c.report(node, path, "expected an integer, got %s%s", describe(node), hint)
+			return
+		}
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			c.report(node, path, "expected true or false, got %s", describe(node))
+			return
+		}
+	}
+
+	c.values[path] = node
+	c.checkRules(node, path)
+}
+
+// checkRules applique les règles de valeur propres à une clé (unités, énumérations, bornes)
+func (c *schemaChecker) checkRules(node *yaml.Node, path string) {
+	value := strings.TrimSpace(node.Value)
+	if value == "" {
+		return
+	}
+
+	if sizeKeys[path] {
+		if _, err := utils.ParseSize(value); err != nil {
+			c.report(node, path, "invalid size %q (expected a number with a unit, e.g. 512KB, 32MB, 5GB)", value)
+		}
+	}
+
+	if allowed, ok := enumKeys[path]; ok && !containsValue(allowed, value) {
+		c.report(node, path, "invalid value %q (expected one of: %s)", value, strings.Join(allowed, ", "))
+	}
+
+	if bounds, ok := rangeKeys[path]; ok {
+		if number, err := strconv.Atoi(value); err == nil && (number < bounds[0] || number > bounds[1]) {
+			if bounds[1] >= 1<<16 {
+				c.report(node, path, "must be at least %d, got %d", bounds[0], number)
+			} else {
+				c.report(node, path, "must be between %d and %d, got %d", bounds[0], bounds[1], number)
+			}
+		}
+	}
+
+	if path == "backup.error_policy" {
+		if _, err := utils.ParseErrorPolicy(value); err != nil {
+			c.report(node, path, "%v", err)
+		}
+	}
+}
+
+// checkExclusions signale les options incompatibles entre elles
+func (c *schemaChecker) checkExclusions() {
+	storageType := "s3"
+	if node := c.values["storage.type"]; node != nil && node.Value != "" {
+		storageType = node.Value
+	}
+
+	var foreign []string
+	switch storageType {
+	case "s3":
+		foreign = []string{"storage.username", "storage.password", "storage.destructive.username", "storage.destructive.password"}
+	case "webdav":
+		foreign = []string{"storage.bucket", "storage.access_key", "storage.secret_key", "storage.storage_class", "storage.addressing_style",
+			"storage.destructive.access_key", "storage.destructive.secret_key", "storage.destructive.role_arn"}
+	}
+	for _, key := range foreign {
+		if node := c.set(key); node != nil {
+			c.report(node, key, "not used with storage.type %s", storageType)
+		}
+	}
+
+	if appendOnly := c.set("backup.append_only"); appendOnly != nil && appendOnly.Value == "true" {
+		for _, key := range []string{"storage.destructive.access_key", "storage.destructive.role_arn", "storage.destructive.username"} {
+			if node := c.set(key); node != nil {
+				c.report(node, key, "destructive credentials conflict with backup.append_only (deletions are refused)")
+			}
+		}
+	}
+
+	if algo := c.set("backup.encryption_algo"); algo != nil && algo.Value == "none" && c.set("backup.allow_unencrypted") == nil {
+		c.report(algo, "backup.encryption_algo", "none requires backup.allow_unencrypted: true")
+	}
+
+	if minNode, maxNode := c.set("retention.min_backups"), c.set("retention.max_backups"); minNode != nil && maxNode != nil {
+		minBackups, errMin := strconv.Atoi(minNode.Value)
+		maxBackups, errMax := strconv.Atoi(maxNode.Value)
+		if errMin == nil && errMax == nil && maxBackups > 0 && minBackups > maxBackups {
+			c.report(minNode, "retention.min_backups", "cannot exceed retention.max_backups (%d)", maxBackups)
+		}
+	}
+
+	if guard := c.set("backup.anomaly_guard"); guard != nil && guard.Value == "off" {
+		if node := c.set("backup.anomaly_threshold"); node != nil && node.Value != "0" {
+			c.report(node, "backup.anomaly_threshold", "has no effect with backup.anomaly_guard: off")
+		}
+	}
+}
+
+// set retourne le nœud d'une clé renseignée (non vide), sinon nil
+func (c *schemaChecker) set(key string) *yaml.Node {
+	if node := c.values[key]; node != nil && strings.TrimSpace(node.Value) != "" && node.Value != "false" {
+		return node
+	}
+	return nil
+}
+
+// closestKey propose la clé connue la plus proche d'une clé inconnue (faute de frappe)
+func closestKey(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+	for candidate := range fields {
+		if distance := levenshtein(key, candidate); distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// levenshtein calcule la distance d'édition entre deux chaînes
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// isNull indique une valeur absente (clé sans valeur ou ~)
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// sizeLike indique une valeur qui ressemble à une taille avec unité
+func sizeLike(value string) bool {
+	_, err := utils.ParseSize(value)
+	return err == nil
+}
+
+// describe décrit un nœud YAML pour les messages d'erreur
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// containsValue indique si une valeur fait partie d'une liste
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	config := `
+storage:
+  type: webdav
+  endpoint: https://dav.example.com/
+  bucket: backups
+backup:
+  max_worker: 8
+  compression_level: fast
+  memory_limit: 256 megs
+  error_policy: threshold=5%
+  anomaly_guard: maybe
+  skip_patterns: "*.tmp"
+  file_handlers:
+    - pattern: "*.db"
+      handler: sqlite
+    - pattern: "*.rdb"
+      handler: redis
+      comand: redis-cli --rdb {output}
+retention:
+  days: 30
+  max_backups: 5
+  min_backups: 7
+`
+	issues, err := ValidateSchema([]byte(config))
+	if err != nil {
+		t.Fatalf("YAML valide refusé: %v", err)
+	}
+
+	expected := map[string]string{
+		"storage.bucket":               "not used with storage.type webdav",
+		"backup.max_worker":            "did you mean max_workers",
+		"backup.compression_level":     "expected an integer",
+		"backup.memory_limit":          "invalid size",
+		"backup.anomaly_guard":         "expected one of",
+		"backup.skip_patterns":         "expected a list",
+		"backup.file_handlers.handler": "expected one of",
+		"backup.file_handlers.comand":  "did you mean command",
+		"retention.min_backups":        "cannot exceed retention.max_backups",
+	}
+	if len(issues) != len(expected) {
+		t.Errorf("Nombre de problèmes incorrect: attendu %d, obtenu %d: %v", len(expected), len(issues), issues)
+	}
+	for _, issue := range issues {
+		want, ok := expected[issue.Key]
+		if !ok || !strings.Contains(issue.Message, want) {
+			t.Errorf("Problème inattendu: %s", issue)
+		}
+		if issue.Line == 0 {
+			t.Errorf("Ligne manquante pour %s", issue.Key)
+		}
+	}
+}
@@ -2,12 +2,18 @@ package s3
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -20,6 +26,7 @@ type ObjectInfo struct {
 	Key          string
 	Size         int64
 	LastModified time.Time
+	ETag         string
 }
 
 // Client représente un client S3
@@ -33,20 +40,50 @@ type Client struct {
 
 // NewClient crée un nouveau client S3
 func NewClient(accessKey, secretKey, region, endpoint, bucket string) (*Client, error) {
+	return NewClientWithRole(accessKey, secretKey, region, endpoint, bucket, "")
+}
+
+// NewClientWithRole crée un client S3 qui assume un rôle STS (roleARN vide = identifiants statiques)
+func NewClientWithRole(accessKey, secretKey, region, endpoint, bucket, roleARN string) (*Client, error) {
+	return NewClientWithOptions(Options{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    region,
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		RoleARN:   roleARN,
+	})
+}
+
+// Options regroupe les paramètres de connexion d'un client S3
+type Options struct {
+	AccessKey     string
+	SecretKey     string
+	Region        string
+	Endpoint      string
+	Bucket        string
+	RoleARN       string // Rôle STS assumé (vide = identifiants statiques)
+	VirtualHosted bool   // Adressage bucket.endpoint au lieu de endpoint/bucket (endpoint personnalisé)
+}
+
+// NewClientWithOptions crée un client S3 à partir de ses options de connexion
+func NewClientWithOptions(opts Options) (*Client, error) {
+	region, bucket, roleARN := opts.Region, opts.Bucket, opts.RoleARN
+
 	// Configuration AWS
 	config := &aws.Config{
 		Region: aws.String(region),
 		Credentials: credentials.NewStaticCredentials(
-			accessKey,
-			secretKey,
+			opts.AccessKey,
+			opts.SecretKey,
 			"",
 		),
 	}
 
 	// Configuration de l'endpoint personnalisé si fourni
-	if endpoint != "" {
-		config.Endpoint = aws.String(endpoint)
-		config.S3ForcePathStyle = aws.Bool(true)
+	if opts.Endpoint != "" {
+		config.Endpoint = aws.String(opts.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(!opts.VirtualHosted)
 	}
 
 	// Créer la session
@@ -55,6 +92,11 @@ func NewClient(accessKey, secretKey, region, endpoint, bucket string) (*Client,
 		return nil, fmt.Errorf("error creating AWS session: %w", err)
 	}
 
+	// Assumer le rôle STS si demandé (identifiants temporaires renouvelés automatiquement)
+	if roleARN != "" {
+		sess = sess.Copy(&aws.Config{Credentials: stscreds.NewCredentials(sess, roleARN)})
+	}
+
 	// Créer le client S3
 	s3Client := s3.New(sess)
 
@@ -87,14 +129,17 @@ func (c *Client) Upload(key string, data []byte) error {
 
 // UploadWithStorageClass upload un fichier vers S3 avec une classe de stockage spécifique
 func (c *Client) UploadWithStorageClass(key string, data []byte, storageClass string) error {
-	utils.Debug("Upload vers S3: %s/%s (%d bytes)", c.bucket, key, len(data))
+	return c.UploadStream(context.Background(), key, bytes.NewReader(data), int64(len(data)), storageClass)
+}
+
+// UploadStream upload un flux vers S3 (multipart au-delà d'une partie, sans charger l'objet en mémoire)
+// size est une indication (-1 si inconnue), l'annulation de ctx interrompt l'upload
+func (c *Client) UploadStream(ctx context.Context, key string, reader io.Reader, size int64, storageClass string) error {
+	utils.Debug("Upload to S3: %s/%s (%d bytes)", c.bucket, key, size)
 	if storageClass != "" {
 		utils.Debug("   Storage class: %s", storageClass)
 	}
 
-	// Créer un reader pour les données
-	reader := bytes.NewReader(data)
-
 	// Paramètres d'upload
 	params := &s3manager.UploadInput{
 		Bucket: aws.String(c.bucket),
@@ -108,9 +153,9 @@ func (c *Client) UploadWithStorageClass(key string, data []byte, storageClass st
 	}
 
 	// Effectuer l'upload
-	_, err := c.uploader.Upload(params)
+	_, err := c.uploader.UploadWithContext(ctx, params)
 	if err != nil {
-		return fmt.Errorf("error during l'upload vers S3: %w", err)
+		return fmt.Errorf("error uploading to S3: %w", err)
 	}
 
 	utils.Debug("Upload successful: %s/%s", c.bucket, key)
@@ -140,6 +185,63 @@ func (c *Client) Download(key string) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// DownloadStream écrit un objet S3 dans writer et retourne le nombre d'octets écrits
+// Un io.WriterAt (fichier) permet le téléchargement parallèle par parties.
+func (c *Client) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	utils.Debug("Download depuis S3: %s/%s", c.bucket, key)
+
+	params := &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}
+
+	if writerAt, ok := writer.(io.WriterAt); ok {
+		n, err := c.downloader.DownloadWithContext(ctx, writerAt, params)
+		if err != nil {
+			return n, fmt.Errorf("error downloading from S3: %w", err)
+		}
+		return n, nil
+	}
+
+	result, err := c.s3Client.GetObjectWithContext(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("error downloading from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	n, err := io.Copy(writer, result.Body)
+	if err != nil {
+		return n, fmt.Errorf("error downloading from S3: %w", err)
+	}
+	return n, nil
+}
+
+// DownloadRange télécharge une plage d'octets d'un objet (length <= 0 = jusqu'à la fin)
+func (c *Client) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	utils.Debug("Ranged download from S3: %s/%s (offset %d, length %d)", c.bucket, key, offset, length)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	result, err := c.s3Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error downloading range from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading range from S3: %w", err)
+	}
+	return data, nil
+}
+
 // ListObjects liste les objets dans un préfixe
 func (c *Client) ListObjects(prefix string) ([]string, error) {
 	utils.Debug("S3 object list with prefix: %s", prefix)
@@ -242,34 +344,92 @@ func (c *Client) DeleteObject(key string) error {
 	return nil
 }
 
-// Exists vérifie si un objet existe
-func (c *Client) Exists(key string) (bool, error) {
-	utils.Debug("Checking existence: %s/%s", c.bucket, key)
+// ErrNotFound est retournée par Stat lorsque l'objet n'existe pas
+var ErrNotFound = errors.New("object not found")
+
+// Stat retourne la taille et la date d'un objet (HEAD), sans le télécharger
+func (c *Client) Stat(key string) (ObjectInfo, error) {
+	utils.Debug("Stat S3 object: %s/%s", c.bucket, key)
 
-	// Paramètres de vérification
 	params := &s3.HeadObjectInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(key),
 	}
 
-	// Effectuer la vérification
-	_, err := c.s3Client.HeadObject(params)
+	result, err := c.s3Client.HeadObject(params)
 	if err != nil {
-		// Si l'objet n'existe pas, AWS retourne une erreur spécifique
 		if isNotFoundError(err) {
-			return false, nil
+			return ObjectInfo{}, ErrNotFound
 		}
-		return false, fmt.Errorf("error checking existence: %w", err)
+		return ObjectInfo{}, fmt.Errorf("error checking object: %w", err)
 	}
 
-	return true, nil
+	info := ObjectInfo{Key: key}
+	if result.ContentLength != nil {
+		info.Size = *result.ContentLength
+	}
+	if result.LastModified != nil {
+		info.LastModified = *result.LastModified
+	}
+	if result.ETag != nil {
+		info.ETag = *result.ETag
+	}
+	return info, nil
 }
 
-// isNotFoundError vérifie si l'erreur est "not found"
+// ServerTime retourne l'heure du serveur S3, lue dans l'en-tête Date d'une requête HEAD sur le bucket
+func (c *Client) ServerTime() (time.Time, error) {
+	request, _ := c.s3Client.HeadBucketRequest(&s3.HeadBucketInput{Bucket: aws.String(c.bucket)})
+	err := request.Send()
+	// Une réponse d'erreur (droits restreints, horloge refusée) porte aussi l'en-tête Date
+	if request.HTTPResponse != nil {
+		if serverTime, parseErr := http.ParseTime(request.HTTPResponse.Header.Get("Date")); parseErr == nil {
+			return serverTime, nil
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error reading server time: %w", err)
+	}
+	return time.Time{}, fmt.Errorf("no Date header in the S3 response")
+}
+
+// Exists vérifie si un objet existe
+func (c *Client) Exists(key string) (bool, error) {
+	_, err := c.Stat(key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PresignGet génère une URL de téléchargement valable expires sans identifiants
+// Signée avec des identifiants temporaires (role_arn), elle expire au plus tard avec eux
+func (c *Client) PresignGet(key string, expires time.Duration) (string, error) {
+	request, _ := c.s3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	url, err := request.Presign(expires)
+	if err != nil {
+		return "", fmt.Errorf("error presigning %s: %w", key, err)
+	}
+	return url, nil
+}
+
+// isNotFoundError vérifie si l'erreur AWS signifie que l'objet n'existe pas
 func isNotFoundError(err error) bool {
-	// Cette fonction devrait vérifier le type d'erreur AWS
-	// Pour simplifier, on considère que toute erreur signifie "not found"
-	return err != nil
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) && requestErr.StatusCode() == 404 {
+		return true
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case "NotFound", s3.ErrCodeNoSuchKey:
+			return true
+		}
+	}
+	return false
 }
 
 // GetBucketInfo retourne les informations sur le bucket
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"bcrdf/pkg/utils"
+)
+
+// DefaultMetadataCacheEntries est le nombre d'objets gardés en mémoire par le cache de métadonnées
+const DefaultMetadataCacheEntries = 256
+
+// CachingClient met en cache les petits objets relus souvent (index, index de base, métadonnées
+// de chunks) en mémoire (LRU) et éventuellement sur disque. Chaque lecture est validée par un
+// Stat (ETag, sinon taille et date): un objet réécrit n'est jamais servi depuis le cache.
+type CachingClient struct {
+	Client
+	dir        string // Cache disque (vide = mémoire uniquement)
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// cacheEntry est un objet en cache avec son validateur
+type cacheEntry struct {
+	Key       string `json:"key"`
+	Validator string `json:"validator"`
+	Data      []byte `json:"data"`
+}
+
+// NewCachingClient ajoute un cache de métadonnées devant un client de stockage
+func NewCachingClient(client Client, dir string, maxEntries int) *CachingClient {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMetadataCacheEntries
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			utils.Warn("Metadata disk cache disabled: %v", err)
+			dir = ""
+		}
+	}
+	return &CachingClient{
+		Client:     client,
+		dir:        dir,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// isCacheable indique si un objet est une métadonnée (petite, relue plusieurs fois par exécution)
+func isCacheable(key string) bool {
+	return strings.HasPrefix(key, "indexes/") || strings.HasPrefix(key, "index-bases/") ||
+		strings.HasSuffix(key, ".metadata")
+}
+
+// Download sert les métadonnées depuis le cache si elles n'ont pas changé
+func (c *CachingClient) Download(key string) ([]byte, error) {
+	if !isCacheable(key) {
+		return c.Client.Download(key)
+	}
+
+	info, err := c.Client.Stat(key)
+	if err != nil {
+		return c.Client.Download(key)
+	}
+	validator := objectValidator(info)
+
+	if data, ok := c.lookup(key, validator); ok {
+		utils.Debug("Metadata cache hit: %s", key)
+		return data, nil
+	}
+
+	data, err := c.Client.Download(key)
+	if err != nil {
+		return nil, err
+	}
+	c.store(&cacheEntry{Key: key, Validator: validator, Data: data})
+	return data, nil
+}
+
+// DownloadStream sert les métadonnées depuis le cache (petits objets), les autres objets en flux
+func (c *CachingClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	if !isCacheable(key) {
+		return c.Client.DownloadStream(ctx, key, writer)
+	}
+	data, err := c.Download(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := writer.Write(data)
+	return int64(n), err
+}
+
+// Upload invalide l'entrée en cache avant d'écrire l'objet
+func (c *CachingClient) Upload(key string, data []byte) error {
+	c.invalidate(key)
+	return c.Client.Upload(key, data)
+}
+
+// UploadStream invalide l'entrée en cache avant d'écrire l'objet
+func (c *CachingClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	c.invalidate(key)
+	return c.Client.UploadStream(ctx, key, reader, size)
+}
+
+// DeleteObject invalide l'entrée en cache avant de supprimer l'objet
+func (c *CachingClient) DeleteObject(key string) error {
+	c.invalidate(key)
+	return c.Client.DeleteObject(key)
+}
+
+// objectValidator identifie une version d'objet (ETag, sinon taille et date de modification)
+func objectValidator(info ObjectInfo) string {
+	if info.ETag != "" {
+		return info.ETag
+	}
+	return fmt.Sprintf("%d-%d", info.Size, info.LastModified.UnixNano())
+}
+
+// lookup retourne les données en cache (mémoire puis disque) si leur validateur correspond
+func (c *CachingClient) lookup(key, validator string) ([]byte, bool) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		if entry.Validator == validator {
+			c.lru.MoveToFront(element)
+			c.mu.Unlock()
+			return entry.Data, true
+		}
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil, false
+	}
+	raw, err := os.ReadFile(c.diskPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if json.Unmarshal(raw, &entry) != nil || entry.Key != key || entry.Validator != validator {
+		return nil, false
+	}
+	c.remember(&entry)
+	return entry.Data, true
+}
+
+// store ajoute un objet au cache mémoire et au cache disque
+func (c *CachingClient) store(entry *cacheEntry) {
+	c.remember(entry)
+	if c.dir == "" {
+		return
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(c.diskPath(entry.Key), raw, 0600); err != nil {
+		utils.Debug("Metadata disk cache write failed for %s: %v", entry.Key, err)
+	}
+}
+
+// remember ajoute un objet au cache mémoire en évinçant le moins récemment utilisé
+func (c *CachingClient) remember(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.Key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[entry.Key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).Key)
+	}
+}
+
+// invalidate retire un objet du cache
+func (c *CachingClient) invalidate(key string) {
+	if !isCacheable(key) {
+		return
+	}
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	if c.dir != "" {
+		os.Remove(c.diskPath(key))
+	}
+}
+
+// diskPath retourne le fichier du cache disque d'un objet
+func (c *CachingClient) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
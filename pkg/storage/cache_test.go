@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// countingClient est un stockage en mémoire qui compte les téléchargements
+type countingClient struct {
+	objects   map[string][]byte
+	versions  map[string]int
+	downloads int
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{objects: make(map[string][]byte), versions: make(map[string]int)}
+}
+
+func (c *countingClient) Upload(key string, data []byte) error {
+	c.objects[key] = data
+	c.versions[key]++
+	return nil
+}
+
+func (c *countingClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return c.Upload(key, data)
+}
+
+func (c *countingClient) Download(key string) ([]byte, error) {
+	c.downloads++
+	data, ok := c.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (c *countingClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	data, err := c.Download(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(writer, bytes.NewReader(data))
+	return n, err
+}
+
+func (c *countingClient) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *countingClient) DeleteObject(key string) error {
+	delete(c.objects, key)
+	return nil
+}
+
+func (c *countingClient) ListObjects(prefix string) ([]ObjectInfo, error) {
+	return nil, nil
+}
+
+func (c *countingClient) Stat(key string) (ObjectInfo, error) {
+	data, ok := c.objects[key]
+	if !ok {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	return ObjectInfo{Key: key, Size: int64(len(data)), ETag: fmt.Sprintf("v%d", c.versions[key])}, nil
+}
+
+func (c *countingClient) TestConnectivity() error {
+	return nil
+}
+
+func (c *countingClient) ServerTime() (time.Time, error) {
+	return time.Now(), nil
+}
+
+func TestCachingClientValidatesETag(t *testing.T) {
+	backend := newCountingClient()
+	backend.Upload("indexes/a.json", []byte("v1"))
+	backend.Upload("data/a/key", []byte("donnees"))
+
+	for _, dir := range []string{"", t.TempDir()} {
+		backend.downloads = 0
+		cache := NewCachingClient(backend, dir, 2)
+
+		for i := 0; i < 3; i++ {
+			if data, err := cache.Download("indexes/a.json"); err != nil || string(data) != string(backend.objects["indexes/a.json"]) {
+				t.Fatalf("Contenu en cache incorrect: %q (%v)", data, err)
+			}
+		}
+		if backend.downloads != 1 {
+			t.Errorf("Un seul téléchargement attendu pour un index inchangé, obtenu %d", backend.downloads)
+		}
+
+		// Un objet réécrit par un autre client ne doit pas être servi depuis le cache
+		backend.Upload("indexes/a.json", []byte("v2"))
+		if data, _ := cache.Download("indexes/a.json"); string(data) != "v2" {
+			t.Errorf("Le cache a servi une version périmée: %q", data)
+		}
+
+		// Les données ne sont pas mises en cache
+		cache.Download("data/a/key")
+		cache.Download("data/a/key")
+		if backend.downloads != 4 {
+			t.Errorf("Les objets de données ne doivent pas être mis en cache (%d téléchargements)", backend.downloads)
+		}
+	}
+
+	// Le cache disque survit à une nouvelle instance
+	dir := t.TempDir()
+	NewCachingClient(backend, dir, 2).Download("indexes/a.json")
+	backend.downloads = 0
+	NewCachingClient(backend, dir, 2).Download("indexes/a.json")
+	if backend.downloads != 0 {
+		t.Errorf("Le cache disque devrait éviter le téléchargement (%d)", backend.downloads)
+	}
+}
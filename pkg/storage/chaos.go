@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// Variables d'environnement d'injection de pannes (tests de chaos, CI)
+const (
+	FaultUploadErrorRateEnv = "BCRDF_FAULT_UPLOAD_ERROR_RATE" // Probabilité qu'un envoi échoue (0-1)
+	FaultSlowEnv            = "BCRDF_FAULT_SLOW_MS"           // Délai ajouté à chaque requête, en millisecondes
+)
+
+// chaosFaultsFromEnv lit les pannes demandées par l'environnement (ok = false si aucune)
+func chaosFaultsFromEnv() (faults Faults, ok bool, err error) {
+	if raw := os.Getenv(FaultUploadErrorRateEnv); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return Faults{}, false, fmt.Errorf("%w: %s must be between 0 and 1, got %q", utils.ErrConfig, FaultUploadErrorRateEnv, raw)
+		}
+		faults.ErrorRate = rate
+	}
+	if raw := os.Getenv(FaultSlowEnv); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return Faults{}, false, fmt.Errorf("%w: %s must be a positive number of milliseconds, got %q", utils.ErrConfig, FaultSlowEnv, raw)
+		}
+		faults.Latency = time.Duration(ms) * time.Millisecond
+	}
+	return faults, faults.ErrorRate > 0 || faults.Latency > 0, nil
+}
+
+// chaosClient simule un réseau dégradé: latence sur toutes les requêtes, échecs sur les envois
+type chaosClient struct {
+	Client
+	faults Faults
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+// newChaosClient enveloppe client si l'environnement demande une injection de pannes
+func newChaosClient(client Client) (Client, error) {
+	faults, ok, err := chaosFaultsFromEnv()
+	if err != nil || !ok {
+		return client, err
+	}
+	utils.Warn("⚠️  Fault injection enabled (upload error rate %.2f, latency %v)", faults.ErrorRate, faults.Latency)
+	return &chaosClient{
+		Client: client,
+		faults: faults,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// delay applique la latence simulée
+func (c *chaosClient) delay() {
+	if c.faults.Latency > 0 {
+		time.Sleep(c.faults.Latency)
+	}
+}
+
+// delayContext applique la latence simulée, interrompue par l'annulation de ctx
+func (c *chaosClient) delayContext(ctx context.Context) error {
+	if c.faults.Latency > 0 {
+		select {
+		case <-time.After(c.faults.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// uploadFault applique la latence (interrompue par l'annulation de ctx) puis tire l'échec éventuel d'un envoi
+func (c *chaosClient) uploadFault(ctx context.Context, key string) error {
+	if err := c.delayContext(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.faults.fails(c.rng, "PUT", key)
+}
+
+func (c *chaosClient) Upload(key string, data []byte) error {
+	if err := c.uploadFault(context.Background(), key); err != nil {
+		return err
+	}
+	return c.Client.Upload(key, data)
+}
+
+func (c *chaosClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	if err := c.uploadFault(ctx, key); err != nil {
+		return err
+	}
+	return c.Client.UploadStream(ctx, key, reader, size)
+}
+
+func (c *chaosClient) Download(key string) ([]byte, error) {
+	c.delay()
+	return c.Client.Download(key)
+}
+
+func (c *chaosClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	if err := c.delayContext(ctx); err != nil {
+		return 0, err
+	}
+	return c.Client.DownloadStream(ctx, key, writer)
+}
+
+func (c *chaosClient) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	c.delay()
+	return c.Client.DownloadRange(key, offset, length)
+}
+
+func (c *chaosClient) DeleteObject(key string) error {
+	c.delay()
+	return c.Client.DeleteObject(key)
+}
+
+func (c *chaosClient) ListObjects(prefix string) ([]ObjectInfo, error) {
+	c.delay()
+	return c.Client.ListObjects(prefix)
+}
+
+func (c *chaosClient) Stat(key string) (ObjectInfo, error) {
+	c.delay()
+	return c.Client.Stat(key)
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChaosClientFromEnv(t *testing.T) {
+	t.Setenv(FaultUploadErrorRateEnv, "1")
+	t.Setenv(FaultSlowEnv, "5")
+
+	store := NewMemoryClient()
+	client, err := newChaosClient(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := client.Upload("data/a", []byte("a")); !errors.Is(err, ErrInjectedFault) {
+		t.Fatalf("échec d'envoi attendu, obtenu: %v", err)
+	}
+	if time.Since(start) < 5*time.Millisecond {
+		t.Error("latence simulée non appliquée")
+	}
+	if _, err := store.Stat("data/a"); !errors.Is(err, ErrObjectNotFound) {
+		t.Error("un envoi en échec ne doit rien écrire")
+	}
+
+	// Les lectures ne sont que ralenties
+	if err := store.Upload("data/b", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Download("data/b"); err != nil {
+		t.Errorf("lecture inattendue en échec: %v", err)
+	}
+}
+
+func TestChaosClientInvalidEnv(t *testing.T) {
+	store := NewMemoryClient()
+	if client, err := newChaosClient(store); err != nil || client != Client(store) {
+		t.Fatalf("aucune injection attendue sans variables: %v", err)
+	}
+
+	t.Setenv(FaultUploadErrorRateEnv, "1.5")
+	if _, err := newChaosClient(store); err == nil {
+		t.Error("taux hors limites accepté")
+	}
+	t.Setenv(FaultUploadErrorRateEnv, "")
+	t.Setenv(FaultSlowEnv, "-1")
+	if _, err := newChaosClient(store); err == nil {
+		t.Error("latence négative acceptée")
+	}
+}
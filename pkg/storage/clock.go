@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// DefaultMaxClockSkew est l'écart toléré avec l'horloge du stockage (backup.max_clock_skew = 0)
+const DefaultMaxClockSkew = 60 * time.Second
+
+// MaxClockSkew retourne l'écart toléré avec l'horloge du stockage
+func MaxClockSkew(config *utils.Config) time.Duration {
+	if config != nil && config.Backup.MaxClockSkew > 0 {
+		return time.Duration(config.Backup.MaxClockSkew) * time.Second
+	}
+	return DefaultMaxClockSkew
+}
+
+// ClockSkew mesure l'avance de l'horloge locale sur celle du stockage (négative en cas de retard).
+// L'en-tête Date est à la seconde: la mesure est précise à une seconde près, plus le temps de réponse.
+func ClockSkew(client Client) (time.Duration, error) {
+	start := time.Now()
+	serverTime, err := client.ServerTime()
+	if err != nil {
+		return 0, err
+	}
+	local := start.Add(time.Since(start) / 2)
+	if serverTime.Nanosecond() == 0 {
+		// Heure tronquée à la seconde: milieu de la seconde annoncée
+		serverTime = serverTime.Add(500 * time.Millisecond)
+	}
+	return local.Sub(serverTime), nil
+}
+
+// CheckClockSkew mesure l'écart avec l'horloge du stockage et avertit au-delà de backup.max_clock_skew:
+// un écart fausse l'âge des sauvegardes (rétention, gc) et la date des IDs de sauvegarde.
+// Retourne la correction à ajouter à l'heure locale pour obtenir celle du stockage, 0 si l'écart est
+// toléré ou ne peut pas être mesuré.
+func CheckClockSkew(config *utils.Config, client Client) time.Duration {
+	skew, err := ClockSkew(client)
+	if err != nil {
+		utils.Debug("Clock skew not measured: %v", err)
+		return 0
+	}
+	maxSkew := MaxClockSkew(config)
+	if skew <= maxSkew && skew >= -maxSkew {
+		utils.Debug("Clock skew with storage: %v", skew.Round(time.Millisecond))
+		return 0
+	}
+	utils.Warn("Local clock is %s the storage server (max_clock_skew %v): backup IDs and age-based retention are affected, check NTP",
+		DescribeClockSkew(skew), maxSkew)
+	return -skew
+}
+
+// DescribeClockSkew décrit un écart d'horloge ("2m30s ahead of", "5s behind")
+func DescribeClockSkew(skew time.Duration) string {
+	if skew < 0 {
+		return (-skew).Round(time.Second).String() + " behind"
+	}
+	return skew.Round(time.Second).String() + " ahead of"
+}
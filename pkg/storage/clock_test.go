@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	store := NewMemoryClient()
+	config := &utils.Config{}
+
+	// Écart toléré: aucune correction
+	store.SetFaults(Faults{ClockSkew: 10 * time.Second})
+	if correction := CheckClockSkew(config, store); correction != 0 {
+		t.Errorf("aucune correction attendue sous max_clock_skew, obtenu %v", correction)
+	}
+
+	// Stockage en avance de 2h: la correction ramène l'heure locale à celle du stockage
+	store.SetFaults(Faults{ClockSkew: 2 * time.Hour})
+	correction := CheckClockSkew(config, store)
+	if diff := correction - 2*time.Hour; diff < -time.Second || diff > time.Second {
+		t.Errorf("correction de 2h attendue, obtenu %v", correction)
+	}
+
+	// Seuil configuré plus large
+	config.Backup.MaxClockSkew = 3 * 3600
+	if correction := CheckClockSkew(config, store); correction != 0 {
+		t.Errorf("écart toléré par max_clock_skew, obtenu %v", correction)
+	}
+}
+
+func TestDescribeClockSkew(t *testing.T) {
+	if got := DescribeClockSkew(90 * time.Second); got != "1m30s ahead of" {
+		t.Errorf("description inattendue: %s", got)
+	}
+	if got := DescribeClockSkew(-5 * time.Second); got != "5s behind" {
+		t.Errorf("description inattendue: %s", got)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+)
+
+// RequestCounts compte les requêtes envoyées au stockage, par type de facturation
+type RequestCounts struct {
+	Put    int64 `json:"put"`
+	Get    int64 `json:"get"`
+	List   int64 `json:"list"`
+	Head   int64 `json:"head"`
+	Delete int64 `json:"delete"`
+}
+
+// Total retourne le nombre total de requêtes
+func (r RequestCounts) Total() int64 {
+	return r.Put + r.Get + r.List + r.Head + r.Delete
+}
+
+// Sub retourne les requêtes envoyées depuis un relevé précédent
+func (r RequestCounts) Sub(previous RequestCounts) RequestCounts {
+	return RequestCounts{
+		Put:    r.Put - previous.Put,
+		Get:    r.Get - previous.Get,
+		List:   r.List - previous.List,
+		Head:   r.Head - previous.Head,
+		Delete: r.Delete - previous.Delete,
+	}
+}
+
+// Compteurs du processus, partagés par tous les clients créés par NewStorageClient
+var requestPut, requestGet, requestList, requestHead, requestDelete atomic.Int64
+
+// Requests retourne un relevé des requêtes envoyées par le processus
+// Une requête réessayée compte à chaque envoi; un envoi multipart ou une liste paginée comptent pour un
+func Requests() RequestCounts {
+	return RequestCounts{
+		Put:    requestPut.Load(),
+		Get:    requestGet.Load(),
+		List:   requestList.Load(),
+		Head:   requestHead.Load(),
+		Delete: requestDelete.Load(),
+	}
+}
+
+// requestCounter compte les requêtes réellement envoyées (placé sous le cache de métadonnées)
+type requestCounter struct {
+	Client
+}
+
+func (c *requestCounter) Upload(key string, data []byte) error {
+	requestPut.Add(1)
+	return c.Client.Upload(key, data)
+}
+
+func (c *requestCounter) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	requestPut.Add(1)
+	return c.Client.UploadStream(ctx, key, reader, size)
+}
+
+func (c *requestCounter) Download(key string) ([]byte, error) {
+	requestGet.Add(1)
+	return c.Client.Download(key)
+}
+
+func (c *requestCounter) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	requestGet.Add(1)
+	return c.Client.DownloadStream(ctx, key, writer)
+}
+
+func (c *requestCounter) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	requestGet.Add(1)
+	return c.Client.DownloadRange(key, offset, length)
+}
+
+func (c *requestCounter) DeleteObject(key string) error {
+	requestDelete.Add(1)
+	return c.Client.DeleteObject(key)
+}
+
+func (c *requestCounter) ListObjects(prefix string) ([]ObjectInfo, error) {
+	requestList.Add(1)
+	return c.Client.ListObjects(prefix)
+}
+
+func (c *requestCounter) Stat(key string) (ObjectInfo, error) {
+	requestHead.Add(1)
+	return c.Client.Stat(key)
+}
@@ -3,32 +3,52 @@ package storage
 import (
 	"fmt"
 
+	"bcrdf/pkg/s3"
 	"bcrdf/pkg/utils"
 )
 
 // NewStorageClient crée un client de stockage basé sur la configuration
 func NewStorageClient(config *utils.Config) (Client, error) {
-	switch config.Storage.Type {
-	case "s3":
-		// Vérifier si une classe de stockage est configurée
-		if config.Storage.StorageClass != "" {
-			return NewS3AdapterWithStorageClass(
-				config.Storage.AccessKey,
-				config.Storage.SecretKey,
-				config.Storage.Region,
-				config.Storage.Endpoint,
-				config.Storage.Bucket,
-				config.Storage.StorageClass,
-			)
+	client, err := newStorageClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrStorageUnreachable, err)
+	}
+	if client, err = withLayout(config, client); err != nil {
+		return nil, err
+	}
+	if client, err = newChaosClient(client); err != nil {
+		return nil, err
+	}
+	if config.Backup.AppendOnly {
+		client = &appendOnlyClient{Client: client}
+	} else if config.Storage.Destructive.IsSet() {
+		deleter, err := newDestructiveClient(config)
+		if err != nil {
+			return nil, fmt.Errorf("%w: destructive credentials: %w", utils.ErrStorageUnreachable, err)
 		}
+		if deleter, err = withLayout(config, deleter); err != nil {
+			return nil, err
+		}
+		client = &destructiveRouter{Client: client, deleter: deleter}
+	}
+	client = &requestCounter{Client: client}
+	if config.Backup.RestoreCacheDir != "" {
+		maxSize, _ := utils.ParseSize(config.Backup.RestoreCacheMaxSize)
+		client = NewRestoreCacheClient(client, config.Backup.RestoreCacheDir, maxSize)
+	}
+	if config.Backup.MetadataCache || config.Backup.MetadataCacheDir != "" {
+		return NewCachingClient(client, config.Backup.MetadataCacheDir, DefaultMetadataCacheEntries), nil
+	}
+	return client, nil
+}
 
-		return NewS3Adapter(
-			config.Storage.AccessKey,
-			config.Storage.SecretKey,
-			config.Storage.Region,
-			config.Storage.Endpoint,
-			config.Storage.Bucket,
-		)
+// newStorageClient instancie l'adaptateur correspondant au type de stockage
+func newStorageClient(config *utils.Config) (Client, error) {
+	switch config.Storage.Type {
+	case "s3":
+		// La classe de stockage vide n'est pas envoyée (fournisseurs sans classes de stockage)
+		return NewS3AdapterWithOptions(s3Options(config, config.Storage.AccessKey, config.Storage.SecretKey, ""),
+			config.Storage.StorageClass)
 
 	case "webdav":
 		return NewWebDAVAdapter(
@@ -37,7 +57,68 @@ func NewStorageClient(config *utils.Config) (Client, error) {
 			config.Storage.Password,
 		)
 
+	case "memory":
+		// Stockage en mémoire du processus (tests de bout en bout), partagé par nom de bucket
+		return MemoryStore(config.Storage.Bucket), nil
+
 	default:
-		return nil, fmt.Errorf("unsupported storage type: %s", config.Storage.Type)
+		return nil, fmt.Errorf("%w: unsupported storage type: %s", utils.ErrConfig, config.Storage.Type)
+	}
+}
+
+// newDestructiveClient instancie le client utilisé uniquement pour les suppressions
+func newDestructiveClient(config *utils.Config) (Client, error) {
+	credentials := config.Storage.Destructive
+	switch config.Storage.Type {
+	case "s3":
+		accessKey, secretKey := credentials.AccessKey, credentials.SecretKey
+		if accessKey == "" {
+			// Rôle STS assumé à partir des identifiants habituels
+			accessKey, secretKey = config.Storage.AccessKey, config.Storage.SecretKey
+		}
+		return NewS3AdapterWithOptions(s3Options(config, accessKey, secretKey, credentials.RoleARN), "")
+
+	case "webdav":
+		return NewWebDAVAdapter(config.Storage.Endpoint, credentials.Username, credentials.Password)
+
+	case "memory":
+		return MemoryStore(config.Storage.Bucket), nil
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported storage type: %s", utils.ErrConfig, config.Storage.Type)
+	}
+}
+
+// destructiveRouter envoie les suppressions vers le client aux identifiants destructifs
+type destructiveRouter struct {
+	Client
+	deleter Client
+}
+
+// DeleteObject supprime un objet avec les identifiants destructifs
+func (r *destructiveRouter) DeleteObject(key string) error {
+	return r.deleter.DeleteObject(key)
+}
+
+// appendOnlyClient refuse toute suppression (backup.append_only), quel que soit le chemin appelant
+type appendOnlyClient struct {
+	Client
+}
+
+// DeleteObject refuse la suppression
+func (c *appendOnlyClient) DeleteObject(key string) error {
+	return fmt.Errorf("%w: refusing to delete %s", utils.ErrAppendOnly, key)
+}
+
+// s3Options construit les options de connexion S3 de la configuration avec les identifiants donnés
+func s3Options(config *utils.Config, accessKey, secretKey, roleARN string) s3.Options {
+	return s3.Options{
+		AccessKey:     accessKey,
+		SecretKey:     secretKey,
+		Region:        config.Storage.Region,
+		Endpoint:      config.Storage.Endpoint,
+		Bucket:        config.Storage.Bucket,
+		RoleARN:       roleARN,
+		VirtualHosted: config.Storage.AddressingStyle == "virtual",
 	}
 }
@@ -1,14 +1,21 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"io"
 	"time"
 )
 
+// ErrObjectNotFound est retournée par Stat lorsque l'objet n'existe pas
+var ErrObjectNotFound = errors.New("object not found")
+
 // ObjectInfo représente les informations d'un objet de stockage
 type ObjectInfo struct {
 	Key          string
 	Size         int64
 	LastModified time.Time
+	ETag         string // Fourni par Stat si le backend le permet
 }
 
 // Client représente une interface commune pour les clients de stockage
@@ -16,17 +23,34 @@ type Client interface {
 	// Upload télécharge des données vers le stockage
 	Upload(key string, data []byte) error
 
+	// UploadStream envoie un flux sans le charger en mémoire (size = taille attendue, -1 si inconnue)
+	// L'annulation de ctx interrompt le transfert en cours
+	UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error
+
 	// Download télécharge des données depuis le stockage
 	Download(key string) ([]byte, error)
 
+	// DownloadStream écrit un objet dans writer et retourne le nombre d'octets écrits
+	// L'annulation de ctx interrompt le transfert en cours
+	DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error)
+
+	// DownloadRange télécharge length octets d'un objet à partir de offset (length <= 0 = jusqu'à la fin)
+	DownloadRange(key string, offset, length int64) ([]byte, error)
+
 	// DeleteObject supprime un objet du stockage
 	DeleteObject(key string) error
 
 	// ListObjects liste les objets avec un préfixe donné
 	ListObjects(prefix string) ([]ObjectInfo, error)
 
+	// Stat retourne la taille et la date d'un objet sans le télécharger (ErrObjectNotFound s'il n'existe pas)
+	Stat(key string) (ObjectInfo, error)
+
 	// TestConnectivity teste la connectivité au stockage
 	TestConnectivity() error
+
+	// ServerTime retourne l'heure du serveur de stockage (en-tête Date de sa réponse)
+	ServerTime() (time.Time, error)
 }
 
 // StorageType représente le type de stockage
@@ -35,4 +59,32 @@ type StorageType string
 const (
 	S3Storage     StorageType = "s3"
 	WebDAVStorage StorageType = "webdav"
+	MemoryStorage StorageType = "memory" // Tests uniquement
 )
+
+// ObjectExists vérifie l'existence d'un objet sans le télécharger
+func ObjectExists(client Client, key string) (bool, error) {
+	_, err := client.Stat(key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CopyObject copie un objet en flux, sans le charger entièrement en mémoire
+func CopyObject(client Client, srcKey, dstKey string) error {
+	size := int64(-1)
+	if info, err := client.Stat(srcKey); err == nil {
+		size = info.Size
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		_, err := client.DownloadStream(context.Background(), srcKey, writer)
+		writer.CloseWithError(err)
+	}()
+
+	err := client.UploadStream(context.Background(), dstKey, reader, size)
+	reader.CloseWithError(err)
+	return err
+}
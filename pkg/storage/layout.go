@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// Disposition du dépôt dans le bucket. Le code manipule des clés logiques (indexes/<id>.json,
+// data/<id>/..., holds/...); layoutClient les place sous storage.prefix, avec les index sous
+// storage.index_prefix et les données sous storage.data_prefix. Le dépôt peut ainsi partager
+// un bucket avec d'autres données, et la disposition change sans modifier le code.
+const (
+	IndexesPrefix = "indexes/" // Préfixe logique des index
+	DataPrefix    = "data/"    // Préfixe logique des données
+)
+
+// reservedPrefixes sont les autres préfixes logiques du dépôt, que les préfixes configurés ne
+// doivent pas masquer
+var reservedPrefixes = []string{"index-bases/", "dictionaries/", "holds/", "audit/", "reports/", "parity/",
+	"migrations/", "bench/", "selftest/", MarkerKey}
+
+// Layout est la disposition des objets du dépôt
+type Layout struct {
+	Prefix      string // Racine du dépôt dans le bucket, vide = racine du bucket
+	IndexPrefix string // Préfixe des index sous la racine
+	DataPrefix  string // Préfixe des données sous la racine
+
+	names *nameCipher // storage.opaque_keys: noms chiffrés sous les préfixes, nil = en clair
+}
+
+// LayoutFromConfig retourne la disposition configurée (storage.prefix, index_prefix, data_prefix)
+func LayoutFromConfig(config *utils.Config) (Layout, error) {
+	layout := Layout{
+		Prefix:      normalizePrefix(config.Storage.Prefix),
+		IndexPrefix: normalizePrefix(config.Storage.IndexPrefix),
+		DataPrefix:  normalizePrefix(config.Storage.DataPrefix),
+	}
+	if layout.IndexPrefix == "" {
+		layout.IndexPrefix = IndexesPrefix
+	}
+	if layout.DataPrefix == "" {
+		layout.DataPrefix = DataPrefix
+	}
+	if strings.HasPrefix(layout.IndexPrefix, layout.DataPrefix) || strings.HasPrefix(layout.DataPrefix, layout.IndexPrefix) {
+		return Layout{}, fmt.Errorf("%w: index_prefix %q and data_prefix %q overlap", utils.ErrConfig, layout.IndexPrefix, layout.DataPrefix)
+	}
+	for _, prefix := range []string{layout.IndexPrefix, layout.DataPrefix} {
+		for _, reserved := range reservedPrefixes {
+			if strings.HasPrefix(prefix, reserved) || strings.HasPrefix(reserved, prefix) {
+				return Layout{}, fmt.Errorf("%w: prefix %q overlaps the reserved %q", utils.ErrConfig, prefix, reserved)
+			}
+		}
+	}
+	names, err := nameCipherFromConfig(config)
+	if err != nil {
+		return Layout{}, err
+	}
+	layout.names = names
+	return layout, nil
+}
+
+// normalizePrefix retire le '/' initial et ajoute le '/' final d'un préfixe non vide
+func normalizePrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// physical traduit une clé (ou un préfixe) logique en clé du bucket. Avec des noms opaques, un
+// préfixe doit se terminer par '/' (voir listPrefix).
+func (l Layout) physical(key string) string {
+	top, rest := splitTop(key)
+	switch top {
+	case IndexesPrefix:
+		top = l.IndexPrefix
+	case DataPrefix:
+		top = l.DataPrefix
+	}
+	return l.Prefix + top + l.names.encode(rest)
+}
+
+// listPrefix retourne le préfixe logique à lister pour un préfixe demandé: avec des noms opaques,
+// un segment incomplet ne se chiffre pas, on liste son répertoire parent puis on filtre
+func (l Layout) listPrefix(prefix string) string {
+	if l.names == nil {
+		return prefix
+	}
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+// logical traduit une clé du bucket en clé logique (ok = false hors du dépôt)
+func (l Layout) logical(key string) (string, bool) {
+	if !strings.HasPrefix(key, l.Prefix) {
+		return "", false
+	}
+	key = strings.TrimPrefix(key, l.Prefix)
+	var top, rest string
+	switch {
+	case strings.HasPrefix(key, l.IndexPrefix):
+		top, rest = IndexesPrefix, strings.TrimPrefix(key, l.IndexPrefix)
+	case strings.HasPrefix(key, l.DataPrefix):
+		top, rest = DataPrefix, strings.TrimPrefix(key, l.DataPrefix)
+	case strings.HasPrefix(key, IndexesPrefix) || strings.HasPrefix(key, DataPrefix):
+		return "", false // Préfixes par défaut d'une autre disposition, hors du dépôt
+	default:
+		top, rest = splitTop(key)
+	}
+	rest, ok := l.names.decode(rest)
+	if !ok {
+		return "", false // Nom non chiffré par ce dépôt
+	}
+	return top + rest, true
+}
+
+// splitTop sépare le préfixe de premier niveau (avec son '/') du reste de la clé; une clé sans
+// '/' (le marqueur) n'a pas de préfixe
+func splitTop(key string) (string, string) {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i+1], key[i+1:]
+}
+
+// layoutClient place les clés logiques selon la disposition et vérifie le marqueur du dépôt
+// avant la première requête
+type layoutClient struct {
+	Client
+	layout   Layout
+	expected *RepositoryMarker // Marqueur correspondant à la configuration
+	state    *markerState
+}
+
+// withLayout applique la disposition configurée à un client de stockage
+func withLayout(config *utils.Config, client Client) (*layoutClient, error) {
+	layout, err := LayoutFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	repository := strings.Join([]string{config.Storage.Type, config.Storage.Endpoint, config.Storage.Bucket, layout.Prefix}, "|")
+	state, _ := markerStates.LoadOrStore(repository, &markerState{})
+	return &layoutClient{Client: client, layout: layout, expected: markerFromConfig(config, layout), state: state.(*markerState)}, nil
+}
+
+// Upload implémente l'interface Client
+func (c *layoutClient) Upload(key string, data []byte) error {
+	if err := c.checkMarker(true); err != nil {
+		return err
+	}
+	return c.Client.Upload(c.layout.physical(key), data)
+}
+
+// UploadStream implémente l'interface Client
+func (c *layoutClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	if err := c.checkMarker(true); err != nil {
+		return err
+	}
+	return c.Client.UploadStream(ctx, c.layout.physical(key), reader, size)
+}
+
+// Download implémente l'interface Client
+func (c *layoutClient) Download(key string) ([]byte, error) {
+	if err := c.checkMarker(false); err != nil {
+		return nil, err
+	}
+	return c.Client.Download(c.layout.physical(key))
+}
+
+// DownloadStream implémente l'interface Client
+func (c *layoutClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	if err := c.checkMarker(false); err != nil {
+		return 0, err
+	}
+	return c.Client.DownloadStream(ctx, c.layout.physical(key), writer)
+}
+
+// DownloadRange implémente l'interface Client
+func (c *layoutClient) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	if err := c.checkMarker(false); err != nil {
+		return nil, err
+	}
+	return c.Client.DownloadRange(c.layout.physical(key), offset, length)
+}
+
+// DeleteObject implémente l'interface Client
+func (c *layoutClient) DeleteObject(key string) error {
+	if err := c.checkMarker(false); err != nil {
+		return err
+	}
+	return c.Client.DeleteObject(c.layout.physical(key))
+}
+
+// ListObjects implémente l'interface Client: les clés retournées sont logiques, les objets
+// hors du dépôt (autres données du bucket) sont ignorés
+func (c *layoutClient) ListObjects(prefix string) ([]ObjectInfo, error) {
+	if err := c.checkMarker(false); err != nil {
+		return nil, err
+	}
+	objects, err := c.Client.ListObjects(c.layout.physical(c.layout.listPrefix(prefix)))
+	if err != nil {
+		return nil, err
+	}
+	result := objects[:0]
+	for _, object := range objects {
+		key, ok := c.layout.logical(object.Key)
+		if !ok || key == MarkerKey || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		object.Key = key
+		result = append(result, object)
+	}
+	return result, nil
+}
+
+// Stat implémente l'interface Client
+func (c *layoutClient) Stat(key string) (ObjectInfo, error) {
+	if err := c.checkMarker(false); err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := c.Client.Stat(c.layout.physical(key))
+	info.Key = key
+	return info, err
+}
+
+// layoutPresigner signe les URLs des clés logiques selon la disposition
+type layoutPresigner struct {
+	presigner Presigner
+	layout    Layout
+}
+
+// PresignGet implémente l'interface Presigner
+func (p *layoutPresigner) PresignGet(key string, expires time.Duration) (string, error) {
+	return p.presigner.PresignGet(p.layout.physical(key), expires)
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func layoutConfig(bucket, prefix, indexPrefix, dataPrefix string) *utils.Config {
+	config := &utils.Config{}
+	config.Storage.Type = "memory"
+	config.Storage.Bucket = bucket
+	config.Storage.Prefix = prefix
+	config.Storage.IndexPrefix = indexPrefix
+	config.Storage.DataPrefix = dataPrefix
+	return config
+}
+
+func TestLayoutClientMapsKeys(t *testing.T) {
+	bucket := t.Name()
+	store := MemoryStore(bucket)
+	// Données étrangères au dépôt dans le même bucket
+	store.Upload("photos/a.jpg", []byte("x"))
+
+	client, err := NewStorageClient(layoutConfig(bucket, "/backups/bcrdf", "idx", "blobs/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload("indexes/b1.json", []byte("index")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload("data/b1/k1", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	client.Upload("holds/h.json", []byte("hold"))
+
+	for _, key := range []string{"backups/bcrdf/idx/b1.json", "backups/bcrdf/blobs/b1/k1", "backups/bcrdf/holds/h.json", "backups/bcrdf/" + MarkerKey} {
+		if _, err := store.Stat(key); err != nil {
+			t.Errorf("objet %s attendu dans le bucket: %v", key, err)
+		}
+	}
+
+	// Les listes retournent les clés logiques, sans le marqueur ni les données étrangères
+	objects, err := client.ListObjects("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]bool)
+	for _, object := range objects {
+		keys[object.Key] = true
+	}
+	if len(keys) != 3 || !keys["indexes/b1.json"] || !keys["data/b1/k1"] || !keys["holds/h.json"] {
+		t.Errorf("clés logiques inattendues: %v", keys)
+	}
+	if data, err := client.Download("data/b1/k1"); err != nil || string(data) != "data" {
+		t.Errorf("lecture par clé logique: %q %v", data, err)
+	}
+
+	// Le marqueur enregistre la disposition
+	raw, _ := store.Download("backups/bcrdf/" + MarkerKey)
+	var marker RepositoryMarker
+	if err := json.Unmarshal(raw, &marker); err != nil || marker.IndexPrefix != "idx/" || marker.DataPrefix != "blobs/" {
+		t.Errorf("marqueur inattendu: %s", raw)
+	}
+}
+
+func TestLayoutMismatchIsRejected(t *testing.T) {
+	bucket := t.Name()
+	client, err := NewStorageClient(layoutConfig(bucket, "", "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload("indexes/b1.json", []byte("index")); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewStorageClient(layoutConfig(bucket, "", "meta/", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ListObjects("indexes/"); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("erreur de disposition attendue, obtenu: %v", err)
+	}
+}
+
+func TestLayoutFromConfigRejectsOverlaps(t *testing.T) {
+	for _, prefixes := range [][2]string{{"data/", "data/"}, {"meta/", "meta/blobs/"}, {"holds/", ""}} {
+		if _, err := LayoutFromConfig(layoutConfig("b", "", prefixes[0], prefixes[1])); !errors.Is(err, utils.ErrConfig) {
+			t.Errorf("préfixes %v acceptés", prefixes)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// Marqueur du dépôt: écrit en clair à la première écriture, il enregistre ce qu'il faut pour lire
+// le dépôt (disposition, algorithme et empreinte de la clé de chiffrement, découpage en chunks).
+// Chaque exécution le compare à sa configuration, pour qu'une clé ou une disposition erronée
+// produise une erreur explicite plutôt que des échecs de déchiffrement.
+const (
+	MarkerKey    = "bcrdf-repository.json" // À la racine du dépôt (storage.prefix)
+	markerFormat = 1                       // Version du format du dépôt
+)
+
+// RepositoryMarker est l'objet marqueur du dépôt
+type RepositoryMarker struct {
+	Format             int       `json:"format"`
+	IndexPrefix        string    `json:"index_prefix"`
+	DataPrefix         string    `json:"data_prefix"`
+	OpaqueKeys         bool      `json:"opaque_keys,omitempty"`
+	EncryptionAlgo     string    `json:"encryption_algo,omitempty"`
+	KeyFingerprint     string    `json:"key_fingerprint,omitempty"` // HMAC-SHA256 tronqué: ne permet pas de retrouver la clé
+	ChunkSize          string    `json:"chunk_size,omitempty"`
+	ChunkSizeLarge     string    `json:"chunk_size_large,omitempty"`
+	LargeFileThreshold string    `json:"large_file_threshold,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	CreatedBy          string    `json:"created_by,omitempty"`
+
+	allowUnencrypted bool // Configuration: un dépôt sans chiffrement peut passer au chiffrement
+}
+
+// markerFromConfig construit le marqueur correspondant à la configuration
+func markerFromConfig(config *utils.Config, layout Layout) *RepositoryMarker {
+	algorithm := config.Backup.EncryptionAlgo
+	if algorithm == "" {
+		algorithm = "aes-256-gcm"
+	}
+	marker := &RepositoryMarker{
+		Format:             markerFormat,
+		IndexPrefix:        layout.IndexPrefix,
+		DataPrefix:         layout.DataPrefix,
+		OpaqueKeys:         layout.names != nil,
+		EncryptionAlgo:     algorithm,
+		ChunkSize:          config.Backup.ChunkSize,
+		ChunkSizeLarge:     config.Backup.ChunkSizeLarge,
+		LargeFileThreshold: config.Backup.LargeFileThreshold,
+		allowUnencrypted:   config.Backup.AllowUnencrypted,
+	}
+	if algorithm != "none" && config.Backup.EncryptionKey != "" {
+		marker.KeyFingerprint = KeyFingerprint(config.Backup.EncryptionKey)
+	}
+	return marker
+}
+
+// KeyFingerprint retourne l'empreinte d'une clé de chiffrement
+func KeyFingerprint(key string) string {
+	mac := hmac.New(sha256.New, encryptionKeyBytes(key))
+	mac.Write([]byte("bcrdf repository marker"))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// encryptionKeyBytes décode une clé de chiffrement comme le chiffrement: 64 caractères
+// hexadécimaux, sinon octets bruts
+func encryptionKeyBytes(key string) []byte {
+	if len(key) == 64 {
+		if decoded, err := hex.DecodeString(key); err == nil {
+			return decoded
+		}
+	}
+	return []byte(key)
+}
+
+// Compare retourne les différences de découpage entre le marqueur du dépôt et celui de la
+// configuration (sans effet sur la lecture), ou l'erreur décrivant la première incompatibilité
+func (m *RepositoryMarker) Compare(expected *RepositoryMarker) ([]string, error) {
+	if m.IndexPrefix != expected.IndexPrefix || m.DataPrefix != expected.DataPrefix {
+		return nil, fmt.Errorf("%w: repository layout mismatch: the repository uses index_prefix %q and data_prefix %q, the configuration %q and %q",
+			utils.ErrConfig, m.IndexPrefix, m.DataPrefix, expected.IndexPrefix, expected.DataPrefix)
+	}
+	if m.OpaqueKeys != expected.OpaqueKeys {
+		return nil, fmt.Errorf("%w: opaque_keys mismatch: the repository was created with storage.opaque_keys %t, the configuration has %t",
+			utils.ErrConfig, m.OpaqueKeys, expected.OpaqueKeys)
+	}
+	// Un dépôt sans chiffrement peut être chiffré par la suite (allow_unencrypted relit l'existant)
+	migrating := m.EncryptionAlgo == "none" && expected.allowUnencrypted
+	if m.EncryptionAlgo != "" && m.EncryptionAlgo != expected.EncryptionAlgo && !migrating {
+		return nil, fmt.Errorf("%w: encryption_algo mismatch: the repository uses %s, the configuration %s",
+			utils.ErrConfig, m.EncryptionAlgo, expected.EncryptionAlgo)
+	}
+	if m.KeyFingerprint != "" && expected.KeyFingerprint != "" && m.KeyFingerprint != expected.KeyFingerprint {
+		return nil, fmt.Errorf("%w: encryption key mismatch: the repository was created with key fingerprint %s, the configured key has %s (check backup.encryption_key or BCRDF_ENCRYPTION_KEY)",
+			utils.ErrConfig, m.KeyFingerprint, expected.KeyFingerprint)
+	}
+
+	var differences []string
+	for _, setting := range []struct{ name, recorded, configured string }{
+		{"chunk_size", m.ChunkSize, expected.ChunkSize},
+		{"chunk_size_large", m.ChunkSizeLarge, expected.ChunkSizeLarge},
+		{"large_file_threshold", m.LargeFileThreshold, expected.LargeFileThreshold},
+	} {
+		if setting.recorded != "" && setting.recorded != setting.configured {
+			differences = append(differences, fmt.Sprintf("%s %s (repository %s)", setting.name, valueOrDefault(setting.configured), setting.recorded))
+		}
+	}
+	return differences, nil
+}
+
+func valueOrDefault(value string) string {
+	if value == "" {
+		return "default"
+	}
+	return value
+}
+
+// markerState est l'état du marqueur d'un dépôt, partagé par les clients du processus
+type markerState struct {
+	mu      sync.Mutex
+	checked bool
+	marker  *RepositoryMarker // nil = pas de marqueur
+	noticed bool              // Différences de découpage déjà signalées
+}
+
+// markerStates mémorise par dépôt le marqueur lu ou écrit, pour une seule requête par processus
+var markerStates sync.Map
+
+// checkMarker lit le marqueur du dépôt (une fois par processus) et le compare à la configuration;
+// avant une écriture, un dépôt sans marqueur reçoit celui de la configuration
+func (c *layoutClient) checkMarker(write bool) error {
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.checked {
+		marker, err := c.readMarker()
+		if err != nil {
+			return err
+		}
+		s.marker, s.checked = marker, true
+	}
+	if s.marker == nil {
+		if !write {
+			return nil // Dépôt vide ou antérieur au marqueur: rien à comparer
+		}
+		if c.layout.names != nil {
+			// Les objets existants, en clair, deviendraient invisibles
+			existing, err := c.Client.ListObjects(c.layout.Prefix + c.layout.IndexPrefix)
+			if err != nil {
+				return fmt.Errorf("error reading repository marker: %w", err)
+			}
+			if len(existing) > 0 {
+				return fmt.Errorf("%w: storage.opaque_keys cannot be enabled on an existing repository, use a new bucket or storage.prefix", utils.ErrConfig)
+			}
+		}
+		marker, err := c.writeMarker()
+		if err != nil {
+			return err
+		}
+		s.marker = marker
+	}
+
+	differences, err := s.marker.Compare(c.expected)
+	if err != nil {
+		return fmt.Errorf("%w (%s)", err, c.layout.Prefix+MarkerKey)
+	}
+	if len(differences) > 0 && !s.noticed {
+		s.noticed = true
+		utils.Info("Chunk settings differ from the repository's: %s; existing backups stay readable", strings.Join(differences, ", "))
+	}
+	return nil
+}
+
+// readMarker lit le marqueur du dépôt (nil s'il n'existe pas)
+func (c *layoutClient) readMarker() (*RepositoryMarker, error) {
+	return readMarker(c.Client, c.layout.Prefix+MarkerKey)
+}
+
+// readMarker lit le marqueur d'un dépôt (nil s'il n'existe pas). Un marqueur illisible, laissé
+// par une écriture interrompue, est traité comme absent et sera réécrit.
+func readMarker(client Client, key string) (*RepositoryMarker, error) {
+	if _, err := client.Stat(key); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading repository marker: %w", err)
+	}
+	data, err := client.Download(key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading repository marker: %w", err)
+	}
+	var marker RepositoryMarker
+	if err := json.Unmarshal(data, &marker); err != nil || marker.Format == 0 {
+		utils.Warn("Repository marker %s is unreadable, it will be rewritten", key)
+		return nil, nil
+	}
+	if marker.Format > markerFormat {
+		return nil, fmt.Errorf("%w: repository format %d is newer than this version supports (%d), update bcrdf",
+			utils.ErrConfig, marker.Format, markerFormat)
+	}
+	return &marker, nil
+}
+
+// writeMarker enregistre le marqueur de la configuration dans le dépôt
+func (c *layoutClient) writeMarker() (*RepositoryMarker, error) {
+	marker := *c.expected
+	marker.CreatedAt = time.Now().UTC()
+	marker.CreatedBy, _ = os.Hostname()
+	data, err := json.MarshalIndent(&marker, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Client.Upload(c.layout.Prefix+MarkerKey, data); err != nil {
+		return nil, fmt.Errorf("error writing repository marker: %w", err)
+	}
+	utils.Debug("Repository marker written: %s", c.layout.Prefix+MarkerKey)
+	return &marker, nil
+}
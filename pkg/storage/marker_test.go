@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func markerConfig(bucket, algorithm, key string) *utils.Config {
+	config := layoutConfig(bucket, "", "", "")
+	config.Backup.EncryptionAlgo = algorithm
+	config.Backup.EncryptionKey = key
+	return config
+}
+
+// createRepository écrit un objet pour que le dépôt reçoive le marqueur de la configuration
+func createRepository(t *testing.T, config *utils.Config) {
+	t.Helper()
+	client, err := NewStorageClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload("indexes/b1.json", []byte("index")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarkerRejectsWrongKey(t *testing.T) {
+	bucket := t.Name()
+	createRepository(t, markerConfig(bucket, "", "cle-du-depot"))
+
+	client, err := NewStorageClient(markerConfig(bucket, "", "autre-cle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Download("indexes/b1.json")
+	if !errors.Is(err, utils.ErrConfig) || !strings.Contains(err.Error(), "encryption key mismatch") {
+		t.Errorf("erreur de clé attendue, obtenu: %v", err)
+	}
+}
+
+func TestMarkerRejectsWrongAlgorithm(t *testing.T) {
+	bucket := t.Name()
+	createRepository(t, markerConfig(bucket, "aes-256-gcm", "cle"))
+
+	client, err := NewStorageClient(markerConfig(bucket, "xchacha20-poly1305", "cle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListObjects("indexes/"); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("erreur d'algorithme attendue, obtenu: %v", err)
+	}
+}
+
+func TestMarkerAllowsEncryptingUnencryptedRepository(t *testing.T) {
+	bucket := t.Name()
+	config := markerConfig(bucket, "none", "")
+	config.Backup.AllowUnencrypted = true
+	createRepository(t, config)
+
+	// Passage au chiffrement: accepté tant que allow_unencrypted relit l'existant
+	config = markerConfig(bucket, "aes-256-gcm", "cle")
+	config.Backup.AllowUnencrypted = true
+	client, err := NewStorageClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Download("indexes/b1.json"); err != nil {
+		t.Errorf("migration vers le chiffrement refusée: %v", err)
+	}
+}
+
+func TestMarkerChunkSettingsAreNotErrors(t *testing.T) {
+	recorded := &RepositoryMarker{IndexPrefix: "indexes/", DataPrefix: "data/", EncryptionAlgo: "aes-256-gcm", ChunkSize: "50MB"}
+	expected := &RepositoryMarker{IndexPrefix: "indexes/", DataPrefix: "data/", EncryptionAlgo: "aes-256-gcm", ChunkSize: "16MB"}
+	differences, err := recorded.Compare(expected)
+	if err != nil {
+		t.Fatalf("différence de découpage traitée comme une erreur: %v", err)
+	}
+	if len(differences) != 1 || differences[0] != "chunk_size 16MB (repository 50MB)" {
+		t.Errorf("différences inattendues: %v", differences)
+	}
+}
+
+func TestKeyFingerprintDecodesHexKeys(t *testing.T) {
+	if KeyFingerprint(strings.Repeat("ab", 32)) != KeyFingerprint(strings.Repeat("\xab", 32)) {
+		t.Error("une clé hexadécimale et ses octets doivent avoir la même empreinte")
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault est retournée par les requêtes que Faults fait échouer
+var ErrInjectedFault = errors.New("injected storage fault")
+
+// Faults décrit les pannes simulées par un stockage de test
+type Faults struct {
+	Latency          time.Duration // Délai ajouté à chaque requête
+	ErrorRate        float64       // Probabilité qu'une requête échoue (0-1)
+	PartialWriteRate float64       // Probabilité qu'un envoi n'écrive que la moitié de l'objet avant d'échouer (0-1)
+	FailPrefix       string        // Les requêtes sur les clés de ce préfixe échouent toujours
+	FailCount        int           // Avec FailPrefix: seules les N premières échouent (panne passagère), 0 = toutes
+	FailAfter        int           // Avec FailPrefix: les N premières réussissent avant la panne
+	CorruptPrefix    string        // Les envois sur ce préfixe réussissent mais stockent un objet altéré
+	ClockSkew        time.Duration // Avance de l'horloge du stockage sur l'horloge locale
+	SlowPrefix       string        // Les envois en flux sur ce préfixe durent SlowUpload, sauf annulation de leur contexte
+	SlowUpload       time.Duration
+	UploadRate       int // Débit des envois en flux (octets/s): le corps est lu progressivement, 0 = illimité
+}
+
+// fails décide si une requête échoue
+func (f Faults) fails(rng *rand.Rand, op, key string) error {
+	if (f.FailPrefix != "" && strings.HasPrefix(key, f.FailPrefix)) || (f.ErrorRate > 0 && rng.Float64() < f.ErrorRate) {
+		return fmt.Errorf("%w: %s %s", ErrInjectedFault, op, key)
+	}
+	return nil
+}
+
+// memoryObject est un objet du stockage en mémoire
+type memoryObject struct {
+	data     []byte
+	modified time.Time
+}
+
+// MemoryClient est un stockage en mémoire avec injection de pannes, pour les tests de bout en bout
+type MemoryClient struct {
+	mu      sync.Mutex
+	objects map[string]memoryObject
+	faults  Faults
+	rng     *rand.Rand
+	// prefixRequests compte les requêtes sur FailPrefix depuis SetFaults (FailAfter, FailCount)
+	prefixRequests int
+}
+
+// NewMemoryClient crée un stockage en mémoire vide
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{
+		objects: make(map[string]memoryObject),
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// memoryStores contient les stockages nommés utilisés par storage.type memory
+var memoryStores sync.Map
+
+// MemoryStore retourne le stockage en mémoire nommé (storage.type memory, bucket = nom), créé au premier appel
+func MemoryStore(name string) *MemoryClient {
+	if client, ok := memoryStores.Load(name); ok {
+		return client.(*MemoryClient)
+	}
+	client, _ := memoryStores.LoadOrStore(name, NewMemoryClient())
+	return client.(*MemoryClient)
+}
+
+// SetFaults remplace les pannes simulées
+func (c *MemoryClient) SetFaults(faults Faults) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.faults = faults
+	c.prefixRequests = 0
+}
+
+// now retourne l'heure du stockage, décalée de Faults.ClockSkew (appelant sous verrou)
+func (c *MemoryClient) now() time.Time {
+	return time.Now().Add(c.faults.ClockSkew)
+}
+
+// fault applique la latence (hors verrou) puis tire le résultat d'une requête
+func (c *MemoryClient) fault(op, key string) error {
+	c.mu.Lock()
+	latency := c.faults.Latency
+	c.mu.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	faults := c.faults
+	if faults.FailPrefix != "" && strings.HasPrefix(key, faults.FailPrefix) {
+		c.prefixRequests++
+		switch {
+		case c.prefixRequests <= faults.FailAfter:
+			faults.FailPrefix = "" // Avant la panne
+		case faults.FailCount > 0 && c.prefixRequests > faults.FailAfter+faults.FailCount:
+			faults.FailPrefix = "" // Panne passagère terminée
+		}
+	}
+	return faults.fails(c.rng, op, key)
+}
+
+// Upload implémente l'interface Client
+func (c *MemoryClient) Upload(key string, data []byte) error {
+	if err := c.fault("PUT", key); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.faults.PartialWriteRate > 0 && c.rng.Float64() < c.faults.PartialWriteRate {
+		c.objects[key] = memoryObject{data: bytes.Clone(data[:len(data)/2]), modified: c.now()}
+		return fmt.Errorf("%w: partial write of %s", ErrInjectedFault, key)
+	}
+	stored := bytes.Clone(data)
+	if c.faults.CorruptPrefix != "" && strings.HasPrefix(key, c.faults.CorruptPrefix) && len(stored) > 0 {
+		stored[len(stored)/2] ^= 0x01
+	}
+	c.objects[key] = memoryObject{data: stored, modified: c.now()}
+	return nil
+}
+
+// UploadStream implémente l'interface Client
+func (c *MemoryClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	c.mu.Lock()
+	faults := c.faults
+	c.mu.Unlock()
+
+	data, err := readAtRate(reader, faults.UploadRate)
+	if err != nil {
+		return err
+	}
+	if faults.SlowPrefix != "" && strings.HasPrefix(key, faults.SlowPrefix) {
+		select {
+		case <-time.After(faults.SlowUpload):
+		case <-ctx.Done():
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return fmt.Errorf("upload of %s: expected %d bytes, got %d", key, size, len(data))
+	}
+	return c.Upload(key, data)
+}
+
+// readAtRate lit tout reader au débit rate (octets/s), par blocs de 1 Ko; rate <= 0 = sans limite
+func readAtRate(reader io.Reader, rate int) ([]byte, error) {
+	if rate <= 0 {
+		return io.ReadAll(reader)
+	}
+	var data bytes.Buffer
+	block := make([]byte, 1024)
+	for {
+		n, err := reader.Read(block)
+		data.Write(block[:n])
+		if err == io.EOF {
+			return data.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		time.Sleep(time.Duration(n) * time.Second / time.Duration(rate))
+	}
+}
+
+// get retourne un objet après injection des pannes
+func (c *MemoryClient) get(key string) ([]byte, error) {
+	if err := c.fault("GET", key); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	object, ok := c.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return object.data, nil
+}
+
+// Download implémente l'interface Client
+func (c *MemoryClient) Download(key string) ([]byte, error) {
+	data, err := c.get(key)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(data), nil
+}
+
+// DownloadStream implémente l'interface Client
+func (c *MemoryClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	data, err := c.get(key)
+	if err != nil {
+		return 0, err
+	}
+	return io.Copy(writer, bytes.NewReader(data))
+}
+
+// DownloadRange implémente l'interface Client
+func (c *MemoryClient) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	data, err := c.get(key)
+	if err != nil {
+		return nil, err
+	}
+	if offset > int64(len(data)) {
+		return nil, fmt.Errorf("range of %s: offset %d beyond %d bytes", key, offset, len(data))
+	}
+	end := int64(len(data))
+	if length > 0 && offset+length < end {
+		end = offset + length
+	}
+	return bytes.Clone(data[offset:end]), nil
+}
+
+// DeleteObject implémente l'interface Client (supprimer un objet absent n'est pas une erreur, comme S3)
+func (c *MemoryClient) DeleteObject(key string) error {
+	if err := c.fault("DELETE", key); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.objects, key)
+	return nil
+}
+
+// ListObjects implémente l'interface Client
+func (c *MemoryClient) ListObjects(prefix string) ([]ObjectInfo, error) {
+	if err := c.fault("LIST", prefix); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var objects []ObjectInfo
+	for key, object := range c.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: int64(len(object.data)), LastModified: object.modified})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Stat implémente l'interface Client
+func (c *MemoryClient) Stat(key string) (ObjectInfo, error) {
+	if err := c.fault("HEAD", key); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	object, ok := c.objects[key]
+	if !ok {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	return ObjectInfo{Key: key, Size: int64(len(object.data)), LastModified: object.modified}, nil
+}
+
+// TestConnectivity implémente l'interface Client
+func (c *MemoryClient) TestConnectivity() error {
+	return c.fault("HEAD", "")
+}
+
+// ServerTime implémente l'interface Client
+func (c *MemoryClient) ServerTime() (time.Time, error) {
+	if err := c.fault("HEAD", ""); err != nil {
+		return time.Time{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now(), nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"strings"
+
+	"bcrdf/pkg/utils"
+)
+
+// Noms opaques (storage.opaque_keys): chaque segment d'une clé sous le préfixe de premier niveau
+// (indexes/, data/, holds/...) est chiffré de façon déterministe, si bien que le fournisseur ne voit
+// ni les IDs de sauvegarde (nom, machine, date) ni la structure des répertoires. Construction SIV:
+// l'IV est le HMAC du segment, qui sert aussi d'authentification; un même segment donne toujours
+// le même nom, ce qui permet les lectures et les listes par préfixe sans table de correspondance.
+const nameIVSize = 16
+
+// nameEncoding est du base32 minuscule sans remplissage, sûr sur les systèmes de fichiers
+// insensibles à la casse (serveurs WebDAV)
+var nameEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// nameCipher chiffre et déchiffre les segments des clés
+type nameCipher struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+// newNameCipher dérive les clés de chiffrement des noms de la clé de chiffrement du dépôt
+func newNameCipher(encryptionKey string) (*nameCipher, error) {
+	key := encryptionKeyBytes(encryptionKey)
+	block, err := aes.NewCipher(deriveKey(key, "bcrdf opaque keys: encryption"))
+	if err != nil {
+		return nil, err
+	}
+	return &nameCipher{block: block, macKey: deriveKey(key, "bcrdf opaque keys: authentication")}, nil
+}
+
+// nameCipherFromConfig retourne le chiffrement des noms configuré (nil sans storage.opaque_keys)
+func nameCipherFromConfig(config *utils.Config) (*nameCipher, error) {
+	if !config.Storage.OpaqueKeys {
+		return nil, nil
+	}
+	if config.Backup.EncryptionAlgo == "none" || config.Backup.EncryptionKey == "" {
+		return nil, fmt.Errorf("%w: storage.opaque_keys requires encryption (an encryption_key and an encryption_algo other than none)", utils.ErrConfig)
+	}
+	return newNameCipher(config.Backup.EncryptionKey)
+}
+
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// encode chiffre les segments d'un chemin (séparés par '/'); un segment vide reste vide
+func (n *nameCipher) encode(path string) string {
+	if n == nil || path == "" {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = n.encodeSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// decode déchiffre les segments d'un chemin (ok = false si un segment n'a pas été chiffré par
+// cette clé, par exemple un objet étranger au dépôt)
+func (n *nameCipher) decode(path string) (string, bool) {
+	if n == nil || path == "" {
+		return path, true
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		plain, ok := n.decodeSegment(segment)
+		if !ok {
+			return "", false
+		}
+		segments[i] = plain
+	}
+	return strings.Join(segments, "/"), true
+}
+
+func (n *nameCipher) encodeSegment(segment string) string {
+	if segment == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, n.macKey)
+	mac.Write([]byte(segment))
+	iv := mac.Sum(nil)[:nameIVSize]
+
+	out := make([]byte, nameIVSize+len(segment))
+	copy(out, iv)
+	cipher.NewCTR(n.block, iv).XORKeyStream(out[nameIVSize:], []byte(segment))
+	return nameEncoding.EncodeToString(out)
+}
+
+func (n *nameCipher) decodeSegment(segment string) (string, bool) {
+	if segment == "" {
+		return "", true
+	}
+	data, err := nameEncoding.DecodeString(segment)
+	if err != nil || len(data) <= nameIVSize {
+		return "", false
+	}
+	iv := data[:nameIVSize]
+	plain := make([]byte, len(data)-nameIVSize)
+	cipher.NewCTR(n.block, iv).XORKeyStream(plain, data[nameIVSize:])
+
+	mac := hmac.New(sha256.New, n.macKey)
+	mac.Write(plain)
+	if !hmac.Equal(mac.Sum(nil)[:nameIVSize], iv) {
+		return "", false
+	}
+	return string(plain), true
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"bcrdf/pkg/utils"
+)
+
+func opaqueConfig(bucket string) *utils.Config {
+	config := markerConfig(bucket, "", "cle-du-depot")
+	config.Storage.OpaqueKeys = true
+	return config
+}
+
+func TestOpaqueKeysHideNames(t *testing.T) {
+	bucket := t.Name()
+	store := MemoryStore(bucket)
+	client, err := NewStorageClient(opaqueConfig(bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logical := []string{"indexes/laptop-home-20260101T000000Z.json", "data/laptop-home-20260101T000000Z/k1.chunk.001",
+		"reports/laptop-home-20260101T000000Z.json", "reports/laptop-home-20260101T000000Z.html"}
+	for _, key := range logical {
+		if err := client.Upload(key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Le bucket ne contient que les préfixes de premier niveau et des noms chiffrés
+	objects, _ := store.ListObjects("")
+	for _, object := range objects {
+		if strings.Contains(object.Key, "laptop") || strings.Contains(object.Key, "chunk") {
+			t.Errorf("nom en clair dans le bucket: %s", object.Key)
+		}
+	}
+
+	// Lectures et listes par clé logique, y compris sur un segment incomplet
+	if data, err := client.Download(logical[1]); err != nil || string(data) != logical[1] {
+		t.Errorf("lecture par clé logique: %q %v", data, err)
+	}
+	reports, err := client.ListObjects("reports/laptop-home-20260101T000000Z.")
+	if err != nil || len(reports) != 2 {
+		t.Errorf("2 rapports attendus, obtenu %v %v", reports, err)
+	}
+	data, err := client.ListObjects("data/laptop-home-20260101T000000Z/")
+	if err != nil || len(data) != 1 || data[0].Key != logical[1] {
+		t.Errorf("liste des données inattendue: %v %v", data, err)
+	}
+}
+
+func TestOpaqueKeysRecordedInMarker(t *testing.T) {
+	bucket := t.Name()
+	createRepository(t, opaqueConfig(bucket))
+
+	client, err := NewStorageClient(markerConfig(bucket, "", "cle-du-depot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListObjects("indexes/"); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("erreur opaque_keys attendue, obtenu: %v", err)
+	}
+}
+
+func TestOpaqueKeysRejectExistingRepository(t *testing.T) {
+	bucket := t.Name()
+	MemoryStore(bucket).Upload("indexes/b1.json", []byte("index"))
+
+	client, err := NewStorageClient(opaqueConfig(bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Upload("indexes/b2.json", []byte("index")); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("activation sur un dépôt existant acceptée: %v", err)
+	}
+}
+
+func TestOpaqueKeysRequireEncryption(t *testing.T) {
+	config := opaqueConfig("b")
+	config.Backup.EncryptionAlgo = "none"
+	if _, err := LayoutFromConfig(config); !errors.Is(err, utils.ErrConfig) {
+		t.Errorf("opaque_keys sans chiffrement accepté: %v", err)
+	}
+}
+
+func TestNameCipherRejectsForeignNames(t *testing.T) {
+	names, _ := newNameCipher("cle")
+	other, _ := newNameCipher("autre-cle")
+	encoded := names.encode("laptop/home")
+	if decoded, ok := names.decode(encoded); !ok || decoded != "laptop/home" {
+		t.Errorf("aller-retour: %q %v", decoded, ok)
+	}
+	if _, ok := other.decode(encoded); ok {
+		t.Error("nom déchiffré avec une autre clé")
+	}
+	if _, ok := names.decode("photos"); ok {
+		t.Error("nom en clair accepté")
+	}
+}
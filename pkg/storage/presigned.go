@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// ErrReadOnlyShare est retournée par les opérations d'écriture d'un partage pré-signé
+var ErrReadOnlyShare = errors.New("read-only share: writes are not allowed")
+
+// Presigner génère des URLs de téléchargement temporaires (S3 uniquement)
+type Presigner interface {
+	PresignGet(key string, expires time.Duration) (string, error)
+}
+
+// NewPresigner instancie le client de signature à partir des identifiants principaux
+func NewPresigner(config *utils.Config) (Presigner, error) {
+	client, err := newStorageClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrStorageUnreachable, err)
+	}
+	presigner, ok := client.(Presigner)
+	if !ok {
+		return nil, fmt.Errorf("%w: pre-signed URLs are not supported by %s storage", utils.ErrConfig, config.Storage.Type)
+	}
+	layout, err := LayoutFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &layoutPresigner{presigner: presigner, layout: layout}, nil
+}
+
+// PresignedObject est un objet accessible par une URL pré-signée
+type PresignedObject struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// PresignedClient est un client en lecture seule limité aux objets d'un partage
+type PresignedClient struct {
+	objects    map[string]PresignedObject
+	httpClient *http.Client
+}
+
+// NewPresignedClient crée un client servant les objets d'un partage
+func NewPresignedClient(objects []PresignedObject) *PresignedClient {
+	client := &PresignedClient{
+		objects:    make(map[string]PresignedObject, len(objects)),
+		httpClient: &http.Client{Timeout: 30 * time.Minute},
+	}
+	for _, object := range objects {
+		client.objects[object.Key] = object
+	}
+	return client
+}
+
+// get envoie un GET sur l'URL de l'objet, avec un en-tête Range optionnel
+func (c *PresignedClient) get(ctx context.Context, key, byteRange string) (io.ReadCloser, error) {
+	object, ok := c.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not part of the share", ErrObjectNotFound, key)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, object.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for %s: %w", key, err)
+	}
+	if byteRange != "" {
+		request.Header.Set("Range", byteRange)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", key, err)
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusPartialContent {
+		response.Body.Close()
+		if response.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("error downloading %s: access denied (share expired or revoked)", key)
+		}
+		return nil, fmt.Errorf("error downloading %s: HTTP %d", key, response.StatusCode)
+	}
+	return response.Body, nil
+}
+
+// Download implémente l'interface Client
+func (c *PresignedClient) Download(key string) ([]byte, error) {
+	body, err := c.get(context.Background(), key, "")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// DownloadStream implémente l'interface Client
+func (c *PresignedClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	body, err := c.get(ctx, key, "")
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	return io.Copy(writer, body)
+}
+
+// DownloadRange implémente l'interface Client
+func (c *PresignedClient) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+	body, err := c.get(context.Background(), key, byteRange)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// ListObjects implémente l'interface Client à partir de la liste du partage
+func (c *PresignedClient) ListObjects(prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for key, object := range c.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: object.Size})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Stat implémente l'interface Client à partir de la liste du partage
+func (c *PresignedClient) Stat(key string) (ObjectInfo, error) {
+	object, ok := c.objects[key]
+	if !ok {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	return ObjectInfo{Key: key, Size: object.Size}, nil
+}
+
+// TestConnectivity implémente l'interface Client
+func (c *PresignedClient) TestConnectivity() error {
+	return nil
+}
+
+// ServerTime implémente l'interface Client: un partage ne donne accès qu'à ses objets, sans requête de service
+func (c *PresignedClient) ServerTime() (time.Time, error) {
+	return time.Time{}, fmt.Errorf("server time is not available through a share")
+}
+
+// Upload implémente l'interface Client
+func (c *PresignedClient) Upload(key string, data []byte) error {
+	return ErrReadOnlyShare
+}
+
+// UploadStream implémente l'interface Client
+func (c *PresignedClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	return ErrReadOnlyShare
+}
+
+// DeleteObject implémente l'interface Client
+func (c *PresignedClient) DeleteObject(key string) error {
+	return ErrReadOnlyShare
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPresignedClient(t *testing.T) {
+	content := []byte("0123456789")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expired" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		http.ServeContent(w, r, "object", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := NewPresignedClient([]PresignedObject{
+		{Key: "data/b1/file", Size: int64(len(content)), URL: server.URL + "/file"},
+		{Key: "indexes/b1.json", Size: 2, URL: server.URL + "/expired"},
+	})
+
+	data, err := client.Download("data/b1/file")
+	if err != nil || string(data) != string(content) {
+		t.Fatalf("téléchargement incorrect: %q, %v", data, err)
+	}
+	part, err := client.DownloadRange("data/b1/file", 2, 3)
+	if err != nil || string(part) != "234" {
+		t.Fatalf("plage incorrecte: %q, %v", part, err)
+	}
+	if _, err := client.Download("indexes/b1.json"); err == nil {
+		t.Fatal("une URL expirée doit retourner une erreur")
+	}
+	if _, err := client.Stat("data/b2/file"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("un objet hors du partage doit être introuvable: %v", err)
+	}
+	objects, _ := client.ListObjects("data/b1/")
+	if len(objects) != 1 {
+		t.Fatalf("1 objet attendu sous data/b1/, obtenu %d", len(objects))
+	}
+	if err := client.Upload("data/b1/other", content); !errors.Is(err, ErrReadOnlyShare) {
+		t.Fatalf("l'écriture doit être refusée: %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bcrdf/pkg/utils"
+)
+
+// DefaultRestoreCacheSize est la taille maximale du cache de restauration sans restore_cache_max_size
+const DefaultRestoreCacheSize = 10 << 30
+
+// RestoreCacheClient garde sur disque les objets de données téléchargés (restaurations, health
+// --test-restore), pour que les restaurations répétées d'une même sauvegarde (exercices de
+// reprise) ne les retéléchargent pas. Les clés de données sont aléatoires et jamais réécrites:
+// un objet en cache est validé par la somme SHA-256 enregistrée à côté, sans requête au stockage.
+type RestoreCacheClient struct {
+	Client
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex // Protège size et sérialise l'éviction
+	size int64      // Taille des objets en cache, mesurée à l'ouverture puis tenue à jour
+}
+
+// cachedObject est un objet présent dans le cache disque
+type cachedObject struct {
+	path string
+	size int64
+	used time.Time
+}
+
+// NewRestoreCacheClient ajoute un cache de restauration sur disque devant un client de stockage
+func NewRestoreCacheClient(client Client, dir string, maxSize int64) Client {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		utils.Warn("Restore cache disabled: %v", err)
+		return client
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultRestoreCacheSize
+	}
+	c := &RestoreCacheClient{Client: client, dir: dir, maxSize: maxSize}
+	_, c.size = c.objects()
+	return c
+}
+
+// isRestoreCacheable indique si un objet contient des données de fichiers (immuables)
+func isRestoreCacheable(key string) bool {
+	return strings.HasPrefix(key, "data/") && !strings.HasSuffix(key, ".metadata")
+}
+
+// Download sert les objets de données depuis le cache disque si leur somme de contrôle est valide
+func (c *RestoreCacheClient) Download(key string) ([]byte, error) {
+	if !isRestoreCacheable(key) {
+		return c.Client.Download(key)
+	}
+	if data, ok := c.lookup(key); ok {
+		utils.Debug("Restore cache hit: %s", key)
+		return data, nil
+	}
+
+	data, err := c.Client.Download(key)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, data)
+	return data, nil
+}
+
+// DownloadStream sert les objets de données depuis le cache disque; un objet absent est écrit
+// dans writer et dans le cache au fil du téléchargement
+func (c *RestoreCacheClient) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	if !isRestoreCacheable(key) {
+		return c.Client.DownloadStream(ctx, key, writer)
+	}
+	if data, ok := c.lookup(key); ok {
+		utils.Debug("Restore cache hit: %s", key)
+		n, err := writer.Write(data)
+		return int64(n), err
+	}
+
+	path := c.diskPath(key)
+	tmp, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		utils.Debug("Restore cache write failed for %s: %v", key, err)
+		return c.Client.DownloadStream(ctx, key, writer)
+	}
+	hasher := sha256.New()
+	n, err := c.Client.DownloadStream(ctx, key, io.MultiWriter(writer, &cacheWriter{file: tmp}, hasher))
+	tmp.Close()
+	if err != nil || n > c.maxSize {
+		os.Remove(tmp.Name())
+		return n, err
+	}
+	c.publish(key, tmp.Name(), n, hex.EncodeToString(hasher.Sum(nil)))
+	return n, nil
+}
+
+// cacheWriter écrit dans le fichier temporaire du cache; une erreur d'écriture (disque plein)
+// abandonne la mise en cache sans interrompre le téléchargement
+type cacheWriter struct {
+	file   *os.File
+	failed bool
+}
+
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		if _, err := w.file.Write(p); err != nil {
+			w.failed = true
+			os.Remove(w.file.Name())
+		}
+	}
+	return len(p), nil
+}
+
+// Upload invalide l'objet en cache avant de l'écrire
+func (c *RestoreCacheClient) Upload(key string, data []byte) error {
+	c.invalidate(key)
+	return c.Client.Upload(key, data)
+}
+
+// UploadStream invalide l'objet en cache avant de l'écrire
+func (c *RestoreCacheClient) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	c.invalidate(key)
+	return c.Client.UploadStream(ctx, key, reader, size)
+}
+
+// DeleteObject retire l'objet du cache avant de le supprimer
+func (c *RestoreCacheClient) DeleteObject(key string) error {
+	c.invalidate(key)
+	return c.Client.DeleteObject(key)
+}
+
+// lookup lit un objet en cache et vérifie sa somme de contrôle (un fichier corrompu est supprimé)
+func (c *RestoreCacheClient) lookup(key string) ([]byte, bool) {
+	path := c.diskPath(key)
+	sum, err := os.ReadFile(path + ".sha256")
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	actual := sha256.Sum256(data)
+	if hex.EncodeToString(actual[:]) != strings.TrimSpace(string(sum)) {
+		utils.Warn("Restore cache entry for %s is corrupted, downloading it again", key)
+		c.invalidate(key)
+		return nil, false
+	}
+
+	// Date d'accès pour l'éviction (moins récemment utilisé en premier)
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// store écrit un objet et sa somme de contrôle, puis évince les plus anciens au-delà de maxSize
+func (c *RestoreCacheClient) store(key string, data []byte) {
+	if int64(len(data)) > c.maxSize {
+		return
+	}
+	sum := sha256.Sum256(data)
+
+	// Écriture atomique: la somme n'est publiée qu'après les données
+	tmp := c.diskPath(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		utils.Debug("Restore cache write failed for %s: %v", key, err)
+		return
+	}
+	c.publish(key, tmp, int64(len(data)), hex.EncodeToString(sum[:]))
+}
+
+// publish installe le fichier temporaire tmp comme objet en cache, écrit sa somme de contrôle
+// puis évince les plus anciens au-delà de maxSize
+func (c *RestoreCacheClient) publish(key, tmp string, size int64, sum string) {
+	path := c.diskPath(key)
+	previous := fileSize(path)
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	c.mu.Lock()
+	c.size += size - previous
+	c.mu.Unlock()
+	if err := os.WriteFile(path+".sha256", []byte(sum), 0600); err != nil {
+		c.invalidate(key)
+		return
+	}
+	c.evict(path)
+}
+
+// evict supprime les objets les moins récemment utilisés tant que le cache dépasse maxSize
+// (keep, l'objet qui vient d'être écrit, est conservé). Le répertoire n'est parcouru que si la
+// taille tenue à jour dépasse maxSize; le parcours la recalcule (cache partagé entre processus).
+func (c *RestoreCacheClient) evict(keep string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= c.maxSize {
+		return
+	}
+
+	objects, total := c.objects()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].used.Before(objects[j].used) })
+	for _, object := range objects {
+		if total <= c.maxSize {
+			break
+		}
+		if object.path == keep {
+			continue
+		}
+		os.Remove(object.path + ".sha256")
+		os.Remove(object.path)
+		total -= object.size
+	}
+	c.size = total
+}
+
+// objects liste les objets du cache et leur taille totale
+func (c *RestoreCacheClient) objects() ([]cachedObject, int64) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, 0
+	}
+	var objects []cachedObject
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || strings.Contains(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		objects = append(objects, cachedObject{path: filepath.Join(c.dir, entry.Name()), size: info.Size(), used: info.ModTime()})
+		total += info.Size()
+	}
+	return objects, total
+}
+
+// invalidate retire un objet du cache
+func (c *RestoreCacheClient) invalidate(key string) {
+	if !isRestoreCacheable(key) {
+		return
+	}
+	path := c.diskPath(key)
+	size := fileSize(path)
+	os.Remove(path + ".sha256")
+	if os.Remove(path) == nil {
+		c.mu.Lock()
+		c.size -= size
+		c.mu.Unlock()
+	}
+}
+
+// fileSize retourne la taille d'un fichier, 0 s'il n'existe pas
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// diskPath retourne le fichier du cache d'un objet
+func (c *RestoreCacheClient) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+)
+
+func TestRestoreCacheServesValidatedObjects(t *testing.T) {
+	backend := newCountingClient()
+	backend.objects["data/b1/k1"] = []byte("chiffré")
+	backend.objects["indexes/b1.json"] = []byte("{}")
+
+	dir := t.TempDir()
+	client := NewRestoreCacheClient(backend, dir, 0).(*RestoreCacheClient)
+	for i := 0; i < 2; i++ {
+		if data, err := client.Download("data/b1/k1"); err != nil || string(data) != "chiffré" {
+			t.Fatalf("lecture %d: %q, %v", i, data, err)
+		}
+	}
+	if backend.downloads != 1 {
+		t.Fatalf("un seul téléchargement attendu, obtenu %d", backend.downloads)
+	}
+
+	// Un nouveau client (exécution suivante) relit le cache disque
+	client = NewRestoreCacheClient(backend, dir, 0).(*RestoreCacheClient)
+	client.Download("data/b1/k1")
+	if backend.downloads != 1 {
+		t.Fatalf("cache disque non réutilisé: %d téléchargements", backend.downloads)
+	}
+
+	// Un objet corrompu est retéléchargé
+	if err := os.WriteFile(client.diskPath("data/b1/k1"), []byte("altéré"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := client.Download("data/b1/k1"); string(data) != "chiffré" {
+		t.Fatalf("objet corrompu servi: %q", data)
+	}
+	if backend.downloads != 2 {
+		t.Fatalf("retéléchargement attendu, obtenu %d téléchargements", backend.downloads)
+	}
+
+	// Les index ne passent pas par le cache de restauration
+	client.Download("indexes/b1.json")
+	client.Download("indexes/b1.json")
+	if backend.downloads != 4 {
+		t.Fatalf("index mis en cache: %d téléchargements", backend.downloads)
+	}
+}
+
+func TestRestoreCacheEvictsBeyondMaxSize(t *testing.T) {
+	backend := newCountingClient()
+	backend.objects["data/b1/k1"] = make([]byte, 60)
+	backend.objects["data/b1/k2"] = make([]byte, 60)
+
+	client := NewRestoreCacheClient(backend, t.TempDir(), 100).(*RestoreCacheClient)
+	client.Download("data/b1/k1")
+	client.Download("data/b1/k2")
+
+	if _, err := os.Stat(client.diskPath("data/b1/k2")); err != nil {
+		t.Errorf("dernier objet évincé: %v", err)
+	}
+	if _, err := os.Stat(client.diskPath("data/b1/k1")); err == nil {
+		t.Error("le cache dépasse sa taille maximale")
+	}
+}
+
+func TestRestoreCacheTracksSize(t *testing.T) {
+	backend := newCountingClient()
+	backend.objects["data/b1/k1"] = make([]byte, 40)
+	backend.objects["data/b1/k2"] = make([]byte, 30)
+
+	dir := t.TempDir()
+	client := NewRestoreCacheClient(backend, dir, 100).(*RestoreCacheClient)
+	client.Download("data/b1/k1")
+	if client.size != 40 {
+		t.Fatalf("taille du cache: %d, attendu 40", client.size)
+	}
+
+	// Un nouveau client mesure le cache existant une seule fois, à l'ouverture
+	client = NewRestoreCacheClient(backend, dir, 100).(*RestoreCacheClient)
+	if client.size != 40 {
+		t.Fatalf("taille mesurée à l'ouverture: %d, attendu 40", client.size)
+	}
+	client.Download("data/b1/k2")
+	client.DeleteObject("data/b1/k1")
+	if client.size != 30 {
+		t.Errorf("taille après invalidation: %d, attendu 30", client.size)
+	}
+}
+
+func TestRestoreCacheStreamsObjects(t *testing.T) {
+	backend := newCountingClient()
+	backend.objects["data/b1/k1"] = []byte("chiffré")
+
+	client := NewRestoreCacheClient(backend, t.TempDir(), 0).(*RestoreCacheClient)
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		n, err := client.DownloadStream(context.Background(), "data/b1/k1", &buf)
+		if err != nil || buf.String() != "chiffré" || n != int64(buf.Len()) {
+			t.Fatalf("lecture %d: %q (%d octets), %v", i, buf.String(), n, err)
+		}
+	}
+	if backend.downloads != 1 {
+		t.Fatalf("l'objet téléchargé en flux devrait être servi par le cache, %d téléchargements", backend.downloads)
+	}
+	if client.size != int64(len("chiffré")) {
+		t.Errorf("taille du cache: %d", client.size)
+	}
+
+	// Le cache écrit en flux est relu par Download
+	if data, err := client.Download("data/b1/k1"); err != nil || string(data) != "chiffré" || backend.downloads != 1 {
+		t.Errorf("cache écrit en flux non réutilisé: %q, %v, %d téléchargements", data, err, backend.downloads)
+	}
+}
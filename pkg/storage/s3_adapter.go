@@ -1,6 +1,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"bcrdf/pkg/s3"
 )
 
@@ -33,6 +39,19 @@ func NewS3AdapterWithStorageClass(accessKey, secretKey, region, endpoint, bucket
 	}, nil
 }
 
+// NewS3AdapterWithOptions crée un adaptateur S3 à partir des options de connexion complètes
+func NewS3AdapterWithOptions(opts s3.Options, storageClass string) (*S3Adapter, error) {
+	client, err := s3.NewClientWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Adapter{
+		client:       client,
+		storageClass: storageClass,
+	}, nil
+}
+
 // Upload implémente l'interface Client
 func (a *S3Adapter) Upload(key string, data []byte) error {
 	return a.client.UploadWithStorageClass(key, data, a.storageClass)
@@ -43,6 +62,26 @@ func (a *S3Adapter) Download(key string) ([]byte, error) {
 	return a.client.Download(key)
 }
 
+// UploadStream implémente l'interface Client
+func (a *S3Adapter) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	return a.client.UploadStream(ctx, key, reader, size, a.storageClass)
+}
+
+// PresignGet implémente l'interface Presigner
+func (a *S3Adapter) PresignGet(key string, expires time.Duration) (string, error) {
+	return a.client.PresignGet(key, expires)
+}
+
+// DownloadStream implémente l'interface Client
+func (a *S3Adapter) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	return a.client.DownloadStream(ctx, key, writer)
+}
+
+// DownloadRange implémente l'interface Client
+func (a *S3Adapter) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	return a.client.DownloadRange(key, offset, length)
+}
+
 // DeleteObject implémente l'interface Client
 func (a *S3Adapter) DeleteObject(key string) error {
 	return a.client.DeleteObject(key)
@@ -74,3 +113,20 @@ func (a *S3Adapter) TestConnectivity() error {
 	_, err := a.client.ListObjects("test/")
 	return err
 }
+
+// ServerTime implémente l'interface Client
+func (a *S3Adapter) ServerTime() (time.Time, error) {
+	return a.client.ServerTime()
+}
+
+// Stat implémente l'interface Client (HEAD)
+func (a *S3Adapter) Stat(key string) (ObjectInfo, error) {
+	info, err := a.client.Stat(key)
+	if errors.Is(err, s3.ErrNotFound) {
+		return ObjectInfo{}, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Key: info.Key, Size: info.Size, LastModified: info.LastModified, ETag: info.ETag}, nil
+}
@@ -1,6 +1,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"bcrdf/pkg/webdav"
 )
 
@@ -29,6 +35,21 @@ func (a *WebDAVAdapter) Download(key string) ([]byte, error) {
 	return a.client.Download(key)
 }
 
+// UploadStream implémente l'interface Client
+func (a *WebDAVAdapter) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	return a.client.UploadStream(ctx, key, reader, size)
+}
+
+// DownloadStream implémente l'interface Client
+func (a *WebDAVAdapter) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
+	return a.client.DownloadStream(ctx, key, writer)
+}
+
+// DownloadRange implémente l'interface Client
+func (a *WebDAVAdapter) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	return a.client.DownloadRange(key, offset, length)
+}
+
 // DeleteObject implémente l'interface Client
 func (a *WebDAVAdapter) DeleteObject(key string) error {
 	return a.client.DeleteObject(key)
@@ -58,3 +79,20 @@ func (a *WebDAVAdapter) ListObjects(prefix string) ([]ObjectInfo, error) {
 func (a *WebDAVAdapter) TestConnectivity() error {
 	return a.client.TestConnectivity()
 }
+
+// ServerTime implémente l'interface Client
+func (a *WebDAVAdapter) ServerTime() (time.Time, error) {
+	return a.client.ServerTime()
+}
+
+// Stat implémente l'interface Client (PROPFIND)
+func (a *WebDAVAdapter) Stat(key string) (ObjectInfo, error) {
+	info, err := a.client.Stat(key)
+	if errors.Is(err, webdav.ErrNotFound) {
+		return ObjectInfo{}, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Key: info.Key, Size: info.Size, LastModified: info.LastModified, ETag: info.ETag}, nil
+}
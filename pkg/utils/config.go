@@ -3,6 +3,9 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -10,6 +13,8 @@ import (
 
 // Config représente la configuration de l'application
 type Config struct {
+	Include []string `mapstructure:"include"` // Shared base configurations merged under this file (fleet settings), relative to it
+
 	Storage struct {
 		Type string `mapstructure:"type"`
 		// S3 fields
@@ -18,11 +23,19 @@ type Config struct {
 		AccessKey    string `mapstructure:"access_key"`
 		SecretKey    string `mapstructure:"secret_key"`
 		StorageClass string `mapstructure:"storage_class"` // S3 storage class (STANDARD, GLACIER, etc.)
+		AddressingStyle string `mapstructure:"addressing_style"` // S3 with custom endpoint: "path" (default) or "virtual"
+		Prefix       string `mapstructure:"prefix"`       // Root of the repository in the bucket (e.g. "backups/bcrdf/"), empty = bucket root
+		IndexPrefix  string `mapstructure:"index_prefix"` // Index location under the root, empty = "indexes/"
+		DataPrefix   string `mapstructure:"data_prefix"`  // Data location under the root, empty = "data/"
+		OpaqueKeys   bool   `mapstructure:"opaque_keys"`  // Encrypt object names below the top-level prefixes (requires encryption)
 		// Common fields
 		Endpoint string `mapstructure:"endpoint"`
 		// WebDAV fields
 		Username string `mapstructure:"username"`
 		Password string `mapstructure:"password"`
+		// Destructive credentials are used only for deletions (retention, clean, gc, delete),
+		// so the everyday credentials can be write-only
+		Destructive DestructiveCredentials `mapstructure:"destructive"`
 	} `mapstructure:"storage"`
 
 	Backup struct {
@@ -30,32 +43,119 @@ type Config struct {
 		EncryptionAlgo      string   `mapstructure:"encryption_algo"`
 		CompressionLevel    int      `mapstructure:"compression_level"`
 		MaxWorkers          int      `mapstructure:"max_workers"`
-		ChecksumMode        string   `mapstructure:"checksum_mode"` // "full", "fast", "metadata"
+		ChecksumMode        string   `mapstructure:"checksum_mode"` // "full", "fast", "metadata", "mtime-size"
 		SkipPatterns        []string `mapstructure:"skip_patterns"`
+		Sources             []string `mapstructure:"sources"`               // Source paths backed up together when backup has no --source
 		BufferSize          string   `mapstructure:"buffer_size"`
 		BatchSize           int      `mapstructure:"batch_size"`            // Number of files to batch together
 		BatchSizeLimit      string   `mapstructure:"batch_size_limit"`      // Max size for batch upload (e.g., "10MB")
 		ChunkSize           string   `mapstructure:"chunk_size"`            // Chunk size for streaming operations
-		MemoryLimit         string   `mapstructure:"memory_limit"`          // Memory limit for large files
+		MemoryLimit         string   `mapstructure:"memory_limit"`          // Cap on data buffered across workers (e.g., "256MB"), empty = no limit
 		NetworkTimeout      int      `mapstructure:"network_timeout"`       // Network timeout in seconds
 		RetryAttempts       int      `mapstructure:"retry_attempts"`        // Number of retry attempts
 		RetryDelay          int      `mapstructure:"retry_delay"`           // Delay between retries in seconds
+		RetryFailedFiles    bool     `mapstructure:"retry_failed_files"`    // Retry failed files one at a time at the end of the run (default true)
 		CacheEnabled        bool     `mapstructure:"cache_enabled"`         // Enable checksum caching
 		CacheMaxSize        int      `mapstructure:"cache_max_size"`        // Maximum cache entries
 		CacheMaxAge         int      `mapstructure:"cache_max_age"`         // Cache entry max age (minutes)
 		CompressionAdaptive bool     `mapstructure:"compression_adaptive"`  // Enable adaptive compression
+		CompressionDictionary bool   `mapstructure:"compression_dictionary"` // Dictionnaire DEFLATE entraîné par job pour les petits fichiers
 		SortBySize          bool     `mapstructure:"sort_by_size"`          // Sort files by size (smallest first)
 		ChunkSizeLarge      string   `mapstructure:"chunk_size_large"`      // Chunk size for large files (e.g., "50MB")
 		LargeFileThreshold  string   `mapstructure:"large_file_threshold"`  // Threshold for large files (e.g., "100MB")
 		UltraLargeThreshold string   `mapstructure:"ultra_large_threshold"` // Threshold for ultra-large files (e.g., "5GB")
+		Recipients          []string `mapstructure:"recipients"`            // age public keys: file data is encrypted with per-backup keys wrapped for them
+		IdentityFile        string   `mapstructure:"identity_file"`         // age identity file used to unwrap data keys (restore workstation)
+		ErrorPolicy         string   `mapstructure:"error_policy"`          // "fail", "continue" or "threshold=N%"
+		PreserveEmptyFiles  bool     `mapstructure:"preserve_empty_files"`  // Record zero-byte files in the index
+		PreserveDirectories bool     `mapstructure:"preserve_directories"`  // Record directory entries (with permissions) in the index
+		OneFileSystem       bool     `mapstructure:"one_file_system"`       // Do not descend into other mounted filesystems
+		Symlinks            string   `mapstructure:"symlinks"`              // "store" (default), "follow" or "skip"
+		HonorIgnoreFiles    bool     `mapstructure:"honor_ignore_files"`    // Apply per-directory .bcrdfignore files found in the source (default true)
+		IDTemplate          string   `mapstructure:"id_template"`           // Backup ID template ({name}, {hostname}, {date}), must end with -{date}
+		FileHandlers        []FileHandlerRule `mapstructure:"file_handlers"` // Application-consistent copies of matching files (sqlite, command)
+		ChangedFilePolicy   string   `mapstructure:"changed_file_policy"`   // "ignore", "retry", "snapshot", "skip" or "verify"
+		MaxFileSize         string   `mapstructure:"max_file_size"`         // Skip files larger than this (e.g., "20GB"), empty = no limit
+		PerFileTimeout      int      `mapstructure:"per_file_timeout"`      // Skip a file whose backup takes longer (seconds), 0 = no limit
+		StallTimeout        int      `mapstructure:"stall_timeout"`         // Abort a transfer without progress for this long (seconds), 0 = default 300
+		CircuitBreakerThreshold int  `mapstructure:"circuit_breaker_threshold"` // Consecutive upload failures that pause all uploads, 0 = default 5
+		CircuitBreakerCooldown  int  `mapstructure:"circuit_breaker_cooldown"`  // Pause duration when the circuit breaker opens (seconds), 0 = default 60
+		AdaptiveConcurrency     bool `mapstructure:"adaptive_concurrency"`      // Scale workers between 1 and max_workers from backend latency/throttling
+		IndexCompression    string   `mapstructure:"index_compression"`     // "gzip" (default) or "none", applied to indexes before encryption
+		IndexDeltas         bool     `mapstructure:"index_deltas"`          // Upload a base index plus per-run deltas instead of the full index
+		StateDB             string   `mapstructure:"state_db"`              // Local SQLite state database (history, file state, checksum cache), empty = disabled
+		Catalog             bool     `mapstructure:"catalog"`               // Keep the file catalog of state_db in line with the repository after each backup (bcrdf find)
+		AnomalyGuard        string   `mapstructure:"anomaly_guard"`         // Abnormal change rate / entropy: "warn" (default), "block" (needs --confirm-anomaly) or "off"
+		AnomalyThreshold    int      `mapstructure:"anomaly_threshold"`     // Percentage of previous files modified or deleted considered abnormal, 0 = default 50
+		MetadataCache       bool     `mapstructure:"metadata_cache"`        // Cache index and chunk metadata objects (validated by ETag)
+		MetadataCacheDir    string   `mapstructure:"metadata_cache_dir"`    // Persist the metadata cache on disk across runs, empty = memory only
+		RestoreNice         int      `mapstructure:"restore_nice"`          // CPU nice (0-19) of restores and health checks
+		RestoreIOPriority   string   `mapstructure:"restore_io_priority"`   // normal (default), low or idle
+		RestoreRateLimit    string   `mapstructure:"restore_rate_limit"`    // Download rate limit of restores (e.g. "20MB" per second), empty = unlimited
+		RestoreCacheDir     string   `mapstructure:"restore_cache_dir"`     // Keep downloaded data objects on disk for repeated restores, empty = disabled
+		RestoreCacheMaxSize string   `mapstructure:"restore_cache_max_size"` // Size cap of the restore cache (e.g. "50GB"), empty = 10GB
+		RestoreAudit        bool     `mapstructure:"restore_audit"`         // Record each restore (who, backup, paths, destination, checksums) in a chained audit log in the repository
+		MaxParallelJobs     int      `mapstructure:"max_parallel_jobs"`     // Scheduled backups running at once on this host, 0 = no coordination
+		JobPriority         int      `mapstructure:"job_priority"`          // Higher runs first when jobs wait for a slot
+		JobQueueDir         string   `mapstructure:"job_queue_dir"`         // Directory shared by the jobs of this host (default: temp dir/bcrdf-jobs)
+		TempDir             string   `mapstructure:"temp_dir"`              // Location of the temp files (snapshots, handler copies, verification restores), empty = TMPDIR
+		TempMaxSize         string   `mapstructure:"temp_max_size"`         // Cap on the temp space used at once (e.g. "20GB"), empty = no cap
+		MaxClockSkew        int      `mapstructure:"max_clock_skew"`        // Tolerated difference with the storage clock (seconds) before warning and using the storage time for age-based retention, 0 = default 60
+		AppendOnly          bool     `mapstructure:"append_only"`           // Refuse every deletion (retention, clean, delete, gc): pruning is done by a trusted host
+		VerifyUploads       bool     `mapstructure:"verify_uploads"`        // Read back each object right after upload (HEAD with MD5 ETag, else download) and compare it with the data sent
+		AllowUnencrypted    bool     `mapstructure:"allow_unencrypted"`     // Required for encryption_algo "none" (storage already encrypted), also allows reading unencrypted objects
+		ReportPath          string   `mapstructure:"report_path"`           // Write a run report to this file or directory ({backup_id} expanded), empty = none
+		ReportFormat        string   `mapstructure:"report_format"`         // "markdown" (default) or "html"
+		ReportUpload        bool     `mapstructure:"report_upload"`         // Upload the report (encrypted) next to the index: bcrdf report <backup-id>
+		Parity              string   `mapstructure:"parity"`                // Reed-Solomon parity per backup, "data+parity" objects (e.g. "10+2"), empty = none
 	} `mapstructure:"backup"`
 
 	Retention struct {
 		Days       int `mapstructure:"days"`
 		MaxBackups int `mapstructure:"max_backups"`
+		MinBackups int `mapstructure:"min_backups"` // Floor kept per backup series whatever the age, 0 = none
+		MaxTotalSize string `mapstructure:"max_total_size"` // Repository size quota (e.g. "2TB"): oldest backups and orphans are pruned beyond it, empty = none
 	} `mapstructure:"retention"`
 }
 
+// DestructiveCredentials sont les identifiants réservés aux suppressions (storage.destructive)
+// Ils peuvent aussi être fournis par l'environnement (BCRDF_DELETE_*) sur l'instance de confiance uniquement.
+type DestructiveCredentials struct {
+	AccessKey string `mapstructure:"access_key" yaml:"access_key,omitempty"`
+	SecretKey string `mapstructure:"secret_key" yaml:"secret_key,omitempty"`
+	RoleARN   string `mapstructure:"role_arn" yaml:"role_arn,omitempty"` // Rôle STS assumé pour les suppressions (S3)
+	Username  string `mapstructure:"username" yaml:"username,omitempty"`
+	Password  string `mapstructure:"password" yaml:"password,omitempty"`
+}
+
+// FileHandlerRule associe un motif de chemin à un handler de copie cohérente (backup.file_handlers)
+type FileHandlerRule struct {
+	Pattern string `mapstructure:"pattern" yaml:"pattern"`           // Nom de fichier (*.db) ou chemin complet si le motif contient /
+	Handler string `mapstructure:"handler" yaml:"handler"`           // sqlite ou command
+	Command string `mapstructure:"command" yaml:"command,omitempty"` // Handler command: {path} et {output} remplacés
+}
+
+// IsSet indique si des identifiants de suppression sont configurés
+func (d DestructiveCredentials) IsSet() bool {
+	return d.AccessKey != "" || d.RoleARN != "" || d.Username != ""
+}
+
+// applyDestructiveEnv surcharge les identifiants de suppression par l'environnement
+func applyDestructiveEnv(config *Config) {
+	overrides := map[string]*string{
+		"BCRDF_DELETE_ACCESS_KEY": &config.Storage.Destructive.AccessKey,
+		"BCRDF_DELETE_SECRET_KEY": &config.Storage.Destructive.SecretKey,
+		"BCRDF_DELETE_ROLE_ARN":   &config.Storage.Destructive.RoleARN,
+		"BCRDF_DELETE_USERNAME":   &config.Storage.Destructive.Username,
+		"BCRDF_DELETE_PASSWORD":   &config.Storage.Destructive.Password,
+	}
+	for name, field := range overrides {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+}
+
 // LoadConfig charge la configuration depuis un fichier
 func LoadConfig(configFile string) (*Config, error) {
 	viper.SetConfigFile(configFile)
@@ -67,6 +167,10 @@ func LoadConfig(configFile string) (*Config, error) {
 	viper.SetDefault("backup.encryption_algo", "aes-256-gcm")
 	viper.SetDefault("backup.compression_level", 3)
 	viper.SetDefault("backup.max_workers", 10)
+	viper.SetDefault("backup.honor_ignore_files", true)
+	viper.SetDefault("backup.retry_failed_files", true)
+	viper.SetDefault("backup.large_file_threshold", "100MB")
+	viper.SetDefault("backup.ultra_large_threshold", "5GB")
 	viper.SetDefault("retention.days", 30)
 	viper.SetDefault("retention.max_backups", 10)
 
@@ -76,17 +180,29 @@ func LoadConfig(configFile string) (*Config, error) {
 			// Créer un fichier de configuration par défaut
 			return createDefaultConfig(configFile)
 		}
-		return nil, fmt.Errorf("error reading file de configuration: %w", err)
+		return nil, fmt.Errorf("%w: error reading configuration file: %w", ErrConfig, err)
+	}
+
+	// Configuration partagée (include:): les fichiers inclus servent de base au fichier local
+	if viper.IsSet("include") {
+		merged, err := resolveIncludes(configFile, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfig, err)
+		}
+		if err := viper.MergeConfigMap(merged); err != nil {
+			return nil, fmt.Errorf("%w: error merging included configuration: %w", ErrConfig, err)
+		}
 	}
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("error decoding configuration: %w", err)
+		return nil, fmt.Errorf("%w: error decoding configuration: %w", ErrConfig, err)
 	}
+	applyDestructiveEnv(&config)
 
 	// Validation de la configuration
 	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("configuration invalide: %w", err)
+		return nil, fmt.Errorf("%w: configuration invalide: %w", ErrConfig, err)
 	}
 
 	return &config, nil
@@ -94,7 +210,7 @@ func LoadConfig(configFile string) (*Config, error) {
 
 // createDefaultConfig crée un fichier de configuration par défaut
 func createDefaultConfig(configFile string) (*Config, error) {
-	Debug("Création d'un fichier de configuration par défaut: %s", configFile)
+	Debug("Creating default configuration file: %s", configFile)
 
 	defaultConfig := `# BCRDF Configuration - Optimized for Performance
 storage:
@@ -110,7 +226,7 @@ backup:
   encryption_algo: "aes-256-gcm"  # Options: "aes-256-gcm", "xchacha20-poly1305"
   compression_level: 1  # GZIP level (1-9) - Fastest to avoid compression issues
   max_workers: 16  # Number of parallel workers (balanced for stability)
-  checksum_mode: "fast"  # Options: "full" (slow, secure), "fast" (recommended), "metadata" (fastest)
+  checksum_mode: "fast"  # Options: "full" (slow, secure), "fast" (recommended), "metadata", "mtime-size" (fastest, reduced safety)
   buffer_size: "32MB"  # Buffer size for I/O operations (smaller for stability)
   batch_size: 25  # Number of small files to batch together (balanced)
   batch_size_limit: "8MB"  # Maximum size for batch uploads (smaller for stability)
@@ -149,10 +265,12 @@ backup:
     - "*.vdi"
     - "*.qcow2"
     - "*.raw"
+  honor_ignore_files: true  # Apply .bcrdfignore files found in the source directories
 
 retention:
   days: 30  # Retention period in days
   max_backups: 10  # Maximum number of backups
+  min_backups: 3  # Never keep fewer backups, whatever their age
 `
 
 	if err := os.WriteFile(configFile, []byte(defaultConfig), 0600); err != nil {
@@ -168,7 +286,7 @@ retention:
 	viper.SetConfigType("yaml")
 
 	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading file de configuration: %w", err)
+		return nil, fmt.Errorf("error reading configuration file: %w", err)
 	}
 
 	if err := viper.Unmarshal(&config); err != nil {
@@ -181,7 +299,7 @@ retention:
 // validateConfig valide la configuration de base (validation légère)
 func validateConfig(config *Config) error {
 	// Validation du type de stockage
-	if config.Storage.Type != "s3" && config.Storage.Type != "webdav" {
+	if config.Storage.Type != "s3" && config.Storage.Type != "webdav" && config.Storage.Type != "memory" {
 		return fmt.Errorf("unsupported storage type: %s", config.Storage.Type)
 	}
 
@@ -191,6 +309,9 @@ func validateConfig(config *Config) error {
 		return validateS3Config(config)
 	case "webdav":
 		return validateWebDAVConfig(config)
+	case "memory":
+		// Stockage en mémoire des tests de bout en bout
+		return validateCommonConfig(config)
 	}
 
 	return nil
@@ -199,7 +320,7 @@ func validateConfig(config *Config) error {
 // validateS3Config valide la configuration S3
 func validateS3Config(config *Config) error {
 	if config.Storage.Bucket == "" {
-		return fmt.Errorf("le nom du bucket S3 est requis")
+		return fmt.Errorf("S3 bucket name is required")
 	}
 
 	if config.Storage.AccessKey == "" {
@@ -207,7 +328,7 @@ func validateS3Config(config *Config) error {
 		if accessKey := os.Getenv("AWS_ACCESS_KEY_ID"); accessKey != "" {
 			config.Storage.AccessKey = accessKey
 		} else {
-			return fmt.Errorf("AWS access key is required (AWS_ACCESS_KEY_ID ou access_key dans la config)")
+			return fmt.Errorf("AWS access key is required (AWS_ACCESS_KEY_ID or access_key in config)")
 		}
 	}
 
@@ -216,7 +337,7 @@ func validateS3Config(config *Config) error {
 		if secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY"); secretKey != "" {
 			config.Storage.SecretKey = secretKey
 		} else {
-			return fmt.Errorf("AWS secret key is required (AWS_SECRET_ACCESS_KEY ou secret_key dans la config)")
+			return fmt.Errorf("AWS secret key is required (AWS_SECRET_ACCESS_KEY or secret_key in config)")
 		}
 	}
 
@@ -226,15 +347,15 @@ func validateS3Config(config *Config) error {
 // validateWebDAVConfig valide la configuration WebDAV
 func validateWebDAVConfig(config *Config) error {
 	if config.Storage.Endpoint == "" {
-		return fmt.Errorf("l'URL du serveur WebDAV est requise")
+		return fmt.Errorf("WebDAV server URL is required")
 	}
 
 	if config.Storage.Username == "" {
-		return fmt.Errorf("le nom d'utilisateur WebDAV est requis")
+		return fmt.Errorf("WebDAV username is required")
 	}
 
 	if config.Storage.Password == "" {
-		return fmt.Errorf("le mot de passe WebDAV est requis")
+		return fmt.Errorf("WebDAV password is required")
 	}
 
 	return validateCommonConfig(config)
@@ -247,9 +368,17 @@ func validateCommonConfig(config *Config) error {
         config.Backup.EncryptionAlgo = algoEnv
     }
 
+    // Encryption-free mode must be opted into explicitly
+    if config.Backup.EncryptionAlgo == "none" && !config.Backup.AllowUnencrypted {
+        return fmt.Errorf("encryption_algo none requires allow_unencrypted: true")
+    }
+
     if config.Backup.EncryptionKey == "" || config.Backup.EncryptionKey == "your-encryption-key-here" {
         if keyEnv := os.Getenv("BCRDF_ENCRYPTION_KEY"); keyEnv != "" {
             config.Backup.EncryptionKey = keyEnv
+        } else if config.Backup.EncryptionAlgo == "none" {
+            // No key needed without encryption
+            config.Backup.EncryptionKey = ""
         } else {
             return fmt.Errorf("encryption key is required")
         }
@@ -276,9 +405,162 @@ func validateCommonConfig(config *Config) error {
 		return fmt.Errorf("retry delay must be between 1 and 60 seconds")
 	}
 
+	if _, err := ParseErrorPolicy(config.Backup.ErrorPolicy); err != nil {
+		return err
+	}
+
+	switch config.Backup.ChangedFilePolicy {
+	case "", "ignore", "retry", "snapshot", "skip", "verify":
+	default:
+		return fmt.Errorf("changed file policy must be one of ignore, retry, snapshot, skip, verify")
+	}
+
+	for _, rule := range config.Backup.FileHandlers {
+		if rule.Pattern == "" || rule.Handler == "" {
+			return fmt.Errorf("file_handlers entries need a pattern and a handler")
+		}
+		if rule.Handler == "command" && rule.Command == "" {
+			return fmt.Errorf("file handler command for %s needs a command", rule.Pattern)
+		}
+	}
+
+	switch config.Backup.Symlinks {
+	case "", "store", "follow", "skip":
+	default:
+		return fmt.Errorf("invalid symlinks %q (expected store, follow or skip)", config.Backup.Symlinks)
+	}
+
+	if err := validateIDTemplate(config.Backup.IDTemplate); err != nil {
+		return err
+	}
+
+	if _, err := ResolveSources(config.Backup.Sources); err != nil {
+		return err
+	}
+
+	if config.Retention.MinBackups < 0 {
+		return fmt.Errorf("retention min_backups must be 0 (no floor) or more")
+	}
+	if config.Retention.MaxBackups > 0 && config.Retention.MinBackups > config.Retention.MaxBackups {
+		return fmt.Errorf("retention min_backups (%d) cannot exceed max_backups (%d)", config.Retention.MinBackups, config.Retention.MaxBackups)
+	}
+
+	if config.Retention.MaxTotalSize != "" {
+		if _, err := ParseSize(config.Retention.MaxTotalSize); err != nil {
+			return fmt.Errorf("invalid retention max_total_size: %w", err)
+		}
+	}
+
+	if config.Backup.MaxFileSize != "" {
+		if _, err := ParseSize(config.Backup.MaxFileSize); err != nil {
+			return fmt.Errorf("invalid max_file_size: %w", err)
+		}
+	}
+
+	if config.Backup.PerFileTimeout < 0 {
+		return fmt.Errorf("per file timeout must be 0 (disabled) or a number of seconds")
+	}
+
+	if config.Backup.StallTimeout < 0 {
+		return fmt.Errorf("stall timeout must be 0 (default) or a number of seconds")
+	}
+
+	if config.Backup.MaxClockSkew < 0 {
+		return fmt.Errorf("max clock skew must be 0 (default) or a number of seconds")
+	}
+
+	if config.Backup.MemoryLimit != "" {
+		if _, err := ParseSize(config.Backup.MemoryLimit); err != nil {
+			return fmt.Errorf("invalid memory_limit: %w", err)
+		}
+	}
+
+	switch config.Backup.IndexCompression {
+	case "", "gzip", "none":
+	default:
+		return fmt.Errorf("invalid index_compression %q (expected gzip or none)", config.Backup.IndexCompression)
+	}
+
+	if config.Backup.RestoreNice < 0 || config.Backup.RestoreNice > 19 {
+		return fmt.Errorf("invalid restore_nice %d (expected 0-19)", config.Backup.RestoreNice)
+	}
+	if err := ValidateIOPriority(config.Backup.RestoreIOPriority); err != nil {
+		return fmt.Errorf("invalid restore_io_priority: %w", err)
+	}
+	if config.Backup.RestoreRateLimit != "" {
+		if _, err := ParseSize(config.Backup.RestoreRateLimit); err != nil {
+			return fmt.Errorf("invalid restore_rate_limit: %w", err)
+		}
+	}
+	if config.Backup.RestoreCacheMaxSize != "" {
+		if _, err := ParseSize(config.Backup.RestoreCacheMaxSize); err != nil {
+			return fmt.Errorf("invalid restore_cache_max_size: %w", err)
+		}
+	}
+	if config.Backup.TempMaxSize != "" {
+		if _, err := ParseSize(config.Backup.TempMaxSize); err != nil {
+			return fmt.Errorf("invalid temp_max_size: %w", err)
+		}
+	}
+
+	if config.Backup.MaxParallelJobs < 0 {
+		return fmt.Errorf("max_parallel_jobs must be 0 (no coordination) or positive")
+	}
+
+	if config.Backup.CircuitBreakerThreshold < 0 || config.Backup.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("circuit breaker threshold and cooldown must be 0 (default) or positive")
+	}
+
+	switch config.Storage.AddressingStyle {
+	case "", "path", "virtual":
+	default:
+		return fmt.Errorf("invalid addressing_style %q (expected path or virtual)", config.Storage.AddressingStyle)
+	}
+
+	switch config.Backup.AnomalyGuard {
+	case "", "warn", "block", "off":
+	default:
+		return fmt.Errorf("invalid anomaly_guard %q (expected warn, block or off)", config.Backup.AnomalyGuard)
+	}
+	if config.Backup.AnomalyThreshold < 0 || config.Backup.AnomalyThreshold > 100 {
+		return fmt.Errorf("anomaly_threshold must be a percentage between 0 (default) and 100")
+	}
+
+	switch config.Backup.ReportFormat {
+	case "", "markdown", "html":
+	default:
+		return fmt.Errorf("invalid report_format %q (expected markdown or html)", config.Backup.ReportFormat)
+	}
+
+	if config.Backup.Catalog && config.Backup.StateDB == "" {
+		return fmt.Errorf("catalog requires state_db (the catalog is stored in the local state database)")
+	}
+
+	if config.Backup.Parity != "" {
+		if _, _, err := ParseParity(config.Backup.Parity); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// ParseParity analyse un schéma de parité "data+parity" (ex. "10+2": une bande de 10 objets de
+// données protégée par 2 objets de parité, jusqu'à 2 pertes reconstructibles par bande)
+func ParseParity(value string) (data, parity int, err error) {
+	dataPart, parityPart, ok := strings.Cut(strings.TrimSpace(value), "+")
+	if ok {
+		data, err = strconv.Atoi(strings.TrimSpace(dataPart))
+		if err == nil {
+			parity, err = strconv.Atoi(strings.TrimSpace(parityPart))
+		}
+	}
+	if !ok || err != nil || data < 1 || parity < 1 || data+parity > 256 {
+		return 0, 0, fmt.Errorf("invalid parity %q (expected data+parity, e.g. 10+2, at most 256 objects)", value)
+	}
+	return data, parity, nil
+}
+
 // WriteConfig écrit une configuration dans un fichier YAML
 func WriteConfig(config *Config, configFile string) error {
 	// Créer une structure temporaire pour l'écriture YAML
@@ -290,8 +572,14 @@ func WriteConfig(config *Config, configFile string) error {
 		AccessKey    string `yaml:"access_key"`
 		SecretKey    string `yaml:"secret_key"`
 		StorageClass string `yaml:"storage_class"`
+		AddressingStyle string `yaml:"addressing_style,omitempty"`
+		Prefix       string `yaml:"prefix,omitempty"`
+		IndexPrefix  string `yaml:"index_prefix,omitempty"`
+		DataPrefix   string `yaml:"data_prefix,omitempty"`
+		OpaqueKeys   bool   `yaml:"opaque_keys,omitempty"`
 		Username     string `yaml:"username"`
 		Password     string `yaml:"password"`
+		Destructive  *DestructiveCredentials `yaml:"destructive,omitempty"`
 	}
 
 	type BackupConfig struct {
@@ -304,27 +592,77 @@ func WriteConfig(config *Config, configFile string) error {
 		BatchSize           int      `yaml:"batch_size"`
 		BatchSizeLimit      string   `yaml:"batch_size_limit"`
 		SkipPatterns        []string `yaml:"skip_patterns"`
+		Sources             []string `yaml:"sources,omitempty"`
 		ChunkSize           string   `yaml:"chunk_size"`
 		MemoryLimit         string   `yaml:"memory_limit"`
 		NetworkTimeout      int      `yaml:"network_timeout"`
 		RetryAttempts       int      `yaml:"retry_attempts"`
 		RetryDelay          int      `yaml:"retry_delay"`
+		RetryFailedFiles    bool     `yaml:"retry_failed_files"`
 		CacheEnabled        bool     `yaml:"cache_enabled"`
 		CacheMaxSize        int      `yaml:"cache_max_size"`
 		CacheMaxAge         int      `yaml:"cache_max_age"`
 		CompressionAdaptive bool     `yaml:"compression_adaptive"`
+		CompressionDictionary bool   `yaml:"compression_dictionary,omitempty"`
 		SortBySize          bool     `yaml:"sort_by_size"`
 		ChunkSizeLarge      string   `yaml:"chunk_size_large"`
 		LargeFileThreshold  string   `yaml:"large_file_threshold"`
 		UltraLargeThreshold string   `yaml:"ultra_large_threshold"`
+		Recipients          []string `yaml:"recipients,omitempty"`
+		IdentityFile        string   `yaml:"identity_file,omitempty"`
+		ErrorPolicy         string   `yaml:"error_policy,omitempty"`
+		PreserveEmptyFiles  bool     `yaml:"preserve_empty_files,omitempty"`
+		PreserveDirectories bool     `yaml:"preserve_directories,omitempty"`
+		OneFileSystem       bool     `yaml:"one_file_system,omitempty"`
+		Symlinks            string   `yaml:"symlinks,omitempty"`
+		HonorIgnoreFiles    bool     `yaml:"honor_ignore_files"`
+		IDTemplate          string   `yaml:"id_template,omitempty"`
+		FileHandlers        []FileHandlerRule `yaml:"file_handlers,omitempty"`
+		ChangedFilePolicy   string   `yaml:"changed_file_policy,omitempty"`
+		MaxFileSize         string   `yaml:"max_file_size,omitempty"`
+		PerFileTimeout      int      `yaml:"per_file_timeout,omitempty"`
+		StallTimeout        int      `yaml:"stall_timeout,omitempty"`
+		CircuitBreakerThreshold int  `yaml:"circuit_breaker_threshold,omitempty"`
+		CircuitBreakerCooldown  int  `yaml:"circuit_breaker_cooldown,omitempty"`
+		AdaptiveConcurrency     bool `yaml:"adaptive_concurrency,omitempty"`
+		IndexCompression    string   `yaml:"index_compression,omitempty"`
+		IndexDeltas         bool     `yaml:"index_deltas,omitempty"`
+		StateDB             string   `yaml:"state_db,omitempty"`
+		Catalog             bool     `yaml:"catalog,omitempty"`
+		AnomalyGuard        string   `yaml:"anomaly_guard,omitempty"`
+		AnomalyThreshold    int      `yaml:"anomaly_threshold,omitempty"`
+		MetadataCache       bool     `yaml:"metadata_cache,omitempty"`
+		MetadataCacheDir    string   `yaml:"metadata_cache_dir,omitempty"`
+		RestoreNice         int      `yaml:"restore_nice,omitempty"`
+		RestoreIOPriority   string   `yaml:"restore_io_priority,omitempty"`
+		RestoreRateLimit    string   `yaml:"restore_rate_limit,omitempty"`
+		RestoreCacheDir     string   `yaml:"restore_cache_dir,omitempty"`
+		RestoreCacheMaxSize string   `yaml:"restore_cache_max_size,omitempty"`
+		RestoreAudit        bool     `yaml:"restore_audit,omitempty"`
+		MaxParallelJobs     int      `yaml:"max_parallel_jobs,omitempty"`
+		JobPriority         int      `yaml:"job_priority,omitempty"`
+		JobQueueDir         string   `yaml:"job_queue_dir,omitempty"`
+		TempDir             string   `yaml:"temp_dir,omitempty"`
+		TempMaxSize         string   `yaml:"temp_max_size,omitempty"`
+		MaxClockSkew        int      `yaml:"max_clock_skew,omitempty"`
+		AppendOnly          bool     `yaml:"append_only,omitempty"`
+		VerifyUploads       bool     `yaml:"verify_uploads,omitempty"`
+		AllowUnencrypted    bool     `yaml:"allow_unencrypted,omitempty"`
+		ReportPath          string   `yaml:"report_path,omitempty"`
+		ReportFormat        string   `yaml:"report_format,omitempty"`
+		ReportUpload        bool     `yaml:"report_upload,omitempty"`
+		Parity              string   `yaml:"parity,omitempty"`
 	}
 
 	type RetentionConfig struct {
 		Days       int `yaml:"days"`
 		MaxBackups int `yaml:"max_backups"`
+		MinBackups int `yaml:"min_backups,omitempty"`
+		MaxTotalSize string `yaml:"max_total_size,omitempty"`
 	}
 
 	type FullConfig struct {
+		Include   []string        `yaml:"include,omitempty"`
 		Storage   StorageConfig   `yaml:"storage"`
 		Backup    BackupConfig    `yaml:"backup"`
 		Retention RetentionConfig `yaml:"retention"`
@@ -332,6 +670,7 @@ func WriteConfig(config *Config, configFile string) error {
 
 	// Créer la configuration complète
 	fullConfig := FullConfig{
+		Include: config.Include,
 		Storage: StorageConfig{
 			Type:         config.Storage.Type,
 			Bucket:       config.Storage.Bucket,
@@ -340,6 +679,11 @@ func WriteConfig(config *Config, configFile string) error {
 			AccessKey:    config.Storage.AccessKey,
 			SecretKey:    config.Storage.SecretKey,
 			StorageClass: config.Storage.StorageClass,
+			AddressingStyle: config.Storage.AddressingStyle,
+			Prefix:       config.Storage.Prefix,
+			IndexPrefix:  config.Storage.IndexPrefix,
+			DataPrefix:   config.Storage.DataPrefix,
+			OpaqueKeys:   config.Storage.OpaqueKeys,
 			Username:     config.Storage.Username,
 			Password:     config.Storage.Password,
 		},
@@ -353,25 +697,78 @@ func WriteConfig(config *Config, configFile string) error {
 			BatchSize:           config.Backup.BatchSize,
 			BatchSizeLimit:      config.Backup.BatchSizeLimit,
 			SkipPatterns:        config.Backup.SkipPatterns,
+			Sources:             config.Backup.Sources,
 			ChunkSize:           config.Backup.ChunkSize,
 			MemoryLimit:         config.Backup.MemoryLimit,
 			NetworkTimeout:      config.Backup.NetworkTimeout,
 			RetryAttempts:       config.Backup.RetryAttempts,
 			RetryDelay:          config.Backup.RetryDelay,
+			RetryFailedFiles:    config.Backup.RetryFailedFiles,
 			CacheEnabled:        config.Backup.CacheEnabled,
 			CacheMaxSize:        config.Backup.CacheMaxSize,
 			CacheMaxAge:         config.Backup.CacheMaxAge,
 			CompressionAdaptive: config.Backup.CompressionAdaptive,
+			CompressionDictionary: config.Backup.CompressionDictionary,
 			SortBySize:          config.Backup.SortBySize,
 			ChunkSizeLarge:      config.Backup.ChunkSizeLarge,
 			LargeFileThreshold:  config.Backup.LargeFileThreshold,
 			UltraLargeThreshold: config.Backup.UltraLargeThreshold,
+			Recipients:          config.Backup.Recipients,
+			IdentityFile:        config.Backup.IdentityFile,
+			ErrorPolicy:         config.Backup.ErrorPolicy,
+			PreserveEmptyFiles:  config.Backup.PreserveEmptyFiles,
+			PreserveDirectories: config.Backup.PreserveDirectories,
+			OneFileSystem:       config.Backup.OneFileSystem,
+			Symlinks:            config.Backup.Symlinks,
+			HonorIgnoreFiles:    config.Backup.HonorIgnoreFiles,
+			IDTemplate:          config.Backup.IDTemplate,
+			FileHandlers:        config.Backup.FileHandlers,
+			ChangedFilePolicy:   config.Backup.ChangedFilePolicy,
+			MaxFileSize:         config.Backup.MaxFileSize,
+			PerFileTimeout:      config.Backup.PerFileTimeout,
+			StallTimeout:        config.Backup.StallTimeout,
+			CircuitBreakerThreshold: config.Backup.CircuitBreakerThreshold,
+			CircuitBreakerCooldown:  config.Backup.CircuitBreakerCooldown,
+			AdaptiveConcurrency:     config.Backup.AdaptiveConcurrency,
+			IndexCompression:    config.Backup.IndexCompression,
+			IndexDeltas:         config.Backup.IndexDeltas,
+			StateDB:             config.Backup.StateDB,
+			Catalog:             config.Backup.Catalog,
+			AnomalyGuard:        config.Backup.AnomalyGuard,
+			AnomalyThreshold:    config.Backup.AnomalyThreshold,
+			MetadataCache:       config.Backup.MetadataCache,
+			MetadataCacheDir:    config.Backup.MetadataCacheDir,
+			RestoreNice:         config.Backup.RestoreNice,
+			RestoreIOPriority:   config.Backup.RestoreIOPriority,
+			RestoreRateLimit:    config.Backup.RestoreRateLimit,
+			RestoreCacheDir:     config.Backup.RestoreCacheDir,
+			RestoreCacheMaxSize: config.Backup.RestoreCacheMaxSize,
+			RestoreAudit:        config.Backup.RestoreAudit,
+			MaxParallelJobs:     config.Backup.MaxParallelJobs,
+			JobPriority:         config.Backup.JobPriority,
+			JobQueueDir:         config.Backup.JobQueueDir,
+			TempDir:             config.Backup.TempDir,
+			TempMaxSize:         config.Backup.TempMaxSize,
+			MaxClockSkew:        config.Backup.MaxClockSkew,
+			AppendOnly:          config.Backup.AppendOnly,
+			VerifyUploads:       config.Backup.VerifyUploads,
+			AllowUnencrypted:    config.Backup.AllowUnencrypted,
+			ReportPath:          config.Backup.ReportPath,
+			ReportFormat:        config.Backup.ReportFormat,
+			ReportUpload:        config.Backup.ReportUpload,
+			Parity:              config.Backup.Parity,
 		},
 		Retention: RetentionConfig{
 			Days:       config.Retention.Days,
 			MaxBackups: config.Retention.MaxBackups,
+			MinBackups: config.Retention.MinBackups,
+			MaxTotalSize: config.Retention.MaxTotalSize,
 		},
 	}
+	if config.Storage.Destructive.IsSet() {
+		destructive := config.Storage.Destructive
+		fullConfig.Storage.Destructive = &destructive
+	}
 
 	// Écrire le fichier YAML
 	data, err := yaml.Marshal(fullConfig)
@@ -381,3 +778,42 @@ func WriteConfig(config *Config, configFile string) error {
 
 	return os.WriteFile(configFile, data, 0600)
 }
+
+// ResolveSources rend absolus les chemins sources d'une sauvegarde et refuse les doublons et les
+// sources imbriquées (sauvegardées deux fois)
+func ResolveSources(sources []string) ([]string, error) {
+	resolved := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if strings.TrimSpace(source) == "" {
+			return nil, fmt.Errorf("empty source path in sources")
+		}
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source path %q: %w", source, err)
+		}
+		for _, other := range resolved {
+			if abs == other || strings.HasPrefix(abs, strings.TrimRight(other, string(filepath.Separator))+string(filepath.Separator)) ||
+				strings.HasPrefix(other, strings.TrimRight(abs, string(filepath.Separator))+string(filepath.Separator)) {
+				return nil, fmt.Errorf("sources %s and %s overlap", other, abs)
+			}
+		}
+		resolved = append(resolved, abs)
+	}
+	return resolved, nil
+}
+
+// validateIDTemplate vérifie un modèle d'ID de sauvegarde: l'horodatage doit terminer l'ID
+// (tri et rétention), et seules les variables connues sont acceptées
+func validateIDTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.HasSuffix(template, "-{date}") || strings.Count(template, "{date}") != 1 {
+		return fmt.Errorf("invalid id_template %q (must end with -{date})", template)
+	}
+	rest := strings.NewReplacer("{name}", "", "{hostname}", "", "{date}", "").Replace(template)
+	if strings.ContainsAny(rest, "{}/\\ ") {
+		return fmt.Errorf("invalid id_template %q (supported variables: {name}, {hostname}, {date}; no spaces or slashes)", template)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateConfigValues modifie des clés (ex: "backup.compression_level") dans un fichier de configuration
+// en conservant les commentaires et l'ordre des clés; les clés absentes sont ajoutées à leur section
+func UpdateConfigValues(configFile string, values map[string]string) error {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("%w: invalid YAML in %s: %w", ErrConfig, configFile, err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: %s is not a YAML mapping", ErrConfig, configFile)
+	}
+
+	for key, value := range values {
+		setConfigNode(document.Content[0], strings.Split(key, "."), value)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("error encoding configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("error encoding configuration: %w", err)
+	}
+
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, buf.Bytes(), info.Mode().Perm())
+}
+
+// setConfigNode affecte une valeur scalaire au chemin donné, en créant les sections manquantes
+func setConfigNode(mapping *yaml.Node, path []string, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+		node := mapping.Content[i+1]
+		if len(path) == 1 {
+			node.Kind, node.Tag, node.Value, node.Style = yaml.ScalarNode, "", value, 0
+			node.Content = nil
+			return
+		}
+		if node.Kind != yaml.MappingNode {
+			node.Kind, node.Tag, node.Value, node.Content = yaml.MappingNode, "!!map", "", nil
+		}
+		setConfigNode(node, path[1:], value)
+		return
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}
+	if len(path) == 1 {
+		mapping.Content = append(mapping.Content, keyNode, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+		return
+	}
+	section := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	mapping.Content = append(mapping.Content, keyNode, section)
+	setConfigNode(section, path[1:], value)
+}
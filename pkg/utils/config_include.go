@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigIncludes retourne les fichiers inclus directement par un fichier de configuration (include:),
+// chemins relatifs résolus depuis le répertoire du fichier
+func ConfigIncludes(configFile string) ([]string, error) {
+	document, err := readConfigMap(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return includePaths(configFile, document)
+}
+
+// resolveIncludes retourne la configuration de configFile fusionnée avec ses fichiers inclus:
+// chaque fichier inclus sert de base dans l'ordre de la liste (les suivants l'emportent) et
+// configFile l'emporte sur tous. Les sections sont fusionnées clé par clé, les listes et valeurs
+// remplacées. Les inclusions sont résolues récursivement; une inclusion circulaire est refusée.
+func resolveIncludes(configFile string, chain []string) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, seen := range chain {
+		if seen == absPath {
+			return nil, fmt.Errorf("circular include: %s", strings.Join(append(chain, absPath), " -> "))
+		}
+	}
+	chain = append(chain, absPath)
+
+	document, err := readConfigMap(configFile)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := includePaths(configFile, document)
+	if err != nil {
+		return nil, err
+	}
+	delete(document, "include")
+
+	merged := make(map[string]interface{})
+	for _, include := range includes {
+		base, err := resolveIncludes(include, chain)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigMaps(merged, base)
+	}
+	mergeConfigMaps(merged, document)
+	return merged, nil
+}
+
+// readConfigMap lit un fichier de configuration YAML sous forme de map
+func readConfigMap(configFile string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	document := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %w", configFile, err)
+	}
+	return document, nil
+}
+
+// includePaths lit la clé include (un chemin ou une liste de chemins) d'un document
+func includePaths(configFile string, document map[string]interface{}) ([]string, error) {
+	var values []interface{}
+	switch include := document["include"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		values = []interface{}{include}
+	case []interface{}:
+		values = include
+	default:
+		return nil, fmt.Errorf("invalid include in %s: expected a path or a list of paths", configFile)
+	}
+
+	paths := make([]string, 0, len(values))
+	for _, value := range values {
+		path, ok := value.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid include in %s: expected a path or a list of paths", configFile)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configFile), path)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// mergeConfigMaps fusionne src dans dst: les sections communes sont fusionnées, le reste remplacé
+func mergeConfigMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		section, ok := value.(map[string]interface{})
+		existing, isMap := dst[key].(map[string]interface{})
+		if ok && isMap {
+			mergeConfigMaps(existing, section)
+			continue
+		}
+		dst[key] = value
+	}
+}
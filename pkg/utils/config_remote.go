@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variables d'environnement de la configuration distante (--config https://...)
+const (
+	ConfigAuthEnv     = "BCRDF_CONFIG_AUTH"      // Valeur de l'en-tête Authorization (ex: "Bearer <token>")
+	ConfigCacheDirEnv = "BCRDF_CONFIG_CACHE_DIR" // Répertoire du cache local (défaut: cache utilisateur/bcrdf/config)
+)
+
+// remoteConfigTimeout borne le téléchargement d'une configuration distante
+const remoteConfigTimeout = 15 * time.Second
+
+// maxRemoteConfigSize limite la taille d'une configuration distante
+const maxRemoteConfigSize = 1 << 20
+
+// IsRemoteConfig indique si le chemin de configuration est une URL http(s)
+func IsRemoteConfig(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// FetchRemoteConfig télécharge une configuration distante dans le cache local et retourne le chemin
+// du fichier en cache. Si le serveur est injoignable ou répond une erreur, la dernière copie en cache
+// est utilisée avec un avertissement; sans copie en cache, l'erreur est retournée (ErrConfig).
+func FetchRemoteConfig(url string) (string, error) {
+	cachePath, err := remoteConfigCachePath(url)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrConfig, err)
+	}
+
+	data, fetchErr := downloadRemoteConfig(url)
+	if fetchErr == nil {
+		if err := writeFileAtomic(cachePath, data, 0600); err != nil {
+			return "", fmt.Errorf("%w: cannot cache remote configuration %s: %w", ErrConfig, url, err)
+		}
+		Debug("Remote configuration %s cached in %s", url, cachePath)
+		return cachePath, nil
+	}
+
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot fetch remote configuration %s and no cached copy: %w", ErrConfig, url, fetchErr)
+	}
+	// Toujours affiché: une configuration périmée doit se voir, même sans --verbose
+	ProgressWarning(fmt.Sprintf("Cannot fetch remote configuration %s (%v), using cached copy from %s",
+		url, fetchErr, info.ModTime().Format("2006-01-02 15:04:05")))
+	return cachePath, nil
+}
+
+// downloadRemoteConfig télécharge la configuration et vérifie qu'il s'agit d'un document YAML
+// (une page d'erreur ne doit jamais remplacer la copie en cache)
+func downloadRemoteConfig(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth := os.Getenv(ConfigAuthEnv); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	client := &http.Client{Timeout: remoteConfigTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRemoteConfigSize {
+		return nil, fmt.Errorf("configuration larger than %s", FormatBytes(maxRemoteConfigSize))
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil || len(document) == 0 {
+		return nil, fmt.Errorf("response is not a YAML configuration")
+	}
+	return data, nil
+}
+
+// remoteConfigCachePath retourne le fichier de cache d'une URL de configuration
+func remoteConfigCachePath(url string) (string, error) {
+	dir := os.Getenv(ConfigCacheDirEnv)
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "bcrdf", "config")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("error creating configuration cache: %w", err)
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".yaml"), nil
+}
+
+// writeFileAtomic écrit data dans path via un fichier temporaire renommé
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".bcrdf-config-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// errDiskUsageUnsupported signale une plateforme sans statistiques de système de fichiers
+var errDiskUsageUnsupported = errors.New("filesystem usage is not available on this platform")
+
+// DiskUsage décrit l'espace et les inodes d'un système de fichiers (Files = 0: inodes inconnus)
+type DiskUsage struct {
+	Total     uint64 // Octets au total
+	Free      uint64 // Octets disponibles pour un utilisateur non privilégié
+	Files     uint64 // Inodes au total
+	FreeFiles uint64 // Inodes libres
+}
+
+// UsedPercent retourne le taux d'occupation de l'espace, en pourcentage
+func (u DiskUsage) UsedPercent() float64 {
+	if u.Total == 0 {
+		return 0
+	}
+	return float64(u.Total-u.Free) * 100 / float64(u.Total)
+}
+
+// UsedFilesPercent retourne le taux d'occupation des inodes, en pourcentage
+func (u DiskUsage) UsedFilesPercent() float64 {
+	if u.Files == 0 {
+		return 0
+	}
+	return float64(u.Files-u.FreeFiles) * 100 / float64(u.Files)
+}
+
+// DiskUsageOf retourne l'occupation du système de fichiers contenant path
+// (le parent existant le plus proche si path n'existe pas encore)
+func DiskUsageOf(path string) (DiskUsage, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return DiskUsage{}, err
+	}
+	for {
+		if _, err := os.Stat(LongPath(path)); err == nil {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return diskUsage(path)
+}
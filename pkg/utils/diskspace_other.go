@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package utils
+
+// diskUsage n'est pas disponible sur cette plateforme
+func diskUsage(path string) (DiskUsage, error) {
+	return DiskUsage{}, errDiskUsageUnsupported
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd
+
+package utils
+
+import "syscall"
+
+// diskUsage interroge statfs
+func diskUsage(path string) (DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return DiskUsage{}, err
+	}
+	blockSize := uint64(stat.Bsize)
+	return DiskUsage{
+		Total:     uint64(stat.Blocks) * blockSize,
+		Free:      uint64(stat.Bavail) * blockSize,
+		Files:     uint64(stat.Files),
+		FreeFiles: uint64(stat.Ffree),
+	}, nil
+}
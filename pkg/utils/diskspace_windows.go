@@ -0,0 +1,25 @@
+//go:build windows
+
+package utils
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// diskUsage interroge GetDiskFreeSpaceEx (NTFS n'a pas de limite d'inodes: Files reste à 0)
+func diskUsage(path string) (DiskUsage, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return DiskUsage{}, err
+	}
+	var free, total, totalFree uint64
+	ret, _, err := procGetDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(&free)), uintptr(unsafe.Pointer(&total)), uintptr(unsafe.Pointer(&totalFree)))
+	if ret == 0 {
+		return DiskUsage{}, err
+	}
+	return DiskUsage{Total: total, Free: free}, nil
+}
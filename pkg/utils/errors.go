@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Codes de sortie documentés de la CLI
+const (
+	ExitOK                 = 0
+	ExitGenericError       = 1
+	ExitConfigError        = 2
+	ExitStorageUnreachable = 3
+	ExitPartialFailure     = 4
+	ExitVerificationFailed = 5
+	ExitLockConflict       = 6
+	ExitAnomalyDetected    = 7
+	ExitInsufficientSpace  = 8
+)
+
+// Erreurs sentinelles permettant de distinguer la classe d'un échec (errors.Is)
+var (
+	ErrConfig             = errors.New("configuration error")
+	ErrStorageUnreachable = errors.New("storage unreachable")
+	ErrPartialFailure     = errors.New("partial failure")
+	ErrVerificationFailed = errors.New("verification failed")
+	ErrLockConflict       = errors.New("lock conflict")
+	ErrAnomalyDetected    = errors.New("anomaly detected")
+	ErrAppendOnly         = errors.New("repository is append-only")
+	ErrLegalHold          = errors.New("backup under legal hold")
+	ErrInsufficientSpace  = errors.New("insufficient disk space")
+)
+
+// ExitCode retourne le code de sortie correspondant à la classe d'une erreur
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrConfig), errors.Is(err, ErrAppendOnly), errors.Is(err, ErrLegalHold):
+		return ExitConfigError
+	case errors.Is(err, ErrStorageUnreachable):
+		return ExitStorageUnreachable
+	case errors.Is(err, ErrPartialFailure):
+		return ExitPartialFailure
+	case errors.Is(err, ErrVerificationFailed):
+		return ExitVerificationFailed
+	case errors.Is(err, ErrLockConflict):
+		return ExitLockConflict
+	case errors.Is(err, ErrAnomalyDetected):
+		return ExitAnomalyDetected
+	case errors.Is(err, ErrInsufficientSpace):
+		return ExitInsufficientSpace
+	default:
+		return ExitGenericError
+	}
+}
+
+// Modes de la politique d'erreurs d'une sauvegarde
+const (
+	ErrorPolicyFail      = "fail"
+	ErrorPolicyContinue  = "continue"
+	ErrorPolicyThreshold = "threshold"
+)
+
+// ErrorPolicy décrit le comportement d'une sauvegarde lorsque des fichiers échouent
+type ErrorPolicy struct {
+	Mode      string
+	Threshold float64 // pourcentage maximum de fichiers en échec (mode threshold)
+}
+
+// ParseErrorPolicy analyse une politique "fail", "continue" ou "threshold=N%" (vide = continue)
+func ParseErrorPolicy(value string) (ErrorPolicy, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	switch {
+	case value == "" || value == ErrorPolicyContinue:
+		return ErrorPolicy{Mode: ErrorPolicyContinue}, nil
+	case value == ErrorPolicyFail:
+		return ErrorPolicy{Mode: ErrorPolicyFail}, nil
+	case strings.HasPrefix(value, ErrorPolicyThreshold+"="):
+		raw := strings.TrimSuffix(strings.TrimPrefix(value, ErrorPolicyThreshold+"="), "%")
+		threshold, err := strconv.ParseFloat(raw, 64)
+		if err != nil || threshold < 0 || threshold > 100 {
+			return ErrorPolicy{}, fmt.Errorf("invalid error policy threshold %q (expected 0-100%%)", value)
+		}
+		return ErrorPolicy{Mode: ErrorPolicyThreshold, Threshold: threshold}, nil
+	default:
+		return ErrorPolicy{}, fmt.Errorf("invalid error policy %q (expected fail, continue or threshold=N%%)", value)
+	}
+}
+
+// Exceeded indique si le nombre d'échecs viole la politique
+func (p ErrorPolicy) Exceeded(failed, total int) bool {
+	if failed == 0 {
+		return false
+	}
+	switch p.Mode {
+	case ErrorPolicyFail:
+		return true
+	case ErrorPolicyThreshold:
+		return total > 0 && float64(failed)*100/float64(total) > p.Threshold
+	default:
+		return false
+	}
+}
+
+// String retourne la représentation textuelle de la politique
+func (p ErrorPolicy) String() string {
+	if p.Mode == ErrorPolicyThreshold {
+		return fmt.Sprintf("threshold=%g%%", p.Threshold)
+	}
+	return p.Mode
+}
+
+// CheckDeletesAllowed refuse une opération destructive quand le dépôt est en mode append-only
+func CheckDeletesAllowed(config *Config, operation string) error {
+	if config != nil && config.Backup.AppendOnly {
+		return fmt.Errorf("%w: %s is disabled (backup.append_only), prune from a trusted instance", ErrAppendOnly, operation)
+	}
+	return nil
+}
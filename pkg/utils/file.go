@@ -34,6 +34,16 @@ func WriteFile(path string, data []byte) error {
 	return nil
 }
 
+// ReadFileHead lit au plus limit octets au début d'un fichier (échantillons de compression)
+func ReadFileHead(path string, limit int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, limit))
+}
+
 // FileExists vérifie si un fichier existe
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
@@ -69,7 +79,7 @@ func EnsureDirectory(path string) error {
 // RemoveFile supprime un fichier
 func RemoveFile(path string) error {
 	if err := os.Remove(path); err != nil {
-		return fmt.Errorf("error during la suppression du fichier %s: %w", path, err)
+		return fmt.Errorf("error deleting file %s: %w", path, err)
 	}
 	return nil
 }
@@ -202,3 +212,53 @@ func ParseBufferSize(sizeStr string) (int, error) {
 
 	return result, nil
 }
+
+// ParseSize parse une chaîne de taille (e.g., "50MB", "1GB") en bytes
+func ParseSize(sizeStr string) (int64, error) {
+	sizeStr = strings.TrimSpace(sizeStr)
+
+	// Si c'est déjà un nombre, le traiter comme des bytes
+	if number, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
+		return number, nil
+	}
+
+	// Extraire le nombre et l'unité
+	var number int64
+	var unit string
+
+	// Trouver le premier caractère non-numérique
+	for i := 0; i < len(sizeStr); i++ {
+		if sizeStr[i] < '0' || sizeStr[i] > '9' {
+			numberStr := sizeStr[:i]
+			unit = sizeStr[i:]
+
+			var err error
+			number, err = strconv.ParseInt(numberStr, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid number in size string: %s", sizeStr)
+			}
+			break
+		}
+	}
+
+	if number == 0 {
+		return 0, fmt.Errorf("no number found in size string: %s", sizeStr)
+	}
+
+	// Convertir selon l'unité
+	unit = strings.ToUpper(strings.TrimSpace(unit))
+	switch unit {
+	case "B", "":
+		return number, nil
+	case "KB":
+		return number * 1024, nil
+	case "MB":
+		return number * 1024 * 1024, nil
+	case "GB":
+		return number * 1024 * 1024 * 1024, nil
+	case "TB":
+		return number * 1024 * 1024 * 1024 * 1024, nil
+	default:
+		return 0, fmt.Errorf("unknown unit in size string: %s", sizeStr)
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Langues des messages de l'interface (catalogue messages.go)
+const (
+	LocaleEnglish = "en"
+	LocaleFrench  = "fr"
+)
+
+// LocaleEnv choisit la langue des messages, prioritaire sur LC_ALL, LC_MESSAGES et LANG
+const LocaleEnv = "BCRDF_LANG"
+
+// locale est la langue active, détectée depuis l'environnement au démarrage
+var locale = DetectLocale()
+
+// DetectLocale retourne la langue demandée par l'environnement (anglais par défaut)
+func DetectLocale() string {
+	for _, name := range []string{LocaleEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if lang := normalizeLocale(value); lang != "" {
+			return lang
+		}
+		return LocaleEnglish
+	}
+	return LocaleEnglish
+}
+
+// SetLocale change la langue des messages (--lang)
+func SetLocale(lang string) error {
+	normalized := normalizeLocale(lang)
+	if normalized == "" {
+		return fmt.Errorf("%w: unsupported language %q (available: %s)", ErrConfig, lang, strings.Join(Locales(), ", "))
+	}
+	locale = normalized
+	return nil
+}
+
+// Locale retourne la langue active
+func Locale() string {
+	return locale
+}
+
+// Locales retourne les langues disponibles
+func Locales() []string {
+	return []string{LocaleEnglish, LocaleFrench}
+}
+
+// normalizeLocale réduit une locale (fr_FR.UTF-8, en-US, FR) à une langue du catalogue ("" si inconnue)
+func normalizeLocale(value string) string {
+	lang := strings.ToLower(value)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case LocaleEnglish, LocaleFrench:
+		return lang
+	case "c", "posix":
+		return LocaleEnglish
+	}
+	return ""
+}
+
+// Msg retourne le message key du catalogue dans la langue active, formaté avec args.
+// Un message absent de la langue active est pris en anglais; une clé inconnue est retournée telle quelle.
+func Msg(key string, args ...interface{}) string {
+	translations, ok := messages[key]
+	if !ok {
+		return key
+	}
+	format, ok := translations[locale]
+	if !ok {
+		format = translations[LocaleEnglish]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -4,28 +4,59 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 )
 
 var (
-	logLevel = "info"
+	// Par défaut seuls les avertissements et erreurs sont journalisés (-v: info, -vv: debug)
+	logLevel = "warn"
 	logger   *log.Logger
+	// quiet supprime tout sauf les erreurs (--quiet, pour cron)
+	quiet bool
 )
 
 func init() {
 	logger = log.New(os.Stdout, "", log.LstdFlags)
 }
 
+// LogToStderr envoie les journaux sur stderr, quand stdout porte des données (export -o -)
+func LogToStderr() {
+	logger.SetOutput(os.Stderr)
+}
+
 // SetLogLevel définit le niveau de log
 func SetLogLevel(level string) {
 	logLevel = level
 }
 
+// SetVerbosity fixe le niveau de log depuis la ligne de commande: --quiet n'affiche que les
+// erreurs (ni messages de progression ni barres), sinon 0 = warn, 1 (-v) = info, 2+ (-vv) = debug
+func SetVerbosity(level int, quietMode bool) {
+	quiet = quietMode
+	switch {
+	case quietMode:
+		logLevel = "error"
+		DisableProgress()
+	case level >= 2:
+		logLevel = "debug"
+	case level == 1:
+		logLevel = "info"
+	default:
+		logLevel = "warn"
+	}
+}
+
+// IsQuiet indique si --quiet est actif
+func IsQuiet() bool {
+	return quiet
+}
+
 // logWithLevel affiche un message selon le niveau de log
 func logWithLevel(level, message string) {
 	if shouldLog(level) {
 		timestamp := time.Now().Format("2006-01-02 15:04:05")
-		logger.Printf("[%s] %s: %s", timestamp, level, message)
+		logger.Printf("[%s] %s%s: %s", timestamp, operationTag(), level, message)
 	}
 }
 
@@ -39,7 +70,7 @@ func shouldLog(level string) bool {
 	}
 
 	currentLevel := levels[logLevel]
-	messageLevel := levels[level]
+	messageLevel := levels[strings.ToLower(level)]
 
 	return messageLevel >= currentLevel
 }
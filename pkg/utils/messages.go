@@ -0,0 +1,244 @@
+package utils
+
+// messages est le catalogue des messages de l'interface (étapes, résultats, bannières) par langue.
+// Les journaux (-v, -vv) et les messages d'erreur restent en anglais pour être cherchables.
+// Toute langue ajoutée ici doit l'être aussi dans normalizeLocale et Locales.
+var messages = map[string]map[string]string{
+	// Interruption (Ctrl+C)
+	"interrupt.detected": {
+		LocaleEnglish: "\n⚠️  Interruption detected. Finishing current operation...",
+		LocaleFrench:  "\n⚠️  Interruption détectée. Fin de l'opération en cours...",
+	},
+	"interrupt.force_hint": {
+		LocaleEnglish: "   Press Ctrl+C again to force exit.",
+		LocaleFrench:  "   Appuyez à nouveau sur Ctrl+C pour forcer l'arrêt.",
+	},
+	"interrupt.forced": {
+		LocaleEnglish: "\n🛑 Force exit.",
+		LocaleFrench:  "\n🛑 Arrêt forcé.",
+	},
+	"progress.below": {
+		LocaleEnglish: "📊 Progress will be displayed below:",
+		LocaleFrench:  "📊 La progression s'affiche ci-dessous :",
+	},
+
+	// Sauvegarde
+	"backup.starting": {
+		LocaleEnglish: "🚀 Starting backup: %s -> %s",
+		LocaleFrench:  "🚀 Démarrage de la sauvegarde : %s -> %s",
+	},
+	"backup.failed": {
+		LocaleEnglish: "❌ Backup failed: %v",
+		LocaleFrench:  "❌ Échec de la sauvegarde : %v",
+	},
+	"backup.succeeded": {
+		LocaleEnglish: "✅ Backup completed successfully!",
+		LocaleFrench:  "✅ Sauvegarde terminée avec succès !",
+	},
+	"backup.step.start": {
+		LocaleEnglish: "🔄 🚀 Starting backup: %s",
+		LocaleFrench:  "🔄 🚀 Démarrage de la sauvegarde : %s",
+	},
+	"backup.step.create_index": {
+		LocaleEnglish: "Creating index...",
+		LocaleFrench:  "Création de l'index...",
+	},
+	"backup.step.find_previous": {
+		LocaleEnglish: "Searching for previous backup...",
+		LocaleFrench:  "Recherche de la sauvegarde précédente...",
+	},
+	"backup.step.first_backup": {
+		LocaleEnglish: "First backup - performing full backup",
+		LocaleFrench:  "Première sauvegarde : sauvegarde complète",
+	},
+	"backup.step.compare": {
+		LocaleEnglish: "Comparing indexes...",
+		LocaleFrench:  "Comparaison des index...",
+	},
+	"backup.step.finalize": {
+		LocaleEnglish: "Finalizing backup...",
+		LocaleFrench:  "Finalisation de la sauvegarde...",
+	},
+	"backup.step.nothing": {
+		LocaleEnglish: "No files to backup",
+		LocaleFrench:  "Aucun fichier à sauvegarder",
+	},
+	"backup.step.nothing_skip": {
+		LocaleEnglish: "No files to backup, skipping backup creation",
+		LocaleFrench:  "Aucun fichier à sauvegarder, sauvegarde non créée",
+	},
+	"backup.step.failed_files": {
+		LocaleEnglish: "%d files failed (recorded in index)",
+		LocaleFrench:  "%d fichiers en échec (consignés dans l'index)",
+	},
+	"backup.step.retention_applied": {
+		LocaleEnglish: "Retention policy applied",
+		LocaleFrench:  "Politique de rétention appliquée",
+	},
+	"backup.step.retention_failed": {
+		LocaleEnglish: "Retention policy failed, but backup completed",
+		LocaleFrench:  "Échec de la politique de rétention, la sauvegarde est terminée",
+	},
+	"backup.step.done": {
+		LocaleEnglish: "✅ Backup completed in %v",
+		LocaleFrench:  "✅ Sauvegarde terminée en %v",
+	},
+	"backup.step.summary": {
+		LocaleEnglish: "📊 %d added, %d modified, %d deleted",
+		LocaleFrench:  "📊 %d ajoutés, %d modifiés, %d supprimés",
+	},
+
+	// Parcours de la source
+	"scan.full": {
+		LocaleEnglish: "🔄 Analyzing directory (full integrity)...",
+		LocaleFrench:  "🔄 Analyse du répertoire (intégrité complète)...",
+	},
+	"scan.fast": {
+		LocaleEnglish: "🔄 Analyzing directory (fast mode)...",
+		LocaleFrench:  "🔄 Analyse du répertoire (mode rapide)...",
+	},
+	"scan.metadata": {
+		LocaleEnglish: "🔄 Analyzing directory (metadata only)...",
+		LocaleFrench:  "🔄 Analyse du répertoire (métadonnées seulement)...",
+	},
+	"scan.mtime_size": {
+		LocaleEnglish: "🔄 Analyzing directory (size and mtime only, reduced safety)...",
+		LocaleFrench:  "🔄 Analyse du répertoire (taille et date seulement, sûreté réduite)...",
+	},
+	"scan.default": {
+		LocaleEnglish: "🔄 Analyzing directory...",
+		LocaleFrench:  "🔄 Analyse du répertoire...",
+	},
+	"scan.done": {
+		LocaleEnglish: "Index created with %d files",
+		LocaleFrench:  "Index créé avec %d fichiers",
+	},
+
+	// Restauration
+	"restore.starting": {
+		LocaleEnglish: "🔄 Starting restore: %s -> %s",
+		LocaleFrench:  "🔄 Démarrage de la restauration : %s -> %s",
+	},
+	"restore.failed": {
+		LocaleEnglish: "❌ Restore failed: %v",
+		LocaleFrench:  "❌ Échec de la restauration : %v",
+	},
+	"restore.succeeded": {
+		LocaleEnglish: "✅ Restore completed successfully!",
+		LocaleFrench:  "✅ Restauration terminée avec succès !",
+	},
+	"restore.step.start": {
+		LocaleEnglish: "🔄 🚀 Starting restore: %s",
+		LocaleFrench:  "🔄 🚀 Démarrage de la restauration : %s",
+	},
+	"restore.step.load_index": {
+		LocaleEnglish: "Loading index...",
+		LocaleFrench:  "Chargement de l'index...",
+	},
+	"restore.step.prepare": {
+		LocaleEnglish: "Preparing destination directory...",
+		LocaleFrench:  "Préparation du répertoire de destination...",
+	},
+	"restore.step.files": {
+		LocaleEnglish: "Restoring %d files to: %s",
+		LocaleFrench:  "Restauration de %d fichiers vers : %s",
+	},
+	"restore.step.done": {
+		LocaleEnglish: "✅ Restore completed successfully to: %s",
+		LocaleFrench:  "✅ Restauration terminée avec succès vers : %s",
+	},
+	"sync.step.start": {
+		LocaleEnglish: "🔄 Syncing %s with backup %s",
+		LocaleFrench:  "🔄 Synchronisation de %s avec la sauvegarde %s",
+	},
+	"sync.step.done": {
+		LocaleEnglish: "✅ %s in sync with %s (%d extraneous entries removed)",
+		LocaleFrench:  "✅ %s synchronisé avec %s (%d entrées en trop supprimées)",
+	},
+
+	// Suppression et nettoyage
+	"delete.starting": {
+		LocaleEnglish: "🗑️  Starting deletion of backup: %s",
+		LocaleFrench:  "🗑️  Suppression de la sauvegarde : %s",
+	},
+	"delete.failed": {
+		LocaleEnglish: "❌ Deletion failed: %v",
+		LocaleFrench:  "❌ Échec de la suppression : %v",
+	},
+	"delete.succeeded": {
+		LocaleEnglish: "✅ Backup deleted successfully!",
+		LocaleFrench:  "✅ Sauvegarde supprimée avec succès !",
+	},
+	"clean.starting_all": {
+		LocaleEnglish: "🧹 Starting cleanup of all backups",
+		LocaleFrench:  "🧹 Nettoyage de toutes les sauvegardes",
+	},
+	"clean.starting": {
+		LocaleEnglish: "🧹 Starting cleanup of backup: %s",
+		LocaleFrench:  "🧹 Nettoyage de la sauvegarde : %s",
+	},
+	"clean.dry_run": {
+		LocaleEnglish: "🔍 Dry run mode - no files will be deleted",
+		LocaleFrench:  "🔍 Simulation : aucun fichier ne sera supprimé",
+	},
+	"clean.failed": {
+		LocaleEnglish: "❌ Cleanup failed: %v",
+		LocaleFrench:  "❌ Échec du nettoyage : %v",
+	},
+	"clean.succeeded": {
+		LocaleEnglish: "✅ Cleanup completed successfully!",
+		LocaleFrench:  "✅ Nettoyage terminé avec succès !",
+	},
+
+	// Rétention
+	"retention.starting": {
+		LocaleEnglish: "🧹 Starting retention policy management",
+		LocaleFrench:  "🧹 Application de la politique de rétention",
+	},
+	"retention.failed": {
+		LocaleEnglish: "❌ Retention policy failed: %v",
+		LocaleFrench:  "❌ Échec de la politique de rétention : %v",
+	},
+	"retention.succeeded": {
+		LocaleEnglish: "✅ Retention policy applied successfully!",
+		LocaleFrench:  "✅ Politique de rétention appliquée avec succès !",
+	},
+	"retention.step.start": {
+		LocaleEnglish: "🧹 Applying retention policy",
+		LocaleFrench:  "🧹 Application de la politique de rétention",
+	},
+	"retention.step.nothing": {
+		LocaleEnglish: "No backups to clean up",
+		LocaleFrench:  "Aucune sauvegarde à supprimer",
+	},
+	"retention.step.satisfied": {
+		LocaleEnglish: "Retention policy satisfied",
+		LocaleFrench:  "Politique de rétention respectée",
+	},
+	"retention.step.delete": {
+		LocaleEnglish: "Deleting backup: %s",
+		LocaleFrench:  "Suppression de la sauvegarde : %s",
+	},
+	"retention.step.partial": {
+		LocaleEnglish: "Deleted %d backups with %d errors",
+		LocaleFrench:  "%d sauvegardes supprimées, %d erreurs",
+	},
+	"retention.step.done": {
+		LocaleEnglish: "Retention cleanup: %d backups deleted",
+		LocaleFrench:  "Rétention : %d sauvegardes supprimées",
+	},
+
+	// Santé et configuration
+	"health.step.start": {
+		LocaleEnglish: "🏥 Checking backup health",
+		LocaleFrench:  "🏥 Vérification de l'état des sauvegardes",
+	},
+	"config.step.validate": {
+		LocaleEnglish: "Validating configuration...",
+		LocaleFrench:  "Validation de la configuration...",
+	},
+	"config.step.valid": {
+		LocaleEnglish: "Configuration validated successfully",
+		LocaleFrench:  "Configuration validée",
+	},
+}
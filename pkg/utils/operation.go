@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// OperationIDEnv permet à un orchestrateur d'imposer l'identifiant d'opération (corrélation multi-agents)
+const OperationIDEnv = "BCRDF_OPERATION_ID"
+
+// operationID identifie l'exécution en cours dans les logs et les index (vide = aucune opération)
+var operationID string
+
+// StartOperation attribue l'identifiant de l'exécution en cours (BCRDF_OPERATION_ID s'il est défini)
+func StartOperation() string {
+	operationID = os.Getenv(OperationIDEnv)
+	if operationID == "" {
+		operationID = NewOperationID()
+	}
+	return operationID
+}
+
+// OperationID retourne l'identifiant de l'exécution en cours
+func OperationID() string {
+	return operationID
+}
+
+// NewOperationID génère un identifiant d'opération aléatoire (12 caractères hexadécimaux)
+func NewOperationID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "000000000000"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// operationTag préfixe les messages avec l'identifiant d'opération
+func operationTag() string {
+	if operationID == "" {
+		return ""
+	}
+	return "[op " + operationID + "] "
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Filtres de sortie pour les terminaux et outils de ticketing qui altèrent les emoji
+const (
+	filterNone    = iota
+	filterNoEmoji // --no-emoji: emoji remplacés par des balises ([OK], [WARN]...) ou supprimés
+	filterASCII   // --ascii: en plus, tout caractère non ASCII est translittéré (accents, barres)
+)
+
+// emojiTags remplace les emoji porteurs de sens par une balise texte
+var emojiTags = map[rune]string{
+	'✅': "[OK]",
+	'✔': "[OK]",
+	'❌': "[ERROR]",
+	'✖': "[ERROR]",
+	'⚠': "[WARN]",
+	'ℹ': "[INFO]",
+	'🛑': "[STOP]",
+}
+
+// asciiReplacements translittère les caractères non ASCII courants de la sortie (mode --ascii)
+var asciiReplacements = map[rune]string{
+	'█': "#", '▓': "#", '▒': "-", '░': "-",
+	'─': "-", '━': "-", '═': "=", '│': "|", '┃': "|",
+	'┌': "+", '┐': "+", '└': "+", '┘': "+", '├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'•': "*", '·': ".", '…': "...", '→': "->", '←': "<-", '⇒': "=>", '×': "x", '≈': "~",
+	'«': "\"", '»': "\"", '“': "\"", '”': "\"", '‘': "'", '’': "'", '–': "-", '—': "-",
+	'\u00a0': " ", '\u202f': " ", // Espaces insécables
+	'à': "a", 'â': "a", 'ä': "a", 'á': "a", 'À': "A", 'Â': "A", 'Ä': "A",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'É': "E", 'È': "E", 'Ê': "E", 'Ë': "E",
+	'î': "i", 'ï': "i", 'í': "i", 'Î': "I", 'Ï': "I",
+	'ô': "o", 'ö': "o", 'ó': "o", 'Ô': "O", 'Ö': "O",
+	'ù': "u", 'û': "u", 'ü': "u", 'ú': "u", 'Ù': "U", 'Û': "U", 'Ü': "U",
+	'ç': "c", 'Ç': "C", 'ñ': "n", 'Ñ': "N", 'ÿ': "y", 'œ': "oe", 'Œ': "OE", 'æ': "ae", 'Æ': "AE",
+}
+
+var (
+	outputFilter   = filterNone
+	filterDone     sync.WaitGroup
+	originalStdout *os.File
+	originalStderr *os.File
+)
+
+// EnableOutputFilter filtre toute la sortie du processus (stdout, stderr, journaux, barres):
+// asciiOnly correspond à --ascii, sinon seuls les emoji sont retirés (--no-emoji).
+// La sortie passe par des tubes relus au fil de l'eau; CloseOutput doit être appelé avant de quitter.
+func EnableOutputFilter(asciiOnly bool) {
+	if outputFilter != filterNone {
+		return
+	}
+	outputFilter = filterNoEmoji
+	if asciiOnly {
+		outputFilter = filterASCII
+	}
+	originalStdout, originalStderr = os.Stdout, os.Stderr
+	os.Stdout = filterFile(originalStdout)
+	os.Stderr = filterFile(originalStderr)
+	logger.SetOutput(os.Stdout)
+}
+
+// CloseOutput vide la sortie filtrée et rétablit stdout/stderr (sans effet sans filtre)
+func CloseOutput() {
+	if outputFilter == filterNone {
+		return
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = originalStdout, originalStderr
+	logger.SetOutput(os.Stdout)
+	if stdout != originalStdout {
+		stdout.Close()
+	}
+	if stderr != originalStderr {
+		stderr.Close()
+	}
+	filterDone.Wait()
+	outputFilter = filterNone
+}
+
+// RawStdout retourne la sortie standard d'origine, hors filtre, pour y écrire des données binaires
+func RawStdout() *os.File {
+	if originalStdout != nil {
+		return originalStdout
+	}
+	return os.Stdout
+}
+
+// filterFile retourne l'extrémité d'écriture d'un tube dont le contenu filtré est recopié dans target
+func filterFile(target *os.File) *os.File {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return target // Sortie non filtrée plutôt qu'aucune sortie
+	}
+	filterDone.Add(1)
+	go func() {
+		defer filterDone.Done()
+		defer reader.Close()
+		copyFiltered(target, reader)
+	}()
+	return writer
+}
+
+// copyFiltered recopie src dans dst en filtrant chaque bloc lu; un caractère UTF-8 coupé
+// entre deux lectures est conservé pour la lecture suivante. Les blocs sont écrits dès leur
+// lecture pour que les invites sans retour à la ligne restent visibles.
+func copyFiltered(dst io.Writer, src io.Reader) {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			data := append(pending, buf[:n]...)
+			cut := len(data)
+			for back := 1; back < utf8.UTFMax && back <= len(data); back++ {
+				if utf8.RuneStart(data[len(data)-back]) {
+					if !utf8.FullRune(data[len(data)-back:]) {
+						cut = len(data) - back
+					}
+					break
+				}
+			}
+			pending = append([]byte(nil), data[cut:]...)
+			_, _ = io.WriteString(dst, FilterText(string(data[:cut])))
+		}
+		if err != nil {
+			if len(pending) > 0 {
+				_, _ = io.WriteString(dst, FilterText(string(pending)))
+			}
+			return
+		}
+	}
+}
+
+// FilterText applique le filtre de sortie actif à un texte
+func FilterText(s string) string {
+	if outputFilter == filterNone {
+		return s
+	}
+	var b strings.Builder
+	skipSpace := false
+	for _, r := range s {
+		if r == 0xFE0F || r == 0x200D || r == 0x20E3 {
+			continue // Modificateurs d'emoji (sélecteur de variante, liant, touche)
+		}
+		if skipSpace && r == ' ' {
+			continue
+		}
+		skipSpace = false
+		if tag, ok := emojiTags[r]; ok {
+			b.WriteString(tag)
+			continue
+		}
+		if isEmoji(r) {
+			// Emoji décoratif: supprimé avec les espaces qui le séparent du texte
+			skipSpace = true
+			continue
+		}
+		if r < utf8.RuneSelf || outputFilter != filterASCII {
+			b.WriteRune(r)
+			continue
+		}
+		if replacement, ok := asciiReplacements[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteByte('?')
+	}
+	return b.String()
+}
+
+// isEmoji reconnaît les emoji (pictogrammes, symboles divers, dingbats)
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2B00 && r <= 0x2BFF) || (r >= 0x231A && r <= 0x23FF)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package utils
+
+// LongPath retourne le chemin inchangé: la limite de longueur ne concerne que Windows
+func LongPath(path string) string {
+	return path
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath est la longueur au-delà de laquelle le préfixe \\?\ est nécessaire (MAX_PATH moins le nom 8.3)
+const maxShortPath = 248
+
+// LongPath préfixe un chemin Windows par \\?\ (ou \\?\UNC\) pour dépasser la limite de 260 caractères
+func LongPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "fmt"
+
+// Priorité d'entrées/sorties des restaurations (restore_io_priority)
+const (
+	IOPriorityNormal = "normal"
+	IOPriorityLow    = "low"  // Best-effort, niveau le plus bas
+	IOPriorityIdle   = "idle" // Uniquement quand le disque est inoccupé
+)
+
+// ValidateIOPriority vérifie une priorité d'E/S (vide = normal)
+func ValidateIOPriority(priority string) error {
+	switch priority {
+	case "", IOPriorityNormal, IOPriorityLow, IOPriorityIdle:
+		return nil
+	default:
+		return fmt.Errorf("invalid io priority %q (expected normal, low or idle)", priority)
+	}
+}
+
+// LowerPriority abaisse la priorité CPU (nice 0-19) et d'E/S du processus, pour qu'une restauration
+// de vérification ne pénalise pas les services d'un hôte de production
+func LowerPriority(nice int, ioPriority string) error {
+	if nice < 0 || nice > 19 {
+		return fmt.Errorf("invalid nice value %d (expected 0-19)", nice)
+	}
+	if err := ValidateIOPriority(ioPriority); err != nil {
+		return err
+	}
+	if nice > 0 {
+		if err := setNice(nice); err != nil {
+			return fmt.Errorf("error setting nice %d: %w", nice, err)
+		}
+	}
+	if ioPriority != "" && ioPriority != IOPriorityNormal {
+		if err := setIOPriority(ioPriority); err != nil {
+			return fmt.Errorf("error setting io priority %s: %w", ioPriority, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Classes ioprio_set (linux/ioprio.h)
+const (
+	ioprioClassShift  = 13
+	ioprioClassBE     = 2
+	ioprioClassIdle   = 3
+	ioprioWhoProcess  = 1
+	ioprioLowestLevel = 7
+)
+
+// setNice applique nice à tous les threads: sous Linux la priorité est par thread,
+// et les threads créés ensuite héritent de celle de leur créateur
+func setNice(nice int) error {
+	return forEachThread(func(tid int) error {
+		return syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice)
+	})
+}
+
+// setIOPriority applique la classe d'E/S à tous les threads (ioprio_set)
+func setIOPriority(priority string) error {
+	value := ioprioClassBE<<ioprioClassShift | ioprioLowestLevel
+	if priority == IOPriorityIdle {
+		value = ioprioClassIdle << ioprioClassShift
+	}
+	return forEachThread(func(tid int) error {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(value)); errno != 0 {
+			return errno
+		}
+		return nil
+	})
+}
+
+// forEachThread appelle fn pour chaque thread du processus
+func forEachThread(fn func(tid int) error) error {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fn(0)
+	}
+	for _, entry := range entries {
+		tid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if err := fn(tid); err != nil && err != syscall.ESRCH {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !windows
+
+package utils
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// setNice abaisse la priorité CPU du processus
+func setNice(nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice)
+}
+
+// setIOPriority n'est pas disponible sur cette plateforme
+func setIOPriority(priority string) error {
+	return fmt.Errorf("io priority is not supported on this platform")
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package utils
+
+import "syscall"
+
+// Classes de priorité Windows (SetPriorityClass)
+const (
+	belowNormalPriorityClass   = 0x00004000
+	idlePriorityClass          = 0x00000040
+	processModeBackgroundBegin = 0x00100000 // Abaisse aussi la priorité d'E/S et mémoire
+)
+
+var procSetPriorityClass = syscall.NewLazyDLL("kernel32.dll").NewProc("SetPriorityClass")
+
+// setNice traduit nice en classe de priorité: 1-14 sous la normale, 15-19 inactif
+func setNice(nice int) error {
+	class := uintptr(belowNormalPriorityClass)
+	if nice >= 15 {
+		class = idlePriorityClass
+	}
+	return setPriorityClass(class)
+}
+
+// setIOPriority passe le processus en mode arrière-plan (E/S et mémoire de basse priorité)
+func setIOPriority(priority string) error {
+	return setPriorityClass(processModeBackgroundBegin)
+}
+
+// setPriorityClass applique une classe de priorité au processus courant
+func setPriorityClass(class uintptr) error {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return err
+	}
+	if ret, _, err := procSetPriorityClass.Call(uintptr(process), class); ret == 0 {
+		return err
+	}
+	return nil
+}
@@ -40,6 +40,7 @@ type ProgressBar struct {
 	width     int
 	startTime time.Time
 	writer    io.Writer
+	plain     plainSummary
 }
 
 // NewProgressBar crée une nouvelle barre de progression
@@ -84,9 +85,15 @@ func (p *ProgressBar) render() {
 	}
 
 	// Formater la taille
-	currentStr := formatBytes(p.current)
-	totalStr := formatBytes(p.total)
-	speedStr := formatBytes(int64(speed)) + "/s"
+	currentStr := FormatBytes(p.current)
+	totalStr := FormatBytes(p.total)
+	speedStr := FormatBytes(int64(speed)) + "/s"
+
+	if progressMode != progressBars {
+		p.plain.print(p.writer, p.current >= p.total, "Progress: %s/%s (%d%%) %s",
+			currentStr, totalStr, int(percentage*100), speedStr)
+		return
+	}
 
 	// Afficher la barre
 	fmt.Fprintf(p.writer, "\r[%s] %s/%s (%d%%) %s",
@@ -97,14 +104,78 @@ func (p *ProgressBar) render() {
 func (p *ProgressBar) Finish() {
 	p.current = p.total
 	p.render()
-	fmt.Fprintln(p.writer)
+	endProgressLine(p.writer)
 }
 
 // Clear efface la ligne de progression
 func (p *ProgressBar) Clear() {
+	if progressMode != progressBars {
+		return
+	}
 	fmt.Fprintf(p.writer, "\r%s", strings.Repeat(" ", p.width+80))
 }
 
+// ScanProgress affiche l'avancement d'un parcours dont le total est inconnu
+// (fichiers et octets indexés, débit), sans parcours préalable de comptage
+type ScanProgress struct {
+	files      int64
+	bytes      int64
+	startTime  time.Time
+	lastRender time.Time
+	writer     io.Writer
+	plain      plainSummary
+}
+
+// scanRenderInterval limite la fréquence d'affichage sur les arborescences de petits fichiers
+const scanRenderInterval = 200 * time.Millisecond
+
+// NewScanProgress crée un compteur de parcours
+func NewScanProgress() *ScanProgress {
+	return &ScanProgress{startTime: time.Now(), writer: os.Stderr}
+}
+
+// Add compte un fichier indexé de size octets
+func (s *ScanProgress) Add(size int64) {
+	s.files++
+	s.bytes += size
+	if time.Since(s.lastRender) >= scanRenderInterval {
+		s.render()
+	}
+}
+
+// Rates retourne les débits moyens depuis le début du parcours (fichiers/s, octets/s)
+func (s *ScanProgress) Rates() (filesPerSec, bytesPerSec float64) {
+	elapsed := time.Since(s.startTime).Seconds()
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	return float64(s.files) / elapsed, float64(s.bytes) / elapsed
+}
+
+// render affiche le compteur
+func (s *ScanProgress) render() {
+	s.renderFinal(false)
+}
+
+// renderFinal affiche le compteur (final: dernier affichage, toujours écrit en mode texte)
+func (s *ScanProgress) renderFinal(final bool) {
+	s.lastRender = time.Now()
+	filesPerSec, bytesPerSec := s.Rates()
+	if progressMode != progressBars {
+		s.plain.print(s.writer, final, "%d files indexed, %s (%.0f files/s, %s/s)",
+			s.files, FormatBytes(s.bytes), filesPerSec, FormatBytes(int64(bytesPerSec)))
+		return
+	}
+	fmt.Fprintf(s.writer, "\r%s%d files indexed, %s (%.0f files/s, %s/s)   ",
+		operationTag(), s.files, FormatBytes(s.bytes), filesPerSec, FormatBytes(int64(bytesPerSec)))
+}
+
+// Finish affiche le compteur final et termine la ligne
+func (s *ScanProgress) Finish() {
+	s.renderFinal(true)
+	endProgressLine(s.writer)
+}
+
 // Status affiche un statut avec un spinner
 type Status struct {
 	message string
@@ -127,6 +198,13 @@ func NewStatus(message string) *Status {
 
 // Start démarre l'affichage du statut
 func (s *Status) Start() {
+	if progressMode != progressBars {
+		// Sans terminal, pas de spinner: le message est écrit une fois
+		if progressMode == progressPlain {
+			fmt.Fprintf(s.writer, "%s%s\n", operationTag(), s.message)
+		}
+		return
+	}
 	go func() {
 		ticker := time.NewTicker(100 * time.Millisecond)
 		defer ticker.Stop()
@@ -157,38 +235,56 @@ func (s *Status) Update(message string) {
 
 // Stop arrête l'affichage du statut
 func (s *Status) Stop() {
+	if progressMode != progressBars {
+		return
+	}
 	close(s.done)
 	fmt.Fprintln(s.writer)
 }
 
 // ProgressSuccess affiche un message de succès
 func ProgressSuccess(message string) {
-	fmt.Fprintf(os.Stderr, "✅ %s\n", message)
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "✅ %s%s\n", operationTag(), message)
 }
 
 // ProgressError affiche un message d'erreur
 func ProgressError(message string) {
-	fmt.Fprintf(os.Stderr, "❌ %s\n", message)
+	fmt.Fprintf(os.Stderr, "❌ %s%s\n", operationTag(), message)
 }
 
 // ProgressWarning affiche un message d'avertissement
 func ProgressWarning(message string) {
-	fmt.Fprintf(os.Stderr, "⚠️  %s\n", message)
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  %s%s\n", operationTag(), message)
 }
 
 // ProgressInfo affiche un message d'information
 func ProgressInfo(message string) {
-	fmt.Fprintf(os.Stderr, "ℹ️  %s\n", message)
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "ℹ️  %s%s\n", operationTag(), message)
 }
 
 // ProgressStep affiche une étape en cours
 func ProgressStep(message string) {
-	fmt.Fprintf(os.Stderr, "🔄 %s\n", message)
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🔄 %s%s\n", operationTag(), message)
 }
 
 // ProgressDone affiche une étape terminée
 func ProgressDone(message string) {
-	fmt.Fprintf(os.Stderr, "✅ %s\n", message)
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "✅ %s%s\n", operationTag(), message)
 }
 
 // DualProgressBar représente une double barre de progression
@@ -211,6 +307,7 @@ type DualProgressBar struct {
 	currentFileName string
 	currentFileSize int64
 	writer          io.Writer
+	plain           plainSummary
 }
 
 // NewDualProgressBar crée une nouvelle double barre de progression
@@ -307,13 +404,19 @@ func (dp *DualProgressBar) render() {
 	}
 
 	// Formater les tailles
-	globalCurrentStr := formatBytes(dp.globalCurrent)
-	globalTotalStr := formatBytes(dp.globalTotal)
-	globalSpeedStr := formatBytes(int64(globalSpeed)) + "/s"
+	globalCurrentStr := FormatBytes(dp.globalCurrent)
+	globalTotalStr := FormatBytes(dp.globalTotal)
+	globalSpeedStr := FormatBytes(int64(globalSpeed)) + "/s"
+
+	chunkCurrentStr := FormatBytes(dp.chunkCurrent)
+	chunkTotalStr := FormatBytes(dp.chunkTotal)
+	chunkSpeedStr := FormatBytes(int64(chunkSpeed)) + "/s"
 
-	chunkCurrentStr := formatBytes(dp.chunkCurrent)
-	chunkTotalStr := formatBytes(dp.chunkTotal)
-	chunkSpeedStr := formatBytes(int64(chunkSpeed)) + "/s"
+	if progressMode != progressBars {
+		dp.plain.print(dp.writer, dp.globalTotal > 0 && dp.globalCurrent >= dp.globalTotal, "Progress: %s/%s (%d%%) %s",
+			globalCurrentStr, globalTotalStr, int(globalPercentage*100), globalSpeedStr)
+		return
+	}
 
 	// Afficher la double barre sur une seule ligne
 	if dp.currentFileName != "" && dp.chunkTotal > 0 {
@@ -323,7 +426,7 @@ func (dp *DualProgressBar) render() {
 			fileName = "..." + fileName[len(fileName)-17:]
 		}
 
-		fileSizeStr := formatBytes(dp.currentFileSize)
+		fileSizeStr := FormatBytes(dp.currentFileSize)
 		fmt.Fprintf(dp.writer, "\r📁 [%s] %s/%s (%d%%) | 📦 [%s] %s (%s) %s/%s (%d%%) %s",
 			globalBar, globalCurrentStr, globalTotalStr, int(globalPercentage*100),
 			chunkBar, fileName, fileSizeStr, chunkCurrentStr, chunkTotalStr, int(chunkPercentage*100), chunkSpeedStr)
@@ -339,11 +442,14 @@ func (dp *DualProgressBar) Finish() {
 	dp.globalCurrent = dp.globalTotal
 	dp.chunkCurrent = dp.chunkTotal
 	dp.render()
-	fmt.Fprintln(dp.writer)
+	endProgressLine(dp.writer)
 }
 
 // Clear efface les lignes de progression
 func (dp *DualProgressBar) Clear() {
+	if progressMode != progressBars {
+		return
+	}
 	fmt.Fprintf(dp.writer, "\r%s\n\r%s",
 		strings.Repeat(" ", dp.globalWidth+50),
 		strings.Repeat(" ", dp.chunkWidth+80))
@@ -373,6 +479,7 @@ type IntegratedProgressBar struct {
 	displayThreshold time.Duration
 	// Nombre de lignes rendues la dernière fois (fichiers visibles + 1 ligne globale)
 	lastRenderedLines int
+	plain             plainSummary
 }
 
 // FileProgress représente la progression d'un fichier individuel
@@ -524,6 +631,9 @@ func (ip *IntegratedProgressBar) renderIfNeeded() {
 
 // clearPreviousOutput efface l'affichage précédent en remontant et en effaçant
 func (ip *IntegratedProgressBar) clearPreviousOutput() {
+	if progressMode != progressBars {
+		return
+	}
 	// Compter le nombre de lignes à effacer
 	ip.fileMutex.RLock()
 	fileCount := len(ip.activeFiles)
@@ -556,6 +666,11 @@ func (ip *IntegratedProgressBar) clearPreviousOutput() {
 
 // render affiche toutes les barres de progression
 func (ip *IntegratedProgressBar) render() {
+	if progressMode != progressBars {
+		ip.renderPlain()
+		return
+	}
+
 	// Sélectionner les fichiers à afficher (actifs ET au-delà du seuil de 3s)
 	ip.fileMutex.RLock()
 	activeFiles := make([]*FileProgress, 0, len(ip.activeFiles))
@@ -623,10 +738,10 @@ func (ip *IntegratedProgressBar) render() {
 		if len(fileName) > 25 {
 			fileName = "..." + fileName[len(fileName)-22:]
 		}
-		fileSizeStr := formatBytes(file.FileSize)
-		chunkCurrentStr := formatBytes(file.ChunkCurrent)
-		chunkTotalStr := formatBytes(file.ChunkTotal)
-		fileSpeedStr := formatBytes(int64(fileSpeed)) + "/s"
+		fileSizeStr := FormatBytes(file.FileSize)
+		chunkCurrentStr := FormatBytes(file.ChunkCurrent)
+		chunkTotalStr := FormatBytes(file.ChunkTotal)
+		fileSpeedStr := FormatBytes(int64(fileSpeed)) + "/s"
 
 		// Dessiner la ligne fichier
 		fmt.Fprintf(ip.writer, "\033[2K📦 %s (%s): [%s] %s/%s (%d%%) %s\n",
@@ -652,9 +767,9 @@ func (ip *IntegratedProgressBar) render() {
 	}
 
 	// Formater les tailles globales
-	globalCurrentStr := formatBytes(ip.globalCurrent)
-	globalTotalStr := formatBytes(ip.globalTotal)
-	globalSpeedStr := formatBytes(int64(globalSpeed)) + "/s"
+	globalCurrentStr := FormatBytes(ip.globalCurrent)
+	globalTotalStr := FormatBytes(ip.globalTotal)
+	globalSpeedStr := FormatBytes(int64(globalSpeed)) + "/s"
 
 	// Dessiner la ligne globale (sans saut de ligne)
 	fmt.Fprintf(ip.writer, "\033[2K📁 Global: [%s] %s/%s (%d%%) %s",
@@ -663,12 +778,31 @@ func (ip *IntegratedProgressBar) render() {
 	ip.lastRenderedLines = currentLines
 }
 
+// renderPlain écrit un résumé texte de la progression globale (sortie sans terminal)
+func (ip *IntegratedProgressBar) renderPlain() {
+	percentage := float64(0)
+	if ip.globalTotal > 0 {
+		percentage = math.Max(0, math.Min(1, float64(ip.globalCurrent)/float64(ip.globalTotal)))
+	}
+	var speed float64
+	if elapsed := time.Since(ip.globalStartTime).Seconds(); elapsed > 0 {
+		speed = float64(ip.globalCurrent) / elapsed
+	}
+
+	ip.fileMutex.RLock()
+	active := len(ip.activeFiles)
+	ip.fileMutex.RUnlock()
+	ip.plain.print(ip.writer, ip.globalTotal > 0 && ip.globalCurrent >= ip.globalTotal,
+		"Progress: %s/%s (%d%%) %s/s, %d files in progress",
+		FormatBytes(ip.globalCurrent), FormatBytes(ip.globalTotal), int(percentage*100), FormatBytes(int64(speed)), active)
+}
+
 // Finish termine la barre de progression
 func (ip *IntegratedProgressBar) Finish() {
 	ip.globalCurrent = ip.globalTotal
 	// Rendre la dernière ligne et passer à la ligne suivante
 	ip.render()
-	fmt.Fprintln(ip.writer)
+	endProgressLine(ip.writer)
 	ip.lastRenderedLines = 0
 }
 
@@ -679,6 +813,9 @@ func (ip *IntegratedProgressBar) ForceRender() {
 
 // clearScreen efface l'écran précédent et remonte au début
 func (ip *IntegratedProgressBar) clearScreen() {
+	if progressMode != progressBars {
+		return
+	}
 	// Compter le nombre de lignes à effacer
 	ip.fileMutex.RLock()
 	fileCount := len(ip.activeFiles)
@@ -713,8 +850,8 @@ func (ip *IntegratedProgressBar) clearScreen() {
 	fmt.Fprintf(ip.writer, "\r")
 }
 
-// formatBytes formate les bytes en unités lisibles
-func formatBytes(bytes int64) string {
+// FormatBytes formate les bytes en unités lisibles
+func FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Modes d'affichage des barres de progression
+const (
+	progressBars  = iota // Terminal: barres animées (retours chariot, séquences ANSI)
+	progressPlain        // Sortie redirigée (cron, CI): résumés texte périodiques, une ligne chacun
+	progressOff          // --no-progress: aucune barre ni résumé
+)
+
+// plainSummaryInterval espace les résumés du mode texte pour ne pas noyer les journaux
+const plainSummaryInterval = 30 * time.Second
+
+// progressMode est déterminé au démarrage selon stderr, où les barres sont écrites
+var progressMode = detectProgressMode(os.Stderr)
+
+// detectProgressMode choisit les barres animées uniquement si f est un terminal
+func detectProgressMode(f *os.File) int {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return progressPlain
+	}
+	return progressBars
+}
+
+// DisableProgress supprime les barres de progression et leurs résumés (--no-progress);
+// les messages d'étape (ProgressStep, ProgressSuccess...) restent affichés
+func DisableProgress() {
+	progressMode = progressOff
+}
+
+// ProgressIsTerminal indique si les barres sont animées (stderr est un terminal et --no-progress absent)
+func ProgressIsTerminal() bool {
+	return progressMode == progressBars
+}
+
+// plainSummary écrit les résumés du mode texte: au plus un par plainSummaryInterval, plus le résumé final
+type plainSummary struct {
+	last time.Time
+	done bool
+}
+
+// print écrit un résumé si l'intervalle est écoulé (toujours pour le résumé final, une seule fois)
+func (p *plainSummary) print(w io.Writer, final bool, format string, args ...interface{}) {
+	if progressMode != progressPlain || p.done {
+		return
+	}
+	if !final && !p.last.IsZero() && time.Since(p.last) < plainSummaryInterval {
+		return
+	}
+	p.last = time.Now()
+	p.done = final
+	fmt.Fprintf(w, "%s%s\n", operationTag(), fmt.Sprintf(format, args...))
+}
+
+// endProgressLine termine la ligne d'une barre animée (rien à faire en mode texte ou désactivé)
+func endProgressLine(w io.Writer) {
+	if progressMode == progressBars {
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limite le débit cumulé de plusieurs workers (octets par seconde)
+type RateLimiter struct {
+	mu   sync.Mutex
+	rate float64
+	next time.Time // Instant à partir duquel le prochain transfert peut démarrer
+}
+
+// NewRateLimiter crée un limiteur, nil (pas de limite) si bytesPerSecond <= 0
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &RateLimiter{rate: float64(bytesPerSecond)}
+}
+
+// Wait comptabilise n octets et attend le temps nécessaire pour rester sous le débit
+func (l *RateLimiter) Wait(n int64) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(n) / l.rate * float64(time.Second)))
+	l.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// RestoreRateLimiter retourne le limiteur de restore_rate_limit, nil si non configuré
+func RestoreRateLimiter(config *Config) *RateLimiter {
+	limit, err := ParseSize(config.Backup.RestoreRateLimit)
+	if config.Backup.RestoreRateLimit == "" || err != nil {
+		return nil
+	}
+	return NewRateLimiter(limit)
+}
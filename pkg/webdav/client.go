@@ -2,7 +2,9 @@ package webdav
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -29,6 +31,7 @@ type ObjectInfo struct {
 	Key          string
 	Size         int64
 	LastModified time.Time
+	ETag         string
 }
 
 // Structures XML pour parser les réponses PROPFIND
@@ -54,6 +57,7 @@ type Prop struct {
 	DisplayName   string       `xml:"displayname"`
 	ContentLength string       `xml:"getcontentlength"`
 	LastModified  string       `xml:"getlastmodified"`
+	ETag          string       `xml:"getetag"`
 	ResourceType  ResourceType `xml:"resourcetype"`
 }
 
@@ -105,7 +109,13 @@ func NewClient(baseURL, username, password string) (*Client, error) {
 
 // Upload télécharge un fichier vers WebDAV
 func (c *Client) Upload(key string, data []byte) error {
-	utils.Debug("Upload vers WebDAV: %s (%d bytes)", key, len(data))
+	return c.UploadStream(context.Background(), key, bytes.NewReader(data), int64(len(data)))
+}
+
+// UploadStream envoie un flux vers WebDAV sans le charger en mémoire (size = -1 si inconnue);
+// l'annulation de ctx interrompt la requête
+func (c *Client) UploadStream(ctx context.Context, key string, reader io.Reader, size int64) error {
+	utils.Debug("Upload to WebDAV: %s (%d bytes)", key, size)
 
 	url := c.baseURL + key
 
@@ -114,10 +124,13 @@ func (c *Client) Upload(key string, data []byte) error {
 		return fmt.Errorf("error creating directory: %w", err)
 	}
 
-	req, err := http.NewRequest("PUT", url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, reader)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
 
 	req.SetBasicAuth(c.username, c.password)
 	req.Header.Set("Content-Type", "application/octet-stream")
@@ -139,39 +152,106 @@ func (c *Client) Upload(key string, data []byte) error {
 
 // Download télécharge un fichier depuis WebDAV
 func (c *Client) Download(key string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := c.DownloadStream(context.Background(), key, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadStream écrit un fichier WebDAV dans writer et retourne le nombre d'octets écrits
+func (c *Client) DownloadStream(ctx context.Context, key string, writer io.Writer) (int64, error) {
 	utils.Debug("Download depuis WebDAV: %s", key)
 
 	url := c.baseURL + key
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return 0, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.SetBasicAuth(c.username, c.password)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors du download: %w", err)
+		return 0, fmt.Errorf("error during download: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 404 {
-		return nil, fmt.Errorf("file not found: %s", key)
+		return 0, fmt.Errorf("file not found: %s", key)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("download failed (status %d): %s", resp.StatusCode, string(body))
+		return 0, fmt.Errorf("download failed (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	if buf, ok := writer.(*bytes.Buffer); ok && resp.ContentLength > 0 {
+		buf.Grow(int(resp.ContentLength))
+	}
+	n, err := io.Copy(writer, resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading data: %w", err)
+		return n, fmt.Errorf("error reading data: %w", err)
 	}
 
-	utils.Debug("Download successful: %s (%d bytes)", key, len(data))
-	return data, nil
+	utils.Debug("Download successful: %s (%d bytes)", key, n)
+	return n, nil
+}
+
+// DownloadRange télécharge une plage d'octets d'un fichier (length <= 0 = jusqu'à la fin)
+func (c *Client) DownloadRange(key string, offset, length int64) ([]byte, error) {
+	utils.Debug("Ranged download from WebDAV: %s (offset %d, length %d)", key, offset, length)
+
+	req, err := http.NewRequest("GET", c.baseURL+key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	if length > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error during download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == 404:
+		return nil, fmt.Errorf("file not found: %s", key)
+	case resp.StatusCode == http.StatusPartialContent:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading data: %w", err)
+		}
+		return data, nil
+	case resp.StatusCode == http.StatusOK:
+		// Serveur sans support des plages: lire jusqu'à la fin de la plage et ignorer le début
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err == io.EOF {
+			return []byte{}, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading data: %w", err)
+		}
+		reader := io.Reader(resp.Body)
+		if length > 0 {
+			reader = io.LimitReader(resp.Body, length)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading data: %w", err)
+		}
+		return data, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return []byte{}, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("download failed (status %d): %s", resp.StatusCode, string(body))
+	}
 }
 
 // DeleteObject supprime un fichier WebDAV
@@ -268,6 +348,60 @@ func (c *Client) ListObjects(prefix string) ([]ObjectInfo, error) {
 	return objects, nil
 }
 
+// ErrNotFound est retournée par Stat lorsque l'objet n'existe pas
+var ErrNotFound = errors.New("object not found")
+
+// Stat retourne la taille et la date d'un fichier (PROPFIND Depth 0), sans le télécharger
+func (c *Client) Stat(key string) (ObjectInfo, error) {
+	utils.Debug("Stat WebDAV: %s", key)
+
+	propfindXML := `<?xml version="1.0" encoding="utf-8" ?>
+<D:propfind xmlns:D="DAV:">
+    <D:prop>
+        <D:getcontentlength/>
+        <D:getlastmodified/>
+        <D:getetag/>
+        <D:resourcetype/>
+    </D:prop>
+</D:propfind>`
+
+	req, err := http.NewRequest("PROPFIND", c.baseURL+key, strings.NewReader(propfindXML))
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Depth", "0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("error during stat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return ObjectInfo{}, fmt.Errorf("stat failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Une collection (répertoire) n'est pas un objet
+	objects := c.parseProFindResponse(string(body), key)
+	if len(objects) == 0 {
+		return ObjectInfo{}, ErrNotFound
+	}
+	objects[0].Key = key
+	return objects[0], nil
+}
+
 // ensureDirectory crée les répertoires parents si nécessaire
 func (c *Client) ensureDirectory(dirPath string) error {
 	if dirPath == "" || dirPath == "." {
@@ -337,7 +471,7 @@ func (c *Client) parseProFindResponse(xmlBody, prefix string) []ObjectInfo {
 
 	var multiStatus MultiStatus
 	if err := xml.Unmarshal([]byte(xmlBody), &multiStatus); err != nil {
-		utils.Debug("Erreur parsing XML: %v", err)
+		utils.Debug("Error parsing XML: %v", err)
 		return objects
 	}
 
@@ -405,6 +539,7 @@ func (c *Client) parseProFindResponse(xmlBody, prefix string) []ObjectInfo {
 			Key:          href,
 			Size:         size,
 			LastModified: lastModified,
+			ETag:         validPropStat.Prop.ETag,
 		}
 
 		objects = append(objects, obj)
@@ -415,6 +550,28 @@ func (c *Client) parseProFindResponse(xmlBody, prefix string) []ObjectInfo {
 	return objects
 }
 
+// ServerTime retourne l'heure du serveur WebDAV, lue dans l'en-tête Date d'une requête HEAD sur la racine
+func (c *Client) ServerTime() (time.Time, error) {
+	req, err := http.NewRequest("HEAD", c.baseURL, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot connect to WebDAV server: %w", err)
+	}
+	resp.Body.Close()
+
+	// Toute réponse, même refusée, porte l'en-tête Date
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("no valid Date header in the WebDAV response (status %d)", resp.StatusCode)
+	}
+	return serverTime, nil
+}
+
 // TestConnectivity teste la connectivité WebDAV
 func (c *Client) TestConnectivity() error {
 	// Tester en faisant un PROPFIND sur la racine
@@ -429,7 +586,7 @@ func (c *Client) TestConnectivity() error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("impossible de se connecter au serveur WebDAV: %w", err)
+		return fmt.Errorf("cannot connect to WebDAV server: %w", err)
 	}
 	defer resp.Body.Close()
 